@@ -0,0 +1,127 @@
+// Package a2atest checks that an A2A server conforms to the protocol: it
+// fetches the agent card, sends, streams and cancels tasks over JSON-RPC,
+// and checks that malformed calls get the error codes the protocol
+// defines. It works against any A2A server URL, so agents built with the
+// a2a package, or any other framework, can be validated in CI.
+//
+//	func TestConformance(t *testing.T) {
+//	    server, _ := a2a.NewServer(a2a.Config{Agent: myAgent})
+//	    server.StartAsync(context.Background())
+//	    a2atest.Run(t, server.URL(), a2atest.Options{})
+//	}
+//
+// The agentkit CLI runs the same checks with "agentkit conformance".
+package a2atest
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+)
+
+// Names of the checks, in the order they run.
+const (
+	CheckAgentCard      = "agent-card"
+	CheckSendMessage    = "send-message"
+	CheckGetTask        = "get-task"
+	CheckStreamMessage  = "stream-message"
+	CheckCancelTask     = "cancel-task"
+	CheckParseError     = "error/parse-error"
+	CheckInvalidRequest = "error/invalid-request"
+	CheckMethodNotFound = "error/method-not-found"
+	CheckInvalidParams  = "error/invalid-params"
+	CheckTaskNotFound   = "error/task-not-found"
+	CheckCancelNotFound = "error/cancel-task-not-found"
+)
+
+// Options configures the checks.
+type Options struct {
+	// Prompt is the text of the messages sent to the agent. Pick one the
+	// agent answers quickly.
+	// Default: "Hello"
+	Prompt string
+
+	// Header is added to every request, e.g. to authenticate.
+	Header http.Header
+
+	// HTTPClient sends the requests.
+	// Default: http.DefaultClient
+	HTTPClient *http.Client
+
+	// Timeout bounds each check.
+	// Default: 60 seconds
+	Timeout time.Duration
+
+	// Skip names checks not to run, e.g. CheckCancelTask for agents that
+	// finish before they can be canceled.
+	Skip []string
+}
+
+// Result is the outcome of a check.
+type Result struct {
+	// Name is the name of the check, e.g. CheckSendMessage.
+	Name string
+
+	// Err is why the check failed.
+	Err error
+
+	// Skipped is why the check did not run, e.g. because the agent does
+	// not stream.
+	Skipped string
+}
+
+// Passed reports whether the check ran and passed.
+func (r Result) Passed() bool {
+	return r.Err == nil && r.Skipped == ""
+}
+
+// Check runs the checks against the A2A server at baseURL, where it
+// serves its agent card, and returns their results in order.
+func Check(ctx context.Context, baseURL string, opts Options) []Result {
+	if opts.Prompt == "" {
+		opts.Prompt = "Hello"
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 60 * time.Second
+	}
+
+	s := &suite{baseURL: baseURL, opts: opts}
+	results := make([]Result, 0, len(s.checks()))
+	for _, c := range s.checks() {
+		result := Result{Name: c.name}
+		switch {
+		case slices.Contains(opts.Skip, c.name):
+			result.Skipped = "skipped by options"
+		case c.name != CheckAgentCard && s.card == nil:
+			result.Skipped = "agent card unavailable"
+		case c.name != CheckAgentCard && s.endpoint == "":
+			result.Skipped = "agent has no JSON-RPC interface"
+		default:
+			checkCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+			result.Skipped, result.Err = c.run(checkCtx)
+			cancel()
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// Run runs the checks against the A2A server at baseURL as subtests of t.
+func Run(t *testing.T, baseURL string, opts Options) {
+	t.Helper()
+	for _, result := range Check(t.Context(), baseURL, opts) {
+		t.Run(result.Name, func(t *testing.T) {
+			switch {
+			case result.Skipped != "":
+				t.Skip(result.Skipped)
+			case result.Err != nil:
+				t.Error(result.Err)
+			}
+		})
+	}
+}
@@ -0,0 +1,517 @@
+package a2atest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/a2aproject/a2a-go/a2asrv"
+)
+
+// JSON-RPC error codes the checks expect.
+const (
+	codeParseError        = -32700
+	codeInvalidRequest    = -32600
+	codeMethodNotFound    = -32601
+	codeInvalidParams     = -32602
+	codeTaskNotFound      = -32001
+	codeTaskNotCancelable = -32002
+)
+
+// taskStates are the task states of the protocol.
+var taskStates = []string{
+	"submitted", "working", "input-required", "completed", "canceled",
+	"failed", "rejected", "auth-required", "unknown",
+}
+
+// suite is a run of the checks against one server. Checks run in order
+// and later ones use what earlier ones learned.
+type suite struct {
+	baseURL string
+	opts    Options
+
+	// card is the agent card as JSON, and endpoint its JSON-RPC URL.
+	card      map[string]any
+	endpoint  string
+	streaming bool
+
+	// taskID is the task created by CheckSendMessage, if any.
+	taskID string
+	nextID int
+}
+
+// check is a named check. run returns why it was skipped, or why it
+// failed.
+type check struct {
+	name string
+	run  func(ctx context.Context) (skipped string, err error)
+}
+
+// checks returns the checks in order.
+func (s *suite) checks() []check {
+	return []check{
+		{CheckAgentCard, s.checkAgentCard},
+		{CheckSendMessage, s.checkSendMessage},
+		{CheckGetTask, s.checkGetTask},
+		{CheckStreamMessage, s.checkStreamMessage},
+		{CheckCancelTask, s.checkCancelTask},
+		{CheckParseError, s.expectError([]byte(`{"jsonrpc": "2.0", "id": 1, "method": `), codeParseError)},
+		{CheckInvalidRequest, s.expectError([]byte(`{"jsonrpc": "2.0", "id": 1}`), codeInvalidRequest)},
+		{CheckMethodNotFound, s.expectError(rpcRequest(1, "a2atest/unknown", map[string]any{}), codeMethodNotFound)},
+		{CheckInvalidParams, s.expectError(rpcRequest(1, "message/send", map[string]any{"message": "not a message"}), codeInvalidParams)},
+		{CheckTaskNotFound, s.expectError(rpcRequest(1, "tasks/get", map[string]any{"id": "a2atest-" + randomID()}), codeTaskNotFound)},
+		{CheckCancelNotFound, s.expectError(rpcRequest(1, "tasks/cancel", map[string]any{"id": "a2atest-" + randomID()}), codeTaskNotFound)},
+	}
+}
+
+// checkAgentCard fetches the agent card and checks its required fields.
+func (s *suite) checkAgentCard(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(s.baseURL, "/")+a2asrv.WellKnownAgentCardPath, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if err := expectJSON(resp); err != nil {
+		return "", err
+	}
+	var card map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&card); err != nil {
+		return "", fmt.Errorf("decoding agent card: %w", err)
+	}
+
+	for _, field := range []string{"name", "description", "url", "version", "protocolVersion"} {
+		if v, _ := card[field].(string); v == "" {
+			return "", fmt.Errorf("agent card: %s is missing or not a string", field)
+		}
+	}
+	for _, field := range []string{"defaultInputModes", "defaultOutputModes", "skills"} {
+		if _, ok := card[field].([]any); !ok {
+			return "", fmt.Errorf("agent card: %s is missing or not a list", field)
+		}
+	}
+	capabilities, ok := card["capabilities"].(map[string]any)
+	if !ok {
+		return "", errors.New("agent card: capabilities is missing or not an object")
+	}
+	for i, skill := range card["skills"].([]any) {
+		fields, _ := skill.(map[string]any)
+		for _, field := range []string{"id", "name", "description"} {
+			if v, _ := fields[field].(string); v == "" {
+				return "", fmt.Errorf("agent card: skills[%d].%s is missing or not a string", i, field)
+			}
+		}
+		if _, ok := fields["tags"].([]any); !ok {
+			return "", fmt.Errorf("agent card: skills[%d].tags is missing or not a list", i)
+		}
+	}
+
+	s.card = card
+	s.streaming, _ = capabilities["streaming"].(bool)
+	if transport, _ := card["preferredTransport"].(string); transport == "" || transport == "JSONRPC" {
+		s.endpoint = card["url"].(string)
+	} else {
+		interfaces, _ := card["additionalInterfaces"].([]any)
+		for _, iface := range interfaces {
+			fields, _ := iface.(map[string]any)
+			if fields["transport"] == "JSONRPC" {
+				s.endpoint, _ = fields["url"].(string)
+				break
+			}
+		}
+	}
+	return "", nil
+}
+
+// checkSendMessage sends a blocking message/send and checks the task or
+// message it returns.
+func (s *suite) checkSendMessage(ctx context.Context) (string, error) {
+	result, err := s.call(ctx, "message/send", map[string]any{
+		"message":       s.message(),
+		"configuration": map[string]any{"blocking": true},
+	})
+	if err != nil {
+		return "", err
+	}
+	kind, err := checkResult(result)
+	if err != nil {
+		return "", err
+	}
+	if kind != "task" && kind != "message" {
+		return "", fmt.Errorf("message/send: result kind %q, want task or message", kind)
+	}
+	s.taskID, _ = result["id"].(string)
+	if kind == "message" {
+		s.taskID, _ = result["taskId"].(string)
+	}
+	return "", nil
+}
+
+// checkGetTask gets the task of CheckSendMessage.
+func (s *suite) checkGetTask(ctx context.Context) (string, error) {
+	if s.taskID == "" {
+		return "agent answered with a message, not a task", nil
+	}
+	result, err := s.call(ctx, "tasks/get", map[string]any{"id": s.taskID})
+	if err != nil {
+		return "", err
+	}
+	kind, err := checkResult(result)
+	if err != nil {
+		return "", err
+	}
+	if kind != "task" {
+		return "", fmt.Errorf("tasks/get: result kind %q, want task", kind)
+	}
+	if result["id"] != s.taskID {
+		return "", fmt.Errorf("tasks/get: got task %v, want %s", result["id"], s.taskID)
+	}
+	return "", nil
+}
+
+// checkStreamMessage sends message/stream and checks the events up to the
+// end of the stream.
+func (s *suite) checkStreamMessage(ctx context.Context) (string, error) {
+	if !s.streaming {
+		return "agent card does not declare streaming", nil
+	}
+	id := s.id()
+	req, err := s.request(ctx, rpcRequest(id, "message/stream", map[string]any{"message": s.message()}))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := s.do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("message/stream: status %s", resp.Status)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "text/event-stream" {
+		return "", fmt.Errorf("message/stream: content type %q, want text/event-stream", resp.Header.Get("Content-Type"))
+	}
+
+	events, last, final := 0, "", false
+	var data bytes.Buffer
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if value, ok := strings.CutPrefix(line, "data:"); ok {
+			data.WriteString(strings.TrimPrefix(value, " "))
+			continue
+		}
+		if line != "" || data.Len() == 0 {
+			continue
+		}
+
+		result, err := decodeResponse(data.Bytes(), id)
+		data.Reset()
+		if err != nil {
+			return "", fmt.Errorf("message/stream event %d: %w", events+1, err)
+		}
+		kind, err := checkResult(result)
+		if err != nil {
+			return "", fmt.Errorf("message/stream event %d: %w", events+1, err)
+		}
+		if events == 0 && kind != "task" && kind != "message" {
+			return "", fmt.Errorf("message/stream: first event kind %q, want task or message", kind)
+		}
+		if final {
+			return "", fmt.Errorf("message/stream: event %q after the final event", kind)
+		}
+		events++
+		last = kind
+		final = finalEvent(kind, result)
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("message/stream: reading events: %w", err)
+	}
+	if events == 0 {
+		return "", errors.New("message/stream: no events")
+	}
+	if !final {
+		return "", fmt.Errorf("message/stream: stream ended after a %q event that is not final", last)
+	}
+	return "", nil
+}
+
+// checkCancelTask starts a task without blocking and cancels it. A task
+// that finishes first must be reported as not cancelable.
+func (s *suite) checkCancelTask(ctx context.Context) (string, error) {
+	result, err := s.call(ctx, "message/send", map[string]any{
+		"message":       s.message(),
+		"configuration": map[string]any{"blocking": false},
+	})
+	if err != nil {
+		return "", err
+	}
+	if _, err := checkResult(result); err != nil {
+		return "", err
+	}
+	if result["kind"] != "task" {
+		return "agent answered with a message, not a task", nil
+	}
+
+	taskID, _ := result["id"].(string)
+	result, err = s.call(ctx, "tasks/cancel", map[string]any{"id": taskID})
+	var rpcErr *rpcError
+	if errors.As(err, &rpcErr) && rpcErr.Code == codeTaskNotCancelable {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	kind, err := checkResult(result)
+	if err != nil {
+		return "", err
+	}
+	if kind != "task" {
+		return "", fmt.Errorf("tasks/cancel: result kind %q, want task", kind)
+	}
+	if state := taskState(result); state != "canceled" {
+		return "", fmt.Errorf("tasks/cancel: task state %q, want canceled or error %d", state, codeTaskNotCancelable)
+	}
+	return "", nil
+}
+
+// expectError returns a check that posts body and expects the JSON-RPC
+// error code.
+func (s *suite) expectError(body []byte, code int) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		req, err := s.request(ctx, body)
+		if err != nil {
+			return "", err
+		}
+		resp, err := s.do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		if err := expectJSON(resp); err != nil {
+			return "", err
+		}
+		var response rpcResponse
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			return "", fmt.Errorf("decoding response: %w", err)
+		}
+		if response.JSONRPC != "2.0" {
+			return "", fmt.Errorf("jsonrpc is %q, want 2.0", response.JSONRPC)
+		}
+		if response.Error == nil {
+			return "", fmt.Errorf("got a result, want error %d", code)
+		}
+		if response.Error.Code != code {
+			return "", fmt.Errorf("got error %d (%s), want %d", response.Error.Code, response.Error.Message, code)
+		}
+		return "", nil
+	}
+}
+
+// rpcResponse is a JSON-RPC response.
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      any             `json:"id"`
+	Result  json.RawMessage `json:"result"`
+	Error   *rpcError       `json:"error"`
+}
+
+// rpcError is a JSON-RPC error.
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error implements error.
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("JSON-RPC error %d: %s", e.Code, e.Message)
+}
+
+// rpcRequest encodes a JSON-RPC request.
+func rpcRequest(id int, method string, params any) []byte {
+	body, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": id, "method": method, "params": params})
+	return body
+}
+
+// call sends a JSON-RPC request and returns its result as JSON, or its
+// error as an *rpcError.
+func (s *suite) call(ctx context.Context, method string, params any) (map[string]any, error) {
+	id := s.id()
+	req, err := s.request(ctx, rpcRequest(id, method, params))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err := expectJSON(resp); err != nil {
+		return nil, fmt.Errorf("%s: %w", method, err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: reading response: %w", method, err)
+	}
+	result, err := decodeResponse(body, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", method, err)
+	}
+	return result, nil
+}
+
+// decodeResponse decodes a JSON-RPC response to the request with id.
+func decodeResponse(body []byte, id int) (map[string]any, error) {
+	var response rpcResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	if response.JSONRPC != "2.0" {
+		return nil, fmt.Errorf("jsonrpc is %q, want 2.0", response.JSONRPC)
+	}
+	if n, ok := response.ID.(float64); !ok || int(n) != id {
+		return nil, fmt.Errorf("response id %v, want %d", response.ID, id)
+	}
+	if response.Error != nil {
+		return nil, response.Error
+	}
+	var result map[string]any
+	if err := json.Unmarshal(response.Result, &result); err != nil {
+		return nil, fmt.Errorf("result is not an object: %w", err)
+	}
+	return result, nil
+}
+
+// checkResult checks the fields of a task, message or task update, and
+// returns its kind.
+func checkResult(result map[string]any) (string, error) {
+	kind, _ := result["kind"].(string)
+	var required []string
+	switch kind {
+	case "task":
+		required = []string{"id", "contextId"}
+		if state := taskState(result); !slices.Contains(taskStates, state) {
+			return "", fmt.Errorf("task state %q is not a task state", state)
+		}
+	case "message":
+		required = []string{"messageId", "role"}
+		if parts, _ := result["parts"].([]any); len(parts) == 0 {
+			return "", errors.New("message has no parts")
+		}
+		if result["role"] != "agent" {
+			return "", fmt.Errorf("message role %v, want agent", result["role"])
+		}
+	case "status-update":
+		required = []string{"taskId", "contextId"}
+		if state := taskState(result); !slices.Contains(taskStates, state) {
+			return "", fmt.Errorf("status update state %q is not a task state", state)
+		}
+	case "artifact-update":
+		required = []string{"taskId", "contextId"}
+		if _, ok := result["artifact"].(map[string]any); !ok {
+			return "", errors.New("artifact update has no artifact")
+		}
+	default:
+		return "", fmt.Errorf("unknown result kind %q", kind)
+	}
+	for _, field := range required {
+		if v, _ := result[field].(string); v == "" {
+			return "", fmt.Errorf("%s: %s is missing or not a string", kind, field)
+		}
+	}
+	return kind, nil
+}
+
+// taskState returns status.state of a task or status update.
+func taskState(result map[string]any) string {
+	status, _ := result["status"].(map[string]any)
+	state, _ := status["state"].(string)
+	return state
+}
+
+// finalEvent reports whether a stream event ends the stream.
+func finalEvent(kind string, result map[string]any) bool {
+	switch kind {
+	case "message":
+		return true
+	case "status-update":
+		final, _ := result["final"].(bool)
+		return final
+	case "task":
+		switch taskState(result) {
+		case "completed", "canceled", "failed", "rejected", "input-required", "auth-required":
+			return true
+		}
+	}
+	return false
+}
+
+// message returns a new user message with the prompt.
+func (s *suite) message() map[string]any {
+	return map[string]any{
+		"kind":      "message",
+		"messageId": "a2atest-" + randomID(),
+		"role":      "user",
+		"parts":     []any{map[string]any{"kind": "text", "text": s.opts.Prompt}},
+	}
+}
+
+// id returns the next JSON-RPC request ID.
+func (s *suite) id() int {
+	s.nextID++
+	return s.nextID
+}
+
+// request returns a JSON-RPC POST to the agent's endpoint.
+func (s *suite) request(ctx context.Context, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// do sends a request with the configured headers.
+func (s *suite) do(req *http.Request) (*http.Response, error) {
+	for key, values := range s.opts.Header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	return s.opts.HTTPClient.Do(req)
+}
+
+// expectJSON checks that a response is a successful JSON response.
+func expectJSON(resp *http.Response) error {
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("status %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "application/json" {
+		return fmt.Errorf("content type %q, want application/json", resp.Header.Get("Content-Type"))
+	}
+	return nil
+}
+
+// randomID returns a random hex ID.
+func randomID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,185 @@
+package a2a
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+
+	agenthttp "github.com/plexusone/agentkit/http"
+	"github.com/plexusone/agentkit/platforms/agentcore"
+)
+
+// AgentCoreExecutor is an a2asrv.AgentExecutor that runs tasks with an
+// agentcore.Agent, so one agent implementation serves AgentCore, plain
+// HTTP and A2A. Each message is one Invoke: the text of the message is
+// the prompt, the A2A context ID the session ID, and string values of the
+// request metadata the metadata. The output becomes the task's artifact,
+// with the response metadata, and an error or Response.Error fails the
+// task. The output of an agentcore.StreamingAgent is streamed as chunks of
+// the artifact, the last of which has the token usage and cost, if known,
+// as metadata. The correlation ID of the call is the
+// agentcore.MetadataCorrelationID of the request and of the artifact.
+type AgentCoreExecutor struct {
+	// Agent handles the requests.
+	Agent agentcore.Agent
+}
+
+// Execute implements a2asrv.AgentExecutor.
+func (e AgentCoreExecutor) Execute(ctx context.Context, reqCtx *a2asrv.RequestContext, queue eventqueue.Queue) error {
+	if reqCtx.StoredTask == nil {
+		if err := queue.Write(ctx, a2a.NewSubmittedTask(reqCtx, reqCtx.Message)); err != nil {
+			return err
+		}
+	}
+	if err := queue.Write(ctx, a2a.NewStatusUpdateEvent(reqCtx, a2a.TaskStateWorking, nil)); err != nil {
+		return err
+	}
+
+	req := agentcore.Request{SessionID: reqCtx.ContextID, Agent: e.Agent.Name()}
+	if reqCtx.Message != nil {
+		req.Prompt, _ = partsContent(reqCtx.Message.Parts)
+	}
+	for k, v := range reqCtx.Metadata {
+		if s, ok := v.(string); ok {
+			if req.Metadata == nil {
+				req.Metadata = make(map[string]string)
+			}
+			req.Metadata[k] = s
+		}
+	}
+	correlationID := agenthttp.CorrelationID(ctx)
+	if correlationID != "" {
+		if req.Metadata == nil {
+			req.Metadata = make(map[string]string)
+		}
+		req.Metadata[agentcore.MetadataCorrelationID] = correlationID
+	}
+
+	if sa, ok := e.Agent.(agentcore.StreamingAgent); ok {
+		return e.executeStream(ctx, reqCtx, queue, sa, req)
+	}
+
+	resp, err := e.Agent.Invoke(ctx, req)
+	if err == nil && resp.Error != "" {
+		err = fmt.Errorf("%s", resp.Error)
+	}
+	if err != nil {
+		return failTask(ctx, reqCtx, queue, err)
+	}
+
+	artifact := a2a.NewArtifactEvent(reqCtx, a2a.TextPart{Text: resp.Output})
+	artifact.LastChunk = true
+	for k, v := range resp.Metadata {
+		artifact.Artifact.SetMeta(k, v)
+	}
+	if correlationID != "" {
+		artifact.Artifact.SetMeta(agentcore.MetadataCorrelationID, correlationID)
+	}
+	if err := queue.Write(ctx, artifact); err != nil {
+		return err
+	}
+	status := a2a.NewStatusUpdateEvent(reqCtx, a2a.TaskStateCompleted, nil)
+	status.Final = true
+	return queue.Write(ctx, status)
+}
+
+// executeStream runs a task with a streaming agent, writing each chunk of
+// its output as an artifact update. A chunk is held back until the next
+// arrives, so the last can be marked as such.
+func (e AgentCoreExecutor) executeStream(ctx context.Context, reqCtx *a2asrv.RequestContext, queue eventqueue.Queue, agent agentcore.StreamingAgent, req agentcore.Request) error {
+	var artifactID a2a.ArtifactID
+	write := func(text string, last bool, meta map[string]any) error {
+		var update *a2a.TaskArtifactUpdateEvent
+		if artifactID == "" {
+			update = a2a.NewArtifactEvent(reqCtx, a2a.TextPart{Text: text})
+			artifactID = update.Artifact.ID
+		} else {
+			update = a2a.NewArtifactUpdateEvent(reqCtx, artifactID, a2a.TextPart{Text: text})
+		}
+		update.LastChunk = last
+		for k, v := range meta {
+			update.Artifact.SetMeta(k, v)
+		}
+		return queue.Write(ctx, update)
+	}
+
+	pending, started := "", false
+	for chunk := range agent.InvokeStream(ctx, req) {
+		switch {
+		case chunk.Err != nil:
+			return failTask(ctx, reqCtx, queue, chunk.Err)
+		case chunk.Done:
+			meta := map[string]any{}
+			if id := req.Metadata[agentcore.MetadataCorrelationID]; id != "" {
+				meta[agentcore.MetadataCorrelationID] = id
+			}
+			if chunk.Usage != nil {
+				meta[agentcore.MetadataPromptTokens] = chunk.Usage.PromptTokens
+				meta[agentcore.MetadataCompletionTokens] = chunk.Usage.CompletionTokens
+				if chunk.Usage.CostUSD > 0 {
+					meta[agentcore.MetadataCostUSD] = chunk.Usage.CostUSD
+				}
+			}
+			if err := write(pending, true, meta); err != nil {
+				return err
+			}
+			status := a2a.NewStatusUpdateEvent(reqCtx, a2a.TaskStateCompleted, nil)
+			status.Final = true
+			return queue.Write(ctx, status)
+		case chunk.Text != "":
+			if started {
+				if err := write(pending, false, nil); err != nil {
+					return err
+				}
+			}
+			pending, started = chunk.Text, true
+		}
+	}
+	// The stream ended without a final chunk.
+	err := ctx.Err()
+	if err == nil {
+		err = fmt.Errorf("agent stream ended unexpectedly")
+	}
+	return failTask(ctx, reqCtx, queue, err)
+}
+
+// failTask fails the task with err as its status message.
+func failTask(ctx context.Context, reqCtx *a2asrv.RequestContext, queue eventqueue.Queue, err error) error {
+	msg := a2a.NewMessageForTask(a2a.MessageRoleAgent, reqCtx, a2a.TextPart{Text: err.Error()})
+	status := a2a.NewStatusUpdateEvent(reqCtx, a2a.TaskStateFailed, msg)
+	status.Final = true
+	return queue.Write(ctx, status)
+}
+
+// HealthCheck implements agentcore.HealthChecker, so readiness covers the
+// agent when it implements it.
+func (e AgentCoreExecutor) HealthCheck(ctx context.Context) error {
+	if hc, ok := e.Agent.(agentcore.HealthChecker); ok {
+		return hc.HealthCheck(ctx)
+	}
+	return nil
+}
+
+// Cancel implements a2asrv.AgentExecutor.
+func (e AgentCoreExecutor) Cancel(ctx context.Context, reqCtx *a2asrv.RequestContext, queue eventqueue.Queue) error {
+	status := a2a.NewStatusUpdateEvent(reqCtx, a2a.TaskStateCanceled, nil)
+	status.Final = true
+	return queue.Write(ctx, status)
+}
+
+// NewAgentCoreServer creates an A2A server for an agentcore.Agent. The
+// agent card is named after the agent and lists cfg.Skills, or one skill
+// built from the name and cfg.Description. cfg.Agent and cfg.Executor are
+// ignored.
+func NewAgentCoreServer(agent agentcore.Agent, cfg Config) (*Server, error) {
+	if agent == nil {
+		return nil, fmt.Errorf("agent is required")
+	}
+	cfg.Agent = nil
+	cfg.Executor = AgentCoreExecutor{Agent: agent}
+	cfg.Name = agent.Name()
+	return NewServer(cfg)
+}
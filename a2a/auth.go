@@ -0,0 +1,104 @@
+package a2a
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/plexusone/agentkit/httpserver"
+)
+
+// grpcAuth applies an HTTP auth middleware, such as Config.Auth, to gRPC
+// calls, so both transports accept the same credentials. Each call is
+// checked as a POST to its method name with the authorization and
+// x-api-key metadata as headers; calls the middleware rejects fail with
+// codes.Unauthenticated.
+type grpcAuth struct {
+	auth httpserver.Middleware
+}
+
+// UnaryInterceptor authenticates unary gRPC calls.
+func (a grpcAuth) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := a.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor authenticates streaming gRPC calls.
+func (a grpcAuth) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, authedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticate runs the middleware on a request carrying the credentials
+// of the call and returns the context it passed on, e.g. with the JWT
+// claims of httpserver.ClaimsFromContext.
+func (a grpcAuth) authenticate(ctx context.Context, method string) (context.Context, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, method, nil)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, name := range []string{"authorization", httpserver.APIKeyHeader} {
+		if values := md.Get(name); len(values) > 0 {
+			req.Header.Set(name, values[0])
+		}
+	}
+
+	var authed context.Context
+	rec := &authRecorder{header: http.Header{}}
+	a.auth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authed = markAuthenticated(r.Context())
+	})).ServeHTTP(rec, req)
+	if authed == nil {
+		msg := "unauthenticated"
+		var body struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(rec.body.Bytes(), &body) == nil && body.Error != "" {
+			msg = body.Error
+		}
+		return nil, status.Error(codes.Unauthenticated, msg)
+	}
+	return authed, nil
+}
+
+// authRecorder is the response writer of grpcAuth, keeping the error the
+// middleware writes.
+type authRecorder struct {
+	header http.Header
+	body   bytes.Buffer
+}
+
+// Header implements http.ResponseWriter.
+func (r *authRecorder) Header() http.Header { return r.header }
+
+// Write implements http.ResponseWriter.
+func (r *authRecorder) Write(b []byte) (int, error) { return r.body.Write(b) }
+
+// WriteHeader implements http.ResponseWriter.
+func (r *authRecorder) WriteHeader(int) {}
+
+// authedStream is a server stream with the context of its authentication.
+type authedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context implements grpc.ServerStream.
+func (s authedStream) Context() context.Context { return s.ctx }
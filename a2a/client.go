@@ -0,0 +1,244 @@
+package a2a
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"iter"
+	"net/http"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2aclient"
+	"github.com/a2aproject/a2a-go/a2aclient/agentcard"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	agenthttp "github.com/plexusone/agentkit/http"
+)
+
+// ClientConfig configures NewClient.
+type ClientConfig struct {
+	// PreferredTransports orders the transports to try, e.g. gRPC first
+	// for high-throughput inter-agent traffic. The first one the agent
+	// card also offers is used.
+	// Supported: a2a.TransportProtocolJSONRPC, a2a.TransportProtocolGRPC
+	// Default: the agent card's preference
+	PreferredTransports []a2a.TransportProtocol
+
+	// HTTPClient fetches the agent card and carries JSON-RPC calls. It
+	// should have no overall timeout, which would cut off streams.
+	// Default: http.DefaultClient
+	HTTPClient *http.Client
+
+	// TLSConfig secures gRPC connections, e.g. with a client certificate
+	// for mutual TLS. gRPC connects in plaintext unless it or
+	// GRPCTLS is set.
+	TLSConfig *tls.Config
+
+	// GRPCTLS connects over TLS with the system roots when TLSConfig is
+	// nil.
+	// Default: false
+	GRPCTLS bool
+
+	// Retry configures retries.
+	// Default: http.DefaultRetryPolicy
+	Retry RetryPolicy
+
+	// Breaker configures the circuit breaker.
+	Breaker CircuitBreakerConfig
+}
+
+// Client is an A2A client for one remote agent. It retries calls that
+// fail for transient reasons (see RetryPolicy) and stops calling the
+// agent while its circuit breaker is open (see CircuitBreakerConfig), so
+// share one Client per agent.
+//
+// Sends are retried without running a message twice: the client gives
+// each message an ID, and when a send fails after it may have reached the
+// agent, it looks the message up in the task history and resends only if
+// it is missing. Streams resubscribe to their task once it is known.
+// Sends that start a new task are retried only when the agent certainly
+// did not get them, such as refused connections and 429 or 503 responses.
+type Client struct {
+	*a2aclient.Client
+
+	name    string
+	retry   RetryPolicy
+	breaker *agenthttp.CircuitBreaker
+}
+
+// NewClient fetches the agent card at baseURL and returns a client for
+// the agent, using the first transport of cfg.PreferredTransports that
+// the card offers: JSON-RPC or, for servers with a GRPCPort, gRPC.
+func NewClient(ctx context.Context, baseURL string, cfg ClientConfig) (*Client, error) {
+	c, httpClient := newClient(baseURL, cfg)
+	card, err := retry(ctx, c, always, func(ctx context.Context) (*a2a.AgentCard, error) {
+		return agentcard.NewResolver(httpClient).Resolve(ctx, baseURL)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch agent card: %w", err)
+	}
+	if err := c.connect(ctx, card, cfg, httpClient); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// NewClientFromCard returns a client for the agent of card, e.g. one found
+// with a DiscoveryClient, without fetching the card.
+func NewClientFromCard(ctx context.Context, card *a2a.AgentCard, cfg ClientConfig) (*Client, error) {
+	c, httpClient := newClient(card.URL, cfg)
+	if err := c.connect(ctx, card, cfg, httpClient); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// newClient returns an unconnected client named name, and the HTTP client
+// to use for it.
+func newClient(name string, cfg ClientConfig) (*Client, *http.Client) {
+	httpClient := http.DefaultClient
+	if cfg.HTTPClient != nil {
+		httpClient = cfg.HTTPClient
+	}
+	base := httpClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	retryClient := *httpClient
+	retryClient.Transport = retryRoundTripper{base: agenthttp.CorrelationTransport(base)}
+
+	c := &Client{name: name, retry: cfg.Retry.WithDefaults(), breaker: agenthttp.NewCircuitBreaker(cfg.Breaker)}
+	return c, &retryClient
+}
+
+// connect creates the transport of the client from card.
+func (c *Client) connect(ctx context.Context, card *a2a.AgentCard, cfg ClientConfig, httpClient *http.Client) error {
+	c.name = card.Name
+
+	creds := insecure.NewCredentials()
+	switch {
+	case cfg.TLSConfig != nil:
+		creds = credentials.NewTLS(cfg.TLSConfig.Clone())
+	case cfg.GRPCTLS:
+		creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+
+	var err error
+	c.Client, err = a2aclient.NewFromCard(ctx, card,
+		a2aclient.WithDefaultsDisabled(),
+		a2aclient.WithJSONRPCTransport(httpClient),
+		a2aclient.WithGRPCTransport(
+			grpc.WithTransportCredentials(creds),
+			grpc.WithChainUnaryInterceptor(correlationUnaryInterceptor, retryUnaryInterceptor),
+			grpc.WithChainStreamInterceptor(correlationStreamInterceptor, retryStreamInterceptor),
+		),
+		a2aclient.WithConfig(a2aclient.Config{PreferredTransports: cfg.PreferredTransports}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create client for %s: %w", card.Name, err)
+	}
+	return nil
+}
+
+// GetTask implements a2aclient.Transport, with retries.
+func (c *Client) GetTask(ctx context.Context, query *a2a.TaskQueryParams) (*a2a.Task, error) {
+	return retry(ctx, c, always, func(ctx context.Context) (*a2a.Task, error) {
+		return c.Client.GetTask(ctx, query)
+	})
+}
+
+// ListTasks implements a2aclient.Transport, with retries.
+func (c *Client) ListTasks(ctx context.Context, req *a2a.ListTasksRequest) (*a2a.ListTasksResponse, error) {
+	return retry(ctx, c, always, func(ctx context.Context) (*a2a.ListTasksResponse, error) {
+		return c.Client.ListTasks(ctx, req)
+	})
+}
+
+// CancelTask implements a2aclient.Transport, with retries.
+func (c *Client) CancelTask(ctx context.Context, id *a2a.TaskIDParams) (*a2a.Task, error) {
+	return retry(ctx, c, always, func(ctx context.Context) (*a2a.Task, error) {
+		return c.Client.CancelTask(ctx, id)
+	})
+}
+
+// SendMessage implements a2aclient.Transport, with retries. If the agent
+// turns out to have the message already, it returns the task as it
+// stands.
+func (c *Client) SendMessage(ctx context.Context, message *a2a.MessageSendParams) (a2a.SendMessageResult, error) {
+	message = withMessageID(message)
+	var taskID a2a.TaskID
+	if message != nil && message.Message != nil {
+		taskID = message.Message.TaskID
+	}
+	lookup := false
+	resend := func(rejected bool) bool {
+		lookup = lookup || !rejected
+		return !lookup || taskID != ""
+	}
+	return retry(ctx, c, resend, func(ctx context.Context) (a2a.SendMessageResult, error) {
+		if lookup {
+			task, err := c.Client.GetTask(ctx, &a2a.TaskQueryParams{ID: taskID})
+			if err != nil {
+				return nil, err
+			}
+			lookup = false
+			if hasMessage(task, message.Message.ID) {
+				return task, nil
+			}
+		}
+		return c.Client.SendMessage(ctx, message)
+	})
+}
+
+// SendStreamingMessage implements a2aclient.Transport, with retries.
+func (c *Client) SendStreamingMessage(ctx context.Context, message *a2a.MessageSendParams) iter.Seq2[a2a.Event, error] {
+	message = withMessageID(message)
+	var taskID a2a.TaskID
+	if message != nil && message.Message != nil {
+		taskID = message.Message.TaskID
+	}
+	return c.stream(ctx, message, taskID)
+}
+
+// ResubscribeToTask implements a2aclient.Transport, with retries.
+func (c *Client) ResubscribeToTask(ctx context.Context, id *a2a.TaskIDParams) iter.Seq2[a2a.Event, error] {
+	return c.stream(ctx, nil, id.ID)
+}
+
+// GetTaskPushConfig implements a2aclient.Transport, with retries.
+func (c *Client) GetTaskPushConfig(ctx context.Context, params *a2a.GetTaskPushConfigParams) (*a2a.TaskPushConfig, error) {
+	return retry(ctx, c, always, func(ctx context.Context) (*a2a.TaskPushConfig, error) {
+		return c.Client.GetTaskPushConfig(ctx, params)
+	})
+}
+
+// ListTaskPushConfig implements a2aclient.Transport, with retries.
+func (c *Client) ListTaskPushConfig(ctx context.Context, params *a2a.ListTaskPushConfigParams) ([]*a2a.TaskPushConfig, error) {
+	return retry(ctx, c, always, func(ctx context.Context) ([]*a2a.TaskPushConfig, error) {
+		return c.Client.ListTaskPushConfig(ctx, params)
+	})
+}
+
+// SetTaskPushConfig implements a2aclient.Transport, with retries.
+func (c *Client) SetTaskPushConfig(ctx context.Context, params *a2a.TaskPushConfig) (*a2a.TaskPushConfig, error) {
+	return retry(ctx, c, always, func(ctx context.Context) (*a2a.TaskPushConfig, error) {
+		return c.Client.SetTaskPushConfig(ctx, params)
+	})
+}
+
+// DeleteTaskPushConfig implements a2aclient.Transport, with retries.
+func (c *Client) DeleteTaskPushConfig(ctx context.Context, params *a2a.DeleteTaskPushConfigParams) error {
+	_, err := retry(ctx, c, always, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, c.Client.DeleteTaskPushConfig(ctx, params)
+	})
+	return err
+}
+
+// GetAgentCard implements a2aclient.Transport, with retries.
+func (c *Client) GetAgentCard(ctx context.Context) (*a2a.AgentCard, error) {
+	return retry(ctx, c, always, func(ctx context.Context) (*a2a.AgentCard, error) {
+		return c.Client.GetAgentCard(ctx)
+	})
+}
@@ -0,0 +1,82 @@
+package a2a
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	agenthttp "github.com/plexusone/agentkit/http"
+)
+
+// correlationMetadataKey is the gRPC metadata key of the correlation ID,
+// the X-Correlation-ID header in the form gRPC requires.
+const correlationMetadataKey = "x-correlation-id"
+
+// correlateHTTP gives the context of requests to h the correlation ID it
+// already has, e.g. set by httpserver.RequestID, the X-Correlation-ID
+// header, or a new one, and echoes it on the response.
+func correlateHTTP(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id := agenthttp.CorrelationID(ctx)
+		if id == "" {
+			if header := r.Header.Get(agenthttp.CorrelationIDHeader); agenthttp.ValidCorrelationID(header) {
+				id = header
+				ctx = agenthttp.WithCorrelationID(ctx, id)
+			} else {
+				ctx, id = agenthttp.EnsureCorrelationID(ctx)
+			}
+		}
+		w.Header().Set(agenthttp.CorrelationIDHeader, id)
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// correlator is a call interceptor that gives calls on any transport a
+// correlation ID: the one of the context, set by correlateHTTP for
+// JSON-RPC, the x-correlation-id metadata of gRPC calls, or a new one.
+// Tasks run in the context of the call that started them, so their
+// agents see it too.
+type correlator struct {
+	a2asrv.PassthroughCallInterceptor
+}
+
+// Before implements a2asrv.CallInterceptor.
+func (correlator) Before(ctx context.Context, callCtx *a2asrv.CallContext, req *a2asrv.Request) (context.Context, error) {
+	if agenthttp.CorrelationID(ctx) != "" {
+		return ctx, nil
+	}
+	if values, ok := callCtx.RequestMeta().Get(correlationMetadataKey); ok && len(values) > 0 && agenthttp.ValidCorrelationID(values[0]) {
+		return agenthttp.WithCorrelationID(ctx, values[0]), nil
+	}
+	ctx, _ = agenthttp.EnsureCorrelationID(ctx)
+	return ctx, nil
+}
+
+// correlationUnaryInterceptor sends the correlation ID of the context of
+// unary gRPC calls as metadata.
+func correlationUnaryInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(outgoingCorrelation(ctx), method, req, reply, cc, opts...)
+}
+
+// correlationStreamInterceptor sends the correlation ID of the context of
+// gRPC streams as metadata.
+func correlationStreamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(outgoingCorrelation(ctx), desc, cc, method, opts...)
+}
+
+// outgoingCorrelation returns ctx with its correlation ID, if any, in the
+// outgoing gRPC metadata.
+func outgoingCorrelation(ctx context.Context) context.Context {
+	id := agenthttp.CorrelationID(ctx)
+	if id == "" {
+		return ctx
+	}
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(correlationMetadataKey)) > 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, correlationMetadataKey, id)
+}
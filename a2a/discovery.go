@@ -0,0 +1,331 @@
+package a2a
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+)
+
+// DiscoveryPath is the path of the agents collection on a discovery server.
+const DiscoveryPath = "/agents"
+
+// AgentQuery selects agents by what they can do. An agent matches if one
+// of its skills matches every field that is set. Matching is
+// case-insensitive.
+type AgentQuery struct {
+	// Skill matches the ID or name of a skill.
+	Skill string
+
+	// Tag matches one of a skill's tags.
+	Tag string
+
+	// InputMode matches a MIME type the skill accepts, e.g.
+	// "application/pdf". Skills without input modes accept the agent's
+	// default modes.
+	InputMode string
+
+	// Text matches a substring of the agent's name or description, or of
+	// the skill's name, description, tags or examples.
+	Text string
+}
+
+// values encodes the query as URL parameters.
+func (q AgentQuery) values() url.Values {
+	v := url.Values{}
+	for key, value := range map[string]string{"skill": q.Skill, "tag": q.Tag, "input_mode": q.InputMode, "q": q.Text} {
+		if value != "" {
+			v.Set(key, value)
+		}
+	}
+	return v
+}
+
+// Matches reports whether card has a skill matching q.
+func (q AgentQuery) Matches(card *a2a.AgentCard) bool {
+	text := strings.ToLower(q.Text)
+	agentText := text == "" ||
+		strings.Contains(strings.ToLower(card.Name), text) ||
+		strings.Contains(strings.ToLower(card.Description), text)
+
+	for _, skill := range card.Skills {
+		if q.Skill != "" && !strings.EqualFold(skill.ID, q.Skill) && !strings.EqualFold(skill.Name, q.Skill) {
+			continue
+		}
+		if q.Tag != "" && !slices.ContainsFunc(skill.Tags, equalFold(q.Tag)) {
+			continue
+		}
+		if q.InputMode != "" {
+			modes := skill.InputModes
+			if len(modes) == 0 {
+				modes = card.DefaultInputModes
+			}
+			if !slices.ContainsFunc(modes, equalFold(q.InputMode)) {
+				continue
+			}
+		}
+		if !agentText && !skillContains(skill, text) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// equalFold returns a case-insensitive comparison with s.
+func equalFold(s string) func(string) bool {
+	return func(t string) bool { return strings.EqualFold(s, t) }
+}
+
+// skillContains reports whether the lowercase text appears in the skill's
+// name, description, tags or examples.
+func skillContains(skill a2a.AgentSkill, text string) bool {
+	fields := append([]string{skill.Name, skill.Description}, skill.Tags...)
+	fields = append(fields, skill.Examples...)
+	return slices.ContainsFunc(fields, func(f string) bool {
+		return strings.Contains(strings.ToLower(f), text)
+	})
+}
+
+// DiscoveryOptions configures a Discovery server.
+type DiscoveryOptions struct {
+	// TTL removes agents that have not registered again within it, so
+	// agents that stop without deregistering drop out. Registered servers
+	// renew at a third of it.
+	// Default: 90 seconds
+	TTL time.Duration
+}
+
+// Discovery is a discovery server: A2A servers register their agent cards
+// with it and clients find agents by skill, tag, input mode or text, so
+// multi-agent systems resolve agents at runtime instead of hardcoding
+// their URLs. Registrations are kept in memory and expire after the TTL.
+//
+// It serves, under DiscoveryPath:
+//
+//	POST   /agents                             register or renew an agent card
+//	GET    /agents?skill=&tag=&input_mode=&q=  list matching cards
+//	DELETE /agents?url=                        deregister the agent at url
+//
+// Mount it on an HTTP server, e.g. with httpserver.Config.Handlers.
+type Discovery struct {
+	ttl time.Duration
+
+	mu     sync.Mutex
+	agents map[string]*registration
+}
+
+// registration is a registered agent card.
+type registration struct {
+	card    *a2a.AgentCard
+	expires time.Time
+}
+
+// Registration is the response to a registration.
+type Registration struct {
+	// URL identifies the agent: the URL of its card.
+	URL string `json:"url"`
+
+	// ExpiresAt is when the registration lapses unless renewed.
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// NewDiscovery creates an empty discovery server.
+func NewDiscovery(opts DiscoveryOptions) *Discovery {
+	if opts.TTL <= 0 {
+		opts.TTL = 90 * time.Second
+	}
+	return &Discovery{ttl: opts.TTL, agents: make(map[string]*registration)}
+}
+
+// Register adds or renews an agent card. Cards are keyed by URL, which
+// must be set along with the name.
+func (d *Discovery) Register(card *a2a.AgentCard) (Registration, error) {
+	if card.URL == "" || card.Name == "" {
+		return Registration{}, fmt.Errorf("agent card needs a name and a URL")
+	}
+	expires := time.Now().Add(d.ttl)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.agents[card.URL] = &registration{card: card, expires: expires}
+	return Registration{URL: card.URL, ExpiresAt: expires}, nil
+}
+
+// Deregister removes the agent with the given URL.
+func (d *Discovery) Deregister(agentURL string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.agents, agentURL)
+}
+
+// Find returns the live agents matching q, ordered by name then URL.
+func (d *Discovery) Find(q AgentQuery) []*a2a.AgentCard {
+	now := time.Now()
+
+	d.mu.Lock()
+	var cards []*a2a.AgentCard
+	for key, reg := range d.agents {
+		if now.After(reg.expires) {
+			delete(d.agents, key)
+			continue
+		}
+		if q.Matches(reg.card) {
+			cards = append(cards, reg.card)
+		}
+	}
+	d.mu.Unlock()
+
+	sort.Slice(cards, func(i, j int) bool {
+		if cards[i].Name != cards[j].Name {
+			return cards[i].Name < cards[j].Name
+		}
+		return cards[i].URL < cards[j].URL
+	})
+	return cards
+}
+
+// ServeHTTP implements http.Handler.
+func (d *Discovery) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		params := r.URL.Query()
+		cards := d.Find(AgentQuery{
+			Skill:     params.Get("skill"),
+			Tag:       params.Get("tag"),
+			InputMode: params.Get("input_mode"),
+			Text:      params.Get("q"),
+		})
+		if cards == nil {
+			cards = []*a2a.AgentCard{}
+		}
+		writeDiscoveryJSON(w, http.StatusOK, agentList{Agents: cards})
+	case http.MethodPost:
+		var card a2a.AgentCard
+		if err := json.NewDecoder(r.Body).Decode(&card); err != nil {
+			http.Error(w, "invalid agent card: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		reg, err := d.Register(&card)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeDiscoveryJSON(w, http.StatusOK, reg)
+	case http.MethodDelete:
+		agentURL := r.URL.Query().Get("url")
+		if agentURL == "" {
+			http.Error(w, "url parameter is required", http.StatusBadRequest)
+			return
+		}
+		d.Deregister(agentURL)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// agentList is the response to a query.
+type agentList struct {
+	Agents []*a2a.AgentCard `json:"agents"`
+}
+
+// writeDiscoveryJSON writes v as a JSON response.
+func writeDiscoveryJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// DiscoveryClient registers agents with, and finds agents on, a discovery
+// server.
+type DiscoveryClient struct {
+	// BaseURL is the URL the Discovery handler is mounted at, without
+	// DiscoveryPath, e.g. "http://discovery:8500".
+	BaseURL string
+
+	// HTTPClient sends the requests.
+	// Default: a client with a 10 second timeout
+	HTTPClient *http.Client
+}
+
+// NewDiscoveryClient creates a client for the discovery server at baseURL.
+func NewDiscoveryClient(baseURL string) *DiscoveryClient {
+	return &DiscoveryClient{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Register adds or renews an agent card.
+func (c *DiscoveryClient) Register(ctx context.Context, card *a2a.AgentCard) (Registration, error) {
+	body, err := json.Marshal(card)
+	if err != nil {
+		return Registration{}, fmt.Errorf("encoding agent card: %w", err)
+	}
+	var reg Registration
+	err = c.do(ctx, http.MethodPost, c.BaseURL+DiscoveryPath, bytes.NewReader(body), &reg)
+	return reg, err
+}
+
+// Deregister removes the agent whose card has the given URL.
+func (c *DiscoveryClient) Deregister(ctx context.Context, agentURL string) error {
+	target := c.BaseURL + DiscoveryPath + "?" + url.Values{"url": {agentURL}}.Encode()
+	return c.do(ctx, http.MethodDelete, target, nil, nil)
+}
+
+// Find returns the registered agents matching q.
+func (c *DiscoveryClient) Find(ctx context.Context, q AgentQuery) ([]*a2a.AgentCard, error) {
+	target := c.BaseURL + DiscoveryPath
+	if params := q.values().Encode(); params != "" {
+		target += "?" + params
+	}
+	var list agentList
+	if err := c.do(ctx, http.MethodGet, target, nil, &list); err != nil {
+		return nil, err
+	}
+	return list.Agents, nil
+}
+
+// do sends a request and decodes the JSON response into out, if not nil.
+func (c *DiscoveryClient) do(ctx context.Context, method, target string, body io.Reader, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return fmt.Errorf("discovery: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("discovery: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("discovery %s %s: %s: %s", method, DiscoveryPath, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("discovery: decoding response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package a2a
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+)
+
+// errShuttingDown is reported for tasks rejected or canceled because the
+// server is stopping.
+var errShuttingDown = errors.New("the server is shutting down")
+
+// drainer wraps the server's executor to track running tasks, so Stop can
+// wait for them. Once draining, it rejects new tasks; tasks still running
+// at the drain deadline are canceled and end with a canceled status
+// instead of being cut off.
+type drainer struct {
+	a2asrv.AgentExecutor
+
+	mu       sync.Mutex
+	draining bool
+	running  map[*execution]struct{}
+	wg       sync.WaitGroup
+}
+
+// execution is a running task.
+type execution struct {
+	cancel  context.CancelFunc
+	aborted atomic.Bool
+}
+
+// newDrainer creates a drainer; its executor is set when the server starts.
+func newDrainer() *drainer {
+	return &drainer{running: make(map[*execution]struct{})}
+}
+
+// Execute implements a2asrv.AgentExecutor.
+func (d *drainer) Execute(ctx context.Context, reqCtx *a2asrv.RequestContext, queue eventqueue.Queue) error {
+	d.mu.Lock()
+	if d.draining {
+		d.mu.Unlock()
+		if reqCtx.StoredTask == nil {
+			if err := queue.Write(ctx, a2a.NewSubmittedTask(reqCtx, reqCtx.Message)); err != nil {
+				return err
+			}
+		}
+		return queue.Write(ctx, shutdownStatus(reqCtx, a2a.TaskStateRejected))
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	exec := &execution{cancel: cancel}
+	d.running[exec] = struct{}{}
+	d.wg.Add(1)
+	d.mu.Unlock()
+
+	defer func() {
+		d.mu.Lock()
+		delete(d.running, exec)
+		d.mu.Unlock()
+		cancel()
+		d.wg.Done()
+	}()
+
+	err := d.AgentExecutor.Execute(ctx, reqCtx, abortableQueue{Queue: queue, exec: exec})
+	if exec.aborted.Load() {
+		return queue.Write(context.WithoutCancel(ctx), shutdownStatus(reqCtx, a2a.TaskStateCanceled))
+	}
+	return err
+}
+
+// ready fails once the server is stopping, as a readiness check, so load
+// balancers stop routing to it.
+func (d *drainer) ready(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.draining {
+		return errShuttingDown
+	}
+	return nil
+}
+
+// drain stops accepting tasks and waits for the running ones until
+// timeout or ctx ends. It then cancels the remaining tasks and waits, for
+// as long as ctx allows, for them to report the cancelation.
+func (d *drainer) drain(ctx context.Context, timeout time.Duration) {
+	d.mu.Lock()
+	d.draining = true
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	d.mu.Lock()
+	for exec := range d.running {
+		exec.aborted.Store(true)
+		exec.cancel()
+	}
+	d.mu.Unlock()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
+
+// shutdownStatus returns the final status of a task rejected or canceled
+// by the shutdown.
+func shutdownStatus(reqCtx *a2asrv.RequestContext, state a2a.TaskState) *a2a.TaskStatusUpdateEvent {
+	msg := a2a.NewMessageForTask(a2a.MessageRoleAgent, reqCtx, a2a.TextPart{Text: errShuttingDown.Error()})
+	status := a2a.NewStatusUpdateEvent(reqCtx, state, msg)
+	status.Final = true
+	return status
+}
+
+// abortableQueue drops the events of an execution once it is aborted, so
+// whatever the executor writes while winding down does not precede the
+// canceled status.
+type abortableQueue struct {
+	eventqueue.Queue
+	exec *execution
+}
+
+// Write implements eventqueue.Queue.
+func (q abortableQueue) Write(ctx context.Context, event a2a.Event) error {
+	if q.exec.aborted.Load() {
+		return context.Canceled
+	}
+	return q.Queue.Write(ctx, event)
+}
+
+// WriteVersioned implements eventqueue.Queue.
+func (q abortableQueue) WriteVersioned(ctx context.Context, event a2a.Event, version a2a.TaskVersion) error {
+	if q.exec.aborted.Load() {
+		return context.Canceled
+	}
+	return q.Queue.WriteVersioned(ctx, event, version)
+}
@@ -0,0 +1,300 @@
+package a2a
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+
+	agenthttp "github.com/plexusone/agentkit/http"
+)
+
+// Metrics served by Metrics. Both carry the method label; requests also
+// carry the outcome.
+const (
+	// MetricRequests counts finished calls.
+	MetricRequests = "agentkit_a2a_requests_total"
+
+	// MetricRequestDuration is a histogram of call durations in seconds.
+	MetricRequestDuration = "agentkit_a2a_request_duration_seconds"
+)
+
+// Call describes a finished call to the server, on any transport.
+type Call struct {
+	// Method is the JSON-RPC method, e.g. "message/send".
+	Method string
+
+	// TaskID is the task of the call, if known.
+	TaskID string
+
+	// Duration runs from the request to the response or, for streams, to
+	// the final event.
+	Duration time.Duration
+
+	// Outcome is "error", the task state of sends (e.g. "completed" or
+	// "input-required"), "message" for sends answered with a message, and
+	// "ok" otherwise.
+	Outcome string
+
+	// Err is the error of failed calls.
+	Err error
+
+	// CorrelationID is the correlation ID of the call, see
+	// agenthttp.CorrelationID.
+	CorrelationID string
+}
+
+// callMethods maps a2asrv.CallContext methods to JSON-RPC methods.
+var callMethods = map[string]string{
+	"OnSendMessage":          "message/send",
+	"OnSendMessageStream":    "message/stream",
+	"OnGetTask":              "tasks/get",
+	"OnListTasks":            "tasks/list",
+	"OnCancelTask":           "tasks/cancel",
+	"OnResubscribeToTask":    "tasks/resubscribe",
+	"OnGetTaskPushConfig":    "tasks/pushNotificationConfig/get",
+	"OnSetTaskPushConfig":    "tasks/pushNotificationConfig/set",
+	"OnListTaskPushConfig":   "tasks/pushNotificationConfig/list",
+	"OnDeleteTaskPushConfig": "tasks/pushNotificationConfig/delete",
+	"OnGetExtendedAgentCard": "agent/getAuthenticatedExtendedCard",
+}
+
+// ObserveCalls returns a call interceptor that reports each finished call
+// to fn. Streams are reported at their final event; streams the client
+// abandons first are not reported.
+func ObserveCalls(fn func(ctx context.Context, call Call)) a2asrv.CallInterceptor {
+	return &callObserver{fn: fn}
+}
+
+// callObserver implements ObserveCalls.
+type callObserver struct {
+	a2asrv.PassthroughCallInterceptor
+	fn func(ctx context.Context, call Call)
+}
+
+// observedCall is a call in progress, carried in the context from Before
+// to After under its observer.
+type observedCall struct {
+	start  time.Time
+	taskID a2a.TaskID
+	done   atomic.Bool
+}
+
+// Before implements a2asrv.CallInterceptor.
+func (o *callObserver) Before(ctx context.Context, callCtx *a2asrv.CallContext, req *a2asrv.Request) (context.Context, error) {
+	call := &observedCall{start: time.Now()}
+	switch p := req.Payload.(type) {
+	case *a2a.MessageSendParams:
+		if p != nil && p.Message != nil {
+			call.taskID = p.Message.TaskID
+		}
+	case *a2a.TaskQueryParams:
+		if p != nil {
+			call.taskID = p.ID
+		}
+	case *a2a.TaskIDParams:
+		if p != nil {
+			call.taskID = p.ID
+		}
+	case *a2a.TaskPushConfig:
+		if p != nil {
+			call.taskID = p.TaskID
+		}
+	case *a2a.GetTaskPushConfigParams:
+		if p != nil {
+			call.taskID = p.TaskID
+		}
+	case *a2a.ListTaskPushConfigParams:
+		if p != nil {
+			call.taskID = p.TaskID
+		}
+	case *a2a.DeleteTaskPushConfigParams:
+		if p != nil {
+			call.taskID = p.TaskID
+		}
+	}
+	return context.WithValue(ctx, o, call), nil
+}
+
+// After implements a2asrv.CallInterceptor. It is called once per event
+// for streams.
+func (o *callObserver) After(ctx context.Context, callCtx *a2asrv.CallContext, resp *a2asrv.Response) error {
+	call, ok := ctx.Value(o).(*observedCall)
+	if !ok || call.done.Load() {
+		return nil
+	}
+	method := callCtx.Method()
+	streaming := method == "OnSendMessageStream" || method == "OnResubscribeToTask"
+
+	outcome, final := "ok", !streaming
+	if resp.Err != nil {
+		outcome, final = "error", true
+	} else {
+		switch p := resp.Payload.(type) {
+		case *a2a.Message:
+			if p != nil {
+				outcome, final = "message", true
+			}
+		case *a2a.Task:
+			if p != nil {
+				outcome = string(p.Status.State)
+				final = final || p.Status.State.Terminal() ||
+					p.Status.State == a2a.TaskStateInputRequired || p.Status.State == a2a.TaskStateAuthRequired
+			}
+		case *a2a.TaskStatusUpdateEvent:
+			if p != nil {
+				outcome, final = string(p.Status.State), final || p.Final
+			}
+		}
+		if event, ok := resp.Payload.(a2a.Event); ok && event != nil && call.taskID == "" {
+			call.taskID = event.TaskInfo().TaskID
+		}
+	}
+	if !final || call.done.Swap(true) {
+		return nil
+	}
+
+	if name, ok := callMethods[method]; ok {
+		method = name
+	}
+	o.fn(ctx, Call{
+		Method:        method,
+		TaskID:        string(call.taskID),
+		Duration:      time.Since(call.start),
+		Outcome:       outcome,
+		Err:           resp.Err,
+		CorrelationID: agenthttp.CorrelationID(ctx),
+	})
+	return nil
+}
+
+// AccessLog returns a call interceptor that logs each finished call with
+// its method, task ID, duration, outcome and correlation ID.
+// Default logger: slog.Default()
+func AccessLog(logger *slog.Logger) a2asrv.CallInterceptor {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return ObserveCalls(func(ctx context.Context, call Call) {
+		attrs := []slog.Attr{
+			slog.String("method", call.Method),
+			slog.String("task_id", call.TaskID),
+			slog.Duration("duration", call.Duration),
+			slog.String("outcome", call.Outcome),
+		}
+		if call.CorrelationID != "" {
+			attrs = append(attrs, slog.String("correlation_id", call.CorrelationID))
+		}
+		level := slog.LevelInfo
+		if call.Err != nil {
+			level = slog.LevelWarn
+			attrs = append(attrs, slog.String("error", call.Err.Error()))
+		}
+		logger.LogAttrs(ctx, level, "a2a call", attrs...)
+	})
+}
+
+// metricsBuckets are the duration histogram buckets in seconds, spanning
+// quick lookups to long agent runs.
+var metricsBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+
+// Metrics is a call interceptor that counts calls and their durations,
+// and serves them in the Prometheus text format.
+type Metrics struct {
+	observer a2asrv.CallInterceptor
+
+	mu        sync.Mutex
+	requests  map[[2]string]uint64
+	durations map[string]*histogram
+}
+
+// histogram is the duration histogram of a method.
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative
+	sum    float64
+	count  uint64
+}
+
+// NewMetrics creates empty metrics.
+func NewMetrics() *Metrics {
+	m := &Metrics{requests: make(map[[2]string]uint64), durations: make(map[string]*histogram)}
+	m.observer = ObserveCalls(m.record)
+	return m
+}
+
+// Before implements a2asrv.CallInterceptor.
+func (m *Metrics) Before(ctx context.Context, callCtx *a2asrv.CallContext, req *a2asrv.Request) (context.Context, error) {
+	return m.observer.Before(ctx, callCtx, req)
+}
+
+// After implements a2asrv.CallInterceptor.
+func (m *Metrics) After(ctx context.Context, callCtx *a2asrv.CallContext, resp *a2asrv.Response) error {
+	return m.observer.After(ctx, callCtx, resp)
+}
+
+// record adds a finished call.
+func (m *Metrics) record(_ context.Context, call Call) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[[2]string{call.Method, call.Outcome}]++
+	h, ok := m.durations[call.Method]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(metricsBuckets))}
+		m.durations[call.Method] = h
+	}
+	seconds := call.Duration.Seconds()
+	if i, _ := slices.BinarySearch(metricsBuckets, seconds); i < len(metricsBuckets) {
+		h.counts[i]++
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// ServeHTTP serves the metrics in the Prometheus text format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# HELP %s A2A calls by method and outcome.\n# TYPE %s counter\n", MetricRequests, MetricRequests)
+	keys := make([][2]string, 0, len(m.requests))
+	for key := range m.requests {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b [2]string) int {
+		return strings.Compare(a[0]+"\x00"+a[1], b[0]+"\x00"+b[1])
+	})
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s{method=%q,outcome=%q} %d\n", MetricRequests, key[0], key[1], m.requests[key])
+	}
+
+	fmt.Fprintf(&b, "# HELP %s A2A call duration by method.\n# TYPE %s histogram\n", MetricRequestDuration, MetricRequestDuration)
+	methods := make([]string, 0, len(m.durations))
+	for method := range m.durations {
+		methods = append(methods, method)
+	}
+	slices.Sort(methods)
+	for _, method := range methods {
+		h := m.durations[method]
+		var cumulative uint64
+		for i, le := range metricsBuckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(&b, "%s_bucket{method=%q,le=\"%g\"} %d\n", MetricRequestDuration, method, le, cumulative)
+		}
+		fmt.Fprintf(&b, "%s_bucket{method=%q,le=\"+Inf\"} %d\n", MetricRequestDuration, method, h.count)
+		fmt.Fprintf(&b, "%s_sum{method=%q} %g\n", MetricRequestDuration, method, h.sum)
+		fmt.Fprintf(&b, "%s_count{method=%q} %d\n", MetricRequestDuration, method, h.count)
+	}
+	m.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write([]byte(b.String()))
+}
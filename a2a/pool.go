@@ -0,0 +1,470 @@
+package a2a
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+)
+
+// ErrNoInstances is returned by a ClientPool that has no instance to call:
+// none is known, or the circuit breakers of all are open.
+var ErrNoInstances = errors.New("no healthy agent instances")
+
+// Balance selects the instance of a ClientPool to call.
+type Balance string
+
+const (
+	// BalanceRoundRobin calls the instances in turn.
+	BalanceRoundRobin Balance = "round-robin"
+
+	// BalanceLeastPending calls the instance with the fewest calls in
+	// progress, suiting agents whose calls vary widely in length.
+	BalanceLeastPending Balance = "least-pending"
+)
+
+// PoolConfig configures NewClientPool. Set URLs for a static list of
+// instances, or Discovery to find them; both may be set.
+type PoolConfig struct {
+	// URLs are the base URLs of the instances, as passed to NewClient.
+	URLs []string
+
+	// Discovery finds the instances: the agents matching Query, and named
+	// Agent if it is set.
+	Discovery *DiscoveryClient
+	Query     AgentQuery
+	Agent     string
+
+	// RefreshInterval is how often the pool looks the instances up on
+	// Discovery and retries static URLs whose agent card it could not
+	// fetch.
+	// Default: 30 seconds
+	RefreshInterval time.Duration
+
+	// Balance selects the instance for calls that start a new task.
+	// Supported: BalanceRoundRobin, BalanceLeastPending
+	// Default: BalanceRoundRobin
+	Balance Balance
+
+	// Client configures the client of each instance. Its
+	// Breaker.FailureThreshold consecutive failures eject an instance, and
+	// after Breaker.Cooldown the pool sends it one call to check it has
+	// recovered.
+	Client ClientConfig
+}
+
+// ClientPool calls the instances of one logical agent, such as the
+// replicas of a scaled-out downstream agent. Calls that start a task go
+// to an instance selected by the pool's Balance; calls about a task go to
+// the instance that started it while that instance is healthy, since
+// tasks are kept in memory by default (see Config.TaskStore). Instances
+// whose circuit breaker is open are ejected from selection, and calls
+// refused by an ejected instance move to another.
+type ClientPool struct {
+	cfg    PoolConfig
+	next   atomic.Uint64
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu        sync.Mutex
+	instances []*poolInstance // sorted by key
+	tasks     map[a2a.TaskID]*poolInstance
+}
+
+// poolInstance is an instance of a ClientPool.
+type poolInstance struct {
+	// key is the static URL or card URL of the instance.
+	key     string
+	client  *Client
+	pending atomic.Int64
+}
+
+// maxPoolTasks caps the tasks a ClientPool routes to their instance.
+// Finished tasks are forgotten; beyond the cap, arbitrary ones are.
+const maxPoolTasks = 10000
+
+// NewClientPool creates a pool and connects to the instances it can
+// reach. It fails if it reaches none; it keeps looking for instances
+// until Close.
+func NewClientPool(ctx context.Context, cfg PoolConfig) (*ClientPool, error) {
+	if len(cfg.URLs) == 0 && cfg.Discovery == nil {
+		return nil, errors.New("client pool needs URLs or Discovery")
+	}
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = 30 * time.Second
+	}
+	switch cfg.Balance {
+	case "":
+		cfg.Balance = BalanceRoundRobin
+	case BalanceRoundRobin, BalanceLeastPending:
+	default:
+		return nil, fmt.Errorf("unsupported balance %q", cfg.Balance)
+	}
+
+	p := &ClientPool{cfg: cfg, done: make(chan struct{}), tasks: make(map[a2a.TaskID]*poolInstance)}
+	if err := p.refresh(ctx); err != nil && len(p.instances) == 0 {
+		return nil, err
+	}
+	if len(p.instances) == 0 {
+		return nil, ErrNoInstances
+	}
+
+	refreshCtx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	go p.watch(refreshCtx)
+	return p, nil
+}
+
+// Close stops looking for instances and closes their connections.
+func (p *ClientPool) Close() error {
+	p.cancel()
+	<-p.done
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var errs []error
+	for _, inst := range p.instances {
+		errs = append(errs, inst.client.Destroy())
+	}
+	p.instances = nil
+	clear(p.tasks)
+	return errors.Join(errs...)
+}
+
+// Instances returns the keys of the instances, with whether each is
+// healthy.
+func (p *ClientPool) Instances() map[string]bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	instances := make(map[string]bool, len(p.instances))
+	for _, inst := range p.instances {
+		instances[inst.key] = !inst.client.breaker.Open()
+	}
+	return instances
+}
+
+// watch refreshes the instances every RefreshInterval until ctx ends.
+func (p *ClientPool) watch(ctx context.Context) {
+	defer close(p.done)
+	ticker := time.NewTicker(p.cfg.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := p.refresh(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("[A2A] client pool refresh failed: %v", err)
+			}
+		}
+	}
+}
+
+// refresh connects to static URLs not yet connected and syncs the
+// instances with Discovery.
+func (p *ClientPool) refresh(ctx context.Context) error {
+	p.mu.Lock()
+	known := make(map[string]*poolInstance, len(p.instances))
+	for _, inst := range p.instances {
+		known[inst.key] = inst
+	}
+	p.mu.Unlock()
+
+	var errs []error
+	keep := make(map[string]*poolInstance)
+	for _, url := range p.cfg.URLs {
+		if inst, ok := known[url]; ok {
+			keep[url] = inst
+			continue
+		}
+		client, err := NewClient(ctx, url, p.cfg.Client)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", url, err))
+			continue
+		}
+		keep[url] = &poolInstance{key: url, client: client}
+	}
+
+	if p.cfg.Discovery != nil {
+		cards, err := p.cfg.Discovery.Find(ctx, p.cfg.Query)
+		if err != nil {
+			// Keep the discovered instances until discovery answers.
+			errs = append(errs, err)
+			for key, inst := range known {
+				if !slices.Contains(p.cfg.URLs, key) {
+					keep[key] = inst
+				}
+			}
+		}
+		for _, card := range cards {
+			if (p.cfg.Agent != "" && card.Name != p.cfg.Agent) || keep[card.URL] != nil {
+				continue
+			}
+			if inst, ok := known[card.URL]; ok {
+				keep[card.URL] = inst
+				continue
+			}
+			client, err := NewClientFromCard(ctx, card, p.cfg.Client)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", card.URL, err))
+				continue
+			}
+			keep[card.URL] = &poolInstance{key: card.URL, client: client}
+		}
+	}
+
+	instances := make([]*poolInstance, 0, len(keep))
+	for _, inst := range keep {
+		instances = append(instances, inst)
+	}
+	slices.SortFunc(instances, func(a, b *poolInstance) int { return strings.Compare(a.key, b.key) })
+
+	p.mu.Lock()
+	p.instances = instances
+	for id, inst := range p.tasks {
+		if keep[inst.key] != inst {
+			delete(p.tasks, id)
+		}
+	}
+	p.mu.Unlock()
+
+	// Close the instances that are gone; their calls in progress fail.
+	for key, inst := range known {
+		if keep[key] != inst {
+			_ = inst.client.Destroy()
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// pick returns the instance to call about taskID, which may be empty,
+// skipping the instances in tried.
+func (p *ClientPool) pick(taskID a2a.TaskID, tried []*poolInstance) (*poolInstance, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if inst, ok := p.tasks[taskID]; ok && !slices.Contains(tried, inst) && !inst.client.breaker.Open() {
+		return inst, nil
+	}
+	var healthy []*poolInstance
+	for _, inst := range p.instances {
+		if !slices.Contains(tried, inst) && !inst.client.breaker.Open() {
+			healthy = append(healthy, inst)
+		}
+	}
+	if len(healthy) == 0 {
+		return nil, ErrNoInstances
+	}
+
+	start := int(p.next.Add(1) % uint64(len(healthy)))
+	if p.cfg.Balance == BalanceRoundRobin {
+		return healthy[start], nil
+	}
+	best := healthy[start]
+	for i := 1; i < len(healthy); i++ {
+		if inst := healthy[(start+i)%len(healthy)]; inst.pending.Load() < best.pending.Load() {
+			best = inst
+		}
+	}
+	return best, nil
+}
+
+// track routes later calls about the task of result to inst, until the
+// task finishes.
+func (p *ClientPool) track(inst *poolInstance, result a2a.Event) {
+	var taskID a2a.TaskID
+	var state a2a.TaskState
+	switch r := result.(type) {
+	case *a2a.Task:
+		taskID, state = r.ID, r.Status.State
+	case *a2a.TaskStatusUpdateEvent:
+		taskID, state = r.TaskID, r.Status.State
+	case *a2a.Message:
+		taskID = r.TaskID
+	default:
+		return
+	}
+	if taskID == "" {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if state.Terminal() {
+		delete(p.tasks, taskID)
+		return
+	}
+	if _, ok := p.tasks[taskID]; !ok && len(p.tasks) >= maxPoolTasks {
+		for id := range p.tasks {
+			delete(p.tasks, id)
+			break
+		}
+	}
+	p.tasks[taskID] = inst
+}
+
+// poolCall calls an instance about taskID, moving to another instance if
+// the call is refused because the circuit of the first is open.
+func poolCall[T any](ctx context.Context, p *ClientPool, taskID a2a.TaskID, call func(ctx context.Context, inst *poolInstance) (T, error)) (T, error) {
+	var tried []*poolInstance
+	var lastErr error
+	for {
+		inst, err := p.pick(taskID, tried)
+		if err != nil {
+			var zero T
+			if lastErr != nil {
+				return zero, lastErr
+			}
+			return zero, err
+		}
+		inst.pending.Add(1)
+		result, err := call(ctx, inst)
+		inst.pending.Add(-1)
+		if !errors.Is(err, ErrCircuitOpen) {
+			return result, err
+		}
+		tried, lastErr = append(tried, inst), err
+	}
+}
+
+// poolStream streams from an instance about taskID, moving to another
+// instance if the stream is refused because the circuit of the first is
+// open.
+func (p *ClientPool) poolStream(ctx context.Context, taskID a2a.TaskID, stream func(c *Client) iter.Seq2[a2a.Event, error]) iter.Seq2[a2a.Event, error] {
+	return func(yield func(a2a.Event, error) bool) {
+		var tried []*poolInstance
+		var lastErr error
+		for {
+			inst, err := p.pick(taskID, tried)
+			if err != nil {
+				if lastErr != nil {
+					err = lastErr
+				}
+				yield(nil, err)
+				return
+			}
+
+			inst.pending.Add(1)
+			refused := false
+			first := true
+			for event, err := range stream(inst.client) {
+				if first && errors.Is(err, ErrCircuitOpen) {
+					refused = true
+					break
+				}
+				first = false
+				if err == nil {
+					p.track(inst, event)
+				}
+				if !yield(event, err) {
+					break
+				}
+			}
+			inst.pending.Add(-1)
+			if !refused {
+				return
+			}
+			tried, lastErr = append(tried, inst), ErrCircuitOpen
+		}
+	}
+}
+
+// SendMessage sends a message to an instance: for a new task one selected
+// by the pool's Balance, otherwise the one that started the task.
+func (p *ClientPool) SendMessage(ctx context.Context, message *a2a.MessageSendParams) (a2a.SendMessageResult, error) {
+	var taskID a2a.TaskID
+	if message != nil && message.Message != nil {
+		taskID = message.Message.TaskID
+	}
+	return poolCall(ctx, p, taskID, func(ctx context.Context, inst *poolInstance) (a2a.SendMessageResult, error) {
+		result, err := inst.client.SendMessage(ctx, message)
+		if err == nil {
+			p.track(inst, result)
+		}
+		return result, err
+	})
+}
+
+// SendStreamingMessage is SendMessage with streaming.
+func (p *ClientPool) SendStreamingMessage(ctx context.Context, message *a2a.MessageSendParams) iter.Seq2[a2a.Event, error] {
+	var taskID a2a.TaskID
+	if message != nil && message.Message != nil {
+		taskID = message.Message.TaskID
+	}
+	return p.poolStream(ctx, taskID, func(c *Client) iter.Seq2[a2a.Event, error] {
+		return c.SendStreamingMessage(ctx, message)
+	})
+}
+
+// ResubscribeToTask streams the events of a task.
+func (p *ClientPool) ResubscribeToTask(ctx context.Context, id *a2a.TaskIDParams) iter.Seq2[a2a.Event, error] {
+	return p.poolStream(ctx, id.ID, func(c *Client) iter.Seq2[a2a.Event, error] {
+		return c.ResubscribeToTask(ctx, id)
+	})
+}
+
+// GetTask returns a task.
+func (p *ClientPool) GetTask(ctx context.Context, query *a2a.TaskQueryParams) (*a2a.Task, error) {
+	return poolCall(ctx, p, query.ID, func(ctx context.Context, inst *poolInstance) (*a2a.Task, error) {
+		task, err := inst.client.GetTask(ctx, query)
+		if err == nil {
+			p.track(inst, task)
+		}
+		return task, err
+	})
+}
+
+// CancelTask cancels a task.
+func (p *ClientPool) CancelTask(ctx context.Context, id *a2a.TaskIDParams) (*a2a.Task, error) {
+	return poolCall(ctx, p, id.ID, func(ctx context.Context, inst *poolInstance) (*a2a.Task, error) {
+		task, err := inst.client.CancelTask(ctx, id)
+		if err == nil {
+			p.track(inst, task)
+		}
+		return task, err
+	})
+}
+
+// SetTaskPushConfig sets a push notification config of a task.
+func (p *ClientPool) SetTaskPushConfig(ctx context.Context, params *a2a.TaskPushConfig) (*a2a.TaskPushConfig, error) {
+	return poolCall(ctx, p, params.TaskID, func(ctx context.Context, inst *poolInstance) (*a2a.TaskPushConfig, error) {
+		return inst.client.SetTaskPushConfig(ctx, params)
+	})
+}
+
+// GetTaskPushConfig returns a push notification config of a task.
+func (p *ClientPool) GetTaskPushConfig(ctx context.Context, params *a2a.GetTaskPushConfigParams) (*a2a.TaskPushConfig, error) {
+	return poolCall(ctx, p, params.TaskID, func(ctx context.Context, inst *poolInstance) (*a2a.TaskPushConfig, error) {
+		return inst.client.GetTaskPushConfig(ctx, params)
+	})
+}
+
+// ListTaskPushConfig returns the push notification configs of a task.
+func (p *ClientPool) ListTaskPushConfig(ctx context.Context, params *a2a.ListTaskPushConfigParams) ([]*a2a.TaskPushConfig, error) {
+	return poolCall(ctx, p, params.TaskID, func(ctx context.Context, inst *poolInstance) ([]*a2a.TaskPushConfig, error) {
+		return inst.client.ListTaskPushConfig(ctx, params)
+	})
+}
+
+// DeleteTaskPushConfig deletes a push notification config of a task.
+func (p *ClientPool) DeleteTaskPushConfig(ctx context.Context, params *a2a.DeleteTaskPushConfigParams) error {
+	_, err := poolCall(ctx, p, params.TaskID, func(ctx context.Context, inst *poolInstance) (struct{}, error) {
+		return struct{}{}, inst.client.DeleteTaskPushConfig(ctx, params)
+	})
+	return err
+}
+
+// GetAgentCard returns the agent card of an instance.
+func (p *ClientPool) GetAgentCard(ctx context.Context) (*a2a.AgentCard, error) {
+	return poolCall(ctx, p, "", func(ctx context.Context, inst *poolInstance) (*a2a.AgentCard, error) {
+		return inst.client.GetAgentCard(ctx)
+	})
+}
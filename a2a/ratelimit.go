@@ -0,0 +1,222 @@
+package a2a
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// RateLimit limits how often each client may call the agent, with a token
+// bucket per client. Clients that Config.Auth authenticated are identified
+// by their API key; others, and clients of a server without Auth, by IP
+// address, since an unchecked key could be made up for every call.
+type RateLimit struct {
+	// Rate is the sustained number of requests per second each client may
+	// make. Required.
+	Rate float64
+
+	// Burst is the number of requests a client may make at once after
+	// being idle.
+	// Default: Rate rounded up, at least 1
+	Burst int
+
+	// APIKeyHeader is the header, or gRPC metadata key, that carries the
+	// client's API key.
+	// Default: "X-API-Key"
+	APIKeyHeader string
+
+	// TrustProxy takes the client IP from the first X-Forwarded-For
+	// address. Set it only behind a proxy that sets the header, since
+	// clients can forge it.
+	// Default: false
+	TrustProxy bool
+}
+
+// RateLimiter enforces a RateLimit. Rejected HTTP requests get 429 Too
+// Many Requests with a Retry-After header; rejected gRPC calls get
+// ResourceExhausted with a retry-after trailer.
+type RateLimiter struct {
+	limit RateLimit
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// maxRateLimitBuckets caps the clients a RateLimiter tracks. Beyond it,
+// new clients share one bucket until the sweep forgets idle ones.
+const maxRateLimitBuckets = 100000
+
+// overflowClient is the client of the shared bucket of maxRateLimitBuckets.
+const overflowClient = "overflow"
+
+// authenticatedKey is the context key marking calls Config.Auth accepted.
+type authenticatedKey struct{}
+
+// markAuthenticated marks the context of a call that passed
+// authentication, so the rate limiter may trust its API key.
+func markAuthenticated(ctx context.Context) context.Context {
+	return context.WithValue(ctx, authenticatedKey{}, true)
+}
+
+// authenticated reports whether ctx was marked by markAuthenticated.
+func authenticated(ctx context.Context) bool {
+	ok, _ := ctx.Value(authenticatedKey{}).(bool)
+	return ok
+}
+
+// bucket is the token bucket of a client.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a limiter for limit.
+func NewRateLimiter(limit RateLimit) *RateLimiter {
+	if limit.Burst <= 0 {
+		limit.Burst = max(1, int(math.Ceil(limit.Rate)))
+	}
+	if limit.APIKeyHeader == "" {
+		limit.APIKeyHeader = "X-API-Key"
+	}
+	return &RateLimiter{limit: limit, buckets: make(map[string]*bucket), lastSweep: time.Now()}
+}
+
+// Allow takes a token from the client's bucket. If there is none, it
+// reports false and how long until there is.
+func (l *RateLimiter) Allow(client string) (bool, time.Duration) {
+	now := time.Now()
+	burst := float64(l.limit.Burst)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Forget clients whose buckets have refilled, so the map does not
+	// grow with every client ever seen; sooner once it is full.
+	sweepEvery := time.Minute
+	if len(l.buckets) >= maxRateLimitBuckets {
+		sweepEvery = time.Second
+	}
+	if now.Sub(l.lastSweep) > sweepEvery {
+		for key, b := range l.buckets {
+			if b.tokens+now.Sub(b.last).Seconds()*l.limit.Rate >= burst {
+				delete(l.buckets, key)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[client]
+	if !ok && len(l.buckets) >= maxRateLimitBuckets {
+		client = overflowClient
+		b, ok = l.buckets[client]
+	}
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		l.buckets[client] = b
+	}
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*l.limit.Rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	if l.limit.Rate <= 0 {
+		return false, time.Hour
+	}
+	return false, time.Duration((1 - b.tokens) / l.limit.Rate * float64(time.Second))
+}
+
+// Wrap returns next limited per client.
+func (l *RateLimiter) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ok, wait := l.Allow(l.httpClient(r))
+		if !ok {
+			w.Header().Set("Retry-After", retryAfter(wait))
+			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// httpClient identifies the client of an HTTP request: by API key if it
+// was authenticated, and by IP otherwise.
+func (l *RateLimiter) httpClient(r *http.Request) string {
+	if key := r.Header.Get(l.limit.APIKeyHeader); key != "" && authenticated(r.Context()) {
+		return "key:" + key
+	}
+	if l.limit.TrustProxy {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			ip, _, _ := strings.Cut(forwarded, ",")
+			return "ip:" + strings.TrimSpace(ip)
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// UnaryInterceptor limits unary gRPC calls.
+func (l *RateLimiter) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := l.allowGRPC(ctx); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor limits streaming gRPC calls.
+func (l *RateLimiter) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := l.allowGRPC(ss.Context()); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// allowGRPC takes a token for the client of a gRPC call, identified like
+// the client of an HTTP request.
+func (l *RateLimiter) allowGRPC(ctx context.Context) error {
+	client := ""
+	md, _ := metadata.FromIncomingContext(ctx)
+	if keys := md.Get(l.limit.APIKeyHeader); len(keys) > 0 && keys[0] != "" && authenticated(ctx) {
+		client = "key:" + keys[0]
+	} else if l.limit.TrustProxy && len(md.Get("x-forwarded-for")) > 0 {
+		ip, _, _ := strings.Cut(md.Get("x-forwarded-for")[0], ",")
+		client = "ip:" + strings.TrimSpace(ip)
+	} else if p, ok := peer.FromContext(ctx); ok {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		client = "ip:" + host
+	}
+
+	ok, wait := l.Allow(client)
+	if ok {
+		return nil
+	}
+	_ = grpc.SetTrailer(ctx, metadata.Pairs("retry-after", retryAfter(wait)))
+	return status.Error(codes.ResourceExhausted, "rate limit exceeded")
+}
+
+// retryAfter formats a wait as whole seconds, rounded up.
+func retryAfter(wait time.Duration) string {
+	return strconv.Itoa(max(1, int(math.Ceil(wait.Seconds()))))
+}
@@ -0,0 +1,124 @@
+package a2a
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+
+	"github.com/plexusone/agentkit/httpserver"
+)
+
+// ReadyCheck reports whether a dependency of the server is ready.
+type ReadyCheck = httpserver.ReadyCheck
+
+// ModelCheck returns a ReadyCheck that sends m a one-token request and
+// fails if the model returns an error. A completed probe is reused for
+// interval, so frequent probes cost one request per interval; concurrent
+// probes share it.
+func ModelCheck(m model.LLM, interval time.Duration) ReadyCheck {
+	var (
+		mu      sync.Mutex
+		checked time.Time
+		result  error
+	)
+	return func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if !checked.IsZero() && time.Since(checked) < interval {
+			return result
+		}
+
+		req := &model.LLMRequest{
+			Model:    m.Name(),
+			Contents: []*genai.Content{genai.NewContentFromText("ping", genai.RoleUser)},
+			Config:   &genai.GenerateContentConfig{MaxOutputTokens: 1},
+		}
+		result = nil
+		for _, err := range m.GenerateContent(ctx, req, false) {
+			if err != nil {
+				result = fmt.Errorf("model %s: %w", m.Name(), err)
+				break
+			}
+		}
+		if ctx.Err() == nil {
+			// A probe cut short by its deadline is retried next time.
+			checked = time.Now()
+		}
+		return result
+	}
+}
+
+// AgentCheck returns a ReadyCheck that fails unless the downstream A2A
+// agent at baseURL serves its agent card, which any A2A server does. For
+// agents called through a Client or ClientPool, their Ready method also
+// checks that calls get through.
+// Default client: http.DefaultClient
+func AgentCheck(baseURL string, client *http.Client) ReadyCheck {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	cardURL := strings.TrimSuffix(baseURL, "/") + a2asrv.WellKnownAgentCardPath
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, cardURL, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("agent %s: %w", baseURL, err)
+		}
+		defer resp.Body.Close()
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("agent %s: agent card: %s", baseURL, resp.Status)
+		}
+		return nil
+	}
+}
+
+// Ready reports whether the agent answers calls, for use as a ReadyCheck
+// of agents that depend on it. It fails at once while the circuit is
+// open; otherwise it asks the agent, without retries, for a task that
+// does not exist, which a healthy agent reports as not found.
+func (c *Client) Ready(ctx context.Context) error {
+	if c.breaker.Open() {
+		return fmt.Errorf("%s: %w", c.name, ErrCircuitOpen)
+	}
+	_, err := c.Client.GetTask(ctx, &a2a.TaskQueryParams{ID: "agentkit-readyz"})
+	if err != nil && !errors.Is(err, a2a.ErrTaskNotFound) {
+		return fmt.Errorf("%s: %w", c.name, err)
+	}
+	return nil
+}
+
+// Ready reports whether any instance of the pool is ready (see
+// Client.Ready), for use as a ReadyCheck of agents that depend on it.
+func (p *ClientPool) Ready(ctx context.Context) error {
+	p.mu.Lock()
+	instances := slices.Clone(p.instances)
+	p.mu.Unlock()
+
+	var errs []error
+	for _, inst := range instances {
+		err := inst.client.Ready(ctx)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return ErrNoInstances
+	}
+	return fmt.Errorf("%w: %w", ErrNoInstances, errors.Join(errs...))
+}
@@ -0,0 +1,338 @@
+package a2a
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	agenthttp "github.com/plexusone/agentkit/http"
+)
+
+// ErrCircuitOpen is returned without calling the agent while its circuit
+// breaker is open. It is http.ErrCircuitOpen.
+var ErrCircuitOpen = agenthttp.ErrCircuitOpen
+
+// RetryPolicy configures how a Client retries calls. It is the shared
+// http.RetryPolicy, whose Retryable defaults to http.IsTransient, which
+// reports the client's own classification: connection errors, HTTP 429 and
+// 5xx responses, and the gRPC codes Unavailable and ResourceExhausted are
+// transient, while other errors, such as an unknown task, are not. A
+// Retryable of your own sees the same errors. A Retry-After from the
+// server takes precedence over the backoff, and OnRetry is called before
+// each wait. Whatever Retryable reports, a send is not repeated when the
+// agent may already have its message.
+type RetryPolicy = agenthttp.RetryPolicy
+
+// CircuitBreakerConfig configures the circuit breaker of a Client, which
+// counts calls that fail for transient reasons after retries. It is the
+// shared http.CircuitBreakerConfig.
+type CircuitBreakerConfig = agenthttp.CircuitBreakerConfig
+
+// attempt collects what the transports observe about one try of a call,
+// which their errors do not carry.
+type attempt struct {
+	mu sync.Mutex
+	// transient is set for failures worth retrying.
+	transient bool
+	// rejected is set when the server certainly did not process the
+	// request, so resending it cannot duplicate work.
+	rejected   bool
+	retryAfter time.Duration
+}
+
+type attemptKey struct{}
+
+// withAttempt returns a context that records into a new attempt.
+func withAttempt(ctx context.Context) (context.Context, *attempt) {
+	at := &attempt{}
+	return context.WithValue(ctx, attemptKey{}, at), at
+}
+
+// fail marks the attempt of ctx, if any, as failed transiently.
+func fail(ctx context.Context, rejected bool, retryAfter time.Duration) {
+	at, ok := ctx.Value(attemptKey{}).(*attempt)
+	if !ok {
+		return
+	}
+	at.mu.Lock()
+	defer at.mu.Unlock()
+	at.transient = true
+	at.rejected = at.rejected || rejected
+	at.retryAfter = max(at.retryAfter, retryAfter)
+}
+
+// result returns what the attempt recorded.
+func (at *attempt) result() (transient, rejected bool, retryAfter time.Duration) {
+	at.mu.Lock()
+	defer at.mu.Unlock()
+	return at.transient, at.rejected, at.retryAfter
+}
+
+// retryRoundTripper records transient JSON-RPC failures in the attempt of
+// the request context.
+type retryRoundTripper struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (rt retryRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	resp, err := rt.base.RoundTrip(req)
+	if err != nil {
+		if ctx.Err() == nil {
+			var opErr *net.OpError
+			fail(ctx, errors.As(err, &opErr) && opErr.Op == "dial", 0)
+		}
+		return nil, err
+	}
+	switch code := resp.StatusCode; {
+	case code == http.StatusTooManyRequests, code == http.StatusServiceUnavailable:
+		fail(ctx, true, agenthttp.ParseRetryAfter(resp.Header.Get("Retry-After")))
+	case code >= 500:
+		fail(ctx, false, 0)
+	default:
+		resp.Body = retryBody{ReadCloser: resp.Body, ctx: ctx}
+	}
+	return resp, nil
+}
+
+// retryBody records a connection lost while reading a response, such as
+// a stream.
+type retryBody struct {
+	io.ReadCloser
+	ctx context.Context
+}
+
+// Read implements io.Reader.
+func (b retryBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF && b.ctx.Err() == nil {
+		fail(b.ctx, false, 0)
+	}
+	return n, err
+}
+
+// grpcFailure records a transient gRPC failure in the attempt of ctx.
+func grpcFailure(ctx context.Context, err error, trailer metadata.MD, started bool) {
+	if err == nil || ctx.Err() != nil {
+		return
+	}
+	switch status.Code(err) {
+	case codes.Unavailable:
+		fail(ctx, !started, 0)
+	case codes.ResourceExhausted:
+		var retryAfter time.Duration
+		if values := trailer.Get("retry-after"); len(values) > 0 {
+			retryAfter = agenthttp.ParseRetryAfter(values[0])
+		}
+		fail(ctx, !started, retryAfter)
+	}
+}
+
+// retryUnaryInterceptor records transient failures of unary gRPC calls.
+func retryUnaryInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	var trailer metadata.MD
+	err := invoker(ctx, method, req, reply, cc, append(opts, grpc.Trailer(&trailer))...)
+	grpcFailure(ctx, err, trailer, false)
+	return err
+}
+
+// retryStreamInterceptor records transient failures of gRPC streams.
+func retryStreamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		grpcFailure(ctx, err, nil, false)
+		return nil, err
+	}
+	return &retryStream{ClientStream: stream, ctx: ctx}, nil
+}
+
+// retryStream records the failure of a gRPC stream.
+type retryStream struct {
+	grpc.ClientStream
+	ctx      context.Context
+	received bool
+}
+
+// RecvMsg implements grpc.ClientStream.
+func (s *retryStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil && err != io.EOF {
+		grpcFailure(s.ctx, err, s.Trailer(), s.received)
+	}
+	s.received = s.received || err == nil
+	return err
+}
+
+// attemptError is the error of one try of a call, with what the
+// transports observed about it.
+type attemptError struct {
+	err        error
+	transient  bool
+	retryAfter time.Duration
+	// resend is false when trying again could run a message twice.
+	resend bool
+}
+
+func (e *attemptError) Error() string             { return e.err.Error() }
+func (e *attemptError) Unwrap() error             { return e.err }
+func (e *attemptError) Transient() bool           { return e.transient }
+func (e *attemptError) RetryAfter() time.Duration { return e.retryAfter }
+
+// policy returns the client's retry policy, which never retries a try
+// that must not be resent.
+func (c *Client) policy() RetryPolicy {
+	p := c.retry
+	retryable := p.Retryable
+	p.Retryable = func(err error) bool {
+		var ae *attemptError
+		return errors.As(err, &ae) && ae.resend && retryable(err)
+	}
+	return p
+}
+
+// finish records the outcome of a call in the circuit breaker, which
+// counts only transient failures, and returns its error unwrapped.
+func (c *Client) finish(err error) error {
+	var ae *attemptError
+	if !errors.As(err, &ae) {
+		c.breaker.Record(false)
+		return err
+	}
+	c.breaker.Record(ae.transient)
+	return ae.err
+}
+
+// retry runs call under the client's retry policy. Only failures for
+// which resend reports true are retried.
+func retry[T any](ctx context.Context, c *Client, resend func(rejected bool) bool, call func(ctx context.Context) (T, error)) (T, error) {
+	var result T
+	if err := c.breaker.Allow(); err != nil {
+		return result, fmt.Errorf("%s: %w", c.name, err)
+	}
+	err := c.policy().Do(ctx, func(ctx context.Context) error {
+		attemptCtx, at := withAttempt(ctx)
+		r, err := call(attemptCtx)
+		if err == nil {
+			result = r
+			return nil
+		}
+		transient, rejected, retryAfter := at.result()
+		return &attemptError{err: err, transient: transient, retryAfter: retryAfter, resend: resend(rejected)}
+	})
+	return result, c.finish(err)
+}
+
+// always allows every retry, for calls that are safe to repeat.
+func always(bool) bool { return true }
+
+// hasMessage reports whether the agent recorded the message in the task,
+// so a send whose outcome is unknown must not be repeated.
+func hasMessage(task *a2a.Task, messageID string) bool {
+	for _, msg := range task.History {
+		if msg != nil && msg.ID == messageID {
+			return true
+		}
+	}
+	return false
+}
+
+// withMessageID returns params with a message ID, so that retries and the
+// task history refer to the same message.
+func withMessageID(params *a2a.MessageSendParams) *a2a.MessageSendParams {
+	if params == nil || params.Message == nil || params.Message.ID != "" {
+		return params
+	}
+	msg := *params.Message
+	msg.ID = a2a.NewMessageID()
+	copied := *params
+	copied.Message = &msg
+	return &copied
+}
+
+// interrupted reports whether a task has stopped, for good or for input.
+func interrupted(state a2a.TaskState) bool {
+	return state.Terminal() || state == a2a.TaskStateInputRequired || state == a2a.TaskStateAuthRequired
+}
+
+// stream runs a streaming send, or resubscribes to taskID when params is
+// nil, and carries on after retried failures. Once the agent has the
+// message, it resubscribes to the task, or returns the task if it has
+// stopped; before that, it resends only when the agent certainly did not
+// get the message or its task history shows it did not.
+func (c *Client) stream(ctx context.Context, params *a2a.MessageSendParams, taskID a2a.TaskID) iter.Seq2[a2a.Event, error] {
+	return func(yield func(a2a.Event, error) bool) {
+		if err := c.breaker.Allow(); err != nil {
+			yield(nil, fmt.Errorf("%s: %w", c.name, err))
+			return
+		}
+		// resume resubscribes instead of sending; lookup fetches the task
+		// before the next try.
+		resume, lookup := params == nil, false
+		err := c.policy().Do(ctx, func(ctx context.Context) error {
+			attemptCtx, at := withAttempt(ctx)
+			var err error
+			var task *a2a.Task
+			if lookup {
+				task, err = c.Client.GetTask(attemptCtx, &a2a.TaskQueryParams{ID: taskID})
+				if err == nil {
+					lookup = false
+					resume = resume || hasMessage(task, params.Message.ID)
+				}
+			}
+			if err == nil && resume && task != nil && interrupted(task.Status.State) {
+				yield(task, nil)
+				return nil
+			}
+
+			if err == nil {
+				var events iter.Seq2[a2a.Event, error]
+				if resume {
+					events = c.Client.ResubscribeToTask(attemptCtx, &a2a.TaskIDParams{ID: taskID})
+				} else {
+					events = c.Client.SendStreamingMessage(attemptCtx, params)
+				}
+				for event, eventErr := range events {
+					if eventErr != nil {
+						err = eventErr
+						break
+					}
+					if id := event.TaskInfo().TaskID; id != "" {
+						taskID, resume = id, true
+					}
+					if !yield(event, nil) {
+						return nil
+					}
+				}
+				if err == nil {
+					return nil
+				}
+			}
+
+			transient, rejected, retryAfter := at.result()
+			resend := true
+			if resume || !rejected {
+				// Without a task to look up, the agent may or may not
+				// have created one.
+				resend = taskID != ""
+				lookup = resend
+			}
+			return &attemptError{err: err, transient: transient, retryAfter: retryAfter, resend: resend}
+		})
+		if err = c.finish(err); err != nil {
+			yield(nil, err)
+		}
+	}
+}
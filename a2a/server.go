@@ -4,26 +4,46 @@ package a2a
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
 	"net/url"
+	"os"
 	"time"
 
 	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2agrpc"
 	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/push"
 	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
 	"google.golang.org/adk/runner"
 	"google.golang.org/adk/server/adka2a"
 	"google.golang.org/adk/session"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/plexusone/agentkit/httpserver"
+	"github.com/plexusone/agentkit/platforms/agentcore"
 )
 
 // Config holds the configuration for an A2A server.
 type Config struct {
-	// Agent is the ADK agent to expose via A2A protocol.
+	// Agent is the ADK agent to expose via A2A protocol. Either Agent or
+	// Executor is required.
 	Agent agent.Agent
 
+	// Executor runs the agent's tasks instead of an ADK agent, e.g. an
+	// AgentCoreExecutor or an SSEExecutor. It requires Name.
+	Executor a2asrv.AgentExecutor
+
+	// Name is the agent's name in the agent card.
+	// Default: the ADK agent's name
+	Name string
+
 	// Port is the port to listen on. If empty, a random port is used.
 	Port string
 
@@ -31,38 +51,285 @@ type Config struct {
 	// If empty, uses the agent's built-in description.
 	Description string
 
+	// Version is the agent's own version, in a format of its choosing.
+	// Default: "1.0.0"
+	Version string
+
+	// Skills describes what the agent can do, with examples of requests,
+	// in the agent card. Every skill needs an ID and a Name.
+	// If empty, one skill is derived from the ADK agent and each sub-agent,
+	// or from Name and Description for an Executor.
+	Skills []a2a.AgentSkill
+
+	// DefaultInputModes and DefaultOutputModes are the MIME types the agent
+	// accepts and produces, unless a skill overrides them.
+	// Default: ["text/plain"]
+	DefaultInputModes  []string
+	DefaultOutputModes []string
+
+	// Provider identifies the organization that runs the agent.
+	// Default: nil (omitted from the card)
+	Provider *a2a.AgentProvider
+
+	// DocumentationURL and IconURL link to the agent's documentation and
+	// icon in the agent card.
+	DocumentationURL string
+	IconURL          string
+
+	// SecuritySchemes declares, by name, how clients authenticate, e.g.
+	// a2a.HTTPAuthSecurityScheme{Scheme: "Bearer"}. Security lists the
+	// combinations of them that the agent accepts; each entry is one
+	// alternative whose schemes are all required. The server advertises
+	// them but does not enforce them; set Auth to do so.
+	SecuritySchemes a2a.NamedSecuritySchemes
+	Security        []a2a.SecurityRequirements
+
+	// Auth authenticates calls to the invoke, SSE and metrics endpoints,
+	// e.g. httpserver.AuthFromConfig of the agent's config, so A2A calls
+	// and plain HTTP endpoints accept the same credentials. The agent
+	// card and health endpoints stay public. gRPC calls are checked the
+	// same way, with their authorization and x-api-key metadata as
+	// headers.
+	// Default: nil (unauthenticated)
+	Auth httpserver.Middleware
+
+	// LivePath serves liveness for orchestrators: 200 OK while the process
+	// serves HTTP. /health remains as an alias.
+	// Default: "/livez"
+	LivePath string
+
+	// ReadyPath serves readiness: 200 when every check passes, otherwise
+	// 503 Service Unavailable, with the result of each check as JSON.
+	// Default: "/readyz"
+	ReadyPath string
+
+	// ReadyChecks are run by the readiness endpoint, keyed by name, in
+	// addition to the built-in checks: "session" calls HealthCheck of a
+	// SessionService that implements agentcore.HealthChecker, such as
+	// RedisSessionService and DynamoDBSessionService, or else lists
+	// sessions (ADK agents only), "executor" calls HealthCheck of an
+	// Executor that implements agentcore.HealthChecker, and "model"
+	// probes Model. See AgentCheck and Client.Ready for downstream
+	// agents.
+	ReadyChecks map[string]ReadyCheck
+
+	// ReadyTimeout bounds each readiness check.
+	// Default: 5 seconds
+	ReadyTimeout time.Duration
+
+	// Model is the model the agent calls. When set, readiness sends it a
+	// one-token request (see ModelCheck).
+	Model model.LLM
+
+	// ModelCheckInterval is how long a model probe result is reused, so
+	// frequent readiness probes do not run up model usage.
+	// Default: 1 minute
+	ModelCheckInterval time.Duration
+
+	// DrainTimeout is how long Stop waits for running tasks to finish. New
+	// tasks are rejected and readiness fails meanwhile; tasks still running
+	// afterwards are canceled, and their clients receive a canceled
+	// status. Stop's context can cut the wait short.
+	// Default: 30 seconds
+	DrainTimeout time.Duration
+
+	// SSEPath serves the agent as a plain SSE stream at this path (see
+	// NewSSERelay), for web frontends that do not speak JSON-RPC.
+	// Default: "" (disabled)
+	SSEPath string
+
+	// RateLimit limits each client's calls to the invoke endpoint, the
+	// SSE endpoint and the gRPC transport, so a runaway caller cannot tie
+	// up an expensive agent. Limited callers get 429 with Retry-After.
+	// With Auth, calls are authenticated first, and rejected calls get
+	// 401 without taking a token.
+	// Default: nil (unlimited)
+	RateLimit *RateLimit
+
+	// Interceptors run around every call on all transports, in order,
+	// e.g. AccessLog and Metrics.
+	Interceptors []a2asrv.CallInterceptor
+
+	// MetricsPath serves Prometheus metrics of the calls at this path: a
+	// *Metrics among Interceptors, or else one the server adds.
+	// Default: "" (disabled)
+	MetricsPath string
+
+	// DiscoveryURL is the base URL of a discovery server (see Discovery).
+	// When set, the server registers its agent card there on Start,
+	// renews the registration every DiscoveryInterval and deregisters on
+	// Stop.
+	DiscoveryURL string
+
+	// DiscoveryInterval is how often the registration is renewed. It must
+	// be shorter than the discovery server's TTL.
+	// Default: 30 seconds
+	DiscoveryInterval time.Duration
+
 	// InvokePath is the path for the invoke endpoint. Default is "/invoke".
 	InvokePath string
 
+	// GRPCPort serves the A2A gRPC transport on this port alongside
+	// JSON-RPC, for lower-latency inter-agent traffic. "0" picks a free
+	// port. It uses the server's TLS configuration, and both transports
+	// are listed in the agent card.
+	// Default: "" (JSON-RPC only)
+	GRPCPort string
+
+	// PreferredTransport is the transport the agent card prefers. Clients
+	// use it if they support it and otherwise fall back to the other.
+	// Supported: a2a.TransportProtocolJSONRPC, a2a.TransportProtocolGRPC
+	// (requires GRPCPort)
+	// Default: a2a.TransportProtocolJSONRPC
+	PreferredTransport a2a.TransportProtocol
+
 	// ReadHeaderTimeout is the timeout for reading request headers.
 	// Default is 10 seconds.
 	ReadHeaderTimeout time.Duration
 
-	// SessionService is the session service for the executor.
-	// If nil, uses in-memory session service.
+	// SessionService is the session service for the executor. Set a
+	// shared one, such as RedisSessionService or DynamoDBSessionService,
+	// so replicas continue each other's conversations. If nil, uses
+	// in-memory session service.
 	SessionService session.Service
+
+	// Streaming runs the ADK agent in streaming mode, so the responses of
+	// its model reach clients of message/stream and the SSE endpoint as
+	// artifact chunks while they are generated.
+	// Default: false (each response is sent once complete)
+	Streaming bool
+
+	// TaskStore persists tasks, so their state survives restarts and
+	// tasks/get works across replicas. See RedisTaskStore and
+	// DynamoDBTaskStore. If nil, tasks are kept in memory.
+	TaskStore TaskStore
+
+	// TLSCertFile and TLSKeyFile are PEM files with the server's
+	// certificate and private key. Setting both serves HTTPS.
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// TLSConfig serves HTTPS with a custom TLS configuration, e.g. one
+	// that reloads certificates. It takes precedence over TLSCertFile and
+	// TLSKeyFile and is not modified.
+	TLSConfig *tls.Config
+
+	// ClientCAFile is a PEM file of CA certificates. Setting it enables
+	// mutual TLS: clients must present a certificate signed by one of
+	// these CAs. It requires TLSCertFile and TLSKeyFile or TLSConfig.
+	ClientCAFile string
+
+	// PushNotifications enables A2A push notifications. Clients register
+	// a callback URL, with an optional token and credentials, for a task,
+	// and the server POSTs the task to it on every status update, so
+	// clients of long-running tasks need not hold a streaming connection.
+	// The agent card advertises the capability.
+	PushNotifications bool
+
+	// PushConfigStore stores the callbacks registered by clients.
+	// If nil, uses an in-memory store.
+	PushConfigStore a2asrv.PushConfigStore
+
+	// PushSender delivers push notifications.
+	// If nil, uses an HTTP sender with a 30 second timeout.
+	PushSender a2asrv.PushSender
+}
+
+// tlsConfig returns the TLS configuration of cfg, or nil for plain HTTP.
+func (cfg Config) tlsConfig() (*tls.Config, error) {
+	var tlsCfg *tls.Config
+	switch {
+	case cfg.TLSConfig != nil:
+		tlsCfg = cfg.TLSConfig.Clone()
+	case cfg.TLSCertFile != "" || cfg.TLSKeyFile != "":
+		if cfg.TLSCertFile == "" || cfg.TLSKeyFile == "" {
+			return nil, fmt.Errorf("TLS requires both a certificate file and a key file")
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+		tlsCfg = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		}
+	case cfg.ClientCAFile != "":
+		return nil, fmt.Errorf("client certificate verification requires TLS")
+	default:
+		return nil, nil
+	}
+
+	if cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in client CA file %s", cfg.ClientCAFile)
+		}
+		tlsCfg.ClientCAs = pool
+		tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsCfg, nil
 }
 
 // Server wraps an A2A protocol server with convenient lifecycle methods.
 type Server struct {
 	agent      agent.Agent
+	name       string
 	listener   net.Listener
+	grpcLis    net.Listener
+	grpcServer *grpc.Server
 	baseURL    *url.URL
 	httpServer *http.Server
+	tlsConfig  *tls.Config
 	config     Config
+
+	// drainer tracks running tasks for Stop.
+	drainer *drainer
+
+	// limiter enforces RateLimit; nil when unlimited.
+	limiter *RateLimiter
+
+	// discovery registers the agent card when DiscoveryURL is set;
+	// stopAnnounce ends the renewals.
+	discovery    *DiscoveryClient
+	announceCtx  context.Context
+	stopAnnounce context.CancelFunc
 }
 
 // NewServer creates a new A2A server for the given agent.
 // This is a factory that eliminates ~70 lines of boilerplate per agent.
 func NewServer(cfg Config) (*Server, error) {
-	if cfg.Agent == nil {
-		return nil, fmt.Errorf("agent is required")
+	if cfg.Agent == nil && cfg.Executor == nil {
+		return nil, fmt.Errorf("agent or executor is required")
+	}
+	if cfg.Name == "" {
+		if cfg.Agent == nil {
+			return nil, fmt.Errorf("name is required with an executor")
+		}
+		cfg.Name = cfg.Agent.Name()
 	}
 
 	// Set defaults
 	if cfg.Port == "" {
 		cfg.Port = "0" // Random port
 	}
+	for i, skill := range cfg.Skills {
+		if skill.ID == "" || skill.Name == "" {
+			return nil, fmt.Errorf("skill %d: ID and Name are required", i)
+		}
+	}
+	if cfg.Version == "" {
+		cfg.Version = "1.0.0"
+	}
+	if len(cfg.DefaultInputModes) == 0 {
+		cfg.DefaultInputModes = []string{"text/plain"}
+	}
+	if len(cfg.DefaultOutputModes) == 0 {
+		cfg.DefaultOutputModes = []string{"text/plain"}
+	}
 	if cfg.InvokePath == "" {
 		cfg.InvokePath = "/invoke"
 	}
@@ -72,6 +339,52 @@ func NewServer(cfg Config) (*Server, error) {
 	if cfg.SessionService == nil {
 		cfg.SessionService = session.InMemoryService()
 	}
+	if cfg.LivePath == "" {
+		cfg.LivePath = "/livez"
+	}
+	if cfg.ReadyPath == "" {
+		cfg.ReadyPath = "/readyz"
+	}
+	if cfg.ReadyTimeout == 0 {
+		cfg.ReadyTimeout = 5 * time.Second
+	}
+	if cfg.ModelCheckInterval == 0 {
+		cfg.ModelCheckInterval = time.Minute
+	}
+	if cfg.DrainTimeout == 0 {
+		cfg.DrainTimeout = 30 * time.Second
+	}
+	if cfg.DiscoveryInterval == 0 {
+		cfg.DiscoveryInterval = 30 * time.Second
+	}
+	if cfg.RateLimit != nil && cfg.RateLimit.Rate <= 0 {
+		return nil, fmt.Errorf("rate limit: Rate must be positive")
+	}
+	if cfg.PushNotifications {
+		if cfg.PushConfigStore == nil {
+			cfg.PushConfigStore = push.NewInMemoryStore()
+		}
+		if cfg.PushSender == nil {
+			cfg.PushSender = push.NewHTTPPushSender(nil)
+		}
+	}
+
+	switch cfg.PreferredTransport {
+	case "":
+		cfg.PreferredTransport = a2a.TransportProtocolJSONRPC
+	case a2a.TransportProtocolJSONRPC:
+	case a2a.TransportProtocolGRPC:
+		if cfg.GRPCPort == "" {
+			return nil, fmt.Errorf("preferred transport GRPC requires GRPCPort")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported transport %q", cfg.PreferredTransport)
+	}
+
+	tlsCfg, err := cfg.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
 
 	// Create listener
 	addr := "0.0.0.0:" + cfg.Port
@@ -81,31 +394,98 @@ func NewServer(cfg Config) (*Server, error) {
 	}
 
 	baseURL := &url.URL{Scheme: "http", Host: listener.Addr().String()}
+	if tlsCfg != nil {
+		baseURL.Scheme = "https"
+	}
+
+	var grpcLis net.Listener
+	if cfg.GRPCPort != "" {
+		grpcLis, err = net.Listen("tcp", "0.0.0.0:"+cfg.GRPCPort)
+		if err != nil {
+			_ = listener.Close()
+			return nil, fmt.Errorf("failed to create gRPC listener: %w", err)
+		}
+	}
 
-	return &Server{
-		agent:    cfg.Agent,
-		listener: listener,
-		baseURL:  baseURL,
-		config:   cfg,
-	}, nil
+	s := &Server{
+		agent:     cfg.Agent,
+		name:      cfg.Name,
+		drainer:   newDrainer(),
+		listener:  listener,
+		grpcLis:   grpcLis,
+		baseURL:   baseURL,
+		tlsConfig: tlsCfg,
+		config:    cfg,
+	}
+	if cfg.RateLimit != nil {
+		s.limiter = NewRateLimiter(*cfg.RateLimit)
+	}
+	if cfg.DiscoveryURL != "" {
+		s.discovery = NewDiscoveryClient(cfg.DiscoveryURL)
+		s.announceCtx, s.stopAnnounce = context.WithCancel(context.Background())
+	}
+	return s, nil
 }
 
-// Start starts the A2A server. This method blocks until the server is stopped.
-func (s *Server) Start(ctx context.Context) error {
+// AgentCard returns the agent card the server publishes at
+// a2asrv.WellKnownAgentCardPath.
+func (s *Server) AgentCard() *a2a.AgentCard {
 	description := s.config.Description
 	if description == "" {
-		description = s.agent.Name()
+		description = s.name
+	}
+	skills := s.config.Skills
+	if len(skills) == 0 {
+		if s.agent != nil {
+			skills = adka2a.BuildAgentSkills(s.agent)
+		} else {
+			skills = []a2a.AgentSkill{{ID: s.name, Name: s.name, Description: description, Tags: []string{}}}
+		}
 	}
 
-	// Build agent card
-	agentCard := &a2a.AgentCard{
-		Name:               s.agent.Name(),
-		Description:        description,
-		Skills:             adka2a.BuildAgentSkills(s.agent),
-		PreferredTransport: a2a.TransportProtocolJSONRPC,
-		URL:                s.baseURL.JoinPath(s.config.InvokePath).String(),
-		Capabilities:       a2a.AgentCapabilities{Streaming: true},
+	return &a2a.AgentCard{
+		Name:                 s.name,
+		Description:          description,
+		Version:              s.config.Version,
+		ProtocolVersion:      string(a2a.Version),
+		Skills:               skills,
+		DefaultInputModes:    s.config.DefaultInputModes,
+		DefaultOutputModes:   s.config.DefaultOutputModes,
+		Provider:             s.config.Provider,
+		DocumentationURL:     s.config.DocumentationURL,
+		IconURL:              s.config.IconURL,
+		SecuritySchemes:      s.config.SecuritySchemes,
+		Security:             s.config.Security,
+		PreferredTransport:   s.config.PreferredTransport,
+		URL:                  s.transportURL(s.config.PreferredTransport),
+		AdditionalInterfaces: s.interfaces(),
+		Capabilities: a2a.AgentCapabilities{
+			Streaming:         true,
+			PushNotifications: s.config.PushNotifications,
+		},
 	}
+}
+
+// interfaces returns the transports the server offers.
+func (s *Server) interfaces() []a2a.AgentInterface {
+	interfaces := []a2a.AgentInterface{{Transport: a2a.TransportProtocolJSONRPC, URL: s.InvokeURL()}}
+	if s.grpcLis != nil {
+		interfaces = append(interfaces, a2a.AgentInterface{Transport: a2a.TransportProtocolGRPC, URL: s.GRPCAddr()})
+	}
+	return interfaces
+}
+
+// transportURL returns the URL of a transport.
+func (s *Server) transportURL(transport a2a.TransportProtocol) string {
+	if transport == a2a.TransportProtocolGRPC {
+		return s.GRPCAddr()
+	}
+	return s.InvokeURL()
+}
+
+// Start starts the A2A server. This method blocks until the server is stopped.
+func (s *Server) Start(ctx context.Context) error {
+	agentCard := s.AgentCard()
 
 	mux := http.NewServeMux()
 
@@ -113,54 +493,235 @@ func (s *Server) Start(ctx context.Context) error {
 	mux.Handle(a2asrv.WellKnownAgentCardPath, a2asrv.NewStaticAgentCardHandler(agentCard))
 
 	// Create executor
-	executor := adka2a.NewExecutor(adka2a.ExecutorConfig{
-		RunnerConfig: runner.Config{
-			AppName:        s.agent.Name(),
-			Agent:          s.agent,
-			SessionService: s.config.SessionService,
-		},
-	})
+	executor := s.config.Executor
+	if executor == nil {
+		runConfig := agent.RunConfig{}
+		if s.config.Streaming {
+			runConfig.StreamingMode = agent.StreamingModeSSE
+		}
+		executor = adka2a.NewExecutor(adka2a.ExecutorConfig{
+			RunnerConfig: runner.Config{
+				AppName:        s.agent.Name(),
+				Agent:          s.agent,
+				SessionService: s.config.SessionService,
+			},
+			RunConfig: runConfig,
+		})
+	}
+
+	s.drainer.AgentExecutor = executor
 
 	// Create handlers
-	requestHandler := a2asrv.NewHandler(executor)
-	mux.Handle(s.config.InvokePath, a2asrv.NewJSONRPCHandler(requestHandler))
+	// The correlator comes first, so that the other interceptors see the
+	// correlation ID.
+	handlerOpts := []a2asrv.RequestHandlerOption{a2asrv.WithCallInterceptor(correlator{})}
+	if s.config.TaskStore != nil {
+		handlerOpts = append(handlerOpts, a2asrv.WithTaskStore(s.config.TaskStore))
+	}
+	if s.config.PushNotifications {
+		handlerOpts = append(handlerOpts, a2asrv.WithPushNotifications(s.config.PushConfigStore, s.config.PushSender))
+	}
+	var metrics *Metrics
+	for _, interceptor := range s.config.Interceptors {
+		if m, ok := interceptor.(*Metrics); ok && metrics == nil {
+			metrics = m
+		}
+		handlerOpts = append(handlerOpts, a2asrv.WithCallInterceptor(interceptor))
+	}
+	if s.config.MetricsPath != "" {
+		if metrics == nil {
+			metrics = NewMetrics()
+			handlerOpts = append(handlerOpts, a2asrv.WithCallInterceptor(metrics))
+		}
+		mux.Handle(s.config.MetricsPath, s.auth(metrics))
+	}
+	requestHandler := a2asrv.NewHandler(s.drainer, handlerOpts...)
+	mux.Handle(s.config.InvokePath, correlateHTTP(s.auth(s.limit(jsonContentType(a2asrv.NewJSONRPCHandler(requestHandler))))))
+	if s.config.SSEPath != "" {
+		mux.Handle(s.config.SSEPath, correlateHTTP(s.auth(s.limit(NewSSERelay(requestHandler.OnSendMessageStream)))))
+	}
+	if s.grpcLis != nil {
+		var opts []grpc.ServerOption
+		if s.tlsConfig != nil {
+			opts = append(opts, grpc.Creds(credentials.NewTLS(s.tlsConfig.Clone())))
+		}
+		// As over HTTP, calls are authenticated before they are rate
+		// limited, so the limiter can trust their API key.
+		if s.config.Auth != nil {
+			auth := grpcAuth{auth: s.config.Auth}
+			opts = append(opts,
+				grpc.ChainUnaryInterceptor(auth.UnaryInterceptor()),
+				grpc.ChainStreamInterceptor(auth.StreamInterceptor()),
+			)
+		}
+		if s.limiter != nil {
+			opts = append(opts,
+				grpc.ChainUnaryInterceptor(s.limiter.UnaryInterceptor()),
+				grpc.ChainStreamInterceptor(s.limiter.StreamInterceptor()),
+			)
+		}
+		s.grpcServer = grpc.NewServer(opts...)
+		a2agrpc.NewHandler(requestHandler).RegisterWith(s.grpcServer)
+		go func() {
+			if err := s.grpcServer.Serve(s.grpcLis); err != nil {
+				log.Printf("[A2A] %s gRPC server error: %v", s.name, err)
+			}
+		}()
+	}
 
-	// Health check
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+	// Health checks
+	live := func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("OK"))
-	})
+	}
+	mux.HandleFunc("/health", live)
+	if s.config.LivePath != "/health" {
+		mux.HandleFunc(s.config.LivePath, live)
+	}
+	mux.HandleFunc(s.config.ReadyPath, httpserver.ReadyHandler(s.readyChecks(executor), s.config.ReadyTimeout))
 
-	log.Printf("[A2A] %s server starting on %s", s.agent.Name(), s.baseURL.String())          //nolint:gosec // G706: Server startup log
+	log.Printf("[A2A] %s server starting on %s", s.name, s.baseURL.String())                  //nolint:gosec // G706: Server startup log
 	log.Printf("[A2A]   Agent Card: %s%s", s.baseURL.String(), a2asrv.WellKnownAgentCardPath) //nolint:gosec // G706: Server startup log
 	log.Printf("[A2A]   Invoke: %s%s", s.baseURL.String(), s.config.InvokePath)               //nolint:gosec // G706: Server startup log
+	if s.grpcLis != nil {
+		log.Printf("[A2A]   gRPC: %s", s.GRPCAddr()) //nolint:gosec // G706: Server startup log
+	}
+
+	if s.discovery != nil {
+		go s.announce(agentCard)
+	}
 
 	s.httpServer = &http.Server{
 		Handler:           mux,
 		ReadHeaderTimeout: s.config.ReadHeaderTimeout,
+		TLSConfig:         s.tlsConfig,
 	}
 
+	if s.tlsConfig != nil {
+		// The certificates are already in TLSConfig.
+		return s.httpServer.ServeTLS(s.listener, "", "")
+	}
 	return s.httpServer.Serve(s.listener)
 }
 
+// limit wraps an endpoint with the rate limiter, if any.
+func (s *Server) limit(h http.Handler) http.Handler {
+	if s.limiter == nil {
+		return h
+	}
+	return s.limiter.Wrap(h)
+}
+
+// auth wraps an endpoint with Config.Auth, if any, marking the requests
+// it accepts as authenticated.
+func (s *Server) auth(h http.Handler) http.Handler {
+	if s.config.Auth == nil {
+		return h
+	}
+	return s.config.Auth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(w, r.WithContext(markAuthenticated(r.Context())))
+	}))
+}
+
+// jsonContentType labels the responses of h as JSON, as JSON-RPC requires;
+// the a2a-go handler leaves them to be sniffed as text. Streams replace
+// the label with text/event-stream.
+func jsonContentType(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		h.ServeHTTP(w, r)
+	})
+}
+
 // StartAsync starts the A2A server in the background.
 // Returns immediately. Use Stop() to shut down the server.
 func (s *Server) StartAsync(ctx context.Context) {
 	go func() {
 		if err := s.Start(ctx); err != nil && err != http.ErrServerClosed {
-			log.Printf("[A2A] %s server error: %v", s.agent.Name(), err)
+			log.Printf("[A2A] %s server error: %v", s.name, err)
 		}
 	}()
 }
 
-// Stop gracefully shuts down the server.
+// readyChecks returns the configured readiness checks with the built-in
+// ones for the session service, executor and model.
+func (s *Server) readyChecks(executor a2asrv.AgentExecutor) map[string]ReadyCheck {
+	checks := make(map[string]ReadyCheck, len(s.config.ReadyChecks)+4)
+	checks["shutdown"] = s.drainer.ready
+	if hc, ok := s.config.SessionService.(agentcore.HealthChecker); ok && s.agent != nil {
+		checks["session"] = hc.HealthCheck
+	} else if s.agent != nil {
+		// Listing a user's sessions is cheap in the in-memory service.
+		checks["session"] = func(ctx context.Context) error {
+			_, err := s.config.SessionService.List(ctx, &session.ListRequest{AppName: s.agent.Name(), UserID: "readyz"})
+			return err
+		}
+	}
+	if hc, ok := executor.(agentcore.HealthChecker); ok {
+		checks["executor"] = hc.HealthCheck
+	}
+	if s.config.Model != nil {
+		checks["model"] = ModelCheck(s.config.Model, s.config.ModelCheckInterval)
+	}
+	for name, check := range s.config.ReadyChecks {
+		checks[name] = check
+	}
+	return checks
+}
+
+// announce registers the agent card with the discovery server and renews
+// the registration until the server stops.
+func (s *Server) announce(card *a2a.AgentCard) {
+	ticker := time.NewTicker(s.config.DiscoveryInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.discovery.Register(s.announceCtx, card); err != nil && s.announceCtx.Err() == nil {
+			log.Printf("[A2A] %s discovery registration failed: %v", s.name, err)
+		}
+		select {
+		case <-s.announceCtx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop gracefully shuts down the server. It stops accepting tasks, waits up
+// to DrainTimeout for running tasks, cancels the rest, and then closes the
+// connections.
 func (s *Server) Stop(ctx context.Context) error {
+	if s.discovery != nil {
+		s.stopAnnounce()
+		if err := s.discovery.Deregister(ctx, s.transportURL(s.config.PreferredTransport)); err != nil {
+			log.Printf("[A2A] %s discovery deregistration failed: %v", s.name, err)
+		}
+	}
+	s.drainer.drain(ctx, s.config.DrainTimeout)
+	if s.grpcServer != nil {
+		s.stopGRPC(ctx)
+	} else if s.grpcLis != nil {
+		_ = s.grpcLis.Close()
+	}
 	if s.httpServer != nil {
 		return s.httpServer.Shutdown(ctx)
 	}
 	return s.listener.Close()
 }
 
+// stopGRPC stops the gRPC server gracefully, or forcibly when ctx ends.
+func (s *Server) stopGRPC(ctx context.Context) {
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+	}
+}
+
 // URL returns the base URL of the server.
 func (s *Server) URL() string {
 	return s.baseURL.String()
@@ -176,6 +737,15 @@ func (s *Server) InvokeURL() string {
 	return s.baseURL.JoinPath(s.config.InvokePath).String()
 }
 
+// GRPCAddr returns the host:port of the gRPC transport, or "" if GRPCPort
+// is not set.
+func (s *Server) GRPCAddr() string {
+	if s.grpcLis == nil {
+		return ""
+	}
+	return s.grpcLis.Addr().String()
+}
+
 // Addr returns the address the server is listening on.
 func (s *Server) Addr() net.Addr {
 	return s.listener.Addr()
@@ -0,0 +1,329 @@
+package a2a
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/adk/session"
+)
+
+// Errors of the durable session services.
+var (
+	errSessionExists   = errors.New("session already exists")
+	errSessionNotFound = errors.New("session not found")
+	errStaleSession    = errors.New("stale session: it was updated since it was read")
+)
+
+// sessionKey identifies a session.
+type sessionKey struct {
+	app, user, id string
+}
+
+// sessionRecord is a session as kept by a sessionBackend.
+type sessionRecord struct {
+	key sessionKey
+	// state holds the session-scoped keys; Get and List merge in the app
+	// and user state.
+	state     map[string]any
+	events    []*session.Event
+	updatedAt time.Time
+}
+
+// sessionBackend is the storage of a durable session service. Backends
+// store the session, app and user state separately, under the state keys
+// without their "app:" and "user:" prefixes, so app and user state is
+// shared by every session that uses it.
+type sessionBackend interface {
+	// create stores a new session and applies the app and user state
+	// deltas, failing with errSessionExists if the ID is taken.
+	create(ctx context.Context, rec sessionRecord, appDelta, userDelta map[string]any) error
+
+	// get loads a session with its last recent events (all if zero), and
+	// the app and user state, reporting false if it does not exist.
+	get(ctx context.Context, key sessionKey, recent int) (rec sessionRecord, appState, userState map[string]any, found bool, err error)
+
+	// list loads the sessions of an app, or of one of its users, without
+	// events and with their app and user state merged into the state.
+	list(ctx context.Context, app, user string) ([]sessionRecord, error)
+
+	// delete removes a session.
+	delete(ctx context.Context, key sessionKey) error
+
+	// append stores an event and applies its state deltas, failing with
+	// errStaleSession if the session was updated after since and with
+	// errSessionNotFound if it does not exist.
+	append(ctx context.Context, key sessionKey, since time.Time, event *session.Event, appDelta, userDelta, sessionDelta map[string]any) error
+}
+
+// createSession implements session.Service.Create on a backend.
+func createSession(ctx context.Context, b sessionBackend, req *session.CreateRequest) (*session.CreateResponse, error) {
+	if req.AppName == "" || req.UserID == "" {
+		return nil, fmt.Errorf("app_name and user_id are required, got app_name: %q, user_id: %q", req.AppName, req.UserID)
+	}
+	id := req.SessionID
+	if id == "" {
+		id = uuid.NewString()
+	}
+
+	appDelta, userDelta, sessionState := splitStateDelta(req.State)
+	rec := sessionRecord{
+		key:       sessionKey{app: req.AppName, user: req.UserID, id: id},
+		state:     sessionState,
+		updatedAt: time.Now().Truncate(time.Microsecond),
+	}
+	if err := b.create(ctx, rec, appDelta, userDelta); err != nil {
+		if errors.Is(err, errSessionExists) {
+			return nil, fmt.Errorf("session %s already exists", id)
+		}
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	_, appState, userState, _, err := b.get(ctx, rec.key, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read new session: %w", err)
+	}
+	rec.state = mergeState(appState, userState, rec.state)
+	return &session.CreateResponse{Session: newStoredSession(rec)}, nil
+}
+
+// getSession implements session.Service.Get on a backend.
+func getSession(ctx context.Context, b sessionBackend, req *session.GetRequest) (*session.GetResponse, error) {
+	if req.AppName == "" || req.UserID == "" || req.SessionID == "" {
+		return nil, fmt.Errorf("app_name, user_id, session_id are required, got app_name: %q, user_id: %q, session_id: %q", req.AppName, req.UserID, req.SessionID)
+	}
+	rec, appState, userState, found, err := b.get(ctx, sessionKey{app: req.AppName, user: req.UserID, id: req.SessionID}, req.NumRecentEvents)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("session %s not found", req.SessionID)
+	}
+
+	if !req.After.IsZero() {
+		first := sort.Search(len(rec.events), func(i int) bool {
+			return !rec.events[i].Timestamp.Before(req.After)
+		})
+		rec.events = rec.events[first:]
+	}
+	rec.state = mergeState(appState, userState, rec.state)
+	return &session.GetResponse{Session: newStoredSession(rec)}, nil
+}
+
+// listSessions implements session.Service.List on a backend.
+func listSessions(ctx context.Context, b sessionBackend, req *session.ListRequest) (*session.ListResponse, error) {
+	if req.AppName == "" {
+		return nil, fmt.Errorf("app_name is required, got app_name: %q", req.AppName)
+	}
+	recs, err := b.list(ctx, req.AppName, req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	slices.SortFunc(recs, func(a, b sessionRecord) int {
+		return strings.Compare(a.key.user+"\x00"+a.key.id, b.key.user+"\x00"+b.key.id)
+	})
+	sessions := make([]session.Session, 0, len(recs))
+	for _, rec := range recs {
+		rec.events = nil
+		sessions = append(sessions, newStoredSession(rec))
+	}
+	return &session.ListResponse{Sessions: sessions}, nil
+}
+
+// deleteSession implements session.Service.Delete on a backend.
+func deleteSession(ctx context.Context, b sessionBackend, req *session.DeleteRequest) error {
+	if req.AppName == "" || req.UserID == "" || req.SessionID == "" {
+		return fmt.Errorf("app_name, user_id, session_id are required, got app_name: %q, user_id: %q, session_id: %q", req.AppName, req.UserID, req.SessionID)
+	}
+	if err := b.delete(ctx, sessionKey{app: req.AppName, user: req.UserID, id: req.SessionID}); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+// appendSessionEvent implements session.Service.AppendEvent on a backend.
+// Like the ADK database service, it rejects events for sessions another
+// replica has updated since they were read.
+func appendSessionEvent(ctx context.Context, b sessionBackend, cur session.Session, event *session.Event) error {
+	if cur == nil {
+		return fmt.Errorf("session is nil")
+	}
+	if event == nil {
+		return fmt.Errorf("event is nil")
+	}
+	if event.Partial {
+		return nil
+	}
+	sess, ok := cur.(*storedSession)
+	if !ok {
+		return fmt.Errorf("unexpected session type %T", cur)
+	}
+
+	// Stores keep microseconds.
+	event.Timestamp = event.Timestamp.Truncate(time.Microsecond)
+	appDelta, userDelta, sessionDelta := splitStateDelta(event.Actions.StateDelta)
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	stored := *event
+	stored.Actions.StateDelta = withoutTempKeys(event.Actions.StateDelta)
+	if err := b.append(ctx, sess.key, sess.updatedAt, &stored, appDelta, userDelta, sessionDelta); err != nil {
+		if errors.Is(err, errSessionNotFound) {
+			return fmt.Errorf("session not found, cannot apply event")
+		}
+		return fmt.Errorf("failed to append event: %w", err)
+	}
+
+	maps.Copy(sess.state, event.Actions.StateDelta)
+	event.Actions.StateDelta = stored.Actions.StateDelta
+	sess.events = append(sess.events, event)
+	sess.updatedAt = event.Timestamp
+	return nil
+}
+
+// splitStateDelta splits state keys into app, user and session scopes,
+// dropping temporary keys, as the ADK session services do.
+func splitStateDelta(delta map[string]any) (appDelta, userDelta, sessionDelta map[string]any) {
+	appDelta, userDelta, sessionDelta = map[string]any{}, map[string]any{}, map[string]any{}
+	for key, value := range delta {
+		if k, ok := strings.CutPrefix(key, session.KeyPrefixApp); ok {
+			appDelta[k] = value
+		} else if k, ok := strings.CutPrefix(key, session.KeyPrefixUser); ok {
+			userDelta[k] = value
+		} else if !strings.HasPrefix(key, session.KeyPrefixTemp) {
+			sessionDelta[key] = value
+		}
+	}
+	return appDelta, userDelta, sessionDelta
+}
+
+// mergeState returns the state of a session as the agent sees it, with
+// the app and user state under their prefixes.
+func mergeState(appState, userState, sessionState map[string]any) map[string]any {
+	merged := make(map[string]any, len(appState)+len(userState)+len(sessionState))
+	maps.Copy(merged, sessionState)
+	for key, value := range appState {
+		merged[session.KeyPrefixApp+key] = value
+	}
+	for key, value := range userState {
+		merged[session.KeyPrefixUser+key] = value
+	}
+	return merged
+}
+
+// withoutTempKeys returns delta without temporary keys, which are not
+// stored with events.
+func withoutTempKeys(delta map[string]any) map[string]any {
+	if len(delta) == 0 {
+		return delta
+	}
+	kept := make(map[string]any, len(delta))
+	for key, value := range delta {
+		if !strings.HasPrefix(key, session.KeyPrefixTemp) {
+			kept[key] = value
+		}
+	}
+	return kept
+}
+
+// storedSession is a session read from a durable session service.
+type storedSession struct {
+	key sessionKey
+
+	mu        sync.RWMutex
+	state     map[string]any
+	events    []*session.Event
+	updatedAt time.Time
+}
+
+// newStoredSession returns the session of a record.
+func newStoredSession(rec sessionRecord) *storedSession {
+	if rec.state == nil {
+		rec.state = map[string]any{}
+	}
+	return &storedSession{key: rec.key, state: rec.state, events: rec.events, updatedAt: rec.updatedAt}
+}
+
+// ID implements session.Session.
+func (s *storedSession) ID() string { return s.key.id }
+
+// AppName implements session.Session.
+func (s *storedSession) AppName() string { return s.key.app }
+
+// UserID implements session.Session.
+func (s *storedSession) UserID() string { return s.key.user }
+
+// State implements session.Session.
+func (s *storedSession) State() session.State { return sessionState{s} }
+
+// Events implements session.Session.
+func (s *storedSession) Events() session.Events {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return sessionEvents(slices.Clip(s.events))
+}
+
+// LastUpdateTime implements session.Session.
+func (s *storedSession) LastUpdateTime() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.updatedAt
+}
+
+// sessionState is the state of a storedSession.
+type sessionState struct {
+	s *storedSession
+}
+
+// Get implements session.State.
+func (st sessionState) Get(key string) (any, error) {
+	st.s.mu.RLock()
+	defer st.s.mu.RUnlock()
+	value, ok := st.s.state[key]
+	if !ok {
+		return nil, session.ErrStateKeyNotExist
+	}
+	return value, nil
+}
+
+// Set implements session.State. Like the ADK services, it changes only
+// this copy of the session; state is stored through event deltas.
+func (st sessionState) Set(key string, value any) error {
+	st.s.mu.Lock()
+	defer st.s.mu.Unlock()
+	st.s.state[key] = value
+	return nil
+}
+
+// All implements session.State.
+func (st sessionState) All() iter.Seq2[string, any] {
+	st.s.mu.RLock()
+	state := maps.Clone(st.s.state)
+	st.s.mu.RUnlock()
+	return maps.All(state)
+}
+
+// sessionEvents is the event list of a storedSession.
+type sessionEvents []*session.Event
+
+// All implements session.Events.
+func (e sessionEvents) All() iter.Seq[*session.Event] { return slices.Values(e) }
+
+// Len implements session.Events.
+func (e sessionEvents) Len() int { return len(e) }
+
+// At implements session.Events.
+func (e sessionEvents) At(i int) *session.Event {
+	if i >= 0 && i < len(e) {
+		return e[i]
+	}
+	return nil
+}
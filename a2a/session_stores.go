@@ -0,0 +1,858 @@
+package a2a
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/adk/session"
+
+	"github.com/plexusone/agentkit/internal/awscli"
+	"github.com/plexusone/agentkit/internal/redis"
+)
+
+// stateFieldPrefix marks the state keys among the fields of a stored
+// session.
+const stateFieldPrefix = "state:"
+
+// RedisSessionService is an ADK session service backed by Redis, so the
+// replicas of an A2A server share conversations. Each session is a hash
+// {Prefix}session:{app}:{user}:{id} with its update time and state, its
+// events a list {Prefix}events:{app}:{user}:{id}, and the set
+// {Prefix}sessions:{app} indexes the sessions of an app. App and user
+// state are hashes {Prefix}app:{app} and {Prefix}user:{app}:{user}.
+// Names are URL-escaped in keys. Appending an event is a Lua script that
+// fails if another replica updated the session since it was read.
+// Connections are pooled and reused across operations.
+type RedisSessionService struct {
+	// Addr is the host:port of the Redis server.
+	Addr string
+
+	// Password authenticates with the server when set.
+	// Default: ""
+	Password string
+
+	// DB is the database number to select.
+	// Default: 0
+	DB int
+
+	// TLS enables TLS with the given configuration when set.
+	// Default: nil (plain TCP)
+	TLS *tls.Config
+
+	// Prefix is prepended to the keys of the service.
+	// Default: "agentkit:session:"
+	Prefix string
+
+	// TTL expires sessions that are not updated within it; zero keeps
+	// them until deleted. App and user state do not expire.
+	// Default: 0
+	TTL time.Duration
+
+	pool redis.Pool
+}
+
+var _ session.Service = (*RedisSessionService)(nil)
+
+// NewRedisSessionService creates a session service for the Redis server
+// at addr.
+func NewRedisSessionService(addr string) *RedisSessionService {
+	return &RedisSessionService{Addr: addr, Prefix: "agentkit:session:"}
+}
+
+// Create implements session.Service.
+func (s *RedisSessionService) Create(ctx context.Context, req *session.CreateRequest) (*session.CreateResponse, error) {
+	return createSession(ctx, s, req)
+}
+
+// Get implements session.Service.
+func (s *RedisSessionService) Get(ctx context.Context, req *session.GetRequest) (*session.GetResponse, error) {
+	return getSession(ctx, s, req)
+}
+
+// List implements session.Service. It loads every session of the app, so
+// it suits apps of up to some thousands of sessions; set TTL to bound
+// them.
+func (s *RedisSessionService) List(ctx context.Context, req *session.ListRequest) (*session.ListResponse, error) {
+	return listSessions(ctx, s, req)
+}
+
+// Delete implements session.Service.
+func (s *RedisSessionService) Delete(ctx context.Context, req *session.DeleteRequest) error {
+	return deleteSession(ctx, s, req)
+}
+
+// AppendEvent implements session.Service.
+func (s *RedisSessionService) AppendEvent(ctx context.Context, cur session.Session, event *session.Event) error {
+	return appendSessionEvent(ctx, s, cur, event)
+}
+
+// HealthCheck implements agentcore.HealthChecker by pinging the server,
+// which the readiness endpoint of Server uses instead of listing sessions.
+func (s *RedisSessionService) HealthCheck(ctx context.Context) error {
+	_, err := s.do(ctx, []string{"PING"})
+	return err
+}
+
+// redisSetState is the Lua function the session scripts use to set the
+// state fields given as key/value pairs after the four fixed ARGV and the
+// three counts.
+const redisSetState = `
+local i = 8
+local function setState(key, n, prefix)
+	for _ = 1, tonumber(n) do
+		redis.call('HSET', key, prefix .. ARGV[i], ARGV[i + 1])
+		i = i + 2
+	end
+end
+`
+
+// redisCreateSession stores a new session. KEYS: session, index, app
+// state, user state. ARGV: update time, index member, TTL in milliseconds
+// (0 for none), unused, then the numbers of session, app and user state
+// fields and their key/value pairs. It returns 0 if the session exists.
+const redisCreateSession = redisSetState + `
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'updated_at', ARGV[1])
+setState(KEYS[1], ARGV[5], 'state:')
+setState(KEYS[3], ARGV[6], '')
+setState(KEYS[4], ARGV[7], '')
+if ARGV[3] ~= '0' then
+	redis.call('PEXPIRE', KEYS[1], ARGV[3])
+end
+redis.call('SADD', KEYS[2], ARGV[2])
+return 1
+`
+
+// redisAppendEvent stores an event if the session was not updated since
+// the caller read it. KEYS: session, events, app state, user state. ARGV:
+// update time, event JSON, TTL in milliseconds (0 for none), time the
+// session was read, then the numbers of session, app and user state
+// fields and their key/value pairs. It returns -1 if the session does not
+// exist and 0 if it is stale.
+const redisAppendEvent = redisSetState + `
+local updated = redis.call('HGET', KEYS[1], 'updated_at')
+if not updated then
+	return -1
+end
+if tonumber(updated) > tonumber(ARGV[4]) then
+	return 0
+end
+setState(KEYS[1], ARGV[5], 'state:')
+setState(KEYS[3], ARGV[6], '')
+setState(KEYS[4], ARGV[7], '')
+redis.call('HSET', KEYS[1], 'updated_at', ARGV[1])
+redis.call('RPUSH', KEYS[2], ARGV[2])
+if ARGV[3] ~= '0' then
+	redis.call('PEXPIRE', KEYS[1], ARGV[3])
+	redis.call('PEXPIRE', KEYS[2], ARGV[3])
+end
+return 1
+`
+
+// key returns a Redis key of the service.
+func (s *RedisSessionService) key(kind string, names ...string) string {
+	for i, name := range names {
+		names[i] = url.QueryEscape(name)
+	}
+	return s.Prefix + kind + ":" + strings.Join(names, ":")
+}
+
+// do runs commands on the service's server.
+func (s *RedisSessionService) do(ctx context.Context, cmds ...[]string) ([]any, error) {
+	return s.pool.Do(ctx, redis.Options{Addr: s.Addr, Password: s.Password, DB: s.DB, TLS: s.TLS}, cmds...)
+}
+
+// Close closes the idle connections of the service.
+func (s *RedisSessionService) Close() error {
+	return s.pool.Close()
+}
+
+// eval runs a session script with the state deltas appended to args.
+func (s *RedisSessionService) eval(ctx context.Context, script string, keys, args []string, deltas ...map[string]any) (int64, error) {
+	cmd := append([]string{"EVAL", script, strconv.Itoa(len(keys))}, keys...)
+	cmd = append(cmd, args...)
+	var pairs []string
+	for _, delta := range deltas {
+		cmd = append(cmd, strconv.Itoa(len(delta)))
+		for key, value := range delta {
+			data, err := json.Marshal(value)
+			if err != nil {
+				return 0, fmt.Errorf("encoding state %q: %w", key, err)
+			}
+			pairs = append(pairs, key, string(data))
+		}
+	}
+	replies, err := s.do(ctx, append(cmd, pairs...))
+	if err != nil {
+		return 0, err
+	}
+	reply, _ := replies[0].([]byte)
+	n, err := strconv.ParseInt(string(reply), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("redis EVAL: unexpected reply %q", reply)
+	}
+	return n, nil
+}
+
+// create implements sessionBackend.
+func (s *RedisSessionService) create(ctx context.Context, rec sessionRecord, appDelta, userDelta map[string]any) error {
+	k := rec.key
+	n, err := s.eval(ctx, redisCreateSession,
+		[]string{s.key("session", k.app, k.user, k.id), s.key("sessions", k.app), s.key("app", k.app), s.key("user", k.app, k.user)},
+		[]string{
+			strconv.FormatInt(rec.updatedAt.UnixMicro(), 10),
+			url.QueryEscape(k.user) + ":" + url.QueryEscape(k.id),
+			strconv.FormatInt(s.TTL.Milliseconds(), 10),
+			"",
+		},
+		rec.state, appDelta, userDelta)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errSessionExists
+	}
+	return nil
+}
+
+// get implements sessionBackend.
+func (s *RedisSessionService) get(ctx context.Context, k sessionKey, recent int) (sessionRecord, map[string]any, map[string]any, bool, error) {
+	replies, err := s.do(ctx,
+		[]string{"HGETALL", s.key("session", k.app, k.user, k.id)},
+		[]string{"LRANGE", s.key("events", k.app, k.user, k.id), strconv.Itoa(-recent), "-1"},
+		[]string{"HGETALL", s.key("app", k.app)},
+		[]string{"HGETALL", s.key("user", k.app, k.user)},
+	)
+	if err != nil {
+		return sessionRecord{}, nil, nil, false, err
+	}
+	rec, found, err := parseRedisSession(k, replies[0])
+	if err != nil || !found {
+		return sessionRecord{}, nil, nil, false, err
+	}
+	items, _ := replies[1].([]any)
+	for _, item := range items {
+		data, _ := item.([]byte)
+		var event session.Event
+		if err := json.Unmarshal(data, &event); err != nil {
+			return sessionRecord{}, nil, nil, false, fmt.Errorf("decoding event of session %s: %w", k.id, err)
+		}
+		rec.events = append(rec.events, &event)
+	}
+	appState, err := parseRedisState(replies[2], "")
+	if err != nil {
+		return sessionRecord{}, nil, nil, false, err
+	}
+	userState, err := parseRedisState(replies[3], "")
+	if err != nil {
+		return sessionRecord{}, nil, nil, false, err
+	}
+	return rec, appState, userState, true, nil
+}
+
+// list implements sessionBackend.
+func (s *RedisSessionService) list(ctx context.Context, app, user string) ([]sessionRecord, error) {
+	replies, err := s.do(ctx, []string{"SMEMBERS", s.key("sessions", app)}, []string{"HGETALL", s.key("app", app)})
+	if err != nil {
+		return nil, err
+	}
+	appState, err := parseRedisState(replies[1], "")
+	if err != nil {
+		return nil, err
+	}
+	members, _ := replies[0].([]any)
+
+	var keys []sessionKey
+	var cmds [][]string
+	users := map[string]int{}
+	for _, member := range members {
+		data, _ := member.([]byte)
+		escUser, escID, _ := strings.Cut(string(data), ":")
+		u, err1 := url.QueryUnescape(escUser)
+		id, err2 := url.QueryUnescape(escID)
+		if err1 != nil || err2 != nil || (user != "" && u != user) {
+			continue
+		}
+		keys = append(keys, sessionKey{app: app, user: u, id: id})
+		cmds = append(cmds, []string{"HGETALL", s.key("session", app, u, id)})
+		if _, ok := users[u]; !ok {
+			users[u] = -1
+		}
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	for u := range users {
+		users[u] = len(cmds)
+		cmds = append(cmds, []string{"HGETALL", s.key("user", app, u)})
+	}
+	replies, err = s.do(ctx, cmds...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Expired sessions leave their members in the index; drop them.
+	expired := []string{"SREM", s.key("sessions", app)}
+	recs := make([]sessionRecord, 0, len(keys))
+	for i, k := range keys {
+		rec, found, err := parseRedisSession(k, replies[i])
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			expired = append(expired, url.QueryEscape(k.user)+":"+url.QueryEscape(k.id))
+			continue
+		}
+		userState, err := parseRedisState(replies[users[k.user]], "")
+		if err != nil {
+			return nil, err
+		}
+		rec.state = mergeState(appState, userState, rec.state)
+		recs = append(recs, rec)
+	}
+	if len(expired) > 2 {
+		if _, err := s.do(ctx, expired); err != nil {
+			return nil, err
+		}
+	}
+	return recs, nil
+}
+
+// delete implements sessionBackend.
+func (s *RedisSessionService) delete(ctx context.Context, k sessionKey) error {
+	_, err := s.do(ctx,
+		[]string{"DEL", s.key("session", k.app, k.user, k.id), s.key("events", k.app, k.user, k.id)},
+		[]string{"SREM", s.key("sessions", k.app), url.QueryEscape(k.user) + ":" + url.QueryEscape(k.id)},
+	)
+	return err
+}
+
+// append implements sessionBackend.
+func (s *RedisSessionService) append(ctx context.Context, k sessionKey, since time.Time, event *session.Event, appDelta, userDelta, sessionDelta map[string]any) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encoding event: %w", err)
+	}
+	n, err := s.eval(ctx, redisAppendEvent,
+		[]string{s.key("session", k.app, k.user, k.id), s.key("events", k.app, k.user, k.id), s.key("app", k.app), s.key("user", k.app, k.user)},
+		[]string{
+			strconv.FormatInt(event.Timestamp.UnixMicro(), 10),
+			string(data),
+			strconv.FormatInt(s.TTL.Milliseconds(), 10),
+			strconv.FormatInt(since.UnixMicro(), 10),
+		},
+		sessionDelta, appDelta, userDelta)
+	if err != nil {
+		return err
+	}
+	switch n {
+	case -1:
+		return errSessionNotFound
+	case 0:
+		return errStaleSession
+	}
+	return nil
+}
+
+// parseRedisSession decodes the HGETALL reply of a session hash. It
+// reports false if the session does not exist.
+func parseRedisSession(k sessionKey, reply any) (sessionRecord, bool, error) {
+	fields, _ := reply.([]any)
+	if len(fields) == 0 {
+		return sessionRecord{}, false, nil
+	}
+	state, err := parseRedisState(reply, stateFieldPrefix)
+	if err != nil {
+		return sessionRecord{}, false, fmt.Errorf("session %s: %w", k.id, err)
+	}
+	rec := sessionRecord{key: k, state: state}
+	for i := 0; i+1 < len(fields); i += 2 {
+		if name, _ := fields[i].([]byte); string(name) == "updated_at" {
+			value, _ := fields[i+1].([]byte)
+			micros, err := strconv.ParseInt(string(value), 10, 64)
+			if err != nil {
+				return sessionRecord{}, false, fmt.Errorf("decoding update time of session %s: %w", k.id, err)
+			}
+			rec.updatedAt = time.UnixMicro(micros)
+		}
+	}
+	return rec, true, nil
+}
+
+// parseRedisState decodes the state fields with prefix of an HGETALL
+// reply.
+func parseRedisState(reply any, prefix string) (map[string]any, error) {
+	fields, _ := reply.([]any)
+	state := make(map[string]any, len(fields)/2)
+	for i := 0; i+1 < len(fields); i += 2 {
+		name, _ := fields[i].([]byte)
+		key, ok := strings.CutPrefix(string(name), prefix)
+		if !ok {
+			continue
+		}
+		data, _ := fields[i+1].([]byte)
+		var value any
+		if err := json.Unmarshal(data, &value); err != nil {
+			return nil, fmt.Errorf("decoding state %q: %w", key, err)
+		}
+		state[key] = value
+	}
+	return state, nil
+}
+
+// DynamoDBSessionService is an ADK session service backed by a DynamoDB
+// table whose partition key is the string attribute "pk", so the replicas
+// of an A2A server share conversations. Sessions, app state and user
+// state are items keyed "session#{app}#{user}#{id}", "app#{app}" and
+// "user#{app}#{user}", with names URL-escaped; each state key is an
+// attribute "state:{key}" holding JSON, and events are a list in the
+// session item, so sessions are limited by the 400 KB item size; writes
+// are handed to the CLI in a file, not as an argument. Appending an event
+// is a transaction that fails if another replica updated the session
+// since it was read. It uses the aws CLI, which must be installed and have
+// credentials for the table.
+type DynamoDBSessionService struct {
+	// Table is the DynamoDB table name.
+	Table string
+
+	// IndexName is a global secondary index of the table with partition
+	// key "app_name" and sort key "user_id" (both strings), which only
+	// session items have. When set, List queries it instead of scanning
+	// the table.
+	// Default: "" (scan)
+	IndexName string
+}
+
+var _ session.Service = DynamoDBSessionService{}
+
+// Create implements session.Service.
+func (s DynamoDBSessionService) Create(ctx context.Context, req *session.CreateRequest) (*session.CreateResponse, error) {
+	return createSession(ctx, s, req)
+}
+
+// Get implements session.Service.
+func (s DynamoDBSessionService) Get(ctx context.Context, req *session.GetRequest) (*session.GetResponse, error) {
+	return getSession(ctx, s, req)
+}
+
+// List implements session.Service. Without IndexName it scans the whole
+// table, so it suits tables of up to some thousands of sessions.
+func (s DynamoDBSessionService) List(ctx context.Context, req *session.ListRequest) (*session.ListResponse, error) {
+	return listSessions(ctx, s, req)
+}
+
+// Delete implements session.Service.
+func (s DynamoDBSessionService) Delete(ctx context.Context, req *session.DeleteRequest) error {
+	return deleteSession(ctx, s, req)
+}
+
+// AppendEvent implements session.Service.
+func (s DynamoDBSessionService) AppendEvent(ctx context.Context, cur session.Session, event *session.Event) error {
+	return appendSessionEvent(ctx, s, cur, event)
+}
+
+// HealthCheck implements agentcore.HealthChecker by describing the table,
+// which the readiness endpoint of Server uses instead of listing sessions.
+func (s DynamoDBSessionService) HealthCheck(ctx context.Context) error {
+	_, err := awscli.Run(ctx, "dynamodb", "describe-table", "--table-name", s.Table)
+	return err
+}
+
+// dynamoValue is an attribute value as read and written by the aws CLI.
+type dynamoValue struct {
+	S *string       `json:"S"`
+	N *string       `json:"N"`
+	L []dynamoValue `json:"L"`
+}
+
+// MarshalJSON encodes the value with exactly one type key, as DynamoDB
+// requires, so the zero value and an empty list encode as {"L":[]}.
+func (v dynamoValue) MarshalJSON() ([]byte, error) {
+	switch {
+	case v.S != nil:
+		return json.Marshal(map[string]string{"S": *v.S})
+	case v.N != nil:
+		return json.Marshal(map[string]string{"N": *v.N})
+	default:
+		list := v.L
+		if list == nil {
+			list = []dynamoValue{}
+		}
+		return json.Marshal(map[string][]dynamoValue{"L": list})
+	}
+}
+
+// dynamoString returns a string attribute value.
+func dynamoString(s string) dynamoValue {
+	return dynamoValue{S: &s}
+}
+
+// dynamoNumber returns a number attribute value.
+func dynamoNumber(n int64) dynamoValue {
+	s := strconv.FormatInt(n, 10)
+	return dynamoValue{N: &s}
+}
+
+// sessionPK returns the partition key of a session item.
+func sessionPK(k sessionKey) string {
+	return "session#" + url.QueryEscape(k.app) + "#" + url.QueryEscape(k.user) + "#" + url.QueryEscape(k.id)
+}
+
+// appPK returns the partition key of an app state item.
+func appPK(app string) string {
+	return "app#" + url.QueryEscape(app)
+}
+
+// userPK returns the partition key of a user state item.
+func userPK(app, user string) string {
+	return "user#" + url.QueryEscape(app) + "#" + url.QueryEscape(user)
+}
+
+// dynamoKey returns the key of an item.
+func dynamoKey(pk string) map[string]dynamoValue {
+	return map[string]dynamoValue{"pk": dynamoString(pk)}
+}
+
+// stateUpdate returns an update of the state attributes of an item, with
+// its SET clauses, attribute names and values, each numbered from tag.
+func stateUpdate(tag string, delta map[string]any, names map[string]string, values map[string]dynamoValue) ([]string, error) {
+	var clauses []string
+	for _, key := range slices.Sorted(maps.Keys(delta)) {
+		data, err := json.Marshal(delta[key])
+		if err != nil {
+			return nil, fmt.Errorf("encoding state %q: %w", key, err)
+		}
+		n := tag + strconv.Itoa(len(clauses))
+		names["#"+n] = stateFieldPrefix + key
+		values[":"+n] = dynamoString(string(data))
+		clauses = append(clauses, "#"+n+" = :"+n)
+	}
+	return clauses, nil
+}
+
+// stateUpdateItem returns a transaction item that applies delta to the
+// state item pk, or nil if delta is empty.
+func (s DynamoDBSessionService) stateUpdateItem(pk string, delta map[string]any) (map[string]any, error) {
+	if len(delta) == 0 {
+		return nil, nil
+	}
+	names, values := map[string]string{}, map[string]dynamoValue{}
+	clauses, err := stateUpdate("s", delta, names, values)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"Update": map[string]any{
+		"TableName":                 s.Table,
+		"Key":                       dynamoKey(pk),
+		"UpdateExpression":          "SET " + strings.Join(clauses, ", "),
+		"ExpressionAttributeNames":  names,
+		"ExpressionAttributeValues": values,
+	}}, nil
+}
+
+// transact runs a write transaction of the non-nil items.
+func (s DynamoDBSessionService) transact(ctx context.Context, items ...map[string]any) error {
+	items = slices.DeleteFunc(items, func(item map[string]any) bool { return item == nil })
+	_, err := awscli.RunInput(ctx, map[string]any{"TransactItems": items}, "dynamodb", "transact-write-items")
+	return err
+}
+
+// create implements sessionBackend.
+func (s DynamoDBSessionService) create(ctx context.Context, rec sessionRecord, appDelta, userDelta map[string]any) error {
+	k := rec.key
+	item := map[string]dynamoValue{
+		"pk":         dynamoString(sessionPK(k)),
+		"app_name":   dynamoString(k.app),
+		"user_id":    dynamoString(k.user),
+		"session_id": dynamoString(k.id),
+		"updated_at": dynamoNumber(rec.updatedAt.UnixMicro()),
+	}
+	for key, value := range rec.state {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("encoding state %q: %w", key, err)
+		}
+		item[stateFieldPrefix+key] = dynamoString(string(data))
+	}
+	appItem, err := s.stateUpdateItem(appPK(k.app), appDelta)
+	if err != nil {
+		return err
+	}
+	userItem, err := s.stateUpdateItem(userPK(k.app, k.user), userDelta)
+	if err != nil {
+		return err
+	}
+
+	err = s.transact(ctx, map[string]any{"Put": map[string]any{
+		"TableName":           s.Table,
+		"Item":                item,
+		"ConditionExpression": "attribute_not_exists(pk)",
+	}}, appItem, userItem)
+	if err != nil && strings.Contains(err.Error(), "ConditionalCheckFailed") {
+		return errSessionExists
+	}
+	return err
+}
+
+// getItems reads items by partition key, returning them by key. Missing
+// items are left out.
+func (s DynamoDBSessionService) getItems(ctx context.Context, pks ...string) (map[string]map[string]dynamoValue, error) {
+	items := make(map[string]map[string]dynamoValue, len(pks))
+	for chunk := range slices.Chunk(pks, 100) {
+		keys := make([]map[string]dynamoValue, len(chunk))
+		for i, pk := range chunk {
+			keys[i] = dynamoKey(pk)
+		}
+		// The CLI retries unprocessed keys.
+		out, err := awscli.RunInput(ctx, map[string]any{
+			"RequestItems": map[string]any{s.Table: map[string]any{"Keys": keys, "ConsistentRead": true}},
+		}, "dynamodb", "batch-get-item")
+		if err != nil {
+			return nil, err
+		}
+		var result struct {
+			Responses map[string][]map[string]dynamoValue
+		}
+		if err := json.Unmarshal(out, &result); err != nil {
+			return nil, fmt.Errorf("parsing batch-get-item output: %w", err)
+		}
+		for _, item := range result.Responses[s.Table] {
+			if pk := item["pk"].S; pk != nil {
+				items[*pk] = item
+			}
+		}
+	}
+	return items, nil
+}
+
+// get implements sessionBackend.
+func (s DynamoDBSessionService) get(ctx context.Context, k sessionKey, recent int) (sessionRecord, map[string]any, map[string]any, bool, error) {
+	items, err := s.getItems(ctx, sessionPK(k), appPK(k.app), userPK(k.app, k.user))
+	if err != nil {
+		return sessionRecord{}, nil, nil, false, err
+	}
+	item, ok := items[sessionPK(k)]
+	if !ok {
+		return sessionRecord{}, nil, nil, false, nil
+	}
+	rec, err := decodeDynamoSession(item, true)
+	if err != nil {
+		return sessionRecord{}, nil, nil, false, err
+	}
+	if recent > 0 && len(rec.events) > recent {
+		rec.events = rec.events[len(rec.events)-recent:]
+	}
+	appState, err := decodeDynamoState(items[appPK(k.app)])
+	if err != nil {
+		return sessionRecord{}, nil, nil, false, err
+	}
+	userState, err := decodeDynamoState(items[userPK(k.app, k.user)])
+	if err != nil {
+		return sessionRecord{}, nil, nil, false, err
+	}
+	return rec, appState, userState, true, nil
+}
+
+// list implements sessionBackend.
+func (s DynamoDBSessionService) list(ctx context.Context, app, user string) ([]sessionRecord, error) {
+	cond := "app_name = :a"
+	values := map[string]dynamoValue{":a": dynamoString(app)}
+	if user != "" {
+		cond += " AND user_id = :u"
+		values[":u"] = dynamoString(user)
+	}
+	var out []byte
+	var err error
+	if s.IndexName != "" {
+		out, err = awscli.RunInput(ctx, map[string]any{
+			"TableName":                 s.Table,
+			"IndexName":                 s.IndexName,
+			"KeyConditionExpression":    cond,
+			"ExpressionAttributeValues": values,
+		}, "dynamodb", "query")
+	} else {
+		values[":p"] = dynamoString("session#")
+		out, err = awscli.RunInput(ctx, map[string]any{
+			"TableName":                 s.Table,
+			"ConsistentRead":            true,
+			"FilterExpression":          "begins_with(pk, :p) AND " + cond,
+			"ExpressionAttributeValues": values,
+		}, "dynamodb", "scan")
+	}
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Items []map[string]dynamoValue
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return nil, fmt.Errorf("parsing list output: %w", err)
+	}
+	if len(result.Items) == 0 {
+		return nil, nil
+	}
+
+	recs := make([]sessionRecord, 0, len(result.Items))
+	pks := []string{appPK(app)}
+	for _, item := range result.Items {
+		rec, err := decodeDynamoSession(item, false)
+		if err != nil {
+			return nil, err
+		}
+		recs = append(recs, rec)
+		pks = append(pks, userPK(app, rec.key.user))
+	}
+	slices.Sort(pks[1:])
+	states, err := s.getItems(ctx, slices.Compact(pks)...)
+	if err != nil {
+		return nil, err
+	}
+	appState, err := decodeDynamoState(states[appPK(app)])
+	if err != nil {
+		return nil, err
+	}
+	for i, rec := range recs {
+		userState, err := decodeDynamoState(states[userPK(app, rec.key.user)])
+		if err != nil {
+			return nil, err
+		}
+		recs[i].state = mergeState(appState, userState, rec.state)
+	}
+	return recs, nil
+}
+
+// delete implements sessionBackend.
+func (s DynamoDBSessionService) delete(ctx context.Context, k sessionKey) error {
+	key, err := json.Marshal(dynamoKey(sessionPK(k)))
+	if err != nil {
+		return fmt.Errorf("encoding key: %w", err)
+	}
+	_, err = awscli.Run(ctx, "dynamodb", "delete-item", "--table-name", s.Table, "--key", string(key))
+	return err
+}
+
+// append implements sessionBackend.
+func (s DynamoDBSessionService) append(ctx context.Context, k sessionKey, since time.Time, event *session.Event, appDelta, userDelta, sessionDelta map[string]any) error {
+	update, err := s.appendUpdate(k, since, event, sessionDelta)
+	if err != nil {
+		return err
+	}
+	appItem, err := s.stateUpdateItem(appPK(k.app), appDelta)
+	if err != nil {
+		return err
+	}
+	userItem, err := s.stateUpdateItem(userPK(k.app, k.user), userDelta)
+	if err != nil {
+		return err
+	}
+
+	err = s.transact(ctx, map[string]any{"Update": update}, appItem, userItem)
+	if err == nil || !strings.Contains(err.Error(), "ConditionalCheckFailed") {
+		return err
+	}
+	items, getErr := s.getItems(ctx, sessionPK(k))
+	if getErr != nil {
+		return errors.Join(err, getErr)
+	}
+	if _, ok := items[sessionPK(k)]; !ok {
+		return errSessionNotFound
+	}
+	return errStaleSession
+}
+
+// appendUpdate returns the update of a session item that appends event
+// and applies sessionDelta, if the session was not updated after since.
+func (s DynamoDBSessionService) appendUpdate(k sessionKey, since time.Time, event *session.Event, sessionDelta map[string]any) (map[string]any, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("encoding event: %w", err)
+	}
+	names := map[string]string{}
+	values := map[string]dynamoValue{
+		":event": {L: []dynamoValue{dynamoString(string(data))}},
+		":empty": {L: []dynamoValue{}},
+		":t":     dynamoNumber(event.Timestamp.UnixMicro()),
+		":since": dynamoNumber(since.UnixMicro()),
+	}
+	clauses, err := stateUpdate("s", sessionDelta, names, values)
+	if err != nil {
+		return nil, err
+	}
+	clauses = append(clauses, "events = list_append(if_not_exists(events, :empty), :event)", "updated_at = :t")
+	update := map[string]any{
+		"TableName":                 s.Table,
+		"Key":                       dynamoKey(sessionPK(k)),
+		"UpdateExpression":          "SET " + strings.Join(clauses, ", "),
+		"ConditionExpression":       "attribute_exists(pk) AND updated_at <= :since",
+		"ExpressionAttributeValues": values,
+	}
+	if len(names) > 0 {
+		update["ExpressionAttributeNames"] = names
+	}
+	return update, nil
+}
+
+// decodeDynamoSession decodes a session item, with its events if asked.
+func decodeDynamoSession(item map[string]dynamoValue, withEvents bool) (sessionRecord, error) {
+	var k sessionKey
+	for field, dst := range map[string]*string{"app_name": &k.app, "user_id": &k.user, "session_id": &k.id} {
+		if v := item[field].S; v != nil {
+			*dst = *v
+		}
+	}
+	rec := sessionRecord{key: k}
+	if n := item["updated_at"].N; n != nil {
+		micros, err := strconv.ParseInt(*n, 10, 64)
+		if err != nil {
+			return sessionRecord{}, fmt.Errorf("decoding update time of session %s: %w", k.id, err)
+		}
+		rec.updatedAt = time.UnixMicro(micros)
+	}
+	state, err := decodeDynamoState(item)
+	if err != nil {
+		return sessionRecord{}, fmt.Errorf("session %s: %w", k.id, err)
+	}
+	rec.state = state
+	if withEvents {
+		for _, v := range item["events"].L {
+			if v.S == nil {
+				continue
+			}
+			var event session.Event
+			if err := json.Unmarshal([]byte(*v.S), &event); err != nil {
+				return sessionRecord{}, fmt.Errorf("decoding event of session %s: %w", k.id, err)
+			}
+			rec.events = append(rec.events, &event)
+		}
+	}
+	return rec, nil
+}
+
+// decodeDynamoState decodes the state attributes of an item, which may be
+// nil.
+func decodeDynamoState(item map[string]dynamoValue) (map[string]any, error) {
+	state := map[string]any{}
+	for field, v := range item {
+		key, ok := strings.CutPrefix(field, stateFieldPrefix)
+		if !ok || v.S == nil {
+			continue
+		}
+		var value any
+		if err := json.Unmarshal([]byte(*v.S), &value); err != nil {
+			return nil, fmt.Errorf("decoding state %q: %w", key, err)
+		}
+		state[key] = value
+	}
+	return state, nil
+}
@@ -0,0 +1,62 @@
+package a2a
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/adk/session"
+)
+
+func TestDynamoValueMarshalJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		value dynamoValue
+		want  string
+	}{
+		{"string", dynamoString("a"), `{"S":"a"}`},
+		{"empty string", dynamoString(""), `{"S":""}`},
+		{"number", dynamoNumber(42), `{"N":"42"}`},
+		{"list", dynamoValue{L: []dynamoValue{dynamoString("a")}}, `{"L":[{"S":"a"}]}`},
+		{"empty list", dynamoValue{L: []dynamoValue{}}, `{"L":[]}`},
+		{"zero", dynamoValue{}, `{"L":[]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("got %s, want %s", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestDynamoDBSessionServiceAppendUpdate(t *testing.T) {
+	s := DynamoDBSessionService{Table: "sessions"}
+	k := sessionKey{app: "app", user: "user", id: "id"}
+	event := session.NewEvent("invocation")
+	update, err := s.appendUpdate(k, time.Now(), event, map[string]any{"count": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(update["ExpressionAttributeValues"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `":empty":{"L":[]}`) {
+		t.Errorf("values %s lack \":empty\":{\"L\":[]}", data)
+	}
+	var values map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		t.Fatal(err)
+	}
+	for name, value := range values {
+		if len(value) != 1 {
+			t.Errorf("value %s has %d type keys, want 1", name, len(value))
+		}
+	}
+}
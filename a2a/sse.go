@@ -0,0 +1,360 @@
+package a2a
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"strings"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+)
+
+// SSE event types of the plain streaming format.
+const (
+	// SSEStatus reports a change of the task's state, with the status
+	// message as text.
+	SSEStatus = "status"
+
+	// SSEArtifact carries output: a chunk of text and/or data. Append is
+	// set when it continues the artifact with the same ID.
+	SSEArtifact = "artifact"
+
+	// SSEMessage carries a direct reply from the agent, sent instead of a
+	// task.
+	SSEMessage = "message"
+
+	// SSEError reports that the call failed. No events follow.
+	SSEError = "error"
+
+	// SSEDone ends the stream, with the task's final state.
+	SSEDone = "done"
+)
+
+// SSERequest is the body of a request to an SSE relay. For GET requests,
+// e.g. from a browser EventSource, the fields are the URL parameters text,
+// context_id and task_id.
+type SSERequest struct {
+	// Text is the user's message.
+	Text string `json:"text"`
+
+	// ContextID continues a conversation.
+	ContextID string `json:"context_id,omitempty"`
+
+	// TaskID continues a task, e.g. one waiting for input.
+	TaskID string `json:"task_id,omitempty"`
+}
+
+// SSEEvent is the data of an event in the plain streaming format. The
+// event type is the SSE event name.
+type SSEEvent struct {
+	TaskID     string         `json:"task_id,omitempty"`
+	ContextID  string         `json:"context_id,omitempty"`
+	State      string         `json:"state,omitempty"`
+	ArtifactID string         `json:"artifact_id,omitempty"`
+	Text       string         `json:"text,omitempty"`
+	Data       map[string]any `json:"data,omitempty"`
+	Append     bool           `json:"append,omitempty"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// StreamFunc starts a streaming A2A call. It matches both
+// a2aclient.Client.SendStreamingMessage, for remote agents, and
+// a2asrv.RequestHandler.OnSendMessageStream, for agents in the process.
+type StreamFunc func(ctx context.Context, params *a2a.MessageSendParams) iter.Seq2[a2a.Event, error]
+
+// NewSSERelay returns a handler that exposes a streaming A2A call as a
+// plain SSE endpoint, so web frontends can show agent output as it is
+// produced without speaking JSON-RPC. It accepts an SSERequest as a POST
+// body or GET parameters, and streams SSEEvents of the types SSEStatus,
+// SSEArtifact and SSEMessage, ending with SSEDone or SSEError.
+func NewSSERelay(stream StreamFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req SSERequest
+		switch r.Method {
+		case http.MethodGet:
+			params := r.URL.Query()
+			req = SSERequest{Text: params.Get("text"), ContextID: params.Get("context_id"), TaskID: params.Get("task_id")}
+		case http.MethodPost:
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if req.Text == "" {
+			http.Error(w, "text is required", http.StatusBadRequest)
+			return
+		}
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		send := func(eventType string, ev SSEEvent) {
+			data, _ := json.Marshal(ev)
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", eventType, data)
+			flusher.Flush()
+		}
+
+		msg := a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: req.Text})
+		msg.ContextID = req.ContextID
+		msg.TaskID = a2a.TaskID(req.TaskID)
+
+		var done SSEEvent
+		for event, err := range stream(r.Context(), &a2a.MessageSendParams{Message: msg}) {
+			if err != nil {
+				send(SSEError, SSEEvent{TaskID: done.TaskID, ContextID: done.ContextID, Error: err.Error()})
+				return
+			}
+			eventType, ev := relayEvent(event)
+			if ev.TaskID != "" {
+				done.TaskID = ev.TaskID
+			}
+			if ev.ContextID != "" {
+				done.ContextID = ev.ContextID
+			}
+			if ev.State != "" {
+				done.State = ev.State
+			}
+			send(eventType, ev)
+		}
+		send(SSEDone, done)
+	})
+}
+
+// relayEvent converts an A2A event to the plain streaming format.
+func relayEvent(event a2a.Event) (string, SSEEvent) {
+	switch e := event.(type) {
+	case *a2a.Task:
+		ev := SSEEvent{TaskID: string(e.ID), ContextID: e.ContextID, State: string(e.Status.State)}
+		if e.Status.Message != nil {
+			ev.Text, ev.Data = partsContent(e.Status.Message.Parts)
+		}
+		return SSEStatus, ev
+	case *a2a.TaskStatusUpdateEvent:
+		ev := SSEEvent{TaskID: string(e.TaskID), ContextID: e.ContextID, State: string(e.Status.State)}
+		if e.Status.Message != nil {
+			ev.Text, ev.Data = partsContent(e.Status.Message.Parts)
+		}
+		return SSEStatus, ev
+	case *a2a.TaskArtifactUpdateEvent:
+		ev := SSEEvent{TaskID: string(e.TaskID), ContextID: e.ContextID, Append: e.Append}
+		if e.Artifact != nil {
+			ev.ArtifactID = string(e.Artifact.ID)
+			ev.Text, ev.Data = partsContent(e.Artifact.Parts)
+		}
+		return SSEArtifact, ev
+	case *a2a.Message:
+		ev := SSEEvent{TaskID: string(e.TaskID), ContextID: e.ContextID}
+		ev.Text, ev.Data = partsContent(e.Parts)
+		return SSEMessage, ev
+	default:
+		return SSEStatus, SSEEvent{}
+	}
+}
+
+// partsContent returns the text of the text parts, concatenated, and the
+// data of the data parts, merged. File parts are left out.
+func partsContent(parts a2a.ContentParts) (string, map[string]any) {
+	var text strings.Builder
+	var data map[string]any
+	for _, part := range parts {
+		switch p := part.(type) {
+		case a2a.TextPart:
+			text.WriteString(p.Text)
+		case a2a.DataPart:
+			if data == nil {
+				data = make(map[string]any, len(p.Data))
+			}
+			for k, v := range p.Data {
+				data[k] = v
+			}
+		}
+	}
+	return text.String(), data
+}
+
+// SSEExecutor is an a2asrv.AgentExecutor that runs tasks by calling a plain
+// SSE endpoint, so a service that already streams over SSE can be served
+// as an A2A agent with a2asrv.NewHandler. It POSTs an SSERequest with the
+// text of the user's message, and turns the events it receives into task
+// updates: SSEEvents are mapped as documented on their types, and events
+// whose data is not an SSEEvent are treated as chunks of text output.
+// The task completes when the stream ends, unless a status event set a
+// final state or an error event failed it.
+type SSEExecutor struct {
+	// URL is the SSE endpoint, e.g. one served by NewSSERelay.
+	URL string
+
+	// HTTPClient sends the requests. It should have no overall timeout,
+	// which would cut off long streams.
+	// Default: http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// Execute implements a2asrv.AgentExecutor.
+func (e *SSEExecutor) Execute(ctx context.Context, reqCtx *a2asrv.RequestContext, queue eventqueue.Queue) error {
+	if reqCtx.StoredTask == nil {
+		if err := queue.Write(ctx, a2a.NewSubmittedTask(reqCtx, reqCtx.Message)); err != nil {
+			return err
+		}
+	}
+	if err := queue.Write(ctx, a2a.NewStatusUpdateEvent(reqCtx, a2a.TaskStateWorking, nil)); err != nil {
+		return err
+	}
+
+	final, finalText, err := e.stream(ctx, reqCtx, queue)
+	if err != nil {
+		final, finalText = a2a.TaskStateFailed, err.Error()
+	}
+	var msg *a2a.Message
+	if finalText != "" {
+		msg = a2a.NewMessageForTask(a2a.MessageRoleAgent, reqCtx, a2a.TextPart{Text: finalText})
+	}
+	status := a2a.NewStatusUpdateEvent(reqCtx, final, msg)
+	status.Final = true
+	return queue.Write(ctx, status)
+}
+
+// stream calls the endpoint and writes its output to queue. It returns
+// the final state of the task and its status text.
+func (e *SSEExecutor) stream(ctx context.Context, reqCtx *a2asrv.RequestContext, queue eventqueue.Queue) (a2a.TaskState, string, error) {
+	var text string
+	if reqCtx.Message != nil {
+		text, _ = partsContent(reqCtx.Message.Parts)
+	}
+	body, err := json.Marshal(SSERequest{Text: text, ContextID: reqCtx.ContextID, TaskID: string(reqCtx.TaskID)})
+	if err != nil {
+		return "", "", fmt.Errorf("encoding request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.URL, bytes.NewReader(body))
+	if err != nil {
+		return "", "", fmt.Errorf("sse: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	client := e.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("sse: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", "", fmt.Errorf("sse: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	final, finalText := a2a.TaskStateCompleted, ""
+	artifacts := make(map[string]a2a.ArtifactID)
+	for eventType, data := range readSSE(resp.Body) {
+		var ev SSEEvent
+		decoded := json.Unmarshal([]byte(data), &ev) == nil
+		if eventType == "" || eventType == SSEMessage {
+			eventType = SSEArtifact
+		}
+		if eventType == SSEArtifact && (!decoded || ev.Text == "" && ev.Data == nil) {
+			// Not our format: the data is a chunk of text.
+			ev = SSEEvent{Text: data}
+		}
+
+		switch eventType {
+		case SSEStatus:
+			state := a2a.TaskState(ev.State)
+			if state.Terminal() || state == a2a.TaskStateInputRequired || state == a2a.TaskStateAuthRequired {
+				final, finalText = state, ev.Text
+				continue
+			}
+			if ev.Text == "" {
+				continue
+			}
+			msg := a2a.NewMessageForTask(a2a.MessageRoleAgent, reqCtx, a2a.TextPart{Text: ev.Text})
+			if err := queue.Write(ctx, a2a.NewStatusUpdateEvent(reqCtx, a2a.TaskStateWorking, msg)); err != nil {
+				return "", "", err
+			}
+		case SSEError:
+			return a2a.TaskStateFailed, ev.Error, nil
+		case SSEDone:
+			return final, finalText, nil
+		default:
+			var parts a2a.ContentParts
+			if ev.Text != "" {
+				parts = append(parts, a2a.TextPart{Text: ev.Text})
+			}
+			if ev.Data != nil {
+				parts = append(parts, a2a.DataPart{Data: ev.Data})
+			}
+			if len(parts) == 0 {
+				continue
+			}
+			var update *a2a.TaskArtifactUpdateEvent
+			if id, ok := artifacts[ev.ArtifactID]; ok {
+				update = a2a.NewArtifactUpdateEvent(reqCtx, id, parts...)
+			} else {
+				update = a2a.NewArtifactEvent(reqCtx, parts...)
+				artifacts[ev.ArtifactID] = update.Artifact.ID
+			}
+			if err := queue.Write(ctx, update); err != nil {
+				return "", "", err
+			}
+		}
+	}
+	return final, finalText, ctx.Err()
+}
+
+// Cancel implements a2asrv.AgentExecutor.
+func (e *SSEExecutor) Cancel(ctx context.Context, reqCtx *a2asrv.RequestContext, queue eventqueue.Queue) error {
+	status := a2a.NewStatusUpdateEvent(reqCtx, a2a.TaskStateCanceled, nil)
+	status.Final = true
+	return queue.Write(ctx, status)
+}
+
+// readSSE yields the type and data of each event of a server-sent event
+// stream. Comments and the id and retry fields are ignored.
+func readSSE(r io.Reader) iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		var eventType string
+		var data []string
+		for scanner.Scan() {
+			line := scanner.Text()
+			if line == "" {
+				if len(data) > 0 && !yield(eventType, strings.Join(data, "\n")) {
+					return
+				}
+				eventType, data = "", nil
+				continue
+			}
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+			switch field {
+			case "event":
+				eventType = value
+			case "data":
+				data = append(data, value)
+			}
+		}
+		if len(data) > 0 {
+			yield(eventType, strings.Join(data, "\n"))
+		}
+	}
+}
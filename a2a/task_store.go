@@ -0,0 +1,131 @@
+package a2a
+
+import (
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+)
+
+// TaskStore persists the tasks of an A2A server, so task state survives
+// restarts and tasks/get is answered by any replica sharing the store.
+// It is the a2asrv interface, so any a2a-go store can be used.
+type TaskStore = a2asrv.TaskStore
+
+// Listing defaults, matching the a2a-go in-memory store.
+const (
+	defaultTaskPageSize = 50
+	maxTaskPageSize     = 100
+)
+
+// storedTask is a task as kept by the durable stores.
+type storedTask struct {
+	Task      *a2a.Task
+	Version   a2a.TaskVersion
+	UpdatedAt time.Time
+}
+
+// listTasks answers a tasks/list request from all stored tasks. It filters,
+// orders by last update (newest first) and paginates the way the a2a-go
+// in-memory store does, so clients see the same results from every store.
+func listTasks(tasks []storedTask, req *a2a.ListTasksRequest) (*a2a.ListTasksResponse, error) {
+	pageSize := req.PageSize
+	if pageSize == 0 {
+		pageSize = defaultTaskPageSize
+	} else if pageSize < 1 || pageSize > maxTaskPageSize {
+		return nil, fmt.Errorf("page size must be between 1 and %d inclusive, got %d", maxTaskPageSize, pageSize)
+	}
+	if req.HistoryLength < 0 {
+		return nil, fmt.Errorf("history length must be non-negative integer, got %d", req.HistoryLength)
+	}
+
+	var filtered []storedTask
+	for _, t := range tasks {
+		if req.ContextID != "" && t.Task.ContextID != req.ContextID {
+			continue
+		}
+		if req.Status != a2a.TaskStateUnspecified && t.Task.Status.State != req.Status {
+			continue
+		}
+		if req.LastUpdatedAfter != nil && t.UpdatedAt.Before(*req.LastUpdatedAfter) {
+			continue
+		}
+		filtered = append(filtered, t)
+	}
+	slices.SortFunc(filtered, compareStoredTasks)
+
+	page := filtered
+	if req.PageToken != "" {
+		cursor, err := decodeTaskPageToken(req.PageToken)
+		if err != nil {
+			return nil, err
+		}
+		start, _ := slices.BinarySearchFunc(filtered, cursor, compareStoredTasks)
+		for start < len(filtered) && compareStoredTasks(filtered[start], cursor) <= 0 {
+			start++
+		}
+		page = filtered[start:]
+	}
+
+	var nextPageToken string
+	if len(page) > pageSize {
+		page = page[:pageSize]
+		nextPageToken = encodeTaskPageToken(page[pageSize-1])
+	}
+
+	result := make([]*a2a.Task, 0, len(page))
+	for _, t := range page {
+		task := t.Task
+		if req.HistoryLength > 0 && len(task.History) > req.HistoryLength {
+			task.History = task.History[len(task.History)-req.HistoryLength:]
+		}
+		if !req.IncludeArtifacts {
+			task.Artifacts = nil
+		}
+		result = append(result, task)
+	}
+
+	return &a2a.ListTasksResponse{
+		Tasks:         result,
+		TotalSize:     len(filtered),
+		PageSize:      pageSize,
+		NextPageToken: nextPageToken,
+	}, nil
+}
+
+// compareStoredTasks orders tasks by last update, newest first, then by
+// descending ID.
+func compareStoredTasks(a, b storedTask) int {
+	if c := b.UpdatedAt.Compare(a.UpdatedAt); c != 0 {
+		return c
+	}
+	return strings.Compare(string(b.Task.ID), string(a.Task.ID))
+}
+
+// encodeTaskPageToken returns the token of the page after t, in the
+// format of the a2a-go in-memory store.
+func encodeTaskPageToken(t storedTask) string {
+	token := t.UpdatedAt.Format(time.RFC3339Nano) + "_" + string(t.Task.ID)
+	return base64.URLEncoding.EncodeToString([]byte(token))
+}
+
+// decodeTaskPageToken returns the last task of the previous page.
+func decodeTaskPageToken(token string) (storedTask, error) {
+	decoded, err := base64.URLEncoding.DecodeString(token)
+	if err != nil {
+		return storedTask{}, a2a.ErrParseError
+	}
+	updated, id, ok := strings.Cut(string(decoded), "_")
+	if !ok {
+		return storedTask{}, a2a.ErrParseError
+	}
+	updatedAt, err := time.Parse(time.RFC3339Nano, updated)
+	if err != nil {
+		return storedTask{}, a2a.ErrParseError
+	}
+	return storedTask{Task: &a2a.Task{ID: a2a.TaskID(id)}, UpdatedAt: updatedAt}, nil
+}
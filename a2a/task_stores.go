@@ -0,0 +1,415 @@
+package a2a
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+
+	"github.com/plexusone/agentkit/internal/awscli"
+	"github.com/plexusone/agentkit/internal/redis"
+)
+
+// RedisTaskStore keeps each task in a Redis hash named {Prefix}task:{ID},
+// with its JSON, version and last update time, and indexes the task IDs in
+// the sorted set {Prefix}tasks for tasks/list. Saves are compare-and-set
+// in a Lua script, so replicas updating the same task cannot overwrite
+// each other. Connections are pooled and reused across operations.
+type RedisTaskStore struct {
+	// Addr is the host:port of the Redis server.
+	Addr string
+
+	// Password authenticates with the server when set.
+	// Default: ""
+	Password string
+
+	// DB is the database number to select.
+	// Default: 0
+	DB int
+
+	// TLS enables TLS with the given configuration when set.
+	// Default: nil (plain TCP)
+	TLS *tls.Config
+
+	// Prefix is prepended to the keys of the store.
+	// Default: "agentkit:a2a:"
+	Prefix string
+
+	// TTL expires tasks that are not updated within it; zero keeps them
+	// until deleted.
+	// Default: 0
+	TTL time.Duration
+
+	pool redis.Pool
+}
+
+// NewRedisTaskStore creates a store for the Redis server at addr.
+func NewRedisTaskStore(addr string) *RedisTaskStore {
+	return &RedisTaskStore{Addr: addr, Prefix: "agentkit:a2a:"}
+}
+
+// redisSaveTask stores a task if its version is still the one the caller
+// read. KEYS: task hash, index. ARGV: previous version (0 for any), task
+// JSON, update time, index score, task ID, TTL in milliseconds (0 for
+// none). It returns the new version, or -1 on a conflict.
+const redisSaveTask = `
+local current = redis.call('HGET', KEYS[1], 'version')
+if current and ARGV[1] ~= '0' and current ~= ARGV[1] then
+	return -1
+end
+local version = (tonumber(current) or 0) + 1
+redis.call('HSET', KEYS[1], 'task', ARGV[2], 'version', version, 'updated_at', ARGV[3])
+if ARGV[6] ~= '0' then
+	redis.call('PEXPIRE', KEYS[1], ARGV[6])
+end
+redis.call('ZADD', KEYS[2], ARGV[4], ARGV[5])
+return version
+`
+
+// taskKey returns the Redis key of a task.
+func (s *RedisTaskStore) taskKey(id a2a.TaskID) string {
+	return s.Prefix + "task:" + string(id)
+}
+
+// indexKey returns the Redis key of the task index.
+func (s *RedisTaskStore) indexKey() string {
+	return s.Prefix + "tasks"
+}
+
+// Save implements TaskStore.
+func (s *RedisTaskStore) Save(ctx context.Context, task *a2a.Task, event a2a.Event, prev *a2a.Task, prevVersion a2a.TaskVersion) (a2a.TaskVersion, error) {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return a2a.TaskVersionMissing, fmt.Errorf("encoding task: %w", err)
+	}
+	now := time.Now().UTC()
+	replies, err := s.do(ctx, []string{
+		"EVAL", redisSaveTask, "2", s.taskKey(task.ID), s.indexKey(),
+		strconv.FormatInt(int64(prevVersion), 10),
+		string(data),
+		now.Format(time.RFC3339Nano),
+		strconv.FormatInt(now.UnixMilli(), 10),
+		string(task.ID),
+		strconv.FormatInt(s.TTL.Milliseconds(), 10),
+	})
+	if err != nil {
+		return a2a.TaskVersionMissing, err
+	}
+	reply, _ := replies[0].([]byte)
+	version, err := strconv.ParseInt(string(reply), 10, 64)
+	if err != nil {
+		return a2a.TaskVersionMissing, fmt.Errorf("redis EVAL: unexpected reply %q", reply)
+	}
+	if version < 0 {
+		return a2a.TaskVersionMissing, a2a.ErrConcurrentTaskModification
+	}
+	return a2a.TaskVersion(version), nil
+}
+
+// Get implements TaskStore.
+func (s *RedisTaskStore) Get(ctx context.Context, taskID a2a.TaskID) (*a2a.Task, a2a.TaskVersion, error) {
+	replies, err := s.do(ctx, []string{"HMGET", s.taskKey(taskID), "task", "version", "updated_at"})
+	if err != nil {
+		return nil, a2a.TaskVersionMissing, err
+	}
+	stored, ok, err := parseRedisTask(replies[0])
+	if err != nil {
+		return nil, a2a.TaskVersionMissing, err
+	}
+	if !ok {
+		return nil, a2a.TaskVersionMissing, a2a.ErrTaskNotFound
+	}
+	return stored.Task, stored.Version, nil
+}
+
+// List implements TaskStore. It loads every task updated after the
+// request's LastUpdatedAfter, so it suits stores of up to some thousands
+// of tasks; set TTL to bound the store.
+func (s *RedisTaskStore) List(ctx context.Context, req *a2a.ListTasksRequest) (*a2a.ListTasksResponse, error) {
+	minScore := "-inf"
+	if req.LastUpdatedAfter != nil {
+		minScore = strconv.FormatInt(req.LastUpdatedAfter.UnixMilli(), 10)
+	}
+	replies, err := s.do(ctx, []string{"ZRANGEBYSCORE", s.indexKey(), minScore, "+inf"})
+	if err != nil {
+		return nil, err
+	}
+	ids, _ := replies[0].([]any)
+
+	var tasks []storedTask
+	if len(ids) > 0 {
+		cmds := make([][]string, len(ids))
+		for i, id := range ids {
+			idBytes, _ := id.([]byte)
+			cmds[i] = []string{"HMGET", s.taskKey(a2a.TaskID(idBytes)), "task", "version", "updated_at"}
+		}
+		replies, err = s.do(ctx, cmds...)
+		if err != nil {
+			return nil, err
+		}
+
+		// Expired tasks leave their IDs in the index; drop them.
+		expired := []string{"ZREM", s.indexKey()}
+		for i, reply := range replies {
+			stored, ok, err := parseRedisTask(reply)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				idBytes, _ := ids[i].([]byte)
+				expired = append(expired, string(idBytes))
+				continue
+			}
+			tasks = append(tasks, stored)
+		}
+		if len(expired) > 2 {
+			if _, err := s.do(ctx, expired); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return listTasks(tasks, req)
+}
+
+// parseRedisTask decodes the reply of HMGET task version updated_at. It
+// reports false if the task does not exist.
+func parseRedisTask(reply any) (storedTask, bool, error) {
+	fields, _ := reply.([]any)
+	if len(fields) != 3 || fields[0] == nil {
+		return storedTask{}, false, nil
+	}
+	data, _ := fields[0].([]byte)
+	version, _ := fields[1].([]byte)
+	updated, _ := fields[2].([]byte)
+	return decodeStoredTask(string(data), string(version), string(updated))
+}
+
+// do runs commands on the store's server.
+func (s *RedisTaskStore) do(ctx context.Context, cmds ...[]string) ([]any, error) {
+	return s.pool.Do(ctx, redis.Options{Addr: s.Addr, Password: s.Password, DB: s.DB, TLS: s.TLS}, cmds...)
+}
+
+// Close closes the idle connections of the store.
+func (s *RedisTaskStore) Close() error {
+	return s.pool.Close()
+}
+
+// DynamoDBTaskStore keeps tasks in a DynamoDB table whose partition key is
+// the string attribute "task_id". The task is stored as JSON in the "task"
+// attribute, with "version" and "updated_at" beside it, so tasks are
+// limited by the 400 KB item size; items are handed to the CLI in a file,
+// not as an argument. Items also carry "kind", always "task", and
+// "updated_ms", the update time in Unix milliseconds, for an index that
+// lists tasks by update time. Saves are conditional on the version, so
+// replicas updating the same task cannot overwrite each other. It uses the
+// aws CLI, which must be installed and have credentials for the table.
+type DynamoDBTaskStore struct {
+	// Table is the DynamoDB table name.
+	Table string
+
+	// IndexName is a global secondary index of the table with partition
+	// key "kind" (string) and sort key "updated_ms" (number). When set,
+	// tasks/list queries it for the newest tasks instead of scanning the
+	// table.
+	// Default: "" (scan)
+	IndexName string
+
+	// MaxListTasks bounds the tasks a tasks/list request reads. With an
+	// index these are the newest tasks before the page token; without one
+	// the scan stops after reading them, so set IndexName for tables that
+	// hold more.
+	// Default: 1000
+	MaxListTasks int
+}
+
+// Save implements TaskStore.
+func (s DynamoDBTaskStore) Save(ctx context.Context, task *a2a.Task, event a2a.Event, prev *a2a.Task, prevVersion a2a.TaskVersion) (a2a.TaskVersion, error) {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return a2a.TaskVersionMissing, fmt.Errorf("encoding task: %w", err)
+	}
+	current, found, err := s.get(ctx, task.ID)
+	if err != nil {
+		return a2a.TaskVersionMissing, err
+	}
+	if found && prevVersion != a2a.TaskVersionMissing && current.Version != prevVersion {
+		return a2a.TaskVersionMissing, a2a.ErrConcurrentTaskModification
+	}
+
+	version := current.Version + 1
+	now := time.Now().UTC()
+	input := map[string]any{
+		"TableName": s.Table,
+		"Item": map[string]map[string]string{
+			"task_id":    {"S": string(task.ID)},
+			"task":       {"S": string(data)},
+			"version":    {"N": strconv.FormatInt(int64(version), 10)},
+			"updated_at": {"S": now.Format(time.RFC3339Nano)},
+			"kind":       {"S": "task"},
+			"updated_ms": {"N": strconv.FormatInt(now.UnixMilli(), 10)},
+		},
+	}
+	if found {
+		input["ConditionExpression"] = "#v = :v"
+		input["ExpressionAttributeNames"] = map[string]string{"#v": "version"}
+		input["ExpressionAttributeValues"] = map[string]map[string]string{
+			":v": {"N": strconv.FormatInt(int64(current.Version), 10)},
+		}
+	} else {
+		input["ConditionExpression"] = "attribute_not_exists(task_id)"
+	}
+	if _, err := awscli.RunInput(ctx, input, "dynamodb", "put-item"); err != nil {
+		if strings.Contains(err.Error(), "ConditionalCheckFailedException") {
+			return a2a.TaskVersionMissing, a2a.ErrConcurrentTaskModification
+		}
+		return a2a.TaskVersionMissing, err
+	}
+	return version, nil
+}
+
+// Get implements TaskStore.
+func (s DynamoDBTaskStore) Get(ctx context.Context, taskID a2a.TaskID) (*a2a.Task, a2a.TaskVersion, error) {
+	stored, found, err := s.get(ctx, taskID)
+	if err != nil {
+		return nil, a2a.TaskVersionMissing, err
+	}
+	if !found {
+		return nil, a2a.TaskVersionMissing, a2a.ErrTaskNotFound
+	}
+	return stored.Task, stored.Version, nil
+}
+
+// List implements TaskStore. It reads at most MaxListTasks tasks, from
+// IndexName when set and by scanning the table otherwise, so TotalSize
+// counts the matching tasks among those.
+func (s DynamoDBTaskStore) List(ctx context.Context, req *a2a.ListTasksRequest) (*a2a.ListTasksResponse, error) {
+	maxTasks := s.MaxListTasks
+	if maxTasks <= 0 {
+		maxTasks = 1000
+	}
+	maxItems := strconv.Itoa(maxTasks)
+
+	var out []byte
+	var err error
+	if s.IndexName == "" {
+		out, err = awscli.Run(ctx, "dynamodb", "scan", "--table-name", s.Table, "--consistent-read",
+			"--max-items", maxItems)
+	} else {
+		var input map[string]any
+		if input, err = s.listQuery(req); err != nil {
+			return nil, err
+		}
+		out, err = awscli.RunInput(ctx, input, "dynamodb", "query", "--max-items", maxItems)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Items []dynamoTaskItem
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return nil, fmt.Errorf("parsing list output: %w", err)
+	}
+	tasks := make([]storedTask, 0, len(result.Items))
+	for _, item := range result.Items {
+		stored, _, err := item.decode()
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, stored)
+	}
+	return listTasks(tasks, req)
+}
+
+// listQuery returns the input of the index query of a tasks/list request:
+// the newest tasks updated after its LastUpdatedAfter and, for later pages,
+// not after the last task of the previous page.
+func (s DynamoDBTaskStore) listQuery(req *a2a.ListTasksRequest) (map[string]any, error) {
+	cond := "#k = :k"
+	values := map[string]map[string]string{":k": {"S": "task"}}
+	var after, before string
+	if req.LastUpdatedAfter != nil {
+		after = strconv.FormatInt(req.LastUpdatedAfter.UnixMilli(), 10)
+	}
+	if req.PageToken != "" {
+		cursor, err := decodeTaskPageToken(req.PageToken)
+		if err != nil {
+			return nil, err
+		}
+		before = strconv.FormatInt(cursor.UpdatedAt.UnixMilli(), 10)
+	}
+	switch {
+	case after != "" && before != "":
+		cond += " AND #u BETWEEN :a AND :b"
+		values[":a"] = map[string]string{"N": after}
+		values[":b"] = map[string]string{"N": before}
+	case after != "":
+		cond += " AND #u >= :a"
+		values[":a"] = map[string]string{"N": after}
+	case before != "":
+		cond += " AND #u <= :b"
+		values[":b"] = map[string]string{"N": before}
+	}
+	return map[string]any{
+		"TableName":                 s.Table,
+		"IndexName":                 s.IndexName,
+		"KeyConditionExpression":    cond,
+		"ExpressionAttributeNames":  map[string]string{"#k": "kind", "#u": "updated_ms"},
+		"ExpressionAttributeValues": values,
+		"ScanIndexForward":          false,
+	}, nil
+}
+
+// get reads a task, reporting false if it does not exist.
+func (s DynamoDBTaskStore) get(ctx context.Context, taskID a2a.TaskID) (storedTask, bool, error) {
+	key := fmt.Sprintf(`{"task_id":{"S":%q}}`, taskID)
+	out, err := awscli.Run(ctx, "dynamodb", "get-item", "--table-name", s.Table, "--key", key, "--consistent-read")
+	if err != nil {
+		return storedTask{}, false, err
+	}
+	var result struct {
+		Item *dynamoTaskItem
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return storedTask{}, false, fmt.Errorf("parsing get-item output: %w", err)
+	}
+	if result.Item == nil {
+		return storedTask{}, false, nil
+	}
+	return result.Item.decode()
+}
+
+// dynamoTaskItem is a task item as returned by the aws CLI.
+type dynamoTaskItem struct {
+	Task      struct{ S string } `json:"task"`
+	Version   struct{ N string } `json:"version"`
+	UpdatedAt struct{ S string } `json:"updated_at"`
+}
+
+// decode returns the stored task of the item.
+func (item dynamoTaskItem) decode() (storedTask, bool, error) {
+	return decodeStoredTask(item.Task.S, item.Version.N, item.UpdatedAt.S)
+}
+
+// decodeStoredTask decodes a task saved as JSON with its version and
+// RFC 3339 update time.
+func decodeStoredTask(data, version, updated string) (storedTask, bool, error) {
+	var task a2a.Task
+	if err := json.Unmarshal([]byte(data), &task); err != nil {
+		return storedTask{}, false, fmt.Errorf("decoding task: %w", err)
+	}
+	v, err := strconv.ParseInt(version, 10, 64)
+	if err != nil {
+		return storedTask{}, false, fmt.Errorf("decoding version of task %s: %w", task.ID, err)
+	}
+	updatedAt, err := time.Parse(time.RFC3339Nano, updated)
+	if err != nil {
+		return storedTask{}, false, fmt.Errorf("decoding update time of task %s: %w", task.ID, err)
+	}
+	return storedTask{Task: &task, Version: a2a.TaskVersion(v), UpdatedAt: updatedAt}, true, nil
+}
@@ -3,9 +3,10 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"time"
 
@@ -13,7 +14,10 @@ import (
 	"google.golang.org/adk/model"
 
 	"github.com/plexusone/agentkit/config"
+	agenthttp "github.com/plexusone/agentkit/http"
 	"github.com/plexusone/agentkit/llm"
+	"github.com/plexusone/agentkit/llm/stream"
+	"github.com/plexusone/agentkit/observability"
 )
 
 // BaseAgent provides common functionality for all agents.
@@ -23,6 +27,37 @@ type BaseAgent struct {
 	Model        model.LLM
 	ModelFactory *llm.ModelFactory
 	Name         string
+
+	// FetchRetry configures how FetchURL retries transient failures.
+	// Only errors its Retryable, by default http.IsTransient, accepts are
+	// retried, so transport errors other than timeouts, refused or reset
+	// connections and unexpected EOFs, such as DNS failures, fail at once.
+	FetchRetry RetryPolicy
+
+	// FetchCache caches the responses of FetchURL when set, e.g. a
+	// MemoryFetchCache or FileFetchCache shared by several agents.
+	// Default: nil (no caching)
+	FetchCache FetchCache
+
+	// FetchCacheTTL is how long cached responses without a Cache-Control
+	// max-age or Expires header stay fresh.
+	// Default: 0 (revalidated on every fetch)
+	FetchCacheTTL time.Duration
+
+	// Crawler applies robots.txt and per-host limits to FetchURL. The
+	// constructors create one from the crawl settings of the config; nil
+	// fetches without them.
+	Crawler *Crawler
+
+	// Logger receives the agent's logs, see Log.
+	// Default: nil (the handler set by SetLogHandler, or slog.Default())
+	Logger *slog.Logger
+
+	// Tracer records the spans of the agent's fetches and, through the
+	// ModelFactory, its LLM calls. The constructors use the factory's
+	// tracer, configured by the observability settings of the config.
+	// Default: nil (no tracing)
+	Tracer observability.Tracer
 }
 
 // NewBaseAgent creates a new base agent with LLM initialization.
@@ -31,17 +66,20 @@ func NewBaseAgent(cfg *config.Config, name string, timeoutSec int) (*BaseAgent,
 
 	// Create model using factory
 	modelFactory := llm.NewModelFactory(cfg)
-	llmModel, err := modelFactory.CreateModel(ctx)
+	llmModel, err := modelFactory.CreateModelForAgent(ctx, name)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create model: %w", err)
 	}
 
+	client := &http.Client{Timeout: time.Duration(timeoutSec) * time.Second}
 	return &BaseAgent{
 		Cfg:          cfg,
-		Client:       &http.Client{Timeout: time.Duration(timeoutSec) * time.Second},
+		Client:       client,
 		Model:        llmModel,
 		ModelFactory: modelFactory,
 		Name:         name,
+		Crawler:      NewCrawler(CrawlPolicyFromConfig(cfg), client),
+		Tracer:       modelFactory.Tracer(),
 	}, nil
 }
 
@@ -55,18 +93,21 @@ func NewBaseAgentSecure(ctx context.Context, name string, timeoutSec int, opts .
 
 	// Create model using factory
 	modelFactory := llm.NewModelFactory(secCfg.Config)
-	llmModel, err := modelFactory.CreateModel(ctx)
+	llmModel, err := modelFactory.CreateModelForAgent(ctx, name)
 	if err != nil {
 		_ = secCfg.Close()
 		return nil, nil, fmt.Errorf("failed to create model: %w", err)
 	}
 
+	client := &http.Client{Timeout: time.Duration(timeoutSec) * time.Second}
 	ba := &BaseAgent{
 		Cfg:          secCfg.Config,
-		Client:       &http.Client{Timeout: time.Duration(timeoutSec) * time.Second},
+		Client:       client,
 		Model:        llmModel,
 		ModelFactory: modelFactory,
 		Name:         name,
+		Crawler:      NewCrawler(CrawlPolicyFromConfig(secCfg.Config), client),
+		Tracer:       modelFactory.Tracer(),
 	}
 
 	return ba, secCfg, nil
@@ -85,46 +126,173 @@ func (ba *BaseAgent) GetProviderInfo() string {
 	return ba.ModelFactory.GetProviderInfo()
 }
 
+// GenerateStream streams the agent's model's response to prompt, see
+// llm.Stream. The stream ends with a chunk with Done and the usage, or
+// with an error.
+func (ba *BaseAgent) GenerateStream(ctx context.Context, prompt string) <-chan stream.Chunk {
+	return llm.StreamText(ctx, ba.Model, prompt)
+}
+
+// StartTrace starts the span of an invocation of the agent with input, the
+// root of a trace unless ctx already carries a span. The LLM calls and
+// fetches made with the returned context are recorded as its children.
+//
+//	ctx, span := ba.StartTrace(ctx, prompt)
+//	output, err := ba.run(ctx, prompt)
+//	span.End(output, err)
+func (ba *BaseAgent) StartTrace(ctx context.Context, input any) (context.Context, observability.Span) {
+	return observability.OrNoop(ba.Tracer).Start(ctx, observability.SpanAgent, ba.Name, input)
+}
+
 // FetchURL fetches content from a URL with proper error handling.
+// Transient failures, such as refused connections and HTTP 408, 429 and
+// 5xx responses, are retried with backoff, honoring Retry-After, as
+// configured by FetchRetry. With a
+// FetchCache, fresh cached responses are returned without a request, and
+// stale ones are revalidated with their ETag or Last-Modified. With a
+// Crawler, URLs robots.txt disallows fail with ErrRobotsDisallowed, and
+// requests wait for the rate limit and concurrency cap of their host.
+// Fetches are recorded as tool spans with the Tracer.
 func (ba *BaseAgent) FetchURL(ctx context.Context, url string, maxSizeMB int) (string, error) {
+	ctx, span := observability.OrNoop(ba.Tracer).Start(ctx, observability.SpanTool, "fetch_url", url)
+	body, err := ba.fetchURL(ctx, url, maxSizeMB)
+	span.SetMetadata("bytes", len(body))
+	span.End(nil, err)
+	return body, err
+}
+
+// fetchURL implements FetchURL.
+func (ba *BaseAgent) fetchURL(ctx context.Context, url string, maxSizeMB int) (string, error) {
+	maxBytes := int64(maxSizeMB * 1024 * 1024)
+	cached := ba.cachedResponse(ctx, url)
+	if cached != nil && cached.fresh() && int64(len(cached.Body)) < maxBytes {
+		return cached.Body, nil
+	}
+	if ba.Crawler != nil {
+		if err := ba.Crawler.Allowed(ctx, url); err != nil {
+			return "", err
+		}
+	}
+
+	policy := ba.FetchRetry.WithDefaults()
+	retryable := policy.Retryable
+	policy.Retryable = func(err error) bool {
+		var statusErr *agenthttp.StatusError
+		if errors.As(err, &statusErr) && statusErr.RetryAfter > policy.MaxBackoff {
+			return false
+		}
+		return retryable(err)
+	}
+	onRetry := policy.OnRetry
+	policy.OnRetry = func(attempt int, wait time.Duration, err error) {
+		ba.Log(ctx).Debug("retrying fetch", "url", url, "attempt", attempt, "wait", wait, "error", err)
+		if onRetry != nil {
+			onRetry(attempt, wait, err)
+		}
+	}
+
+	attempts := 0
+	var resp *CachedResponse
+	err := policy.Do(ctx, func(ctx context.Context) error {
+		attempts++
+		var err error
+		resp, err = ba.fetch(ctx, url, maxBytes, cached)
+		return err
+	})
+	if err != nil {
+		if attempts > 1 {
+			err = fmt.Errorf("%w (after %d attempts)", err, attempts)
+		}
+		return "", err
+	}
+	ba.cacheResponse(ctx, url, resp, maxBytes)
+	return resp.Body, nil
+}
+
+// fetch tries to fetch a URL once, revalidating cached if it is not nil.
+// Responses other than 200 OK fail with a *http.StatusError.
+func (ba *BaseAgent) fetch(ctx context.Context, url string, maxBytes int64, cached *CachedResponse) (*CachedResponse, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("User-Agent", fmt.Sprintf("AgentKit/%s", ba.Name))
+	if cached != nil && int64(len(cached.Body)) < maxBytes {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
 
+	if ba.Crawler != nil {
+		release, err := ba.Crawler.wait(ctx, url)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
 	resp, err := ba.Client.Do(req) //nolint:gosec // G704: URL provided by SDK user
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch URL: %w", err)
+		return nil, fmt.Errorf("failed to fetch URL: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		revalidated := *cached
+		if etag := resp.Header.Get("ETag"); etag != "" {
+			revalidated.ETag = etag
+		}
+		revalidated.freshness(resp.Header, ba.FetchCacheTTL)
+		return &revalidated, nil
+	}
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+		return nil, agenthttp.NewStatusError(req, resp)
 	}
 
 	// Limit response size
-	maxBytes := int64(maxSizeMB * 1024 * 1024)
 	limitedReader := io.LimitReader(resp.Body, maxBytes)
 	body, err := io.ReadAll(limitedReader)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	return string(body), nil
+	fetched := &CachedResponse{
+		Body:         string(body),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	fetched.freshness(resp.Header, ba.FetchCacheTTL)
+	return fetched, nil
 }
 
-// LogInfo logs an informational message with agent context.
-func (ba *BaseAgent) LogInfo(format string, args ...interface{}) {
-	msg := fmt.Sprintf(format, args...)
-	log.Printf("[%s] %s", ba.Name, msg)
+// cachedResponse returns the cached response for url, or nil if there is
+// none or no cache.
+func (ba *BaseAgent) cachedResponse(ctx context.Context, url string) *CachedResponse {
+	if ba.FetchCache == nil {
+		return nil
+	}
+	cached, err := ba.FetchCache.Get(ctx, url)
+	if err != nil {
+		if !errors.Is(err, ErrCacheMiss) {
+			ba.Log(ctx).Warn("reading fetch cache failed", "url", url, "error", err)
+		}
+		return nil
+	}
+	return cached
 }
 
-// LogError logs an error message with agent context.
-func (ba *BaseAgent) LogError(format string, args ...interface{}) {
-	msg := fmt.Sprintf(format, args...)
-	log.Printf("[%s] ERROR: %s", ba.Name, msg)
+// cacheResponse caches resp for url, unless its headers forbid it or it
+// was cut off at maxBytes.
+func (ba *BaseAgent) cacheResponse(ctx context.Context, url string, resp *CachedResponse, maxBytes int64) {
+	if ba.FetchCache == nil || resp.noStore || int64(len(resp.Body)) >= maxBytes {
+		return
+	}
+	if err := ba.FetchCache.Set(ctx, url, resp); err != nil {
+		ba.Log(ctx).Warn("writing fetch cache failed", "url", url, "error", err)
+	}
 }
 
 // Wrapper wraps common agent initialization patterns.
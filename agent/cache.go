@@ -0,0 +1,226 @@
+package agent
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrCacheMiss is returned by FetchCache.Get for URLs it has no response
+// for.
+var ErrCacheMiss = errors.New("fetch cache miss")
+
+// FetchCache stores the responses FetchURL fetches, keyed by URL, so
+// repeated fetches of the same page by an agent's iterations or by
+// parallel agents sharing the cache do not hit the site again. Stale
+// responses are kept, so FetchURL can revalidate them with their ETag or
+// Last-Modified instead of downloading them again.
+type FetchCache interface {
+	// Get returns the response stored for url, or ErrCacheMiss.
+	Get(ctx context.Context, url string) (*CachedResponse, error)
+
+	// Set stores resp for url, replacing any previous response.
+	Set(ctx context.Context, url string, resp *CachedResponse) error
+}
+
+// CachedResponse is a response stored in a FetchCache.
+type CachedResponse struct {
+	// Body is the content of the response.
+	Body string `json:"body"`
+
+	// ETag and LastModified validate the response with the site once it
+	// is stale.
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+
+	// Expires is when the response becomes stale, from its Cache-Control
+	// max-age or Expires header, or the agent's FetchCacheTTL.
+	Expires time.Time `json:"expires"`
+
+	// noStore is set for responses that must not be cached.
+	noStore bool
+}
+
+// fresh reports whether the response can be used without asking the site.
+func (r *CachedResponse) fresh() bool {
+	return time.Now().Before(r.Expires)
+}
+
+// validated reports whether the response can be revalidated once stale.
+func (r *CachedResponse) validated() bool {
+	return r.ETag != "" || r.LastModified != ""
+}
+
+// freshness sets r.Expires and r.noStore from the caching headers of a
+// response, using ttl for responses that do not say how long they stay
+// fresh.
+func (r *CachedResponse) freshness(h http.Header, ttl time.Duration) {
+	now := time.Now()
+	r.Expires = now.Add(ttl)
+	maxAge, hasMaxAge := time.Duration(0), false
+	for _, directive := range strings.Split(h.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-store":
+			r.noStore = true
+		case "no-cache":
+			maxAge, hasMaxAge = 0, true
+		case "max-age":
+			if seconds, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil && !hasMaxAge {
+				maxAge, hasMaxAge = time.Duration(seconds)*time.Second, true
+			}
+		}
+	}
+	switch {
+	case hasMaxAge:
+		if age, err := strconv.Atoi(h.Get("Age")); err == nil {
+			maxAge -= time.Duration(age) * time.Second
+		}
+		r.Expires = now.Add(maxAge)
+	case h.Get("Expires") != "":
+		// Invalid dates, such as "0", mean already expired.
+		expires, err := http.ParseTime(h.Get("Expires"))
+		if err != nil {
+			expires = now
+		}
+		r.Expires = expires
+	}
+	if !r.fresh() && !r.validated() {
+		r.noStore = true
+	}
+}
+
+// MemoryFetchCache keeps responses in memory, evicting the least recently
+// used beyond its capacity. It can be shared by the agents of a process.
+type MemoryFetchCache struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	order   *list.List // of *memoryEntry, most recently used first
+	entries map[string]*list.Element
+}
+
+// memoryEntry is an element of MemoryFetchCache.order.
+type memoryEntry struct {
+	url  string
+	resp CachedResponse
+}
+
+// NewMemoryFetchCache creates an empty in-memory cache holding up to
+// maxEntries responses. Zero or less means 1000.
+func NewMemoryFetchCache(maxEntries int) *MemoryFetchCache {
+	if maxEntries <= 0 {
+		maxEntries = 1000
+	}
+	return &MemoryFetchCache{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Get implements FetchCache.
+func (c *MemoryFetchCache) Get(ctx context.Context, url string) (*CachedResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[url]
+	if !ok {
+		return nil, ErrCacheMiss
+	}
+	c.order.MoveToFront(elem)
+	resp := elem.Value.(*memoryEntry).resp
+	return &resp, nil
+}
+
+// Set implements FetchCache.
+func (c *MemoryFetchCache) Set(ctx context.Context, url string, resp *CachedResponse) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[url]; ok {
+		elem.Value.(*memoryEntry).resp = *resp
+		c.order.MoveToFront(elem)
+		return nil
+	}
+	c.entries[url] = c.order.PushFront(&memoryEntry{url: url, resp: *resp})
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryEntry).url)
+	}
+	return nil
+}
+
+// FileFetchCache keeps responses as JSON files in a directory, named after
+// the SHA-256 of their URL, so they survive restarts and can be shared by
+// the processes of a machine. Files are never evicted; remove the
+// directory to clear the cache.
+type FileFetchCache struct {
+	dir string
+}
+
+// NewFileFetchCache creates a cache in dir, creating the directory if it
+// does not exist.
+func NewFileFetchCache(dir string) (*FileFetchCache, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return &FileFetchCache{dir: dir}, nil
+}
+
+// path returns the file of url's response.
+func (c *FileFetchCache) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Get implements FetchCache.
+func (c *FileFetchCache) Get(ctx context.Context, url string) (*CachedResponse, error) {
+	data, err := os.ReadFile(c.path(url))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrCacheMiss
+		}
+		return nil, fmt.Errorf("failed to read cache file: %w", err)
+	}
+	var resp CachedResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode cache file: %w", err)
+	}
+	return &resp, nil
+}
+
+// Set implements FetchCache. It writes a temporary file and renames it, so
+// concurrent readers never see a partial response.
+func (c *FileFetchCache) Set(ctx context.Context, url string, resp *CachedResponse) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache file: %w", err)
+	}
+	tmp, err := os.CreateTemp(c.dir, "fetch-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create cache file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path(url)); err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	return nil
+}
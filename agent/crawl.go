@@ -0,0 +1,335 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/plexusone/agentkit/config"
+)
+
+// ErrRobotsDisallowed is returned by FetchURL for URLs the site's
+// robots.txt disallows.
+var ErrRobotsDisallowed = errors.New("disallowed by robots.txt")
+
+// robotsAgent is the product token FetchURL looks for in robots.txt
+// user-agent lines, as in its "AgentKit/<agent>" User-Agent.
+const robotsAgent = "agentkit"
+
+// CrawlPolicy configures how politely FetchURL treats the sites it
+// fetches from.
+type CrawlPolicy struct {
+	// IgnoreRobots fetches URLs even if robots.txt disallows them, and
+	// ignores its Crawl-delay.
+	// Default: false (robots.txt is respected)
+	IgnoreRobots bool
+
+	// RateLimit is the most requests per minute sent to one host. A
+	// longer robots.txt Crawl-delay takes precedence.
+	// Default: 0 (unlimited)
+	RateLimit int
+
+	// MaxConcurrency is the most requests in flight to one host.
+	// Default: 4
+	MaxConcurrency int
+
+	// RobotsTTL is how long a host's robots.txt is cached.
+	// Default: 24 hours
+	RobotsTTL time.Duration
+}
+
+// CrawlPolicyFromConfig returns the crawl policy of the crawl settings of
+// cfg: CRAWL_IGNORE_ROBOTS, CRAWL_RATE_LIMIT and CRAWL_MAX_CONCURRENCY, or
+// the crawl block of the config file.
+func CrawlPolicyFromConfig(cfg *config.Config) CrawlPolicy {
+	return CrawlPolicy{
+		IgnoreRobots:   cfg.CrawlIgnoreRobots,
+		RateLimit:      cfg.CrawlRateLimit,
+		MaxConcurrency: cfg.CrawlMaxConcurrency,
+	}
+}
+
+// Crawler enforces a CrawlPolicy on fetches. Share one between agents,
+// e.g. by assigning it to their Crawler fields, so that its limits apply
+// to all their requests together.
+type Crawler struct {
+	policy CrawlPolicy
+	client *http.Client
+
+	mu    sync.Mutex
+	hosts map[string]*crawlHost
+}
+
+// crawlHost is the state of one host of a Crawler.
+type crawlHost struct {
+	slots chan struct{} // one per request in flight
+	next  time.Time     // earliest start of the next request
+
+	robotsMu      sync.Mutex
+	robots        *robotsRules
+	robotsExpires time.Time
+}
+
+// NewCrawler creates a crawler that fetches robots.txt with client.
+func NewCrawler(policy CrawlPolicy, client *http.Client) *Crawler {
+	if policy.MaxConcurrency <= 0 {
+		policy.MaxConcurrency = 4
+	}
+	if policy.RobotsTTL <= 0 {
+		policy.RobotsTTL = 24 * time.Hour
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Crawler{
+		policy: policy,
+		client: client,
+		hosts:  make(map[string]*crawlHost),
+	}
+}
+
+// host returns the state of the host of u.
+func (c *Crawler) host(u *url.URL) *crawlHost {
+	key := u.Scheme + "://" + u.Host
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	h, ok := c.hosts[key]
+	if !ok {
+		h = &crawlHost{slots: make(chan struct{}, c.policy.MaxConcurrency)}
+		c.hosts[key] = h
+	}
+	return h
+}
+
+// Allowed returns ErrRobotsDisallowed if robots.txt disallows fetching
+// rawURL, fetching and caching robots.txt as needed. A robots.txt that
+// cannot be fetched allows everything.
+func (c *Crawler) Allowed(ctx context.Context, rawURL string) error {
+	if c.policy.IgnoreRobots {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	rules := c.robotsRules(ctx, u)
+	if !rules.allowed(u.EscapedPath(), u.RawQuery) {
+		return fmt.Errorf("%s: %w", rawURL, ErrRobotsDisallowed)
+	}
+	return nil
+}
+
+// wait blocks until a request to rawURL may start under the rate limit and
+// concurrency cap of its host, and returns a function that ends it.
+func (c *Crawler) wait(ctx context.Context, rawURL string) (func(), error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	h := c.host(u)
+
+	select {
+	case h.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	release := func() { <-h.slots }
+
+	var interval time.Duration
+	if c.policy.RateLimit > 0 {
+		interval = time.Minute / time.Duration(c.policy.RateLimit)
+	}
+	if !c.policy.IgnoreRobots {
+		interval = max(interval, c.robotsRules(ctx, u).crawlDelay)
+	}
+	if interval > 0 {
+		c.mu.Lock()
+		start := time.Now()
+		if h.next.After(start) {
+			start = h.next
+		}
+		h.next = start.Add(interval)
+		c.mu.Unlock()
+		if err := sleep(ctx, time.Until(start)); err != nil {
+			release()
+			return nil, err
+		}
+	}
+	return release, nil
+}
+
+// robotsRules returns the rules of robots.txt for the host of u, fetching
+// it if they are not cached.
+func (c *Crawler) robotsRules(ctx context.Context, u *url.URL) *robotsRules {
+	h := c.host(u)
+	h.robotsMu.Lock()
+	defer h.robotsMu.Unlock()
+	if h.robots != nil && time.Now().Before(h.robotsExpires) {
+		return h.robots
+	}
+	rules, err := c.fetchRobots(ctx, u)
+	if err != nil {
+		// Allow everything, and try again soon rather than a day later.
+		h.robots, h.robotsExpires = &robotsRules{}, time.Now().Add(time.Minute)
+		return h.robots
+	}
+	h.robots, h.robotsExpires = rules, time.Now().Add(c.policy.RobotsTTL)
+	return rules
+}
+
+// fetchRobots fetches and parses robots.txt for the host of u. A missing
+// robots.txt allows everything.
+func (c *Crawler) fetchRobots(ctx context.Context, u *url.URL) (*robotsRules, error) {
+	robotsURL := (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/robots.txt"}).String()
+	req, err := http.NewRequestWithContext(ctx, "GET", robotsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "AgentKit")
+	resp, err := c.client.Do(req) //nolint:gosec // G704: URL provided by SDK user
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return &robotsRules{}, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+	}
+	// RFC 9309 requires parsing at least 500 KiB.
+	return parseRobots(io.LimitReader(resp.Body, 512*1024), robotsAgent), nil
+}
+
+// robotsRules are the rules of a robots.txt group.
+type robotsRules struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+// robotsRule is an Allow or Disallow line.
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+// parseRobots returns the rules of the group of robots.txt that applies to
+// agent, or of the "*" group if none names it, as RFC 9309 specifies.
+func parseRobots(r io.Reader, agent string) *robotsRules {
+	var specific, general robotsRules
+	var hasSpecific bool
+	var current []*robotsRules // groups the lines being read apply to
+	inAgents := false
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		if key == "user-agent" {
+			if !inAgents {
+				current = nil
+				inAgents = true
+			}
+			name := strings.ToLower(value)
+			switch {
+			case name == "*":
+				current = append(current, &general)
+			case name != "" && strings.Contains(agent, name):
+				current = append(current, &specific)
+				hasSpecific = true
+			}
+			continue
+		}
+		inAgents = false
+		for _, group := range current {
+			switch key {
+			case "allow", "disallow":
+				if value != "" {
+					group.rules = append(group.rules, robotsRule{allow: key == "allow", pattern: value})
+				}
+			case "crawl-delay":
+				if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+					group.crawlDelay = time.Duration(seconds * float64(time.Second))
+				}
+			}
+		}
+	}
+	if hasSpecific {
+		return &specific
+	}
+	return &general
+}
+
+// allowed reports whether the rules allow a path: the longest matching
+// rule wins, and Allow wins ties.
+func (r *robotsRules) allowed(path, query string) bool {
+	if path == "" {
+		path = "/"
+	}
+	if query != "" {
+		path += "?" + query
+	}
+	if path == "/robots.txt" {
+		return true
+	}
+	allow, longest := true, -1
+	for _, rule := range r.rules {
+		if !robotsMatch(rule.pattern, path) {
+			continue
+		}
+		if n := len(rule.pattern); n > longest || n == longest && rule.allow {
+			allow, longest = rule.allow, n
+		}
+	}
+	return allow
+}
+
+// robotsMatch reports whether a robots.txt path pattern, with "*"
+// wildcards and a "$" end anchor, matches the start of path.
+func robotsMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+	for i, part := range parts[1:] {
+		last := i == len(parts)-2
+		if last && anchored {
+			return strings.HasSuffix(rest, part)
+		}
+		idx := strings.Index(rest, part)
+		if idx < 0 {
+			return false
+		}
+		rest = rest[idx+len(part):]
+	}
+	return !anchored || rest == ""
+}
+
+// sleep waits for d or until ctx ends.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
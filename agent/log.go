@@ -0,0 +1,75 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+
+	agenthttp "github.com/plexusone/agentkit/http"
+	"github.com/plexusone/agentkit/httpserver"
+	"github.com/plexusone/agentkit/platforms/agentcore"
+)
+
+// logHandler is the handler set by SetLogHandler, or nil for
+// slog.Default().
+var logHandler atomic.Pointer[slog.Handler]
+
+// SetLogHandler sets the handler agents log to when their Logger is nil,
+// e.g. a slog.JSONHandler so the logs of a multi-agent system can be
+// queried by agent and request. nil restores slog.Default().
+func SetLogHandler(h slog.Handler) {
+	if h == nil {
+		logHandler.Store(nil)
+		return
+	}
+	logHandler.Store(&h)
+}
+
+// Log returns the agent's logger for work done for ctx, with the agent's
+// name and, when ctx has them, the request ID set by
+// httpserver.RequestID, the correlation ID shared by the agents serving
+// the request and the AgentCore session ID.
+//
+//	ba.Log(ctx).Warn("search returned no results", "query", query)
+func (ba *BaseAgent) Log(ctx context.Context) *slog.Logger {
+	logger := ba.Logger
+	if logger == nil {
+		if h := logHandler.Load(); h != nil {
+			logger = slog.New(*h)
+		} else {
+			logger = slog.Default()
+		}
+	}
+	logger = logger.With("agent", ba.Name)
+	if id := httpserver.RequestIDFromContext(ctx); id != "" {
+		logger = logger.With("request_id", id)
+	}
+	if id := agenthttp.CorrelationID(ctx); id != "" {
+		logger = logger.With("correlation_id", id)
+	}
+	if id := agentcore.SessionID(ctx); id != "" {
+		logger = logger.With("session_id", id)
+	}
+	return logger
+}
+
+// LogDebug logs a debug message with agent context.
+func (ba *BaseAgent) LogDebug(format string, args ...interface{}) {
+	ba.Log(context.Background()).Debug(fmt.Sprintf(format, args...))
+}
+
+// LogInfo logs an informational message with agent context.
+func (ba *BaseAgent) LogInfo(format string, args ...interface{}) {
+	ba.Log(context.Background()).Info(fmt.Sprintf(format, args...))
+}
+
+// LogWarn logs a warning with agent context.
+func (ba *BaseAgent) LogWarn(format string, args ...interface{}) {
+	ba.Log(context.Background()).Warn(fmt.Sprintf(format, args...))
+}
+
+// LogError logs an error message with agent context.
+func (ba *BaseAgent) LogError(format string, args ...interface{}) {
+	ba.Log(context.Background()).Error(fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,13 @@
+package agent
+
+import (
+	agenthttp "github.com/plexusone/agentkit/http"
+)
+
+// RetryPolicy configures how FetchURL retries fetches that fail for
+// transient reasons: those for which Retryable, by default
+// http.IsTransient, reports true, such as refused connections and HTTP
+// 408, 429 and 5xx responses other than 501. Other responses, such as
+// 404, are returned at once, and so are responses whose Retry-After asks
+// for a wait longer than MaxBackoff. It is the shared http.RetryPolicy.
+type RetryPolicy = agenthttp.RetryPolicy
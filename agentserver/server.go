@@ -0,0 +1,265 @@
+// Package agentserver hosts a set of agents on every protocol agentkit
+// speaks, in one process with one lifecycle: the AgentCore contract
+// (/ping, /invocations), MCP over HTTP, and plain HTTP handlers on one
+// port, and an A2A server per agent.
+//
+//	server, err := agentserver.New(ctx, agentserver.Config{
+//	    Agents:    []agentcore.Agent{research, synthesis},
+//	    HTTP:      httpserver.Config{Port: 8080},
+//	    AgentCore: &agentcore.Config{DefaultAgent: "research"},
+//	    MCPPath:   "/mcp",
+//	    A2A:       &a2a.Config{Port: "9001"},
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if err := server.Run(ctx); err != nil {
+//	    log.Fatal(err)
+//	}
+package agentserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/plexusone/agentkit/a2a"
+	"github.com/plexusone/agentkit/httpserver"
+	"github.com/plexusone/agentkit/mcp"
+	"github.com/plexusone/agentkit/platforms/agentcore"
+)
+
+// Config configures a Server.
+type Config struct {
+	// Name names the server in logs and in MCP and OpenAPI metadata.
+	// Default: "agentkit"
+	Name string
+
+	// Version is reported to MCP clients.
+	// Default: "1.0.0"
+	Version string
+
+	// Agents are the agents to host. Required.
+	Agents []agentcore.Agent
+
+	// HTTP configures the HTTP server, with its own handlers, routes,
+	// middlewares and logger. The AgentCore and MCP endpoints are added
+	// to its handlers, and the agents' health checks to its readiness
+	// checks.
+	// Default port: 8080
+	HTTP httpserver.Config
+
+	// AgentCore serves the AgentCore contract, /ping and /invocations, on
+	// the HTTP server. Its port and timeouts are ignored.
+	// Default: nil (disabled)
+	AgentCore *agentcore.Config
+
+	// MCPPath serves the agents as MCP tools on the HTTP server at this
+	// path (see mcp.HTTPHandler).
+	// Default: "" (disabled)
+	MCPPath string
+
+	// A2A serves each agent on its own A2A server, as an A2A server
+	// publishes one agent card. The agents use consecutive ports from
+	// Port, and from GRPCPort if set; "0" picks free ports.
+	// Default: nil (disabled)
+	A2A *a2a.Config
+}
+
+// Server hosts agents on several protocols.
+type Server struct {
+	config     Config
+	registry   *agentcore.Registry
+	agentCore  *agentcore.Server
+	httpServer *httpserver.Server
+	a2a        map[string]*a2a.Server
+
+	stopOnce sync.Once
+	stopErr  error
+}
+
+// New creates a server for cfg.Agents, initializing agents that implement
+// agentcore.Initializer. The A2A servers start listening at once, so
+// their URLs are known before Run.
+func New(ctx context.Context, cfg Config) (*Server, error) {
+	if len(cfg.Agents) == 0 {
+		return nil, fmt.Errorf("at least one agent is required")
+	}
+	if cfg.Name == "" {
+		cfg.Name = "agentkit"
+	}
+	if cfg.Version == "" {
+		cfg.Version = "1.0.0"
+	}
+	if cfg.HTTP.Name == "" {
+		cfg.HTTP.Name = cfg.Name
+	}
+	if cfg.HTTP.Port == 0 {
+		cfg.HTTP.Port = 8080
+	}
+
+	registry := agentcore.NewRegistry()
+	if err := registry.RegisterAll(ctx, cfg.Agents...); err != nil {
+		return nil, err
+	}
+	s := &Server{
+		config:   cfg,
+		registry: registry,
+		a2a:      make(map[string]*a2a.Server),
+	}
+
+	httpCfg := cfg.HTTP
+	httpCfg.Handlers = maps.Clone(cfg.HTTP.Handlers)
+	if httpCfg.Handlers == nil {
+		httpCfg.Handlers = make(map[string]http.Handler)
+	}
+	httpCfg.ReadyChecks = maps.Clone(cfg.HTTP.ReadyChecks)
+	if httpCfg.ReadyChecks == nil {
+		httpCfg.ReadyChecks = make(map[string]httpserver.ReadyCheck)
+	}
+	for _, agent := range cfg.Agents {
+		if hc, ok := agent.(agentcore.HealthChecker); ok {
+			httpCfg.ReadyChecks["agent:"+agent.Name()] = hc.HealthCheck
+		}
+	}
+	if cfg.AgentCore != nil {
+		s.agentCore = agentcore.NewServerWithRegistry(*cfg.AgentCore, registry)
+		handler := s.agentCore.Handler()
+		httpCfg.Handlers["/ping"] = handler
+		httpCfg.Handlers["/invocations"] = handler
+	}
+	if cfg.MCPPath != "" {
+		httpCfg.Handlers[cfg.MCPPath] = mcp.NewHTTPHandler(registry, cfg.Name, cfg.Version)
+	}
+	httpServer, err := httpserver.New(httpCfg)
+	if err != nil {
+		_ = registry.Close()
+		return nil, err
+	}
+	s.httpServer = httpServer
+
+	if cfg.A2A != nil {
+		if err := s.newA2AServers(*cfg.A2A); err != nil {
+			_ = s.stop(context.Background())
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// newA2AServers creates an A2A server per agent on consecutive ports.
+func (s *Server) newA2AServers(cfg a2a.Config) error {
+	for i, agent := range s.config.Agents {
+		agentCfg := cfg
+		var err error
+		if agentCfg.Port, err = offsetPort(cfg.Port, i); err != nil {
+			return fmt.Errorf("a2a port: %w", err)
+		}
+		if agentCfg.GRPCPort, err = offsetPort(cfg.GRPCPort, i); err != nil {
+			return fmt.Errorf("a2a gRPC port: %w", err)
+		}
+		server, err := a2a.NewAgentCoreServer(agent, agentCfg)
+		if err != nil {
+			return fmt.Errorf("a2a server for %s: %w", agent.Name(), err)
+		}
+		s.a2a[agent.Name()] = server
+	}
+	return nil
+}
+
+// offsetPort returns the port i after port, keeping "" and "0".
+func offsetPort(port string, i int) (string, error) {
+	if port == "" || port == "0" {
+		return port, nil
+	}
+	n, err := strconv.Atoi(port)
+	if err != nil {
+		return "", fmt.Errorf("invalid port %q", port)
+	}
+	return strconv.Itoa(n + i), nil
+}
+
+// A2AServer returns the A2A server of an agent, or nil if A2A is disabled
+// or there is no such agent.
+func (s *Server) A2AServer(agent string) *a2a.Server {
+	return s.a2a[agent]
+}
+
+// Registry returns the registry of the agents.
+func (s *Server) Registry() *agentcore.Registry {
+	return s.registry
+}
+
+// Run serves all protocols until ctx is done, the process receives SIGINT
+// or SIGTERM, or a server fails, then stops them all, waiting up to the
+// HTTP ShutdownTimeout for in-flight requests and tasks, and closes the
+// agents. It returns nil after a clean shutdown.
+func (s *Server) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	a2aErrs := make(chan error, len(s.a2a))
+	for name, server := range s.a2a {
+		go func() {
+			if err := server.Start(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				a2aErrs <- fmt.Errorf("a2a server for %s: %w", name, err)
+				cancel()
+			}
+		}()
+	}
+
+	err := s.httpServer.Run(ctx)
+
+	timeout := s.config.HTTP.ShutdownTimeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), timeout)
+	defer stopCancel()
+	errs := []error{err, s.stop(stopCtx)}
+	for {
+		select {
+		case err := <-a2aErrs:
+			errs = append(errs, err)
+		default:
+			return errors.Join(errs...)
+		}
+	}
+}
+
+// Stop stops the servers gracefully, which makes Run return, and closes
+// the agents.
+func (s *Server) Stop(ctx context.Context) error {
+	return errors.Join(s.httpServer.Stop(ctx), s.stop(ctx))
+}
+
+// stop stops the A2A servers and closes the agents, once.
+func (s *Server) stop(ctx context.Context) error {
+	s.stopOnce.Do(func() {
+		s.stopErr = s.stopAll(ctx)
+	})
+	return s.stopErr
+}
+
+// stopAll stops the A2A servers and closes the agents.
+func (s *Server) stopAll(ctx context.Context) error {
+	var errs []error
+	for name, server := range s.a2a {
+		if err := server.Stop(ctx); err != nil && !errors.Is(err, net.ErrClosed) {
+			errs = append(errs, fmt.Errorf("a2a server for %s: %w", name, err))
+		}
+	}
+	if s.agentCore != nil {
+		// Closes the registry and the audit sink.
+		errs = append(errs, s.agentCore.Stop(ctx))
+	} else {
+		errs = append(errs, s.registry.Close())
+	}
+	return errors.Join(errs...)
+}
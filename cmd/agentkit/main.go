@@ -3,11 +3,20 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/plexusone/agentkit/a2a/a2atest"
+	"github.com/plexusone/agentkit/config"
+	"github.com/plexusone/agentkit/platforms/agentcore/emulator"
 	"github.com/plexusone/agentkit/platforms/local/generate"
 )
 
@@ -33,6 +42,21 @@ func main() {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
+	case "emulate":
+		if err := runEmulate(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	case "conformance":
+		if err := runConformance(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	case "config":
+		if err := config.RunCLI(os.Args[2:], os.Stdout, os.Stderr); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
 	case "version", "-v", "--version":
 		fmt.Printf("%s version %s\n", programName, programVersion)
 	case "help", "-h", "--help":
@@ -53,6 +77,9 @@ Usage:
 Commands:
   generate    Generate Go code from multi-agent-spec
   run         Run agents directly from spec (interpreted mode)
+  emulate     Run a local AgentCore emulator in front of an agent container
+  conformance Check an A2A server against the A2A protocol
+  config      Scaffold, validate, print and diagnose agent config
   version     Show version information
   help        Show this help message
 
@@ -63,8 +90,17 @@ Examples:
   # Run workflow directly (interpreted mode)
   %s run --spec ./agent-team-prd --input "Review this feature"
 
+  # Test an AgentCore container locally
+  %s emulate --target http://localhost:8080
+
+  # Check an A2A agent's protocol conformance
+  %s conformance --url http://localhost:9001
+
+  # Check config, API keys and providers
+  %s config doctor
+
 Use "%s <command> --help" for more information about a command.
-`, programName, programName, programName, programName, programName)
+`, programName, programName, programName, programName, programName, programName, programName, programName)
 }
 
 func runGenerate(args []string) error {
@@ -222,3 +258,133 @@ Examples:
 
 	return nil
 }
+
+func runEmulate(args []string) error {
+	fs := flag.NewFlagSet("emulate", flag.ExitOnError)
+
+	var (
+		target  = fs.String("target", "http://localhost:8080", "Base URL of the agent container")
+		port    = fs.Int("port", 9090, "Port for the emulator and web UI")
+		timeout = fs.Duration("timeout", 300*time.Second, "Invocation timeout")
+		history = fs.Int("history", 100, "Number of invocations kept in the UI history")
+		quiet   = fs.Bool("quiet", false, "Disable request logging")
+	)
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Run a local AgentCore emulator in front of an agent container.
+
+The emulator implements the AgentCore Runtime contract: it assigns session
+IDs, forwards them via the X-Amzn-Bedrock-AgentCore-Runtime-Session-Id
+header, logs invocations, and serves a web UI for sending requests.
+
+Usage:
+  agentkit emulate [options]
+
+Options:
+`)
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, `
+Examples:
+  # Run the container, then the emulator
+  docker run -p 8080:8080 my-agent
+  agentkit emulate --target http://localhost:8080
+
+  # Invoke through the emulator
+  curl -X POST localhost:9090/invocations -d '{"prompt": "hello"}'
+`)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return emulator.Run(ctx, emulator.Config{
+		Port:                 *port,
+		Target:               *target,
+		InvocationTimeout:    *timeout,
+		HistorySize:          *history,
+		EnableRequestLogging: !*quiet,
+	})
+}
+
+// headerFlags collects repeated "Name: value" flags.
+type headerFlags http.Header
+
+func (h headerFlags) String() string { return "" }
+
+func (h headerFlags) Set(value string) error {
+	name, v, ok := strings.Cut(value, ":")
+	if !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("header %q is not Name: value", value)
+	}
+	http.Header(h).Add(strings.TrimSpace(name), strings.TrimSpace(v))
+	return nil
+}
+
+func runConformance(args []string) error {
+	fs := flag.NewFlagSet("conformance", flag.ExitOnError)
+
+	header := headerFlags{}
+	var (
+		url     = fs.String("url", "http://localhost:8080", "Base URL of the A2A server")
+		prompt  = fs.String("prompt", "Hello", "Text of the messages sent to the agent")
+		timeout = fs.Duration("timeout", 60*time.Second, "Timeout of each check")
+		skip    = fs.String("skip", "", "Comma-separated checks to skip")
+	)
+	fs.Var(header, "header", "Header added to every request, as \"Name: value\" (repeatable)")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Check an A2A server against the A2A protocol.
+
+Fetches the agent card, sends, streams and cancels tasks over JSON-RPC,
+and checks the error codes of malformed calls. Exits with status 1 if a
+check fails, so it can gate CI.
+
+Usage:
+  agentkit conformance [options]
+
+Options:
+`)
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, `
+Examples:
+  agentkit conformance --url http://localhost:9001
+
+  # Authenticate, and skip cancellation for an agent that answers at once
+  agentkit conformance --url https://agent.example.com \
+    --header "X-API-Key: $API_KEY" --skip cancel-task
+`)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	opts := a2atest.Options{Prompt: *prompt, Header: http.Header(header), Timeout: *timeout}
+	if *skip != "" {
+		opts.Skip = strings.Split(*skip, ",")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	failed := 0
+	for _, result := range a2atest.Check(ctx, *url, opts) {
+		switch {
+		case result.Skipped != "":
+			fmt.Printf("SKIP  %s: %s\n", result.Name, result.Skipped)
+		case result.Err != nil:
+			failed++
+			fmt.Printf("FAIL  %s: %v\n", result.Name, result.Err)
+		default:
+			fmt.Printf("PASS  %s\n", result.Name)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d conformance checks failed", failed)
+	}
+	return nil
+}
@@ -0,0 +1,486 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LocalConfigFile is the developer-local override file scaffolded by
+// "config init" and layered over the project config by the config CLI.
+// It should not be committed.
+const LocalConfigFile = "local.yaml"
+
+// RunCLI runs a config subcommand and writes its output to stdout and
+// stderr. It is meant to be embedded in an agent's own CLI:
+//
+//	case "config":
+//	    if err := config.RunCLI(os.Args[2:], os.Stdout, os.Stderr); err != nil {
+//	        os.Exit(1)
+//	    }
+//
+// Subcommands:
+//
+//	init       Scaffold config.yaml and local.yaml
+//	validate   Validate config files (unknown fields, invalid values)
+//	print      Print the effective config, with secrets redacted
+//	doctor     Diagnose missing API keys and provider problems
+func RunCLI(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		printCLIUsage(stderr)
+		return errors.New("missing config subcommand")
+	}
+	c := &cli{stdout: stdout, stderr: stderr}
+	switch args[0] {
+	case "init":
+		return c.runInit(args[1:])
+	case "validate":
+		return c.runValidate(args[1:])
+	case "print":
+		return c.runPrint(args[1:])
+	case "doctor":
+		return c.runDoctor(args[1:])
+	case "help", "-h", "--help":
+		printCLIUsage(stdout)
+		return nil
+	default:
+		printCLIUsage(stderr)
+		return fmt.Errorf("unknown config subcommand: %s", args[0])
+	}
+}
+
+// printCLIUsage prints the config subcommands.
+func printCLIUsage(w io.Writer) {
+	fmt.Fprint(w, `Manage agent configuration.
+
+Usage:
+  config <subcommand> [options]
+
+Subcommands:
+  init       Scaffold config.yaml and local.yaml
+  validate   Validate config files (unknown fields, invalid values)
+  print      Print the effective config, with secrets redacted
+  doctor     Diagnose missing API keys and provider problems
+
+Use "config <subcommand> --help" for more information about a subcommand.
+`)
+}
+
+// cli holds the output streams of a config CLI run.
+type cli struct {
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// flagSet creates a flag set that reports errors instead of exiting.
+func (c *cli) flagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "%s\n\nUsage:\n  config %s [options]\n\nOptions:\n", usage, name)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// configFlag adds the --config flag, which lists the config files to
+// layer, and returns a function that resolves them.
+func configFlag(fs *flag.FlagSet) func() ([]string, error) {
+	files := fs.String("config", "", "Comma-separated config files or URIs, lowest precedence first (default: config file search, then "+LocalConfigFile+")")
+	return func() ([]string, error) {
+		if *files != "" {
+			return strings.Split(*files, ","), nil
+		}
+		path, err := findConfigFile(GetProjectName())
+		if err != nil {
+			return nil, err
+		}
+		paths := []string{path}
+		local := filepath.Join(filepath.Dir(path), LocalConfigFile)
+		if _, err := os.Stat(local); err == nil && local != path {
+			paths = append(paths, local)
+		}
+		return paths, nil
+	}
+}
+
+// configTemplate is the config.yaml written by "config init".
+const configTemplate = `# Agent configuration. Secrets (API keys) do not belong here; they are
+# read from the secrets provider or environment variables.
+llm:
+  provider: %s
+  model: %s
+
+search:
+  provider: serper
+
+observability:
+  enabled: false
+  provider: opik
+  project: %s
+
+a2a:
+  enabled: true
+  authType: apikey
+
+secrets:
+  provider: env
+
+# Per-environment overrides, selected by AGENT_ENV.
+# profiles:
+#   prod:
+#     observability:
+#       enabled: true
+#     secrets:
+#       provider: aws-sm
+#       prefix: %s/
+`
+
+// localTemplate is the local.yaml written by "config init".
+const localTemplate = `# Developer-local overrides, layered over config.yaml. Do not commit.
+# llm:
+#   provider: ollama
+#   baseUrl: http://localhost:11434
+`
+
+// runInit scaffolds config.yaml and local.yaml.
+func (c *cli) runInit(args []string) error {
+	fs := c.flagSet("init", "Scaffold config.yaml and local.yaml.")
+	dir := fs.String("dir", ".", "Directory to write the files to")
+	provider := fs.String("provider", "gemini", "LLM provider")
+	force := fs.Bool("force", false, "Overwrite existing files")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	project := filepath.Base(*dir)
+	if abs, err := filepath.Abs(*dir); err == nil {
+		project = filepath.Base(abs)
+	}
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"config.yaml", fmt.Sprintf(configTemplate, *provider, GetDefaultModel(*provider), project, project)},
+		{LocalConfigFile, localTemplate},
+	}
+	for _, f := range files {
+		path := filepath.Join(*dir, f.name)
+		if _, err := os.Stat(path); err == nil && !*force {
+			fmt.Fprintf(c.stdout, "skipped %s (exists; use --force to overwrite)\n", path)
+			continue
+		}
+		if err := os.WriteFile(path, []byte(f.content), 0600); err != nil {
+			return fmt.Errorf("writing %s: %w", path, err)
+		}
+		fmt.Fprintf(c.stdout, "wrote %s\n", path)
+	}
+	fmt.Fprintf(c.stdout, "Add %s to .gitignore.\n", LocalConfigFile)
+	return nil
+}
+
+// runValidate validates each config file.
+func (c *cli) runValidate(args []string) error {
+	fs := c.flagSet("validate", "Validate config files: unknown fields and invalid values.")
+	configFiles := configFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	paths := fs.Args()
+	if len(paths) == 0 {
+		var err error
+		if paths, err = configFiles(); err != nil {
+			return err
+		}
+	}
+
+	invalid := 0
+	for _, path := range paths {
+		var warnings []KeyChange
+		if report, err := CheckDeprecatedKeys(path); err == nil {
+			warnings = report.Changes
+		}
+		errs := ValidateConfigFile(path)
+		if len(errs) == 0 && len(warnings) == 0 {
+			fmt.Fprintf(c.stdout, "%s: ok\n", path)
+			continue
+		}
+		if len(errs) > 0 {
+			invalid++
+		}
+		fmt.Fprintf(c.stdout, "%s:\n", path)
+		for _, w := range warnings {
+			fmt.Fprintf(c.stdout, "  warning: %s\n", w)
+		}
+		for _, err := range errs {
+			fmt.Fprintf(c.stdout, "  %v\n", err)
+		}
+	}
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d config files are invalid", invalid, len(paths))
+	}
+	return nil
+}
+
+// runPrint prints the effective config.
+func (c *cli) runPrint(args []string) error {
+	fs := c.flagSet("print", "Print the effective config: layered files, profile, defaults and environment overrides.")
+	configFiles := configFlag(fs)
+	format := fs.String("format", "yaml", "Output format: yaml or json")
+	redacted := fs.Bool("redacted", true, "Redact tokens, passwords and URL credentials")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cfg, err := c.loadEffective(configFiles)
+	if err != nil {
+		return err
+	}
+
+	// Round-trip through JSON to print config file field names.
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	delete(out, "profiles")
+	if *redacted {
+		redactConfigValue(out)
+	}
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(c.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	case "yaml":
+		enc := yaml.NewEncoder(c.stdout)
+		enc.SetIndent(2)
+		if err := enc.Encode(out); err != nil {
+			return err
+		}
+		return enc.Close()
+	default:
+		return fmt.Errorf("unsupported format: %s (use yaml or json)", *format)
+	}
+}
+
+// loadEffective loads the layered config files with defaults and
+// environment overrides applied.
+func (c *cli) loadEffective(configFiles func() ([]string, error)) (*ConfigFile, error) {
+	paths, err := configFiles()
+	if err != nil {
+		// No config file: defaults and environment only.
+		paths = nil
+	}
+	cfg, err := LoadLayered(paths...)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.Defaults().MergeEnv(), nil
+}
+
+// redactConfigValue redacts sensitive values in decoded config data.
+func redactConfigValue(v interface{}) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return
+	}
+	for k, val := range m {
+		switch val := val.(type) {
+		case map[string]interface{}:
+			redactConfigValue(val)
+		case string:
+			if val == "" {
+				continue
+			}
+			if sensitiveKeyPattern.MatchString(k) {
+				m[k] = redactedValue
+			} else {
+				m[k] = redactURL(val)
+			}
+		}
+	}
+}
+
+// doctorCheck is the outcome of one "config doctor" check.
+type doctorCheck struct {
+	level   string // "ok", "warn" or "FAIL"
+	message string
+}
+
+// runDoctor diagnoses config problems.
+func (c *cli) runDoctor(args []string) error {
+	fs := c.flagSet("doctor", "Diagnose missing API keys and provider problems.")
+	configFiles := configFlag(fs)
+	verify := fs.Bool("verify", false, "Verify API keys with a cheap authenticated call to each provider")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var checks []doctorCheck
+	add := func(level, format string, a ...interface{}) {
+		checks = append(checks, doctorCheck{level: level, message: fmt.Sprintf(format, a...)})
+	}
+
+	paths, err := configFiles()
+	if err != nil {
+		add("warn", "no config file found; using defaults and environment variables")
+	}
+	for _, path := range paths {
+		if errs := ValidateConfigFile(path); len(errs) > 0 {
+			add("FAIL", "%s: %v", path, errors.Join(errs...))
+		} else {
+			add("ok", "%s is valid", path)
+		}
+	}
+
+	cfg, err := c.loadEffective(configFiles)
+	if err != nil {
+		add("FAIL", "loading config: %v", err)
+		return c.printChecks(checks)
+	}
+	for _, err := range cfg.Validate() {
+		add("FAIL", "%v", err)
+	}
+
+	ctx := context.Background()
+	secrets, err := NewSecretsClient(SecretsConfig{
+		Provider:      SecretsProvider(cfg.Secrets.Provider),
+		Prefix:        cfg.Secrets.Prefix,
+		Region:        cfg.Secrets.Region,
+		Project:       cfg.Secrets.Project,
+		Vault:         cfg.Secrets.Vault,
+		Doppler:       cfg.Secrets.Doppler,
+		OnePassword:   cfg.Secrets.OnePassword,
+		Identity:      cfg.Secrets.Identity,
+		FallbackToEnv: true,
+	})
+	if err != nil {
+		add("FAIL", "secrets provider %s: %v", cfg.Secrets.Provider, err)
+		return c.printChecks(checks)
+	}
+	defer secrets.Close()
+	diag := secrets.Diagnose(ctx)
+	if diag.Reachable {
+		add("ok", "secrets provider %s: reachable in %s", diag.Provider, diag.Latency.Round(time.Millisecond))
+	}
+	if diag.Identity != "" {
+		add("ok", "secrets provider %s: %s", diag.Provider, diag.Identity)
+	}
+	for _, p := range diag.Problems {
+		add("FAIL", "secrets provider %s: %s", diag.Provider, p)
+	}
+	for _, w := range diag.Warnings {
+		add("warn", "secrets provider %s: %s", diag.Provider, w)
+	}
+
+	// hasSecret reports which of names, if any, has a value.
+	hasSecret := func(names ...string) (string, bool) {
+		for _, name := range names {
+			if v, err := secrets.Get(ctx, name); err == nil && v != "" {
+				return name, true
+			}
+		}
+		return "", false
+	}
+	requireSecret := func(level, what string, names ...string) {
+		if name, ok := hasSecret(names...); ok {
+			add("ok", "%s: %s is set", what, name)
+		} else if len(names) == 1 {
+			add(level, "%s: %s is not set", what, names[0])
+		} else {
+			add(level, "%s: none of %s is set", what, strings.Join(names, ", "))
+		}
+	}
+
+	llmKeys := map[string][]string{
+		"gemini":  {"LLM_API_KEY", "GEMINI_API_KEY", "GOOGLE_API_KEY"},
+		"claude":  {"LLM_API_KEY", "CLAUDE_API_KEY", "ANTHROPIC_API_KEY"},
+		"openai":  {"LLM_API_KEY", "OPENAI_API_KEY"},
+		"xai":     {"LLM_API_KEY", "XAI_API_KEY"},
+		"bedrock": {"LLM_API_KEY", "AWS_BEARER_TOKEN_BEDROCK"},
+		"azure":   {"LLM_API_KEY", "AZURE_OPENAI_API_KEY"},
+		"groq":    {"LLM_API_KEY", "GROQ_API_KEY"},
+		"mistral": {"LLM_API_KEY", "MISTRAL_API_KEY"},
+	}
+	// Check the global provider and every per-agent provider override.
+	providers := []string{cfg.LLM.Provider}
+	for _, name := range sortedKeys(cfg.Agents) {
+		if p := cfg.Agents[name].Provider; p != "" && !slices.Contains(providers, p) {
+			providers = append(providers, p)
+		}
+	}
+	for _, provider := range providers {
+		if keys, ok := llmKeys[provider]; ok {
+			requireSecret("FAIL", "llm provider "+provider, keys...)
+		} else if provider == "ollama" {
+			add("ok", "llm provider ollama needs no API key")
+		}
+	}
+
+	searchKeys := map[string]string{"serper": "SERPER_API_KEY", "serpapi": "SERPAPI_API_KEY"}
+	if key, ok := searchKeys[cfg.Search.Provider]; ok {
+		requireSecret("warn", "search provider "+cfg.Search.Provider, key)
+	}
+
+	if cfg.Observability.Enabled && cfg.Observability.Provider != "phoenix" {
+		requireSecret("FAIL", "observability provider "+cfg.Observability.Provider, "OBSERVABILITY_API_KEY", "OPIK_API_KEY")
+	}
+
+	if cfg.A2A.Enabled && cfg.A2A.AuthType == "apikey" && os.Getenv("A2A_AUTH_TOKEN") == "" {
+		add("warn", "a2a apikey auth: A2A_AUTH_TOKEN is not set")
+	}
+
+	if *verify {
+		var opts []LoadOption
+		if len(paths) > 0 {
+			opts = append(opts, WithConfigFiles(paths...))
+		}
+		loaded, err := Load(ctx, opts...)
+		if err != nil {
+			add("FAIL", "loading config for verification: %v", err)
+			return c.printChecks(checks)
+		}
+		defer loaded.Close()
+		for _, check := range loaded.VerifyCredentials(ctx).Checks {
+			level := "ok"
+			switch {
+			case check.Failed():
+				level = "FAIL"
+			case check.Status == CredentialSkipped:
+				level = "skip"
+			}
+			add(level, "verify %s", check)
+		}
+	}
+
+	return c.printChecks(checks)
+}
+
+// printChecks prints doctor checks and returns an error if any failed.
+func (c *cli) printChecks(checks []doctorCheck) error {
+	failed := 0
+	for _, check := range checks {
+		fmt.Fprintf(c.stdout, "[%-4s] %s\n", check.level, check.message)
+		if check.level == "FAIL" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("config doctor found %d problem(s)", failed)
+	}
+	return nil
+}
@@ -12,17 +12,26 @@ package config
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
 	"os"
+	"strconv"
+	"strings"
 )
 
 // Config holds the application configuration.
 type Config struct {
 	// LLM Configuration
-	LLMProvider string // "gemini", "claude", "openai", "ollama", "xai"
+	LLMProvider string // "gemini", "claude", "openai", "ollama", "xai", "bedrock", "azure", "groq", "mistral"
 	LLMAPIKey   string
 	LLMModel    string
 	LLMBaseURL  string // For Ollama or custom endpoints
 
+	// Rate limits of the calls made with each provider API key, shared
+	// by the agents of the process (0 = unlimited, see llm.RateLimit)
+	LLMRequestsPerMinute int
+	LLMTokensPerMinute   int
+
 	// Provider-specific API keys
 	GeminiAPIKey string
 	ClaudeAPIKey string
@@ -30,6 +39,19 @@ type Config struct {
 	XAIAPIKey    string
 	OllamaURL    string
 
+	// AWS Bedrock: an API key (sent as a bearer token) and the region of
+	// the runtime endpoint
+	BedrockAPIKey string
+	BedrockRegion string
+
+	// Azure OpenAI: an API key and the resource endpoint, e.g.
+	// https://my-resource.openai.azure.com. Models are deployment names.
+	AzureOpenAIAPIKey   string
+	AzureOpenAIEndpoint string
+
+	GroqAPIKey    string
+	MistralAPIKey string
+
 	// Search Configuration
 	SearchProvider string // "serper", "serpapi"
 	SerperAPIKey   string
@@ -38,6 +60,12 @@ type Config struct {
 	// Agent URLs (for multi-agent systems)
 	AgentURLs map[string]string
 
+	// Agent descriptions from the config file, keyed by agent name
+	AgentDescriptions map[string]string
+
+	// Per-agent LLM overrides, keyed by agent name (see ResolveAgentLLM)
+	AgentLLM map[string]AgentLLMConfig
+
 	// A2A Protocol Configuration
 	A2AEnabled   bool
 	A2AAuthType  string // "jwt", "apikey", "oauth2"
@@ -55,92 +83,97 @@ type Config struct {
 	SecurityMinScore     int  // Minimum security score (0-100)
 	SecurityRequireEncry bool // Require disk encryption
 
+	// Crawl Configuration (see agent.CrawlPolicyFromConfig)
+	CrawlIgnoreRobots   bool // Fetch URLs that robots.txt disallows
+	CrawlRateLimit      int  // Requests per minute per host (0 = unlimited)
+	CrawlMaxConcurrency int  // Requests in flight per host
+
 	// Secrets Configuration (OmniVault)
 	secrets *SecretsClient
+
+	// provenance records the source of each value set by Load.
+	provenance map[string]Source
 }
 
-// LoadConfig loads configuration from environment variables.
+// LoadConfig loads configuration from environment variables. Use Load to
+// also read config files, the secrets provider and flags.
+//
+// Invalid values (such as A2A_ENABLED=yes) are logged and replaced with
+// their defaults; use LoadConfigWithSecrets to fail on them instead.
 func LoadConfig() *Config {
-	provider := getEnv("LLM_PROVIDER", "gemini")
+	r := NewResolver(nil)
+	provider := r.String("LLM_PROVIDER", "gemini")
 
 	cfg := &Config{
 		// LLM settings
 		LLMProvider: provider,
-		LLMAPIKey:   getEnv("LLM_API_KEY", ""),
-		LLMModel:    getEnv("LLM_MODEL", GetDefaultModel(provider)),
-		LLMBaseURL:  getEnv("LLM_BASE_URL", ""),
+		LLMAPIKey:   r.String("LLM_API_KEY", ""),
+		LLMModel:    r.String("LLM_MODEL", GetDefaultModel(provider)),
+		LLMBaseURL:  r.String("LLM_BASE_URL", ""),
+
+		LLMRequestsPerMinute: r.Int("LLM_REQUESTS_PER_MINUTE", 0),
+		LLMTokensPerMinute:   r.Int("LLM_TOKENS_PER_MINUTE", 0),
 
 		// Provider-specific API keys
-		GeminiAPIKey: getEnv("GEMINI_API_KEY", getEnv("GOOGLE_API_KEY", "")),
-		ClaudeAPIKey: getEnv("CLAUDE_API_KEY", getEnv("ANTHROPIC_API_KEY", "")),
-		OpenAIAPIKey: getEnv("OPENAI_API_KEY", ""),
-		XAIAPIKey:    getEnv("XAI_API_KEY", ""),
-		OllamaURL:    getEnv("OLLAMA_URL", "http://localhost:11434"),
+		GeminiAPIKey: r.String("GEMINI_API_KEY", r.String("GOOGLE_API_KEY", "")),
+		ClaudeAPIKey: r.String("CLAUDE_API_KEY", r.String("ANTHROPIC_API_KEY", "")),
+		OpenAIAPIKey: r.String("OPENAI_API_KEY", ""),
+		XAIAPIKey:    r.String("XAI_API_KEY", ""),
+		OllamaURL:    r.String("OLLAMA_URL", "http://localhost:11434"),
+
+		BedrockAPIKey:       r.String("AWS_BEARER_TOKEN_BEDROCK", ""),
+		BedrockRegion:       r.String("AWS_REGION", r.String("AWS_DEFAULT_REGION", "us-east-1")),
+		AzureOpenAIAPIKey:   r.String("AZURE_OPENAI_API_KEY", ""),
+		AzureOpenAIEndpoint: r.String("AZURE_OPENAI_ENDPOINT", ""),
+		GroqAPIKey:          r.String("GROQ_API_KEY", ""),
+		MistralAPIKey:       r.String("MISTRAL_API_KEY", ""),
 
 		// Search settings
-		SearchProvider: getEnv("SEARCH_PROVIDER", "serper"),
-		SerperAPIKey:   getEnv("SERPER_API_KEY", ""),
-		SerpAPIKey:     getEnv("SERPAPI_API_KEY", ""),
+		SearchProvider: r.String("SEARCH_PROVIDER", "serper"),
+		SerperAPIKey:   r.String("SERPER_API_KEY", ""),
+		SerpAPIKey:     r.String("SERPAPI_API_KEY", ""),
 
-		// Agent URLs
-		AgentURLs: make(map[string]string),
+		// Agents
+		AgentURLs:         make(map[string]string),
+		AgentDescriptions: make(map[string]string),
+		AgentLLM:          make(map[string]AgentLLMConfig),
 
 		// A2A Protocol
-		A2AEnabled:   getEnv("A2A_ENABLED", "true") == "true",
-		A2AAuthType:  getEnv("A2A_AUTH_TYPE", "apikey"),
-		A2AAuthToken: getEnv("A2A_AUTH_TOKEN", ""),
+		A2AEnabled:   r.Bool("A2A_ENABLED", true),
+		A2AAuthType:  r.String("A2A_AUTH_TYPE", "apikey"),
+		A2AAuthToken: r.String("A2A_AUTH_TOKEN", ""),
 
 		// Observability
-		ObservabilityEnabled:  getEnv("OBSERVABILITY_ENABLED", "false") == "true",
-		ObservabilityProvider: getEnv("OBSERVABILITY_PROVIDER", "opik"),
-		ObservabilityAPIKey:   getEnv("OBSERVABILITY_API_KEY", getEnv("OPIK_API_KEY", "")),
-		ObservabilityEndpoint: getEnv("OBSERVABILITY_ENDPOINT", ""),
-		ObservabilityProject:  getEnv("OBSERVABILITY_PROJECT", "agentkit"),
+		ObservabilityEnabled:  r.Bool("OBSERVABILITY_ENABLED", false),
+		ObservabilityProvider: r.String("OBSERVABILITY_PROVIDER", "opik"),
+		ObservabilityAPIKey:   r.String("OBSERVABILITY_API_KEY", r.String("OPIK_API_KEY", "")),
+		ObservabilityEndpoint: r.String("OBSERVABILITY_ENDPOINT", ""),
+		ObservabilityProject:  r.String("OBSERVABILITY_PROJECT", "agentkit"),
 
 		// Security
-		SecurityEnabled:      getEnv("SECURITY_ENABLED", "false") == "true",
+		SecurityEnabled:      r.Bool("SECURITY_ENABLED", false),
 		SecurityMinScore:     50,
-		SecurityRequireEncry: getEnv("SECURITY_REQUIRE_ENCRYPTION", "false") == "true",
-	}
+		SecurityRequireEncry: r.Bool("SECURITY_REQUIRE_ENCRYPTION", false),
 
-	// Set LLMAPIKey based on provider if not explicitly set
-	if cfg.LLMAPIKey == "" {
-		switch provider {
-		case "gemini":
-			cfg.LLMAPIKey = cfg.GeminiAPIKey
-		case "claude":
-			cfg.LLMAPIKey = cfg.ClaudeAPIKey
-		case "openai":
-			cfg.LLMAPIKey = cfg.OpenAIAPIKey
-		case "xai":
-			cfg.LLMAPIKey = cfg.XAIAPIKey
-		}
+		// Crawling
+		CrawlIgnoreRobots:   r.Bool("CRAWL_IGNORE_ROBOTS", false),
+		CrawlRateLimit:      r.Int("CRAWL_RATE_LIMIT", 0),
+		CrawlMaxConcurrency: r.Int("CRAWL_MAX_CONCURRENCY", 4),
 	}
 
-	// Set LLMBaseURL for Ollama if not explicitly set
-	if cfg.LLMBaseURL == "" && provider == "ollama" {
-		cfg.LLMBaseURL = cfg.OllamaURL
+	cfg.applyLLMDefaults()
+
+	for _, err := range r.Errors() {
+		slog.Warn("ignoring invalid config value", "error", err)
 	}
 
 	return cfg
 }
 
-// GetDefaultModel returns the default model for a given provider.
+// GetDefaultModel returns the default model for a given provider from the
+// current model catalog (see ModelCatalog).
 func GetDefaultModel(provider string) string {
-	switch provider {
-	case "gemini":
-		return "gemini-2.0-flash-exp"
-	case "claude":
-		return "claude-sonnet-4-20250514"
-	case "openai":
-		return "gpt-4o"
-	case "xai":
-		return "grok-3"
-	case "ollama":
-		return "llama3.2:latest"
-	default:
-		return "gemini-2.0-flash-exp"
-	}
+	return CurrentModelCatalog().DefaultModel(provider)
 }
 
 // SetAgentURL sets a URL for a named agent.
@@ -154,7 +187,89 @@ func (c *Config) GetAgentURL(name string) string {
 		return url
 	}
 	// Try environment variable fallback
-	return getEnv(name+"_URL", "")
+	if url := getEnv(name+"_URL", ""); url != "" {
+		return url
+	}
+	return getEnv(agentURLEnv(name), "")
+}
+
+// GetAgentDescription gets the description of a named agent.
+func (c *Config) GetAgentDescription(name string) string {
+	return c.AgentDescriptions[name]
+}
+
+// agentURLEnv returns the environment variable that overrides the URL of
+// a named agent: the upper-cased name with other characters replaced by
+// "_", plus "_URL". For example, "research-agent" is RESEARCH_AGENT_URL.
+func agentURLEnv(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, strings.ToUpper(name)) + "_URL"
+}
+
+// AgentLLMConfig holds the LLM settings of one agent. As an override in
+// Config.AgentLLM, empty fields fall back to the global LLM settings.
+type AgentLLMConfig struct {
+	Provider    string
+	Model       string
+	Temperature *float64 // nil uses the provider default
+	BaseURL     string
+}
+
+// String formats the settings, showing the temperature rather than its
+// pointer.
+func (l AgentLLMConfig) String() string {
+	temperature := "default"
+	if l.Temperature != nil {
+		temperature = strconv.FormatFloat(*l.Temperature, 'g', -1, 64)
+	}
+	return fmt.Sprintf("{Provider: %s, Model: %s, Temperature: %s, BaseURL: %s}", l.Provider, l.Model, temperature, l.BaseURL)
+}
+
+// SetAgentLLM sets the LLM overrides for a named agent.
+func (c *Config) SetAgentLLM(name string, llm AgentLLMConfig) {
+	if c.AgentLLM == nil {
+		c.AgentLLM = make(map[string]AgentLLMConfig)
+	}
+	c.AgentLLM[name] = llm
+}
+
+// ResolveAgentLLM returns the effective LLM settings for a named agent:
+// its overrides from AgentLLM, falling back to the global LLM settings.
+// An agent that switches provider without naming a model gets the
+// provider's default model rather than the global one.
+func (c *Config) ResolveAgentLLM(name string) AgentLLMConfig {
+	global := AgentLLMConfig{
+		Provider: c.LLMProvider,
+		Model:    c.LLMModel,
+		BaseURL:  c.LLMBaseURL,
+	}
+	override, ok := c.AgentLLM[name]
+	if !ok {
+		return global
+	}
+
+	resolved := override
+	if resolved.Provider == "" || resolved.Provider == global.Provider {
+		resolved.Provider = global.Provider
+		if resolved.Model == "" {
+			resolved.Model = global.Model
+		}
+		if resolved.BaseURL == "" {
+			resolved.BaseURL = global.BaseURL
+		}
+		return resolved
+	}
+	if resolved.Model == "" {
+		resolved.Model = GetDefaultModel(resolved.Provider)
+	}
+	if resolved.BaseURL == "" && resolved.Provider == "ollama" {
+		resolved.BaseURL = c.OllamaURL
+	}
+	return resolved
 }
 
 // getEnv gets an environment variable or returns a default value.
@@ -166,8 +281,10 @@ func getEnv(key, defaultValue string) string {
 }
 
 // LoadConfigWithSecrets loads configuration using OmniVault for secrets.
-// This is the recommended way to load configuration in production as it
-// supports multiple secret backends (env, AWS Secrets Manager, etc.).
+// It supports multiple secret backends (env, AWS Secrets Manager, etc.).
+//
+// Deprecated: Use Load, which also reads config files and flags and
+// records where each value came from.
 func LoadConfigWithSecrets(ctx context.Context, secretsCfg SecretsConfig) (*Config, error) {
 	// Create secrets client
 	secrets, err := NewSecretsClient(secretsCfg)
@@ -175,61 +292,59 @@ func LoadConfigWithSecrets(ctx context.Context, secretsCfg SecretsConfig) (*Conf
 		return nil, err
 	}
 
-	provider := getEnv("LLM_PROVIDER", "gemini")
+	r := NewResolver(nil)
+	provider := r.String("LLM_PROVIDER", "gemini")
 
 	cfg := &Config{
 		// LLM settings
 		LLMProvider: provider,
-		LLMModel:    getEnv("LLM_MODEL", GetDefaultModel(provider)),
-		LLMBaseURL:  getEnv("LLM_BASE_URL", ""),
+		LLMModel:    r.String("LLM_MODEL", GetDefaultModel(provider)),
+		LLMBaseURL:  r.String("LLM_BASE_URL", ""),
+
+		LLMRequestsPerMinute: r.Int("LLM_REQUESTS_PER_MINUTE", 0),
+		LLMTokensPerMinute:   r.Int("LLM_TOKENS_PER_MINUTE", 0),
+
+		BedrockRegion: r.String("AWS_REGION", r.String("AWS_DEFAULT_REGION", "us-east-1")),
 
 		// Search settings
-		SearchProvider: getEnv("SEARCH_PROVIDER", "serper"),
+		SearchProvider: r.String("SEARCH_PROVIDER", "serper"),
 
 		// Agent URLs
 		AgentURLs: make(map[string]string),
 
 		// A2A Protocol
-		A2AEnabled:   getEnv("A2A_ENABLED", "true") == "true",
-		A2AAuthType:  getEnv("A2A_AUTH_TYPE", "apikey"),
-		A2AAuthToken: getEnv("A2A_AUTH_TOKEN", ""),
+		A2AEnabled:   r.Bool("A2A_ENABLED", true),
+		A2AAuthType:  r.String("A2A_AUTH_TYPE", "apikey"),
+		A2AAuthToken: r.String("A2A_AUTH_TOKEN", ""),
 
 		// Observability
-		ObservabilityEnabled:  getEnv("OBSERVABILITY_ENABLED", "false") == "true",
-		ObservabilityProvider: getEnv("OBSERVABILITY_PROVIDER", "opik"),
-		ObservabilityEndpoint: getEnv("OBSERVABILITY_ENDPOINT", ""),
-		ObservabilityProject:  getEnv("OBSERVABILITY_PROJECT", "agentkit"),
+		ObservabilityEnabled:  r.Bool("OBSERVABILITY_ENABLED", false),
+		ObservabilityProvider: r.String("OBSERVABILITY_PROVIDER", "opik"),
+		ObservabilityEndpoint: r.String("OBSERVABILITY_ENDPOINT", ""),
+		ObservabilityProject:  r.String("OBSERVABILITY_PROJECT", "agentkit"),
 
 		// Security
-		SecurityEnabled:      getEnv("SECURITY_ENABLED", "false") == "true",
+		SecurityEnabled:      r.Bool("SECURITY_ENABLED", false),
 		SecurityMinScore:     50,
-		SecurityRequireEncry: getEnv("SECURITY_REQUIRE_ENCRYPTION", "false") == "true",
+		SecurityRequireEncry: r.Bool("SECURITY_REQUIRE_ENCRYPTION", false),
+
+		// Crawling
+		CrawlIgnoreRobots:   r.Bool("CRAWL_IGNORE_ROBOTS", false),
+		CrawlRateLimit:      r.Int("CRAWL_RATE_LIMIT", 0),
+		CrawlMaxConcurrency: r.Int("CRAWL_MAX_CONCURRENCY", 4),
 
 		// Secrets client
 		secrets: secrets,
 	}
+	if err := r.Err(); err != nil {
+		secrets.Close()
+		return nil, err
+	}
 
 	// Load API keys from secrets provider
 	cfg.loadSecretsFromProvider(ctx)
 
-	// Set LLMAPIKey based on provider if not explicitly set
-	if cfg.LLMAPIKey == "" {
-		switch provider {
-		case "gemini":
-			cfg.LLMAPIKey = cfg.GeminiAPIKey
-		case "claude":
-			cfg.LLMAPIKey = cfg.ClaudeAPIKey
-		case "openai":
-			cfg.LLMAPIKey = cfg.OpenAIAPIKey
-		case "xai":
-			cfg.LLMAPIKey = cfg.XAIAPIKey
-		}
-	}
-
-	// Set LLMBaseURL for Ollama if not explicitly set
-	if cfg.LLMBaseURL == "" && provider == "ollama" {
-		cfg.LLMBaseURL = cfg.OllamaURL
-	}
+	cfg.applyLLMDefaults()
 
 	return cfg, nil
 }
@@ -260,6 +375,21 @@ func (c *Config) loadSecretsFromProvider(ctx context.Context) {
 	if key, err := c.secrets.Get(ctx, "XAI_API_KEY"); err == nil && key != "" {
 		c.XAIAPIKey = key
 	}
+	if key, err := c.secrets.Get(ctx, "AWS_BEARER_TOKEN_BEDROCK"); err == nil && key != "" {
+		c.BedrockAPIKey = key
+	}
+	if key, err := c.secrets.Get(ctx, "AZURE_OPENAI_API_KEY"); err == nil && key != "" {
+		c.AzureOpenAIAPIKey = key
+	}
+	if endpoint, err := c.secrets.Get(ctx, "AZURE_OPENAI_ENDPOINT"); err == nil && endpoint != "" {
+		c.AzureOpenAIEndpoint = endpoint
+	}
+	if key, err := c.secrets.Get(ctx, "GROQ_API_KEY"); err == nil && key != "" {
+		c.GroqAPIKey = key
+	}
+	if key, err := c.secrets.Get(ctx, "MISTRAL_API_KEY"); err == nil && key != "" {
+		c.MistralAPIKey = key
+	}
 
 	// Load search API keys
 	if key, err := c.secrets.Get(ctx, "SERPER_API_KEY"); err == nil && key != "" {
@@ -307,6 +437,12 @@ func (c *Config) SecretsProvider() SecretsProvider {
 	return SecretsProviderEnv
 }
 
+// Secrets returns the secrets client, or nil if the config was not loaded
+// with one.
+func (c *Config) Secrets() *SecretsClient {
+	return c.secrets
+}
+
 // Close releases resources held by the config (e.g., secrets client).
 func (c *Config) Close() error {
 	if c.secrets != nil {
@@ -334,118 +470,40 @@ type LoadOptions struct {
 
 	// SecretsRegion is the AWS region for aws-sm/aws-ssm providers.
 	SecretsRegion string
-}
-
-// Load loads configuration from config file, environment variables, and secrets.
-// This is the recommended way to load configuration as it:
-//   - Reads settings from config.json (LLM_PROVIDER, SEARCH_PROVIDER, etc.)
-//   - Allows environment variable overrides
-//   - Loads secrets from OmniVault (API keys)
-//
-// Example:
-//
-//	cfg, err := config.Load(ctx, config.LoadOptions{
-//	    ConfigFile: "config.json",
-//	})
-func Load(ctx context.Context, opts LoadOptions) (*Config, error) {
-	// Detect project name if not provided
-	projectName := opts.ProjectName
-	if projectName == "" {
-		projectName = GetProjectName()
-	}
-
-	// Load config file
-	fileCfg, err := LoadConfigFile(opts.ConfigFile, projectName)
-	if err != nil {
-		return nil, err
-	}
-
-	// Apply defaults and merge environment overrides
-	fileCfg.Defaults().MergeEnv()
-
-	// Determine secrets configuration
-	secretsCfg := SecretsConfig{
-		Provider:      SecretsProvider(fileCfg.Secrets.Provider),
-		Prefix:        fileCfg.Secrets.Prefix,
-		Region:        fileCfg.Secrets.Region,
-		FallbackToEnv: true,
-	}
-
-	// Override with explicit options
-	if opts.SecretsProvider != "" {
-		secretsCfg.Provider = opts.SecretsProvider
-	}
-	if opts.SecretsPrefix != "" {
-		secretsCfg.Prefix = opts.SecretsPrefix
-	}
-	if opts.SecretsRegion != "" {
-		secretsCfg.Region = opts.SecretsRegion
-	}
-
-	// Create secrets client
-	secrets, err := NewSecretsClient(secretsCfg)
-	if err != nil {
-		return nil, err
-	}
-
-	// Build Config from file config
-	cfg := &Config{
-		// LLM settings from file
-		LLMProvider: fileCfg.LLM.Provider,
-		LLMModel:    fileCfg.LLM.Model,
-		LLMBaseURL:  fileCfg.LLM.BaseURL,
-
-		// Search settings from file
-		SearchProvider: fileCfg.Search.Provider,
-
-		// Agent URLs from file
-		AgentURLs: make(map[string]string),
-
-		// A2A Protocol from file
-		A2AEnabled:  fileCfg.A2A.Enabled,
-		A2AAuthType: fileCfg.A2A.AuthType,
 
-		// Observability from file
-		ObservabilityEnabled:  fileCfg.Observability.Enabled,
-		ObservabilityProvider: fileCfg.Observability.Provider,
-		ObservabilityEndpoint: fileCfg.Observability.Endpoint,
-		ObservabilityProject:  fileCfg.Observability.Project,
+	// SecretsProject is the GCP project ID for the gcp-sm provider.
+	SecretsProject string
 
-		// Security from file
-		SecurityEnabled:      fileCfg.Security.Enabled,
-		SecurityMinScore:     fileCfg.Security.MinScore,
-		SecurityRequireEncry: fileCfg.Security.RequireEncryption,
-
-		// Secrets client
-		secrets: secrets,
-	}
-
-	// Copy agent URLs from file
-	for name, agent := range fileCfg.Agents {
-		cfg.AgentURLs[name] = agent.URL
-	}
-
-	// Load API keys from secrets provider
-	cfg.loadSecretsFromProvider(ctx)
+	// Strict rejects config files with unknown fields or invalid values.
+	// See LoadConfigFileStrict.
+	Strict bool
+}
 
-	// Set LLMAPIKey based on provider if not explicitly set
-	if cfg.LLMAPIKey == "" {
-		switch cfg.LLMProvider {
+// applyLLMDefaults fills in LLMAPIKey from the provider-specific key and,
+// for Ollama, LLMBaseURL from OllamaURL, unless they are set explicitly.
+func (c *Config) applyLLMDefaults() {
+	if c.LLMAPIKey == "" {
+		switch c.LLMProvider {
 		case "gemini":
-			cfg.LLMAPIKey = cfg.GeminiAPIKey
+			c.LLMAPIKey = c.GeminiAPIKey
 		case "claude":
-			cfg.LLMAPIKey = cfg.ClaudeAPIKey
+			c.LLMAPIKey = c.ClaudeAPIKey
 		case "openai":
-			cfg.LLMAPIKey = cfg.OpenAIAPIKey
+			c.LLMAPIKey = c.OpenAIAPIKey
 		case "xai":
-			cfg.LLMAPIKey = cfg.XAIAPIKey
+			c.LLMAPIKey = c.XAIAPIKey
+		case "bedrock":
+			c.LLMAPIKey = c.BedrockAPIKey
+		case "azure":
+			c.LLMAPIKey = c.AzureOpenAIAPIKey
+		case "groq":
+			c.LLMAPIKey = c.GroqAPIKey
+		case "mistral":
+			c.LLMAPIKey = c.MistralAPIKey
 		}
 	}
 
-	// Set LLMBaseURL for Ollama if not explicitly set
-	if cfg.LLMBaseURL == "" && cfg.LLMProvider == "ollama" {
-		cfg.LLMBaseURL = cfg.OllamaURL
+	if c.LLMBaseURL == "" && c.LLMProvider == "ollama" {
+		c.LLMBaseURL = c.OllamaURL
 	}
-
-	return cfg, nil
 }
@@ -0,0 +1,226 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// KeyMigration declares a renamed or removed config file key. Keys are
+// dotted paths such as "llm.baseUrl"; a "*" segment matches any key, as in
+// "agents.*.url".
+type KeyMigration struct {
+	// Old is the deprecated key.
+	Old string
+
+	// New is the key that replaces Old. Empty if Old was removed.
+	New string
+
+	// Note explains the change, e.g. where a removed setting went.
+	Note string
+}
+
+// keyMigrations are the deprecated config file keys. Deprecated keys are
+// mapped to their replacements when a file is loaded, with a warning.
+var keyMigrations = []KeyMigration{
+	{Old: "llm.base_url", New: "llm.baseUrl"},
+	{Old: "llm.apiKey", Note: "API keys do not belong in config files; set LLM_API_KEY or use a secrets provider"},
+	{Old: "llm.api_key", Note: "API keys do not belong in config files; set LLM_API_KEY or use a secrets provider"},
+	{Old: "observability.apiKey", Note: "set OBSERVABILITY_API_KEY or use a secrets provider"},
+	{Old: "a2a.auth_type", New: "a2a.authType"},
+	{Old: "security.min_score", New: "security.minScore"},
+	{Old: "security.require_encryption", New: "security.requireEncryption"},
+	{Old: "agents.*.endpoint", New: "agents.*.url"},
+}
+
+// DeprecatedKeys returns the deprecated config file keys.
+func DeprecatedKeys() []KeyMigration {
+	return append([]KeyMigration(nil), keyMigrations...)
+}
+
+// KeyChange is a deprecated key found in a config file.
+type KeyChange struct {
+	KeyMigration
+
+	// Key is the key as found, with "*" segments filled in, e.g.
+	// "profiles.prod.agents.research.endpoint".
+	Key string
+
+	// Replacement is the key the value was moved to. Empty if the key was
+	// removed or the replacement was already set.
+	Replacement string
+}
+
+// String describes the change.
+func (c KeyChange) String() string {
+	var msg string
+	switch {
+	case c.New == "":
+		msg = fmt.Sprintf("%s was removed and is ignored", c.Key)
+	case c.Replacement == "":
+		msg = fmt.Sprintf("%s is deprecated and is ignored because its replacement %s is set", c.Key, c.New)
+	default:
+		msg = fmt.Sprintf("%s is deprecated; use %s", c.Key, c.Replacement)
+	}
+	if c.Note != "" {
+		msg += " (" + c.Note + ")"
+	}
+	return msg
+}
+
+// MigrationReport lists the deprecated keys found in a config file.
+type MigrationReport struct {
+	Path    string
+	Changes []KeyChange
+}
+
+// String formats the report, one change per line.
+func (r *MigrationReport) String() string {
+	if len(r.Changes) == 0 {
+		return fmt.Sprintf("%s: no deprecated keys", r.Path)
+	}
+	lines := make([]string, 0, len(r.Changes)+1)
+	lines = append(lines, fmt.Sprintf("%s: %d deprecated keys", r.Path, len(r.Changes)))
+	for _, c := range r.Changes {
+		lines = append(lines, "  "+c.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// CheckDeprecatedKeys reports the deprecated keys in the config file at
+// path and how they are mapped when the file is loaded.
+func CheckDeprecatedKeys(path string) (*MigrationReport, error) {
+	data, err := readConfigData(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]interface{}
+	if err := decodeConfigData(data, path, &raw); err != nil {
+		return nil, err
+	}
+	return &MigrationReport{Path: path, Changes: migrateKeys(raw)}, nil
+}
+
+// migrateConfigKeys maps deprecated keys in decoded config data to their
+// replacements, logging a warning for each.
+func migrateConfigKeys(raw map[string]interface{}, path string) {
+	for _, c := range migrateKeys(raw) {
+		slog.Warn("deprecated config key",
+			"file", path,
+			"key", c.Key,
+			"replacement", c.Replacement,
+			"detail", c.String())
+	}
+}
+
+// migrateKeys maps deprecated keys in raw, and in each of its profiles,
+// to their replacements. Removed keys are deleted. If the replacement is
+// already set, it wins and the deprecated key is deleted.
+func migrateKeys(raw map[string]interface{}) []KeyChange {
+	changes := migrateKeysIn(raw, "")
+	if profiles, ok := raw["profiles"].(map[string]interface{}); ok {
+		for _, name := range sortedKeys(profiles) {
+			if profile, ok := profiles[name].(map[string]interface{}); ok {
+				changes = append(changes, migrateKeysIn(profile, "profiles."+name+".")...)
+			}
+		}
+	}
+	return changes
+}
+
+// migrateKeysIn applies the key migrations to one config object. prefix
+// is the object's path, for reporting.
+func migrateKeysIn(obj map[string]interface{}, prefix string) []KeyChange {
+	var changes []KeyChange
+	for _, m := range keyMigrations {
+		for _, match := range matchKey(obj, strings.Split(m.Old, "."), nil) {
+			change := KeyChange{KeyMigration: m, Key: prefix + strings.Join(match.path, ".")}
+			delete(match.parent, match.key)
+			if m.New != "" {
+				newPath := fillWildcards(strings.Split(m.New, "."), match.wildcards)
+				if setKey(obj, newPath, match.value) {
+					change.Replacement = prefix + strings.Join(newPath, ".")
+				}
+			}
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// keyMatch is a key found by matchKey.
+type keyMatch struct {
+	parent    map[string]interface{}
+	key       string
+	value     interface{}
+	path      []string // full path, with wildcards filled in
+	wildcards []string // keys matched by "*" segments
+}
+
+// matchKey finds the keys in obj matching pattern.
+func matchKey(obj map[string]interface{}, pattern, path []string) []keyMatch {
+	seg := pattern[0]
+	keys := []string{seg}
+	if seg == "*" {
+		keys = sortedKeys(obj)
+	}
+
+	var matches []keyMatch
+	for _, key := range keys {
+		value, ok := obj[key]
+		if !ok {
+			continue
+		}
+		keyPath := append(append([]string(nil), path...), key)
+		if len(pattern) == 1 {
+			matches = append(matches, keyMatch{parent: obj, key: key, value: value, path: keyPath})
+			continue
+		}
+		child, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, m := range matchKey(child, pattern[1:], keyPath) {
+			if seg == "*" {
+				m.wildcards = append([]string{key}, m.wildcards...)
+			}
+			matches = append(matches, m)
+		}
+	}
+	return matches
+}
+
+// fillWildcards replaces the "*" segments of pattern with keys, in order.
+func fillWildcards(pattern, keys []string) []string {
+	path := make([]string, len(pattern))
+	for i, seg := range pattern {
+		if seg == "*" && len(keys) > 0 {
+			seg, keys = keys[0], keys[1:]
+		}
+		path[i] = seg
+	}
+	return path
+}
+
+// setKey sets path in obj to value, creating objects as needed. It does
+// not overwrite a key that is already set, and reports whether it set the
+// value.
+func setKey(obj map[string]interface{}, path []string, value interface{}) bool {
+	for _, seg := range path[:len(path)-1] {
+		child, ok := obj[seg].(map[string]interface{})
+		if !ok {
+			if _, exists := obj[seg]; exists {
+				return false
+			}
+			child = make(map[string]interface{})
+			obj[seg] = child
+		}
+		obj = child
+	}
+	last := path[len(path)-1]
+	if _, exists := obj[last]; exists {
+		return false
+	}
+	obj[last] = value
+	return true
+}
@@ -0,0 +1,214 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omnivault/vault"
+)
+
+// diagnoseProbe is the secret name read to probe a provider. It does not
+// need to exist: a not-found answer proves the provider is reachable and
+// allows reads under the prefix.
+const diagnoseProbe = "agentkit-diagnose-probe"
+
+// SecretSource is where a secret was resolved from.
+type SecretSource string
+
+const (
+	// SecretFromProvider means the secret was read from the provider.
+	SecretFromProvider SecretSource = "provider"
+
+	// SecretFromEnv means the secret was only found in the environment
+	// fallback. Such a secret works locally but not in a deployment
+	// without the variable.
+	SecretFromEnv SecretSource = "env"
+
+	// SecretMissing means the secret was not found.
+	SecretMissing SecretSource = "missing"
+)
+
+// SecretDiagnosis is the result of resolving one named secret.
+type SecretDiagnosis struct {
+	Name   string
+	Source SecretSource
+
+	// Err is the provider error, if the provider lookup failed.
+	Err error
+}
+
+// SecretsDiagnosis is the result of SecretsClient.Diagnose.
+type SecretsDiagnosis struct {
+	Provider SecretsProvider
+	Prefix   string
+
+	// Identity describes the AWS credentials used, e.g. "web-identity
+	// credentials, assuming arn:aws:iam::123456789012:role/reader". Empty
+	// for other providers.
+	Identity string
+
+	// Reachable reports whether the provider answered the probe.
+	Reachable bool
+
+	// Latency is the duration of the probe.
+	Latency time.Duration
+
+	// CanRead reports whether the provider allows reads under Prefix.
+	CanRead bool
+
+	// CanList reports whether the provider allows listing Prefix.
+	CanList bool
+
+	// Listed is the number of secrets under Prefix, if CanList.
+	Listed int
+
+	// CanWrite reports whether the provider supports writes.
+	CanWrite bool
+
+	// Secrets are the named secrets passed to Diagnose.
+	Secrets []SecretDiagnosis
+
+	// Problems are the failures that make the provider unusable or leave
+	// a named secret missing.
+	Problems []string
+
+	// Warnings are findings that do not fail the diagnosis, such as
+	// secrets only found in the environment fallback.
+	Warnings []string
+}
+
+// Err returns an error listing the problems, or nil if there are none.
+func (d *SecretsDiagnosis) Err() error {
+	if len(d.Problems) == 0 {
+		return nil
+	}
+	errs := make([]error, len(d.Problems))
+	for i, p := range d.Problems {
+		errs[i] = errors.New(p)
+	}
+	return fmt.Errorf("secrets provider %s: %w", d.Provider, errors.Join(errs...))
+}
+
+// String formats the diagnosis, one finding per line.
+func (d *SecretsDiagnosis) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "secrets provider %s", d.Provider)
+	if d.Prefix != "" {
+		fmt.Fprintf(&b, " (prefix %q)", d.Prefix)
+	}
+	if d.Reachable {
+		fmt.Fprintf(&b, ": reachable in %s", d.Latency.Round(time.Millisecond))
+	} else {
+		b.WriteString(": unreachable")
+	}
+	if d.Identity != "" {
+		b.WriteString("\n  identity: " + d.Identity)
+	}
+	fmt.Fprintf(&b, "\n  read: %t, list: %t, write: %t", d.CanRead, d.CanList, d.CanWrite)
+	if d.CanList {
+		fmt.Fprintf(&b, " (%d secrets)", d.Listed)
+	}
+	for _, s := range d.Secrets {
+		fmt.Fprintf(&b, "\n  %s: %s", s.Name, s.Source)
+	}
+	for _, p := range d.Problems {
+		b.WriteString("\n  problem: " + p)
+	}
+	for _, w := range d.Warnings {
+		b.WriteString("\n  warning: " + w)
+	}
+	return b.String()
+}
+
+// Diagnose checks that the provider is reachable and allows reads under
+// the configured prefix, and measures its latency. If names are given, it
+// also reports where each named secret resolves from, flagging secrets
+// that are only found in the environment fallback. Use it to debug
+// secrets that resolve locally but not in a deployment, where the usual
+// causes are a missing IAM permission on the prefix or a variable that
+// only exists on the developer's machine.
+func (sc *SecretsClient) Diagnose(ctx context.Context, names ...string) *SecretsDiagnosis {
+	d := &SecretsDiagnosis{
+		Provider: sc.config.Provider,
+		Prefix:   sc.config.Prefix,
+		CanWrite: sc.CanWrite(),
+	}
+	if isAWSProvider(d.Provider) {
+		d.Identity = sc.config.Identity.describe()
+		if d.Identity == "" {
+			d.Warnings = append(d.Warnings, "no AWS workload identity or access keys found in the environment")
+		}
+	}
+
+	start := time.Now()
+	_, err := sc.client.Get(ctx, sc.path(diagnoseProbe))
+	d.Latency = time.Since(start)
+	switch {
+	case err == nil || errors.Is(err, vault.ErrSecretNotFound):
+		d.Reachable = true
+		d.CanRead = true
+	case errors.Is(err, vault.ErrAccessDenied) || errors.Is(err, vault.ErrAuthenticationFailed):
+		d.Reachable = true
+		d.Problems = append(d.Problems, fmt.Sprintf("reading %q: %v", d.Prefix, err))
+	default:
+		d.Problems = append(d.Problems, fmt.Sprintf("unreachable: %v", err))
+	}
+
+	if d.Reachable && sc.client.Capabilities().List {
+		paths, err := sc.client.List(ctx, d.Prefix)
+		if err != nil {
+			// Policies often grant reads without listing; that is enough.
+			d.Warnings = append(d.Warnings, fmt.Sprintf("listing %q: %v", d.Prefix, err))
+		} else {
+			d.CanList = true
+			d.Listed = len(paths)
+		}
+	}
+
+	for _, name := range names {
+		s := SecretDiagnosis{Name: name, Source: SecretMissing}
+		value, err := sc.getFromProvider(ctx, name)
+		switch {
+		case err == nil && value != "":
+			s.Source = SecretFromProvider
+		case sc.fallbackToEnv && sc.config.Provider != SecretsProviderEnv && os.Getenv(name) != "":
+			s.Source = SecretFromEnv
+			s.Err = err
+			d.Warnings = append(d.Warnings, fmt.Sprintf("%s is only set in the environment, not in %s", name, d.Provider))
+		default:
+			s.Err = err
+			if err != nil && !errors.Is(err, vault.ErrSecretNotFound) {
+				d.Problems = append(d.Problems, fmt.Sprintf("%s: %v", name, err))
+			} else {
+				d.Problems = append(d.Problems, fmt.Sprintf("%s not found", name))
+			}
+		}
+		d.Secrets = append(d.Secrets, s)
+	}
+	return d
+}
+
+// Ready reports whether the provider is reachable and allows reads. It
+// can be used as a server readiness check:
+//
+//	httpserver.NewBuilder("research", 8001).
+//	    WithReadyCheck("secrets", secrets.Ready)
+func (sc *SecretsClient) Ready(ctx context.Context) error {
+	return sc.Diagnose(ctx).Err()
+}
+
+// ReadyCheck returns a readiness check that also fails unless each named
+// secret resolves, from the provider or the environment fallback, so a
+// server holds traffic until the credentials it needs are usable:
+//
+//	httpserver.NewBuilder("research", 8001).
+//	    WithReadyCheck("secrets", secrets.ReadyCheck("OPENAI_API_KEY"))
+func (sc *SecretsClient) ReadyCheck(names ...string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return sc.Diagnose(ctx, names...).Err()
+	}
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omnivault/vault"
+)
+
+// dopplerAPIURL is the Doppler API base URL.
+const dopplerAPIURL = "https://api.doppler.com/v3"
+
+// DopplerConfig configures the Doppler secrets provider. The service token
+// is read from DOPPLER_TOKEN, never from config files.
+type DopplerConfig struct {
+	// Project is the Doppler project. Not needed with a service token,
+	// which is scoped to one project and config.
+	// Default: DOPPLER_PROJECT
+	Project string `json:"project" yaml:"project"`
+
+	// Config is the Doppler config (environment), e.g. "prd".
+	// Default: DOPPLER_CONFIG
+	Config string `json:"config" yaml:"config"`
+}
+
+// DopplerProvider is an OmniVault provider for Doppler. Doppler secret
+// names are upper case, so paths are upper-cased and "/" (as in a
+// "stats-agent/" prefix) is replaced with "_".
+type DopplerProvider struct {
+	config DopplerConfig
+	token  string
+	client *http.Client
+}
+
+// NewDopplerProvider creates a Doppler provider, filling in unset fields
+// from the DOPPLER_* environment variables.
+func NewDopplerProvider(cfg DopplerConfig) (*DopplerProvider, error) {
+	if cfg.Project == "" {
+		cfg.Project = os.Getenv("DOPPLER_PROJECT")
+	}
+	if cfg.Config == "" {
+		cfg.Config = os.Getenv("DOPPLER_CONFIG")
+	}
+	token := os.Getenv("DOPPLER_TOKEN")
+	if token == "" {
+		return nil, errors.New("DOPPLER_TOKEN is not set")
+	}
+	return &DopplerProvider{
+		config: cfg,
+		token:  token,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Get implements vault.Vault.
+func (p *DopplerProvider) Get(ctx context.Context, path string) (*vault.Secret, error) {
+	var resp struct {
+		Value struct {
+			Computed string `json:"computed"`
+		} `json:"value"`
+	}
+	query := p.query()
+	query.Set("name", dopplerSecretName(path))
+	if err := p.do(ctx, "configs/config/secret", query, &resp); err != nil {
+		return nil, vault.NewVaultError("Get", path, p.Name(), err)
+	}
+	return &vault.Secret{
+		Value: resp.Value.Computed,
+		Metadata: vault.Metadata{
+			Provider: p.Name(),
+			Path:     path,
+		},
+	}, nil
+}
+
+// Set implements vault.Vault. The provider is read-only.
+func (p *DopplerProvider) Set(ctx context.Context, path string, secret *vault.Secret) error {
+	return vault.NewVaultError("Set", path, p.Name(), vault.ErrReadOnly)
+}
+
+// Delete implements vault.Vault. The provider is read-only.
+func (p *DopplerProvider) Delete(ctx context.Context, path string) error {
+	return vault.NewVaultError("Delete", path, p.Name(), vault.ErrReadOnly)
+}
+
+// Exists implements vault.Vault.
+func (p *DopplerProvider) Exists(ctx context.Context, path string) (bool, error) {
+	_, err := p.Get(ctx, path)
+	if errors.Is(err, vault.ErrSecretNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// List implements vault.Vault. It returns the secret names that start
+// with prefix.
+func (p *DopplerProvider) List(ctx context.Context, prefix string) ([]string, error) {
+	var resp struct {
+		Names []string `json:"names"`
+	}
+	if err := p.do(ctx, "configs/config/secrets/names", p.query(), &resp); err != nil {
+		return nil, vault.NewVaultError("List", prefix, p.Name(), err)
+	}
+	namePrefix := dopplerSecretName(prefix)
+	names := []string{}
+	for _, name := range resp.Names {
+		if strings.HasPrefix(name, namePrefix) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// Name implements vault.Vault.
+func (p *DopplerProvider) Name() string {
+	return string(SecretsProviderDoppler)
+}
+
+// Capabilities implements vault.Vault.
+func (p *DopplerProvider) Capabilities() vault.Capabilities {
+	return vault.Capabilities{
+		Read: true,
+		List: true,
+	}
+}
+
+// Close implements vault.Vault.
+func (p *DopplerProvider) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
+
+// query returns the project and config query parameters.
+func (p *DopplerProvider) query() url.Values {
+	query := url.Values{}
+	if p.config.Project != "" {
+		query.Set("project", p.config.Project)
+	}
+	if p.config.Config != "" {
+		query.Set("config", p.config.Config)
+	}
+	return query
+}
+
+// dopplerSecretName converts a secret path to a Doppler secret name.
+func dopplerSecretName(path string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.Trim(path, "/"), "/", "_"))
+}
+
+// do sends an authenticated GET request to the Doppler API and decodes the
+// JSON response into out.
+func (p *DopplerProvider) do(ctx context.Context, endpoint string, query url.Values, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, dopplerAPIURL+"/"+endpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", vault.ErrConnectionFailed, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return vault.ErrSecretNotFound
+	case resp.StatusCode == http.StatusUnauthorized:
+		return vault.ErrAuthenticationFailed
+	case resp.StatusCode == http.StatusForbidden:
+		return vault.ErrAccessDenied
+	case resp.StatusCode >= 300:
+		var e struct {
+			Messages []string `json:"messages"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&e)
+		if len(e.Messages) > 0 {
+			return fmt.Errorf("doppler returned %s: %s", resp.Status, strings.Join(e.Messages, "; "))
+		}
+		return fmt.Errorf("doppler returned %s", resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding doppler response: %w", err)
+	}
+	return nil
+}
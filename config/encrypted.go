@@ -0,0 +1,164 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Config files may be stored encrypted, so settings such as endpoints and
+// project names can be committed to git. Encrypted files are decrypted
+// transparently at load time:
+//
+//   - SOPS files (a JSON or YAML file with a top-level "sops" key) are
+//     decrypted with the sops CLI, which reads its keys from the usual
+//     places: SOPS_AGE_KEY or SOPS_AGE_KEY_FILE for age, and AWS, GCP or
+//     Azure credentials for KMS.
+//   - age files (config.yaml.age) are decrypted with the age CLI, using the
+//     identity in SOPS_AGE_KEY, the file named by SOPS_AGE_KEY_FILE, or
+//     ~/.config/sops/age/keys.txt.
+
+// ageHeaders are the first bytes of binary and armored age files.
+var ageHeaders = [][]byte{
+	[]byte("age-encryption.org/v1\n"),
+	[]byte("-----BEGIN AGE ENCRYPTED FILE-----"),
+}
+
+// ageExt is the file extension of age-encrypted config files. The format
+// of the decrypted file comes from the extension before it.
+const ageExt = ".age"
+
+// decryptConfigData decrypts SOPS and age encrypted config data. Data that
+// is not encrypted is returned unchanged.
+func decryptConfigData(ctx context.Context, data []byte, path string) ([]byte, error) {
+	switch {
+	case isAgeEncrypted(data):
+		plain, err := decryptAge(ctx, data)
+		if err != nil {
+			return nil, fmt.Errorf("decrypting age config %s: %w", path, err)
+		}
+		// A SOPS file may itself be age-encrypted.
+		return decryptConfigData(ctx, plain, strings.TrimSuffix(path, ageExt))
+	case isSOPSEncrypted(data, path):
+		plain, err := decryptSOPS(ctx, data, configFormat(path, data))
+		if err != nil {
+			return nil, fmt.Errorf("decrypting SOPS config %s: %w", path, err)
+		}
+		return plain, nil
+	default:
+		return data, nil
+	}
+}
+
+// isAgeEncrypted reports whether data is an age file.
+func isAgeEncrypted(data []byte) bool {
+	for _, header := range ageHeaders {
+		if bytes.HasPrefix(data, header) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSOPSEncrypted reports whether data is a SOPS file: a JSON or YAML
+// object with SOPS metadata under a top-level "sops" key.
+func isSOPSEncrypted(data []byte, path string) bool {
+	if !bytes.Contains(data, []byte("sops")) {
+		return false
+	}
+	var doc struct {
+		SOPS *struct {
+			MAC string `json:"mac" yaml:"mac"`
+		} `json:"sops" yaml:"sops"`
+	}
+	if decodeConfigData(data, path, &doc) != nil {
+		return false
+	}
+	return doc.SOPS != nil && doc.SOPS.MAC != ""
+}
+
+// configFormat returns "json" or "yaml" for config data at path.
+func configFormat(path string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(strings.TrimSuffix(path, ageExt))) {
+	case ".json":
+		return "json"
+	case ".yaml", ".yml":
+		return "yaml"
+	}
+	if json.Valid(data) {
+		return "json"
+	}
+	return "yaml"
+}
+
+// decryptSOPS decrypts a SOPS file with "sops --decrypt".
+func decryptSOPS(ctx context.Context, data []byte, format string) ([]byte, error) {
+	// sops needs a file; the encrypted file is safe to write.
+	f, err := os.CreateTemp("", "agentkit-config-*."+format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write temp file: %w", err)
+	}
+
+	return runDecryptCLI(ctx, nil, "sops", "--decrypt", "--input-type", format, "--output-type", format, f.Name())
+}
+
+// decryptAge decrypts an age file with "age --decrypt".
+func decryptAge(ctx context.Context, data []byte) ([]byte, error) {
+	identityFile := os.Getenv("SOPS_AGE_KEY_FILE")
+	if key := os.Getenv("SOPS_AGE_KEY"); key != "" {
+		f, err := os.CreateTemp("", "agentkit-age-key-*")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temp file: %w", err)
+		}
+		defer os.Remove(f.Name())
+		_, err = f.WriteString(key + "\n")
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to write age identity: %w", err)
+		}
+		identityFile = f.Name()
+	}
+	if identityFile == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return nil, fmt.Errorf("no age identity: set SOPS_AGE_KEY or SOPS_AGE_KEY_FILE")
+		}
+		identityFile = filepath.Join(dir, "sops", "age", "keys.txt")
+	}
+
+	return runDecryptCLI(ctx, data, "age", "--decrypt", "--identity", identityFile)
+}
+
+// runDecryptCLI runs a decryption command with stdin as input and returns
+// its output.
+func runDecryptCLI(ctx context.Context, stdin []byte, name string, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %s", name, msg)
+		}
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return out, nil
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"regexp"
+)
+
+// String values in config files may reference environment variables:
+//
+//	${VAR}            value of VAR, or "" if unset
+//	${VAR:-default}   value of VAR, or default if VAR is unset or empty
+//
+// Write $${...} for a literal "${...}". A bare $VAR is left as is.
+
+// envRefPattern matches ${VAR} and ${VAR:-default} references and $${ escapes.
+var envRefPattern = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// ExpandEnv expands ${VAR} and ${VAR:-default} references in s.
+func ExpandEnv(s string) string {
+	return envRefPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		if ref == "$${" {
+			return "${"
+		}
+		m := envRefPattern.FindStringSubmatch(ref)
+		if value := os.Getenv(m[1]); value != "" {
+			return value
+		}
+		return m[2]
+	})
+}
+
+// ExpandEnvFields expands environment variable references in every string
+// field of the struct v points to, including strings in nested structs,
+// pointers, slices and map values.
+func ExpandEnvFields(v any) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return
+	}
+	expandEnvValue(rv.Elem())
+}
+
+// expandEnvValue expands the strings in an addressable value.
+func expandEnvValue(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(ExpandEnv(v.String()))
+		}
+	case reflect.Pointer:
+		if !v.IsNil() {
+			expandEnvValue(v.Elem())
+		}
+	case reflect.Interface:
+		if !v.IsNil() && v.CanSet() {
+			// Interface values are not addressable; expand a copy.
+			elem := reflect.New(v.Elem().Type()).Elem()
+			elem.Set(v.Elem())
+			expandEnvValue(elem)
+			v.Set(elem)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				expandEnvValue(v.Field(i))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			expandEnvValue(v.Index(i))
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			// Map values are not addressable; expand a copy.
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(key))
+			expandEnvValue(elem)
+			v.SetMapIndex(key, elem)
+		}
+	}
+}
@@ -4,6 +4,7 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
@@ -24,7 +25,7 @@ type ConfigFile struct {
 	Observability ObservabilityConfig `json:"observability" yaml:"observability"`
 
 	// Agent URLs for multi-agent systems
-	Agents map[string]AgentConfig `json:"agents" yaml:"agents"`
+	Agents map[string]AgentConfig `json:"agents" yaml:"agents" validate:"dive"`
 
 	// A2A Protocol configuration
 	A2A A2AConfig `json:"a2a" yaml:"a2a"`
@@ -32,66 +33,108 @@ type ConfigFile struct {
 	// Security configuration
 	Security SecurityConfig `json:"security" yaml:"security"`
 
+	// Crawl configuration for agent fetches
+	Crawl CrawlConfig `json:"crawl" yaml:"crawl"`
+
 	// Secrets configuration (provider settings, not actual secrets)
 	Secrets SecretsFileConfig `json:"secrets" yaml:"secrets"`
 
-	// Environment overrides (optional)
+	// Environment selects the profile merged over the base values.
+	// Overridden by the AGENT_ENV environment variable.
 	Environment string `json:"environment" yaml:"environment"`
+
+	// Profiles are per-environment overrides keyed by environment name.
+	// The selected profile is deep-merged over the base values.
+	Profiles map[string]ConfigFile `json:"profiles,omitempty" yaml:"profiles,omitempty"`
 }
 
 // LLMConfig holds LLM provider configuration.
 type LLMConfig struct {
-	Provider string `json:"provider" yaml:"provider"` // gemini, claude, openai, ollama, xai
-	Model    string `json:"model" yaml:"model"`       // Model name override
-	BaseURL  string `json:"baseUrl" yaml:"baseUrl"`   // Custom endpoint (for ollama)
+	Provider string `json:"provider" yaml:"provider" validate:"omitempty,oneof=gemini claude openai ollama xai bedrock azure groq mistral"`
+	Model    string `json:"model" yaml:"model"`                              // Model name override
+	BaseURL  string `json:"baseUrl" yaml:"baseUrl" validate:"omitempty,url"` // Custom endpoint (for ollama)
+
+	RequestsPerMinute int `json:"requestsPerMinute" yaml:"requestsPerMinute" validate:"min=0"` // Per provider API key
+	TokensPerMinute   int `json:"tokensPerMinute" yaml:"tokensPerMinute" validate:"min=0"`     // Per provider API key
 }
 
 // SearchConfig holds search provider configuration.
 type SearchConfig struct {
-	Provider string `json:"provider" yaml:"provider"` // serper, serpapi
+	Provider string `json:"provider" yaml:"provider" validate:"omitempty,oneof=serper serpapi"`
 }
 
 // ObservabilityConfig holds observability settings.
 type ObservabilityConfig struct {
 	Enabled  bool   `json:"enabled" yaml:"enabled"`
-	Provider string `json:"provider" yaml:"provider"` // opik, langfuse, phoenix
-	Endpoint string `json:"endpoint" yaml:"endpoint"` // Custom endpoint
-	Project  string `json:"project" yaml:"project"`   // Project name
+	Provider string `json:"provider" yaml:"provider" validate:"omitempty,oneof=opik langfuse phoenix"`
+	Endpoint string `json:"endpoint" yaml:"endpoint" validate:"omitempty,url"` // Custom endpoint
+	Project  string `json:"project" yaml:"project"`                            // Project name
 }
 
 // AgentConfig holds configuration for a single agent in multi-agent systems.
+// The LLM fields override the llm block for this agent, so a multi-agent
+// system can use, for example, Gemini for research and Claude for
+// synthesis. Unset fields fall back to the llm block.
 type AgentConfig struct {
-	URL         string `json:"url" yaml:"url"`
+	URL         string `json:"url" yaml:"url" validate:"required,url"`
 	Description string `json:"description" yaml:"description"`
+
+	Provider    string   `json:"provider" yaml:"provider" validate:"omitempty,oneof=gemini claude openai ollama xai bedrock azure groq mistral"`
+	Model       string   `json:"model" yaml:"model"`
+	Temperature *float64 `json:"temperature" yaml:"temperature" validate:"omitempty,min=0,max=2"` // Sampling temperature
+	BaseURL     string   `json:"baseUrl" yaml:"baseUrl" validate:"omitempty,url"`                 // Custom endpoint
 }
 
 // A2AConfig holds A2A protocol configuration.
 type A2AConfig struct {
 	Enabled  bool   `json:"enabled" yaml:"enabled"`
-	AuthType string `json:"authType" yaml:"authType"` // jwt, apikey, oauth2
+	AuthType string `json:"authType" yaml:"authType" validate:"omitempty,oneof=jwt apikey oauth2"`
 }
 
 // SecurityConfig holds security settings.
 type SecurityConfig struct {
 	Enabled           bool `json:"enabled" yaml:"enabled"`
-	MinScore          int  `json:"minScore" yaml:"minScore"`
+	MinScore          int  `json:"minScore" yaml:"minScore" validate:"min=0,max=100"`
 	RequireEncryption bool `json:"requireEncryption" yaml:"requireEncryption"`
 }
 
+// CrawlConfig holds the crawling politeness settings of agent fetches.
+type CrawlConfig struct {
+	IgnoreRobots   bool `json:"ignoreRobots" yaml:"ignoreRobots"`                      // Fetch URLs that robots.txt disallows
+	RateLimit      int  `json:"rateLimit" yaml:"rateLimit" validate:"min=0"`           // Requests per minute per host
+	MaxConcurrency int  `json:"maxConcurrency" yaml:"maxConcurrency" validate:"min=0"` // Requests in flight per host
+}
+
 // SecretsFileConfig holds secrets provider configuration (not actual secrets).
 type SecretsFileConfig struct {
-	Provider string `json:"provider" yaml:"provider"` // env, aws-sm, aws-ssm
-	Prefix   string `json:"prefix" yaml:"prefix"`     // Secret path prefix
-	Region   string `json:"region" yaml:"region"`     // AWS region
+	Provider    string            `json:"provider" yaml:"provider" validate:"omitempty,oneof=env aws-sm aws-ssm gcp-sm memory vault doppler op"`
+	Prefix      string            `json:"prefix" yaml:"prefix"`           // Secret path prefix
+	Region      string            `json:"region" yaml:"region"`           // AWS region
+	Project     string            `json:"project" yaml:"project"`         // GCP project ID
+	Vault       VaultConfig       `json:"vault" yaml:"vault"`             // HashiCorp Vault connection
+	Doppler     DopplerConfig     `json:"doppler" yaml:"doppler"`         // Doppler project
+	OnePassword OnePasswordConfig `json:"onePassword" yaml:"onePassword"` // 1Password Connect server
+
+	Identity WorkloadIdentityConfig `json:"identity" yaml:"identity"` // AWS role to assume
 }
 
 // LoadConfigFile loads configuration from a JSON or YAML file.
 // It searches in the following order:
-//  1. Explicit path provided
+//  1. Explicit path or https://, s3:// or ssm:// URI provided
 //  2. config.json in current directory
 //  3. config.yaml in current directory
 //  4. ../config.json (parent directory)
 //  5. ~/.agentplexus/projects/{project}/config.json
+//
+// SOPS and age encrypted files are decrypted; see decryptConfigData.
+//
+// If the file defines profiles, the profile named by AGENT_ENV or the
+// environment field is deep-merged over the base values. String values
+// may reference environment variables; see ExpandEnv.
+//
+// Deprecated keys are mapped to their replacements with a warning; see
+// DeprecatedKeys. Unknown fields are ignored; use LoadConfigFileStrict or
+// ValidateConfigFile to report them.
 func LoadConfigFile(path string, projectName string) (*ConfigFile, error) {
 	var configPath string
 
@@ -107,34 +150,72 @@ func LoadConfigFile(path string, projectName string) (*ConfigFile, error) {
 		}
 	}
 
-	data, err := os.ReadFile(configPath)
+	data, err := readConfigData(configPath)
 	if err != nil {
-		return nil, fmt.Errorf("reading config file: %w", err)
+		return nil, err
 	}
 
+	cfg, _, err := parseConfigFile(data, configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// parseConfigFile decodes config file data and applies the selected
+// profile. It also returns the decoded data as written, before the
+// profile is applied.
+func parseConfigFile(data []byte, path string) (*ConfigFile, map[string]interface{}, error) {
+	var raw map[string]interface{}
+	if err := decodeConfigData(data, path, &raw); err != nil {
+		return nil, nil, err
+	}
+	migrateConfigKeys(raw, path)
+	cfg, err := buildConfigFile(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, raw, nil
+}
+
+// buildConfigFile applies the selected profile to decoded config data and
+// converts it to a ConfigFile with environment references expanded.
+func buildConfigFile(raw map[string]interface{}) (*ConfigFile, error) {
+	merged, err := json.Marshal(applyProfile(raw))
+	if err != nil {
+		return nil, fmt.Errorf("applying config profile: %w", err)
+	}
 	var cfg ConfigFile
+	if err := json.Unmarshal(merged, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing config: %w", err)
+	}
+	ExpandEnvFields(&cfg)
+	return &cfg, nil
+}
 
-	// Determine format based on extension
-	ext := strings.ToLower(filepath.Ext(configPath))
+// decodeConfigData decodes config file data into v, choosing the format
+// from the file extension of path (ignoring a trailing ".age").
+func decodeConfigData(data []byte, path string, v interface{}) error {
+	ext := strings.ToLower(filepath.Ext(strings.TrimSuffix(path, ageExt)))
 	switch ext {
 	case ".json":
-		if err := json.Unmarshal(data, &cfg); err != nil {
-			return nil, fmt.Errorf("parsing JSON config: %w", err)
+		if err := json.Unmarshal(data, v); err != nil {
+			return fmt.Errorf("parsing JSON config: %w", err)
 		}
 	case ".yaml", ".yml":
-		if err := yaml.Unmarshal(data, &cfg); err != nil {
-			return nil, fmt.Errorf("parsing YAML config: %w", err)
+		if err := yaml.Unmarshal(data, v); err != nil {
+			return fmt.Errorf("parsing YAML config: %w", err)
 		}
 	default:
 		// Try JSON first, then YAML
-		if err := json.Unmarshal(data, &cfg); err != nil {
-			if err := yaml.Unmarshal(data, &cfg); err != nil {
-				return nil, fmt.Errorf("parsing config file (unknown format): %w", err)
+		if err := json.Unmarshal(data, v); err != nil {
+			if err := yaml.Unmarshal(data, v); err != nil {
+				return fmt.Errorf("parsing config file (unknown format): %w", err)
 			}
 		}
 	}
-
-	return &cfg, nil
+	return nil
 }
 
 // findConfigFile searches for a config file in standard locations.
@@ -221,6 +302,9 @@ func (c *ConfigFile) Defaults() *ConfigFile {
 	if c.Security.MinScore == 0 {
 		c.Security.MinScore = 50
 	}
+	if c.Crawl.MaxConcurrency == 0 {
+		c.Crawl.MaxConcurrency = 4
+	}
 	if c.Secrets.Provider == "" {
 		c.Secrets.Provider = "env"
 	}
@@ -229,7 +313,11 @@ func (c *ConfigFile) Defaults() *ConfigFile {
 
 // MergeEnv merges environment variable overrides into the config.
 // Environment variables take precedence over file values.
+//
+// Invalid values (such as OBSERVABILITY_ENABLED=yes) are logged and ignored.
 func (c *ConfigFile) MergeEnv() *ConfigFile {
+	r := NewResolver(nil)
+
 	// LLM overrides
 	if v := os.Getenv("LLM_PROVIDER"); v != "" {
 		c.LLM.Provider = v
@@ -240,6 +328,16 @@ func (c *ConfigFile) MergeEnv() *ConfigFile {
 	if v := os.Getenv("LLM_BASE_URL"); v != "" {
 		c.LLM.BaseURL = v
 	}
+	c.LLM.RequestsPerMinute = r.Int("LLM_REQUESTS_PER_MINUTE", c.LLM.RequestsPerMinute)
+	c.LLM.TokensPerMinute = r.Int("LLM_TOKENS_PER_MINUTE", c.LLM.TokensPerMinute)
+
+	// Agent URL overrides, e.g. RESEARCH_URL for agents.research.url
+	for name, agent := range c.Agents {
+		if v := os.Getenv(agentURLEnv(name)); v != "" {
+			agent.URL = v
+			c.Agents[name] = agent
+		}
+	}
 
 	// Search overrides
 	if v := os.Getenv("SEARCH_PROVIDER"); v != "" {
@@ -247,9 +345,7 @@ func (c *ConfigFile) MergeEnv() *ConfigFile {
 	}
 
 	// Observability overrides
-	if v := os.Getenv("OBSERVABILITY_ENABLED"); v == "true" {
-		c.Observability.Enabled = true
-	}
+	c.Observability.Enabled = r.Bool("OBSERVABILITY_ENABLED", c.Observability.Enabled)
 	if v := os.Getenv("OBSERVABILITY_PROVIDER"); v != "" {
 		c.Observability.Provider = v
 	}
@@ -261,31 +357,43 @@ func (c *ConfigFile) MergeEnv() *ConfigFile {
 	}
 
 	// A2A overrides
-	if v := os.Getenv("A2A_ENABLED"); v != "" {
-		c.A2A.Enabled = v == "true"
-	}
+	c.A2A.Enabled = r.Bool("A2A_ENABLED", c.A2A.Enabled)
 	if v := os.Getenv("A2A_AUTH_TYPE"); v != "" {
 		c.A2A.AuthType = v
 	}
 
 	// Security overrides
-	if v := os.Getenv("SECURITY_ENABLED"); v == "true" {
-		c.Security.Enabled = true
-	}
-	if v := os.Getenv("SECURITY_REQUIRE_ENCRYPTION"); v == "true" {
-		c.Security.RequireEncryption = true
-	}
+	c.Security.Enabled = r.Bool("SECURITY_ENABLED", c.Security.Enabled)
+	c.Security.RequireEncryption = r.Bool("SECURITY_REQUIRE_ENCRYPTION", c.Security.RequireEncryption)
+
+	// Crawl overrides
+	c.Crawl.IgnoreRobots = r.Bool("CRAWL_IGNORE_ROBOTS", c.Crawl.IgnoreRobots)
+	c.Crawl.RateLimit = r.Int("CRAWL_RATE_LIMIT", c.Crawl.RateLimit)
+	c.Crawl.MaxConcurrency = r.Int("CRAWL_MAX_CONCURRENCY", c.Crawl.MaxConcurrency)
 
 	// Secrets provider overrides
+	c.Secrets.mergeEnv()
+
+	for _, err := range r.Errors() {
+		slog.Warn("ignoring invalid config value", "error", err)
+	}
+
+	return c
+}
+
+// mergeEnv merges the secrets provider environment variables into s.
+func (s *SecretsFileConfig) mergeEnv() {
 	if v := os.Getenv("SECRETS_PROVIDER"); v != "" {
-		c.Secrets.Provider = v
+		s.Provider = v
 	}
 	if v := os.Getenv("SECRETS_PREFIX"); v != "" {
-		c.Secrets.Prefix = v
+		s.Prefix = v
 	}
-	if v := os.Getenv("AWS_REGION"); v != "" && c.Secrets.Region == "" {
-		c.Secrets.Region = v
+	if v := os.Getenv("AWS_REGION"); v != "" && s.Region == "" {
+		s.Region = v
 	}
-
-	return c
+	if v := os.Getenv("GOOGLE_CLOUD_PROJECT"); v != "" && s.Project == "" {
+		s.Project = v
+	}
+	s.Identity.mergeEnv()
 }
@@ -0,0 +1,299 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/plexusone/omnivault/vault"
+)
+
+// GCP API endpoints.
+const (
+	gcpSecretManagerURL = "https://secretmanager.googleapis.com/v1"
+	gcpMetadataURL      = "http://metadata.google.internal/computeMetadata/v1"
+)
+
+// GCPSecretManagerProvider is an OmniVault provider for Google Cloud Secret
+// Manager. It reads the latest version of each secret. Secret IDs may only
+// contain letters, digits, "-" and "_", so "/" in a path (as in a
+// "stats-agent/" prefix) is replaced with "_".
+//
+// Access tokens come from GOOGLE_OAUTH_ACCESS_TOKEN, the metadata server
+// (Cloud Run, GKE, Compute Engine) or, for local development,
+// "gcloud auth print-access-token".
+type GCPSecretManagerProvider struct {
+	project string
+	client  *http.Client
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+// NewGCPSecretManagerProvider creates a GCP Secret Manager provider for
+// project. If project is empty, it is read from GOOGLE_CLOUD_PROJECT,
+// GCLOUD_PROJECT or the metadata server.
+func NewGCPSecretManagerProvider(ctx context.Context, project string) (*GCPSecretManagerProvider, error) {
+	p := &GCPSecretManagerProvider{
+		project: project,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+	if p.project == "" {
+		p.project = getEnv("GOOGLE_CLOUD_PROJECT", os.Getenv("GCLOUD_PROJECT"))
+	}
+	if p.project == "" {
+		data, err := p.metadata(ctx, "project/project-id")
+		if err != nil {
+			return nil, fmt.Errorf("project is not set (GOOGLE_CLOUD_PROJECT) and the metadata server is unavailable: %w", err)
+		}
+		p.project = string(data)
+	}
+	return p, nil
+}
+
+// Get implements vault.Vault. Secrets whose payload is a JSON object also
+// expose its top-level string fields.
+func (p *GCPSecretManagerProvider) Get(ctx context.Context, path string) (*vault.Secret, error) {
+	var resp struct {
+		Name    string `json:"name"`
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	endpoint := fmt.Sprintf("%s/versions/latest:access", p.secretName(path))
+	if err := p.do(ctx, http.MethodGet, endpoint, &resp); err != nil {
+		return nil, vault.NewVaultError("Get", path, p.Name(), err)
+	}
+	data, err := base64.StdEncoding.DecodeString(resp.Payload.Data)
+	if err != nil {
+		return nil, vault.NewVaultError("Get", path, p.Name(), fmt.Errorf("decoding payload: %w", err))
+	}
+
+	secret := &vault.Secret{
+		Value: string(data),
+		Metadata: vault.Metadata{
+			Version:  resp.Name[strings.LastIndex(resp.Name, "/")+1:],
+			Provider: p.Name(),
+			Path:     path,
+		},
+	}
+	var fields map[string]interface{}
+	if json.Unmarshal(data, &fields) == nil {
+		for k, v := range fields {
+			if s, ok := v.(string); ok {
+				secret.SetField(k, s)
+			}
+		}
+	}
+	return secret, nil
+}
+
+// Set implements vault.Vault. The provider is read-only.
+func (p *GCPSecretManagerProvider) Set(ctx context.Context, path string, secret *vault.Secret) error {
+	return vault.NewVaultError("Set", path, p.Name(), vault.ErrReadOnly)
+}
+
+// Delete implements vault.Vault. The provider is read-only.
+func (p *GCPSecretManagerProvider) Delete(ctx context.Context, path string) error {
+	return vault.NewVaultError("Delete", path, p.Name(), vault.ErrReadOnly)
+}
+
+// Exists implements vault.Vault.
+func (p *GCPSecretManagerProvider) Exists(ctx context.Context, path string) (bool, error) {
+	err := p.do(ctx, http.MethodGet, p.secretName(path), nil)
+	if errors.Is(err, vault.ErrSecretNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, vault.NewVaultError("Exists", path, p.Name(), err)
+	}
+	return true, nil
+}
+
+// List implements vault.Vault. It returns the IDs of the secrets whose ID
+// starts with prefix.
+func (p *GCPSecretManagerProvider) List(ctx context.Context, prefix string) ([]string, error) {
+	idPrefix := gcpSecretID(prefix)
+	var ids []string
+	pageToken := ""
+	for {
+		query := url.Values{"pageSize": {"250"}}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+		var resp struct {
+			Secrets []struct {
+				Name string `json:"name"`
+			} `json:"secrets"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		endpoint := fmt.Sprintf("projects/%s/secrets?%s", url.PathEscape(p.project), query.Encode())
+		if err := p.do(ctx, http.MethodGet, endpoint, &resp); err != nil {
+			return nil, vault.NewVaultError("List", prefix, p.Name(), err)
+		}
+		for _, s := range resp.Secrets {
+			id := s.Name[strings.LastIndex(s.Name, "/")+1:]
+			if strings.HasPrefix(id, idPrefix) {
+				ids = append(ids, id)
+			}
+		}
+		if resp.NextPageToken == "" {
+			return ids, nil
+		}
+		pageToken = resp.NextPageToken
+	}
+}
+
+// Name implements vault.Vault.
+func (p *GCPSecretManagerProvider) Name() string {
+	return string(SecretsProviderGCPSM)
+}
+
+// Capabilities implements vault.Vault.
+func (p *GCPSecretManagerProvider) Capabilities() vault.Capabilities {
+	return vault.Capabilities{
+		Read:       true,
+		List:       true,
+		Versioning: true,
+		MultiField: true,
+	}
+}
+
+// Close implements vault.Vault.
+func (p *GCPSecretManagerProvider) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
+
+// secretName returns the resource name of the secret at path.
+func (p *GCPSecretManagerProvider) secretName(path string) string {
+	return fmt.Sprintf("projects/%s/secrets/%s", url.PathEscape(p.project), url.PathEscape(gcpSecretID(path)))
+}
+
+// gcpSecretID converts a secret path to a Secret Manager secret ID.
+func gcpSecretID(path string) string {
+	return strings.ReplaceAll(strings.Trim(path, "/"), "/", "_")
+}
+
+// do sends an authenticated request to the Secret Manager API and decodes
+// the JSON response into out, if set.
+func (p *GCPSecretManagerProvider) do(ctx context.Context, method, endpoint string, out interface{}) error {
+	token, err := p.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, gcpSecretManagerURL+"/"+endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", vault.ErrConnectionFailed, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return vault.ErrSecretNotFound
+	case resp.StatusCode == http.StatusForbidden:
+		return vault.ErrAccessDenied
+	case resp.StatusCode == http.StatusUnauthorized:
+		return vault.ErrAuthenticationFailed
+	case resp.StatusCode >= 300:
+		var e struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&e)
+		if e.Error.Message != "" {
+			return fmt.Errorf("secret manager returned %s: %s", resp.Status, e.Error.Message)
+		}
+		return fmt.Errorf("secret manager returned %s", resp.Status)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding secret manager response: %w", err)
+	}
+	return nil
+}
+
+// accessToken returns a cached OAuth access token, fetching a new one when
+// it is about to expire.
+func (p *GCPSecretManagerProvider) accessToken(ctx context.Context) (string, error) {
+	if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+		return token, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.token != "" && time.Until(p.tokenExpiry) > time.Minute {
+		return p.token, nil
+	}
+
+	data, err := p.metadata(ctx, "instance/service-accounts/default/token")
+	if err == nil {
+		var resp struct {
+			AccessToken string `json:"access_token"`
+			ExpiresIn   int    `json:"expires_in"`
+		}
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return "", fmt.Errorf("%w: parsing metadata token: %v", vault.ErrAuthenticationFailed, err)
+		}
+		p.token = resp.AccessToken
+		p.tokenExpiry = time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+		return p.token, nil
+	}
+
+	// Outside GCP, fall back to the gcloud CLI credentials.
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "gcloud", "auth", "print-access-token")
+	cmd.Stderr = &stderr
+	out, cliErr := cmd.Output()
+	if cliErr != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			cliErr = errors.New(msg)
+		}
+		return "", fmt.Errorf("%w: no metadata server (%v) and gcloud failed: %v", vault.ErrAuthenticationFailed, err, cliErr)
+	}
+	p.token = strings.TrimSpace(string(out))
+	p.tokenExpiry = time.Now().Add(30 * time.Minute)
+	return p.token, nil
+}
+
+// metadata reads a value from the GCP metadata server.
+func (p *GCPSecretManagerProvider) metadata(ctx context.Context, path string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gcpMetadataURL+"/"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("metadata server returned %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,369 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/plexusone/omnivault/vault"
+)
+
+// HashiCorp Vault auth methods.
+const (
+	// VaultAuthToken authenticates with a Vault token (VAULT_TOKEN).
+	VaultAuthToken = "token"
+
+	// VaultAuthAppRole authenticates with an AppRole role ID and secret ID
+	// (VAULT_ROLE_ID, VAULT_SECRET_ID).
+	VaultAuthAppRole = "approle"
+
+	// VaultAuthKubernetes authenticates with the pod's service account token.
+	VaultAuthKubernetes = "kubernetes"
+)
+
+// kubernetesServiceAccountTokenPath is where Kubernetes mounts the pod's
+// service account token.
+const kubernetesServiceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// VaultConfig configures the HashiCorp Vault secrets provider. Secrets are
+// read from a KV version 2 secrets engine. Credentials (tokens and AppRole
+// secret IDs) are read from the environment, never from config files.
+type VaultConfig struct {
+	// Address is the Vault server address.
+	// Default: VAULT_ADDR, or "http://127.0.0.1:8200"
+	Address string `json:"address" yaml:"address" validate:"omitempty,url"`
+
+	// AuthMethod is how the provider authenticates to Vault.
+	// Supported: "token", "approle", "kubernetes"
+	// Default: "token"
+	AuthMethod string `json:"authMethod" yaml:"authMethod" validate:"omitempty,oneof=token approle kubernetes"`
+
+	// AuthMountPath is the mount path of the auth method.
+	// Default: the auth method name ("approle" or "kubernetes")
+	AuthMountPath string `json:"authMountPath" yaml:"authMountPath"`
+
+	// MountPath is the mount path of the KV version 2 secrets engine.
+	// Default: "secret"
+	MountPath string `json:"mountPath" yaml:"mountPath"`
+
+	// Namespace is the Vault Enterprise namespace.
+	// Default: VAULT_NAMESPACE
+	Namespace string `json:"namespace" yaml:"namespace"`
+
+	// Role is the Vault role for kubernetes auth.
+	// Default: VAULT_ROLE
+	Role string `json:"role" yaml:"role"`
+
+	// RoleID is the AppRole role ID.
+	// Default: VAULT_ROLE_ID
+	RoleID string `json:"roleId" yaml:"roleId"`
+}
+
+// VaultProvider is an OmniVault provider for HashiCorp Vault KV version 2
+// secrets. A secret's "value" field is its value; its other fields are
+// available with SecretsClient.GetField.
+type VaultProvider struct {
+	config VaultConfig
+	client *http.Client
+
+	mu    sync.Mutex
+	token string
+}
+
+// NewVaultProvider creates a HashiCorp Vault provider, filling in unset
+// fields from the VAULT_* environment variables.
+func NewVaultProvider(cfg VaultConfig) (*VaultProvider, error) {
+	if cfg.Address == "" {
+		cfg.Address = getEnv("VAULT_ADDR", "http://127.0.0.1:8200")
+	}
+	cfg.Address = strings.TrimSuffix(cfg.Address, "/")
+	if cfg.AuthMethod == "" {
+		cfg.AuthMethod = VaultAuthToken
+	}
+	if cfg.AuthMountPath == "" {
+		cfg.AuthMountPath = cfg.AuthMethod
+	}
+	if cfg.MountPath == "" {
+		cfg.MountPath = "secret"
+	}
+	cfg.MountPath = strings.Trim(cfg.MountPath, "/")
+	if cfg.Namespace == "" {
+		cfg.Namespace = os.Getenv("VAULT_NAMESPACE")
+	}
+	if cfg.Role == "" {
+		cfg.Role = os.Getenv("VAULT_ROLE")
+	}
+	if cfg.RoleID == "" {
+		cfg.RoleID = os.Getenv("VAULT_ROLE_ID")
+	}
+
+	p := &VaultProvider{
+		config: cfg,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+
+	switch cfg.AuthMethod {
+	case VaultAuthToken:
+		p.token = os.Getenv("VAULT_TOKEN")
+		if p.token == "" {
+			return nil, fmt.Errorf("vault token auth requires VAULT_TOKEN")
+		}
+	case VaultAuthAppRole:
+		if cfg.RoleID == "" || os.Getenv("VAULT_SECRET_ID") == "" {
+			return nil, fmt.Errorf("vault approle auth requires a role ID and VAULT_SECRET_ID")
+		}
+	case VaultAuthKubernetes:
+		if cfg.Role == "" {
+			return nil, fmt.Errorf("vault kubernetes auth requires a role")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported vault auth method: %s", cfg.AuthMethod)
+	}
+	return p, nil
+}
+
+// Get implements vault.Vault.
+func (p *VaultProvider) Get(ctx context.Context, path string) (*vault.Secret, error) {
+	var resp struct {
+		Data struct {
+			Data     map[string]interface{} `json:"data"`
+			Metadata struct {
+				Version int `json:"version"`
+			} `json:"metadata"`
+		} `json:"data"`
+	}
+	if err := p.do(ctx, http.MethodGet, p.kvPath("data", path), nil, &resp); err != nil {
+		return nil, vault.NewVaultError("Get", path, p.Name(), err)
+	}
+
+	secret := &vault.Secret{
+		Fields: make(map[string]string, len(resp.Data.Data)),
+		Metadata: vault.Metadata{
+			Version:  fmt.Sprintf("%d", resp.Data.Metadata.Version),
+			Provider: p.Name(),
+			Path:     path,
+		},
+	}
+	for k, v := range resp.Data.Data {
+		if s, ok := v.(string); ok {
+			secret.Fields[k] = s
+		} else {
+			b, _ := json.Marshal(v)
+			secret.Fields[k] = string(b)
+		}
+	}
+	// A secret with a single field is that field's value.
+	if v, ok := secret.Fields["value"]; ok {
+		secret.Value = v
+	} else if len(secret.Fields) == 1 {
+		for _, v := range secret.Fields {
+			secret.Value = v
+		}
+	}
+	return secret, nil
+}
+
+// Set implements vault.Vault.
+func (p *VaultProvider) Set(ctx context.Context, path string, secret *vault.Secret) error {
+	data := make(map[string]string, len(secret.Fields)+1)
+	for k, v := range secret.Fields {
+		data[k] = v
+	}
+	if value := secret.String(); value != "" {
+		data["value"] = value
+	}
+	body := map[string]interface{}{"data": data}
+	if err := p.do(ctx, http.MethodPost, p.kvPath("data", path), body, nil); err != nil {
+		return vault.NewVaultError("Set", path, p.Name(), err)
+	}
+	return nil
+}
+
+// Delete implements vault.Vault. It deletes every version of the secret.
+func (p *VaultProvider) Delete(ctx context.Context, path string) error {
+	err := p.do(ctx, http.MethodDelete, p.kvPath("metadata", path), nil, nil)
+	if err != nil && !errors.Is(err, vault.ErrSecretNotFound) {
+		return vault.NewVaultError("Delete", path, p.Name(), err)
+	}
+	return nil
+}
+
+// Exists implements vault.Vault.
+func (p *VaultProvider) Exists(ctx context.Context, path string) (bool, error) {
+	_, err := p.Get(ctx, path)
+	if errors.Is(err, vault.ErrSecretNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// List implements vault.Vault. It lists the secrets and folders directly
+// under prefix; folder names end with "/".
+func (p *VaultProvider) List(ctx context.Context, prefix string) ([]string, error) {
+	var resp struct {
+		Data struct {
+			Keys []string `json:"keys"`
+		} `json:"data"`
+	}
+	err := p.do(ctx, "LIST", p.kvPath("metadata", prefix), nil, &resp)
+	if errors.Is(err, vault.ErrSecretNotFound) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, vault.NewVaultError("List", prefix, p.Name(), err)
+	}
+	dir := strings.Trim(prefix, "/")
+	if dir != "" {
+		dir += "/"
+	}
+	paths := make([]string, len(resp.Data.Keys))
+	for i, key := range resp.Data.Keys {
+		paths[i] = dir + key
+	}
+	return paths, nil
+}
+
+// Name implements vault.Vault.
+func (p *VaultProvider) Name() string {
+	return string(SecretsProviderVault)
+}
+
+// Capabilities implements vault.Vault.
+func (p *VaultProvider) Capabilities() vault.Capabilities {
+	return vault.Capabilities{
+		Read:       true,
+		Write:      true,
+		Delete:     true,
+		List:       true,
+		Versioning: true,
+		MultiField: true,
+	}
+}
+
+// Close implements vault.Vault.
+func (p *VaultProvider) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
+
+// kvPath returns the KV version 2 API path of a secret.
+func (p *VaultProvider) kvPath(kind, path string) string {
+	return fmt.Sprintf("%s/%s/%s", p.config.MountPath, kind, strings.Trim(path, "/"))
+}
+
+// do sends an authenticated request to the Vault API. A rejected token is
+// renewed by logging in again, once.
+func (p *VaultProvider) do(ctx context.Context, method, path string, body, out interface{}) error {
+	token, err := p.authToken(ctx, false)
+	if err != nil {
+		return err
+	}
+	err = p.request(ctx, method, path, token, body, out)
+	if errors.Is(err, vault.ErrAccessDenied) && p.config.AuthMethod != VaultAuthToken {
+		if token, err = p.authToken(ctx, true); err != nil {
+			return err
+		}
+		err = p.request(ctx, method, path, token, body, out)
+	}
+	return err
+}
+
+// authToken returns the Vault token, logging in if there is none or if
+// renew is set.
+func (p *VaultProvider) authToken(ctx context.Context, renew bool) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.token != "" && (!renew || p.config.AuthMethod == VaultAuthToken) {
+		return p.token, nil
+	}
+
+	var login map[string]string
+	switch p.config.AuthMethod {
+	case VaultAuthAppRole:
+		login = map[string]string{"role_id": p.config.RoleID, "secret_id": os.Getenv("VAULT_SECRET_ID")}
+	case VaultAuthKubernetes:
+		jwt, err := os.ReadFile(kubernetesServiceAccountTokenPath)
+		if err != nil {
+			return "", fmt.Errorf("%w: reading service account token: %v", vault.ErrAuthenticationFailed, err)
+		}
+		login = map[string]string{"role": p.config.Role, "jwt": strings.TrimSpace(string(jwt))}
+	}
+
+	var resp struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	if err := p.request(ctx, http.MethodPost, "auth/"+strings.Trim(p.config.AuthMountPath, "/")+"/login", "", login, &resp); err != nil {
+		return "", fmt.Errorf("%w: %s login: %v", vault.ErrAuthenticationFailed, p.config.AuthMethod, err)
+	}
+	if resp.Auth.ClientToken == "" {
+		return "", fmt.Errorf("%w: %s login returned no token", vault.ErrAuthenticationFailed, p.config.AuthMethod)
+	}
+	p.token = resp.Auth.ClientToken
+	return p.token, nil
+}
+
+// request sends a request to the Vault API and decodes the JSON response
+// into out, if set.
+func (p *VaultProvider) request(ctx context.Context, method, path, token string, body, out interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, p.config.Address+"/v1/"+path, reqBody)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if p.config.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.config.Namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", vault.ErrConnectionFailed, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return vault.ErrSecretNotFound
+	case resp.StatusCode == http.StatusForbidden:
+		return vault.ErrAccessDenied
+	case resp.StatusCode >= 300:
+		var e struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&e)
+		if len(e.Errors) > 0 {
+			return fmt.Errorf("vault returned %s: %s", resp.Status, strings.Join(e.Errors, "; "))
+		}
+		return fmt.Errorf("vault returned %s", resp.Status)
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding vault response: %w", err)
+	}
+	return nil
+}
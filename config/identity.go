@@ -0,0 +1,138 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// AWS credential sources, as reported by DetectAWSCredentialSource.
+const (
+	// AWSCredentialsContainer means credentials come from the container
+	// credentials endpoint, which serves ECS task roles and the AgentCore
+	// Runtime execution role.
+	AWSCredentialsContainer = "container"
+
+	// AWSCredentialsWebIdentity means credentials come from a web identity
+	// token, as with IAM Roles for Service Accounts (IRSA) on EKS.
+	AWSCredentialsWebIdentity = "web-identity"
+
+	// AWSCredentialsLambda means credentials come from the Lambda
+	// execution role.
+	AWSCredentialsLambda = "lambda"
+
+	// AWSCredentialsStatic means static access keys are set in the
+	// environment.
+	AWSCredentialsStatic = "static"
+)
+
+// WorkloadIdentityConfig configures role-based access to the AWS secrets
+// providers (aws-sm, aws-ssm). The base credentials always come from the
+// workload's identity through the AWS default credential chain: an ECS
+// task role, IRSA, the AgentCore Runtime execution role, or a Lambda
+// execution role. Set RoleARN to assume a further role, for example one
+// in the account that owns the secrets, so cross-account access needs no
+// static AWS keys.
+type WorkloadIdentityConfig struct {
+	// RoleARN is the IAM role to assume for secret access, e.g.
+	// "arn:aws:iam::123456789012:role/agent-secrets-reader".
+	// Default: SECRETS_ROLE_ARN
+	RoleARN string `json:"roleArn" yaml:"roleArn"`
+
+	// ExternalID is passed when assuming RoleARN, for trust policies that
+	// require one. It is not a secret, but should be unique per tenant.
+	// Default: SECRETS_EXTERNAL_ID
+	ExternalID string `json:"externalId" yaml:"externalId"`
+
+	// SessionName names the assumed-role session in CloudTrail.
+	// Default: SECRETS_ROLE_SESSION_NAME, or "agentkit"
+	SessionName string `json:"sessionName" yaml:"sessionName"`
+}
+
+// mergeEnv fills unset fields from the SECRETS_ROLE_* environment
+// variables.
+func (w *WorkloadIdentityConfig) mergeEnv() {
+	if v := os.Getenv("SECRETS_ROLE_ARN"); v != "" && w.RoleARN == "" {
+		w.RoleARN = v
+	}
+	if v := os.Getenv("SECRETS_EXTERNAL_ID"); v != "" && w.ExternalID == "" {
+		w.ExternalID = v
+	}
+	if v := os.Getenv("SECRETS_ROLE_SESSION_NAME"); v != "" && w.SessionName == "" {
+		w.SessionName = v
+	}
+}
+
+// Validate checks that RoleARN is an IAM role ARN and that ExternalID is
+// only set with a role.
+func (w WorkloadIdentityConfig) Validate() error {
+	if w.RoleARN == "" {
+		if w.ExternalID != "" {
+			return fmt.Errorf("externalId is set without roleArn")
+		}
+		return nil
+	}
+	parts := strings.SplitN(w.RoleARN, ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" || parts[2] != "iam" || !strings.HasPrefix(parts[5], "role/") {
+		return fmt.Errorf("roleArn %q is not an IAM role ARN", w.RoleARN)
+	}
+	return nil
+}
+
+// extra returns the assume-role settings as OmniVault provider options.
+func (w WorkloadIdentityConfig) extra() map[string]any {
+	if w.RoleARN == "" {
+		return nil
+	}
+	extra := map[string]any{
+		"role_arn":     w.RoleARN,
+		"session_name": w.SessionName,
+	}
+	if extra["session_name"] == "" {
+		extra["session_name"] = "agentkit"
+	}
+	if w.ExternalID != "" {
+		extra["external_id"] = w.ExternalID
+	}
+	return extra
+}
+
+// describe returns the AWS credential source and the role assumed, if
+// any, or "" if neither is known.
+func (w WorkloadIdentityConfig) describe() string {
+	source := DetectAWSCredentialSource()
+	var parts []string
+	if source != "" {
+		parts = append(parts, source+" credentials")
+	}
+	if w.RoleARN != "" {
+		parts = append(parts, "assuming "+w.RoleARN)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// DetectAWSCredentialSource reports where the AWS default credential chain
+// will find base credentials, or "" if there is no workload identity or
+// static key in the environment (credentials may still come from a
+// shared config file or the EC2 instance profile).
+func DetectAWSCredentialSource() string {
+	switch {
+	case os.Getenv("AWS_ACCESS_KEY_ID") != "" && os.Getenv("AWS_LAMBDA_FUNCTION_NAME") == "":
+		// Static keys take precedence in the chain; Lambda sets them
+		// for its execution role.
+		return AWSCredentialsStatic
+	case os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE") != "":
+		return AWSCredentialsWebIdentity
+	case os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI") != "" || os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI") != "":
+		return AWSCredentialsContainer
+	case os.Getenv("AWS_LAMBDA_FUNCTION_NAME") != "":
+		return AWSCredentialsLambda
+	default:
+		return ""
+	}
+}
+
+// isAWSProvider reports whether p is an AWS secrets provider.
+func isAWSProvider(p SecretsProvider) bool {
+	return p == SecretsProviderAWSSM || p == SecretsProviderAWSSSM
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"reflect"
+)
+
+// Configs can be layered so an organization-wide base, a project config and
+// a developer's local overrides combine into one:
+//
+//	cfg, err := config.LoadLayered(
+//	    "s3://org-config/agents/base.yaml", // organization defaults
+//	    "config.yaml",                      // project settings
+//	    "config.local.yaml",                // developer overrides (optional)
+//	)
+//
+// Later layers take precedence. Objects are merged key by key, so a layer
+// only needs the values it changes; lists and other values replace those
+// of earlier layers.
+
+// LoadLayered loads and merges config files in order of increasing
+// precedence. Each path may be a local file or a remote URI. Local files
+// that do not exist are skipped, so optional override files can be listed.
+// Profiles are applied and environment references expanded after merging,
+// so a profile or reference in any layer sees the merged values.
+func LoadLayered(paths ...string) (*ConfigFile, error) {
+	merged := make(map[string]interface{})
+	for _, path := range paths {
+		data, err := readConfigData(path)
+		if err != nil {
+			if !IsRemoteConfig(path) && errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		var raw map[string]interface{}
+		if err := decodeConfigData(data, path, &raw); err != nil {
+			return nil, err
+		}
+		migrateConfigKeys(raw, path)
+		merged = mergeMaps(merged, raw)
+	}
+	return buildConfigFile(merged)
+}
+
+// Merge returns base with each override merged over it in order. Nested
+// structs and the agents map are merged field by field; an override's
+// non-zero values replace earlier ones. Because zero values are not
+// overrides, Merge cannot turn a setting off; use LoadLayered, which
+// merges file contents, to override with false, 0 or "".
+// The inputs are not modified.
+func Merge(base *ConfigFile, overrides ...*ConfigFile) *ConfigFile {
+	result := &ConfigFile{}
+	for _, c := range append([]*ConfigFile{base}, overrides...) {
+		if c != nil {
+			mergeValue(reflect.ValueOf(result).Elem(), reflect.ValueOf(c).Elem())
+		}
+	}
+	return result
+}
+
+// mergeValue merges the non-zero parts of src into the addressable dst.
+func mergeValue(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Struct:
+		for i := 0; i < src.NumField(); i++ {
+			if src.Type().Field(i).IsExported() {
+				mergeValue(dst.Field(i), src.Field(i))
+			}
+		}
+	case reflect.Map:
+		if src.Len() == 0 {
+			return
+		}
+		merged := reflect.MakeMapWithSize(src.Type(), dst.Len()+src.Len())
+		for _, key := range dst.MapKeys() {
+			merged.SetMapIndex(key, dst.MapIndex(key))
+		}
+		for _, key := range src.MapKeys() {
+			// Map values are not addressable; merge into a copy.
+			elem := reflect.New(src.Type().Elem()).Elem()
+			if existing := merged.MapIndex(key); existing.IsValid() {
+				elem.Set(existing)
+			}
+			mergeValue(elem, src.MapIndex(key))
+			merged.SetMapIndex(key, elem)
+		}
+		dst.Set(merged)
+	default:
+		if !src.IsZero() {
+			dst.Set(src)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package config
+
+import (
+	_ "embed"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultModelsManifest is the built-in model catalog.
+//
+//go:embed models.yaml
+var defaultModelsManifest []byte
+
+// ProviderModels lists the models of one LLM provider.
+type ProviderModels struct {
+	// Default is the model used when none is configured.
+	Default string `json:"default" yaml:"default"`
+
+	// Aliases map names such as "fast" and "smart" to model names.
+	Aliases map[string]string `json:"aliases" yaml:"aliases"`
+
+	// Pricing maps model names to their prices, see Cost.
+	Pricing map[string]ModelPrice `json:"pricing,omitempty" yaml:"pricing,omitempty"`
+}
+
+// ModelCatalog holds the default model, model aliases and model prices of
+// each LLM provider. The built-in catalog is embedded in the library and can be
+// overridden without a release, so model churn only needs a config change:
+//
+//   - AGENTKIT_MODELS_FILE names a manifest file or remote URI, in the
+//     format of DefaultModelCatalog's models.yaml.
+//   - AGENTKIT_MODELS holds an inline YAML or JSON manifest.
+//   - SetModelCatalog replaces the catalog programmatically.
+//
+// Manifests are merged over the built-in catalog, so they only need the
+// entries they change.
+type ModelCatalog struct {
+	// DefaultProvider is the provider whose default model is used for
+	// unknown providers.
+	DefaultProvider string `json:"defaultProvider" yaml:"defaultProvider"`
+
+	// Providers maps provider names to their models.
+	Providers map[string]ProviderModels `json:"providers" yaml:"providers"`
+}
+
+var (
+	modelCatalogMu   sync.RWMutex
+	modelCatalog     *ModelCatalog
+	modelCatalogOnce sync.Once
+)
+
+// DefaultModelCatalog returns the built-in model catalog.
+func DefaultModelCatalog() *ModelCatalog {
+	catalog, err := ParseModelCatalog(defaultModelsManifest)
+	if err != nil {
+		panic(fmt.Sprintf("config: invalid built-in models manifest: %v", err))
+	}
+	return catalog
+}
+
+// ParseModelCatalog parses a YAML or JSON models manifest.
+func ParseModelCatalog(data []byte) (*ModelCatalog, error) {
+	var catalog ModelCatalog
+	if err := yaml.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("parsing models manifest: %w", err)
+	}
+	return &catalog, nil
+}
+
+// LoadModelCatalog loads a models manifest from a file or remote URI and
+// merges it over the built-in catalog.
+func LoadModelCatalog(path string) (*ModelCatalog, error) {
+	data, err := readConfigData(path)
+	if err != nil {
+		return nil, err
+	}
+	override, err := ParseModelCatalog(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return DefaultModelCatalog().Merge(override), nil
+}
+
+// Merge returns c with the entries of override merged over it: a
+// provider's default is replaced if override sets one, and aliases and
+// prices are merged by name. The inputs are not modified.
+func (c *ModelCatalog) Merge(override *ModelCatalog) *ModelCatalog {
+	merged := &ModelCatalog{
+		DefaultProvider: c.DefaultProvider,
+		Providers:       make(map[string]ProviderModels, len(c.Providers)),
+	}
+	for _, catalog := range []*ModelCatalog{c, override} {
+		if catalog.DefaultProvider != "" {
+			merged.DefaultProvider = catalog.DefaultProvider
+		}
+		for name, models := range catalog.Providers {
+			m := merged.Providers[name]
+			if models.Default != "" {
+				m.Default = models.Default
+			}
+			aliases := make(map[string]string, len(m.Aliases)+len(models.Aliases))
+			for alias, model := range m.Aliases {
+				aliases[alias] = model
+			}
+			for alias, model := range models.Aliases {
+				aliases[alias] = model
+			}
+			m.Aliases = aliases
+			pricing := make(map[string]ModelPrice, len(m.Pricing)+len(models.Pricing))
+			for model, price := range m.Pricing {
+				pricing[model] = price
+			}
+			for model, price := range models.Pricing {
+				pricing[model] = price
+			}
+			m.Pricing = pricing
+			merged.Providers[name] = m
+		}
+	}
+	return merged
+}
+
+// DefaultModel returns the default model of provider, or of the default
+// provider if provider is unknown.
+func (c *ModelCatalog) DefaultModel(provider string) string {
+	if models, ok := c.Providers[provider]; ok && models.Default != "" {
+		return models.Default
+	}
+	return c.Providers[c.DefaultProvider].Default
+}
+
+// ResolveModel returns the model name for a configured model of provider:
+// an alias is replaced by its model, an empty model by the provider's
+// default, and other names are returned unchanged.
+func (c *ModelCatalog) ResolveModel(provider, model string) string {
+	if model == "" {
+		return c.DefaultModel(provider)
+	}
+	if resolved, ok := c.Providers[provider].Aliases[model]; ok {
+		return resolved
+	}
+	return model
+}
+
+// SetModelCatalog replaces the model catalog used by GetDefaultModel and
+// ResolveModel. Use DefaultModelCatalog().Merge to change only some
+// entries.
+func SetModelCatalog(catalog *ModelCatalog) {
+	modelCatalogOnce.Do(func() {}) // Skip loading from the environment.
+	modelCatalogMu.Lock()
+	defer modelCatalogMu.Unlock()
+	modelCatalog = catalog
+}
+
+// CurrentModelCatalog returns the model catalog in use: the built-in
+// catalog with the AGENTKIT_MODELS_FILE and AGENTKIT_MODELS overrides, or
+// the catalog set with SetModelCatalog. Invalid overrides are logged and
+// ignored.
+func CurrentModelCatalog() *ModelCatalog {
+	modelCatalogOnce.Do(func() {
+		catalog := loadModelCatalogFromEnv()
+		modelCatalogMu.Lock()
+		defer modelCatalogMu.Unlock()
+		modelCatalog = catalog
+	})
+	modelCatalogMu.RLock()
+	defer modelCatalogMu.RUnlock()
+	return modelCatalog
+}
+
+// loadModelCatalogFromEnv loads the built-in catalog with the overrides
+// from AGENTKIT_MODELS_FILE and AGENTKIT_MODELS.
+func loadModelCatalogFromEnv() *ModelCatalog {
+	catalog := DefaultModelCatalog()
+	if path := os.Getenv("AGENTKIT_MODELS_FILE"); path != "" {
+		loaded, err := LoadModelCatalog(path)
+		if err != nil {
+			slog.Warn("ignoring models manifest", "file", path, "error", err)
+		} else {
+			catalog = loaded
+		}
+	}
+	if inline := os.Getenv("AGENTKIT_MODELS"); inline != "" {
+		override, err := ParseModelCatalog([]byte(inline))
+		if err != nil {
+			slog.Warn("ignoring AGENTKIT_MODELS", "error", err)
+		} else {
+			catalog = catalog.Merge(override)
+		}
+	}
+	return catalog
+}
+
+// ResolveModel resolves a configured model of provider with the current
+// model catalog; see ModelCatalog.ResolveModel.
+func ResolveModel(provider, model string) string {
+	return CurrentModelCatalog().ResolveModel(provider, model)
+}
@@ -3,6 +3,9 @@ package config
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -28,6 +31,18 @@ const (
 
 	// SecretsProviderMemory uses in-memory storage (testing).
 	SecretsProviderMemory SecretsProvider = "memory"
+
+	// SecretsProviderVault uses HashiCorp Vault (KV version 2).
+	SecretsProviderVault SecretsProvider = "vault"
+
+	// SecretsProviderGCPSM uses Google Cloud Secret Manager.
+	SecretsProviderGCPSM SecretsProvider = "gcp-sm"
+
+	// SecretsProviderDoppler uses Doppler (DOPPLER_TOKEN service token).
+	SecretsProviderDoppler SecretsProvider = "doppler"
+
+	// SecretsProvider1Password uses 1Password Connect (OP_CONNECT_TOKEN).
+	SecretsProvider1Password SecretsProvider = "op"
 )
 
 // SecretsConfig holds configuration for OmniVault secrets management.
@@ -43,6 +58,24 @@ type SecretsConfig struct {
 	// Region is the AWS region (for aws-sm, aws-ssm providers).
 	Region string
 
+	// Identity configures an IAM role to assume for secret access (for
+	// aws-sm, aws-ssm providers). Without a role, the workload's own
+	// identity is used.
+	Identity WorkloadIdentityConfig
+
+	// Project is the GCP project ID (for the gcp-sm provider).
+	// Default: GOOGLE_CLOUD_PROJECT, or the metadata server project
+	Project string
+
+	// Vault configures the HashiCorp Vault connection (for the vault provider).
+	Vault VaultConfig
+
+	// Doppler configures the Doppler project (for the doppler provider).
+	Doppler DopplerConfig
+
+	// OnePassword configures the 1Password Connect server (for the op provider).
+	OnePassword OnePasswordConfig
+
 	// CustomVault allows injecting a custom vault implementation.
 	// When set, this takes precedence over Provider.
 	CustomVault vault.Vault
@@ -56,6 +89,10 @@ type SecretsConfig struct {
 	FallbackToEnv bool
 }
 
+// ErrSecretsReadOnly is returned when writing to a secrets provider that
+// does not support writes.
+var ErrSecretsReadOnly = errors.New("secrets provider is read-only")
+
 // SecretsClient wraps OmniVault with agentkit-specific functionality.
 type SecretsClient struct {
 	client        *omnivault.Client
@@ -86,6 +123,14 @@ func NewSecretsClient(cfg SecretsConfig) (*SecretsClient, error) {
 		provider = omnivault.ProviderAWSParameterStore
 	case SecretsProviderMemory:
 		provider = omnivault.ProviderMemory
+	case SecretsProviderVault:
+		provider = omnivault.ProviderHashiCorpVault
+	case SecretsProviderGCPSM:
+		provider = omnivault.ProviderGCPSecretManager
+	case SecretsProviderDoppler:
+		provider = omnivault.ProviderDoppler
+	case SecretsProvider1Password:
+		provider = omnivault.Provider1Password
 	default:
 		// Allow passing through other omnivault providers directly
 		provider = omnivault.ProviderName(cfg.Provider)
@@ -98,11 +143,32 @@ func NewSecretsClient(cfg SecretsConfig) (*SecretsClient, error) {
 		Logger:      cfg.Logger,
 	}
 
+	// Use agentkit's providers for backends OmniVault has no built-in for
+	if ovConfig.CustomVault == nil {
+		v, err := newProviderVault(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("creating %s provider: %w", cfg.Provider, err)
+		}
+		if v != nil {
+			ovConfig.CustomVault = v
+		}
+	}
+
 	// Add provider-specific config for AWS
-	if cfg.Region != "" && (cfg.Provider == SecretsProviderAWSSM || cfg.Provider == SecretsProviderAWSSSM) {
-		ovConfig.Extra = map[string]any{
-			"region": cfg.Region,
+	if err := cfg.Identity.Validate(); err != nil {
+		return nil, fmt.Errorf("secrets identity: %w", err)
+	}
+	if isAWSProvider(cfg.Provider) {
+		extra := cfg.Identity.extra()
+		if cfg.Region != "" {
+			if extra == nil {
+				extra = make(map[string]any)
+			}
+			extra["region"] = cfg.Region
 		}
+		ovConfig.Extra = extra
+	} else if cfg.Identity.RoleARN != "" {
+		return nil, fmt.Errorf("secrets identity: roleArn is only supported by the aws-sm and aws-ssm providers, not %s", cfg.Provider)
 	}
 
 	client, err := omnivault.NewClient(ovConfig)
@@ -117,30 +183,32 @@ func NewSecretsClient(cfg SecretsConfig) (*SecretsClient, error) {
 	}, nil
 }
 
+// newProviderVault creates the agentkit provider for cfg.Provider, or
+// returns nil if the provider is built into OmniVault.
+func newProviderVault(cfg SecretsConfig) (vault.Vault, error) {
+	switch cfg.Provider {
+	case SecretsProviderVault:
+		return NewVaultProvider(cfg.Vault)
+	case SecretsProviderGCPSM:
+		return NewGCPSecretManagerProvider(context.Background(), cfg.Project)
+	case SecretsProviderDoppler:
+		return NewDopplerProvider(cfg.Doppler)
+	case SecretsProvider1Password:
+		return NewOnePasswordProvider(cfg.OnePassword)
+	default:
+		return nil, nil
+	}
+}
+
 // Get retrieves a secret by name.
 // If a prefix is configured, it's prepended to the name.
 // Falls back to environment variables if configured and secret not found.
 func (sc *SecretsClient) Get(ctx context.Context, name string) (string, error) {
-	// Build the full path with prefix
-	path := name
-	if sc.config.Prefix != "" {
-		path = sc.config.Prefix + name
-	}
-
-	// Try the primary provider
-	value, err := sc.client.GetValue(ctx, path)
+	value, err := sc.getFromProvider(ctx, name)
 	if err == nil && value != "" {
 		return value, nil
 	}
 
-	// Try without prefix if prefixed lookup failed
-	if sc.config.Prefix != "" && err != nil {
-		value, err = sc.client.GetValue(ctx, name)
-		if err == nil && value != "" {
-			return value, nil
-		}
-	}
-
 	// Fallback to environment variables
 	if sc.fallbackToEnv && sc.config.Provider != SecretsProviderEnv {
 		if envValue := os.Getenv(name); envValue != "" {
@@ -154,15 +222,26 @@ func (sc *SecretsClient) Get(ctx context.Context, name string) (string, error) {
 	return "", fmt.Errorf("secret %s not found", name)
 }
 
+// getFromProvider retrieves a secret value from the provider, without the
+// environment fallback.
+func (sc *SecretsClient) getFromProvider(ctx context.Context, name string) (string, error) {
+	// Try the primary provider
+	value, err := sc.client.GetValue(ctx, sc.path(name))
+	if err == nil && value != "" {
+		return value, nil
+	}
+
+	// Try without prefix if prefixed lookup failed
+	if sc.config.Prefix != "" && err != nil {
+		value, err = sc.client.GetValue(ctx, name)
+	}
+	return value, err
+}
+
 // GetField retrieves a specific field from a JSON secret.
 // Useful for AWS Secrets Manager secrets with multiple key-value pairs.
 func (sc *SecretsClient) GetField(ctx context.Context, name, field string) (string, error) {
-	path := name
-	if sc.config.Prefix != "" {
-		path = sc.config.Prefix + name
-	}
-
-	value, err := sc.client.GetField(ctx, path, field)
+	value, err := sc.client.GetField(ctx, sc.path(name), field)
 	if err == nil && value != "" {
 		return value, nil
 	}
@@ -182,18 +261,112 @@ func (sc *SecretsClient) GetField(ctx context.Context, name, field string) (stri
 
 // Exists checks if a secret exists.
 func (sc *SecretsClient) Exists(ctx context.Context, name string) bool {
-	path := name
-	if sc.config.Prefix != "" {
-		path = sc.config.Prefix + name
-	}
-
-	exists, err := sc.client.Exists(ctx, path)
+	exists, err := sc.client.Exists(ctx, sc.path(name))
 	if err != nil {
 		return false
 	}
 	return exists
 }
 
+// CanWrite reports whether the provider supports Set and Rotate.
+func (sc *SecretsClient) CanWrite() bool {
+	return sc.client.Capabilities().Write
+}
+
+// Set stores a secret value by name, with the prefix applied.
+// Returns ErrSecretsReadOnly if the provider does not support writes.
+func (sc *SecretsClient) Set(ctx context.Context, name, value string) error {
+	return sc.SetSecret(ctx, name, &vault.Secret{Value: value})
+}
+
+// SetFields stores a multi-field secret (a JSON secret in AWS Secrets
+// Manager, a KV secret in HashiCorp Vault) by name.
+// Returns ErrSecretsReadOnly if the provider does not support writes.
+func (sc *SecretsClient) SetFields(ctx context.Context, name string, fields map[string]string) error {
+	return sc.SetSecret(ctx, name, &vault.Secret{Fields: fields})
+}
+
+// SetSecret stores a secret by name, with the prefix applied.
+// Returns ErrSecretsReadOnly if the provider does not support writes.
+func (sc *SecretsClient) SetSecret(ctx context.Context, name string, secret *vault.Secret) error {
+	if !sc.CanWrite() {
+		return fmt.Errorf("setting secret %s: %w", name, ErrSecretsReadOnly)
+	}
+	if err := sc.client.Set(ctx, sc.path(name), secret); err != nil {
+		return fmt.Errorf("setting secret %s: %w", name, err)
+	}
+	return nil
+}
+
+// Delete removes a secret by name, with the prefix applied. Deleting a
+// secret that does not exist is not an error.
+// Returns ErrSecretsReadOnly if the provider does not support deletes.
+func (sc *SecretsClient) Delete(ctx context.Context, name string) error {
+	if !sc.client.Capabilities().Delete {
+		return fmt.Errorf("deleting secret %s: %w", name, ErrSecretsReadOnly)
+	}
+	if err := sc.client.Delete(ctx, sc.path(name)); err != nil && !errors.Is(err, vault.ErrSecretNotFound) {
+		return fmt.Errorf("deleting secret %s: %w", name, err)
+	}
+	return nil
+}
+
+// RotateFunc returns the new value of a secret being rotated. current is
+// the secret's current value, or empty if it does not exist yet. A
+// RotateFunc for an API key typically creates a new key with the vendor
+// and revokes current after the new key is stored.
+type RotateFunc func(ctx context.Context, current string) (string, error)
+
+// Rotate replaces a secret with a new value and returns it. If generate is
+// nil, the provider's own rotation is used when it supports rotation, and
+// otherwise GenerateRandomSecret.
+// Returns ErrSecretsReadOnly if the provider does not support writes.
+func (sc *SecretsClient) Rotate(ctx context.Context, name string, generate RotateFunc) (string, error) {
+	path := sc.path(name)
+	if generate == nil {
+		if ev, ok := sc.client.Vault().(vault.ExtendedVault); ok && sc.client.Capabilities().Rotation {
+			secret, err := ev.Rotate(ctx, path)
+			if err != nil {
+				return "", fmt.Errorf("rotating secret %s: %w", name, err)
+			}
+			return secret.String(), nil
+		}
+		generate = GenerateRandomSecret
+	}
+	if !sc.CanWrite() {
+		return "", fmt.Errorf("rotating secret %s: %w", name, ErrSecretsReadOnly)
+	}
+
+	current, err := sc.client.GetValue(ctx, path)
+	if err != nil && !errors.Is(err, vault.ErrSecretNotFound) {
+		return "", fmt.Errorf("rotating secret %s: %w", name, err)
+	}
+	value, err := generate(ctx, current)
+	if err != nil {
+		return "", fmt.Errorf("rotating secret %s: generating value: %w", name, err)
+	}
+	if err := sc.client.SetValue(ctx, path, value); err != nil {
+		return "", fmt.Errorf("rotating secret %s: %w", name, err)
+	}
+	return value, nil
+}
+
+// GenerateRandomSecret is a RotateFunc that returns 32 random bytes,
+// base64url-encoded. Use it for secrets agentkit owns, such as A2A shared
+// tokens.
+func GenerateRandomSecret(ctx context.Context, current string) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// path returns the provider path of a secret, with the prefix applied.
+func (sc *SecretsClient) path(name string) string {
+	return sc.config.Prefix + name
+}
+
 // Provider returns the configured provider name.
 func (sc *SecretsClient) Provider() SecretsProvider {
 	return sc.config.Provider
@@ -220,10 +393,12 @@ func DefaultSecretsConfig() SecretsConfig {
 		cfg.Provider = SecretsProvider(provider)
 	}
 
-	// Check for AWS environment indicators
+	// Check for AWS and GCP environment indicators
 	if cfg.Provider == SecretsProviderEnv {
 		if isAWSEnvironment() {
 			cfg.Provider = SecretsProviderAWSSM
+		} else if isGCPEnvironment() {
+			cfg.Provider = SecretsProviderGCPSM
 		}
 	}
 
@@ -243,6 +418,14 @@ func DefaultSecretsConfig() SecretsConfig {
 		cfg.Region = region
 	}
 
+	// Get GCP project from environment
+	if project := os.Getenv("GOOGLE_CLOUD_PROJECT"); project != "" {
+		cfg.Project = project
+	}
+
+	// Get the role to assume from environment
+	cfg.Identity.mergeEnv()
+
 	return cfg
 }
 
@@ -261,6 +444,11 @@ func isAWSEnvironment() bool {
 		return true
 	}
 
+	// Check for a workload identity (IRSA on EKS, container credentials)
+	if os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE") != "" || os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI") != "" {
+		return true
+	}
+
 	// Check for EC2 instance metadata availability
 	// (This is a lightweight check, not a full metadata query)
 	if os.Getenv("AWS_EXECUTION_ENV") != "" {
@@ -269,3 +457,18 @@ func isAWSEnvironment() bool {
 
 	return false
 }
+
+// isGCPEnvironment checks if we're running in a GCP serverless environment.
+func isGCPEnvironment() bool {
+	// Cloud Run services and jobs
+	if os.Getenv("K_SERVICE") != "" || os.Getenv("CLOUD_RUN_JOB") != "" {
+		return true
+	}
+
+	// Cloud Functions
+	if os.Getenv("FUNCTION_TARGET") != "" {
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,260 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/plexusone/omnivault/vault"
+)
+
+// onePasswordIDPattern matches 1Password vault and item UUIDs.
+var onePasswordIDPattern = regexp.MustCompile(`^[a-z0-9]{26}$`)
+
+// OnePasswordConfig configures the 1Password Connect secrets provider. The
+// Connect token is read from OP_CONNECT_TOKEN, never from config files.
+type OnePasswordConfig struct {
+	// Host is the 1Password Connect server URL.
+	// Default: OP_CONNECT_HOST
+	Host string `json:"host" yaml:"host" validate:"omitempty,url"`
+
+	// Vault is the vault name or UUID that holds the secrets.
+	// Default: OP_VAULT
+	Vault string `json:"vault" yaml:"vault"`
+}
+
+// OnePasswordProvider is an OmniVault provider for 1Password Connect. A
+// secret path is an item title; the item's password (or "credential")
+// field is its value, and every field is available by label with
+// SecretsClient.GetField.
+type OnePasswordProvider struct {
+	config OnePasswordConfig
+	token  string
+	client *http.Client
+
+	mu      sync.Mutex
+	vaultID string
+}
+
+// NewOnePasswordProvider creates a 1Password Connect provider, filling in
+// unset fields from the OP_* environment variables.
+func NewOnePasswordProvider(cfg OnePasswordConfig) (*OnePasswordProvider, error) {
+	if cfg.Host == "" {
+		cfg.Host = os.Getenv("OP_CONNECT_HOST")
+	}
+	cfg.Host = strings.TrimSuffix(cfg.Host, "/")
+	if cfg.Vault == "" {
+		cfg.Vault = os.Getenv("OP_VAULT")
+	}
+	token := os.Getenv("OP_CONNECT_TOKEN")
+	switch {
+	case cfg.Host == "":
+		return nil, errors.New("connect host is not set (OP_CONNECT_HOST)")
+	case cfg.Vault == "":
+		return nil, errors.New("vault is not set (OP_VAULT)")
+	case token == "":
+		return nil, errors.New("OP_CONNECT_TOKEN is not set")
+	}
+	return &OnePasswordProvider{
+		config: cfg,
+		token:  token,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// onePasswordItem is a 1Password Connect item.
+type onePasswordItem struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Version int    `json:"version"`
+	Fields  []struct {
+		Label   string `json:"label"`
+		Purpose string `json:"purpose"`
+		Value   string `json:"value"`
+	} `json:"fields"`
+}
+
+// Get implements vault.Vault.
+func (p *OnePasswordProvider) Get(ctx context.Context, path string) (*vault.Secret, error) {
+	item, err := p.item(ctx, path)
+	if err != nil {
+		return nil, vault.NewVaultError("Get", path, p.Name(), err)
+	}
+
+	secret := &vault.Secret{
+		Fields: make(map[string]string, len(item.Fields)),
+		Metadata: vault.Metadata{
+			Version:  fmt.Sprintf("%d", item.Version),
+			Provider: p.Name(),
+			Path:     path,
+		},
+	}
+	for _, f := range item.Fields {
+		if f.Label != "" {
+			secret.Fields[f.Label] = f.Value
+		}
+		if f.Purpose == "PASSWORD" || (secret.Value == "" && f.Label == "credential") {
+			secret.Value = f.Value
+		}
+	}
+	return secret, nil
+}
+
+// Set implements vault.Vault. The provider is read-only.
+func (p *OnePasswordProvider) Set(ctx context.Context, path string, secret *vault.Secret) error {
+	return vault.NewVaultError("Set", path, p.Name(), vault.ErrReadOnly)
+}
+
+// Delete implements vault.Vault. The provider is read-only.
+func (p *OnePasswordProvider) Delete(ctx context.Context, path string) error {
+	return vault.NewVaultError("Delete", path, p.Name(), vault.ErrReadOnly)
+}
+
+// Exists implements vault.Vault.
+func (p *OnePasswordProvider) Exists(ctx context.Context, path string) (bool, error) {
+	_, err := p.item(ctx, path)
+	if errors.Is(err, vault.ErrSecretNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, vault.NewVaultError("Exists", path, p.Name(), err)
+	}
+	return true, nil
+}
+
+// List implements vault.Vault. It returns the titles of the items in the
+// vault that start with prefix.
+func (p *OnePasswordProvider) List(ctx context.Context, prefix string) ([]string, error) {
+	vaultID, err := p.vault(ctx)
+	if err != nil {
+		return nil, vault.NewVaultError("List", prefix, p.Name(), err)
+	}
+	var items []onePasswordItem
+	if err := p.do(ctx, fmt.Sprintf("vaults/%s/items", vaultID), nil, &items); err != nil {
+		return nil, vault.NewVaultError("List", prefix, p.Name(), err)
+	}
+	titles := []string{}
+	for _, item := range items {
+		if strings.HasPrefix(item.Title, prefix) {
+			titles = append(titles, item.Title)
+		}
+	}
+	return titles, nil
+}
+
+// Name implements vault.Vault.
+func (p *OnePasswordProvider) Name() string {
+	return string(SecretsProvider1Password)
+}
+
+// Capabilities implements vault.Vault.
+func (p *OnePasswordProvider) Capabilities() vault.Capabilities {
+	return vault.Capabilities{
+		Read:       true,
+		List:       true,
+		MultiField: true,
+	}
+}
+
+// Close implements vault.Vault.
+func (p *OnePasswordProvider) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
+
+// item returns the item titled title, with its field values.
+func (p *OnePasswordProvider) item(ctx context.Context, title string) (*onePasswordItem, error) {
+	vaultID, err := p.vault(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var items []onePasswordItem
+	query := url.Values{"filter": {fmt.Sprintf("title eq %q", title)}}
+	if err := p.do(ctx, fmt.Sprintf("vaults/%s/items", vaultID), query, &items); err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, vault.ErrSecretNotFound
+	}
+	var item onePasswordItem
+	if err := p.do(ctx, fmt.Sprintf("vaults/%s/items/%s", vaultID, items[0].ID), nil, &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// vault returns the configured vault's UUID, looking it up by name once.
+func (p *OnePasswordProvider) vault(ctx context.Context) (string, error) {
+	if onePasswordIDPattern.MatchString(p.config.Vault) {
+		return p.config.Vault, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.vaultID != "" {
+		return p.vaultID, nil
+	}
+	var vaults []struct {
+		ID string `json:"id"`
+	}
+	query := url.Values{"filter": {fmt.Sprintf("name eq %q", p.config.Vault)}}
+	if err := p.do(ctx, "vaults", query, &vaults); err != nil {
+		return "", fmt.Errorf("looking up vault %s: %w", p.config.Vault, err)
+	}
+	if len(vaults) == 0 {
+		return "", fmt.Errorf("vault %s not found", p.config.Vault)
+	}
+	p.vaultID = vaults[0].ID
+	return p.vaultID, nil
+}
+
+// do sends an authenticated GET request to the Connect API and decodes the
+// JSON response into out.
+func (p *OnePasswordProvider) do(ctx context.Context, endpoint string, query url.Values, out interface{}) error {
+	u := p.config.Host + "/v1/" + endpoint
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.token)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", vault.ErrConnectionFailed, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return vault.ErrSecretNotFound
+	case resp.StatusCode == http.StatusUnauthorized:
+		return vault.ErrAuthenticationFailed
+	case resp.StatusCode == http.StatusForbidden:
+		return vault.ErrAccessDenied
+	case resp.StatusCode >= 300:
+		var e struct {
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&e)
+		if e.Message != "" {
+			return fmt.Errorf("1password connect returned %s: %s", resp.Status, e.Message)
+		}
+		return fmt.Errorf("1password connect returned %s", resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding 1password connect response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,568 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SourceKind is a kind of configuration source.
+type SourceKind string
+
+const (
+	// SourceDefault is a built-in default value.
+	SourceDefault SourceKind = "default"
+
+	// SourceFile is a config file.
+	SourceFile SourceKind = "file"
+
+	// SourceEnv is an environment variable.
+	SourceEnv SourceKind = "env"
+
+	// SourceSecrets is the secrets provider.
+	SourceSecrets SourceKind = "secrets"
+
+	// SourceFlag is a command-line flag.
+	SourceFlag SourceKind = "flag"
+)
+
+// DefaultPrecedence returns the default source precedence, lowest first:
+// defaults, config files, environment variables, the secrets provider,
+// then command-line flags.
+func DefaultPrecedence() []SourceKind {
+	return []SourceKind{SourceDefault, SourceFile, SourceEnv, SourceSecrets, SourceFlag}
+}
+
+// Source records where a config value came from.
+type Source struct {
+	// Kind is the kind of source.
+	Kind SourceKind
+
+	// Name identifies the source: the config file path, environment
+	// variable, secret or flag name. Empty for defaults.
+	Name string
+}
+
+// String formats the source as "kind:name", e.g. "env:LLM_PROVIDER".
+func (s Source) String() string {
+	if s.Name == "" {
+		return string(s.Kind)
+	}
+	return string(s.Kind) + ":" + s.Name
+}
+
+// LoadOption configures Load. LoadOptions is a LoadOption, as are the
+// With* options.
+type LoadOption interface {
+	applyLoad(*loadSettings)
+}
+
+// loadSettings is the combined effect of the LoadOptions given to Load.
+type loadSettings struct {
+	LoadOptions
+	configFiles []string
+	flags       *flag.FlagSet
+	precedence  []SourceKind
+	secrets     *SecretsClient
+}
+
+// applyLoad implements LoadOption. Non-zero fields replace those of
+// earlier options.
+func (o LoadOptions) applyLoad(s *loadSettings) {
+	mergeValue(reflect.ValueOf(&s.LoadOptions).Elem(), reflect.ValueOf(o))
+}
+
+// loadOptionFunc is a LoadOption implemented by a function.
+type loadOptionFunc func(*loadSettings)
+
+func (f loadOptionFunc) applyLoad(s *loadSettings) { f(s) }
+
+// WithConfigFiles loads and layers the given config files instead of a
+// single LoadOptions.ConfigFile; see LoadLayered.
+func WithConfigFiles(paths ...string) LoadOption {
+	return loadOptionFunc(func(s *loadSettings) {
+		s.configFiles = paths
+	})
+}
+
+// WithFlags reads the flags registered with RegisterFlags from fs. Only
+// flags set on the command line are used, so fs must be parsed first.
+func WithFlags(fs *flag.FlagSet) LoadOption {
+	return loadOptionFunc(func(s *loadSettings) {
+		s.flags = fs
+	})
+}
+
+// WithSecretsClient makes Load read secrets from sc instead of creating a
+// client from the secrets settings. The returned Config takes ownership of
+// sc and closes it in Close; if Load fails, it closes sc itself.
+func WithSecretsClient(sc *SecretsClient) LoadOption {
+	return loadOptionFunc(func(s *loadSettings) {
+		s.secrets = sc
+	})
+}
+
+// WithPrecedence sets the order in which sources are applied, lowest
+// precedence first. Sources that are left out are not read; defaults are
+// always applied first. The default is DefaultPrecedence.
+func WithPrecedence(kinds ...SourceKind) LoadOption {
+	return loadOptionFunc(func(s *loadSettings) {
+		s.precedence = kinds
+	})
+}
+
+// fieldSpec declares the sources of one Config field.
+type fieldSpec struct {
+	field   string                   // Config field name
+	file    func(*ConfigFile) string // config file value, "" if unset
+	env     []string                 // environment variables, first set wins
+	secrets []string                 // secret names, first set wins
+	flag    string                   // flag name
+	usage   string                   // flag usage
+	def     func(*Config) string     // default; may use fields resolved before it
+}
+
+// candidate is a value for a field from one source.
+type candidate struct {
+	source Source
+	value  string
+}
+
+// fileBool returns "true" for true and "" for false, so only true file
+// values override defaults.
+func fileBool(v bool) string {
+	if v {
+		return "true"
+	}
+	return ""
+}
+
+// fileInt returns the decimal value of v, or "" for 0.
+func fileInt(v int) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.Itoa(v)
+}
+
+// constant returns a default function for a fixed value.
+func constant(v string) func(*Config) string {
+	return func(*Config) string { return v }
+}
+
+// fieldSpecs declares every Config field Load resolves, in resolution
+// order.
+var fieldSpecs = []fieldSpec{
+	// LLM settings
+	{field: "LLMProvider", file: func(f *ConfigFile) string { return f.LLM.Provider }, env: []string{"LLM_PROVIDER"},
+		flag: "llm-provider", usage: "LLM provider (gemini, claude, openai, xai, ollama, bedrock, azure, groq, mistral)", def: constant("gemini")},
+	{field: "LLMModel", file: func(f *ConfigFile) string { return f.LLM.Model }, env: []string{"LLM_MODEL"},
+		flag: "llm-model", usage: "LLM model name or alias", def: func(c *Config) string { return GetDefaultModel(c.LLMProvider) }},
+	{field: "LLMBaseURL", file: func(f *ConfigFile) string { return f.LLM.BaseURL }, env: []string{"LLM_BASE_URL"},
+		flag: "llm-base-url", usage: "LLM endpoint URL"},
+	{field: "LLMRequestsPerMinute", file: func(f *ConfigFile) string { return fileInt(f.LLM.RequestsPerMinute) }, env: []string{"LLM_REQUESTS_PER_MINUTE"},
+		flag: "llm-requests-per-minute", usage: "LLM requests per minute per provider API key (0 for unlimited)"},
+	{field: "LLMTokensPerMinute", file: func(f *ConfigFile) string { return fileInt(f.LLM.TokensPerMinute) }, env: []string{"LLM_TOKENS_PER_MINUTE"},
+		flag: "llm-tokens-per-minute", usage: "LLM tokens per minute per provider API key (0 for unlimited)"},
+	{field: "OllamaURL", env: []string{"OLLAMA_URL"}, secrets: []string{"OLLAMA_URL"}, def: constant("http://localhost:11434")},
+
+	// API keys
+	{field: "LLMAPIKey", env: []string{"LLM_API_KEY"}, secrets: []string{"LLM_API_KEY"}},
+	{field: "GeminiAPIKey", env: []string{"GEMINI_API_KEY", "GOOGLE_API_KEY"}, secrets: []string{"GEMINI_API_KEY", "GOOGLE_API_KEY"}},
+	{field: "ClaudeAPIKey", env: []string{"CLAUDE_API_KEY", "ANTHROPIC_API_KEY"}, secrets: []string{"CLAUDE_API_KEY", "ANTHROPIC_API_KEY"}},
+	{field: "OpenAIAPIKey", env: []string{"OPENAI_API_KEY"}, secrets: []string{"OPENAI_API_KEY"}},
+	{field: "XAIAPIKey", env: []string{"XAI_API_KEY"}, secrets: []string{"XAI_API_KEY"}},
+	{field: "BedrockAPIKey", env: []string{"AWS_BEARER_TOKEN_BEDROCK"}, secrets: []string{"AWS_BEARER_TOKEN_BEDROCK"}},
+	{field: "BedrockRegion", env: []string{"AWS_REGION", "AWS_DEFAULT_REGION"}, def: constant("us-east-1")},
+	{field: "AzureOpenAIAPIKey", env: []string{"AZURE_OPENAI_API_KEY"}, secrets: []string{"AZURE_OPENAI_API_KEY"}},
+	{field: "AzureOpenAIEndpoint", env: []string{"AZURE_OPENAI_ENDPOINT"}, secrets: []string{"AZURE_OPENAI_ENDPOINT"}},
+	{field: "GroqAPIKey", env: []string{"GROQ_API_KEY"}, secrets: []string{"GROQ_API_KEY"}},
+	{field: "MistralAPIKey", env: []string{"MISTRAL_API_KEY"}, secrets: []string{"MISTRAL_API_KEY"}},
+
+	// Search settings
+	{field: "SearchProvider", file: func(f *ConfigFile) string { return f.Search.Provider }, env: []string{"SEARCH_PROVIDER"},
+		flag: "search-provider", usage: "Search provider (serper, serpapi)", def: constant("serper")},
+	{field: "SerperAPIKey", env: []string{"SERPER_API_KEY"}, secrets: []string{"SERPER_API_KEY"}},
+	{field: "SerpAPIKey", env: []string{"SERPAPI_API_KEY"}, secrets: []string{"SERPAPI_API_KEY"}},
+
+	// A2A Protocol
+	{field: "A2AEnabled", file: func(f *ConfigFile) string { return fileBool(f.A2A.Enabled) }, env: []string{"A2A_ENABLED"},
+		flag: "a2a-enabled", usage: "Enable the A2A server"},
+	{field: "A2AAuthType", file: func(f *ConfigFile) string { return f.A2A.AuthType }, env: []string{"A2A_AUTH_TYPE"},
+		flag: "a2a-auth-type", usage: "A2A authentication (jwt, apikey, oauth2)", def: constant("apikey")},
+	{field: "A2AAuthToken", env: []string{"A2A_AUTH_TOKEN"}, secrets: []string{"A2A_AUTH_TOKEN"}},
+
+	// Observability
+	{field: "ObservabilityEnabled", file: func(f *ConfigFile) string { return fileBool(f.Observability.Enabled) }, env: []string{"OBSERVABILITY_ENABLED"},
+		flag: "observability-enabled", usage: "Enable LLM observability"},
+	{field: "ObservabilityProvider", file: func(f *ConfigFile) string { return f.Observability.Provider }, env: []string{"OBSERVABILITY_PROVIDER"},
+		flag: "observability-provider", usage: "Observability provider (opik, langfuse, phoenix)", def: constant("opik")},
+	{field: "ObservabilityAPIKey", env: []string{"OBSERVABILITY_API_KEY", "OPIK_API_KEY"}, secrets: []string{"OBSERVABILITY_API_KEY", "OPIK_API_KEY"}},
+	{field: "ObservabilityEndpoint", file: func(f *ConfigFile) string { return f.Observability.Endpoint }, env: []string{"OBSERVABILITY_ENDPOINT"},
+		flag: "observability-endpoint", usage: "Observability endpoint URL"},
+	{field: "ObservabilityProject", file: func(f *ConfigFile) string { return f.Observability.Project }, env: []string{"OBSERVABILITY_PROJECT"},
+		flag: "observability-project", usage: "Observability project name", def: constant("agentkit")},
+
+	// Security
+	{field: "SecurityEnabled", file: func(f *ConfigFile) string { return fileBool(f.Security.Enabled) }, env: []string{"SECURITY_ENABLED"},
+		flag: "security-enabled", usage: "Enable VaultGuard security checks"},
+	{field: "SecurityMinScore", file: func(f *ConfigFile) string { return fileInt(f.Security.MinScore) },
+		flag: "security-min-score", usage: "Minimum security score (0-100)", def: constant("50")},
+	{field: "SecurityRequireEncry", file: func(f *ConfigFile) string { return fileBool(f.Security.RequireEncryption) }, env: []string{"SECURITY_REQUIRE_ENCRYPTION"},
+		flag: "security-require-encryption", usage: "Require disk encryption"},
+
+	// Crawling
+	{field: "CrawlIgnoreRobots", file: func(f *ConfigFile) string { return fileBool(f.Crawl.IgnoreRobots) }, env: []string{"CRAWL_IGNORE_ROBOTS"},
+		flag: "crawl-ignore-robots", usage: "Fetch URLs that robots.txt disallows"},
+	{field: "CrawlRateLimit", file: func(f *ConfigFile) string { return fileInt(f.Crawl.RateLimit) }, env: []string{"CRAWL_RATE_LIMIT"},
+		flag: "crawl-rate-limit", usage: "Requests per minute per host (0 for unlimited)"},
+	{field: "CrawlMaxConcurrency", file: func(f *ConfigFile) string { return fileInt(f.Crawl.MaxConcurrency) }, env: []string{"CRAWL_MAX_CONCURRENCY"},
+		flag: "crawl-max-concurrency", usage: "Requests in flight per host", def: constant("4")},
+}
+
+// RegisterFlags defines a flag on fs for every setting Load can read from
+// flags, such as --llm-provider and --a2a-enabled. Pass fs to Load with
+// WithFlags after parsing.
+func RegisterFlags(fs *flag.FlagSet) {
+	cfgType := reflect.TypeOf(Config{})
+	for _, spec := range fieldSpecs {
+		if spec.flag == "" {
+			continue
+		}
+		field, _ := cfgType.FieldByName(spec.field)
+		switch field.Type.Kind() {
+		case reflect.Bool:
+			fs.Bool(spec.flag, false, spec.usage)
+		case reflect.Int:
+			fs.Int(spec.flag, 0, spec.usage)
+		default:
+			fs.String(spec.flag, "", spec.usage)
+		}
+	}
+}
+
+// Load loads configuration from every source with a declared precedence
+// and returns a snapshot of the result. It is the single entry point for
+// configuration:
+//
+//   - defaults
+//   - config files (LoadOptions.ConfigFile, the config file search path, or
+//     WithConfigFiles), with profiles and environment references applied
+//   - environment variables, such as LLM_PROVIDER and GEMINI_API_KEY
+//   - the secrets provider (API keys only), configured by the secrets block
+//     of the config file, SECRETS_* variables and LoadOptions
+//   - command-line flags registered with RegisterFlags (WithFlags)
+//
+// Later sources take precedence; change the order with WithPrecedence.
+// Config.Source reports where each value came from.
+//
+// The returned Config is not updated after Load returns; use a Store to
+// reload. Invalid values (such as A2A_ENABLED=yes) are logged and skipped,
+// or returned as errors with LoadOptions.Strict.
+//
+// Example:
+//
+//	fs := flag.NewFlagSet("agent", flag.ExitOnError)
+//	config.RegisterFlags(fs)
+//	_ = fs.Parse(os.Args[1:])
+//	cfg, err := config.Load(ctx, config.LoadOptions{ConfigFile: "config.yaml"}, config.WithFlags(fs))
+func Load(ctx context.Context, opts ...LoadOption) (*Config, error) {
+	s := &loadSettings{precedence: DefaultPrecedence()}
+	for _, opt := range opts {
+		opt.applyLoad(s)
+	}
+
+	fileCfg, fileName, err := s.loadFile()
+	if err != nil {
+		if s.secrets != nil {
+			_ = s.secrets.Close()
+		}
+		return nil, err
+	}
+
+	// The client also serves Config.GetSecret, so it is created even if
+	// the secrets source is left out of the precedence.
+	secrets := s.secrets
+	if secrets == nil {
+		secrets, err = NewSecretsClient(s.secretsConfig(fileCfg))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cfg := &Config{
+		AgentURLs:         make(map[string]string),
+		AgentDescriptions: make(map[string]string),
+		AgentLLM:          make(map[string]AgentLLMConfig),
+		secrets:           secrets,
+		provenance:        make(map[string]Source),
+	}
+
+	flagValues := make(map[string]string)
+	if s.flags != nil {
+		s.flags.Visit(func(f *flag.Flag) {
+			flagValues[f.Name] = f.Value.String()
+		})
+	}
+
+	var errs []error
+	v := reflect.ValueOf(cfg).Elem()
+	for _, spec := range fieldSpecs {
+		// Each source overrides the ones before it; an invalid value
+		// leaves the previous one in place.
+		var candidates []candidate
+		if spec.def != nil {
+			if d := spec.def(cfg); d != "" {
+				candidates = append(candidates, candidate{Source{Kind: SourceDefault}, d})
+			}
+		}
+		for _, kind := range s.precedence {
+			if source, value, ok := s.lookup(ctx, kind, spec, fileCfg, fileName, secrets, flagValues); ok {
+				candidates = append(candidates, candidate{source, value})
+			}
+		}
+
+		field := v.FieldByName(spec.field)
+		for _, c := range candidates {
+			if err := setField(field, c.value); err != nil {
+				errs = append(errs, fmt.Errorf("%s (%s): %w", spec.field, c.source, err))
+				continue
+			}
+			cfg.provenance[spec.field] = c.source
+		}
+	}
+
+	if slices.Contains(s.precedence, SourceFile) {
+		cfg.loadFileAgents(fileCfg, fileName)
+	}
+	cfg.applyLLMDefaultsWithProvenance()
+
+	if len(errs) > 0 {
+		if s.Strict {
+			_ = cfg.Close()
+			return nil, fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+		}
+		for _, err := range errs {
+			slog.Warn("ignoring invalid config value", "error", err)
+		}
+	}
+	return cfg, nil
+}
+
+// LoadConfigWithFile loads configuration from a config file, environment
+// variables and defaults, with API keys from environment variables only.
+// If path is empty, the config file is searched for as in LoadConfigFile.
+//
+// Deprecated: Use Load, which also reads the secrets provider and flags.
+func LoadConfigWithFile(path string) (*Config, error) {
+	return Load(context.Background(), LoadOptions{ConfigFile: path, SecretsProvider: SecretsProviderEnv})
+}
+
+// loadFile loads the configured config files. It returns an empty config
+// if no file is configured or found.
+func (s *loadSettings) loadFile() (*ConfigFile, string, error) {
+	if !slices.Contains(s.precedence, SourceFile) {
+		return &ConfigFile{}, "", nil
+	}
+	if len(s.configFiles) > 0 {
+		fileCfg, err := LoadLayered(s.configFiles...)
+		if err != nil {
+			return nil, "", err
+		}
+		if s.Strict {
+			if errs := fileCfg.Validate(); len(errs) > 0 {
+				return nil, "", fmt.Errorf("invalid config: %w", errors.Join(errs...))
+			}
+		}
+		return fileCfg, strings.Join(s.configFiles, ","), nil
+	}
+
+	path := s.ConfigFile
+	if path == "" {
+		projectName := s.ProjectName
+		if projectName == "" {
+			projectName = GetProjectName()
+		}
+		found, err := findConfigFile(projectName)
+		if err != nil {
+			// No config file: defaults, environment and secrets only.
+			return &ConfigFile{}, "", nil
+		}
+		path = found
+	}
+
+	loadFile := LoadConfigFile
+	if s.Strict {
+		loadFile = LoadConfigFileStrict
+	}
+	fileCfg, err := loadFile(path, s.ProjectName)
+	if err != nil {
+		return nil, "", err
+	}
+	return fileCfg, path, nil
+}
+
+// secretsConfig returns the secrets provider configuration: the config
+// file's secrets block with SECRETS_* environment overrides, then the
+// explicit options.
+func (s *loadSettings) secretsConfig(fileCfg *ConfigFile) SecretsConfig {
+	sf := fileCfg.Secrets
+	sf.mergeEnv()
+	cfg := SecretsConfig{
+		Provider:      SecretsProvider(sf.Provider),
+		Prefix:        sf.Prefix,
+		Region:        sf.Region,
+		Project:       sf.Project,
+		Vault:         sf.Vault,
+		Doppler:       sf.Doppler,
+		OnePassword:   sf.OnePassword,
+		Identity:      sf.Identity,
+		FallbackToEnv: true,
+	}
+	if cfg.Provider == "" {
+		cfg.Provider = SecretsProviderEnv
+	}
+	if s.SecretsProvider != "" {
+		cfg.Provider = s.SecretsProvider
+	}
+	if s.SecretsPrefix != "" {
+		cfg.Prefix = s.SecretsPrefix
+	}
+	if s.SecretsRegion != "" {
+		cfg.Region = s.SecretsRegion
+	}
+	if s.SecretsProject != "" {
+		cfg.Project = s.SecretsProject
+	}
+	return cfg
+}
+
+// lookup returns the value of spec from one kind of source, if set.
+func (s *loadSettings) lookup(ctx context.Context, kind SourceKind, spec fieldSpec, fileCfg *ConfigFile, fileName string,
+	secrets *SecretsClient, flagValues map[string]string) (Source, string, bool) {
+	switch kind {
+	case SourceFile:
+		if spec.file != nil {
+			if v := spec.file(fileCfg); v != "" {
+				return Source{Kind: SourceFile, Name: fileName}, v, true
+			}
+		}
+	case SourceEnv:
+		for _, name := range spec.env {
+			if v := os.Getenv(name); v != "" {
+				return Source{Kind: SourceEnv, Name: name}, v, true
+			}
+		}
+	case SourceSecrets:
+		// The env provider is the environment source.
+		if secrets == nil || secrets.config.Provider == SecretsProviderEnv {
+			break
+		}
+		for _, name := range spec.secrets {
+			if v, err := secrets.getFromProvider(ctx, name); err == nil && v != "" {
+				return Source{Kind: SourceSecrets, Name: name}, v, true
+			}
+		}
+	case SourceFlag:
+		if v, ok := flagValues[spec.flag]; ok && spec.flag != "" {
+			return Source{Kind: SourceFlag, Name: spec.flag}, v, true
+		}
+	}
+	return Source{}, "", false
+}
+
+// setField parses value into a string, bool or int Config field.
+func setField(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		field.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		field.SetInt(int64(n))
+	default:
+		field.SetString(value)
+	}
+	return nil
+}
+
+// loadFileAgents copies agent URLs, descriptions and LLM overrides from
+// the config file. An agent's URL can be overridden with <NAME>_URL, e.g.
+// RESEARCH_URL.
+func (c *Config) loadFileAgents(fileCfg *ConfigFile, fileName string) {
+	for name, agent := range fileCfg.Agents {
+		key := "AgentURLs[" + name + "]"
+		c.AgentURLs[name] = agent.URL
+		c.provenance[key] = Source{Kind: SourceFile, Name: fileName}
+		if v := os.Getenv(agentURLEnv(name)); v != "" {
+			c.AgentURLs[name] = v
+			c.provenance[key] = Source{Kind: SourceEnv, Name: agentURLEnv(name)}
+		}
+		if agent.Description != "" {
+			c.AgentDescriptions[name] = agent.Description
+		}
+		override := AgentLLMConfig{
+			Provider:    agent.Provider,
+			Model:       agent.Model,
+			Temperature: agent.Temperature,
+			BaseURL:     agent.BaseURL,
+		}
+		if override != (AgentLLMConfig{}) {
+			c.AgentLLM[name] = override
+		}
+	}
+}
+
+// applyLLMDefaultsWithProvenance applies the LLM defaults, recording the
+// source of derived values.
+func (c *Config) applyLLMDefaultsWithProvenance() {
+	apiKey, baseURL := c.LLMAPIKey, c.LLMBaseURL
+	c.applyLLMDefaults()
+	if apiKey == "" && c.LLMAPIKey != "" {
+		providerKey := map[string]string{
+			"gemini":  "GeminiAPIKey",
+			"claude":  "ClaudeAPIKey",
+			"openai":  "OpenAIAPIKey",
+			"xai":     "XAIAPIKey",
+			"bedrock": "BedrockAPIKey",
+			"azure":   "AzureOpenAIAPIKey",
+			"groq":    "GroqAPIKey",
+			"mistral": "MistralAPIKey",
+		}[c.LLMProvider]
+		c.provenance["LLMAPIKey"] = c.provenance[providerKey]
+	}
+	if baseURL == "" && c.LLMBaseURL != "" {
+		c.provenance["LLMBaseURL"] = c.provenance["OllamaURL"]
+	}
+}
+
+// Source returns where a config value came from, by Config field name
+// (such as "LLMProvider") or "AgentURLs[name]" for agent URLs. It reports
+// false for configs not created by Load and values left unset.
+func (c *Config) Source(field string) (Source, bool) {
+	source, ok := c.provenance[field]
+	return source, ok
+}
+
+// Provenance returns the source of every value set by Load, keyed as in
+// Source.
+func (c *Config) Provenance() map[string]Source {
+	provenance := make(map[string]Source, len(c.provenance))
+	for k, v := range c.provenance {
+		provenance[k] = v
+	}
+	return provenance
+}
@@ -0,0 +1,56 @@
+package config
+
+import "sort"
+
+// ModelPrice is the price of a model in USD per million tokens.
+type ModelPrice struct {
+	// Input is the price of prompt tokens.
+	Input float64 `json:"input" yaml:"input"`
+
+	// Output is the price of completion tokens.
+	Output float64 `json:"output" yaml:"output"`
+}
+
+// Cost returns the cost in USD of a call with the given token usage.
+func (p ModelPrice) Cost(promptTokens, completionTokens int) float64 {
+	return (float64(promptTokens)*p.Input + float64(completionTokens)*p.Output) / 1e6
+}
+
+// Price returns the price of a model of provider, with aliases resolved.
+// Models the provider has no price for are looked up by name in the other
+// providers, so provider names of other libraries, such as "anthropic",
+// work for models with unique names. It reports false for unknown models.
+func (c *ModelCatalog) Price(provider, model string) (ModelPrice, bool) {
+	model = c.ResolveModel(provider, model)
+	if price, ok := c.Providers[provider].Pricing[model]; ok {
+		return price, true
+	}
+	names := make([]string, 0, len(c.Providers))
+	for name := range c.Providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if price, ok := c.Providers[name].Pricing[model]; ok {
+			return price, true
+		}
+	}
+	return ModelPrice{}, false
+}
+
+// Cost returns the cost in USD of a call of a model of provider with the
+// given token usage, priced with the current model catalog. It reports
+// false for models without a price. Override prices like the rest of the
+// catalog, e.g. with a pricing entry in AGENTKIT_MODELS:
+//
+//	providers:
+//	  openai:
+//	    pricing:
+//	      gpt-4o: {input: 2.50, output: 10.00}
+func Cost(provider, model string, promptTokens, completionTokens int) (float64, bool) {
+	price, ok := CurrentModelCatalog().Price(provider, model)
+	if !ok {
+		return 0, false
+	}
+	return price.Cost(promptTokens, completionTokens), true
+}
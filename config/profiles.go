@@ -0,0 +1,59 @@
+package config
+
+import "os"
+
+// ProfileEnvVar is the environment variable selecting the config profile.
+// It takes precedence over the config file's environment field.
+const ProfileEnvVar = "AGENT_ENV"
+
+// applyProfile returns the config data with the selected profile
+// deep-merged over the base values. The environment field is set to the
+// selected profile name. Data without a matching profile is returned as is.
+func applyProfile(raw map[string]interface{}) map[string]interface{} {
+	env, _ := raw["environment"].(string)
+	if v := os.Getenv(ProfileEnvVar); v != "" {
+		env = v
+	}
+	if env == "" {
+		return raw
+	}
+
+	merged := make(map[string]interface{}, len(raw))
+	for k, v := range raw {
+		merged[k] = v
+	}
+	merged["environment"] = env
+
+	profiles, _ := raw["profiles"].(map[string]interface{})
+	profile, ok := profiles[env].(map[string]interface{})
+	if !ok {
+		return merged
+	}
+	overrides := make(map[string]interface{}, len(profile))
+	for k, v := range profile {
+		// Profiles cannot select or define other profiles.
+		if k != "environment" && k != "profiles" {
+			overrides[k] = v
+		}
+	}
+	return mergeMaps(merged, overrides)
+}
+
+// mergeMaps returns dst with src deep-merged over it. Nested objects are
+// merged; other values in src replace those in dst.
+func mergeMaps(dst, src map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(dst)+len(src))
+	for k, v := range dst {
+		result[k] = v
+	}
+	for k, v := range src {
+		srcMap, srcOK := v.(map[string]interface{})
+		dstMap, dstOK := result[k].(map[string]interface{})
+		if srcOK && dstOK {
+			result[k] = mergeMaps(dstMap, srcMap)
+			continue
+		}
+		result[k] = v
+	}
+	return result
+}
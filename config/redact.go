@@ -0,0 +1,149 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// redactedValue replaces secret values in redacted output.
+const redactedValue = "[REDACTED]"
+
+// sensitiveKeyPattern matches config keys and field names whose values are
+// secrets.
+var sensitiveKeyPattern = regexp.MustCompile(`(?i)(token|secret|password|apikey|api_key|credential)`)
+
+// redactURL redacts the password and query values of a URL. Other
+// strings are returned unchanged.
+func redactURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return s
+	}
+	if u.RawQuery != "" {
+		q := u.Query()
+		keys := make([]string, 0, len(q))
+		for k := range q {
+			keys = append(keys, url.QueryEscape(k)+"="+redactedValue)
+		}
+		sort.Strings(keys)
+		u.RawQuery = strings.Join(keys, "&")
+	}
+	// Redacted replaces the password with "xxxxx".
+	return u.Redacted()
+}
+
+// Redacted returns a copy of the config with API keys and tokens replaced
+// by "[REDACTED]" and credentials removed from URLs. Unset keys stay empty,
+// so the copy still shows which keys are configured. The copy has no
+// secrets client.
+func (c *Config) Redacted() *Config {
+	r := *c
+	r.secrets = nil
+
+	v := reflect.ValueOf(&r).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		if !t.Field(i).IsExported() || field.Kind() != reflect.String || field.String() == "" {
+			continue
+		}
+		if sensitiveKeyPattern.MatchString(t.Field(i).Name) {
+			field.SetString(redactedValue)
+		} else {
+			field.SetString(redactURL(field.String()))
+		}
+	}
+
+	r.AgentURLs = make(map[string]string, len(c.AgentURLs))
+	for name, u := range c.AgentURLs {
+		r.AgentURLs[name] = redactURL(u)
+	}
+	r.AgentLLM = make(map[string]AgentLLMConfig, len(c.AgentLLM))
+	for name, llm := range c.AgentLLM {
+		llm.BaseURL = redactURL(llm.BaseURL)
+		r.AgentLLM[name] = llm
+	}
+	return &r
+}
+
+// configField is a redacted config field name and value.
+type configField struct {
+	name  string
+	value interface{}
+}
+
+// fields returns the exported fields of the redacted config, in
+// declaration order.
+func (c *Config) fields() []configField {
+	v := reflect.ValueOf(c.Redacted()).Elem()
+	t := v.Type()
+	fields := make([]configField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			fields = append(fields, configField{name: t.Field(i).Name, value: v.Field(i).Interface()})
+		}
+	}
+	return fields
+}
+
+// String implements fmt.Stringer with secrets redacted, so a Config can be
+// logged or printed with %v safely.
+func (c *Config) String() string {
+	var b strings.Builder
+	b.WriteString("Config{")
+	for i, f := range c.fields() {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "%s: %v", f.name, f.value)
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// GoString implements fmt.GoStringer, so %#v is redacted too.
+func (c *Config) GoString() string {
+	return c.String()
+}
+
+// LogValue implements slog.LogValuer with secrets redacted.
+func (c *Config) LogValue() slog.Value {
+	fields := c.fields()
+	attrs := make([]slog.Attr, 0, len(fields))
+	for _, f := range fields {
+		attrs = append(attrs, slog.Any(f.name, f.value))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// DumpEffectiveConfig loads configuration as Load does (config file,
+// defaults, environment overrides and secrets) and writes the result to w,
+// one field per line with its source, with secrets redacted. It is meant
+// for debugging where a setting comes from without leaking secrets into
+// logs.
+func DumpEffectiveConfig(ctx context.Context, w io.Writer, opts LoadOptions) error {
+	cfg, err := Load(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer cfg.Close()
+
+	fmt.Fprintf(w, "SecretsProvider: %s\n", cfg.SecretsProvider())
+	for _, f := range cfg.fields() {
+		line := fmt.Sprintf("%s: %v", f.name, f.value)
+		if source, ok := cfg.Source(f.name); ok {
+			line += "  (" + source.String() + ")"
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
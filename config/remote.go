@@ -0,0 +1,268 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/plexusone/agentkit/internal/awscli"
+)
+
+// Remote config files are loaded from URIs instead of local paths:
+//
+//	https://config.example.com/agents/research.yaml
+//	s3://my-bucket/agents/research.yaml
+//	ssm:///agents/research/config   (parameter "/agents/research/config")
+//
+// Fetched files are cached on disk with their version (an ETag or
+// parameter version). Later loads send the cached version so unchanged
+// files are not downloaded again, and fall back to the cached file if the
+// source is unreachable.
+
+// remoteConfigTimeout bounds a single remote config fetch.
+const remoteConfigTimeout = 30 * time.Second
+
+// ErrNotModified is returned by a ConfigSource when the remote config
+// matches the cached version.
+var ErrNotModified = errors.New("config not modified")
+
+// ConfigSource fetches config file data from a remote location.
+type ConfigSource interface {
+	// Fetch returns the config data at uri and its version. If version is
+	// the current version, Fetch may return ErrNotModified instead.
+	Fetch(ctx context.Context, uri *url.URL, version string) (data []byte, newVersion string, err error)
+}
+
+var (
+	configSourcesMu sync.RWMutex
+	configSources   = map[string]ConfigSource{
+		"https": &HTTPConfigSource{},
+		"s3":    AWSCLIConfigSource{},
+		"ssm":   AWSCLIConfigSource{},
+	}
+)
+
+// RegisterConfigSource registers the source for a URI scheme, replacing
+// any existing one. Use it to fetch s3:// and ssm:// URIs with the AWS SDK
+// instead of the aws CLI.
+func RegisterConfigSource(scheme string, source ConfigSource) {
+	configSourcesMu.Lock()
+	defer configSourcesMu.Unlock()
+	configSources[scheme] = source
+}
+
+// configSource returns the source for the scheme of path, or nil if path
+// is not a remote config URI.
+func configSource(path string) (ConfigSource, *url.URL) {
+	u, err := url.Parse(path)
+	if err != nil || u.Scheme == "" {
+		return nil, nil
+	}
+	configSourcesMu.RLock()
+	defer configSourcesMu.RUnlock()
+	source, ok := configSources[u.Scheme]
+	if !ok {
+		return nil, nil
+	}
+	return source, u
+}
+
+// IsRemoteConfig reports whether path is a URI with a registered source.
+func IsRemoteConfig(path string) bool {
+	source, _ := configSource(path)
+	return source != nil
+}
+
+// readConfigData reads a local config file or fetches a remote one, and
+// decrypts it if it is encrypted.
+func readConfigData(path string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteConfigTimeout)
+	defer cancel()
+
+	var data []byte
+	var err error
+	if source, u := configSource(path); source != nil {
+		data, err = fetchRemoteConfig(ctx, source, u)
+	} else if data, err = os.ReadFile(path); err != nil {
+		err = fmt.Errorf("reading config file: %w", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decryptConfigData(ctx, data, path)
+}
+
+// fetchRemoteConfig fetches a remote config through the on-disk cache.
+func fetchRemoteConfig(ctx context.Context, source ConfigSource, u *url.URL) ([]byte, error) {
+	cachePath := remoteConfigCachePath(u.String())
+	cached, cachedVersion := readRemoteConfigCache(cachePath)
+
+	data, version, err := source.Fetch(ctx, u, cachedVersion)
+	switch {
+	case errors.Is(err, ErrNotModified) && cached != nil:
+		return cached, nil
+	case err != nil && cached != nil:
+		// Serve the last known config while the source is unreachable.
+		return cached, nil
+	case err != nil:
+		return nil, fmt.Errorf("fetching config %s: %w", u.Redacted(), err)
+	}
+
+	writeRemoteConfigCache(cachePath, data, version)
+	return data, nil
+}
+
+// remoteConfigCachePath returns the cache file for a config URI, or an
+// empty string if there is no user cache directory.
+func remoteConfigCachePath(uri string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(uri))
+	return filepath.Join(dir, "agentkit", "config", hex.EncodeToString(sum[:]))
+}
+
+// readRemoteConfigCache returns the cached data and version, or nil if
+// nothing is cached.
+func readRemoteConfigCache(path string) ([]byte, string) {
+	if path == "" {
+		return nil, ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, ""
+	}
+	version, _ := os.ReadFile(path + ".version")
+	return data, string(version)
+}
+
+// writeRemoteConfigCache caches fetched data. Caching is best effort.
+func writeRemoteConfigCache(path string, data []byte, version string) {
+	if path == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return
+	}
+	_ = os.WriteFile(path+".version", []byte(version), 0600)
+}
+
+// HTTPConfigSource fetches https:// config URIs, using the ETag header as
+// the version.
+type HTTPConfigSource struct {
+	// Client is the HTTP client.
+	// Default: http.DefaultClient
+	Client *http.Client
+}
+
+// Fetch implements ConfigSource.
+func (s *HTTPConfigSource) Fetch(ctx context.Context, uri *url.URL, version string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri.String(), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if version != "" {
+		req.Header.Set("If-None-Match", version)
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, version, ErrNotModified
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, resp.Header.Get("ETag"), nil
+}
+
+// AWSCLIConfigSource fetches s3:// and ssm:// config URIs with the aws
+// CLI. S3 objects are versioned by ETag and SSM parameters by parameter
+// version. SecureString parameters are decrypted.
+type AWSCLIConfigSource struct{}
+
+// Fetch implements ConfigSource.
+func (AWSCLIConfigSource) Fetch(ctx context.Context, uri *url.URL, version string) ([]byte, string, error) {
+	switch uri.Scheme {
+	case "s3":
+		return fetchS3Config(ctx, uri, version)
+	case "ssm":
+		return fetchSSMConfig(ctx, uri)
+	default:
+		return nil, "", fmt.Errorf("unsupported scheme %q", uri.Scheme)
+	}
+}
+
+// fetchS3Config downloads an S3 object with "aws s3api get-object".
+func fetchS3Config(ctx context.Context, uri *url.URL, version string) ([]byte, string, error) {
+	args := []string{"s3api", "get-object", "--bucket", uri.Host, "--key", strings.TrimPrefix(uri.Path, "/")}
+	if version != "" {
+		args = append(args, "--if-none-match", version)
+	}
+	data, out, err := awscli.RunOutfile(ctx, args...)
+	if err != nil {
+		if version != "" && (strings.Contains(err.Error(), "304") || strings.Contains(err.Error(), "Not Modified")) {
+			return nil, version, ErrNotModified
+		}
+		return nil, "", err
+	}
+
+	var result struct {
+		ETag string
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return nil, "", fmt.Errorf("parsing get-object output: %w", err)
+	}
+	return data, result.ETag, nil
+}
+
+// fetchSSMConfig reads an SSM parameter with "aws ssm get-parameter".
+// "ssm:///a/b" and "ssm://a/b" both name the parameter "/a/b".
+func fetchSSMConfig(ctx context.Context, uri *url.URL) ([]byte, string, error) {
+	name := uri.Host + uri.Path
+	if uri.Host != "" && uri.Path != "" {
+		name = "/" + name
+	}
+	out, err := awscli.Run(ctx, "ssm", "get-parameter", "--name", name, "--with-decryption")
+	if err != nil {
+		return nil, "", err
+	}
+
+	var result struct {
+		Parameter struct {
+			Value   string
+			Version int64
+		}
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return nil, "", fmt.Errorf("parsing get-parameter output: %w", err)
+	}
+	return []byte(result.Parameter.Value), fmt.Sprintf("%d", result.Parameter.Version), nil
+}
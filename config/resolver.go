@@ -0,0 +1,140 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// LookupFunc returns the value of a config key and whether it is set.
+// os.LookupEnv is a LookupFunc.
+type LookupFunc func(key string) (string, bool)
+
+// Resolver reads typed config values, collecting every missing or invalid
+// value instead of silently falling back to the default. Getters always
+// return a usable value (the default, or the zero value for required keys),
+// so a whole config can be read before checking Err:
+//
+//	r := config.NewResolver(nil)
+//	cfg := MyConfig{
+//	    APIKey:  r.RequiredString("MY_API_KEY"),
+//	    Port:    r.Int("PORT", 8080),
+//	    Timeout: r.Duration("TIMEOUT", 30*time.Second),
+//	    Debug:   r.Bool("DEBUG", false),
+//	}
+//	if err := r.Err(); err != nil {
+//	    return err // lists every problem
+//	}
+type Resolver struct {
+	lookup LookupFunc
+	errs   []error
+}
+
+// NewResolver creates a Resolver that reads values with lookup.
+// If lookup is nil, values are read from environment variables.
+func NewResolver(lookup LookupFunc) *Resolver {
+	if lookup == nil {
+		lookup = lookupEnv
+	}
+	return &Resolver{lookup: lookup}
+}
+
+// lookupEnv is os.LookupEnv, treating empty variables as unset.
+func lookupEnv(key string) (string, bool) {
+	v := getEnv(key, "")
+	return v, v != ""
+}
+
+// String returns the value of key, or def if key is not set.
+func (r *Resolver) String(key, def string) string {
+	if v, ok := r.lookup(key); ok {
+		return v
+	}
+	return def
+}
+
+// RequiredString returns the value of key, recording an error if it is
+// not set.
+func (r *Resolver) RequiredString(key string) string {
+	v, ok := r.lookup(key)
+	if !ok {
+		r.addMissing(key)
+	}
+	return v
+}
+
+// Int returns the integer value of key, or def if key is not set or is
+// not an integer.
+func (r *Resolver) Int(key string, def int) int {
+	return resolve(r, key, def, false, "integer", strconv.Atoi)
+}
+
+// RequiredInt returns the integer value of key, recording an error if it
+// is not set.
+func (r *Resolver) RequiredInt(key string) int {
+	return resolve(r, key, 0, true, "integer", strconv.Atoi)
+}
+
+// Bool returns the boolean value of key, or def if key is not set or is
+// not a boolean. Accepted values are those of strconv.ParseBool
+// ("true", "false", "1", "0", "TRUE", ...).
+func (r *Resolver) Bool(key string, def bool) bool {
+	return resolve(r, key, def, false, "boolean", strconv.ParseBool)
+}
+
+// RequiredBool returns the boolean value of key, recording an error if it
+// is not set.
+func (r *Resolver) RequiredBool(key string) bool {
+	return resolve(r, key, false, true, "boolean", strconv.ParseBool)
+}
+
+// Duration returns the duration value of key (such as "30s" or "5m"), or
+// def if key is not set or is not a duration.
+func (r *Resolver) Duration(key string, def time.Duration) time.Duration {
+	return resolve(r, key, def, false, "duration", time.ParseDuration)
+}
+
+// RequiredDuration returns the duration value of key, recording an error
+// if it is not set.
+func (r *Resolver) RequiredDuration(key string) time.Duration {
+	return resolve(r, key, 0, true, "duration", time.ParseDuration)
+}
+
+// Errors returns every missing required key and invalid value, in the
+// order they were read.
+func (r *Resolver) Errors() []error {
+	return r.errs
+}
+
+// Err returns an error listing every missing required key and invalid
+// value, or nil if there were none.
+func (r *Resolver) Err() error {
+	if len(r.errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid configuration: %w", errors.Join(r.errs...))
+}
+
+// addMissing records a missing required key.
+func (r *Resolver) addMissing(key string) {
+	r.errs = append(r.errs, fmt.Errorf("%s: required but not set", key))
+}
+
+// resolve parses the value of key, recording an error if it is invalid or
+// if it is required and not set.
+func resolve[T any](r *Resolver, key string, def T, required bool, kind string, parse func(string) (T, error)) T {
+	s, ok := r.lookup(key)
+	if !ok {
+		if required {
+			r.addMissing(key)
+		}
+		return def
+	}
+	v, err := parse(s)
+	if err != nil {
+		r.errs = append(r.errs, fmt.Errorf("%s: invalid %s %q", key, kind, s))
+		return def
+	}
+	return v
+}
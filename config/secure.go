@@ -2,22 +2,28 @@ package config
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"github.com/plexusone/omnivault/vault"
 	"github.com/plexusone/vaultguard"
 )
 
-// SecureConfig wraps Config with VaultGuard for secure credential access
-// and optionally integrates with OmniVault for unified secret management.
+// SecureConfig combines VaultGuard and OmniVault: VaultGuard assesses the
+// security of the environment and enforces the policy, and a
+// SecretsClient (OmniVault) supplies the secrets. The secrets backend is
+// the provider configured with WithSecretsProvider or one of its
+// shortcuts, or else VaultGuard's own provider for the environment.
+// Configuration is loaded with Load, reading API keys from that backend.
 type SecureConfig struct {
 	*Config
-	vault   *vaultguard.SecureVault
-	secrets *SecretsClient
+	vault *vaultguard.SecureVault
 }
 
 // LoadSecureConfig loads configuration with VaultGuard security checks.
-// It enforces security policies based on the environment (local or cloud).
-// Optionally integrates with OmniVault for unified secret management.
+// It enforces security policies based on the environment (local or cloud),
+// then loads configuration with Load, reading secrets through a single
+// SecretsClient. Close releases both.
 func LoadSecureConfig(ctx context.Context, opts ...SecureConfigOption) (*SecureConfig, error) {
 	options := &secureConfigOptions{
 		policy: nil, // Use default policy
@@ -37,121 +43,58 @@ func LoadSecureConfig(ctx context.Context, opts ...SecureConfigOption) (*SecureC
 		return nil, fmt.Errorf("security check failed: %w", err)
 	}
 
-	// Create OmniVault secrets client if configured
-	var secrets *SecretsClient
-	if options.secretsConfig != nil {
-		secrets, err = NewSecretsClient(*options.secretsConfig)
-		if err != nil {
-			return nil, fmt.Errorf("creating secrets client: %w", err)
-		}
-	}
-
-	// Load base config
-	cfg := LoadConfig()
-
-	sc := &SecureConfig{
-		Config:  cfg,
-		vault:   sv,
-		secrets: secrets,
-	}
-
-	// Load sensitive credentials (OmniVault first, then VaultGuard fallback)
-	sc.loadSecureCredentials(ctx)
-
-	return sc, nil
-}
-
-// loadSecureCredentials loads API keys from OmniVault (if configured) or VaultGuard.
-// Missing credentials are silently skipped as they are optional.
-// Resolution order: OmniVault → VaultGuard → Environment variables
-func (sc *SecureConfig) loadSecureCredentials(ctx context.Context) {
-	// Load LLM API key if not set
-	if sc.LLMAPIKey == "" {
-		sc.LLMAPIKey = sc.getSecureValue(ctx, "LLM_API_KEY")
-	}
-
-	// Load provider-specific keys
-	if sc.GeminiAPIKey == "" {
-		sc.GeminiAPIKey = sc.getSecureValue(ctx, "GEMINI_API_KEY")
-		if sc.GeminiAPIKey == "" {
-			sc.GeminiAPIKey = sc.getSecureValue(ctx, "GOOGLE_API_KEY")
-		}
+	// Use VaultGuard's provider as the backend unless one is configured
+	secretsCfg := SecretsConfig{
+		Provider:      SecretsProvider(sv.Provider()),
+		CustomVault:   guardVault{sv},
+		FallbackToEnv: true,
 	}
-
-	if sc.ClaudeAPIKey == "" {
-		sc.ClaudeAPIKey = sc.getSecureValue(ctx, "CLAUDE_API_KEY")
-		if sc.ClaudeAPIKey == "" {
-			sc.ClaudeAPIKey = sc.getSecureValue(ctx, "ANTHROPIC_API_KEY")
-		}
-	}
-
-	if sc.OpenAIAPIKey == "" {
-		sc.OpenAIAPIKey = sc.getSecureValue(ctx, "OPENAI_API_KEY")
-	}
-
-	if sc.XAIAPIKey == "" {
-		sc.XAIAPIKey = sc.getSecureValue(ctx, "XAI_API_KEY")
-	}
-
-	// Load search API keys
-	if sc.SerperAPIKey == "" {
-		sc.SerperAPIKey = sc.getSecureValue(ctx, "SERPER_API_KEY")
+	if options.secretsConfig != nil {
+		secretsCfg = *options.secretsConfig
 	}
-
-	if sc.SerpAPIKey == "" {
-		sc.SerpAPIKey = sc.getSecureValue(ctx, "SERPAPI_API_KEY")
+	secrets, err := NewSecretsClient(secretsCfg)
+	if err != nil {
+		_ = sv.Close()
+		return nil, fmt.Errorf("creating secrets client: %w", err)
 	}
 
-	// Load observability API key
-	if sc.ObservabilityAPIKey == "" {
-		sc.ObservabilityAPIKey = sc.getSecureValue(ctx, "OBSERVABILITY_API_KEY")
-		if sc.ObservabilityAPIKey == "" {
-			sc.ObservabilityAPIKey = sc.getSecureValue(ctx, "OPIK_API_KEY")
-		}
+	cfg, err := Load(ctx, append(options.loadOptions, WithSecretsClient(secrets))...)
+	if err != nil {
+		_ = sv.Close()
+		return nil, err
 	}
 
-	// Update LLMAPIKey based on provider if still not set
-	if sc.LLMAPIKey == "" {
-		switch sc.LLMProvider {
-		case "gemini":
-			sc.LLMAPIKey = sc.GeminiAPIKey
-		case "claude":
-			sc.LLMAPIKey = sc.ClaudeAPIKey
-		case "openai":
-			sc.LLMAPIKey = sc.OpenAIAPIKey
-		case "xai":
-			sc.LLMAPIKey = sc.XAIAPIKey
-		}
-	}
+	return &SecureConfig{
+		Config: cfg,
+		vault:  sv,
+	}, nil
 }
 
-// getSecureValue retrieves a value from OmniVault first, then VaultGuard.
-func (sc *SecureConfig) getSecureValue(ctx context.Context, name string) string {
-	// Try OmniVault first if configured
-	if sc.secrets != nil {
-		if value, err := sc.secrets.Get(ctx, name); err == nil && value != "" {
-			return value
-		}
-	}
+// guardVault adapts a VaultGuard SecureVault to the OmniVault interface.
+type guardVault struct {
+	*vaultguard.SecureVault
+}
 
-	// Fall back to VaultGuard
-	if value, err := sc.GetCredential(ctx, name); err == nil && value != "" {
-		return value
-	}
+// Name returns the VaultGuard provider name.
+func (v guardVault) Name() string {
+	return "vaultguard:" + string(v.Provider())
+}
 
-	return ""
+// Capabilities reports the operations SecureVault supports.
+func (v guardVault) Capabilities() vault.Capabilities {
+	return vault.Capabilities{Read: true, Write: true, Delete: true, List: true}
 }
 
-// GetCredential retrieves a credential from the secure vault.
+// GetCredential retrieves a credential from the secrets backend.
 func (sc *SecureConfig) GetCredential(ctx context.Context, name string) (string, error) {
-	return sc.vault.GetValue(ctx, name)
+	return sc.GetSecret(ctx, name)
 }
 
 // GetRequiredCredentials retrieves multiple credentials, failing if any are missing.
 func (sc *SecureConfig) GetRequiredCredentials(ctx context.Context, names ...string) (map[string]string, error) {
 	result := make(map[string]string)
 	for _, name := range names {
-		value, err := sc.vault.GetValue(ctx, name)
+		value, err := sc.GetCredential(ctx, name)
 		if err != nil {
 			return nil, fmt.Errorf("required credential %s not found: %w", name, err)
 		}
@@ -173,23 +116,16 @@ func (sc *SecureConfig) SecurityResult() *vaultguard.SecurityResult {
 	return sc.vault.SecurityResult()
 }
 
-// Close cleans up resources.
+// Close releases the secrets client and the secure vault.
 func (sc *SecureConfig) Close() error {
 	var errs []error
-	if sc.secrets != nil {
-		if err := sc.secrets.Close(); err != nil {
-			errs = append(errs, err)
-		}
+	if sc.Config != nil {
+		errs = append(errs, sc.Config.Close())
 	}
 	if sc.vault != nil {
-		if err := sc.vault.Close(); err != nil {
-			errs = append(errs, err)
-		}
+		errs = append(errs, sc.vault.Close())
 	}
-	if len(errs) > 0 {
-		return errs[0]
-	}
-	return nil
+	return errors.Join(errs...)
 }
 
 // SecureConfigOption configures secure config loading.
@@ -198,6 +134,7 @@ type SecureConfigOption func(*secureConfigOptions)
 type secureConfigOptions struct {
 	policy        *vaultguard.Policy
 	secretsConfig *SecretsConfig
+	loadOptions   []LoadOption
 }
 
 // WithPolicy sets a custom security policy.
@@ -221,8 +158,17 @@ func WithStrictPolicy() SecureConfigOption {
 	}
 }
 
-// WithSecretsProvider configures OmniVault as the secrets provider.
-// When set, secrets are loaded from OmniVault first, with fallback to VaultGuard.
+// WithLoadOptions sets the options used to load configuration, such as
+// config files and flags. Secrets settings in them are ignored; use
+// WithSecretsProvider.
+func WithLoadOptions(opts ...LoadOption) SecureConfigOption {
+	return func(o *secureConfigOptions) {
+		o.loadOptions = append(o.loadOptions, opts...)
+	}
+}
+
+// WithSecretsProvider configures the OmniVault secrets backend. Without it,
+// VaultGuard's provider for the environment is used.
 func WithSecretsProvider(cfg SecretsConfig) SecureConfigOption {
 	return func(o *secureConfigOptions) {
 		o.secretsConfig = &cfg
@@ -242,8 +188,38 @@ func WithAWSSecretsManager(prefix, region string) SecureConfigOption {
 	}
 }
 
+// WithAssumeRole makes the AWS secrets provider assume roleARN, with an
+// optional externalID, on top of the workload's own identity. Use it with
+// WithAWSSecretsManager for cross-account secret access; without another
+// secrets option, the provider is auto-detected as in
+// WithAutoSecretsProvider.
+func WithAssumeRole(roleARN, externalID string) SecureConfigOption {
+	return func(o *secureConfigOptions) {
+		if o.secretsConfig == nil {
+			cfg := DefaultSecretsConfig()
+			o.secretsConfig = &cfg
+		}
+		o.secretsConfig.Identity.RoleARN = roleARN
+		o.secretsConfig.Identity.ExternalID = externalID
+	}
+}
+
+// WithGCPSecretManager configures Google Cloud Secret Manager as the secrets
+// provider. This is a convenience function for GCP deployments (Cloud Run).
+func WithGCPSecretManager(prefix, project string) SecureConfigOption {
+	return func(o *secureConfigOptions) {
+		o.secretsConfig = &SecretsConfig{
+			Provider:      SecretsProviderGCPSM,
+			Prefix:        prefix,
+			Project:       project,
+			FallbackToEnv: true,
+		}
+	}
+}
+
 // WithAutoSecretsProvider uses DefaultSecretsConfig to auto-detect the provider.
-// In AWS environments, this will use AWS Secrets Manager; otherwise, env vars.
+// In AWS environments, this will use AWS Secrets Manager; on Cloud Run, GCP
+// Secret Manager; otherwise, env vars.
 func WithAutoSecretsProvider() SecureConfigOption {
 	return func(o *secureConfigOptions) {
 		cfg := DefaultSecretsConfig()
@@ -0,0 +1,248 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Section names a part of Config that components can subscribe to.
+type Section string
+
+const (
+	// SectionLLM covers the LLM provider, model, endpoint and API keys.
+	SectionLLM Section = "llm"
+
+	// SectionSearch covers the search provider and API keys.
+	SectionSearch Section = "search"
+
+	// SectionAgents covers agent URLs and per-agent LLM overrides.
+	SectionAgents Section = "agents"
+
+	// SectionA2A covers A2A enablement and authentication.
+	SectionA2A Section = "a2a"
+
+	// SectionObservability covers the observability provider and API key.
+	SectionObservability Section = "observability"
+
+	// SectionSecurity covers VaultGuard security settings.
+	SectionSecurity Section = "security"
+
+	// SectionCrawl covers robots.txt and per-host limits of agent fetches.
+	SectionCrawl Section = "crawl"
+)
+
+// ValidSections returns all config sections.
+func ValidSections() []Section {
+	return []Section{
+		SectionLLM,
+		SectionSearch,
+		SectionAgents,
+		SectionA2A,
+		SectionObservability,
+		SectionSecurity,
+		SectionCrawl,
+	}
+}
+
+// sectionValue returns the fields of cfg that belong to section.
+func sectionValue(cfg *Config, section Section) interface{} {
+	switch section {
+	case SectionLLM:
+		return []interface{}{cfg.LLMProvider, cfg.LLMAPIKey, cfg.LLMModel, cfg.LLMBaseURL,
+			cfg.LLMRequestsPerMinute, cfg.LLMTokensPerMinute,
+			cfg.GeminiAPIKey, cfg.ClaudeAPIKey, cfg.OpenAIAPIKey, cfg.XAIAPIKey, cfg.OllamaURL,
+			cfg.BedrockAPIKey, cfg.BedrockRegion, cfg.AzureOpenAIAPIKey, cfg.AzureOpenAIEndpoint, cfg.GroqAPIKey, cfg.MistralAPIKey}
+	case SectionSearch:
+		return []interface{}{cfg.SearchProvider, cfg.SerperAPIKey, cfg.SerpAPIKey}
+	case SectionAgents:
+		return []interface{}{cfg.AgentURLs, cfg.AgentLLM}
+	case SectionA2A:
+		return []interface{}{cfg.A2AEnabled, cfg.A2AAuthType, cfg.A2AAuthToken}
+	case SectionObservability:
+		return []interface{}{cfg.ObservabilityEnabled, cfg.ObservabilityProvider, cfg.ObservabilityAPIKey,
+			cfg.ObservabilityEndpoint, cfg.ObservabilityProject}
+	case SectionSecurity:
+		return []interface{}{cfg.SecurityEnabled, cfg.SecurityMinScore, cfg.SecurityRequireEncry}
+	case SectionCrawl:
+		return []interface{}{cfg.CrawlIgnoreRobots, cfg.CrawlRateLimit, cfg.CrawlMaxConcurrency}
+	default:
+		return nil
+	}
+}
+
+// ChangedSections returns the sections that differ between old and new.
+func ChangedSections(old, new *Config) []Section {
+	var changed []Section
+	for _, section := range ValidSections() {
+		if !reflect.DeepEqual(sectionValue(old, section), sectionValue(new, section)) {
+			changed = append(changed, section)
+		}
+	}
+	return changed
+}
+
+// ChangeFunc prepares a component for a config change. It should build
+// whatever the new config needs (a model factory, an auth validator)
+// without applying it, and return a commit function that applies it.
+// Commit functions must not fail: if any subscriber returns an error, the
+// reload is abandoned and no commit function is called.
+type ChangeFunc func(ctx context.Context, old, new *Config) (commit func(), err error)
+
+// Swap returns a ChangeFunc that rebuilds a component with build and
+// stores it in p when the config changes. The previous component is
+// closed after the swap if it implements io.Closer.
+//
+//	var factory atomic.Pointer[llm.ModelFactory]
+//	factory.Store(llm.NewModelFactory(store.Current()))
+//	store.Subscribe(config.Swap(&factory, func(ctx context.Context, cfg *config.Config) (*llm.ModelFactory, error) {
+//	    return llm.NewModelFactory(cfg), nil
+//	}), config.SectionLLM, config.SectionObservability)
+func Swap[T any](p *atomic.Pointer[T], build func(ctx context.Context, cfg *Config) (*T, error)) ChangeFunc {
+	return func(ctx context.Context, old, new *Config) (func(), error) {
+		next, err := build(ctx, new)
+		if err != nil {
+			return nil, err
+		}
+		return func() {
+			prev := p.Swap(next)
+			if closer, ok := any(prev).(io.Closer); ok && prev != nil {
+				_ = closer.Close()
+			}
+		}, nil
+	}
+}
+
+// LoadFunc loads a complete configuration.
+type LoadFunc func(ctx context.Context) (*Config, error)
+
+// subscription is a registered ChangeFunc.
+type subscription struct {
+	fn       ChangeFunc
+	sections []Section
+}
+
+// Store holds the current configuration of a long-lived process and
+// notifies subscribed components when a reload changes the sections they
+// depend on. A Store is safe for concurrent use.
+type Store struct {
+	load    LoadFunc
+	current atomic.Pointer[Config]
+
+	// reloadMu serializes reloads.
+	reloadMu sync.Mutex
+
+	mu     sync.Mutex
+	subs   map[int]subscription
+	nextID int
+}
+
+// NewStore creates a Store, loading the initial configuration with load.
+func NewStore(ctx context.Context, load LoadFunc) (*Store, error) {
+	cfg, err := load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s := &Store{
+		load: load,
+		subs: make(map[int]subscription),
+	}
+	s.current.Store(cfg)
+	return s, nil
+}
+
+// LoadStore creates a Store that loads configuration with Load and opts.
+func LoadStore(ctx context.Context, opts LoadOptions) (*Store, error) {
+	return NewStore(ctx, func(ctx context.Context) (*Config, error) {
+		return Load(ctx, opts)
+	})
+}
+
+// Current returns the current configuration. Callers should not keep it
+// across reloads; the previous configuration is closed after a reload.
+func (s *Store) Current() *Config {
+	return s.current.Load()
+}
+
+// Subscribe registers fn to be called when a reload changes any of
+// sections, or any section if none are given. It returns a function that
+// removes the subscription.
+func (s *Store) Subscribe(fn ChangeFunc, sections ...Section) (unsubscribe func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := s.nextID
+	s.nextID++
+	s.subs[id] = subscription{fn: fn, sections: sections}
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.subs, id)
+	}
+}
+
+// Reload loads the configuration again and applies it atomically: every
+// subscriber whose sections changed prepares for the new configuration,
+// and only if all succeed are their changes committed and the new
+// configuration made current. On error the current configuration stays
+// in place. Reload returns the sections that changed.
+func (s *Store) Reload(ctx context.Context) ([]Section, error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	next, err := s.load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("reloading config: %w", err)
+	}
+	old := s.current.Load()
+	changed := ChangedSections(old, next)
+	if len(changed) == 0 {
+		// Keep the current config; the new one is identical.
+		_ = next.Close()
+		return nil, nil
+	}
+
+	s.mu.Lock()
+	subs := make([]subscription, 0, len(s.subs))
+	for _, sub := range s.subs {
+		if len(sub.sections) == 0 || slices.ContainsFunc(sub.sections, func(section Section) bool {
+			return slices.Contains(changed, section)
+		}) {
+			subs = append(subs, sub)
+		}
+	}
+	s.mu.Unlock()
+
+	commits := make([]func(), 0, len(subs))
+	var errs []error
+	for _, sub := range subs {
+		commit, err := sub.fn(ctx, old, next)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if commit != nil {
+			commits = append(commits, commit)
+		}
+	}
+	if len(errs) > 0 {
+		_ = next.Close()
+		return nil, fmt.Errorf("reloading config: %w", errors.Join(errs...))
+	}
+
+	for _, commit := range commits {
+		commit()
+	}
+	s.current.Store(next)
+	_ = old.Close()
+	return changed, nil
+}
+
+// Close closes the current configuration.
+func (s *Store) Close() error {
+	return s.current.Load().Close()
+}
@@ -0,0 +1,252 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// fileValidator validates ConfigFile values against their validate tags,
+// reporting fields by their config file names.
+var fileValidator = newFileValidator()
+
+// newFileValidator creates a validator that names fields by their json tag.
+func newFileValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return v
+}
+
+// ValidateConfigFile checks the config file at path and returns every
+// problem found: parse errors, unknown fields (such as a misspelled
+// "observabilty") and invalid values. It returns nil if the file is valid.
+func ValidateConfigFile(path string) []error {
+	data, err := readConfigData(path)
+	if err != nil {
+		return []error{err}
+	}
+	_, errs := parseConfigFileStrict(data, path)
+	return errs
+}
+
+// LoadConfigFileStrict is like LoadConfigFile, but fails if the file has
+// unknown fields or invalid values. The returned error lists every problem.
+func LoadConfigFileStrict(path string, projectName string) (*ConfigFile, error) {
+	configPath := path
+	if configPath == "" {
+		var err error
+		configPath, err = findConfigFile(projectName)
+		if err != nil {
+			// Return empty config if no file found (use defaults)
+			return &ConfigFile{}, nil
+		}
+	}
+
+	data, err := readConfigData(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, errs := parseConfigFileStrict(data, configPath)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid config file %s: %w", configPath, errors.Join(errs...))
+	}
+	return cfg, nil
+}
+
+// parseConfigFileStrict decodes config file data and returns the config
+// with every unknown field and invalid value found.
+func parseConfigFileStrict(data []byte, path string) (*ConfigFile, []error) {
+	cfg, raw, err := parseConfigFile(data, path)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	errs := unknownFields(raw, reflect.TypeOf(*cfg), "")
+	errs = append(errs, cfg.Validate()...)
+	return cfg, errs
+}
+
+// Validate checks the config values and returns every problem found:
+// unsupported providers, malformed URLs, out-of-range ports and scores.
+// Empty values are allowed; Defaults fills them in.
+func (c *ConfigFile) Validate() []error {
+	var errs []error
+
+	if err := fileValidator.Struct(c); err != nil {
+		var validationErrs validator.ValidationErrors
+		if !errors.As(err, &validationErrs) {
+			return []error{err}
+		}
+		for _, e := range validationErrs {
+			errs = append(errs, fmt.Errorf("%s: %s", fieldPath(e.Namespace()), validationMessage(e)))
+		}
+	}
+
+	// The url validator accepts any port number.
+	urls := map[string]string{
+		"llm.baseUrl":            c.LLM.BaseURL,
+		"observability.endpoint": c.Observability.Endpoint,
+	}
+	for name, agent := range c.Agents {
+		urls[fmt.Sprintf("agents[%s].url", name)] = agent.URL
+	}
+	for _, field := range sortedKeys(urls) {
+		if err := validatePort(urls[field]); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", field, err))
+		}
+	}
+
+	if err := c.Secrets.Identity.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("secrets.identity: %w", err))
+	}
+
+	return errs
+}
+
+// fieldPath strips the struct name from a validator field namespace.
+func fieldPath(namespace string) string {
+	_, path, _ := strings.Cut(namespace, ".")
+	return path
+}
+
+// validationMessage describes a failed validation rule.
+func validationMessage(e validator.FieldError) string {
+	switch e.Tag() {
+	case "required":
+		return "is required"
+	case "oneof":
+		return fmt.Sprintf("must be one of %s (got %q)", strings.ReplaceAll(e.Param(), " ", ", "), e.Value())
+	case "url":
+		return fmt.Sprintf("must be a valid URL (got %q)", e.Value())
+	case "min":
+		return fmt.Sprintf("must be at least %s", e.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s", e.Param())
+	default:
+		return fmt.Sprintf("failed validation: %s", e.Tag())
+	}
+}
+
+// validatePort checks that the port of rawURL, if any, is in 1-65535.
+func validatePort(rawURL string) error {
+	if rawURL == "" {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Port() == "" {
+		return nil
+	}
+	port, err := strconv.Atoi(u.Port())
+	if err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("port must be between 1 and 65535 (got %s)", u.Port())
+	}
+	return nil
+}
+
+// unknownFields returns an error for each key in raw that does not match a
+// field of t, recursing into nested objects.
+func unknownFields(raw interface{}, t reflect.Type, path string) []error {
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		// Type mismatches are reported by the decoder.
+		return nil
+	}
+
+	var errs []error
+	switch t.Kind() {
+	case reflect.Struct:
+		fields := make(map[string]reflect.Type)
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name != "" && name != "-" {
+				fields[name] = f.Type
+			}
+		}
+		for _, key := range sortedKeys(m) {
+			field := joinFieldPath(path, key)
+			ft, ok := fields[key]
+			if !ok {
+				if suggestion := closestField(key, fields); suggestion != "" {
+					errs = append(errs, fmt.Errorf("%s: unknown field (did you mean %q?)", field, suggestion))
+				} else {
+					errs = append(errs, fmt.Errorf("%s: unknown field", field))
+				}
+				continue
+			}
+			errs = append(errs, unknownFields(m[key], ft, field)...)
+		}
+	case reflect.Map:
+		for _, key := range sortedKeys(m) {
+			errs = append(errs, unknownFields(m[key], t.Elem(), fmt.Sprintf("%s[%s]", path, key))...)
+		}
+	}
+	return errs
+}
+
+// joinFieldPath appends key to a dotted field path.
+func joinFieldPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// closestField returns the field name closest to key, or an empty string
+// if none is within two edits.
+func closestField(key string, fields map[string]reflect.Type) string {
+	best, bestDistance := "", 3
+	for _, name := range sortedKeys(fields) {
+		if strings.EqualFold(name, key) {
+			return name
+		}
+		if d := editDistance(strings.ToLower(key), strings.ToLower(name)); d < bestDistance {
+			best, bestDistance = name, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,314 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// CredentialStatus is the outcome of verifying one provider's credentials.
+type CredentialStatus string
+
+const (
+	// CredentialOK means the provider accepted the credentials.
+	CredentialOK CredentialStatus = "ok"
+
+	// CredentialMissing means no credentials are configured.
+	CredentialMissing CredentialStatus = "missing"
+
+	// CredentialInvalid means the provider rejected the credentials.
+	CredentialInvalid CredentialStatus = "invalid"
+
+	// CredentialUnreachable means the provider could not be reached or
+	// returned an unexpected response.
+	CredentialUnreachable CredentialStatus = "unreachable"
+
+	// CredentialSkipped means the provider has no cheap verification call.
+	CredentialSkipped CredentialStatus = "skipped"
+)
+
+// CredentialCheck is the result of verifying one provider.
+type CredentialCheck struct {
+	// Kind is "llm", "search" or "observability".
+	Kind string
+
+	// Provider is the provider name, e.g. "claude".
+	Provider string
+
+	Status CredentialStatus
+
+	// Detail explains a status other than ok.
+	Detail string
+
+	// Latency is the duration of the verification call.
+	Latency time.Duration
+}
+
+// Failed reports whether the check found a problem.
+func (c CredentialCheck) Failed() bool {
+	return c.Status != CredentialOK && c.Status != CredentialSkipped
+}
+
+// String formats the check, e.g. "llm claude: invalid (HTTP 401
+// Unauthorized)".
+func (c CredentialCheck) String() string {
+	s := fmt.Sprintf("%s %s: %s", c.Kind, c.Provider, c.Status)
+	if c.Detail != "" {
+		s += " (" + c.Detail + ")"
+	}
+	return s
+}
+
+// CredentialReport is the result of VerifyCredentials.
+type CredentialReport struct {
+	Checks []CredentialCheck
+}
+
+// OK reports whether every verified provider accepted its credentials.
+func (r *CredentialReport) OK() bool {
+	return r.Err() == nil
+}
+
+// Err returns an error listing the failed checks, or nil if there were
+// none.
+func (r *CredentialReport) Err() error {
+	var errs []error
+	for _, c := range r.Checks {
+		if c.Failed() {
+			errs = append(errs, errors.New(c.String()))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("credential verification failed: %w", errors.Join(errs...))
+}
+
+// String formats the report, one check per line.
+func (r *CredentialReport) String() string {
+	lines := make([]string, len(r.Checks))
+	for i, c := range r.Checks {
+		lines[i] = c.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// verifyTimeout bounds each verification call.
+const verifyTimeout = 10 * time.Second
+
+// verifyClient sends verification requests.
+var verifyClient = &http.Client{Timeout: verifyTimeout}
+
+// VerifyCredentials makes a cheap authenticated call (such as listing
+// models) to the configured LLM provider, each per-agent LLM provider,
+// the search provider and the observability provider, and reports the
+// result for each. Call it at startup so misconfigured keys fail fast
+// instead of mid-workflow:
+//
+//	if err := cfg.VerifyCredentials(ctx).Err(); err != nil {
+//	    log.Fatal(err)
+//	}
+//
+// Verification makes network calls and is optional. Providers without a
+// free verification call are reported as skipped.
+func (c *Config) VerifyCredentials(ctx context.Context) *CredentialReport {
+	report := &CredentialReport{}
+
+	providers := []string{c.LLMProvider}
+	for _, name := range sortedKeys(c.AgentLLM) {
+		if p := c.ResolveAgentLLM(name).Provider; !slices.Contains(providers, p) {
+			providers = append(providers, p)
+		}
+	}
+	for _, provider := range providers {
+		report.Checks = append(report.Checks, c.verifyLLM(ctx, provider))
+	}
+	if c.SearchProvider != "" {
+		report.Checks = append(report.Checks, c.verifySearch(ctx))
+	}
+	if c.ObservabilityEnabled {
+		report.Checks = append(report.Checks, c.verifyObservability(ctx))
+	}
+	return report
+}
+
+// llmAPIKey returns the API key for an LLM provider.
+func (c *Config) llmAPIKey(provider string) string {
+	key := map[string]string{
+		"gemini":  c.GeminiAPIKey,
+		"claude":  c.ClaudeAPIKey,
+		"openai":  c.OpenAIAPIKey,
+		"xai":     c.XAIAPIKey,
+		"bedrock": c.BedrockAPIKey,
+		"azure":   c.AzureOpenAIAPIKey,
+		"groq":    c.GroqAPIKey,
+		"mistral": c.MistralAPIKey,
+	}[provider]
+	if key == "" && provider == c.LLMProvider {
+		key = c.LLMAPIKey
+	}
+	return key
+}
+
+// verifyLLM lists the models of an LLM provider.
+func (c *Config) verifyLLM(ctx context.Context, provider string) CredentialCheck {
+	check := CredentialCheck{Kind: "llm", Provider: provider}
+	if provider == "ollama" {
+		base := c.OllamaURL
+		if provider == c.LLMProvider && c.LLMBaseURL != "" {
+			base = c.LLMBaseURL
+		}
+		return verifyRequest(ctx, check, http.MethodGet, strings.TrimSuffix(base, "/")+"/api/tags", nil)
+	}
+
+	key := c.llmAPIKey(provider)
+	if key == "" {
+		check.Status = CredentialMissing
+		check.Detail = "no API key"
+		return check
+	}
+	switch provider {
+	case "gemini", "":
+		check.Provider = "gemini"
+		return verifyRequest(ctx, check, http.MethodGet,
+			"https://generativelanguage.googleapis.com/v1beta/models?pageSize=1",
+			map[string]string{"x-goog-api-key": key})
+	case "claude":
+		return verifyRequest(ctx, check, http.MethodGet, "https://api.anthropic.com/v1/models?limit=1",
+			map[string]string{"x-api-key": key, "anthropic-version": "2023-06-01"})
+	case "openai":
+		return verifyRequest(ctx, check, http.MethodGet, "https://api.openai.com/v1/models",
+			map[string]string{"Authorization": "Bearer " + key})
+	case "xai":
+		return verifyRequest(ctx, check, http.MethodGet, "https://api.x.ai/v1/models",
+			map[string]string{"Authorization": "Bearer " + key})
+	case "groq":
+		return verifyRequest(ctx, check, http.MethodGet, "https://api.groq.com/openai/v1/models",
+			map[string]string{"Authorization": "Bearer " + key})
+	case "mistral":
+		return verifyRequest(ctx, check, http.MethodGet, "https://api.mistral.ai/v1/models",
+			map[string]string{"Authorization": "Bearer " + key})
+	case "azure":
+		if c.AzureOpenAIEndpoint == "" {
+			check.Status = CredentialMissing
+			check.Detail = "no endpoint"
+			return check
+		}
+		return verifyRequest(ctx, check, http.MethodGet, strings.TrimSuffix(c.AzureOpenAIEndpoint, "/")+"/openai/v1/models",
+			map[string]string{"api-key": key})
+	case "bedrock":
+		// Bedrock API keys cannot list models, and a test call would be
+		// billed.
+		check.Status = CredentialSkipped
+		check.Detail = "API key set, not verified"
+		return check
+	default:
+		check.Status = CredentialSkipped
+		check.Detail = "unsupported provider"
+		return check
+	}
+}
+
+// verifySearch checks the search provider's API key.
+func (c *Config) verifySearch(ctx context.Context) CredentialCheck {
+	check := CredentialCheck{Kind: "search", Provider: c.SearchProvider}
+	switch c.SearchProvider {
+	case "serpapi":
+		if c.SerpAPIKey == "" {
+			check.Status = CredentialMissing
+			check.Detail = "no API key"
+			return check
+		}
+		// The account API does not use search credits.
+		return verifyRequest(ctx, check, http.MethodGet,
+			"https://serpapi.com/account.json?api_key="+url.QueryEscape(c.SerpAPIKey), nil)
+	case "serper":
+		if c.SerperAPIKey == "" {
+			check.Status = CredentialMissing
+			check.Detail = "no API key"
+			return check
+		}
+		// Every Serper call uses search credits.
+		check.Status = CredentialSkipped
+		check.Detail = "no free verification call"
+		return check
+	default:
+		check.Status = CredentialSkipped
+		check.Detail = "unsupported provider"
+		return check
+	}
+}
+
+// verifyObservability checks the observability provider's API key.
+func (c *Config) verifyObservability(ctx context.Context) CredentialCheck {
+	check := CredentialCheck{Kind: "observability", Provider: c.ObservabilityProvider}
+	switch c.ObservabilityProvider {
+	case "opik":
+		if c.ObservabilityAPIKey == "" {
+			check.Status = CredentialMissing
+			check.Detail = "no API key"
+			return check
+		}
+		endpoint := c.ObservabilityEndpoint
+		if endpoint == "" {
+			endpoint = "https://www.comet.com/opik/api"
+		}
+		return verifyRequest(ctx, check, http.MethodGet,
+			strings.TrimSuffix(endpoint, "/")+"/v1/private/projects?size=1",
+			map[string]string{"Authorization": c.ObservabilityAPIKey})
+	default:
+		check.Status = CredentialSkipped
+		check.Detail = "no verification call"
+		return check
+	}
+}
+
+// verifyRequest sends a verification request and sets the check status
+// from the response.
+func verifyRequest(ctx context.Context, check CredentialCheck, method, rawURL string, headers map[string]string) CredentialCheck {
+	ctx, cancel := context.WithTimeout(ctx, verifyTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		check.Status = CredentialUnreachable
+		check.Detail = err.Error()
+		return check
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	start := time.Now()
+	resp, err := verifyClient.Do(req)
+	check.Latency = time.Since(start)
+	if err != nil {
+		check.Status = CredentialUnreachable
+		// Errors include the URL, which may hold an API key.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		check.Detail = err.Error()
+		return check
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode < 300:
+		check.Status = CredentialOK
+	case resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		// Gemini reports invalid keys as 400.
+		check.Status = CredentialInvalid
+		check.Detail = "HTTP " + resp.Status
+	default:
+		check.Status = CredentialUnreachable
+		check.Detail = "HTTP " + resp.Status
+	}
+	return check
+}
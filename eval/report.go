@@ -0,0 +1,164 @@
+package eval
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/plexusone/agentkit/llm/stream"
+)
+
+// Report is the result of running a suite.
+type Report struct {
+	Suite     string        `json:"suite"`
+	Target    string        `json:"target"`
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration_ns"`
+
+	// Total counts the cases. Passed counts those whose assertions
+	// passed, Failed those with a failed assertion, and Errors those the
+	// target failed to run.
+	Total  int `json:"total"`
+	Passed int `json:"passed"`
+	Failed int `json:"failed"`
+	Errors int `json:"errors"`
+
+	// CostUSD is the cost of the runs of the target that report usage.
+	CostUSD float64 `json:"cost_usd"`
+
+	Cases []CaseResult `json:"cases"`
+}
+
+// CaseResult is the result of a case.
+type CaseResult struct {
+	Name     string        `json:"name"`
+	Input    string        `json:"input"`
+	Output   string        `json:"output,omitempty"`
+	Passed   bool          `json:"passed"`
+	Duration time.Duration `json:"duration_ns"`
+
+	// Error is why the target failed to run, if it did.
+	Error string `json:"error,omitempty"`
+
+	Usage      *stream.Usage     `json:"usage,omitempty"`
+	Assertions []AssertionResult `json:"assertions,omitempty"`
+}
+
+// AssertionResult is the result of an assertion.
+type AssertionResult struct {
+	Type   AssertionType `json:"type"`
+	Passed bool          `json:"passed"`
+
+	// Score is the judge score of judge assertions.
+	Score *float64 `json:"score,omitempty"`
+
+	// Message explains a failure, or the score of a judge assertion.
+	Message string `json:"message,omitempty"`
+}
+
+// OK reports whether every case passed.
+func (r *Report) OK() bool {
+	return r.Passed == r.Total
+}
+
+// Summary returns a one-line summary of r, e.g. for logs.
+func (r *Report) Summary() string {
+	return fmt.Sprintf("%s: %d/%d passed, %d failed, %d errors in %s ($%.4f)",
+		r.Suite, r.Passed, r.Total, r.Failed, r.Errors, r.Duration.Round(time.Millisecond), r.CostUSD)
+}
+
+// WriteJSON writes r as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// junitTestSuites is the root of a JUnit XML report.
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes r as a JUnit XML report, with a test suite named
+// after the suite and a test case per case. Failed assertions are
+// failures, and cases the target failed to run are errors.
+func (r *Report) WriteJUnit(w io.Writer) error {
+	suite := junitTestSuite{
+		Name:      r.Suite,
+		Tests:     r.Total,
+		Failures:  r.Failed,
+		Errors:    r.Errors,
+		Time:      seconds(r.Duration),
+		Timestamp: r.StartedAt.UTC().Format("2006-01-02T15:04:05"),
+	}
+	for _, c := range r.Cases {
+		tc := junitTestCase{
+			Name:      c.Name,
+			ClassName: r.Suite + "." + r.Target,
+			Time:      seconds(c.Duration),
+			SystemOut: c.Output,
+		}
+		switch {
+		case c.Error != "":
+			tc.Error = &junitMessage{Message: c.Error, Text: c.Error}
+		case !c.Passed:
+			var failed []string
+			for _, a := range c.Assertions {
+				if !a.Passed {
+					failed = append(failed, fmt.Sprintf("%s: %s", a.Type, a.Message))
+				}
+			}
+			tc.Failure = &junitMessage{Message: failed[0], Text: strings.Join(failed, "\n")}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// seconds formats d in seconds, as JUnit reports times.
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,187 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/adk/model"
+
+	"github.com/plexusone/agentkit/llm"
+)
+
+// Options configures Run.
+type Options struct {
+	// Judge is the model that scores the output of judge assertions.
+	// Default: nil (judge assertions fail)
+	Judge model.LLM
+
+	// Parallelism is the most cases run at once.
+	// Default: 4
+	Parallelism int
+
+	// Timeout bounds each case, including its judge calls.
+	// Default: 2m
+	Timeout time.Duration
+}
+
+// withDefaults returns o with zero fields set to their defaults.
+func (o Options) withDefaults() Options {
+	if o.Parallelism <= 0 {
+		o.Parallelism = 4
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = 2 * time.Minute
+	}
+	return o
+}
+
+// Run runs the cases of suite against target and checks their output. A
+// case passes if the target returns output and every assertion passes.
+// An invalid suite fails every case with the validation error.
+func Run(ctx context.Context, target Target, suite *Suite, opts Options) *Report {
+	opts = opts.withDefaults()
+	report := &Report{Suite: suite.Name, Target: target.Name(), StartedAt: time.Now()}
+	invalid := suite.Validate()
+
+	results := make([]CaseResult, len(suite.Cases))
+	sem := make(chan struct{}, opts.Parallelism)
+	var wg sync.WaitGroup
+	for i, c := range suite.Cases {
+		if invalid != nil {
+			results[i] = CaseResult{Name: c.Name, Input: c.Input, Error: invalid.Error()}
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+				results[i] = runCase(ctx, target, c, opts)
+			case <-ctx.Done():
+				results[i] = CaseResult{Name: c.Name, Input: c.Input, Error: ctx.Err().Error()}
+			}
+		}()
+	}
+	wg.Wait()
+
+	report.Cases = results
+	report.Duration = time.Since(report.StartedAt)
+	for _, r := range results {
+		report.Total++
+		switch {
+		case r.Passed:
+			report.Passed++
+		case r.Error != "":
+			report.Errors++
+		default:
+			report.Failed++
+		}
+		if r.Usage != nil {
+			report.CostUSD += r.Usage.CostUSD
+		}
+	}
+	return report
+}
+
+// runCase runs a case and checks its assertions.
+func runCase(ctx context.Context, target Target, c Case, opts Options) CaseResult {
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	result := CaseResult{Name: c.Name, Input: c.Input}
+
+	out, err := target.Run(ctx, c.Input, c.Metadata)
+	if err != nil {
+		result.Error = err.Error()
+		result.Duration = time.Since(start)
+		return result
+	}
+	result.Output = out.Text
+	result.Usage = out.Usage
+
+	result.Passed = true
+	for _, a := range c.Assertions {
+		ar := check(ctx, a, c.Input, out.Text, opts.Judge)
+		result.Passed = result.Passed && ar.Passed
+		result.Assertions = append(result.Assertions, ar)
+	}
+	result.Duration = time.Since(start)
+	return result
+}
+
+// check checks output against a.
+func check(ctx context.Context, a Assertion, input, output string, judge model.LLM) AssertionResult {
+	r := AssertionResult{Type: a.Type}
+	contains := func() bool {
+		if a.IgnoreCase {
+			return strings.Contains(strings.ToLower(output), strings.ToLower(a.Value))
+		}
+		return strings.Contains(output, a.Value)
+	}
+	switch a.Type {
+	case AssertContains:
+		r.Passed = contains()
+		if !r.Passed {
+			r.Message = fmt.Sprintf("output does not contain %q", a.Value)
+		}
+	case AssertNotContains:
+		r.Passed = !contains()
+		if !r.Passed {
+			r.Message = fmt.Sprintf("output contains %q", a.Value)
+		}
+	case AssertRegex:
+		r.Passed = a.re.MatchString(output)
+		if !r.Passed {
+			r.Message = fmt.Sprintf("output does not match %q", a.Value)
+		}
+	case AssertJSONSchema:
+		if err := llm.ValidateJSON(output, a.Schema); err != nil {
+			r.Message = err.Error()
+		} else {
+			r.Passed = true
+		}
+	case AssertJudge:
+		r = judgeOutput(ctx, a, input, output, judge)
+	}
+	return r
+}
+
+// verdict is the response of a judge.
+type verdict struct {
+	Score  float64 `json:"score" description:"how well the response meets the criteria, from 0 (not at all) to 1 (fully)"`
+	Reason string  `json:"reason" description:"a one-sentence justification of the score"`
+}
+
+// judgeOutput has judge score output against the criteria of a.
+func judgeOutput(ctx context.Context, a Assertion, input, output string, judge model.LLM) AssertionResult {
+	r := AssertionResult{Type: a.Type}
+	if judge == nil {
+		r.Message = "no judge model configured (Options.Judge)"
+		return r
+	}
+	minScore := a.MinScore
+	if minScore == 0 {
+		minScore = 0.7
+	}
+
+	prompt := "You are evaluating the response of an AI agent against criteria. " +
+		"Judge only whether the response meets the criteria.\n\n" +
+		"Criteria:\n" + a.Criteria + "\n\n" +
+		"Input to the agent:\n" + input + "\n\n" +
+		"Response of the agent:\n" + output
+	v, err := llm.GenerateStructured[verdict](ctx, judge, prompt, nil)
+	if err != nil {
+		r.Message = fmt.Sprintf("judge failed: %v", err)
+		return r
+	}
+	score := min(max(v.Score, 0), 1)
+	r.Score = &score
+	r.Passed = score >= minScore
+	r.Message = fmt.Sprintf("score %.2f (min %.2f): %s", score, minScore, v.Reason)
+	return r
+}
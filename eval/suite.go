@@ -0,0 +1,207 @@
+// Package eval runs agents against suites of test cases and checks their
+// output with assertions, for regression testing of prompt and workflow
+// changes in CI.
+//
+// A Suite is defined in YAML:
+//
+//	name: research
+//	cases:
+//	  - name: capital
+//	    input: What is the capital of France?
+//	    assert:
+//	      - type: contains
+//	        value: Paris
+//	        ignoreCase: true
+//	      - type: judge
+//	        criteria: Answers in one short sentence.
+//
+// Run runs the cases of a suite against a Target, an EmbeddedAgent or
+// agentcore.Agent, in parallel, and returns a Report that can be written
+// as JSON or as a JUnit XML report for CI systems:
+//
+//	suite, err := eval.LoadSuite("evals/research.yaml")
+//	report := eval.Run(ctx, eval.Embedded(agent), suite, eval.Options{Judge: judgeModel})
+//	_ = report.WriteJUnit(f)
+//	if !report.OK() {
+//	    os.Exit(1)
+//	}
+package eval
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AssertionType is the kind of check an Assertion makes.
+type AssertionType string
+
+const (
+	// AssertContains checks that the output contains Value.
+	AssertContains AssertionType = "contains"
+
+	// AssertNotContains checks that the output does not contain Value.
+	AssertNotContains AssertionType = "not_contains"
+
+	// AssertRegex checks that the output matches the regular expression
+	// Value.
+	AssertRegex AssertionType = "regex"
+
+	// AssertJSONSchema checks that the output holds a JSON value matching
+	// Schema, see llm.ValidateJSON.
+	AssertJSONSchema AssertionType = "json_schema"
+
+	// AssertJudge has a model score the output against Criteria, see
+	// Options.Judge.
+	AssertJudge AssertionType = "judge"
+)
+
+// Suite is a named set of test cases.
+type Suite struct {
+	// Name names the suite in reports.
+	Name string `json:"name" yaml:"name"`
+
+	// Cases are the test cases, run in parallel.
+	Cases []Case `json:"cases" yaml:"cases"`
+}
+
+// Case is an input to an agent and the assertions its output must pass.
+type Case struct {
+	// Name names the case in reports. It must be unique in the suite.
+	// Default: "case-N", N being its position from 1
+	Name string `json:"name" yaml:"name"`
+
+	// Input is the prompt sent to the agent.
+	Input string `json:"input" yaml:"input"`
+
+	// Metadata is sent to agentcore.Agent targets as request metadata.
+	Metadata map[string]string `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+
+	// Assertions are the checks of the output. A case without assertions
+	// passes if the agent does not fail.
+	Assertions []Assertion `json:"assert" yaml:"assert"`
+}
+
+// Assertion is a check of the output of a case.
+type Assertion struct {
+	// Type is the kind of check.
+	Type AssertionType `json:"type" yaml:"type"`
+
+	// Value is the text of contains and not_contains assertions, and the
+	// regular expression of regex assertions.
+	Value string `json:"value,omitempty" yaml:"value,omitempty"`
+
+	// IgnoreCase makes contains, not_contains and regex assertions case
+	// insensitive.
+	IgnoreCase bool `json:"ignoreCase,omitempty" yaml:"ignoreCase,omitempty"`
+
+	// Schema is the JSON Schema of json_schema assertions.
+	Schema any `json:"schema,omitempty" yaml:"schema,omitempty"`
+
+	// Criteria describes what the judge of a judge assertion looks for.
+	Criteria string `json:"criteria,omitempty" yaml:"criteria,omitempty"`
+
+	// MinScore is the lowest judge score, from 0 to 1, that passes a judge
+	// assertion.
+	// Default: 0.7
+	MinScore float64 `json:"minScore,omitempty" yaml:"minScore,omitempty"`
+
+	re *regexp.Regexp // compiled Value of regex assertions
+}
+
+// LoadSuite reads a suite from a YAML file.
+func LoadSuite(path string) (*Suite, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path provided by SDK user
+	if err != nil {
+		return nil, fmt.Errorf("failed to read suite: %w", err)
+	}
+	suite, err := ParseSuite(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return suite, nil
+}
+
+// ParseSuite parses and validates a suite in YAML, or JSON. Unknown fields
+// are rejected, so that misspelled assertions do not pass silently.
+func ParseSuite(data []byte) (*Suite, error) {
+	var suite Suite
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&suite); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid suite: %w", err)
+	}
+	if err := suite.Validate(); err != nil {
+		return nil, err
+	}
+	return &suite, nil
+}
+
+// Validate checks the cases and assertions of s, naming unnamed cases and
+// compiling regular expressions. Run validates suites it is given.
+func (s *Suite) Validate() error {
+	if len(s.Cases) == 0 {
+		return errors.New("suite has no cases")
+	}
+	names := make(map[string]bool, len(s.Cases))
+	var errs []error
+	for i := range s.Cases {
+		c := &s.Cases[i]
+		if c.Name == "" {
+			c.Name = fmt.Sprintf("case-%d", i+1)
+		}
+		if names[c.Name] {
+			errs = append(errs, fmt.Errorf("case %s: duplicate name", c.Name))
+		}
+		names[c.Name] = true
+		if strings.TrimSpace(c.Input) == "" {
+			errs = append(errs, fmt.Errorf("case %s: input is empty", c.Name))
+		}
+		for j := range c.Assertions {
+			if err := c.Assertions[j].validate(); err != nil {
+				errs = append(errs, fmt.Errorf("case %s: assertion %d: %w", c.Name, j+1, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validate checks that a has the fields its type needs.
+func (a *Assertion) validate() error {
+	switch a.Type {
+	case AssertContains, AssertNotContains:
+		if a.Value == "" {
+			return fmt.Errorf("%s needs a value", a.Type)
+		}
+	case AssertRegex:
+		expr := a.Value
+		if a.IgnoreCase {
+			expr = "(?i)" + expr
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return fmt.Errorf("invalid regex: %w", err)
+		}
+		a.re = re
+	case AssertJSONSchema:
+		if a.Schema == nil {
+			return errors.New("json_schema needs a schema")
+		}
+	case AssertJudge:
+		if strings.TrimSpace(a.Criteria) == "" {
+			return errors.New("judge needs criteria")
+		}
+		if a.MinScore < 0 || a.MinScore > 1 {
+			return fmt.Errorf("minScore %v is not between 0 and 1", a.MinScore)
+		}
+	default:
+		return fmt.Errorf("unknown assertion type %q (supported: contains, not_contains, regex, json_schema, judge)", a.Type)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/plexusone/agentkit/llm/stream"
+	"github.com/plexusone/agentkit/platforms/agentcore"
+	"github.com/plexusone/agentkit/platforms/local"
+)
+
+// Target is an agent under evaluation.
+type Target interface {
+	// Name names the agent in reports.
+	Name() string
+
+	// Run sends the input and metadata of a case to the agent and returns
+	// its output.
+	Run(ctx context.Context, input string, metadata map[string]string) (*Output, error)
+}
+
+// Output is the output of an agent for a case.
+type Output struct {
+	// Text is the output the assertions check.
+	Text string
+
+	// Usage is the token usage and cost of the run, if the agent reports
+	// it.
+	Usage *stream.Usage
+}
+
+// Embedded returns a Target that invokes an embedded agent. Metadata is
+// not sent.
+func Embedded(agent *local.EmbeddedAgent) Target {
+	return &embeddedTarget{agent: agent}
+}
+
+type embeddedTarget struct {
+	agent *local.EmbeddedAgent
+}
+
+// Name implements Target.
+func (t *embeddedTarget) Name() string { return t.agent.Name() }
+
+// Run implements Target.
+func (t *embeddedTarget) Run(ctx context.Context, input string, _ map[string]string) (*Output, error) {
+	result, err := t.agent.Invoke(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Success {
+		return nil, errors.New(result.Error)
+	}
+	return &Output{Text: result.Output, Usage: result.Usage}, nil
+}
+
+// AgentCore returns a Target that invokes an AgentCore agent with the input
+// as prompt. The usage is read from the response metadata, see
+// agentcore.UsageMetadata.
+func AgentCore(agent agentcore.Agent) Target {
+	return &agentCoreTarget{agent: agent}
+}
+
+type agentCoreTarget struct {
+	agent agentcore.Agent
+}
+
+// Name implements Target.
+func (t *agentCoreTarget) Name() string { return t.agent.Name() }
+
+// Run implements Target.
+func (t *agentCoreTarget) Run(ctx context.Context, input string, metadata map[string]string) (*Output, error) {
+	resp, err := t.agent.Invoke(ctx, agentcore.Request{Prompt: input, Agent: t.agent.Name(), Metadata: metadata})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return &Output{Text: resp.Output, Usage: usageOf(resp.Metadata)}, nil
+}
+
+// usageOf returns the usage in response metadata, or nil if it has none.
+func usageOf(metadata map[string]string) *stream.Usage {
+	prompt, err := strconv.Atoi(metadata[agentcore.MetadataPromptTokens])
+	if err != nil {
+		return nil
+	}
+	completion, _ := strconv.Atoi(metadata[agentcore.MetadataCompletionTokens])
+	cost, _ := strconv.ParseFloat(metadata[agentcore.MetadataCostUSD], 64)
+	return &stream.Usage{PromptTokens: prompt, CompletionTokens: completion, CostUSD: cost}
+}
@@ -0,0 +1,515 @@
+package extract
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+// converter renders HTML nodes as Markdown or text. Block elements ask for
+// line breaks, which are written before the next content, so that empty
+// elements leave no blank lines.
+type converter struct {
+	opts *Options
+	base *url.URL
+	out  strings.Builder
+
+	indent      string // prefix of new lines, for lists and quotes
+	newlines    int    // line breaks pending before the next content
+	breakIndent string // prefix of the pending blank lines
+	space       bool   // a space is pending before the next content
+	marker      bool   // a list marker was just written; ignore line breaks
+}
+
+// String returns the rendered content, without trailing spaces or runs of
+// blank lines.
+func (c *converter) String() string {
+	lines := strings.Split(c.out.String(), "\n")
+	var b strings.Builder
+	blank := 0
+	for _, line := range lines {
+		line = strings.TrimRightFunc(line, unicode.IsSpace)
+		if strings.TrimLeft(line, "> ") == "" {
+			blank++
+			if blank > 1 {
+				continue
+			}
+		} else {
+			blank = 0
+		}
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// block asks for n line breaks before the next content.
+func (c *converter) block(n int) {
+	if c.marker {
+		return
+	}
+	// Blank lines belong to the outermost block asking for them, e.g.
+	// around a quote.
+	if c.newlines == 0 {
+		c.breakIndent = c.indent
+	}
+	for !strings.HasPrefix(c.indent, c.breakIndent) {
+		c.breakIndent = c.breakIndent[:len(c.breakIndent)-1]
+	}
+	c.newlines = max(c.newlines, n)
+	c.space = false
+}
+
+// emit writes content after the pending line breaks or space.
+func (c *converter) emit(s string) {
+	if s == "" {
+		return
+	}
+	switch {
+	case c.out.Len() == 0:
+		c.out.WriteString(c.indent)
+	case c.newlines > 0:
+		for range c.newlines - 1 {
+			c.out.WriteString("\n" + c.breakIndent)
+		}
+		c.out.WriteString("\n" + c.indent)
+	case c.space:
+		c.out.WriteByte(' ')
+	}
+	c.newlines, c.space, c.marker = 0, false, false
+	c.out.WriteString(s)
+}
+
+// text writes text with its whitespace collapsed.
+func (c *converter) text(s string) {
+	if s == "" {
+		return
+	}
+	if unicode.IsSpace(rune(s[0])) {
+		c.space = true
+	}
+	c.emit(collapse(s))
+	if unicode.IsSpace(rune(s[len(s)-1])) {
+		c.space = true
+	}
+}
+
+// children renders the children of n.
+func (c *converter) children(n *html.Node) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		c.node(child)
+	}
+}
+
+// node renders n.
+func (c *converter) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		c.text(n.Data)
+		return
+	case html.ElementNode:
+	default:
+		c.children(n)
+		return
+	}
+	if c.skip(n) {
+		return
+	}
+
+	md := c.opts.Format == Markdown
+	switch tag := n.Data; tag {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		c.block(2)
+		text := c.inline(n)
+		if md && text != "" {
+			level, _ := strconv.Atoi(tag[1:])
+			text = strings.Repeat("#", level) + " " + text
+		}
+		c.emit(text)
+		c.block(2)
+	case "p":
+		c.block(2)
+		c.children(n)
+		c.block(2)
+	case "br":
+		c.block(1)
+	case "hr":
+		c.block(2)
+		if md {
+			c.emit("---")
+		}
+		c.block(2)
+	case "ul", "ol":
+		c.list(n, tag == "ol")
+	case "li":
+		c.item(n, "-")
+	case "blockquote":
+		c.block(2)
+		indent := c.indent
+		if md {
+			c.indent += "> "
+		}
+		c.children(n)
+		c.indent = indent
+		c.block(2)
+	case "pre":
+		c.pre(n)
+	case "code", "kbd", "samp":
+		c.wrap(n, "`", md)
+	case "strong", "b":
+		c.wrap(n, "**", md)
+	case "em", "i":
+		c.wrap(n, "_", md)
+	case "del", "s":
+		c.wrap(n, "~~", md)
+	case "a":
+		c.link(n, md)
+	case "table":
+		c.table(n, md)
+	case "div", "section", "article", "main", "header", "footer", "figure", "figcaption",
+		"dl", "dt", "dd", "address", "details", "summary", "tr", "caption":
+		c.block(1)
+		c.children(n)
+		c.block(1)
+	default:
+		c.children(n)
+	}
+}
+
+// inline renders the children of n on one line.
+func (c *converter) inline(n *html.Node) string {
+	sub := &converter{opts: c.opts, base: c.base}
+	sub.children(n)
+	return collapse(sub.String())
+}
+
+// wrap renders n inline between markers in Markdown, keeping the spaces
+// around it outside the markers.
+func (c *converter) wrap(n *html.Node, marker string, md bool) {
+	raw := textOf(n)
+	text := c.inline(n)
+	if marker == "`" {
+		text = collapse(raw)
+	}
+	if text == "" {
+		c.space = c.space || raw != ""
+		return
+	}
+	if strings.TrimLeftFunc(raw, unicode.IsSpace) != raw {
+		c.space = true
+	}
+	if md {
+		text = marker + text + marker
+	}
+	c.emit(text)
+	if strings.TrimRightFunc(raw, unicode.IsSpace) != raw {
+		c.space = true
+	}
+}
+
+// link renders a link as [text](href) in Markdown. In-page and script
+// links keep their text only.
+func (c *converter) link(n *html.Node, md bool) {
+	text := c.inline(n)
+	if text == "" {
+		return
+	}
+	raw := textOf(n)
+	if strings.TrimLeftFunc(raw, unicode.IsSpace) != raw {
+		c.space = true
+	}
+	href := strings.TrimSpace(attr(n, "href"))
+	if md && href != "" && !strings.HasPrefix(href, "#") && !strings.HasPrefix(strings.ToLower(href), "javascript:") {
+		if c.base != nil {
+			if ref, err := c.base.Parse(href); err == nil {
+				href = ref.String()
+			}
+		}
+		text = "[" + text + "](" + href + ")"
+	}
+	c.emit(text)
+	if strings.TrimRightFunc(raw, unicode.IsSpace) != raw {
+		c.space = true
+	}
+}
+
+// list renders the items of a list, numbered if ordered, indenting
+// nested lists under their item.
+func (c *converter) list(n *html.Node, ordered bool) {
+	nested := closest(n.Parent, "li") != nil
+	if nested {
+		c.block(1)
+	} else {
+		c.block(2)
+	}
+	number := 1
+	if start, err := strconv.Atoi(attr(n, "start")); err == nil {
+		number = start
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if !isTag("li")(child) {
+			c.node(child)
+			continue
+		}
+		if c.skip(child) {
+			continue
+		}
+		marker := "-"
+		if ordered {
+			marker = strconv.Itoa(number) + "."
+			number++
+		}
+		c.item(child, marker)
+	}
+	if nested {
+		c.block(1)
+	} else {
+		c.block(2)
+	}
+}
+
+// item renders a list item after its marker, indenting its other lines.
+func (c *converter) item(n *html.Node, marker string) {
+	c.marker = false
+	c.block(1)
+	c.emit(marker)
+	c.marker, c.space = true, true
+	indent := c.indent
+	c.indent += strings.Repeat(" ", len(marker)+1)
+	c.children(n)
+	c.indent = indent
+	c.marker = false
+	c.block(1)
+}
+
+// languagePattern matches the language class of a code block, e.g.
+// "language-go" or "lang-go".
+var languagePattern = regexp.MustCompile(`\blang(?:uage)?-([\w+#-]+)`)
+
+// pre renders preformatted text as a fenced code block in Markdown,
+// keeping its whitespace.
+func (c *converter) pre(n *html.Node) {
+	text := strings.Trim(textOf(n), "\n")
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	c.block(2)
+	if c.opts.Format == Markdown {
+		class := attr(n, "class")
+		if code := find(n, isTag("code")); code != nil {
+			class += " " + attr(code, "class")
+		}
+		lang := ""
+		if m := languagePattern.FindStringSubmatch(class); m != nil {
+			lang = m[1]
+		}
+		c.emit("```" + lang)
+		c.block(1)
+		c.emit(strings.ReplaceAll(text, "\n", "\n"+c.indent))
+		c.block(1)
+		c.emit("```")
+	} else {
+		c.emit(strings.ReplaceAll(text, "\n", "\n"+c.indent))
+	}
+	c.block(2)
+}
+
+// table renders a table as a Markdown table with its first row as header,
+// or as lines of cells separated by " | " in text.
+func (c *converter) table(n *html.Node, md bool) {
+	var rows [][]string
+	walk(n, func(row *html.Node) {
+		if !isTag("tr")(row) || closest(row, "table") != n {
+			return
+		}
+		var cells []string
+		for cell := row.FirstChild; cell != nil; cell = cell.NextSibling {
+			if isTag("td")(cell) || isTag("th")(cell) {
+				text := c.inline(cell)
+				if md {
+					text = strings.ReplaceAll(text, "|", `\|`)
+				}
+				cells = append(cells, text)
+			}
+		}
+		if len(cells) > 0 {
+			rows = append(rows, cells)
+		}
+	})
+	if len(rows) == 0 {
+		return
+	}
+
+	c.block(2)
+	if !md {
+		for _, row := range rows {
+			c.block(1)
+			c.emit(strings.Join(row, " | "))
+		}
+		c.block(2)
+		return
+	}
+	columns := 0
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+	for i, row := range rows {
+		for len(row) < columns {
+			row = append(row, "")
+		}
+		c.block(1)
+		c.emit("| " + strings.Join(row, " | ") + " |")
+		if i == 0 {
+			c.block(1)
+			c.emit("|" + strings.Repeat(" --- |", columns))
+		}
+	}
+	c.block(2)
+}
+
+// skippedTags are never rendered: scripts, styles, media and metadata.
+var skippedTags = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true, "head": true,
+	"iframe": true, "object": true, "embed": true, "canvas": true, "svg": true,
+	"img": true, "picture": true, "video": true, "audio": true, "source": true,
+	"map": true, "link": true, "meta": true,
+}
+
+// boilerplateTags hold navigation and interaction rather than content.
+var boilerplateTags = map[string]bool{
+	"nav": true, "aside": true, "form": true, "button": true, "input": true,
+	"select": true, "textarea": true, "dialog": true,
+}
+
+// boilerplateRoles are the ARIA roles of boilerplate.
+var boilerplateRoles = map[string]bool{
+	"navigation": true, "banner": true, "contentinfo": true, "complementary": true,
+	"search": true, "dialog": true, "alertdialog": true,
+}
+
+// boilerplateWords are the words of class names and IDs of boilerplate.
+var boilerplateWords = map[string]bool{
+	"nav": true, "navbar": true, "navigation": true, "menu": true, "sidebar": true,
+	"breadcrumb": true, "breadcrumbs": true, "cookie": true, "cookies": true,
+	"consent": true, "share": true, "sharing": true, "social": true, "advert": true,
+	"advertisement": true, "ads": true, "sponsored": true, "promo": true,
+	"newsletter": true, "popup": true, "modal": true, "related": true, "comments": true,
+	"footer": true, "skip": true,
+}
+
+// skip reports whether n is not rendered: scripts, styles and media,
+// hidden elements, and unless KeepBoilerplate, navigation, page headers
+// and footers, sidebars, forms, and elements whose class or ID names
+// boilerplate such as cookie banners and share buttons.
+func (c *converter) skip(n *html.Node) bool {
+	if skippedTags[n.Data] {
+		return true
+	}
+	if _, hidden := attrOK(n, "hidden"); hidden || attr(n, "aria-hidden") == "true" ||
+		strings.Contains(strings.ReplaceAll(attr(n, "style"), " ", ""), "display:none") {
+		return true
+	}
+	if c.opts.KeepBoilerplate {
+		return false
+	}
+	if boilerplateTags[n.Data] || boilerplateRoles[attr(n, "role")] {
+		return true
+	}
+	if (n.Data == "header" || n.Data == "footer") && closest(n.Parent, "article") == nil {
+		return true
+	}
+	words := strings.FieldsFunc(strings.ToLower(attr(n, "class")+" "+attr(n, "id")), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		if boilerplateWords[word] {
+			return true
+		}
+	}
+	return false
+}
+
+// isTag returns a predicate matching elements named tag.
+func isTag(tag string) func(*html.Node) bool {
+	return func(n *html.Node) bool {
+		return n.Type == html.ElementNode && n.Data == tag
+	}
+}
+
+// find returns the first node under n, or n itself, that matches, in
+// document order.
+func find(n *html.Node, match func(*html.Node) bool) *html.Node {
+	if n == nil {
+		return nil
+	}
+	if match(n) {
+		return n
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if found := find(child, match); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// walk calls fn for n and every node under it, in document order.
+func walk(n *html.Node, fn func(*html.Node)) {
+	fn(n)
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		walk(child, fn)
+	}
+}
+
+// closest returns n or its nearest ancestor named tag, or nil.
+func closest(n *html.Node, tag string) *html.Node {
+	for ; n != nil; n = n.Parent {
+		if isTag(tag)(n) {
+			return n
+		}
+	}
+	return nil
+}
+
+// attrOK returns the value of an attribute of n and whether n has it.
+func attrOK(n *html.Node, key string) (string, bool) {
+	if n == nil {
+		return "", false
+	}
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// attr returns the value of an attribute of n, or "".
+func attr(n *html.Node, key string) string {
+	v, _ := attrOK(n, key)
+	return v
+}
+
+// textOf returns the text under n, without scripts and styles.
+func textOf(n *html.Node) string {
+	if n == nil {
+		return ""
+	}
+	var b strings.Builder
+	walk(n, func(node *html.Node) {
+		if node.Type == html.TextNode && !skippedTags[node.Parent.Data] {
+			b.WriteString(node.Data)
+		}
+	})
+	return b.String()
+}
+
+// collapse replaces runs of whitespace with single spaces and trims the
+// ends.
+func collapse(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,203 @@
+// Package extract turns fetched HTML into compact Markdown or plain text
+// for LLM context: it keeps the main content, drops navigation, scripts
+// and other boilerplate, and truncates the result to a token budget.
+//
+//	page, err := ba.FetchURL(ctx, url, 10)
+//	if err != nil {
+//	    return err
+//	}
+//	doc, err := extract.FromHTML(page, extract.Options{BaseURL: url, MaxTokens: 4000})
+//	if err != nil {
+//	    return err
+//	}
+//	prompt := "Summarize " + doc.Title + ":\n\n" + doc.Content
+package extract
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// Format is an output format of FromHTML.
+type Format string
+
+const (
+	// Markdown keeps headings, lists, links, emphasis, code and tables as
+	// Markdown.
+	Markdown Format = "markdown"
+
+	// Text keeps the text only, with lists as "- " lines.
+	Text Format = "text"
+)
+
+// Options configures FromHTML.
+type Options struct {
+	// Format is the output format.
+	// Default: Markdown
+	Format Format
+
+	// MaxTokens truncates the content to this many tokens, at a paragraph
+	// or word boundary.
+	// Default: 0 (no limit)
+	MaxTokens int
+
+	// CountTokens counts the tokens of a text for MaxTokens.
+	// Default: EstimateTokens
+	CountTokens func(text string) int
+
+	// BaseURL resolves relative links, usually the URL the page was
+	// fetched from.
+	// Default: "" (links are kept as written)
+	BaseURL string
+
+	// KeepBoilerplate converts the whole body, including navigation,
+	// headers, footers, sidebars and forms.
+	// Default: false
+	KeepBoilerplate bool
+}
+
+// Result is the content extracted from a page.
+type Result struct {
+	// Title is the page's <title>, or its first <h1> if it has none.
+	Title string
+
+	// Content is the main content in the requested format.
+	Content string
+
+	// Tokens is the number of tokens of Content, by Options.CountTokens.
+	Tokens int
+
+	// Truncated is set if Content was cut to Options.MaxTokens.
+	Truncated bool
+}
+
+// EstimateTokens estimates the tokens of text at about four characters
+// per token, which is close for English prose with common tokenizers.
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+// FromHTML extracts the main content of an HTML page. The main content is
+// the page's <main> element, else its largest <article>, else its body.
+// Images are dropped.
+func FromHTML(page string, opts Options) (*Result, error) {
+	if opts.Format == "" {
+		opts.Format = Markdown
+	}
+	if opts.Format != Markdown && opts.Format != Text {
+		return nil, fmt.Errorf("unknown format %q", opts.Format)
+	}
+	if opts.CountTokens == nil {
+		opts.CountTokens = EstimateTokens
+	}
+	var base *url.URL
+	if opts.BaseURL != "" {
+		var err error
+		if base, err = url.Parse(opts.BaseURL); err != nil {
+			return nil, fmt.Errorf("invalid base URL: %w", err)
+		}
+	}
+
+	doc, err := html.Parse(strings.NewReader(page))
+	if err != nil {
+		return nil, fmt.Errorf("parsing HTML: %w", err)
+	}
+	if href := attr(find(doc, isTag("base")), "href"); href != "" && base != nil {
+		if ref, err := base.Parse(href); err == nil {
+			base = ref
+		}
+	}
+
+	c := &converter{opts: &opts, base: base}
+	c.children(mainContent(doc, opts.KeepBoilerplate))
+	result := &Result{Title: title(doc), Content: c.String()}
+	if opts.MaxTokens > 0 {
+		result.Content, result.Truncated = Truncate(result.Content, opts.MaxTokens, opts.CountTokens)
+	}
+	result.Tokens = opts.CountTokens(result.Content)
+	return result, nil
+}
+
+// title returns the text of the page's <title>, or of its first <h1>.
+func title(doc *html.Node) string {
+	if t := find(doc, isTag("title")); t != nil {
+		if text := collapse(textOf(t)); text != "" {
+			return text
+		}
+	}
+	return collapse(textOf(find(doc, isTag("h1"))))
+}
+
+// mainContent returns the node holding the main content of doc.
+func mainContent(doc *html.Node, keepBoilerplate bool) *html.Node {
+	body := find(doc, isTag("body"))
+	if body == nil {
+		body = doc
+	}
+	if keepBoilerplate {
+		return body
+	}
+	if main := find(body, func(n *html.Node) bool {
+		return isTag("main")(n) || attr(n, "role") == "main"
+	}); main != nil {
+		return main
+	}
+	var largest *html.Node
+	size := 0
+	walk(body, func(n *html.Node) {
+		if isTag("article")(n) {
+			if s := len(textOf(n)); s > size {
+				largest, size = n, s
+			}
+		}
+	})
+	if largest != nil && size > len(textOf(body))/3 {
+		return largest
+	}
+	return body
+}
+
+// Truncate cuts text to maxTokens, at the last paragraph that fits, or
+// within the first paragraph at a word boundary, and reports whether it
+// did. count counts tokens, e.g. EstimateTokens.
+func Truncate(text string, maxTokens int, count func(string) int) (string, bool) {
+	if count(text) <= maxTokens {
+		return text, false
+	}
+	blocks := strings.Split(text, "\n\n")
+	kept := longestFit(len(blocks), func(n int) bool {
+		return count(strings.Join(blocks[:n], "\n\n")) <= maxTokens
+	})
+	if kept > 0 {
+		return strings.Join(blocks[:kept], "\n\n"), true
+	}
+
+	// Cut the first block.
+	runes := []rune(blocks[0])
+	prefix := string(runes[:longestFit(len(runes), func(n int) bool {
+		return count(string(runes[:n])) <= maxTokens
+	})])
+	if i := strings.LastIndexAny(prefix, " \n"); i > 0 {
+		prefix = prefix[:i]
+	}
+	return strings.TrimSpace(prefix), true
+}
+
+// longestFit returns the largest n up to limit for which fits is true,
+// given that it is true up to some n and false after.
+func longestFit(limit int, fits func(n int) bool) int {
+	lo, hi := 0, limit
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if fits(mid) {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	return lo
+}
@@ -0,0 +1,101 @@
+package http
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without making a call while a CircuitBreaker
+// is open.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// CircuitBreakerConfig configures a CircuitBreaker. After FailureThreshold
+// consecutive calls fail, the circuit opens and calls fail fast with
+// ErrCircuitOpen. After Cooldown, one trial call is let through; its
+// success closes the circuit.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failed calls that
+	// opens the circuit. A negative value disables circuit breaking.
+	// Default: 5
+	FailureThreshold int
+
+	// Cooldown is how long the circuit stays open before a trial call.
+	// Default: 30s
+	Cooldown time.Duration
+}
+
+// CircuitBreaker tracks consecutive failures of calls to one service. It
+// is the circuit breaker shared by the calling parts of agentkit, such as
+// orchestration.AgentCaller and a2a.Client. It is safe for concurrent
+// use, and a nil *CircuitBreaker allows every call.
+type CircuitBreaker struct {
+	cfg CircuitBreakerConfig
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	trial    bool // a trial call is in flight
+}
+
+// NewCircuitBreaker creates a closed circuit breaker, or returns nil if
+// cfg disables circuit breaking.
+func NewCircuitBreaker(cfg CircuitBreakerConfig) *CircuitBreaker {
+	if cfg.FailureThreshold < 0 {
+		return nil
+	}
+	if cfg.FailureThreshold == 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 30 * time.Second
+	}
+	return &CircuitBreaker{cfg: cfg}
+}
+
+// Allow returns ErrCircuitOpen while the circuit is open or a trial call is
+// in flight. Otherwise the call may proceed, and its outcome must be passed
+// to Record.
+func (b *CircuitBreaker) Allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.cfg.FailureThreshold {
+		return nil
+	}
+	if b.trial || time.Since(b.openedAt) < b.cfg.Cooldown {
+		return ErrCircuitOpen
+	}
+	b.trial = true
+	return nil
+}
+
+// Open reports whether Allow would fail, without starting a trial call.
+func (b *CircuitBreaker) Open() bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures >= b.cfg.FailureThreshold && (b.trial || time.Since(b.openedAt) < b.cfg.Cooldown)
+}
+
+// Record records the outcome of a call allowed by Allow.
+func (b *CircuitBreaker) Record(failed bool) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+	if !failed {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.cfg.FailureThreshold {
+		b.openedAt = time.Now()
+	}
+}
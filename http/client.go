@@ -3,87 +3,227 @@ package http
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
-// PostJSON makes a POST request with JSON payload and decodes the JSON response.
-func PostJSON(ctx context.Context, client *http.Client, url string, request interface{}, response interface{}) error {
-	reqData, err := json.Marshal(request)
-	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
-	}
+// maxErrorBody is the most of a response body kept in a StatusError.
+const maxErrorBody = 4 << 10
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(reqData))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
+// StatusError is returned when a service responds with an unexpected HTTP
+// status.
+type StatusError struct {
+	// StatusCode is the HTTP status code, e.g. 503.
+	StatusCode int
 
-	resp, err := client.Do(httpReq) //nolint:gosec // G704: URL provided by SDK user
-	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
-	}
-	defer func() { _ = resp.Body.Close() }()
+	// Status is the HTTP status line, e.g. "503 Service Unavailable".
+	Status string
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("HTTP %d: %s - %s", resp.StatusCode, resp.Status, string(body))
-	}
+	// Method and URL identify the failed request.
+	Method string
+	URL    string
 
-	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
-		return fmt.Errorf("failed to decode response: %w", err)
-	}
+	// Body is an excerpt of the response body: its first 4 KiB, followed
+	// by "..." if it is longer.
+	Body string
 
-	return nil
+	// RetryAfter is the wait asked for by the Retry-After header of the
+	// response, or 0 if it has none.
+	RetryAfter time.Duration
 }
 
-// GetJSON makes a GET request and decodes the JSON response.
-func GetJSON(ctx context.Context, client *http.Client, url string, response interface{}) error {
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+// NewStatusError reads an excerpt of the body of resp, the response to
+// req, into a StatusError.
+func NewStatusError(req *http.Request, resp *http.Response) *StatusError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody+1))
+	excerpt := string(body)
+	if len(body) > maxErrorBody {
+		excerpt = string(body[:maxErrorBody]) + "..."
 	}
-	httpReq.Header.Set("Accept", "application/json")
-
-	resp, err := client.Do(httpReq) //nolint:gosec // G704: URL provided by SDK user
-	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+	return &StatusError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		Body:       excerpt,
+		RetryAfter: ParseRetryAfter(resp.Header.Get("Retry-After")),
 	}
-	defer func() { _ = resp.Body.Close() }()
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("HTTP %d: %s - %s", resp.StatusCode, resp.Status, string(body))
+// Error implements error.
+func (e *StatusError) Error() string {
+	msg := fmt.Sprintf("HTTP %d: %s - %s", e.StatusCode, e.Status, e.Body)
+	if e.URL != "" {
+		msg = e.Method + " " + e.URL + ": " + msg
 	}
+	return msg
+}
+
+// Transient reports whether the request may succeed if retried: request
+// timeouts (408), rate limiting (429) and server errors other than 501 Not
+// Implemented. Other client errors are permanent.
+func (e *StatusError) Transient() bool {
+	return IsTransientStatus(e.StatusCode)
+}
 
-	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
-		return fmt.Errorf("failed to decode response: %w", err)
+// IsTransientStatus reports whether an HTTP status code indicates a
+// failure that may succeed if retried; see StatusError.Transient.
+func IsTransientStatus(code int) bool {
+	switch {
+	case code == http.StatusRequestTimeout, code == http.StatusTooManyRequests:
+		return true
+	case code == http.StatusNotImplemented:
+		return false
+	default:
+		return code >= 500
 	}
+}
 
-	return nil
+// ParseRetryAfter parses a Retry-After header, given in seconds or as an
+// HTTP date, into a wait. It returns 0 for a missing or invalid header.
+func ParseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(strings.TrimSpace(header)); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		return max(time.Until(t), 0)
+	}
+	return 0
 }
 
-// HealthCheck checks if a service is healthy by calling its /health endpoint.
-func HealthCheck(ctx context.Context, client *http.Client, baseURL string) error {
-	url := baseURL + "/health"
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+// PostJSON makes a POST request with JSON payload and decodes the JSON response.
+// A status other than 200 OK is returned as a *StatusError. Options add
+// retries, per-attempt timeouts, request compression and headers; gzip
+// responses are decompressed.
+func PostJSON(ctx context.Context, client *http.Client, url string, request interface{}, response interface{}, opts ...Option) error {
+	reqData, err := json.Marshal(request)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := client.Do(httpReq) //nolint:gosec // G704: URL provided by SDK user
-	if err != nil {
-		return fmt.Errorf("health check failed: %w", err)
+	o := newCallOptions(opts)
+	o.header.Set("Content-Type", "application/json")
+	if o.gzipMinSize > 0 && len(reqData) >= o.gzipMinSize {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(reqData); err != nil {
+			return fmt.Errorf("failed to compress request: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return fmt.Errorf("failed to compress request: %w", err)
+		}
+		reqData = buf.Bytes()
+		o.header.Set("Content-Encoding", "gzip")
 	}
-	defer func() { _ = resp.Body.Close() }()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("service unhealthy: HTTP %d", resp.StatusCode)
+	return do(ctx, client, http.MethodPost, url, reqData, o, func(resp *http.Response) error {
+		if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetJSON makes a GET request and decodes the JSON response.
+// A status other than 200 OK is returned as a *StatusError. It takes the
+// same options as PostJSON.
+func GetJSON(ctx context.Context, client *http.Client, url string, response interface{}, opts ...Option) error {
+	o := newCallOptions(opts)
+	o.header.Set("Accept", "application/json")
+
+	return do(ctx, client, http.MethodGet, url, nil, o, func(resp *http.Response) error {
+		if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+		return nil
+	})
+}
+
+// HealthCheck checks if a service is healthy by calling its /health endpoint.
+// An unhealthy status is returned as an error wrapping a *StatusError. It
+// takes the same options as PostJSON.
+func HealthCheck(ctx context.Context, client *http.Client, baseURL string, opts ...Option) error {
+	err := do(ctx, client, http.MethodGet, baseURL+"/health", nil, newCallOptions(opts), func(*http.Response) error {
+		return nil
+	})
+	var statusErr *StatusError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &statusErr):
+		return fmt.Errorf("service unhealthy: %w", err)
+	default:
+		return fmt.Errorf("health check failed: %w", err)
 	}
+}
 
-	return nil
+// do sends a request with body under o, retrying failed attempts, and
+// passes a 200 OK response to handle. Other statuses fail with a
+// *StatusError.
+func do(ctx context.Context, client *http.Client, method, url string, body []byte, o *callOptions, handle func(*http.Response) error) error {
+	return o.retry.Do(ctx, func(ctx context.Context) error {
+		if o.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, o.timeout)
+			defer cancel()
+		}
+
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+		httpReq, err := http.NewRequestWithContext(ctx, method, url, reader)
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		// Asking for gzip explicitly makes the transport leave decompression
+		// to us, so that it happens whatever transport the client uses.
+		httpReq.Header.Set("Accept-Encoding", "gzip")
+		if id := CorrelationID(ctx); id != "" {
+			httpReq.Header.Set(CorrelationIDHeader, id)
+		}
+		for key, values := range o.header {
+			httpReq.Header[key] = values
+		}
+
+		resp, err := client.Do(httpReq) //nolint:gosec // G704: URL provided by SDK user
+		if err != nil {
+			return fmt.Errorf("request failed: %w", err)
+		}
+		raw := resp.Body
+		defer func() {
+			// Drain what is left of a small body so that the connection
+			// can be reused.
+			_, _ = io.Copy(io.Discard, io.LimitReader(raw, maxErrorBody))
+			_ = raw.Close()
+		}()
+
+		if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") && !resp.Uncompressed {
+			zr, err := gzip.NewReader(raw)
+			switch {
+			case errors.Is(err, io.EOF):
+				resp.Body = http.NoBody
+			case err != nil:
+				return fmt.Errorf("failed to decompress response: %w", err)
+			default:
+				resp.Body = zr
+			}
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return NewStatusError(httpReq, resp)
+		}
+		return handle(resp)
+	})
 }
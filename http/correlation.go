@@ -0,0 +1,90 @@
+package http
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// CorrelationIDHeader carries the correlation ID of a request between
+// agents. Unlike a request ID, which names one hop, the correlation ID is
+// the same for every call made on behalf of the request that started it.
+const CorrelationIDHeader = "X-Correlation-ID"
+
+// correlationIDKey is the context key of the correlation ID.
+type correlationIDKey struct{}
+
+// WithCorrelationID returns a context carrying the correlation ID id. Calls
+// made with PostJSON, GetJSON and HealthCheck send it in the
+// X-Correlation-ID header.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationID returns the correlation ID of ctx, or "".
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// EnsureCorrelationID returns ctx and its correlation ID, or, if it has
+// none, a context carrying a new one. Entry points of agents call it so
+// that everything downstream shares one ID.
+func EnsureCorrelationID(ctx context.Context) (context.Context, string) {
+	if id := CorrelationID(ctx); id != "" {
+		return ctx, id
+	}
+	id := NewCorrelationID()
+	return WithCorrelationID(ctx, id), id
+}
+
+// NewCorrelationID returns a new random correlation ID.
+func NewCorrelationID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ValidCorrelationID reports whether a correlation ID received from a
+// caller is short and safe to log and echo.
+func ValidCorrelationID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("-_.:", c)) {
+			return false
+		}
+	}
+	return true
+}
+
+// CorrelationTransport returns a round tripper that sets the
+// X-Correlation-ID header of requests whose context has a correlation ID,
+// for clients other than those of this package. base defaults to
+// http.DefaultTransport.
+func CorrelationTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return correlationRoundTripper{base: base}
+}
+
+// correlationRoundTripper is the round tripper of CorrelationTransport.
+type correlationRoundTripper struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t correlationRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	id := CorrelationID(req.Context())
+	if id == "" || req.Header.Get(CorrelationIDHeader) != "" {
+		return t.base.RoundTrip(req)
+	}
+	// Round trippers must not modify the request they are given.
+	req = req.Clone(req.Context())
+	req.Header.Set(CorrelationIDHeader, id)
+	return t.base.RoundTrip(req)
+}
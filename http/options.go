@@ -0,0 +1,66 @@
+package http
+
+import (
+	"net/http"
+	"time"
+)
+
+// Option configures a call of PostJSON, GetJSON or HealthCheck.
+type Option func(*callOptions)
+
+// callOptions are the settings of one call.
+type callOptions struct {
+	retry       RetryPolicy
+	timeout     time.Duration
+	gzipMinSize int // request bodies are not compressed if 0
+	header      http.Header
+}
+
+// newCallOptions applies opts to the defaults: a single attempt, no
+// timeout beyond the client's, and uncompressed request bodies.
+func newCallOptions(opts []Option) *callOptions {
+	o := &callOptions{
+		retry:  RetryPolicy{MaxAttempts: 1}.WithDefaults(),
+		header: http.Header{},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithRetry retries failed attempts of the call under policy. Request
+// bodies are resent on each attempt.
+func WithRetry(policy RetryPolicy) Option {
+	return func(o *callOptions) {
+		o.retry = policy.WithDefaults()
+	}
+}
+
+// WithTimeout bounds each attempt of the call; retries get a fresh
+// timeout. The deadline of the context and the client's timeout still
+// apply.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *callOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithGzipRequest compresses request bodies of at least minSize bytes with
+// gzip. Use it only with services that accept gzip-encoded requests.
+// Default: 1024 bytes if minSize is not positive
+func WithGzipRequest(minSize int) Option {
+	return func(o *callOptions) {
+		if minSize <= 0 {
+			minSize = 1024
+		}
+		o.gzipMinSize = minSize
+	}
+}
+
+// WithHeader sets a request header, such as Authorization, on the call.
+func WithHeader(key, value string) Option {
+	return func(o *callOptions) {
+		o.header.Set(key, value)
+	}
+}
@@ -0,0 +1,180 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy configures how a call is retried, see WithRetry and Do. It
+// is the exponential backoff with jitter shared by the retrying parts of
+// agentkit, such as orchestration nodes and agent fetches.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first.
+	// Default: 3
+	MaxAttempts int
+
+	// InitialBackoff is the wait before the first retry.
+	// Default: 500ms
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the wait between attempts, including waits asked
+	// for by a Retry-After header.
+	// Default: 30s
+	MaxBackoff time.Duration
+
+	// Multiplier grows the wait after each retry.
+	// Default: 2
+	Multiplier float64
+
+	// Jitter randomizes each wait by up to this fraction, so that clients
+	// do not retry in lockstep.
+	// Range: 0-1
+	// Default: 0.2
+	Jitter float64
+
+	// Retryable reports whether an error should be retried.
+	// Default: IsTransient
+	Retryable func(err error) bool
+
+	// OnRetry, if set, is called by Do before each wait with the number of
+	// the failed attempt, the wait and the attempt's error, e.g. to log
+	// the retry.
+	OnRetry func(attempt int, wait time.Duration, err error)
+}
+
+// DefaultRetryPolicy returns a RetryPolicy with the default values.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		Retryable:      IsTransient,
+	}
+}
+
+// WithDefaults returns p with zero fields set to their defaults.
+func (p RetryPolicy) WithDefaults() RetryPolicy {
+	d := DefaultRetryPolicy()
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = d.MaxAttempts
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = d.InitialBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = d.MaxBackoff
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = d.Multiplier
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		p.Jitter = d.Jitter
+	}
+	if p.Retryable == nil {
+		p.Retryable = d.Retryable
+	}
+	return p
+}
+
+// Backoff returns the wait before retry number retry (1 for the first
+// retry), with jitter applied.
+func (p RetryPolicy) Backoff(retry int) time.Duration {
+	p = p.WithDefaults()
+	wait := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(retry-1))
+	wait = math.Min(wait, float64(p.MaxBackoff))
+	wait *= 1 + p.Jitter*(2*rand.Float64()-1)
+	return time.Duration(wait)
+}
+
+// Delay returns the wait before retry number retry when the server asked
+// for retryAfter, e.g. in a Retry-After header: retryAfter, capped at
+// MaxBackoff, if positive, and Backoff otherwise.
+func (p RetryPolicy) Delay(retry int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return min(retryAfter, p.WithDefaults().MaxBackoff)
+	}
+	return p.Backoff(retry)
+}
+
+// Wait sleeps for Delay(retry, retryAfter), returning ctx.Err() if ctx is
+// done first.
+func (p RetryPolicy) Wait(ctx context.Context, retry int, retryAfter time.Duration) error {
+	return sleep(ctx, p.Delay(retry, retryAfter))
+}
+
+// Do calls fn until it succeeds, fails with an error that is not
+// retryable, or MaxAttempts is reached, waiting between attempts, and
+// returns the last error. A Retry-After header of a *StatusError, or the
+// wait reported by an error with a RetryAfter() time.Duration method,
+// takes precedence over the exponential backoff. If ctx is done, Do stops
+// retrying and returns the last error.
+func (p RetryPolicy) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	p = p.WithDefaults()
+	var err error
+	for i := 1; ; i++ {
+		if err = fn(ctx); err == nil || i >= p.MaxAttempts || ctx.Err() != nil || !p.Retryable(err) {
+			return err
+		}
+		wait := p.Delay(i, retryAfter(err))
+		if p.OnRetry != nil {
+			p.OnRetry(i, wait, err)
+		}
+		if sleep(ctx, wait) != nil {
+			return err
+		}
+	}
+}
+
+// retryAfter returns the wait asked for by err, or zero.
+func retryAfter(err error) time.Duration {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.RetryAfter
+	}
+	var hint interface{ RetryAfter() time.Duration }
+	if errors.As(err, &hint) {
+		return hint.RetryAfter()
+	}
+	return 0
+}
+
+// sleep waits for d, returning ctx.Err() if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// IsTransient reports whether err is likely to succeed on retry: errors
+// implementing Transient() bool, such as *StatusError, whose Transient
+// method reports true, network timeouts, refused or reset connections,
+// and unexpected EOFs. Context cancellation is never transient.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var t interface{ Transient() bool }
+	if errors.As(err, &t) {
+		return t.Transient()
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
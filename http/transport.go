@@ -0,0 +1,100 @@
+package http
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// ClientConfig configures the HTTP client created by NewClient. The
+// defaults of net/http keep only 2 idle connections per host, which makes
+// busy agents reconnect for most calls to a peer.
+type ClientConfig struct {
+	// Timeout bounds each request, including reading the response body.
+	// Default: 60s
+	Timeout time.Duration
+
+	// MaxIdleConns is the maximum number of idle connections to all hosts.
+	// Default: 100
+	MaxIdleConns int
+
+	// MaxIdleConnsPerHost is the maximum number of idle connections kept
+	// per host.
+	// Default: 32
+	MaxIdleConnsPerHost int
+
+	// MaxConnsPerHost limits the connections per host, including those in
+	// use. Zero means no limit.
+	// Default: 0
+	MaxConnsPerHost int
+
+	// IdleConnTimeout is how long an idle connection is kept.
+	// Default: 90s
+	IdleConnTimeout time.Duration
+
+	// DialTimeout bounds establishing a connection.
+	// Default: 10s
+	DialTimeout time.Duration
+
+	// TLSHandshakeTimeout bounds the TLS handshake.
+	// Default: 10s
+	TLSHandshakeTimeout time.Duration
+
+	// ResponseHeaderTimeout bounds the wait for response headers after the
+	// request is sent. Zero means no limit beyond Timeout.
+	// Default: 0
+	ResponseHeaderTimeout time.Duration
+}
+
+// withDefaults returns c with zero fields set to their defaults.
+func (c ClientConfig) withDefaults() ClientConfig {
+	if c.Timeout <= 0 {
+		c.Timeout = 60 * time.Second
+	}
+	if c.MaxIdleConns <= 0 {
+		c.MaxIdleConns = 100
+	}
+	if c.MaxIdleConnsPerHost <= 0 {
+		c.MaxIdleConnsPerHost = 32
+	}
+	if c.IdleConnTimeout <= 0 {
+		c.IdleConnTimeout = 90 * time.Second
+	}
+	if c.DialTimeout <= 0 {
+		c.DialTimeout = 10 * time.Second
+	}
+	if c.TLSHandshakeTimeout <= 0 {
+		c.TLSHandshakeTimeout = 10 * time.Second
+	}
+	return c
+}
+
+// NewTransport creates an HTTP transport with the connection pool of cfg.
+// It uses the proxy settings of the environment and HTTP/2 where the
+// server supports it.
+func NewTransport(cfg ClientConfig) *http.Transport {
+	cfg = cfg.withDefaults()
+	dialer := &net.Dialer{Timeout: cfg.DialTimeout, KeepAlive: 30 * time.Second}
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          cfg.MaxIdleConns,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       cfg.MaxConnsPerHost,
+		IdleConnTimeout:       cfg.IdleConnTimeout,
+		TLSHandshakeTimeout:   cfg.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: cfg.ResponseHeaderTimeout,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+// NewClient creates an HTTP client for inter-agent calls, with the
+// timeout and connection pool of cfg.
+func NewClient(cfg ClientConfig) *http.Client {
+	cfg = cfg.withDefaults()
+	return &http.Client{
+		Timeout:   cfg.Timeout,
+		Transport: NewTransport(cfg),
+	}
+}
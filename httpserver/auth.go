@@ -0,0 +1,245 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/plexusone/agentkit/config"
+)
+
+// Authentication types of AuthOptions, matching config.A2AConfig.AuthType.
+const (
+	AuthAPIKey = "apikey"
+	AuthJWT    = "jwt"
+)
+
+// APIKeyHeader carries the API key of AuthAPIKey requests, unless they
+// send it as a bearer token.
+const APIKeyHeader = "X-API-Key"
+
+// AuthOptions configures Auth.
+type AuthOptions struct {
+	// Type is how callers authenticate.
+	// Supported: AuthAPIKey, AuthJWT
+	Type string
+
+	// Token is the API key callers send with AuthAPIKey, or the HMAC
+	// secret JWTs are signed with (HS256, HS384 or HS512) with AuthJWT.
+	Token string
+
+	// Issuer and Audience, when set, must match the iss and aud claims of
+	// JWTs.
+	Issuer   string
+	Audience string
+
+	// Leeway tolerates clock skew when checking the exp and nbf claims of
+	// JWTs.
+	// Default: 1 minute
+	Leeway time.Duration
+
+	// AllowNoExpiry accepts JWTs without an exp claim, which never
+	// expire. By default they are rejected.
+	AllowNoExpiry bool
+
+	// SkipPaths are served without authentication, so orchestrators can
+	// probe them.
+	// Default: "/health", "/livez", "/readyz", "/ready"
+	SkipPaths []string
+}
+
+// claimsKey is the context key of the JWT claims.
+type claimsKey struct{}
+
+// ClaimsFromContext returns the claims of the JWT Auth accepted, or nil.
+func ClaimsFromContext(ctx context.Context) map[string]interface{} {
+	claims, _ := ctx.Value(claimsKey{}).(map[string]interface{})
+	return claims
+}
+
+// Auth returns a middleware that rejects unauthenticated requests with 401
+// Unauthorized. With AuthAPIKey, requests must send Token in the
+// X-API-Key header or as a bearer token; with AuthJWT, they must send a
+// bearer JWT signed with Token, whose claims are then available from
+// ClaimsFromContext. CORS preflight requests are answered with 204 No
+// Content without reaching the handler; put CORS outside Auth to add its
+// headers to them.
+func Auth(opts AuthOptions) (Middleware, error) {
+	if opts.Token == "" {
+		return nil, fmt.Errorf("%s auth requires a token", opts.Type)
+	}
+	if opts.Leeway == 0 {
+		opts.Leeway = time.Minute
+	}
+	if opts.SkipPaths == nil {
+		opts.SkipPaths = []string{"/health", "/livez", "/readyz", "/ready"}
+	}
+
+	var authenticate func(r *http.Request) (*http.Request, error)
+	switch opts.Type {
+	case AuthAPIKey:
+		authenticate = func(r *http.Request) (*http.Request, error) {
+			key := r.Header.Get(APIKeyHeader)
+			if key == "" {
+				key = bearerToken(r)
+			}
+			if key == "" {
+				return nil, errors.New("missing API key")
+			}
+			if subtle.ConstantTimeCompare([]byte(key), []byte(opts.Token)) != 1 {
+				return nil, errors.New("invalid API key")
+			}
+			return r, nil
+		}
+	case AuthJWT:
+		authenticate = func(r *http.Request) (*http.Request, error) {
+			token := bearerToken(r)
+			if token == "" {
+				return nil, errors.New("missing bearer token")
+			}
+			claims, err := verifyJWT(token, opts, time.Now())
+			if err != nil {
+				return nil, err
+			}
+			return r.WithContext(context.WithValue(r.Context(), claimsKey{}, claims)), nil
+		}
+	default:
+		return nil, fmt.Errorf("unsupported auth type %q (supported: %s, %s)", opts.Type, AuthAPIKey, AuthJWT)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if slices.Contains(opts.SkipPaths, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			// Browsers send preflights without credentials.
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			authed, err := authenticate(r)
+			if err != nil {
+				if opts.Type == AuthJWT {
+					w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				}
+				writeJSONError(w, r, http.StatusUnauthorized, err.Error())
+				return
+			}
+			next.ServeHTTP(w, authed)
+		})
+	}, nil
+}
+
+// AuthFromConfig returns the Auth middleware of the A2A settings in cfg:
+// A2AAuthType ("apikey" or "jwt") with A2AAuthToken, so plain HTTP
+// endpoints accept the same credentials as the agent's A2A endpoints.
+func AuthFromConfig(cfg *config.Config) (Middleware, error) {
+	if cfg.A2AAuthToken == "" {
+		return nil, fmt.Errorf("%s auth requires A2A_AUTH_TOKEN", cfg.A2AAuthType)
+	}
+	return Auth(AuthOptions{Type: cfg.A2AAuthType, Token: cfg.A2AAuthToken})
+}
+
+// bearerToken returns the bearer token of the Authorization header, or "".
+func bearerToken(r *http.Request) string {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// verifyJWT checks the HMAC signature and the registered claims of a JWT
+// and returns its claims.
+func verifyJWT(token string, opts AuthOptions, now time.Time) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("malformed token header: %w", err)
+	}
+	var newHash func() hash.Hash
+	switch header.Alg {
+	case "HS256":
+		newHash = sha256.New
+	case "HS384":
+		newHash = sha512.New384
+	case "HS512":
+		newHash = sha512.New
+	default:
+		return nil, fmt.Errorf("unsupported token algorithm %q", header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("malformed token signature")
+	}
+	mac := hmac.New(newHash, []byte(opts.Token))
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, errors.New("invalid token signature")
+	}
+
+	var claims map[string]interface{}
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("malformed token claims: %w", err)
+	}
+	exp, ok := claims["exp"].(float64)
+	if !ok && !opts.AllowNoExpiry {
+		return nil, errors.New("token has no expiry")
+	}
+	if ok && now.After(time.Unix(int64(exp), 0).Add(opts.Leeway)) {
+		return nil, errors.New("token expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Before(time.Unix(int64(nbf), 0).Add(-opts.Leeway)) {
+		return nil, errors.New("token not yet valid")
+	}
+	if opts.Issuer != "" && claims["iss"] != opts.Issuer {
+		return nil, errors.New("invalid token issuer")
+	}
+	if opts.Audience != "" && !hasAudience(claims["aud"], opts.Audience) {
+		return nil, errors.New("invalid token audience")
+	}
+	return claims, nil
+}
+
+// decodeJWTPart decodes a base64url-encoded JSON part of a JWT.
+func decodeJWTPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// hasAudience reports whether the aud claim, a string or an array of
+// strings, contains audience.
+func hasAudience(aud interface{}, audience string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, a := range aud {
+			if a == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package httpserver
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSecret = "secret"
+
+// signJWT returns a JWT with claims signed with secret using HS256, or
+// unsigned if alg is "none".
+func signJWT(t *testing.T, alg, secret string, claims map[string]interface{}) string {
+	t.Helper()
+	encode := func(v interface{}) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	unsigned := encode(map[string]string{"alg": alg, "typ": "JWT"}) + "." + encode(claims)
+	if alg == "none" {
+		return unsigned + "."
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyJWT(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	valid := func() map[string]interface{} {
+		return map[string]interface{}{
+			"sub": "agent",
+			"iss": "issuer",
+			"aud": []string{"other", "agentkit"},
+			"exp": now.Add(time.Hour).Unix(),
+		}
+	}
+	with := func(key string, value interface{}) map[string]interface{} {
+		claims := valid()
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+	opts := AuthOptions{Token: testSecret, Issuer: "issuer", Audience: "agentkit", Leeway: time.Minute}
+
+	tests := []struct {
+		name    string
+		token   string
+		opts    AuthOptions
+		wantErr string
+	}{
+		{"valid", signJWT(t, "HS256", testSecret, valid()), opts, ""},
+		{"bad signature", signJWT(t, "HS256", "other", valid()), opts, "invalid token signature"},
+		{"alg none", signJWT(t, "none", "", valid()), opts, "unsupported token algorithm"},
+		{"malformed", "not-a-token", opts, "malformed token"},
+		{"expired", signJWT(t, "HS256", testSecret, with("exp", now.Add(-time.Hour).Unix())), opts, "token expired"},
+		{"expired within leeway", signJWT(t, "HS256", testSecret, with("exp", now.Add(-30*time.Second).Unix())), opts, ""},
+		{"not yet valid", signJWT(t, "HS256", testSecret, with("nbf", now.Add(time.Hour).Unix())), opts, "token not yet valid"},
+		{"missing exp", signJWT(t, "HS256", testSecret, with("exp", nil)), opts, "token has no expiry"},
+		{"missing exp allowed", signJWT(t, "HS256", testSecret, with("exp", nil)), AuthOptions{Token: testSecret, AllowNoExpiry: true}, ""},
+		{"wrong issuer", signJWT(t, "HS256", testSecret, with("iss", "someone")), opts, "invalid token issuer"},
+		{"missing issuer", signJWT(t, "HS256", testSecret, with("iss", nil)), opts, "invalid token issuer"},
+		{"wrong audience", signJWT(t, "HS256", testSecret, with("aud", "someone")), opts, "invalid token audience"},
+		{"audience string", signJWT(t, "HS256", testSecret, with("aud", "agentkit")), opts, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := verifyJWT(tt.token, tt.opts, now)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if claims["sub"] != "agent" {
+					t.Errorf("got claims %v", claims)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuth(t *testing.T) {
+	token := signJWT(t, "HS256", testSecret, map[string]interface{}{"sub": "agent", "exp": time.Now().Add(time.Hour).Unix()})
+
+	tests := []struct {
+		name       string
+		authType   string
+		method     string
+		path       string
+		header     map[string]string
+		wantStatus int
+		wantCalled bool
+	}{
+		{"api key", AuthAPIKey, http.MethodPost, "/research", map[string]string{APIKeyHeader: testSecret}, http.StatusOK, true},
+		{"api key as bearer", AuthAPIKey, http.MethodPost, "/research", map[string]string{"Authorization": "Bearer " + testSecret}, http.StatusOK, true},
+		{"wrong api key", AuthAPIKey, http.MethodPost, "/research", map[string]string{APIKeyHeader: "wrong"}, http.StatusUnauthorized, false},
+		{"missing api key", AuthAPIKey, http.MethodPost, "/research", nil, http.StatusUnauthorized, false},
+		{"jwt", AuthJWT, http.MethodPost, "/research", map[string]string{"Authorization": "Bearer " + token}, http.StatusOK, true},
+		{"missing jwt", AuthJWT, http.MethodPost, "/research", nil, http.StatusUnauthorized, false},
+		{"skip path", AuthJWT, http.MethodGet, "/health", nil, http.StatusOK, true},
+		{"options without credentials", AuthAPIKey, http.MethodOptions, "/research", nil, http.StatusUnauthorized, false},
+		{"options with credentials", AuthAPIKey, http.MethodOptions, "/research", map[string]string{APIKeyHeader: testSecret}, http.StatusOK, true},
+		{"preflight", AuthAPIKey, http.MethodOptions, "/research", map[string]string{"Origin": "https://app.example.com", "Access-Control-Request-Method": "POST"}, http.StatusNoContent, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth, err := Auth(AuthOptions{Type: tt.authType, Token: testSecret})
+			if err != nil {
+				t.Fatal(err)
+			}
+			called := false
+			handler := auth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				if tt.authType == AuthJWT && tt.path != "/health" && ClaimsFromContext(r.Context())["sub"] != "agent" {
+					t.Error("claims missing from context")
+				}
+			}))
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(`{"topic":"go"}`))
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("got status %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if called != tt.wantCalled {
+				t.Errorf("handler called = %v, want %v", called, tt.wantCalled)
+			}
+		})
+	}
+}
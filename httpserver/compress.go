@@ -0,0 +1,224 @@
+package httpserver
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// CompressOptions configures Compress.
+type CompressOptions struct {
+	// MinSize is the smallest response compressed; smaller ones are not
+	// worth the overhead.
+	// Default: 1024 bytes
+	MinSize int
+
+	// ContentTypes are the media types compressed. Entries ending in "/"
+	// match a whole type, e.g. "text/".
+	// Default: application/json, text/plain, text/markdown, text/html
+	ContentTypes []string
+
+	// Level is the compression level, from flate.BestSpeed to
+	// flate.BestCompression.
+	// Default: flate.DefaultCompression
+	Level int
+}
+
+// Compress returns a middleware that compresses responses with gzip or
+// deflate, whichever the client accepts, preferring gzip. Only responses
+// of at least MinSize bytes with one of ContentTypes are compressed, so
+// small replies and already compressed or streamed content such as
+// text/event-stream pass through unchanged.
+func Compress(opts CompressOptions) Middleware {
+	if opts.MinSize <= 0 {
+		opts.MinSize = 1024
+	}
+	if opts.ContentTypes == nil {
+		opts.ContentTypes = []string{"application/json", "text/plain", "text/markdown", "text/html"}
+	}
+	if opts.Level == 0 {
+		opts.Level = flate.DefaultCompression
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			encoding := acceptedEncoding(r.Header.Get("Accept-Encoding"))
+			if encoding == "" || r.Method == http.MethodHead || r.Header.Get("Range") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			cw := &compressWriter{ResponseWriter: w, opts: &opts, encoding: encoding}
+			defer cw.close()
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// acceptedEncoding returns the encoding to compress with under the
+// Accept-Encoding header, or "" if the client accepts neither.
+func acceptedEncoding(header string) string {
+	accepted := map[string]bool{}
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		accepted[strings.ToLower(name)] = q > 0
+	}
+	for _, encoding := range []string{"gzip", "deflate"} {
+		if ok, listed := accepted[encoding]; ok || !listed && accepted["*"] {
+			return encoding
+		}
+	}
+	return ""
+}
+
+// compressWriter buffers the start of a response until it knows whether
+// to compress it: once MinSize bytes are written, on Flush, or when the
+// handler returns.
+type compressWriter struct {
+	http.ResponseWriter
+	opts     *CompressOptions
+	encoding string
+
+	status  int
+	buf     []byte
+	decided bool
+	w       io.WriteCloser // nil if not compressing
+}
+
+// WriteHeader implements http.ResponseWriter. The header is sent once the
+// response is known to be compressed or not.
+func (w *compressWriter) WriteHeader(status int) {
+	if w.decided || w.status != 0 {
+		if w.decided && w.w == nil {
+			w.ResponseWriter.WriteHeader(status)
+		}
+		return
+	}
+	if status < 200 {
+		// Informational responses, e.g. 103 Early Hints, go out as is.
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	w.status = status
+}
+
+// Write implements http.ResponseWriter.
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if !w.decided {
+		w.buf = append(w.buf, p...)
+		if len(w.buf) < w.opts.MinSize {
+			return len(p), nil
+		}
+		if err := w.decide(); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	if w.w != nil {
+		return w.w.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// decide sends the header, compressed if the response qualifies, and the
+// buffered body.
+func (w *compressWriter) decide() error {
+	w.decided = true
+	h := w.Header()
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if h.Get("Content-Type") == "" && len(w.buf) > 0 {
+		h.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+	if len(w.buf) >= w.opts.MinSize && w.compressible() {
+		h.Set("Content-Encoding", w.encoding)
+		h.Del("Content-Length")
+		h.Del("Accept-Ranges")
+		switch w.encoding {
+		case "gzip":
+			w.w, _ = gzip.NewWriterLevel(w.ResponseWriter, w.opts.Level)
+		default:
+			w.w, _ = flate.NewWriter(w.ResponseWriter, w.opts.Level)
+		}
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if w.w != nil {
+		_, err = w.w.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// compressible reports whether the response qualifies for compression by
+// its status and headers.
+func (w *compressWriter) compressible() bool {
+	h := w.Header()
+	if w.status == http.StatusNoContent || w.status == http.StatusNotModified || h.Get("Content-Encoding") != "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return slices.ContainsFunc(w.opts.ContentTypes, func(t string) bool {
+		if strings.HasSuffix(t, "/") {
+			return strings.HasPrefix(mediaType, t)
+		}
+		return mediaType == t
+	})
+}
+
+// close finishes the response when the handler returns.
+func (w *compressWriter) close() {
+	if !w.decided {
+		_ = w.decide()
+	}
+	if w.w != nil {
+		_ = w.w.Close()
+	}
+}
+
+// Flush implements http.Flusher. A response flushed before MinSize bytes
+// is sent uncompressed, since streaming clients want each piece at once.
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		_ = w.decide()
+	}
+	if f, ok := w.w.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack implements http.Hijacker.
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.decided = true
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// Unwrap returns the wrapped writer, for http.ResponseController.
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
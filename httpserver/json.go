@@ -0,0 +1,149 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// MaxJSONBodySize is the largest request body JSONHandler decodes; larger
+// requests get 413 Request Entity Too Large.
+const MaxJSONBodySize = 1 << 20
+
+// Errors JSONHandler maps to status codes. Wrap them to add detail, e.g.
+// fmt.Errorf("%w: unknown topic %q", httpserver.ErrNotFound, topic).
+var (
+	ErrBadRequest   = errors.New("bad request")         // 400
+	ErrUnauthorized = errors.New("unauthorized")        // 401
+	ErrForbidden    = errors.New("forbidden")           // 403
+	ErrNotFound     = errors.New("not found")           // 404
+	ErrConflict     = errors.New("conflict")            // 409
+	ErrUnavailable  = errors.New("service unavailable") // 503
+)
+
+// StatusError is an error JSONHandler responds to with Status, for codes
+// the sentinel errors do not cover.
+type StatusError struct {
+	Status int
+	Err    error
+}
+
+// Error implements error.
+func (e *StatusError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the wrapped error.
+func (e *StatusError) Unwrap() error { return e.Err }
+
+// Validator is implemented by request types that check themselves.
+// JSONHandler responds 400 Bad Request if Validate fails.
+type Validator interface {
+	Validate() error
+}
+
+// JSONHandler returns a handler that decodes the JSON request body into a
+// Req, validates it if Req implements Validator, calls fn and encodes its
+// result as the JSON response. Requests without a body (e.g. GET) call fn
+// with the zero Req.
+//
+// Errors are answered as {"error": "..."}: invalid bodies with 400,
+// errors wrapping ErrBadRequest, ErrNotFound and the other sentinel
+// errors or a *StatusError with their status, and deadline errors with
+// 504. Other errors are logged and answered with 500 and a generic
+// message, so internal details do not leak to callers.
+//
+//	server, _ := httpserver.NewBuilder("research", 8001).
+//	    WithHandlerFunc("/research", httpserver.JSONHandler(agent.Research)).
+//	    Build()
+func JSONHandler[Req, Resp any](fn func(context.Context, Req) (Resp, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, ok := decodeRequest[Req](w, r)
+		if !ok {
+			return
+		}
+
+		resp, err := fn(r.Context(), req)
+		if err != nil {
+			status := errorStatus(err)
+			message := err.Error()
+			if status >= 500 && status != http.StatusServiceUnavailable && status != http.StatusGatewayTimeout {
+				LoggerFromContext(r.Context()).ErrorContext(r.Context(), "http handler failed",
+					"method", r.Method, "path", r.URL.Path, "error", err)
+				message = http.StatusText(status)
+			}
+			writeJSONError(w, r, status, message)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			LoggerFromContext(r.Context()).Error("failed to encode response", "error", err)
+		}
+	}
+}
+
+// decodeRequest decodes and validates the JSON request body as
+// JSONHandler does. On failure it responds with the error and returns
+// false.
+func decodeRequest[Req any](w http.ResponseWriter, r *http.Request) (Req, bool) {
+	var req Req
+	if r.Body != nil {
+		err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxJSONBodySize)).Decode(&req)
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			writeJSONError(w, r, http.StatusRequestEntityTooLarge, "request body too large")
+			return req, false
+		case err != nil && !errors.Is(err, io.EOF):
+			writeJSONError(w, r, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+			return req, false
+		}
+	}
+	v, ok := any(req).(Validator)
+	if !ok {
+		v, ok = any(&req).(Validator)
+	}
+	if ok {
+		if err := v.Validate(); err != nil {
+			writeJSONError(w, r, http.StatusBadRequest, fmt.Sprintf("invalid request: %v", err))
+			return req, false
+		}
+	}
+	return req, true
+}
+
+// errorStatus returns the status code JSONHandler responds to err with.
+func errorStatus(err error) int {
+	var statusErr *StatusError
+	switch {
+	case errors.As(err, &statusErr):
+		return statusErr.Status
+	case errors.Is(err, ErrBadRequest):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, ErrUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeJSONError writes {"error": message} with status.
+func writeJSONError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(map[string]string{"error": message}); err != nil {
+		LoggerFromContext(r.Context()).Error("failed to encode response", "error", err)
+	}
+}
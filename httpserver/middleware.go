@@ -0,0 +1,380 @@
+package httpserver
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	agenthttp "github.com/plexusone/agentkit/http"
+)
+
+// Middleware wraps an HTTP handler, e.g. to log or authenticate requests.
+type Middleware func(http.Handler) http.Handler
+
+// RequestIDHeader carries the request ID of RequestID.
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDKey is the context key of the request ID.
+type requestIDKey struct{}
+
+// loggerKey is the context key of the server's logger.
+type loggerKey struct{}
+
+// routeKey is the context key of the route AccessLog reports.
+type routeKey struct{}
+
+// LoggerFromContext returns the Logger of the server serving the request,
+// or slog.Default() outside a Server.
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// loggerOr returns logger, or the logger of ctx if it is nil.
+func loggerOr(logger *slog.Logger, ctx context.Context) *slog.Logger {
+	if logger == nil {
+		return LoggerFromContext(ctx)
+	}
+	return logger
+}
+
+// withLogger makes logger available to the handlers of next, see
+// LoggerFromContext.
+func withLogger(logger *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), loggerKey{}, logger)))
+	})
+}
+
+// recordRoute reports the pattern mux matched to AccessLog, which cannot
+// see it when middlewares in between copy the request.
+func recordRoute(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if route, ok := r.Context().Value(routeKey{}).(*string); ok {
+			// Deferred, so panicking routes are reported too.
+			defer func() { *route = r.Pattern }()
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// RequestIDFromContext returns the request ID set by RequestID, or "".
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// RequestID returns a middleware that gives each request an ID: the
+// X-Request-ID header of the request if it is a plausible ID, so IDs
+// follow a request across services, or a new random one. The ID is set
+// on the response header and in the request context, see
+// RequestIDFromContext. Requests that already have an ID in their context
+// keep it.
+//
+// It also sets the correlation ID of the request, see
+// agenthttp.CorrelationID: the X-Correlation-ID header if it is a
+// plausible ID, or else the request ID, which makes this request the
+// start of a new chain of agent calls. It is echoed on the response.
+func RequestID() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if RequestIDFromContext(r.Context()) != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			id := r.Header.Get(RequestIDHeader)
+			if !agenthttp.ValidCorrelationID(id) {
+				b := make([]byte, 16)
+				_, _ = rand.Read(b)
+				id = hex.EncodeToString(b)
+			}
+			w.Header().Set(RequestIDHeader, id)
+			ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+
+			correlationID := agenthttp.CorrelationID(ctx)
+			if correlationID == "" {
+				correlationID = r.Header.Get(agenthttp.CorrelationIDHeader)
+				if !agenthttp.ValidCorrelationID(correlationID) {
+					correlationID = id
+				}
+				ctx = agenthttp.WithCorrelationID(ctx, correlationID)
+			}
+			w.Header().Set(agenthttp.CorrelationIDHeader, correlationID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// requestAttrs returns the log attributes of the request and correlation
+// IDs of ctx.
+func requestAttrs(ctx context.Context) []slog.Attr {
+	var attrs []slog.Attr
+	if id := RequestIDFromContext(ctx); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+	if id := agenthttp.CorrelationID(ctx); id != "" {
+		attrs = append(attrs, slog.String("correlation_id", id))
+	}
+	return attrs
+}
+
+// Recover returns a middleware that turns handler panics into 500
+// Internal Server Error responses, logging the panic with its stack, so
+// one bad request does not take down the server.
+// Default logger: LoggerFromContext
+func Recover(logger *slog.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := &responseWriter{ResponseWriter: w}
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				// The server uses this panic to abort a response on purpose.
+				if err, ok := v.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+					panic(v)
+				}
+				attrs := []slog.Attr{
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+					slog.String("panic", fmt.Sprint(v)),
+					slog.String("stack", string(debug.Stack())),
+				}
+				attrs = append(attrs, requestAttrs(r.Context())...)
+				loggerOr(logger, r.Context()).LogAttrs(r.Context(), slog.LevelError, "http handler panic", attrs...)
+				if rw.status == 0 {
+					http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				}
+			}()
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// AccessLogOptions configures AccessLogWithOptions.
+type AccessLogOptions struct {
+	// Logger receives the log lines.
+	// Default: LoggerFromContext
+	Logger *slog.Logger
+
+	// SampledPaths are paths polled so often, e.g. by load balancer
+	// health checks, that logging every request would drown the others.
+	// Their successful requests are sampled; failures are always logged.
+	// Default: "/health", "/livez", "/readyz", "/ready"
+	SampledPaths []string
+
+	// SampleRate logs one in SampleRate successful requests to each of
+	// SampledPaths. 1 logs them all.
+	// Default: 100
+	SampleRate int
+}
+
+// AccessLog returns a middleware that logs each request with its method,
+// path, route (the pattern of a Server route), status, response size, duration, remote address and, with
+// RequestID in front of it, request ID, sampling health checks as
+// AccessLogWithOptions does by default.
+// Default logger: LoggerFromContext
+func AccessLog(logger *slog.Logger) Middleware {
+	return AccessLogWithOptions(AccessLogOptions{Logger: logger})
+}
+
+// AccessLogWithOptions returns a middleware that logs one line per
+// request, like AccessLog, configured by opts. Requests answered with a
+// 5xx status are logged at Warn.
+func AccessLogWithOptions(opts AccessLogOptions) Middleware {
+	if opts.SampledPaths == nil {
+		opts.SampledPaths = []string{"/health", "/livez", "/readyz", "/ready"}
+	}
+	if opts.SampleRate <= 0 {
+		opts.SampleRate = 100
+	}
+	counters := make(map[string]*atomic.Uint64, len(opts.SampledPaths))
+	for _, path := range opts.SampledPaths {
+		counters[path] = new(atomic.Uint64)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rw := &responseWriter{ResponseWriter: w}
+			var route string
+			next.ServeHTTP(rw, r.WithContext(context.WithValue(r.Context(), routeKey{}, &route)))
+
+			status := rw.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			if counter, ok := counters[r.URL.Path]; ok && status < 400 && opts.SampleRate > 1 {
+				// Log the first request, then one in SampleRate.
+				if (counter.Add(1)-1)%uint64(opts.SampleRate) != 0 {
+					return
+				}
+			}
+			level := slog.LevelInfo
+			if status >= 500 {
+				level = slog.LevelWarn
+			}
+			attrs := []slog.Attr{
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+			}
+			if route != "" {
+				attrs = append(attrs, slog.String("route", route))
+			}
+			attrs = append(attrs,
+				slog.Int("status", status),
+				slog.Int64("bytes", rw.bytes),
+				slog.Duration("duration", time.Since(start)),
+				slog.String("remote", r.RemoteAddr),
+			)
+			attrs = append(attrs, requestAttrs(r.Context())...)
+			loggerOr(opts.Logger, r.Context()).LogAttrs(r.Context(), level, "http request", attrs...)
+		})
+	}
+}
+
+// CORSOptions configures CORS.
+type CORSOptions struct {
+	// AllowedOrigins are the origins allowed to call the server, e.g.
+	// "https://app.example.com". "*" allows any origin.
+	AllowedOrigins []string
+
+	// AllowedMethods are the methods allowed in cross-origin requests.
+	// Default: GET, HEAD, POST, PUT, PATCH, DELETE
+	AllowedMethods []string
+
+	// AllowedHeaders are the request headers allowed in cross-origin
+	// requests.
+	// Default: the headers the preflight request asks for
+	AllowedHeaders []string
+
+	// ExposedHeaders are the response headers browsers let callers read.
+	// Default: none besides the CORS-safelisted ones
+	ExposedHeaders []string
+
+	// AllowCredentials lets browsers send cookies and HTTP auth. It
+	// requires listing the origins, since browsers reject credentials with
+	// "*".
+	// Default: false
+	AllowCredentials bool
+
+	// MaxAge is how long browsers may cache a preflight response.
+	// Default: 0 (the browser's default)
+	MaxAge time.Duration
+}
+
+// CORS returns a middleware that lets browsers on the allowed origins call
+// the server. It answers preflight requests itself and adds the CORS
+// headers to other requests from allowed origins; requests from other
+// origins pass through without them, so browsers block their responses.
+func CORS(opts CORSOptions) Middleware {
+	if len(opts.AllowedMethods) == 0 {
+		opts.AllowedMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	}
+	anyOrigin := slices.Contains(opts.AllowedOrigins, "*")
+	methods := strings.Join(opts.AllowedMethods, ", ")
+	headers := strings.Join(opts.AllowedHeaders, ", ")
+	exposed := strings.Join(opts.ExposedHeaders, ", ")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			h := w.Header()
+			h.Add("Vary", "Origin")
+			if origin == "" || !(anyOrigin || slices.Contains(opts.AllowedOrigins, origin)) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if anyOrigin && !opts.AllowCredentials {
+				h.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				h.Set("Access-Control-Allow-Origin", origin)
+			}
+			if opts.AllowCredentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			if !preflight {
+				if exposed != "" {
+					h.Set("Access-Control-Expose-Headers", exposed)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+			h.Set("Access-Control-Allow-Methods", methods)
+			if headers != "" {
+				h.Set("Access-Control-Allow-Headers", headers)
+			} else if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+				h.Set("Access-Control-Allow-Headers", requested)
+			}
+			if opts.MaxAge > 0 {
+				h.Set("Access-Control-Max-Age", strconv.Itoa(int(opts.MaxAge.Seconds())))
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// responseWriter records the status and size of a response. It passes
+// Flush and Hijack through, so streaming handlers keep working.
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write implements http.ResponseWriter.
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher.
+func (w *responseWriter) Flush() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack implements http.Hijacker.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// Unwrap returns the wrapped writer, for http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,169 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/plexusone/agentkit/orchestration"
+)
+
+// Route is an endpoint registered with its OpenAPI metadata.
+type Route struct {
+	// Method is the HTTP method, e.g. http.MethodPost. Required.
+	Method string
+
+	// Path is the path, with wildcards as in http.ServeMux patterns,
+	// e.g. "/tasks/{id}". Required.
+	Path string
+
+	// Summary and Description document the operation.
+	Summary     string
+	Description string
+
+	// Tags group the operation in documentation tools.
+	Tags []string
+
+	// RequestSchema and ResponseSchema are the JSON Schemas of the
+	// request and response bodies, or nil if they have none.
+	RequestSchema  json.RawMessage
+	ResponseSchema json.RawMessage
+
+	// Handler serves the route. Required.
+	Handler http.Handler
+}
+
+// JSONRoute returns a route that serves fn with JSONHandler, documented
+// with the JSON Schemas of Req and Resp. jsonschema struct tags add
+// descriptions and constraints.
+//
+//	server, _ := httpserver.NewBuilder("research", 8001).
+//	    WithRoute(httpserver.JSONRoute(http.MethodPost, "/research",
+//	        "Researches a topic", agent.Research)).
+//	    Build()
+func JSONRoute[Req, Resp any](method, path, summary string, fn func(context.Context, Req) (Resp, error)) Route {
+	route := Route{
+		Method:         method,
+		Path:           path,
+		Summary:        summary,
+		ResponseSchema: orchestration.SchemaOf[Resp](),
+		Handler:        JSONHandler(fn),
+	}
+	if reflect.TypeFor[Req]() != reflect.TypeFor[struct{}]() {
+		route.RequestSchema = orchestration.SchemaOf[Req]()
+	}
+	return route
+}
+
+// pattern returns the http.ServeMux pattern of r.
+func (r Route) pattern() string {
+	if r.Method == "" {
+		return r.Path
+	}
+	return r.Method + " " + r.Path
+}
+
+// wildcardPattern matches the wildcards of a path, e.g. "{id}" or
+// "{rest...}".
+var wildcardPattern = regexp.MustCompile(`\{([^}.]+)(\.\.\.)?\}`)
+
+// OpenAPIHandler returns a handler that serves the OpenAPI 3.1 document of
+// routes as JSON, titled with title and version.
+func OpenAPIHandler(title, version string, routes []Route) http.HandlerFunc {
+	doc, err := json.Marshal(openAPIDocument(title, version, routes))
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err != nil {
+			writeJSONError(w, r, http.StatusInternalServerError, "encoding OpenAPI document: "+err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(doc)
+	}
+}
+
+// openAPIDocument builds the OpenAPI 3.1 document of routes. Errors are
+// documented with the {"error": "..."} body of JSONHandler.
+func openAPIDocument(title, version string, routes []Route) map[string]interface{} {
+	errorResponse := map[string]interface{}{
+		"description": "Error",
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{
+				"schema": map[string]interface{}{
+					"type":       "object",
+					"properties": map[string]interface{}{"error": map[string]interface{}{"type": "string"}},
+				},
+			},
+		},
+	}
+
+	sorted := append([]Route(nil), routes...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Path < sorted[j].Path })
+	paths := make(map[string]interface{})
+	for _, route := range sorted {
+		path := wildcardPattern.ReplaceAllString(route.Path, "{$1}")
+		item, _ := paths[path].(map[string]interface{})
+		if item == nil {
+			item = make(map[string]interface{})
+			paths[path] = item
+		}
+
+		op := map[string]interface{}{}
+		if route.Summary != "" {
+			op["summary"] = route.Summary
+		}
+		if route.Description != "" {
+			op["description"] = route.Description
+		}
+		if len(route.Tags) > 0 {
+			op["tags"] = route.Tags
+		}
+		var params []interface{}
+		for _, m := range wildcardPattern.FindAllStringSubmatch(route.Path, -1) {
+			params = append(params, map[string]interface{}{
+				"name":     m[1],
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]interface{}{"type": "string"},
+			})
+		}
+		if params != nil {
+			op["parameters"] = params
+		}
+		if route.RequestSchema != nil {
+			op["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": route.RequestSchema},
+				},
+			}
+		}
+		ok := map[string]interface{}{"description": "OK"}
+		if route.ResponseSchema != nil {
+			ok["content"] = map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": route.ResponseSchema},
+			}
+		}
+		op["responses"] = map[string]interface{}{"200": ok, "default": errorResponse}
+
+		// Routes without a method serve every method; document the usual
+		// one for their body.
+		method := strings.ToLower(route.Method)
+		if method == "" {
+			method = "get"
+			if route.RequestSchema != nil {
+				method = "post"
+			}
+		}
+		item[method] = op
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.1.0",
+		"info":    map[string]interface{}{"title": title, "version": version},
+		"paths":   paths,
+	}
+}
@@ -4,10 +4,17 @@ package httpserver
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+	"syscall"
 	"time"
 )
 
@@ -27,6 +34,19 @@ type Config struct {
 	// Example: {"/research": agent.HandleResearchRequest}
 	HandlerFuncs map[string]http.HandlerFunc
 
+	// Routes are endpoints registered with their OpenAPI metadata, e.g.
+	// with JSONRoute. They are documented at OpenAPIPath.
+	Routes []Route
+
+	// OpenAPIPath serves the OpenAPI document of Routes, when there are
+	// any.
+	// Default is "/openapi.json".
+	OpenAPIPath string
+
+	// APIVersion is the version of the API in the OpenAPI document.
+	// Default is "1.0.0".
+	APIVersion string
+
 	// ReadTimeout is the maximum duration for reading the entire request.
 	// Default is 30 seconds.
 	ReadTimeout time.Duration
@@ -39,6 +59,17 @@ type Config struct {
 	// Default is 60 seconds.
 	IdleTimeout time.Duration
 
+	// RouteTimeouts overrides ReadTimeout and WriteTimeout, and bounds the
+	// handler, for the routes registered at a path: a key of Handlers or
+	// HandlerFuncs, the Path of Routes, or the health and readiness paths.
+	// Example: {"/health": {Write: 10 * time.Second}, "/synthesize": {Write: 10 * time.Minute}}
+	RouteTimeouts map[string]Timeouts
+
+	// ShutdownTimeout is how long Run waits for in-flight requests to
+	// finish when shutting down, before closing their connections.
+	// Default is 30 seconds.
+	ShutdownTimeout time.Duration
+
 	// HealthPath is the path for the health check endpoint.
 	// Default is "/health".
 	HealthPath string
@@ -47,11 +78,54 @@ type Config struct {
 	// If nil, a simple "OK" response handler is used.
 	HealthHandler http.HandlerFunc
 
+	// LivePath serves liveness for orchestrators: 200 OK while the process
+	// serves HTTP, whatever its dependencies, so a restart is only
+	// triggered by a wedged process.
+	// Default is "/livez".
+	LivePath string
+
+	// ReadyPath is the path for the readiness endpoint. The default path
+	// is also served at "/ready", where earlier versions served it.
+	// Default is "/readyz".
+	ReadyPath string
+
+	// ReadyChecks are run by the readiness endpoint, keyed by name. The
+	// endpoint responds 503 Service Unavailable if any check fails, so a
+	// load balancer holds traffic until dependencies such as the secrets
+	// provider (config.SecretsClient.ReadyCheck), the model
+	// (a2a.ModelCheck) or downstream agents (a2a.AgentCheck) are usable.
+	ReadyChecks map[string]ReadyCheck
+
+	// ReadyTimeout bounds each readiness check. Checks run concurrently.
+	// Default is 5 seconds.
+	ReadyTimeout time.Duration
+
 	// EnableDualModeLog logs a message about dual HTTP/A2A mode.
 	// Default is false.
 	EnableDualModeLog bool
+
+	// Logger receives the server's logs. Handlers and middlewares get it
+	// from LoggerFromContext.
+	// Default is slog.Default().
+	Logger *slog.Logger
+
+	// AccessLog, when set, logs one line per request (see
+	// AccessLogWithOptions), giving each request an ID first (see
+	// RequestID). It wraps the Middlewares.
+	// Default is nil (no access log).
+	AccessLog *AccessLogOptions
+
+	// Middlewares wrap every route, including the health and readiness
+	// endpoints. The first is the outermost, so it sees requests first,
+	// e.g. RequestID, AccessLog, Recover, which logs panics with their
+	// request ID and the 500 responses Recover sends.
+	Middlewares []Middleware
 }
 
+// ReadyCheck reports whether a dependency is ready, e.g.
+// config.SecretsClient.Ready.
+type ReadyCheck func(ctx context.Context) error
+
 // Server wraps an HTTP server with convenient lifecycle methods.
 type Server struct {
 	httpServer *http.Server
@@ -79,31 +153,93 @@ func New(cfg Config) (*Server, error) {
 	if cfg.IdleTimeout == 0 {
 		cfg.IdleTimeout = 60 * time.Second
 	}
+	if cfg.ShutdownTimeout == 0 {
+		cfg.ShutdownTimeout = 30 * time.Second
+	}
 	if cfg.HealthPath == "" {
 		cfg.HealthPath = "/health"
 	}
 	if cfg.HealthHandler == nil {
 		cfg.HealthHandler = defaultHealthHandler
 	}
+	if cfg.LivePath == "" {
+		cfg.LivePath = "/livez"
+	}
+	if cfg.ReadyPath == "" {
+		cfg.ReadyPath = "/readyz"
+	}
+	if cfg.ReadyTimeout == 0 {
+		cfg.ReadyTimeout = 5 * time.Second
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	if cfg.OpenAPIPath == "" {
+		cfg.OpenAPIPath = "/openapi.json"
+	}
+	if cfg.APIVersion == "" {
+		cfg.APIVersion = "1.0.0"
+	}
+	for _, route := range cfg.Routes {
+		if route.Path == "" || route.Handler == nil {
+			return nil, fmt.Errorf("route %q requires a path and a handler", route.pattern())
+		}
+	}
 
 	// Build mux
 	mux := http.NewServeMux()
 
-	// Register handlers
+	// Register handlers, with their route timeouts
+	handle := func(pattern, path string, handler http.Handler) {
+		mux.Handle(pattern, cfg.RouteTimeouts[path].wrap(handler))
+	}
 	for path, handler := range cfg.Handlers {
-		mux.Handle(path, handler)
+		handle(path, path, handler)
 	}
 	for path, handlerFunc := range cfg.HandlerFuncs {
-		mux.HandleFunc(path, handlerFunc)
+		handle(path, path, handlerFunc)
+	}
+	for _, route := range cfg.Routes {
+		handle(route.pattern(), route.Path, route.Handler)
 	}
 
-	// Register health check
-	mux.HandleFunc(cfg.HealthPath, cfg.HealthHandler)
+	// Register health, liveness and readiness checks, unless a handler
+	// takes their path
+	taken := func(path string) bool {
+		_, handler := cfg.Handlers[path]
+		_, handlerFunc := cfg.HandlerFuncs[path]
+		return handler || handlerFunc || slices.ContainsFunc(cfg.Routes, func(route Route) bool {
+			return route.Path == path
+		})
+	}
+	if len(cfg.Routes) > 0 && !taken(cfg.OpenAPIPath) {
+		handle(cfg.OpenAPIPath, cfg.OpenAPIPath, OpenAPIHandler(cfg.Name, cfg.APIVersion, cfg.Routes))
+	}
+	handle(cfg.HealthPath, cfg.HealthPath, cfg.HealthHandler)
+	if cfg.LivePath != cfg.HealthPath && !taken(cfg.LivePath) {
+		handle(cfg.LivePath, cfg.LivePath, http.HandlerFunc(defaultHealthHandler))
+	}
+	ready := ReadyHandler(cfg.ReadyChecks, cfg.ReadyTimeout)
+	if !taken(cfg.ReadyPath) {
+		handle(cfg.ReadyPath, cfg.ReadyPath, ready)
+	}
+	if cfg.ReadyPath == "/readyz" && !taken("/ready") && cfg.HealthPath != "/ready" {
+		handle("/ready", "/ready", ready)
+	}
+
+	handler := recordRoute(mux)
+	for i := len(cfg.Middlewares) - 1; i >= 0; i-- {
+		handler = cfg.Middlewares[i](handler)
+	}
+	if cfg.AccessLog != nil {
+		handler = RequestID()(AccessLogWithOptions(*cfg.AccessLog)(handler))
+	}
+	handler = withLogger(cfg.Logger, handler)
 
 	addr := fmt.Sprintf(":%d", cfg.Port)
 	httpServer := &http.Server{
 		Addr:         addr,
-		Handler:      mux,
+		Handler:      handler,
 		ReadTimeout:  cfg.ReadTimeout,
 		WriteTimeout: cfg.WriteTimeout,
 		IdleTimeout:  cfg.IdleTimeout,
@@ -119,15 +255,56 @@ func New(cfg Config) (*Server, error) {
 func defaultHealthHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	if _, err := w.Write([]byte("OK")); err != nil {
-		log.Printf("Failed to write health response: %v", err)
+		LoggerFromContext(r.Context()).Error("failed to write health response", "error", err)
+	}
+}
+
+// ReadyHandler runs the readiness checks concurrently, each bounded by
+// timeout, and reports their results as JSON, e.g.
+// {"status":"unavailable","checks":{"secrets":"access denied"}}. It
+// responds 503 Service Unavailable if any check fails.
+func ReadyHandler(checks map[string]ReadyCheck, timeout time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		results := make(map[string]string, len(checks))
+		status, code := "ready", http.StatusOK
+		var (
+			mu sync.Mutex
+			wg sync.WaitGroup
+		)
+		for name, check := range checks {
+			wg.Go(func() {
+				ctx, cancel := context.WithTimeout(r.Context(), timeout)
+				err := check(ctx)
+				cancel()
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					results[name] = err.Error()
+					status, code = "unavailable", http.StatusServiceUnavailable
+					return
+				}
+				results[name] = "ok"
+			})
+		}
+		wg.Wait()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		if err := json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": status,
+			"checks": results,
+		}); err != nil {
+			LoggerFromContext(r.Context()).Error("failed to write readiness response", "error", err)
+		}
 	}
 }
 
 // Start starts the HTTP server. This method blocks until the server is stopped.
 func (s *Server) Start() error {
-	log.Printf("[HTTP] %s server starting on %s", s.config.Name, s.httpServer.Addr)
+	s.config.Logger.Info("http server starting", "name", s.config.Name, "addr", s.httpServer.Addr)
 	if s.config.EnableDualModeLog {
-		log.Printf("[HTTP] (Dual mode: HTTP for security/observability, A2A for interoperability)")
+		s.config.Logger.Info("http server in dual mode: HTTP for security/observability, A2A for interoperability", "name", s.config.Name)
 	}
 
 	return s.httpServer.ListenAndServe()
@@ -138,7 +315,7 @@ func (s *Server) Start() error {
 func (s *Server) StartAsync() {
 	go func() {
 		if err := s.Start(); err != nil && err != http.ErrServerClosed {
-			log.Printf("[HTTP] %s server error: %v", s.config.Name, err)
+			s.config.Logger.Error("http server failed", "name", s.config.Name, "error", err)
 		}
 	}()
 }
@@ -147,10 +324,54 @@ func (s *Server) StartAsync() {
 // Useful for testing or when you need control over the listener.
 func (s *Server) StartWithListener(listener net.Listener) error {
 	s.listener = listener
-	log.Printf("[HTTP] %s server starting on %s", s.config.Name, listener.Addr().String())
+	s.config.Logger.Info("http server starting", "name", s.config.Name, "addr", listener.Addr().String())
 	return s.httpServer.Serve(listener)
 }
 
+// Run starts the server and blocks until ctx is done or the process
+// receives SIGINT or SIGTERM, then shuts down gracefully, waiting up to
+// ShutdownTimeout for in-flight requests. It returns nil after a clean
+// shutdown, and an error if the server fails or requests outlast the
+// timeout. A second signal during shutdown terminates the process.
+//
+//	if err := server.Run(context.Background()); err != nil {
+//	    log.Fatal(err)
+//	}
+func (s *Server) Run(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.Start()
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return fmt.Errorf("%s server: %w", s.config.Name, err)
+	case <-ctx.Done():
+	}
+	// Restore default signal handling, so a second signal kills the
+	// process if shutdown hangs.
+	stop()
+
+	s.config.Logger.Info("http server shutting down", "name", s.config.Name, "timeout", s.config.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
+	defer cancel()
+	if err := s.httpServer.Shutdown(shutdownCtx); err != nil {
+		_ = s.httpServer.Close()
+		return fmt.Errorf("%s server shutdown: %w", s.config.Name, err)
+	}
+	if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("%s server: %w", s.config.Name, err)
+	}
+	s.config.Logger.Info("http server stopped", "name", s.config.Name)
+	return nil
+}
+
 // Stop gracefully shuts down the server.
 func (s *Server) Stop(ctx context.Context) error {
 	return s.httpServer.Shutdown(ctx)
@@ -190,6 +411,13 @@ func (b *Builder) WithHandlerFunc(path string, handler http.HandlerFunc) *Builde
 	return b
 }
 
+// WithRoute adds a route documented in the OpenAPI document, e.g. a
+// JSONRoute.
+func (b *Builder) WithRoute(route Route) *Builder {
+	b.config.Routes = append(b.config.Routes, route)
+	return b
+}
+
 // WithTimeouts sets all timeouts.
 func (b *Builder) WithTimeouts(read, write, idle time.Duration) *Builder {
 	b.config.ReadTimeout = read
@@ -198,18 +426,62 @@ func (b *Builder) WithTimeouts(read, write, idle time.Duration) *Builder {
 	return b
 }
 
+// WithRouteTimeouts overrides the timeouts of the routes at path, see
+// Config.RouteTimeouts.
+func (b *Builder) WithRouteTimeouts(path string, timeouts Timeouts) *Builder {
+	if b.config.RouteTimeouts == nil {
+		b.config.RouteTimeouts = make(map[string]Timeouts)
+	}
+	b.config.RouteTimeouts[path] = timeouts
+	return b
+}
+
+// WithShutdownTimeout sets how long Run waits for in-flight requests when
+// shutting down.
+func (b *Builder) WithShutdownTimeout(timeout time.Duration) *Builder {
+	b.config.ShutdownTimeout = timeout
+	return b
+}
+
 // WithDualModeLog enables the dual mode log message.
 func (b *Builder) WithDualModeLog() *Builder {
 	b.config.EnableDualModeLog = true
 	return b
 }
 
+// WithLogger sets the server's logger.
+func (b *Builder) WithLogger(logger *slog.Logger) *Builder {
+	b.config.Logger = logger
+	return b
+}
+
+// WithAccessLog logs one line per request, see Config.AccessLog.
+func (b *Builder) WithAccessLog(opts AccessLogOptions) *Builder {
+	b.config.AccessLog = &opts
+	return b
+}
+
 // WithHealthHandler sets a custom health check handler.
 func (b *Builder) WithHealthHandler(handler http.HandlerFunc) *Builder {
 	b.config.HealthHandler = handler
 	return b
 }
 
+// WithReadyCheck adds a named readiness check.
+func (b *Builder) WithReadyCheck(name string, check ReadyCheck) *Builder {
+	if b.config.ReadyChecks == nil {
+		b.config.ReadyChecks = make(map[string]ReadyCheck)
+	}
+	b.config.ReadyChecks[name] = check
+	return b
+}
+
+// Use appends middlewares that wrap every route.
+func (b *Builder) Use(middlewares ...Middleware) *Builder {
+	b.config.Middlewares = append(b.config.Middlewares, middlewares...)
+	return b
+}
+
 // Build creates the server.
 func (b *Builder) Build() (*Server, error) {
 	return New(b.config)
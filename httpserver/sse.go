@@ -0,0 +1,223 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/plexusone/agentkit/orchestration"
+)
+
+// SSE event names SSEHandler ends streams with, as orchestration's
+// StreamHandler does.
+const (
+	// SSEDone ends a successful stream, with {} as data.
+	SSEDone = "done"
+
+	// SSEError ends a failed stream, with {"error": "..."} as data.
+	SSEError = "error"
+)
+
+// SSEOptions configures SSEHandler.
+type SSEOptions struct {
+	// KeepAlive is how often a comment is sent while no events are, so
+	// proxies do not close idle streams.
+	// Default: 15 seconds
+	KeepAlive time.Duration
+
+	// Retry tells browsers how long to wait before reconnecting a dropped
+	// stream.
+	// Default: 0 (the browser's default)
+	Retry time.Duration
+}
+
+// SSEStream sends Server-Sent Events to one client. Its methods are safe
+// for concurrent use.
+type SSEStream struct {
+	mu     sync.Mutex
+	w      http.ResponseWriter
+	rc     *http.ResponseController
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// Context is done when the client disconnects or a write fails; stop
+// producing events then.
+func (s *SSEStream) Context() context.Context {
+	return s.ctx
+}
+
+// Send sends an event with data encoded as JSON and flushes it. An empty
+// event name sends a default "message" event. It returns an error once
+// the client is gone.
+func (s *SSEStream) Send(event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("encoding event: %w", err)
+	}
+	var b strings.Builder
+	if event != "" {
+		fmt.Fprintf(&b, "event: %s\n", event)
+	}
+	fmt.Fprintf(&b, "data: %s\n\n", payload)
+	return s.write(b.String())
+}
+
+// comment sends an SSE comment, which clients ignore.
+func (s *SSEStream) comment(text string) error {
+	return s.write(": " + text + "\n\n")
+}
+
+// write writes and flushes raw event text, canceling the stream's context
+// if the client is gone.
+func (s *SSEStream) write(text string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	if _, err := s.w.Write([]byte(text)); err != nil {
+		s.cancel()
+		return err
+	}
+	if err := s.rc.Flush(); err != nil {
+		s.cancel()
+		return err
+	}
+	return nil
+}
+
+// SSEHandler returns a handler that streams the events fn sends as
+// Server-Sent Events. It sets the event-stream headers, flushes every
+// event, sends keep-alive comments, and cancels the stream's context when
+// the client disconnects. When fn returns, the stream ends with an
+// SSEDone event, or an SSEError event if fn failed.
+//
+//	httpserver.SSEHandler(func(r *http.Request, stream *httpserver.SSEStream) error {
+//	    for token := range agent.Tokens(stream.Context(), r.URL.Query().Get("q")) {
+//	        if err := stream.Send("", token); err != nil {
+//	            return err
+//	        }
+//	    }
+//	    return nil
+//	}, httpserver.SSEOptions{})
+func SSEHandler(fn func(r *http.Request, stream *SSEStream) error, opts SSEOptions) http.HandlerFunc {
+	if opts.KeepAlive <= 0 {
+		opts.KeepAlive = 15 * time.Second
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		rc := http.NewResponseController(w)
+		h := w.Header()
+		h.Set("Content-Type", "text/event-stream")
+		h.Set("Cache-Control", "no-cache")
+		h.Set("Connection", "keep-alive")
+		h.Set("X-Accel-Buffering", "no") // Stop nginx from buffering the stream
+		w.WriteHeader(http.StatusOK)
+		if err := rc.Flush(); err != nil {
+			LoggerFromContext(r.Context()).Error("streaming not supported", "error", err)
+			return
+		}
+		// Streams outlive the server's write timeout.
+		_ = rc.SetWriteDeadline(time.Time{})
+
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		stream := &SSEStream{w: w, rc: rc, ctx: ctx, cancel: cancel}
+		if opts.Retry > 0 {
+			_ = stream.write(fmt.Sprintf("retry: %d\n\n", opts.Retry.Milliseconds()))
+		}
+
+		var wg sync.WaitGroup
+		stopKeepAlive := make(chan struct{})
+		wg.Go(func() {
+			ticker := time.NewTicker(opts.KeepAlive)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					_ = stream.comment("keep-alive")
+				case <-stopKeepAlive:
+					return
+				case <-ctx.Done():
+					return
+				}
+			}
+		})
+
+		err := fn(r.WithContext(ctx), stream)
+		close(stopKeepAlive)
+		wg.Wait()
+
+		switch {
+		case ctx.Err() != nil:
+			// The client is gone.
+		case err != nil:
+			_ = stream.Send(SSEError, map[string]string{"error": err.Error()})
+		default:
+			_ = stream.Send(SSEDone, struct{}{})
+		}
+	}
+}
+
+// SendChannel sends each value received from ch as a message event until
+// ch is closed or the stream's context is done. Values that are errors
+// end the stream with that error.
+func SendChannel[T any](stream *SSEStream, ch <-chan T) error {
+	for {
+		select {
+		case v, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if err, isErr := any(v).(error); isErr {
+				return err
+			}
+			if err := stream.Send("", v); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+// SSEChunks returns a handler that decodes the request like JSONHandler,
+// starts a streaming executor with it, e.g. orchestration.Executor.Stream,
+// and streams each chunk's value as a message event, ending with SSEDone,
+// or SSEError with the chunk's Err if the execution fails.
+//
+//	mux.Handle("POST /research/stream", httpserver.SSEChunks(executor.Stream, httpserver.SSEOptions{}))
+func SSEChunks[I, O any](stream func(ctx context.Context, input I) (<-chan orchestration.Chunk[O], error), opts SSEOptions) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		input, ok := decodeRequest[I](w, r)
+		if !ok {
+			return
+		}
+		SSEHandler(func(r *http.Request, sse *SSEStream) error {
+			chunks, err := stream(r.Context(), input)
+			if err != nil {
+				return err
+			}
+			for {
+				select {
+				case chunk, ok := <-chunks:
+					if !ok {
+						return nil
+					}
+					if chunk.Err != nil {
+						return chunk.Err
+					}
+					if err := sse.Send("", chunk.Value); err != nil {
+						return err
+					}
+				case <-r.Context().Done():
+					return r.Context().Err()
+				}
+			}
+		}, opts)(w, r)
+	}
+}
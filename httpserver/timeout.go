@@ -0,0 +1,56 @@
+package httpserver
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Timeouts overrides the server-wide timeouts for one route, e.g. 10
+// seconds for a health check and 10 minutes for a long synthesis, so the
+// server's WriteTimeout need not be the longest any endpoint needs. Zero
+// fields keep the server's timeouts; negative ones disable the timeout.
+type Timeouts struct {
+	// Read bounds reading the request body, from when the handler starts.
+	Read time.Duration
+
+	// Write bounds writing the response, from when the handler starts.
+	Write time.Duration
+
+	// Handler bounds the request's context, so the handler's work is
+	// canceled when it runs over. JSONHandler answers such requests 504
+	// Gateway Timeout.
+	Handler time.Duration
+}
+
+// wrap returns h with the timeouts applied, or h itself if there are
+// none.
+func (t Timeouts) wrap(h http.Handler) http.Handler {
+	if t == (Timeouts{}) {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rc := http.NewResponseController(w)
+		if t.Read != 0 {
+			_ = rc.SetReadDeadline(deadline(t.Read))
+		}
+		if t.Write != 0 {
+			_ = rc.SetWriteDeadline(deadline(t.Write))
+		}
+		if t.Handler > 0 {
+			ctx, cancel := context.WithTimeout(r.Context(), t.Handler)
+			defer cancel()
+			r = r.WithContext(ctx)
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// deadline returns the deadline d from now, or no deadline if d is
+// negative.
+func deadline(d time.Duration) time.Time {
+	if d < 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(d)
+}
@@ -0,0 +1,96 @@
+// Package awscli runs aws CLI commands for the config sources, stores,
+// queues and deployment hooks that reach AWS services without the AWS
+// SDK. The aws CLI must be
+// installed and have credentials for the resources used.
+package awscli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Run runs an aws CLI command, e.g. "ssm", "get-parameter", and returns
+// its JSON output. Errors carry the CLI's error message.
+func Run(ctx context.Context, args ...string) ([]byte, error) {
+	return run(ctx, "json", args)
+}
+
+// RunText runs an aws CLI command like Run and returns its text output,
+// e.g. the value selected with --query.
+func RunText(ctx context.Context, args ...string) ([]byte, error) {
+	return run(ctx, "text", args)
+}
+
+// RunOutfile runs an aws CLI command like Run whose response is written
+// to an output file, such as "s3api get-object" or "lambda invoke". The
+// file is a temporary one passed as the last argument. RunOutfile returns
+// the response and the command's JSON output.
+func RunOutfile(ctx context.Context, args ...string) (response, out []byte, err error) {
+	dir, err := os.MkdirTemp("", "agentkit-aws-")
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	outfile := filepath.Join(dir, "response")
+
+	out, err = Run(ctx, append(args, outfile)...)
+	if err != nil {
+		return nil, nil, err
+	}
+	response, err = os.ReadFile(outfile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("aws %s %s: reading response: %w", args[0], args[1], err)
+	}
+	return response, out, nil
+}
+
+// run runs an aws CLI command with the output format output.
+func run(ctx context.Context, output string, args []string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "aws", append(args, "--output", output)...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, fmt.Errorf("aws %s %s: aws CLI not installed: %w", args[0], args[1], err)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("aws %s %s: %s", args[0], args[1], msg)
+		}
+		return nil, fmt.Errorf("aws %s %s: %w", args[0], args[1], err)
+	}
+	return out, nil
+}
+
+// RunInput runs an aws CLI command like Run, with the parameters of input,
+// e.g. {"TableName": ..., "Item": ...}, encoded as JSON in the request
+// syntax of the service API. The parameters are read from a temporary
+// file with --cli-input-json rather than passed as arguments, since Linux
+// limits each argument to 128 KB, well below the size of DynamoDB items
+// and SQS messages.
+func RunInput(ctx context.Context, input any, args ...string) ([]byte, error) {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("aws %s %s: encoding input: %w", args[0], args[1], err)
+	}
+	tmp, err := os.CreateTemp("", "agentkit-aws-*.json")
+	if err != nil {
+		return nil, fmt.Errorf("creating temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return nil, fmt.Errorf("writing temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("writing temp file: %w", err)
+	}
+	return Run(ctx, append(args, "--cli-input-json", "file://"+tmp.Name())...)
+}
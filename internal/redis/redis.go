@@ -0,0 +1,251 @@
+// Package redis is a minimal Redis client for the stores that keep state
+// in Redis: it pipelines commands over pooled connections, optionally
+// over TLS, and decodes RESP replies.
+package redis
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// maxIdle is the number of idle connections a Pool keeps.
+const maxIdle = 8
+
+// Options are the server and credentials of a connection.
+type Options struct {
+	// Addr is the host:port of the Redis server.
+	Addr string
+
+	// Password authenticates with the server when set.
+	Password string
+
+	// DB is the database number to select.
+	DB int
+
+	// TLS enables TLS with the given configuration when set.
+	TLS *tls.Config
+}
+
+// Error is an error reply of the server.
+type Error string
+
+// Error implements error.
+func (e Error) Error() string { return string(e) }
+
+// Pool runs commands on pooled connections. Connections authenticate and
+// select their database once, when opened, and are reused by later calls
+// with the same options. The zero value is ready to use and a Pool must not
+// be copied after first use.
+type Pool struct {
+	mu   sync.Mutex
+	idle []*conn
+}
+
+// conn is a connection and the options it was opened with.
+type conn struct {
+	net.Conn
+	opts Options
+	r    *bufio.Reader
+	w    *bufio.Writer
+}
+
+// Do pipelines cmds to the server of opts and returns their replies. Bulk
+// strings, simple strings and integers are returned as []byte, null
+// replies as nil and arrays as []any. If a command fails, Do returns its
+// error reply, as an Error, wrapped with the command name.
+func (p *Pool) Do(ctx context.Context, opts Options, cmds ...[]string) ([]any, error) {
+	c, reused, err := p.get(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+	replies, err := c.do(ctx, cmds)
+	if err != nil && reused && isClosed(err) {
+		// The server closed the idle connection; commands were not run.
+		_ = c.Close()
+		if c, err = p.dial(ctx, opts); err != nil {
+			return nil, fmt.Errorf("redis: %w", err)
+		}
+		replies, err = c.do(ctx, cmds)
+	}
+	if err != nil {
+		_ = c.Close()
+		return nil, fmt.Errorf("redis %s: %w", cmds[0][0], err)
+	}
+	p.put(c)
+
+	for i, reply := range replies {
+		if e, ok := reply.(Error); ok {
+			return nil, fmt.Errorf("redis %s: %w", cmds[i][0], e)
+		}
+	}
+	return replies, nil
+}
+
+// Close closes the idle connections of the pool.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	idle := p.idle
+	p.idle = nil
+	p.mu.Unlock()
+	for _, c := range idle {
+		_ = c.Close()
+	}
+	return nil
+}
+
+// get returns an idle connection for opts, reporting true, or a new one.
+func (p *Pool) get(ctx context.Context, opts Options) (*conn, bool, error) {
+	p.mu.Lock()
+	for len(p.idle) > 0 {
+		c := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+		if c.opts == opts {
+			p.mu.Unlock()
+			return c, true, nil
+		}
+		_ = c.Close()
+	}
+	p.mu.Unlock()
+	c, err := p.dial(ctx, opts)
+	return c, false, err
+}
+
+// put returns a connection to the pool, or closes it if the pool is full.
+func (p *Pool) put(c *conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.idle) >= maxIdle {
+		_ = c.Close()
+		return
+	}
+	p.idle = append(p.idle, c)
+}
+
+// dial opens a connection, authenticating and selecting the database.
+func (p *Pool) dial(ctx context.Context, opts Options) (*conn, error) {
+	var nc net.Conn
+	var err error
+	if opts.TLS != nil {
+		dialer := tls.Dialer{Config: opts.TLS}
+		nc, err = dialer.DialContext(ctx, "tcp", opts.Addr)
+	} else {
+		var dialer net.Dialer
+		nc, err = dialer.DialContext(ctx, "tcp", opts.Addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c := &conn{Conn: nc, opts: opts, r: bufio.NewReader(nc), w: bufio.NewWriter(nc)}
+
+	var setup [][]string
+	if opts.Password != "" {
+		setup = append(setup, []string{"AUTH", opts.Password})
+	}
+	if opts.DB != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(opts.DB)})
+	}
+	if len(setup) == 0 {
+		return c, nil
+	}
+	replies, err := c.do(ctx, setup)
+	if err == nil {
+		for i, reply := range replies {
+			if e, ok := reply.(Error); ok {
+				err = fmt.Errorf("%s: %w", setup[i][0], e)
+				break
+			}
+		}
+	}
+	if err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// do writes cmds and reads all their replies, so the connection stays in
+// step with the server even if some fail.
+func (c *conn) do(ctx context.Context, cmds [][]string) ([]any, error) {
+	deadline, _ := ctx.Deadline()
+	_ = c.SetDeadline(deadline)
+	defer c.SetDeadline(time.Time{})
+
+	for _, cmd := range cmds {
+		fmt.Fprintf(c.w, "*%d\r\n", len(cmd))
+		for _, arg := range cmd {
+			fmt.Fprintf(c.w, "$%d\r\n%s\r\n", len(arg), arg)
+		}
+	}
+	if err := c.w.Flush(); err != nil {
+		return nil, err
+	}
+	replies := make([]any, len(cmds))
+	for i := range cmds {
+		reply, err := readReply(c.r)
+		if err != nil {
+			return nil, err
+		}
+		replies[i] = reply
+	}
+	return replies, nil
+}
+
+// isClosed reports whether err is the failure of a connection the server
+// closed before reading the commands.
+func isClosed(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE)
+}
+
+// readReply reads one RESP reply. Simple strings, integers and bulk
+// strings are returned as []byte, null replies as nil, arrays as []any,
+// and error replies as Error.
+func readReply(r *bufio.Reader) (any, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("malformed reply %q", line)
+	}
+	kind, body := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+', ':':
+		return []byte(body), nil
+	case '-':
+		return Error(body), nil
+	case '$', '*':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, fmt.Errorf("malformed reply %q", line)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		if kind == '*' {
+			items := make([]any, n)
+			for i := range items {
+				if items[i], err = readReply(r); err != nil {
+					return nil, err
+				}
+			}
+			return items, nil
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		return data[:n], nil
+	default:
+		return nil, fmt.Errorf("unexpected reply %q", line)
+	}
+}
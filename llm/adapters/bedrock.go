@@ -0,0 +1,219 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/plexusone/omnillm/provider"
+)
+
+// BedrockProvider is an OmniLLM provider for the AWS Bedrock Converse API,
+// authenticated with a Bedrock API key. Use it as
+// OmniLLMAdapterConfig.Provider.
+type BedrockProvider struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+// NewBedrockProvider creates a Bedrock provider for the runtime endpoint of
+// region. baseURL overrides the endpoint, e.g. for a VPC endpoint.
+func NewBedrockProvider(apiKey, region, baseURL string) (*BedrockProvider, error) {
+	if apiKey == "" {
+		return nil, errors.New("bedrock API key is required")
+	}
+	if baseURL == "" {
+		if region == "" {
+			return nil, errors.New("bedrock region is required")
+		}
+		baseURL = "https://bedrock-runtime." + region + ".amazonaws.com"
+	}
+	return &BedrockProvider{
+		apiKey:  apiKey,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: 5 * time.Minute},
+	}, nil
+}
+
+// Name implements provider.Provider.
+func (p *BedrockProvider) Name() string {
+	return "bedrock"
+}
+
+// Close implements provider.Provider.
+func (p *BedrockProvider) Close() error {
+	return nil
+}
+
+// converseRequest is the body of a Converse request.
+type converseRequest struct {
+	Messages        []converseMessage        `json:"messages"`
+	System          []converseContent        `json:"system,omitempty"`
+	InferenceConfig *converseInferenceConfig `json:"inferenceConfig,omitempty"`
+}
+
+type converseMessage struct {
+	Role    string            `json:"role"`
+	Content []converseContent `json:"content"`
+}
+
+type converseContent struct {
+	Text string `json:"text"`
+}
+
+type converseInferenceConfig struct {
+	MaxTokens     *int     `json:"maxTokens,omitempty"`
+	Temperature   *float64 `json:"temperature,omitempty"`
+	TopP          *float64 `json:"topP,omitempty"`
+	StopSequences []string `json:"stopSequences,omitempty"`
+}
+
+// converseResponse is the body of a Converse response.
+type converseResponse struct {
+	Output struct {
+		Message converseMessage `json:"message"`
+	} `json:"output"`
+	StopReason string `json:"stopReason"`
+	Usage      struct {
+		InputTokens  int `json:"inputTokens"`
+		OutputTokens int `json:"outputTokens"`
+		TotalTokens  int `json:"totalTokens"`
+	} `json:"usage"`
+}
+
+// CreateChatCompletion implements provider.Provider with the Converse API.
+func (p *BedrockProvider) CreateChatCompletion(ctx context.Context, req *provider.ChatCompletionRequest) (*provider.ChatCompletionResponse, error) {
+	body, err := json.Marshal(converseBody(req))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	endpoint := p.baseURL + "/model/" + url.PathEscape(req.Model) + "/converse"
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	resp, err := p.client.Do(httpReq) //nolint:gosec // G704: endpoint is configured at client init
+	if err != nil {
+		return nil, fmt.Errorf("bedrock request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+			return nil, fmt.Errorf("bedrock HTTP %d: %s", resp.StatusCode, apiErr.Message)
+		}
+		return nil, fmt.Errorf("bedrock HTTP %d: %s", resp.StatusCode, resp.Status)
+	}
+
+	var out converseResponse
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	var text strings.Builder
+	for _, c := range out.Output.Message.Content {
+		text.WriteString(c.Text)
+	}
+	finish := out.StopReason
+	return &provider.ChatCompletionResponse{
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   req.Model,
+		Choices: []provider.ChatCompletionChoice{{
+			Message:      provider.Message{Role: provider.RoleAssistant, Content: text.String()},
+			FinishReason: &finish,
+		}},
+		Usage: provider.Usage{
+			PromptTokens:     out.Usage.InputTokens,
+			CompletionTokens: out.Usage.OutputTokens,
+			TotalTokens:      out.Usage.TotalTokens,
+		},
+	}, nil
+}
+
+// CreateChatCompletionStream implements provider.Provider. It makes a
+// Converse call and streams its response as a single chunk.
+func (p *BedrockProvider) CreateChatCompletionStream(ctx context.Context, req *provider.ChatCompletionRequest) (provider.ChatCompletionStream, error) {
+	resp, err := p.CreateChatCompletion(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &singleChunkStream{resp: resp}, nil
+}
+
+// singleChunkStream streams a whole response as one chunk.
+type singleChunkStream struct {
+	resp *provider.ChatCompletionResponse
+	done bool
+}
+
+// Recv implements provider.ChatCompletionStream.
+func (s *singleChunkStream) Recv() (*provider.ChatCompletionChunk, error) {
+	if s.done {
+		return nil, io.EOF
+	}
+	s.done = true
+	usage := s.resp.Usage
+	return &provider.ChatCompletionChunk{
+		Object:  "chat.completion.chunk",
+		Created: s.resp.Created,
+		Model:   s.resp.Model,
+		Choices: s.resp.Choices,
+		Usage:   &usage,
+	}, nil
+}
+
+// Close implements provider.ChatCompletionStream.
+func (s *singleChunkStream) Close() error {
+	return nil
+}
+
+// converseBody converts a chat request to a Converse request. System
+// messages become the system prompt, and consecutive messages of the same
+// role are merged, as Converse requires alternating roles.
+func converseBody(req *provider.ChatCompletionRequest) *converseRequest {
+	body := &converseRequest{Messages: []converseMessage{}}
+	for _, m := range req.Messages {
+		if m.Content == "" {
+			continue
+		}
+		if m.Role == provider.RoleSystem {
+			body.System = append(body.System, converseContent{Text: m.Content})
+			continue
+		}
+		role := "user"
+		if m.Role == provider.RoleAssistant {
+			role = "assistant"
+		}
+		if n := len(body.Messages); n > 0 && body.Messages[n-1].Role == role {
+			body.Messages[n-1].Content = append(body.Messages[n-1].Content, converseContent{Text: m.Content})
+			continue
+		}
+		body.Messages = append(body.Messages, converseMessage{Role: role, Content: []converseContent{{Text: m.Content}}})
+	}
+	if req.MaxTokens != nil || req.Temperature != nil || req.TopP != nil || len(req.Stop) > 0 {
+		body.InferenceConfig = &converseInferenceConfig{
+			MaxTokens:     req.MaxTokens,
+			Temperature:   req.Temperature,
+			TopP:          req.TopP,
+			StopSequences: req.Stop,
+		}
+	}
+	return body
+}
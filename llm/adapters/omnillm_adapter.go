@@ -3,8 +3,13 @@ package adapters
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"iter"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/plexusone/omnillm"
 	"github.com/plexusone/omnillm/provider"
@@ -17,7 +22,32 @@ type OmniLLMAdapterConfig struct {
 	ProviderName      string
 	APIKey            string //nolint:gosec // G117: Config needs API key field
 	ModelName         string
+	BaseURL           string // Custom endpoint; empty uses the provider default
 	ObservabilityHook omnillm.ObservabilityHook
+
+	// Headers are added to every request, e.g. the api-key header of
+	// Azure OpenAI.
+	Headers map[string]string
+
+	// Provider is used instead of the OmniLLM provider named by
+	// ProviderName, e.g. a BedrockProvider. APIKey and BaseURL are then
+	// ignored.
+	Provider provider.Provider
+}
+
+// headerTransport adds headers to the requests it sends.
+type headerTransport struct {
+	headers map[string]string
+	base    http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	return t.base.RoundTrip(req)
 }
 
 // OmniLLMAdapter adapts OmniLLM ChatClient to ADK's LLM interface.
@@ -37,8 +67,8 @@ func NewOmniLLMAdapter(providerName, apiKey, modelName string) (*OmniLLMAdapter,
 
 // NewOmniLLMAdapterWithConfig creates a new OmniLLM adapter with full configuration.
 func NewOmniLLMAdapterWithConfig(cfg OmniLLMAdapterConfig) (*OmniLLMAdapter, error) {
-	// For ollama, API key is optional
-	if cfg.ProviderName != "ollama" && cfg.APIKey == "" {
+	// For ollama and injected providers, API key is optional
+	if cfg.Provider == nil && cfg.ProviderName != "ollama" && cfg.APIKey == "" {
 		return nil, fmt.Errorf("%s API key is required", cfg.ProviderName)
 	}
 
@@ -48,10 +78,19 @@ func NewOmniLLMAdapterWithConfig(cfg OmniLLMAdapterConfig) (*OmniLLMAdapter, err
 			{
 				Provider: omnillm.ProviderName(cfg.ProviderName),
 				APIKey:   cfg.APIKey,
+				BaseURL:  cfg.BaseURL,
+
+				CustomProvider: cfg.Provider,
 			},
 		},
 		ObservabilityHook: cfg.ObservabilityHook,
 	}
+	if len(cfg.Headers) > 0 {
+		config.Providers[0].HTTPClient = &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: &headerTransport{headers: cfg.Headers, base: http.DefaultTransport},
+		}
+	}
 
 	client, err := omnillm.NewClient(config)
 	if err != nil {
@@ -102,6 +141,18 @@ func (m *OmniLLMAdapter) GenerateContent(ctx context.Context, req *model.LLMRequ
 			Model:    m.model,
 			Messages: messages,
 		}
+		if req.Config != nil && req.Config.Temperature != nil {
+			temperature := float64(*req.Config.Temperature)
+			omniReq.Temperature = &temperature
+		}
+		if req.Config != nil && req.Config.ResponseMIMEType == "application/json" {
+			omniReq.ResponseFormat = &provider.ResponseFormat{Type: "json_object"}
+		}
+
+		if stream {
+			m.generateStream(ctx, omniReq, yield)
+			return
+		}
 
 		// Call OmniLLM API
 		resp, err := m.client.CreateChatCompletion(ctx, omniReq)
@@ -113,13 +164,75 @@ func (m *OmniLLMAdapter) GenerateContent(ctx context.Context, req *model.LLMRequ
 		// Convert OmniLLM response to ADK response
 		if len(resp.Choices) > 0 {
 			adkResp := &model.LLMResponse{
-				Content: &genai.Content{
-					Parts: []*genai.Part{
-						{Text: resp.Choices[0].Message.Content},
-					},
-				},
+				Content:       textContent(resp.Choices[0].Message.Content),
+				UsageMetadata: usageMetadata(resp.Usage),
 			}
 			yield(adkResp, nil)
 		}
 	}
 }
+
+// generateStream streams the response to req as partial responses, one
+// per chunk, followed by a response with the whole text and, if the
+// provider reports it, the usage.
+func (m *OmniLLMAdapter) generateStream(ctx context.Context, req *provider.ChatCompletionRequest, yield func(*model.LLMResponse, error) bool) {
+	s, err := m.client.CreateChatCompletionStream(ctx, req)
+	if err != nil {
+		yield(nil, fmt.Errorf("OmniLLM API error: %w", err))
+		return
+	}
+	defer func() { _ = s.Close() }()
+
+	var text strings.Builder
+	var usage *provider.Usage
+	for {
+		chunk, err := s.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			yield(nil, fmt.Errorf("OmniLLM stream error: %w", err))
+			return
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		delta := chunk.Choices[0].Message.Content
+		if d := chunk.Choices[0].Delta; d != nil {
+			delta = d.Content
+		}
+		if delta == "" {
+			continue
+		}
+		text.WriteString(delta)
+		if !yield(&model.LLMResponse{Content: textContent(delta), Partial: true}, nil) {
+			return
+		}
+	}
+
+	final := &model.LLMResponse{Content: textContent(text.String()), TurnComplete: true}
+	if usage != nil {
+		final.UsageMetadata = usageMetadata(*usage)
+	}
+	yield(final, nil)
+}
+
+// textContent returns model content holding text.
+func textContent(text string) *genai.Content {
+	return &genai.Content{
+		Role:  genai.RoleModel,
+		Parts: []*genai.Part{{Text: text}},
+	}
+}
+
+// usageMetadata converts OmniLLM usage to ADK usage metadata.
+func usageMetadata(u provider.Usage) *genai.GenerateContentResponseUsageMetadata {
+	return &genai.GenerateContentResponseUsageMetadata{
+		PromptTokenCount:     int32(u.PromptTokens),     //nolint:gosec // G115: token counts fit in int32
+		CandidatesTokenCount: int32(u.CompletionTokens), //nolint:gosec // G115: token counts fit in int32
+		TotalTokenCount:      int32(u.TotalTokens),      //nolint:gosec // G115: token counts fit in int32
+	}
+}
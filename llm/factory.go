@@ -4,96 +4,125 @@ package llm
 import (
 	"context"
 	"fmt"
+	"iter"
+	"log/slog"
+	"strings"
+	"sync"
 
-	"github.com/plexusone/omnillm"
-	omnillmhook "github.com/plexusone/omniobserve/integrations/omnillm"
-	"github.com/plexusone/omniobserve/llmops"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/model/gemini"
 	"google.golang.org/genai"
 
 	"github.com/plexusone/agentkit/config"
 	"github.com/plexusone/agentkit/llm/adapters"
-
-	// Import observability providers (driver registration via init())
-	_ "github.com/plexusone/omniobserve/llmops/langfuse"
-	_ "github.com/plexusone/opik-go/llmops"
-	_ "github.com/plexusone/phoenix-go/llmops"
+	"github.com/plexusone/agentkit/observability"
 )
 
 // ModelFactory creates LLM models based on configuration.
 type ModelFactory struct {
-	cfg      *config.Config
-	obsHook  omnillm.ObservabilityHook
-	obsClose func() error
+	cfg    *config.Config
+	tracer observability.Tracer
+
+	usage     *usageTracker
+	closed    chan struct{} // closed by Close, stopping ExportUsage
+	closeOnce sync.Once
 }
 
 // NewModelFactory creates a new model factory.
 func NewModelFactory(cfg *config.Config) *ModelFactory {
-	mf := &ModelFactory{cfg: cfg}
+	mf := &ModelFactory{cfg: cfg, usage: newUsageTracker(), closed: make(chan struct{})}
 
 	// Initialize observability if enabled
-	if cfg.ObservabilityEnabled && cfg.ObservabilityProvider != "" {
-		hook, closeFn := mf.initObservability()
-		mf.obsHook = hook
-		mf.obsClose = closeFn
+	tracer, err := observability.New(cfg)
+	if err != nil {
+		// Log error but don't fail - observability is optional
+		slog.Warn("failed to initialize observability", "provider", cfg.ObservabilityProvider, "error", err)
+		tracer = observability.Noop()
 	}
+	mf.tracer = tracer
 
 	return mf
 }
 
-// initObservability initializes the observability provider and returns a hook.
-func (mf *ModelFactory) initObservability() (omnillm.ObservabilityHook, func() error) {
-	opts := []llmops.ClientOption{
-		llmops.WithProjectName(mf.cfg.ObservabilityProject),
-	}
-
-	if mf.cfg.ObservabilityAPIKey != "" {
-		opts = append(opts, llmops.WithAPIKey(mf.cfg.ObservabilityAPIKey))
-	}
-
-	if mf.cfg.ObservabilityEndpoint != "" {
-		opts = append(opts, llmops.WithEndpoint(mf.cfg.ObservabilityEndpoint))
-	}
-
-	provider, err := llmops.Open(mf.cfg.ObservabilityProvider, opts...)
-	if err != nil {
-		// Log error but don't fail - observability is optional
-		fmt.Printf("Warning: failed to initialize observability provider %s: %v\n", mf.cfg.ObservabilityProvider, err)
-		return nil, nil
-	}
-
-	return omnillmhook.NewHook(provider), provider.Close
+// Tracer returns the tracer that records the calls of the factory's
+// models, configured by the observability settings of the config. Use it
+// to trace the invocations the calls belong to.
+func (mf *ModelFactory) Tracer() observability.Tracer {
+	return mf.tracer
 }
 
 // Close cleans up resources (call when factory is no longer needed).
 func (mf *ModelFactory) Close() error {
-	if mf.obsClose != nil {
-		return mf.obsClose()
-	}
-	return nil
+	mf.closeOnce.Do(func() { close(mf.closed) })
+	return mf.tracer.Close()
 }
 
 // CreateModel creates an LLM model based on the configured provider.
 func (mf *ModelFactory) CreateModel(ctx context.Context) (model.LLM, error) {
-	switch mf.cfg.LLMProvider {
+	return mf.createModel(ctx, "", mf.cfg.ResolveAgentLLM(""))
+}
+
+// CreateModelForAgent creates the LLM model for a named agent, applying its
+// per-agent provider, model, temperature and base URL overrides (see
+// config.Config.ResolveAgentLLM). Agents without overrides get the same
+// model as CreateModel.
+func (mf *ModelFactory) CreateModelForAgent(ctx context.Context, name string) (model.LLM, error) {
+	return mf.createModel(ctx, name, mf.cfg.ResolveAgentLLM(name))
+}
+
+// createModel creates a model from resolved LLM settings, recording its
+// usage under agent and limiting its calls with the rate limiter of its
+// provider API key. Model aliases such as "fast" are resolved with
+// config.ResolveModel.
+func (mf *ModelFactory) createModel(ctx context.Context, agent string, settings config.AgentLLMConfig) (model.LLM, error) {
+	var llmModel model.LLM
+	var err error
+	switch settings.Provider {
 	case "gemini", "":
-		return mf.createGeminiModel(ctx)
+		llmModel, err = mf.createGeminiModel(ctx, settings)
 	case "claude":
-		return mf.createClaudeModel()
+		llmModel, err = mf.createClaudeModel(settings)
 	case "openai":
-		return mf.createOpenAIModel()
+		llmModel, err = mf.createOpenAIModel(settings)
 	case "xai":
-		return mf.createXAIModel()
+		llmModel, err = mf.createXAIModel(settings)
 	case "ollama":
-		return mf.createOllamaModel()
+		llmModel, err = mf.createOllamaModel(settings)
+	case "bedrock":
+		llmModel, err = mf.createBedrockModel(settings)
+	case "azure":
+		llmModel, err = mf.createAzureModel(settings)
+	case "groq":
+		llmModel, err = mf.createGroqModel(settings)
+	case "mistral":
+		llmModel, err = mf.createMistralModel(settings)
 	default:
-		return nil, fmt.Errorf("unsupported LLM provider: %s (supported: gemini, claude, openai, xai, ollama)", mf.cfg.LLMProvider)
+		return nil, fmt.Errorf("unsupported LLM provider: %s (supported: gemini, claude, openai, xai, ollama, bedrock, azure, groq, mistral)", settings.Provider)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if settings.Temperature != nil {
+		llmModel = withTemperature(llmModel, *settings.Temperature)
+	}
+	provider := settings.Provider
+	if provider == "" {
+		provider = "gemini"
+	}
+	limit := RateLimit{RequestsPerMinute: mf.cfg.LLMRequestsPerMinute, TokensPerMinute: mf.cfg.LLMTokensPerMinute}
+	if limiter := rateLimiterFor(provider, providerCredential(mf.cfg, provider, settings), limit); limiter != nil {
+		llmModel = &rateModel{LLM: llmModel, limiter: limiter}
 	}
+	key := UsageKey{Agent: agent, Provider: provider, Model: config.ResolveModel(provider, settings.Model)}
+	return &usageModel{
+		LLM:     &traceModel{LLM: llmModel, key: key, tracer: mf.tracer},
+		key:     key,
+		tracker: mf.usage,
+	}, nil
 }
 
 // createGeminiModel creates a Gemini model.
-func (mf *ModelFactory) createGeminiModel(ctx context.Context) (model.LLM, error) {
+func (mf *ModelFactory) createGeminiModel(ctx context.Context, settings config.AgentLLMConfig) (model.LLM, error) {
 	apiKey := mf.cfg.GeminiAPIKey
 	if apiKey == "" {
 		apiKey = mf.cfg.LLMAPIKey
@@ -103,18 +132,16 @@ func (mf *ModelFactory) createGeminiModel(ctx context.Context) (model.LLM, error
 		return nil, fmt.Errorf("gemini API key not set - please set GOOGLE_API_KEY or GEMINI_API_KEY")
 	}
 
-	modelName := mf.cfg.LLMModel
-	if modelName == "" {
-		modelName = "gemini-2.0-flash-exp"
-	}
+	modelName := config.ResolveModel("gemini", settings.Model)
 
 	return gemini.NewModel(ctx, modelName, &genai.ClientConfig{
-		APIKey: apiKey,
+		APIKey:      apiKey,
+		HTTPOptions: genai.HTTPOptions{BaseURL: settings.BaseURL},
 	})
 }
 
 // createClaudeModel creates a Claude model using OmniLLM.
-func (mf *ModelFactory) createClaudeModel() (model.LLM, error) {
+func (mf *ModelFactory) createClaudeModel(settings config.AgentLLMConfig) (model.LLM, error) {
 	apiKey := mf.cfg.ClaudeAPIKey
 	if apiKey == "" {
 		apiKey = mf.cfg.LLMAPIKey
@@ -124,21 +151,18 @@ func (mf *ModelFactory) createClaudeModel() (model.LLM, error) {
 		return nil, fmt.Errorf("claude API key not set - please set CLAUDE_API_KEY or ANTHROPIC_API_KEY")
 	}
 
-	modelName := mf.cfg.LLMModel
-	if modelName == "" {
-		modelName = "claude-sonnet-4-20250514"
-	}
+	modelName := config.ResolveModel("claude", settings.Model)
 
 	return adapters.NewOmniLLMAdapterWithConfig(adapters.OmniLLMAdapterConfig{
-		ProviderName:      "anthropic",
-		APIKey:            apiKey,
-		ModelName:         modelName,
-		ObservabilityHook: mf.obsHook,
+		ProviderName: "anthropic",
+		APIKey:       apiKey,
+		ModelName:    modelName,
+		BaseURL:      settings.BaseURL,
 	})
 }
 
 // createOpenAIModel creates an OpenAI model using OmniLLM.
-func (mf *ModelFactory) createOpenAIModel() (model.LLM, error) {
+func (mf *ModelFactory) createOpenAIModel(settings config.AgentLLMConfig) (model.LLM, error) {
 	apiKey := mf.cfg.OpenAIAPIKey
 	if apiKey == "" {
 		apiKey = mf.cfg.LLMAPIKey
@@ -148,21 +172,18 @@ func (mf *ModelFactory) createOpenAIModel() (model.LLM, error) {
 		return nil, fmt.Errorf("openai API key not set - please set OPENAI_API_KEY")
 	}
 
-	modelName := mf.cfg.LLMModel
-	if modelName == "" {
-		modelName = "gpt-4o-mini"
-	}
+	modelName := config.ResolveModel("openai", settings.Model)
 
 	return adapters.NewOmniLLMAdapterWithConfig(adapters.OmniLLMAdapterConfig{
-		ProviderName:      "openai",
-		APIKey:            apiKey,
-		ModelName:         modelName,
-		ObservabilityHook: mf.obsHook,
+		ProviderName: "openai",
+		APIKey:       apiKey,
+		ModelName:    modelName,
+		BaseURL:      settings.BaseURL,
 	})
 }
 
 // createXAIModel creates an xAI Grok model using OmniLLM.
-func (mf *ModelFactory) createXAIModel() (model.LLM, error) {
+func (mf *ModelFactory) createXAIModel(settings config.AgentLLMConfig) (model.LLM, error) {
 	apiKey := mf.cfg.XAIAPIKey
 	if apiKey == "" {
 		apiKey = mf.cfg.LLMAPIKey
@@ -172,36 +193,166 @@ func (mf *ModelFactory) createXAIModel() (model.LLM, error) {
 		return nil, fmt.Errorf("xAI API key not set - please set XAI_API_KEY")
 	}
 
-	modelName := mf.cfg.LLMModel
-	if modelName == "" {
-		modelName = "grok-3"
-	}
+	modelName := config.ResolveModel("xai", settings.Model)
 
 	return adapters.NewOmniLLMAdapterWithConfig(adapters.OmniLLMAdapterConfig{
-		ProviderName:      "xai",
-		APIKey:            apiKey,
-		ModelName:         modelName,
-		ObservabilityHook: mf.obsHook,
+		ProviderName: "xai",
+		APIKey:       apiKey,
+		ModelName:    modelName,
+		BaseURL:      settings.BaseURL,
 	})
 }
 
 // createOllamaModel creates an Ollama model using OmniLLM.
-func (mf *ModelFactory) createOllamaModel() (model.LLM, error) {
-	modelName := mf.cfg.LLMModel
-	if modelName == "" {
-		modelName = "llama3.2"
-	}
+func (mf *ModelFactory) createOllamaModel(settings config.AgentLLMConfig) (model.LLM, error) {
+	modelName := config.ResolveModel("ollama", settings.Model)
 
 	// Ollama doesn't need an API key for local instances
 	return adapters.NewOmniLLMAdapterWithConfig(adapters.OmniLLMAdapterConfig{
-		ProviderName:      "ollama",
-		APIKey:            "",
-		ModelName:         modelName,
-		ObservabilityHook: mf.obsHook,
+		ProviderName: "ollama",
+		APIKey:       "",
+		ModelName:    modelName,
+		BaseURL:      settings.BaseURL,
 	})
 }
 
+// createBedrockModel creates an AWS Bedrock model using the Converse API.
+// settings.BaseURL overrides the regional runtime endpoint.
+func (mf *ModelFactory) createBedrockModel(settings config.AgentLLMConfig) (model.LLM, error) {
+	apiKey := mf.cfg.BedrockAPIKey
+	if apiKey == "" {
+		apiKey = mf.cfg.LLMAPIKey
+	}
+
+	if apiKey == "" {
+		return nil, fmt.Errorf("bedrock API key not set - please set AWS_BEARER_TOKEN_BEDROCK")
+	}
+
+	bedrock, err := adapters.NewBedrockProvider(apiKey, mf.cfg.BedrockRegion, settings.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	modelName := config.ResolveModel("bedrock", settings.Model)
+
+	return adapters.NewOmniLLMAdapterWithConfig(adapters.OmniLLMAdapterConfig{
+		ProviderName: "bedrock",
+		ModelName:    modelName,
+		Provider:     bedrock,
+	})
+}
+
+// createAzureModel creates an Azure OpenAI model using OmniLLM. The model
+// name is the deployment name.
+func (mf *ModelFactory) createAzureModel(settings config.AgentLLMConfig) (model.LLM, error) {
+	apiKey := mf.cfg.AzureOpenAIAPIKey
+	if apiKey == "" {
+		apiKey = mf.cfg.LLMAPIKey
+	}
+
+	if apiKey == "" {
+		return nil, fmt.Errorf("azure OpenAI API key not set - please set AZURE_OPENAI_API_KEY")
+	}
+
+	endpoint := settings.BaseURL
+	if endpoint == "" {
+		endpoint = mf.cfg.AzureOpenAIEndpoint
+	}
+	if endpoint == "" {
+		return nil, fmt.Errorf("azure OpenAI endpoint not set - please set AZURE_OPENAI_ENDPOINT")
+	}
+
+	modelName := config.ResolveModel("azure", settings.Model)
+
+	// The v1 API of Azure OpenAI is OpenAI compatible.
+	return adapters.NewOmniLLMAdapterWithConfig(adapters.OmniLLMAdapterConfig{
+		ProviderName: "openai",
+		APIKey:       apiKey,
+		ModelName:    modelName,
+		BaseURL:      strings.TrimSuffix(endpoint, "/") + "/openai/v1",
+		Headers:      map[string]string{"api-key": apiKey},
+	})
+}
+
+// createGroqModel creates a Groq model using OmniLLM.
+func (mf *ModelFactory) createGroqModel(settings config.AgentLLMConfig) (model.LLM, error) {
+	apiKey := mf.cfg.GroqAPIKey
+	if apiKey == "" {
+		apiKey = mf.cfg.LLMAPIKey
+	}
+
+	if apiKey == "" {
+		return nil, fmt.Errorf("groq API key not set - please set GROQ_API_KEY")
+	}
+
+	modelName := config.ResolveModel("groq", settings.Model)
+
+	return adapters.NewOmniLLMAdapterWithConfig(adapters.OmniLLMAdapterConfig{
+		ProviderName: "openai",
+		APIKey:       apiKey,
+		ModelName:    modelName,
+		BaseURL:      orDefault(settings.BaseURL, "https://api.groq.com/openai/v1"),
+	})
+}
+
+// createMistralModel creates a Mistral model using OmniLLM.
+func (mf *ModelFactory) createMistralModel(settings config.AgentLLMConfig) (model.LLM, error) {
+	apiKey := mf.cfg.MistralAPIKey
+	if apiKey == "" {
+		apiKey = mf.cfg.LLMAPIKey
+	}
+
+	if apiKey == "" {
+		return nil, fmt.Errorf("mistral API key not set - please set MISTRAL_API_KEY")
+	}
+
+	modelName := config.ResolveModel("mistral", settings.Model)
+
+	return adapters.NewOmniLLMAdapterWithConfig(adapters.OmniLLMAdapterConfig{
+		ProviderName: "openai",
+		APIKey:       apiKey,
+		ModelName:    modelName,
+		BaseURL:      orDefault(settings.BaseURL, "https://api.mistral.ai/v1"),
+	})
+}
+
+// orDefault returns s, or def if s is empty.
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
 // GetProviderInfo returns information about the current provider.
 func (mf *ModelFactory) GetProviderInfo() string {
 	return fmt.Sprintf("Provider: %s, Model: %s", mf.cfg.LLMProvider, mf.cfg.LLMModel)
 }
+
+// temperatureModel sets a default sampling temperature on requests that
+// do not set one.
+type temperatureModel struct {
+	model.LLM
+	temperature float32
+}
+
+// withTemperature wraps m so requests default to temperature.
+func withTemperature(m model.LLM, temperature float64) model.LLM {
+	return &temperatureModel{LLM: m, temperature: float32(temperature)}
+}
+
+// GenerateContent implements model.LLM.
+func (m *temperatureModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	if req.Config == nil || req.Config.Temperature == nil {
+		r := *req
+		if r.Config == nil {
+			r.Config = &genai.GenerateContentConfig{}
+		} else {
+			c := *r.Config
+			r.Config = &c
+		}
+		r.Config.Temperature = &m.temperature
+		req = &r
+	}
+	return m.LLM.GenerateContent(ctx, req, stream)
+}
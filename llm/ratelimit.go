@@ -0,0 +1,306 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"iter"
+	"sync"
+	"time"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+
+	"github.com/plexusone/agentkit/config"
+)
+
+// RateLimit limits the calls made with one provider API key. Zero fields
+// do not limit.
+type RateLimit struct {
+	// RequestsPerMinute is the most calls started per minute.
+	// Default: 0 (unlimited)
+	RequestsPerMinute int
+
+	// TokensPerMinute is the most prompt and completion tokens used per
+	// minute. Calls are charged an estimate when they start, corrected by
+	// their reported usage when they end.
+	// Default: 0 (unlimited)
+	TokensPerMinute int
+}
+
+// unlimited reports whether l does not limit calls.
+func (l RateLimit) unlimited() bool {
+	return l.RequestsPerMinute <= 0 && l.TokensPerMinute <= 0
+}
+
+// RateLimiter queues calls to keep them within a RateLimit. Its limits
+// are token buckets holding a minute's worth of requests and tokens, so
+// short bursts pass at once. Callers wait in the order they arrive.
+type RateLimiter struct {
+	mu       sync.Mutex
+	requests *bucket // nil if unlimited
+	tokens   *bucket // nil if unlimited
+}
+
+// NewRateLimiter creates a rate limiter enforcing limit.
+func NewRateLimiter(limit RateLimit) *RateLimiter {
+	l := &RateLimiter{}
+	l.SetLimit(limit)
+	return l
+}
+
+// SetLimit replaces the limit of l. Calls already waiting keep their
+// place in the queue.
+func (l *RateLimiter) SetLimit(limit RateLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.requests = l.requests.resize(limit.RequestsPerMinute, now)
+	l.tokens = l.tokens.resize(limit.TokensPerMinute, now)
+}
+
+// Wait blocks until a call of an estimated tokens may start. It returns
+// ctx's error, without using up the limit, if ctx is done first, and
+// fails at once if the wait would outlast ctx's deadline.
+func (l *RateLimiter) Wait(ctx context.Context, tokens int) error {
+	l.mu.Lock()
+	now := time.Now()
+	wait := max(l.requests.take(1, now), l.tokens.take(float64(tokens), now))
+	l.mu.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+
+	giveBack := func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.requests.give(1)
+		l.tokens.give(float64(tokens))
+	}
+	if deadline, ok := ctx.Deadline(); ok && now.Add(wait).After(deadline) {
+		giveBack()
+		return fmt.Errorf("rate limit wait of %s exceeds the deadline: %w", wait.Round(time.Millisecond), context.DeadlineExceeded)
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		giveBack()
+		return ctx.Err()
+	}
+}
+
+// Adjust charges tokens more to the token limit, or gives them back if
+// tokens is negative, once the actual usage of a call is known.
+func (l *RateLimiter) Adjust(tokens int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if tokens > 0 {
+		l.tokens.take(float64(tokens), time.Now())
+	} else {
+		l.tokens.give(float64(-tokens))
+	}
+}
+
+// bucket is a token bucket refilled at perMinute per minute, holding at
+// most perMinute. Its level goes below zero while calls wait for it.
+type bucket struct {
+	perMinute float64
+	level     float64
+	updated   time.Time
+}
+
+// resize returns b with a new limit, nil if perMinute does not limit. A
+// new bucket starts full.
+func (b *bucket) resize(perMinute int, now time.Time) *bucket {
+	if perMinute <= 0 {
+		return nil
+	}
+	if b == nil {
+		return &bucket{perMinute: float64(perMinute), level: float64(perMinute), updated: now}
+	}
+	b.refill(now)
+	b.perMinute = float64(perMinute)
+	b.level = min(b.level, b.perMinute)
+	return b
+}
+
+// refill adds what has accrued since the last update.
+func (b *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.level = min(b.perMinute, b.level+b.perMinute*elapsed.Minutes())
+		b.updated = now
+	}
+}
+
+// take removes n from b and returns how long until the level is back to
+// zero, which is the wait of the caller.
+func (b *bucket) take(n float64, now time.Time) time.Duration {
+	if b == nil {
+		return 0
+	}
+	b.refill(now)
+	b.level -= n
+	if b.level >= 0 {
+		return 0
+	}
+	return time.Duration(-b.level / b.perMinute * float64(time.Minute))
+}
+
+// give returns n to b.
+func (b *bucket) give(n float64) {
+	if b != nil {
+		b.level = min(b.perMinute, b.level+n)
+	}
+}
+
+// rateLimiters holds the rate limiter of each provider API key, shared by
+// all model factories of the process so that their calls count together.
+var rateLimiters = struct {
+	mu        sync.Mutex
+	limiters  map[limiterKey]*RateLimiter
+	overrides map[string]RateLimit // by provider, see SetRateLimit
+}{limiters: map[limiterKey]*RateLimiter{}, overrides: map[string]RateLimit{}}
+
+// limiterKey identifies an API key of a provider. Keys are hashed, so
+// that they are not kept in memory longer than the config.
+type limiterKey struct {
+	provider string
+	keyHash  string
+}
+
+// SetRateLimit sets the rate limit of every API key of provider, e.g.
+// "claude", in the process, overriding LLM_REQUESTS_PER_MINUTE and
+// LLM_TOKENS_PER_MINUTE. It applies to models already created, and to
+// those created later by any ModelFactory.
+func SetRateLimit(provider string, limit RateLimit) {
+	rateLimiters.mu.Lock()
+	defer rateLimiters.mu.Unlock()
+	rateLimiters.overrides[provider] = limit
+	for key, l := range rateLimiters.limiters {
+		if key.provider == provider {
+			l.SetLimit(limit)
+		}
+	}
+}
+
+// rateLimiterFor returns the rate limiter of an API key of provider, or
+// nil if neither limit nor an override of the provider limit it.
+func rateLimiterFor(provider, apiKey string, limit RateLimit) *RateLimiter {
+	rateLimiters.mu.Lock()
+	defer rateLimiters.mu.Unlock()
+	if override, ok := rateLimiters.overrides[provider]; ok {
+		limit = override
+	}
+	sum := sha256.Sum256([]byte(apiKey))
+	key := limiterKey{provider: provider, keyHash: hex.EncodeToString(sum[:8])}
+	l, ok := rateLimiters.limiters[key]
+	switch {
+	case ok:
+		l.SetLimit(limit)
+	case limit.unlimited():
+		return nil
+	default:
+		l = NewRateLimiter(limit)
+		rateLimiters.limiters[key] = l
+	}
+	return l
+}
+
+// providerCredential returns what identifies the account the calls of
+// provider are made with: its API key, or the server URL for Ollama.
+func providerCredential(cfg *config.Config, provider string, settings config.AgentLLMConfig) string {
+	var key string
+	switch provider {
+	case "gemini":
+		key = cfg.GeminiAPIKey
+	case "claude":
+		key = cfg.ClaudeAPIKey
+	case "openai":
+		key = cfg.OpenAIAPIKey
+	case "xai":
+		key = cfg.XAIAPIKey
+	case "bedrock":
+		key = cfg.BedrockAPIKey
+	case "azure":
+		key = cfg.AzureOpenAIAPIKey
+	case "groq":
+		key = cfg.GroqAPIKey
+	case "mistral":
+		key = cfg.MistralAPIKey
+	case "ollama":
+		return orDefault(settings.BaseURL, cfg.OllamaURL)
+	}
+	if key == "" {
+		key = cfg.LLMAPIKey
+	}
+	return key
+}
+
+// rateModel waits for a rate limiter before each call of a model.
+type rateModel struct {
+	model.LLM
+	limiter *RateLimiter
+}
+
+// GenerateContent implements model.LLM.
+func (m *rateModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		estimate := estimateTokens(req)
+		if err := m.limiter.Wait(ctx, estimate); err != nil {
+			yield(nil, fmt.Errorf("rate limit: %w", err))
+			return
+		}
+
+		used := -1
+		defer func() {
+			if used >= 0 {
+				m.limiter.Adjust(used - estimate)
+			}
+		}()
+		for resp, err := range m.LLM.GenerateContent(ctx, req, stream) {
+			if resp != nil && resp.UsageMetadata != nil {
+				used = int(resp.UsageMetadata.PromptTokenCount + resp.UsageMetadata.CandidatesTokenCount)
+			}
+			if !yield(resp, err) {
+				return
+			}
+		}
+	}
+}
+
+// estimateTokens estimates the tokens of a call from its request and its
+// output limit.
+func estimateTokens(req *model.LLMRequest) int {
+	tokens := estimateInputTokens(req)
+	if req.Config != nil {
+		tokens += int(req.Config.MaxOutputTokens)
+	}
+	return tokens
+}
+
+// estimateInputTokens estimates the prompt tokens of req from the length
+// of its text, at 4 characters a token.
+func estimateInputTokens(req *model.LLMRequest) int {
+	chars := 0
+	addText := func(c *genai.Content) {
+		if c == nil {
+			return
+		}
+		for _, part := range c.Parts {
+			if part != nil {
+				chars += len(part.Text)
+			}
+		}
+	}
+	for _, c := range req.Contents {
+		addText(c)
+	}
+	if req.Config != nil {
+		addText(req.Config.SystemInstruction)
+	}
+	return chars/4 + 1
+}
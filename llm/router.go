@@ -0,0 +1,225 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"log/slog"
+
+	"google.golang.org/adk/model"
+
+	"github.com/plexusone/agentkit/config"
+)
+
+// Tier is a class of models, from cheap and fast to expensive and capable.
+type Tier string
+
+const (
+	// TierCheap is for short, simple tasks such as classification and
+	// extraction.
+	TierCheap Tier = "cheap"
+
+	// TierStandard is for most tasks.
+	TierStandard Tier = "standard"
+
+	// TierPremium is for long or demanding tasks, and tasks that failed
+	// on a cheaper tier.
+	TierPremium Tier = "premium"
+)
+
+// tiers are the tiers in escalation order.
+var tiers = []Tier{TierCheap, TierStandard, TierPremium}
+
+// next returns the tier above t, or "" if t is the highest.
+func (t Tier) next() Tier {
+	for i, tier := range tiers[:len(tiers)-1] {
+		if tier == t {
+			return tiers[i+1]
+		}
+	}
+	return ""
+}
+
+type tierKey struct{}
+
+// WithTier returns a context in which routers send calls to the models of
+// tier, whatever their size. Use it to declare the tier of a task.
+func WithTier(ctx context.Context, tier Tier) context.Context {
+	return context.WithValue(ctx, tierKey{}, tier)
+}
+
+// TierFromContext returns the tier declared with WithTier, or "".
+func TierFromContext(ctx context.Context) Tier {
+	tier, _ := ctx.Value(tierKey{}).(Tier)
+	return tier
+}
+
+// RouterConfig configures a Router.
+type RouterConfig struct {
+	// Models are the model names or aliases of each tier, for the
+	// provider of the agent. Tiers without a model use their default.
+	// Default: "fast" for TierCheap, the agent's model for TierStandard,
+	// and "smart" for TierPremium, where the provider has these aliases
+	Models map[Tier]string
+
+	// CheapMaxTokens is the largest estimated request, in tokens, sent to
+	// TierCheap.
+	// Default: 500
+	CheapMaxTokens int
+
+	// PremiumMinTokens is the smallest estimated request, in tokens, sent
+	// to TierPremium.
+	// Default: 8000
+	PremiumMinTokens int
+
+	// DisableEscalation stops retrying a call that fails before returning
+	// anything on the next tier up.
+	// Default: false (failed calls escalate)
+	DisableEscalation bool
+
+	// Route, if set, is called with the tier chosen for each call and
+	// returns the tier to use, e.g. to pin some agents or tenants to a
+	// tier.
+	// Default: nil (use the chosen tier)
+	Route func(ctx context.Context, req *model.LLMRequest, tier Tier) Tier
+}
+
+// withDefaults returns c with zero fields set to their defaults.
+func (c RouterConfig) withDefaults() RouterConfig {
+	if c.CheapMaxTokens <= 0 {
+		c.CheapMaxTokens = 500
+	}
+	if c.PremiumMinTokens <= 0 {
+		c.PremiumMinTokens = 8000
+	}
+	return c
+}
+
+// Router is a model that sends each call to the model of a tier, so that
+// simple calls use a cheaper model than demanding ones. The tier of a call
+// is, in order of precedence:
+//
+//   - the tier declared with WithTier,
+//   - TierCheap for requests of up to CheapMaxTokens, TierPremium for
+//     requests of PremiumMinTokens or more, and TierStandard otherwise,
+//
+// as changed by the Route hook. A call that fails on a tier before
+// returning anything is retried on the next tier up.
+type Router struct {
+	cfg    RouterConfig
+	models map[Tier]model.LLM
+}
+
+// CreateRouter creates a Router between the models of the tiers of cfg,
+// for the provider of the named agent; see CreateModelForAgent. Each model
+// records its usage and cost under agent.
+func (mf *ModelFactory) CreateRouter(ctx context.Context, agent string, cfg RouterConfig) (*Router, error) {
+	cfg = cfg.withDefaults()
+	settings := mf.cfg.ResolveAgentLLM(agent)
+	provider := orDefault(settings.Provider, "gemini")
+	defaults := map[Tier]string{TierCheap: "fast", TierStandard: settings.Model, TierPremium: "smart"}
+
+	r := &Router{cfg: cfg, models: make(map[Tier]model.LLM, len(tiers))}
+	byName := map[string]model.LLM{}
+	for _, tier := range tiers {
+		name := cfg.Models[tier]
+		if name == "" {
+			name = defaults[tier]
+			// Providers without the alias use the standard model.
+			if tier != TierStandard && config.ResolveModel(provider, name) == name {
+				name = settings.Model
+			}
+		}
+		resolved := config.ResolveModel(provider, name)
+		if m, ok := byName[resolved]; ok {
+			r.models[tier] = m
+			continue
+		}
+		tierSettings := settings
+		tierSettings.Model = name
+		m, err := mf.createModel(ctx, agent, tierSettings)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s model %s: %w", tier, name, err)
+		}
+		byName[resolved] = m
+		r.models[tier] = m
+	}
+	return r, nil
+}
+
+// Name implements model.LLM. It returns the name of the standard model.
+func (r *Router) Name() string {
+	return r.models[TierStandard].Name()
+}
+
+// TierModel returns the model of tier.
+func (r *Router) TierModel(tier Tier) model.LLM {
+	return r.models[tier]
+}
+
+// Select returns the tier a call with req is sent to first.
+func (r *Router) Select(ctx context.Context, req *model.LLMRequest) Tier {
+	tier := TierFromContext(ctx)
+	if _, ok := r.models[tier]; !ok {
+		tier = TierStandard
+		switch tokens := estimateInputTokens(req); {
+		case tokens <= r.cfg.CheapMaxTokens:
+			tier = TierCheap
+		case tokens >= r.cfg.PremiumMinTokens:
+			tier = TierPremium
+		}
+	}
+	if r.cfg.Route != nil {
+		if routed := r.cfg.Route(ctx, req, tier); r.models[routed] != nil {
+			tier = routed
+		}
+	}
+	return tier
+}
+
+// GenerateContent implements model.LLM.
+func (r *Router) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		tier := r.Select(ctx, req)
+		for {
+			m := r.models[tier]
+			slog.DebugContext(ctx, "routing model call", "tier", tier, "model", m.Name())
+
+			// A call that fails before returning anything is retried on
+			// the next tier with another model, if there is one.
+			escalate := tier.next()
+			for escalate != "" && r.models[escalate] == m {
+				escalate = escalate.next()
+			}
+			if r.cfg.DisableEscalation {
+				escalate = ""
+			}
+
+			returned := false
+			failed := false
+			for resp, err := range m.GenerateContent(ctx, req, stream) {
+				if !returned && escalate != "" && ctx.Err() == nil && (err != nil || resp != nil && resp.ErrorCode != "") {
+					slog.WarnContext(ctx, "model call failed, escalating", "tier", tier, "next", escalate, "error", routeError(resp, err))
+					failed = true
+					break
+				}
+				returned = true
+				if !yield(resp, err) {
+					return
+				}
+			}
+			if !failed {
+				return
+			}
+			tier = escalate
+		}
+	}
+}
+
+// routeError returns the error of a failed call, as an error or response.
+func routeError(resp *model.LLMResponse, err error) error {
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("model error %s: %s", resp.ErrorCode, resp.ErrorMessage)
+}
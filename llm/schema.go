@@ -0,0 +1,299 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// SchemaFor returns a JSON Schema for the JSON encoding of T. Struct fields
+// are named by their json tags and required unless they are pointers or
+// tagged omitempty; a description tag describes a field to the model.
+//
+//	type Verdict struct {
+//	    Label      string   `json:"label" description:"one of: spam, ham"`
+//	    Confidence float64  `json:"confidence"`
+//	    Reasons    []string `json:"reasons,omitempty"`
+//	}
+//	schema := llm.SchemaFor[Verdict]()
+func SchemaFor[T any]() map[string]any {
+	return schemaOf(reflect.TypeFor[T](), nil)
+}
+
+// ValidateJSON checks that output, a model response, holds a JSON value
+// matching schema, a JSON Schema or a value that encodes to one. The value
+// may be wrapped in a Markdown code fence or surrounded by prose, as in
+// GenerateStructured.
+func ValidateJSON(output string, schema any) error {
+	schemaJSON, err := json.Marshal(schema)
+	if err != nil {
+		return fmt.Errorf("failed to encode schema: %w", err)
+	}
+	var validator jsonSchema
+	if err := json.Unmarshal(schemaJSON, &validator); err != nil {
+		return fmt.Errorf("invalid schema: %w", err)
+	}
+	var value any
+	return decodeStructured(output, &validator, &value)
+}
+
+// schemaOf returns the schema of t. seen holds the struct types being
+// described, so recursive types end in an unconstrained schema.
+func schemaOf(t reflect.Type, seen []reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == reflect.TypeFor[time.Time]():
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t == reflect.TypeFor[json.RawMessage]():
+		return map[string]any{}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string"} // base64
+		}
+		return map[string]any{"type": "array", "items": schemaOf(t.Elem(), seen)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaOf(t.Elem(), seen)}
+	case reflect.Struct:
+		if slices.Contains(seen, t) {
+			return map[string]any{"type": "object"}
+		}
+		seen = append(seen, t)
+		properties := map[string]any{}
+		required := []string{}
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" && opts == "" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			prop := schemaOf(f.Type, seen)
+			if desc := f.Tag.Get("description"); desc != "" {
+				prop["description"] = desc
+			}
+			properties[name] = prop
+			if !strings.Contains(","+opts+",", ",omitempty,") && f.Type.Kind() != reflect.Pointer {
+				required = append(required, name)
+			}
+		}
+		return map[string]any{"type": "object", "properties": properties, "required": required}
+	default:
+		return map[string]any{}
+	}
+}
+
+// jsonSchema is the subset of JSON Schema that validate checks: type,
+// enum, const, properties, required, additionalProperties, items, anyOf,
+// and the bounds of numbers, strings and arrays. Other keywords are
+// ignored.
+type jsonSchema struct {
+	Type                 schemaTypes            `json:"type"`
+	Enum                 []any                  `json:"enum"`
+	Const                *any                   `json:"const"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	Required             []string               `json:"required"`
+	AdditionalProperties *additionalProperties  `json:"additionalProperties"`
+	Items                *jsonSchema            `json:"items"`
+	AnyOf                []*jsonSchema          `json:"anyOf"`
+	Minimum              *float64               `json:"minimum"`
+	Maximum              *float64               `json:"maximum"`
+	MinLength            *int                   `json:"minLength"`
+	MaxLength            *int                   `json:"maxLength"`
+	MinItems             *int                   `json:"minItems"`
+	MaxItems             *int                   `json:"maxItems"`
+}
+
+// schemaTypes is the "type" keyword, a type name or a list of them.
+type schemaTypes []string
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*t = schemaTypes{name}
+		return nil
+	}
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return fmt.Errorf("type must be a string or an array of strings")
+	}
+	*t = names
+	return nil
+}
+
+// additionalProperties is the "additionalProperties" keyword, false or a
+// schema.
+type additionalProperties struct {
+	forbidden bool
+	schema    *jsonSchema
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (a *additionalProperties) UnmarshalJSON(data []byte) error {
+	var allowed bool
+	if err := json.Unmarshal(data, &allowed); err == nil {
+		a.forbidden = !allowed
+		return nil
+	}
+	return json.Unmarshal(data, &a.schema)
+}
+
+// validate reports the first way v, decoded with json.Decoder.UseNumber,
+// does not match s, naming its location by a JSON path.
+func (s *jsonSchema) validate(v any, path string) error {
+	if s == nil {
+		return nil
+	}
+	if len(s.Type) > 0 && !slices.ContainsFunc(s.Type, func(t string) bool { return hasType(v, t) }) {
+		return fmt.Errorf("%s: expected %s, got %s", path, strings.Join(s.Type, " or "), typeName(v))
+	}
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e any) bool { return jsonEqual(v, e) }) {
+		enum, _ := json.Marshal(s.Enum)
+		return fmt.Errorf("%s: must be one of %s", path, enum)
+	}
+	if s.Const != nil && !jsonEqual(v, *s.Const) {
+		c, _ := json.Marshal(*s.Const)
+		return fmt.Errorf("%s: must be %s", path, c)
+	}
+	if len(s.AnyOf) > 0 && !slices.ContainsFunc(s.AnyOf, func(sub *jsonSchema) bool { return sub.validate(v, path) == nil }) {
+		return fmt.Errorf("%s: matches none of the allowed schemas", path)
+	}
+
+	switch v := v.(type) {
+	case json.Number:
+		f, _ := v.Float64()
+		if s.Minimum != nil && f < *s.Minimum {
+			return fmt.Errorf("%s: must be at least %v", path, *s.Minimum)
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			return fmt.Errorf("%s: must be at most %v", path, *s.Maximum)
+		}
+	case string:
+		n := utf8.RuneCountInString(v)
+		if s.MinLength != nil && n < *s.MinLength {
+			return fmt.Errorf("%s: must be at least %d characters", path, *s.MinLength)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			return fmt.Errorf("%s: must be at most %d characters", path, *s.MaxLength)
+		}
+	case []any:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			return fmt.Errorf("%s: must have at least %d items", path, *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			return fmt.Errorf("%s: must have at most %d items", path, *s.MaxItems)
+		}
+		for i, item := range v {
+			if err := s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				return fmt.Errorf("%s: missing required property %q", path, name)
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			prop, ok := s.Properties[name]
+			switch {
+			case ok:
+			case s.AdditionalProperties == nil:
+				continue
+			case s.AdditionalProperties.forbidden:
+				return fmt.Errorf("%s: unknown property %q", path, name)
+			default:
+				prop = s.AdditionalProperties.schema
+			}
+			if err := prop.validate(v[name], path+"."+name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// hasType reports whether v is of the JSON Schema type t.
+func hasType(v any, t string) bool {
+	switch t {
+	case "integer":
+		n, ok := v.(json.Number)
+		if !ok {
+			return false
+		}
+		f, err := n.Float64()
+		return err == nil && f == math.Trunc(f)
+	case "number":
+		_, ok := v.(json.Number)
+		return ok
+	default:
+		return typeName(v) == t
+	}
+}
+
+// typeName returns the JSON Schema type of v.
+func typeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// jsonEqual reports whether a and b have the same JSON encoding, comparing
+// numbers by value.
+func jsonEqual(a, b any) bool {
+	if n, ok := a.(json.Number); ok {
+		fa, _ := n.Float64()
+		switch b := b.(type) {
+		case json.Number:
+			fb, _ := b.Float64()
+			return fa == fb
+		case float64:
+			return fa == b
+		}
+		return false
+	}
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(ja) == string(jb)
+}
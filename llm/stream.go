@@ -0,0 +1,104 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+
+	"github.com/plexusone/agentkit/llm/stream"
+)
+
+// Stream calls m with req, streaming its response, and sends the text of
+// the response to the returned channel as it arrives, as stream.Chunk
+// documents. Canceling ctx ends the stream with ctx's error. Providers
+// that do not stream send the whole response as one chunk. The usage of
+// models and routers created by a ModelFactory includes their cost.
+//
+//	for chunk := range llm.Stream(ctx, ba.Model, req) {
+//	    if chunk.Err != nil {
+//	        return chunk.Err
+//	    }
+//	    fmt.Print(chunk.Text)
+//	}
+func Stream(ctx context.Context, m model.LLM, req *model.LLMRequest) <-chan stream.Chunk {
+	ch := make(chan stream.Chunk)
+	go func() {
+		defer close(ch)
+		send := func(c stream.Chunk) bool {
+			select {
+			case ch <- c:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		var usage *stream.Usage
+		var served UsageKey
+		partial := false
+		for resp, err := range m.GenerateContent(context.WithValue(ctx, servedKey{}, &served), req, true) {
+			if err != nil {
+				send(stream.Chunk{Err: fmt.Errorf("model call failed: %w", err)})
+				return
+			}
+			if resp == nil {
+				continue
+			}
+			if resp.UsageMetadata != nil {
+				usage = &stream.Usage{
+					PromptTokens:     int(resp.UsageMetadata.PromptTokenCount),
+					CompletionTokens: int(resp.UsageMetadata.CandidatesTokenCount),
+				}
+				usage.CostUSD = served.cost(int64(usage.PromptTokens), int64(usage.CompletionTokens))
+			}
+			if resp.ErrorCode != "" {
+				send(stream.Chunk{Err: fmt.Errorf("model error %s: %s", resp.ErrorCode, resp.ErrorMessage)})
+				return
+			}
+			// Streaming models end with the aggregate of their partial
+			// responses, which has been sent already.
+			if !resp.Partial && partial {
+				continue
+			}
+			partial = partial || resp.Partial
+			if text := responseText(resp); text != "" && !send(stream.Chunk{Text: text}) {
+				return
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			// Tell a reader still waiting, without blocking on one that
+			// has left.
+			select {
+			case ch <- stream.Chunk{Err: err}:
+			default:
+			}
+			return
+		}
+		send(stream.Chunk{Done: true, Usage: usage})
+	}()
+	return ch
+}
+
+// StreamText streams m's response to a single user prompt, see Stream.
+func StreamText(ctx context.Context, m model.LLM, prompt string) <-chan stream.Chunk {
+	return Stream(ctx, m, &model.LLMRequest{
+		Contents: []*genai.Content{genai.NewContentFromText(prompt, genai.RoleUser)},
+	})
+}
+
+// responseText returns the text of resp, without thoughts.
+func responseText(resp *model.LLMResponse) string {
+	if resp.Content == nil {
+		return ""
+	}
+	var text strings.Builder
+	for _, part := range resp.Content.Parts {
+		if part != nil && !part.Thought {
+			text.WriteString(part.Text)
+		}
+	}
+	return text.String()
+}
@@ -0,0 +1,59 @@
+// Package stream defines the chunks of streamed model output shared by the
+// llm package, agents and the servers that relay their output, so each
+// consumes provider streams the same way. It has no dependencies, so
+// lightweight packages can use it without pulling in the model providers.
+//
+//	for chunk := range ba.GenerateStream(ctx, prompt) {
+//	    if chunk.Err != nil {
+//	        return chunk.Err
+//	    }
+//	    fmt.Print(chunk.Text)
+//	}
+package stream
+
+import "strings"
+
+// Usage is the token usage of a streamed response.
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+
+	// CostUSD is the cost of the tokens, if the model has a price (see
+	// config.Cost).
+	CostUSD float64 `json:"cost_usd,omitempty"`
+}
+
+// Chunk is an element of a stream of model output. A stream sends chunks
+// of text as they are produced and ends with a chunk with Done set, which
+// carries the usage if it is known, or with a chunk with Err set. The
+// channel is closed after the last chunk.
+type Chunk struct {
+	// Text is the text produced since the previous chunk.
+	Text string `json:"text,omitempty"`
+
+	// Done marks the last chunk of a successful stream.
+	Done bool `json:"done,omitempty"`
+
+	// Usage is the token usage of the response, on the last chunk.
+	Usage *Usage `json:"usage,omitempty"`
+
+	// Err ends a failed stream.
+	Err error `json:"-"`
+}
+
+// Collect reads a stream to its end and returns its text and usage, or the
+// error that ended it.
+func Collect(ch <-chan Chunk) (string, *Usage, error) {
+	var text strings.Builder
+	var usage *Usage
+	for chunk := range ch {
+		if chunk.Err != nil {
+			return text.String(), usage, chunk.Err
+		}
+		text.WriteString(chunk.Text)
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+	}
+	return text.String(), usage, nil
+}
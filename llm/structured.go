@@ -0,0 +1,191 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// StructuredOutputError is returned by GenerateStructured when the model
+// does not produce valid output within its repair attempts.
+type StructuredOutputError struct {
+	// Output is the model's last response.
+	Output string
+
+	// Attempts is the number of model calls made.
+	Attempts int
+
+	// Err is why Output is invalid.
+	Err error
+}
+
+// Error implements error.
+func (e *StructuredOutputError) Error() string {
+	return fmt.Sprintf("invalid structured output after %d attempts: %v", e.Attempts, e.Err)
+}
+
+// Unwrap returns e.Err.
+func (e *StructuredOutputError) Unwrap() error {
+	return e.Err
+}
+
+// StructuredOption configures GenerateStructured.
+type StructuredOption func(*structuredOptions)
+
+type structuredOptions struct {
+	repairs int
+	config  *genai.GenerateContentConfig
+}
+
+// WithRepairAttempts sets how many times GenerateStructured asks the model
+// to correct invalid output.
+// Default: 2
+func WithRepairAttempts(n int) StructuredOption {
+	return func(o *structuredOptions) { o.repairs = max(n, 0) }
+}
+
+// WithGenerateConfig sets the generation config of the model calls, e.g.
+// their temperature or system instruction. GenerateStructured sets its
+// response MIME type and schema.
+func WithGenerateConfig(cfg *genai.GenerateContentConfig) StructuredOption {
+	return func(o *structuredOptions) { o.config = cfg }
+}
+
+// GenerateStructured asks m for a JSON value matching schema and decodes it
+// into a T. schema is a JSON Schema, as a map, json.RawMessage or any value
+// that encodes to one; nil uses SchemaFor[T]. The model's JSON mode is
+// requested where the provider supports it. Output that is not valid JSON,
+// does not match the schema or does not decode into T is sent back to the
+// model with the error for a bounded number of repair attempts.
+//
+//	verdict, err := llm.GenerateStructured[Verdict](ctx, ba.Model, "Classify this message: "+msg, nil)
+func GenerateStructured[T any](ctx context.Context, m model.LLM, prompt string, schema any, opts ...StructuredOption) (T, error) {
+	var result T
+	o := structuredOptions{repairs: 2}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if schema == nil {
+		schema = SchemaFor[T]()
+	}
+	schemaJSON, err := json.Marshal(schema)
+	if err != nil {
+		return result, fmt.Errorf("failed to encode schema: %w", err)
+	}
+	var validator jsonSchema
+	if err := json.Unmarshal(schemaJSON, &validator); err != nil {
+		return result, fmt.Errorf("invalid schema: %w", err)
+	}
+	var responseSchema any
+	_ = json.Unmarshal(schemaJSON, &responseSchema)
+
+	cfg := &genai.GenerateContentConfig{}
+	if o.config != nil {
+		c := *o.config
+		cfg = &c
+	}
+	cfg.ResponseMIMEType = "application/json"
+	cfg.ResponseSchema = nil
+	cfg.ResponseJsonSchema = responseSchema
+
+	req := &model.LLMRequest{
+		Contents: []*genai.Content{genai.NewContentFromText(
+			prompt+"\n\nRespond with only a JSON value, without any other text, that matches this JSON Schema:\n"+string(schemaJSON),
+			genai.RoleUser)},
+		Config: cfg,
+	}
+
+	var output string
+	var invalid error
+	for attempt := 1; ; attempt++ {
+		output, err = generateText(ctx, m, req)
+		if err != nil {
+			return result, err
+		}
+		var value T
+		if invalid = decodeStructured(output, &validator, &value); invalid == nil {
+			return value, nil
+		}
+		if attempt > o.repairs {
+			return result, &StructuredOutputError{Output: output, Attempts: attempt, Err: invalid}
+		}
+		req.Contents = append(req.Contents,
+			genai.NewContentFromText(output, genai.RoleModel),
+			genai.NewContentFromText("That response is invalid: "+invalid.Error()+
+				"\n\nRespond with only the corrected JSON value.", genai.RoleUser))
+	}
+}
+
+// generateText returns the text of m's response to req.
+func generateText(ctx context.Context, m model.LLM, req *model.LLMRequest) (string, error) {
+	var text strings.Builder
+	for resp, err := range m.GenerateContent(ctx, req, false) {
+		if err != nil {
+			return "", fmt.Errorf("model call failed: %w", err)
+		}
+		if resp != nil {
+			text.WriteString(responseText(resp))
+		}
+	}
+	return text.String(), nil
+}
+
+// decodeStructured extracts the JSON value of output, validates it against
+// schema and decodes it into result.
+func decodeStructured(output string, schema *jsonSchema, result any) error {
+	data := extractJSON(output)
+	if data == "" {
+		return fmt.Errorf("no JSON value found")
+	}
+	dec := json.NewDecoder(strings.NewReader(data))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	if err := schema.validate(value, "$"); err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(data), result); err != nil {
+		return fmt.Errorf("cannot decode: %w", err)
+	}
+	return nil
+}
+
+// extractJSON returns the JSON value of a model response, which may be
+// wrapped in a Markdown code fence or surrounded by prose.
+func extractJSON(output string) string {
+	s := strings.TrimSpace(output)
+	if start := strings.Index(s, "```"); start >= 0 {
+		fenced := s[start+3:]
+		if nl := strings.IndexByte(fenced, '\n'); nl >= 0 {
+			fenced = fenced[nl+1:] // drop the language
+		}
+		if end := strings.Index(fenced, "```"); end >= 0 {
+			s = strings.TrimSpace(fenced[:end])
+		}
+	}
+	if json.Valid([]byte(s)) {
+		return s
+	}
+	// Take the text from the first brace or bracket to the last matching
+	// one.
+	start := strings.IndexAny(s, "{[")
+	if start < 0 {
+		return s
+	}
+	closing := byte('}')
+	if s[start] == '[' {
+		closing = ']'
+	}
+	end := strings.LastIndexByte(s, closing)
+	if end < start {
+		return s[start:]
+	}
+	return s[start : end+1]
+}
@@ -0,0 +1,84 @@
+package llm
+
+import (
+	"context"
+	"iter"
+	"strings"
+
+	"google.golang.org/adk/model"
+
+	"github.com/plexusone/agentkit/observability"
+)
+
+// traceModel records each call of a model as an LLM span.
+type traceModel struct {
+	model.LLM
+	key    UsageKey
+	tracer observability.Tracer
+}
+
+// tracedMessage is a request message as recorded in a span.
+type tracedMessage struct {
+	Role string `json:"role"`
+	Text string `json:"text"`
+}
+
+// GenerateContent implements model.LLM.
+func (m *traceModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		input := make([]tracedMessage, 0, len(req.Contents))
+		for _, c := range req.Contents {
+			if c == nil {
+				continue
+			}
+			msg := tracedMessage{Role: c.Role}
+			for _, part := range c.Parts {
+				if part != nil {
+					msg.Text += part.Text
+				}
+			}
+			input = append(input, msg)
+		}
+		ctx, span := m.tracer.Start(ctx, observability.SpanLLM, m.key.Model, input)
+		span.SetModel(m.key.Provider, m.key.Model)
+		if m.key.Agent != "" {
+			span.SetMetadata("agent", m.key.Agent)
+		}
+
+		// A streamed call ends with the aggregate of its partial
+		// responses, if the model sends one.
+		var partial, final strings.Builder
+		var callErr error
+		var promptTokens, completionTokens int64
+		defer func() {
+			if cost := m.key.cost(promptTokens, completionTokens); cost > 0 {
+				span.SetCost(cost)
+			}
+			output := final.String()
+			if output == "" {
+				output = partial.String()
+			}
+			span.End(output, callErr)
+		}()
+		for resp, err := range m.LLM.GenerateContent(ctx, req, stream) {
+			if err != nil {
+				callErr = err
+			}
+			if resp != nil {
+				if resp.UsageMetadata != nil {
+					promptTokens = int64(resp.UsageMetadata.PromptTokenCount)
+					completionTokens = int64(resp.UsageMetadata.CandidatesTokenCount)
+					span.SetUsage(int(promptTokens), int(completionTokens))
+				}
+				if resp.Partial {
+					partial.WriteString(responseText(resp))
+				} else {
+					final.WriteString(responseText(resp))
+				}
+			}
+			if !yield(resp, err) {
+				return
+			}
+		}
+	}
+}
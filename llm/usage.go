@@ -0,0 +1,276 @@
+package llm
+
+import (
+	"context"
+	"iter"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/adk/model"
+
+	"github.com/plexusone/agentkit/config"
+)
+
+// UsageKey identifies the models whose calls are counted together.
+type UsageKey struct {
+	// Agent is the name passed to CreateModelForAgent, or "" for models
+	// created with CreateModel.
+	Agent string `json:"agent"`
+
+	// Provider is the LLM provider, e.g. "gemini" or "claude".
+	Provider string `json:"provider"`
+
+	// Model is the provider's model name, with aliases resolved.
+	Model string `json:"model"`
+}
+
+// cost returns the cost of a call of the model of k with the given token
+// usage, or 0 if the model has no price.
+func (k UsageKey) cost(promptTokens, completionTokens int64) float64 {
+	cost, _ := config.Cost(k.Provider, k.Model, int(promptTokens), int(completionTokens))
+	return cost
+}
+
+// UsageStats is the token usage of the model calls of a UsageKey.
+type UsageStats struct {
+	UsageKey
+
+	// Calls is the number of model calls, and Errors how many of them
+	// failed.
+	Calls  int64 `json:"calls"`
+	Errors int64 `json:"errors"`
+
+	// PromptTokens and CompletionTokens are the tokens reported by the
+	// provider. Calls whose provider reports no usage count as zero.
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+
+	// CostUSD is the cost of the tokens, priced with config.Cost. Models
+	// without a price cost nothing.
+	CostUSD float64 `json:"cost_usd"`
+
+	// Latency is the total time from the start of the calls to their last
+	// response.
+	Latency time.Duration `json:"latency"`
+}
+
+// TotalTokens returns the prompt and completion tokens of s.
+func (s UsageStats) TotalTokens() int64 {
+	return s.PromptTokens + s.CompletionTokens
+}
+
+// AverageLatency returns the mean latency of the calls of s.
+func (s UsageStats) AverageLatency() time.Duration {
+	if s.Calls == 0 {
+		return 0
+	}
+	return s.Latency / time.Duration(s.Calls)
+}
+
+// add adds the counts of o to s.
+func (s *UsageStats) add(o UsageStats) {
+	s.Calls += o.Calls
+	s.Errors += o.Errors
+	s.PromptTokens += o.PromptTokens
+	s.CompletionTokens += o.CompletionTokens
+	s.CostUSD += o.CostUSD
+	s.Latency += o.Latency
+}
+
+// UsageSnapshot is the token usage of a ModelFactory's models at a point
+// in time.
+type UsageSnapshot struct {
+	// Since is when counting started: when the factory was created or its
+	// usage last reset.
+	Since time.Time `json:"since"`
+
+	// Time is when the snapshot was taken.
+	Time time.Time `json:"time"`
+
+	// Stats holds one entry per agent, provider and model, sorted by agent,
+	// provider, then model.
+	Stats []UsageStats `json:"stats"`
+}
+
+// Total returns the usage of all models.
+func (s UsageSnapshot) Total() UsageStats {
+	var total UsageStats
+	for _, stats := range s.Stats {
+		total.add(stats)
+	}
+	return total
+}
+
+// ByAgent returns the usage of the models of each agent, over all
+// providers and models, keyed by agent name.
+func (s UsageSnapshot) ByAgent() map[string]UsageStats {
+	agents := make(map[string]UsageStats)
+	for _, stats := range s.Stats {
+		agent := agents[stats.Agent]
+		agent.Agent = stats.Agent
+		agent.add(stats)
+		agents[stats.Agent] = agent
+	}
+	return agents
+}
+
+// UsageExporter receives the snapshots of ExportUsage, e.g. to send them to
+// a metrics backend.
+type UsageExporter func(ctx context.Context, snapshot UsageSnapshot) error
+
+// LogUsage returns an exporter that logs one line per agent, provider and
+// model to logger, or slog.Default() if it is nil.
+func LogUsage(logger *slog.Logger) UsageExporter {
+	return func(ctx context.Context, snapshot UsageSnapshot) error {
+		l := logger
+		if l == nil {
+			l = slog.Default()
+		}
+		for _, s := range snapshot.Stats {
+			l.InfoContext(ctx, "llm usage",
+				"agent", s.Agent,
+				"provider", s.Provider,
+				"model", s.Model,
+				"calls", s.Calls,
+				"errors", s.Errors,
+				"prompt_tokens", s.PromptTokens,
+				"completion_tokens", s.CompletionTokens,
+				"cost_usd", s.CostUSD,
+				"avg_latency", s.AverageLatency(),
+			)
+		}
+		return nil
+	}
+}
+
+// usageTracker counts the usage of a factory's models.
+type usageTracker struct {
+	mu    sync.Mutex
+	since time.Time
+	stats map[UsageKey]*UsageStats
+}
+
+// newUsageTracker creates an empty tracker.
+func newUsageTracker() *usageTracker {
+	return &usageTracker{since: time.Now(), stats: make(map[UsageKey]*UsageStats)}
+}
+
+// record adds a model call to the usage of key.
+func (t *usageTracker) record(key UsageKey, call UsageStats) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.stats[key]
+	if !ok {
+		s = &UsageStats{UsageKey: key}
+		t.stats[key] = s
+	}
+	s.add(call)
+}
+
+// snapshot returns the usage counted so far, and restarts counting if
+// reset is set.
+func (t *usageTracker) snapshot(reset bool) UsageSnapshot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	snap := UsageSnapshot{Since: t.since, Time: time.Now(), Stats: make([]UsageStats, 0, len(t.stats))}
+	for _, s := range t.stats {
+		snap.Stats = append(snap.Stats, *s)
+	}
+	sort.Slice(snap.Stats, func(i, j int) bool {
+		a, b := snap.Stats[i].UsageKey, snap.Stats[j].UsageKey
+		if a.Agent != b.Agent {
+			return a.Agent < b.Agent
+		}
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		return a.Model < b.Model
+	})
+	if reset {
+		t.since = snap.Time
+		t.stats = make(map[UsageKey]*UsageStats)
+	}
+	return snap
+}
+
+// Usage returns the token usage of the models the factory has created
+// since it was created or its usage last reset.
+func (mf *ModelFactory) Usage() UsageSnapshot {
+	return mf.usage.snapshot(false)
+}
+
+// ResetUsage returns the token usage like Usage and restarts counting.
+func (mf *ModelFactory) ResetUsage() UsageSnapshot {
+	return mf.usage.snapshot(true)
+}
+
+// ExportUsage calls export with a snapshot of the factory's usage every
+// interval until ctx is done or the factory is closed, and once more then.
+// Each snapshot holds the usage since the previous one if reset is set, or
+// since the factory was created otherwise. Export errors are logged.
+//
+//	go factory.ExportUsage(ctx, time.Minute, llm.LogUsage(nil), false)
+func (mf *ModelFactory) ExportUsage(ctx context.Context, interval time.Duration, export UsageExporter, reset bool) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	send := func(ctx context.Context) {
+		if err := export(ctx, mf.usage.snapshot(reset)); err != nil {
+			slog.WarnContext(ctx, "failed to export LLM usage", "error", err)
+		}
+	}
+	for {
+		select {
+		case <-ticker.C:
+			send(ctx)
+		case <-ctx.Done():
+			send(context.WithoutCancel(ctx))
+			return
+		case <-mf.closed:
+			send(ctx)
+			return
+		}
+	}
+}
+
+// servedKey is the context key of the UsageKey of the model that served a
+// call, set by usageModel for Stream to price the call, also when a
+// Router picks the model.
+type servedKey struct{}
+
+// usageModel records the usage of the calls of a model.
+type usageModel struct {
+	model.LLM
+	key     UsageKey
+	tracker *usageTracker
+}
+
+// GenerateContent implements model.LLM.
+func (m *usageModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		if served, ok := ctx.Value(servedKey{}).(*UsageKey); ok {
+			*served = m.key
+		}
+		start := time.Now()
+		call := UsageStats{Calls: 1}
+		defer func() {
+			call.Latency = time.Since(start)
+			call.CostUSD = m.key.cost(call.PromptTokens, call.CompletionTokens)
+			m.tracker.record(m.key, call)
+		}()
+		for resp, err := range m.LLM.GenerateContent(ctx, req, stream) {
+			if err != nil {
+				call.Errors = 1
+			}
+			// Streamed responses report the usage so far, so the last wins.
+			if resp != nil && resp.UsageMetadata != nil {
+				call.PromptTokens = int64(resp.UsageMetadata.PromptTokenCount)
+				call.CompletionTokens = int64(resp.UsageMetadata.CandidatesTokenCount)
+			}
+			if !yield(resp, err) {
+				return
+			}
+		}
+	}
+}
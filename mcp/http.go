@@ -0,0 +1,164 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	agenthttp "github.com/plexusone/agentkit/http"
+	"github.com/plexusone/agentkit/platforms/agentcore"
+)
+
+// HTTPProtocolVersion is the MCP protocol version of the HTTP transport.
+const HTTPProtocolVersion = "2025-03-26"
+
+// HTTPHandler serves agents as MCP tools over the Streamable HTTP
+// transport, so MCP clients can call them remotely. Each agent of the
+// registry is a tool named after it, taking a prompt and an optional
+// session ID. Responses are plain JSON; the handler does not open event
+// streams.
+type HTTPHandler struct {
+	registry   *agentcore.Registry
+	serverInfo ServerInfo
+}
+
+// NewHTTPHandler creates an MCP handler for the agents of registry.
+func NewHTTPHandler(registry *agentcore.Registry, name, version string) *HTTPHandler {
+	return &HTTPHandler{
+		registry: registry,
+		serverInfo: ServerInfo{
+			Name:    name,
+			Version: version,
+		},
+	}
+}
+
+// ServeHTTP implements http.Handler. It answers JSON-RPC requests POSTed
+// to it, and accepts notifications with 202 Accepted.
+func (h *HTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req Request
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 10*1024*1024)).Decode(&req); err != nil {
+		h.write(w, errorResponse(nil, ErrParseError, "Parse error", err.Error()))
+		return
+	}
+	if req.ID == nil {
+		// Notifications, e.g. notifications/initialized, need no answer.
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	switch req.Method {
+	case "initialize":
+		h.write(w, &Response{JSONRPC: "2.0", ID: req.ID, Result: InitializeResult{
+			ProtocolVersion: HTTPProtocolVersion,
+			Capabilities:    Capabilities{Tools: &ToolsCapability{}},
+			ServerInfo:      h.serverInfo,
+		}})
+	case "ping":
+		h.write(w, &Response{JSONRPC: "2.0", ID: req.ID, Result: struct{}{}})
+	case "tools/list":
+		h.write(w, &Response{JSONRPC: "2.0", ID: req.ID, Result: ListToolsResult{Tools: h.tools()}})
+	case "tools/call":
+		h.write(w, h.call(r, &req))
+	default:
+		h.write(w, errorResponse(req.ID, ErrMethodNotFound, "Method not found", nil))
+	}
+}
+
+// tools returns a tool per agent, sorted by name.
+func (h *HTTPHandler) tools() []ToolInfo {
+	names := h.registry.List()
+	sort.Strings(names)
+	tools := make([]ToolInfo, 0, len(names))
+	for _, name := range names {
+		tools = append(tools, ToolInfo{
+			Name:        name,
+			Description: fmt.Sprintf("Invoke the %s agent with a prompt", name),
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"prompt": {
+						Type:        "string",
+						Description: "Input prompt for the agent",
+					},
+					"session_id": {
+						Type:        "string",
+						Description: "Optional session ID to continue a conversation",
+					},
+				},
+				Required: []string{"prompt"},
+			},
+		})
+	}
+	return tools
+}
+
+// call invokes the agent named by a tools/call request. Agent failures
+// are tool results with IsError set, as MCP prescribes, not protocol
+// errors.
+func (h *HTTPHandler) call(r *http.Request, req *Request) *Response {
+	var params CallToolParams
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return errorResponse(req.ID, ErrInvalidParams, "Invalid params", err.Error())
+	}
+	if _, err := h.registry.Get(params.Name); err != nil || params.Name == "" {
+		return errorResponse(req.ID, ErrInvalidParams, "Unknown tool", params.Name)
+	}
+	prompt, _ := params.Arguments["prompt"].(string)
+	sessionID, _ := params.Arguments["session_id"].(string)
+	if strings.TrimSpace(prompt) == "" {
+		return errorResponse(req.ID, ErrInvalidParams, "prompt is required", nil)
+	}
+
+	ctx := r.Context()
+	if id := r.Header.Get(agenthttp.CorrelationIDHeader); agenthttp.CorrelationID(ctx) == "" && agenthttp.ValidCorrelationID(id) {
+		ctx = agenthttp.WithCorrelationID(ctx, id)
+	}
+	ctx, correlationID := correlate(ctx, &params)
+	agentReq := agentcore.Request{
+		Prompt:    prompt,
+		SessionID: sessionID,
+		Agent:     params.Name,
+		Metadata:  map[string]string{agentcore.MetadataCorrelationID: correlationID},
+	}
+	ctx = agentcore.NewSessionContext(ctx, sessionID, &agentReq)
+	resp, err := h.registry.Invoke(ctx, agentReq)
+	result := CallToolResult{Content: []ContentBlock{NewTextContent(resp.Output)}}
+	switch {
+	case err != nil:
+		result = CallToolResult{Content: []ContentBlock{NewErrorContent(err)}, IsError: true}
+	case resp.Error != "":
+		result = CallToolResult{Content: []ContentBlock{NewTextContent("Error: " + resp.Error)}, IsError: true}
+	}
+	return &Response{JSONRPC: "2.0", ID: req.ID, Result: withCorrelationID(result, correlationID)}
+}
+
+// write sends resp as JSON.
+func (h *HTTPHandler) write(w http.ResponseWriter, resp *Response) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("[MCP] Write error: %v", err)
+	}
+}
+
+// errorResponse returns a JSON-RPC error response.
+func errorResponse(id json.RawMessage, code int, message string, data interface{}) *Response {
+	return &Response{
+		JSONRPC: "2.0",
+		ID:      id,
+		Error: &ErrorResponse{
+			Code:    code,
+			Message: message,
+			Data:    data,
+		},
+	}
+}
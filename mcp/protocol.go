@@ -117,16 +117,23 @@ type ListToolsResult struct {
 	Tools []ToolInfo `json:"tools"`
 }
 
+// MetaCorrelationID is the _meta key of the correlation ID of tool calls
+// and their results, see agenthttp.CorrelationID. Clients may set it to
+// tie a call to their own request; results always carry it.
+const MetaCorrelationID = "correlationId"
+
 // CallToolParams represents the tools/call request parameters.
 type CallToolParams struct {
 	Name      string                 `json:"name"`
 	Arguments map[string]interface{} `json:"arguments,omitempty"`
+	Meta      map[string]interface{} `json:"_meta,omitempty"`
 }
 
 // CallToolResult represents the tools/call response.
 type CallToolResult struct {
-	Content []ContentBlock `json:"content"`
-	IsError bool           `json:"isError,omitempty"`
+	Content []ContentBlock         `json:"content"`
+	IsError bool                   `json:"isError,omitempty"`
+	Meta    map[string]interface{} `json:"_meta,omitempty"`
 }
 
 // ContentBlock represents a content block in a tool result.
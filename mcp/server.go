@@ -10,6 +10,7 @@ import (
 	"os"
 	"strings"
 
+	agenthttp "github.com/plexusone/agentkit/http"
 	"github.com/plexusone/agentkit/platforms/local"
 )
 
@@ -251,7 +252,8 @@ func (s *Server) handleToolsCall(ctx context.Context, req *Request) *Response {
 		return s.errorResponse(req.ID, ErrInvalidParams, "Invalid params", err)
 	}
 
-	log.Printf("[MCP] Tool call: %s", params.Name)
+	ctx, correlationID := correlate(ctx, &params)
+	log.Printf("[MCP] Tool call: %s (correlation_id=%s)", params.Name, correlationID)
 
 	var result CallToolResult
 
@@ -277,8 +279,31 @@ func (s *Server) handleToolsCall(ctx context.Context, req *Request) *Response {
 	return &Response{
 		JSONRPC: "2.0",
 		ID:      req.ID,
-		Result:  result,
+		Result:  withCorrelationID(result, correlationID),
+	}
+}
+
+// correlate returns ctx with the correlation ID of a tool call with
+// params: the one ctx already has, the MetaCorrelationID of params, or a
+// new one.
+func correlate(ctx context.Context, params *CallToolParams) (context.Context, string) {
+	if id := agenthttp.CorrelationID(ctx); id != "" {
+		return ctx, id
+	}
+	if id, _ := params.Meta[MetaCorrelationID].(string); agenthttp.ValidCorrelationID(id) {
+		return agenthttp.WithCorrelationID(ctx, id), id
+	}
+	return agenthttp.EnsureCorrelationID(ctx)
+}
+
+// withCorrelationID returns result with the correlation ID id in its
+// _meta.
+func withCorrelationID(result CallToolResult, id string) CallToolResult {
+	if result.Meta == nil {
+		result.Meta = map[string]interface{}{}
 	}
+	result.Meta[MetaCorrelationID] = id
+	return result
 }
 
 // Tool handlers
@@ -517,15 +542,7 @@ func (s *Server) handlePromptsGet(req *Request) *Response {
 // Helper methods
 
 func (s *Server) errorResponse(id json.RawMessage, code int, message string, data interface{}) *Response {
-	return &Response{
-		JSONRPC: "2.0",
-		ID:      id,
-		Error: &ErrorResponse{
-			Code:    code,
-			Message: message,
-			Data:    data,
-		},
-	}
+	return errorResponse(id, code, message, data)
 }
 
 func (s *Server) writeResponse(w io.Writer, resp *Response) error {
@@ -0,0 +1,40 @@
+package observability
+
+import (
+	"fmt"
+
+	"github.com/plexusone/omniobserve/llmops"
+
+	"github.com/plexusone/agentkit/config"
+
+	// Import observability providers (driver registration via init())
+	_ "github.com/plexusone/omniobserve/llmops/langfuse"
+	_ "github.com/plexusone/opik-go/llmops"
+	_ "github.com/plexusone/phoenix-go/llmops"
+)
+
+// New creates the Tracer configured by the observability settings of cfg:
+// ObservabilityProvider (opik, langfuse or phoenix), with its API key,
+// endpoint and project. It returns a Tracer that records nothing if
+// observability is disabled.
+func New(cfg *config.Config) (Tracer, error) {
+	if !cfg.ObservabilityEnabled || cfg.ObservabilityProvider == "" {
+		return Noop(), nil
+	}
+
+	opts := []llmops.ClientOption{
+		llmops.WithProjectName(cfg.ObservabilityProject),
+	}
+	if cfg.ObservabilityAPIKey != "" {
+		opts = append(opts, llmops.WithAPIKey(cfg.ObservabilityAPIKey))
+	}
+	if cfg.ObservabilityEndpoint != "" {
+		opts = append(opts, llmops.WithEndpoint(cfg.ObservabilityEndpoint))
+	}
+
+	provider, err := llmops.Open(cfg.ObservabilityProvider, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize observability provider %s: %w", cfg.ObservabilityProvider, err)
+	}
+	return NewLLMOpsTracer(provider), nil
+}
@@ -0,0 +1,167 @@
+package observability
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/plexusone/omniobserve/llmops"
+
+	agenthttp "github.com/plexusone/agentkit/http"
+)
+
+// LLMOpsTracer is a Tracer that records spans with an omniobserve llmops
+// provider, such as the Opik, Langfuse or Phoenix driver.
+type LLMOpsTracer struct {
+	provider llmops.Provider
+}
+
+// NewLLMOpsTracer creates a Tracer that records spans with provider. The
+// tracer closes provider when it is closed.
+func NewLLMOpsTracer(provider llmops.Provider) *LLMOpsTracer {
+	return &LLMOpsTracer{provider: provider}
+}
+
+// Provider returns the llmops provider of the tracer.
+func (t *LLMOpsTracer) Provider() llmops.Provider {
+	return t.provider
+}
+
+type llmopsSpanKey struct{}
+
+// Start implements Tracer. Without a span in ctx it starts a trace named
+// name, in the session of ctx, and the span as its first child. Failing
+// to start a span is logged, and returns a span that records nothing.
+func (t *LLMOpsTracer) Start(ctx context.Context, kind SpanKind, name string, input any) (context.Context, Span) {
+	opts := []llmops.SpanOption{llmops.WithSpanType(spanType(kind))}
+	if input != nil {
+		opts = append(opts, llmops.WithSpanInput(input))
+	}
+
+	s := &llmopsSpan{}
+	parent, _ := ctx.Value(llmopsSpanKey{}).(llmops.Span)
+	var err error
+	if parent != nil {
+		ctx, s.span, err = parent.StartSpan(ctx, name, opts...)
+	} else {
+		traceOpts := []llmops.TraceOption{}
+		if input != nil {
+			traceOpts = append(traceOpts, llmops.WithTraceInput(input))
+		}
+		if id := SessionID(ctx); id != "" {
+			traceOpts = append(traceOpts, llmops.WithThreadID(id))
+		}
+		// The traces of each agent a request reaches share its
+		// correlation ID, by which they can be found together.
+		if id := agenthttp.CorrelationID(ctx); id != "" {
+			traceOpts = append(traceOpts, llmops.WithTraceMetadata(map[string]any{"correlation_id": id}))
+		}
+		ctx, s.trace, err = t.provider.StartTrace(ctx, name, traceOpts...)
+		if err == nil {
+			ctx, s.span, err = s.trace.StartSpan(ctx, name, opts...)
+			if err != nil {
+				_ = s.trace.End()
+			}
+		}
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "failed to start trace span", "provider", t.provider.Name(), "span", name, "error", err)
+		return ctx, noopSpan{}
+	}
+	return context.WithValue(ctx, llmopsSpanKey{}, s.span), s
+}
+
+// Close implements Tracer.
+func (t *LLMOpsTracer) Close() error {
+	return t.provider.Close()
+}
+
+// llmopsSpan is a span, and the trace it is the root of, if any.
+type llmopsSpan struct {
+	mu    sync.Mutex
+	span  llmops.Span
+	trace llmops.Trace
+	usage llmops.TokenUsage
+	ended bool
+}
+
+// SetModel implements Span.
+func (s *llmopsSpan) SetModel(provider, model string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+	_ = s.span.SetProvider(provider)
+	_ = s.span.SetModel(model)
+}
+
+// SetUsage implements Span.
+func (s *llmopsSpan) SetUsage(promptTokens, completionTokens int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+	s.usage.PromptTokens = promptTokens
+	s.usage.CompletionTokens = completionTokens
+	s.usage.TotalTokens = promptTokens + completionTokens
+	_ = s.span.SetUsage(s.usage)
+}
+
+// SetCost implements Span.
+func (s *llmopsSpan) SetCost(usd float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+	s.usage.TotalCost = usd
+	s.usage.Currency = "USD"
+	_ = s.span.SetUsage(s.usage)
+}
+
+// SetMetadata implements Span.
+func (s *llmopsSpan) SetMetadata(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+	_ = s.span.SetMetadata(map[string]any{key: value})
+}
+
+// End implements Span.
+func (s *llmopsSpan) End(output any, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+	s.ended = true
+	opts := []llmops.EndOption{}
+	if output != nil {
+		opts = append(opts, llmops.WithEndOutput(output))
+	}
+	if err != nil {
+		opts = append(opts, llmops.WithEndError(err))
+	}
+	_ = s.span.End(opts...)
+	if s.trace != nil {
+		_ = s.trace.End(opts...)
+	}
+}
+
+// spanType returns the llmops span type of kind.
+func spanType(kind SpanKind) llmops.SpanType {
+	switch kind {
+	case SpanAgent:
+		return llmops.SpanTypeAgent
+	case SpanLLM:
+		return llmops.SpanTypeLLM
+	case SpanTool:
+		return llmops.SpanTypeTool
+	default:
+		return llmops.SpanTypeGeneral
+	}
+}
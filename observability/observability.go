@@ -0,0 +1,104 @@
+// Package observability traces agent invocations and the LLM and tool
+// calls they make to an LLM observability platform: Comet Opik, Langfuse
+// or Arize Phoenix.
+//
+// A Tracer is created from the observability settings of the config with
+// New, and passed to the components that record spans: agent.BaseAgent
+// (through llm.ModelFactory), local.EmbeddedAgent and agentcore.Server.
+// A span started in the context of another becomes its child, so the
+// invocation of an agent is one trace, with a span per LLM and tool call.
+package observability
+
+import (
+	"context"
+)
+
+// SpanKind categorizes spans by the work they record.
+type SpanKind string
+
+const (
+	// SpanAgent is an agent invocation.
+	SpanAgent SpanKind = "agent"
+
+	// SpanLLM is a model call.
+	SpanLLM SpanKind = "llm"
+
+	// SpanTool is a tool call.
+	SpanTool SpanKind = "tool"
+)
+
+// Tracer starts spans. Implementations must be safe for concurrent use.
+type Tracer interface {
+	// Start starts a span of kind named name with input, as a child of the
+	// span of ctx, or as the root of a new trace if ctx has none. The
+	// returned context carries the span.
+	Start(ctx context.Context, kind SpanKind, name string, input any) (context.Context, Span)
+
+	// Close sends pending traces and releases the tracer's resources.
+	Close() error
+}
+
+// Span records one unit of work. Its methods must be safe to call after
+// End, which makes them no-ops.
+type Span interface {
+	// SetModel records the LLM provider and model of an LLM span.
+	SetModel(provider, model string)
+
+	// SetUsage records the token usage of an LLM or agent span.
+	SetUsage(promptTokens, completionTokens int)
+
+	// SetCost records the cost in USD of the tokens of the span.
+	SetCost(usd float64)
+
+	// SetMetadata adds a metadata entry to the span.
+	SetMetadata(key string, value any)
+
+	// End ends the span with its output, or the error it failed with.
+	End(output any, err error)
+}
+
+// Noop returns a Tracer that records nothing.
+func Noop() Tracer {
+	return noopTracer{}
+}
+
+// OrNoop returns t, or a Tracer that records nothing if t is nil.
+func OrNoop(t Tracer) Tracer {
+	if t == nil {
+		return noopTracer{}
+	}
+	return t
+}
+
+type noopTracer struct{}
+
+// Start implements Tracer.
+func (noopTracer) Start(ctx context.Context, _ SpanKind, _ string, _ any) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+// Close implements Tracer.
+func (noopTracer) Close() error { return nil }
+
+type noopSpan struct{}
+
+func (noopSpan) SetModel(string, string) {}
+func (noopSpan) SetUsage(int, int)       {}
+func (noopSpan) SetCost(float64)         {}
+func (noopSpan) SetMetadata(string, any) {}
+func (noopSpan) End(any, error)          {}
+
+type sessionKey struct{}
+
+// WithSessionID returns a context in which new traces belong to the
+// session, or thread, id, so that platforms group the invocations of a
+// conversation.
+func WithSessionID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, id)
+}
+
+// SessionID returns the session ID set by WithSessionID, or "".
+func SessionID(ctx context.Context) string {
+	id, _ := ctx.Value(sessionKey{}).(string)
+	return id
+}
@@ -0,0 +1,282 @@
+package orchestration
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/compose"
+)
+
+// ErrApprovalRejected is returned by an approval node when the request is
+// rejected, aborting the workflow.
+var ErrApprovalRejected = errors.New("approval rejected")
+
+// ErrApprovalNotFound is returned by Approvals.Decide when no request with
+// the token is pending.
+var ErrApprovalNotFound = errors.New("approval request not found")
+
+// ApprovalRequest describes a workflow paused for a human decision.
+type ApprovalRequest struct {
+	// Token identifies the request when deciding it.
+	Token string `json:"token"`
+
+	// Node is the approval node that paused.
+	Node string `json:"node"`
+
+	// RunID is the run being approved, when running under WithCheckpoints.
+	RunID string `json:"run_id,omitempty"`
+
+	// State is the JSON-encoded state awaiting approval.
+	State json.RawMessage `json:"state"`
+
+	// CreatedAt is when the workflow paused.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ApprovalDecision is a human decision on an ApprovalRequest.
+type ApprovalDecision struct {
+	// Approved continues the workflow; false aborts it.
+	Approved bool `json:"approved"`
+
+	// Approver identifies who decided.
+	Approver string `json:"approver,omitempty"`
+
+	// Comment explains the decision.
+	Comment string `json:"comment,omitempty"`
+
+	// DecidedAt is when the decision was made. Decide sets it if zero.
+	DecidedAt time.Time `json:"decided_at"`
+}
+
+// pendingApproval is a request waiting for its decision.
+type pendingApproval struct {
+	request  *ApprovalRequest
+	decision chan ApprovalDecision
+}
+
+// Approvals tracks the approval requests of running workflows. Approval
+// nodes wait on it until Decide is called with their token, directly or
+// through Handler.
+type Approvals struct {
+	// Notify is called when a node starts waiting, e.g. to post the
+	// request's token or review link to a chat channel. A Notify error
+	// fails the node.
+	// Default: nil (no notification)
+	Notify func(ctx context.Context, req *ApprovalRequest) error
+
+	// Store persists pending requests, keyed by token, so they can be
+	// inspected outside the process. Requests are deleted once decided.
+	// Default: nil (not persisted)
+	Store CheckpointStore
+
+	// Timeout bounds how long a node waits for a decision.
+	// Default: 0 (wait until the context is done)
+	Timeout time.Duration
+
+	mu      sync.Mutex
+	pending map[string]*pendingApproval
+}
+
+// NewApprovals creates an empty approval registry.
+func NewApprovals() *Approvals {
+	return &Approvals{pending: make(map[string]*pendingApproval)}
+}
+
+// Pending returns the requests waiting for a decision, oldest first.
+func (a *Approvals) Pending() []*ApprovalRequest {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	requests := make([]*ApprovalRequest, 0, len(a.pending))
+	for _, p := range a.pending {
+		requests = append(requests, p.request)
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].CreatedAt.Before(requests[j].CreatedAt)
+	})
+	return requests
+}
+
+// Decide resumes the node waiting on token with decision. It returns
+// ErrApprovalNotFound if no request with the token is pending.
+func (a *Approvals) Decide(token string, decision ApprovalDecision) error {
+	a.mu.Lock()
+	p, ok := a.pending[token]
+	if ok {
+		delete(a.pending, token)
+	}
+	a.mu.Unlock()
+	if !ok {
+		return ErrApprovalNotFound
+	}
+
+	if decision.DecidedAt.IsZero() {
+		decision.DecidedAt = time.Now().UTC()
+	}
+	p.decision <- decision
+	return nil
+}
+
+// wait registers a request and blocks until it is decided, ctx is done or
+// the timeout expires.
+func (a *Approvals) wait(ctx context.Context, req *ApprovalRequest) (ApprovalDecision, error) {
+	p := &pendingApproval{request: req, decision: make(chan ApprovalDecision, 1)}
+	a.mu.Lock()
+	if a.pending == nil {
+		a.pending = make(map[string]*pendingApproval)
+	}
+	a.pending[req.Token] = p
+	a.mu.Unlock()
+
+	defer func() {
+		a.mu.Lock()
+		delete(a.pending, req.Token)
+		a.mu.Unlock()
+		if a.Store != nil {
+			if err := a.Store.Delete(context.WithoutCancel(ctx), req.Token); err != nil {
+				log.Printf("[%s] Failed to delete approval request %s: %v", req.Node, req.Token, err)
+			}
+		}
+	}()
+
+	if a.Store != nil {
+		err := a.Store.Save(ctx, &Checkpoint{
+			RunID:     req.Token,
+			Steps:     []string{req.Node},
+			State:     req.State,
+			UpdatedAt: req.CreatedAt,
+		})
+		if err != nil {
+			return ApprovalDecision{}, fmt.Errorf("persisting approval request: %w", err)
+		}
+	}
+	if a.Notify != nil {
+		if err := a.Notify(ctx, req); err != nil {
+			return ApprovalDecision{}, fmt.Errorf("notifying approvers: %w", err)
+		}
+	}
+
+	if a.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.Timeout)
+		defer cancel()
+	}
+	select {
+	case decision := <-p.decision:
+		return decision, nil
+	case <-ctx.Done():
+		return ApprovalDecision{}, fmt.Errorf("waiting for approval: %w", ctx.Err())
+	}
+}
+
+// ApprovalKey returns the State.Metadata key under which an approval node
+// records its decision.
+func ApprovalKey(node string) string {
+	return "approval." + node
+}
+
+// ApprovalNode returns a lambda node that pauses the workflow until the
+// request it registers with approvals is decided. An approved state passes
+// through unchanged; a rejection fails the node with ErrApprovalRejected.
+// If the state embeds State, the decision is recorded in its metadata; see
+// ApprovalKey.
+//
+//	approvals := orchestration.NewApprovals()
+//	gb.AddLambdaNodeFunc("approve_publish",
+//	    orchestration.ApprovalNode[*ReportState](approvals, "approve_publish"))
+//	mux.Handle("/approvals", approvals.Handler())
+//
+// The wait does not survive a restart. Under WithCheckpoints, executing the
+// run again resumes from the last checkpoint and registers a new request.
+func ApprovalNode[S any](approvals *Approvals, node string) *compose.Lambda {
+	return compose.InvokableLambda(func(ctx context.Context, state S) (S, error) {
+		data, err := json.Marshal(state)
+		if err != nil {
+			return state, fmt.Errorf("node %s: encoding state: %w", node, err)
+		}
+		token, err := newApprovalToken()
+		if err != nil {
+			return state, fmt.Errorf("node %s: %w", node, err)
+		}
+		req := &ApprovalRequest{
+			Token:     token,
+			Node:      node,
+			State:     data,
+			CreatedAt: time.Now().UTC(),
+		}
+		if run, ok := ctx.Value(checkpointRunKey{}).(*checkpointRun); ok {
+			req.RunID = run.runID
+		}
+
+		log.Printf("[%s] Waiting for approval %s", node, token)
+		decision, err := approvals.wait(ctx, req)
+		if err != nil {
+			return state, fmt.Errorf("node %s: %w", node, err)
+		}
+
+		if m, ok := any(state).(metadataSetter); ok && !isNilPointer(m) {
+			m.SetMetadata(ApprovalKey(node), decision)
+		}
+		if !decision.Approved {
+			log.Printf("[%s] Rejected by %q: %s", node, decision.Approver, decision.Comment)
+			return state, fmt.Errorf("node %s: %w (approver %q: %s)", node, ErrApprovalRejected, decision.Approver, decision.Comment)
+		}
+		log.Printf("[%s] Approved by %q", node, decision.Approver)
+		return state, nil
+	})
+}
+
+// newApprovalToken returns a random request token.
+func newApprovalToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating approval token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// decideRequest is the body of a decision posted to Handler.
+type decideRequest struct {
+	Token string `json:"token"`
+	ApprovalDecision
+}
+
+// Handler returns an HTTP handler for approvers. GET lists the pending
+// requests; POST decides one with a JSON body such as
+//
+//	{"token": "...", "approved": true, "approver": "alice", "comment": "LGTM"}
+//
+// The handler does no authentication; mount it behind the server's auth
+// middleware.
+func (a *Approvals) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(a.Pending()); err != nil {
+				log.Printf("Failed to encode response: %v", err)
+			}
+		case http.MethodPost:
+			var req decideRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+				return
+			}
+			if err := a.Decide(req.Token, req.ApprovalDecision); err != nil {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
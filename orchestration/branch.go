@@ -0,0 +1,49 @@
+package orchestration
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudwego/eino/compose"
+)
+
+// AddBranch routes the output of node from to one of several nodes.
+// condition inspects the output and returns a route key, and routes maps
+// each key to the node to run next; use compose.END to end the workflow.
+// A key missing from routes fails the workflow.
+//
+// AddBranch is a function rather than a GraphBuilder method because the
+// state type S is the output type of from, not of the graph:
+//
+//	err := orchestration.AddBranch(gb, "quality_gate",
+//	    func(s *ReviewState) string {
+//	        if s.Decision.Passed {
+//	            return "pass"
+//	        }
+//	        return "fail"
+//	    },
+//	    map[string]string{"pass": "publish", "fail": "revise"})
+func AddBranch[I, O, S any](gb *GraphBuilder[I, O], from string, condition func(state S) string, routes map[string]string) error {
+	if len(routes) == 0 {
+		return fmt.Errorf("branch from %s: no routes", from)
+	}
+
+	endNodes := make(map[string]bool, len(routes))
+	for _, to := range routes {
+		endNodes[to] = true
+	}
+
+	branch := compose.NewGraphBranch(func(ctx context.Context, state S) (string, error) {
+		key := condition(state)
+		to, ok := routes[key]
+		if !ok {
+			return "", fmt.Errorf("branch from %s: no route for %q", from, key)
+		}
+		return to, nil
+	}, endNodes)
+
+	if err := gb.graph.AddBranch(from, branch); err != nil {
+		return fmt.Errorf("failed to add branch from %s: %w", from, err)
+	}
+	return nil
+}
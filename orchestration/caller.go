@@ -0,0 +1,117 @@
+package orchestration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	agenthttp "github.com/plexusone/agentkit/http"
+)
+
+// ErrCircuitOpen is returned by AgentCaller while its circuit breaker is
+// open, without calling the agent. It is http.ErrCircuitOpen.
+var ErrCircuitOpen = agenthttp.ErrCircuitOpen
+
+// CircuitBreakerConfig configures the circuit breaker of an AgentCaller,
+// which counts calls that fail with a retryable error. It is the shared
+// http.CircuitBreakerConfig.
+type CircuitBreakerConfig = agenthttp.CircuitBreakerConfig
+
+// AgentCaller provides methods for calling other agents via HTTP. Calls
+// are retried with backoff while they fail with a transient error, such as
+// a network error or a 5xx or 429 response, and a circuit breaker stops
+// calling an agent that keeps failing.
+type AgentCaller struct {
+	client  *http.Client
+	baseURL string
+	name    string
+	retry   RetryPolicy
+	timeout time.Duration
+	breaker *agenthttp.CircuitBreaker
+}
+
+// NewAgentCaller creates a new agent caller with the default retry policy
+// and circuit breaker, and an HTTP client with a pooled transport, see
+// agenthttp.NewClient.
+func NewAgentCaller(baseURL, name string) *AgentCaller {
+	return &AgentCaller{
+		client:  agenthttp.NewClient(agenthttp.ClientConfig{}),
+		baseURL: baseURL,
+		name:    name,
+		retry:   DefaultRetryPolicy(),
+		breaker: agenthttp.NewCircuitBreaker(CircuitBreakerConfig{}),
+	}
+}
+
+// SetClient sets a custom HTTP client.
+func (ac *AgentCaller) SetClient(client *http.Client) *AgentCaller {
+	ac.client = client
+	return ac
+}
+
+// SetRetryPolicy sets how failed calls are retried. Use
+// RetryPolicy{MaxAttempts: 1} to disable retries.
+func (ac *AgentCaller) SetRetryPolicy(policy RetryPolicy) *AgentCaller {
+	ac.retry = policy.WithDefaults()
+	return ac
+}
+
+// SetTimeout bounds each attempt of a call; retries get a fresh timeout.
+// Zero leaves only the HTTP client's timeout.
+func (ac *AgentCaller) SetTimeout(timeout time.Duration) *AgentCaller {
+	ac.timeout = timeout
+	return ac
+}
+
+// SetCircuitBreaker replaces the circuit breaker, resetting it to closed.
+func (ac *AgentCaller) SetCircuitBreaker(cfg CircuitBreakerConfig) *AgentCaller {
+	ac.breaker = agenthttp.NewCircuitBreaker(cfg)
+	return ac
+}
+
+// Call calls an agent endpoint with JSON request/response.
+func (ac *AgentCaller) Call(ctx context.Context, endpoint string, request, response interface{}) error {
+	if err := ac.breaker.Allow(); err != nil {
+		return fmt.Errorf("agent %s: %w", ac.name, err)
+	}
+
+	url := fmt.Sprintf("%s%s", ac.baseURL, endpoint)
+	attempts, err := runRetry(ctx, ac.retry, ac.name, func(ctx context.Context) error {
+		if ac.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, ac.timeout)
+			defer cancel()
+		}
+		return agenthttp.PostJSON(ctx, ac.client, url, request, response)
+	})
+
+	// Only failures that suggest the agent is unhealthy count; a call
+	// cancelled by the caller or rejected as invalid does not.
+	ac.breaker.Record(err != nil && ctx.Err() == nil && ac.retry.Retryable(err))
+	if err != nil && attempts > 1 {
+		return fmt.Errorf("agent %s failed after %d attempts: %w", ac.name, attempts, err)
+	}
+	return err
+}
+
+// Call calls an agent endpoint and decodes its response into a Resp. It is
+// the typed form of AgentCaller.Call, which it uses for retries and
+// circuit breaking; it is a function because Go methods cannot have type
+// parameters.
+//
+//	summary, err := orchestration.Call[ResearchRequest, ResearchResponse](
+//	    ctx, caller, "/research", ResearchRequest{Topic: topic})
+func Call[Req, Resp any](ctx context.Context, ac *AgentCaller, endpoint string, request Req) (Resp, error) {
+	var response Resp
+	if err := ac.Call(ctx, endpoint, request, &response); err != nil {
+		var zero Resp
+		return zero, err
+	}
+	return response, nil
+}
+
+// HealthCheck checks if the agent is healthy.
+func (ac *AgentCaller) HealthCheck(ctx context.Context) error {
+	return agenthttp.HealthCheck(ctx, ac.client, ac.baseURL)
+}
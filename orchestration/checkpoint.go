@@ -0,0 +1,173 @@
+package orchestration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// ErrCheckpointNotFound is returned by a CheckpointStore when a run has no
+// checkpoint.
+var ErrCheckpointNotFound = errors.New("checkpoint not found")
+
+// Checkpoint is the persisted progress of a workflow run.
+type Checkpoint struct {
+	// RunID identifies the run.
+	RunID string `json:"run_id"`
+
+	// Steps lists the completed node executions in order. A node that
+	// runs more than once, as in a revise loop, appears once per run.
+	Steps []string `json:"steps"`
+
+	// State is the JSON-encoded state after the last completed step.
+	State json.RawMessage `json:"state"`
+
+	// Version is the version of the workflow that saved the checkpoint;
+	// see WithWorkflowVersion.
+	Version string `json:"version,omitempty"`
+
+	// UpdatedAt is when the checkpoint was saved.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CheckpointStore persists workflow checkpoints.
+type CheckpointStore interface {
+	// Save stores cp, replacing the run's previous checkpoint.
+	Save(ctx context.Context, cp *Checkpoint) error
+
+	// Load returns the checkpoint of a run, or ErrCheckpointNotFound.
+	Load(ctx context.Context, runID string) (*Checkpoint, error)
+
+	// Delete removes the checkpoint of a run. Deleting a missing
+	// checkpoint is not an error.
+	Delete(ctx context.Context, runID string) error
+}
+
+// checkpointRun tracks the checkpoint of one run during execution.
+type checkpointRun struct {
+	store CheckpointStore
+	runID string
+
+	mu     sync.Mutex
+	loaded bool
+	saved  *Checkpoint    // checkpoint found when the run started
+	steps  []string       // steps completed so far, including resumed ones
+	visits map[string]int // executions of each node in this process
+}
+
+type checkpointRunKey struct{}
+
+// WithCheckpoints returns a context under which Checkpointed nodes save a
+// checkpoint to store after each step of run runID. If the run already
+// has a checkpoint, the steps it records are skipped, so executing the
+// workflow again with the same run ID resumes it after a crash or deploy:
+//
+//	ctx = orchestration.WithCheckpoints(ctx, store, runID)
+//	result, err := executor.Execute(ctx, input)
+func WithCheckpoints(ctx context.Context, store CheckpointStore, runID string) context.Context {
+	return context.WithValue(ctx, checkpointRunKey{}, &checkpointRun{
+		store:  store,
+		runID:  runID,
+		visits: make(map[string]int),
+	})
+}
+
+// Checkpointed wraps a node function so that, under WithCheckpoints, the
+// state it returns is saved after it completes. When a run is resumed,
+// executions the checkpoint records as completed are skipped and return
+// the checkpointed state instead. The state must round-trip through JSON.
+//
+//	gb.AddLambdaNodeFunc("research", compose.InvokableLambda(
+//	    orchestration.Checkpointed("research", research)))
+func Checkpointed[S any](node string, fn func(ctx context.Context, state S) (S, error)) func(ctx context.Context, state S) (S, error) {
+	return func(ctx context.Context, state S) (S, error) {
+		run, ok := ctx.Value(checkpointRunKey{}).(*checkpointRun)
+		if !ok {
+			return fn(ctx, state)
+		}
+
+		skip, err := run.visit(ctx, node)
+		if err != nil {
+			return state, err
+		}
+		if skip {
+			var resumed S
+			if err := json.Unmarshal(run.saved.State, &resumed); err != nil {
+				return state, fmt.Errorf("node %s: decoding checkpoint of run %s: %w", node, run.runID, err)
+			}
+			log.Printf("[%s] Skipping step completed before resume of run %s", node, run.runID)
+			return resumed, nil
+		}
+
+		out, err := fn(ctx, state)
+		if err != nil {
+			return out, err
+		}
+		if err := run.save(ctx, node, out); err != nil {
+			return out, fmt.Errorf("node %s: %w", node, err)
+		}
+		return out, nil
+	}
+}
+
+// visit records an execution of node and reports whether the run's
+// checkpoint already covers it.
+func (r *checkpointRun) visit(ctx context.Context, node string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.loaded {
+		saved, err := r.store.Load(ctx, r.runID)
+		switch {
+		case errors.Is(err, ErrCheckpointNotFound):
+		case err != nil:
+			return false, fmt.Errorf("loading checkpoint of run %s: %w", r.runID, err)
+		default:
+			if version := WorkflowVersion(ctx); saved.Version != "" && version != "" && saved.Version != version {
+				return false, fmt.Errorf("checkpoint of run %s was saved by workflow version %s, not %s", r.runID, saved.Version, version)
+			}
+			r.saved = saved
+			r.steps = append(r.steps, saved.Steps...)
+		}
+		r.loaded = true
+	}
+
+	r.visits[node]++
+	if r.saved == nil {
+		return false, nil
+	}
+	done := 0
+	for _, step := range r.saved.Steps {
+		if step == node {
+			done++
+		}
+	}
+	return r.visits[node] <= done, nil
+}
+
+// save saves a checkpoint after node completed with state.
+func (r *checkpointRun) save(ctx context.Context, node string, state interface{}) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("encoding checkpoint: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.steps = append(r.steps, node)
+	cp := &Checkpoint{
+		RunID:     r.runID,
+		Steps:     append([]string(nil), r.steps...),
+		State:     data,
+		Version:   WorkflowVersion(ctx),
+		UpdatedAt: time.Now().UTC(),
+	}
+	if err := r.store.Save(ctx, cp); err != nil {
+		return fmt.Errorf("saving checkpoint of run %s: %w", r.runID, err)
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package orchestration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/plexusone/agentkit/internal/awscli"
+)
+
+// MemoryCheckpointStore keeps checkpoints in memory. It is meant for tests
+// and single-process development; checkpoints do not survive a restart.
+type MemoryCheckpointStore struct {
+	mu          sync.RWMutex
+	checkpoints map[string]Checkpoint
+}
+
+// NewMemoryCheckpointStore creates an empty in-memory store.
+func NewMemoryCheckpointStore() *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{checkpoints: make(map[string]Checkpoint)}
+}
+
+// Save implements CheckpointStore.
+func (s *MemoryCheckpointStore) Save(ctx context.Context, cp *Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkpoints[cp.RunID] = *cp
+	return nil
+}
+
+// Load implements CheckpointStore.
+func (s *MemoryCheckpointStore) Load(ctx context.Context, runID string) (*Checkpoint, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	cp, ok := s.checkpoints[runID]
+	if !ok {
+		return nil, ErrCheckpointNotFound
+	}
+	return &cp, nil
+}
+
+// Delete implements CheckpointStore.
+func (s *MemoryCheckpointStore) Delete(ctx context.Context, runID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.checkpoints, runID)
+	return nil
+}
+
+// FileCheckpointStore keeps each run's checkpoint in a JSON file named
+// after the run ID. Files are replaced atomically, so a crash while saving
+// leaves the previous checkpoint intact.
+type FileCheckpointStore struct {
+	// Dir is the directory holding the checkpoint files. It is created
+	// when the first checkpoint is saved.
+	Dir string
+}
+
+// path returns the file of a run's checkpoint.
+func (s FileCheckpointStore) path(runID string) string {
+	return filepath.Join(s.Dir, checkpointKey(runID))
+}
+
+// Save implements CheckpointStore.
+func (s FileCheckpointStore) Save(ctx context.Context, cp *Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("encoding checkpoint: %w", err)
+	}
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return fmt.Errorf("creating checkpoint dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(s.Dir, ".checkpoint-*")
+	if err != nil {
+		return fmt.Errorf("creating checkpoint file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("writing checkpoint file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing checkpoint file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path(cp.RunID)); err != nil {
+		return fmt.Errorf("writing checkpoint file: %w", err)
+	}
+	return nil
+}
+
+// Load implements CheckpointStore.
+func (s FileCheckpointStore) Load(ctx context.Context, runID string) (*Checkpoint, error) {
+	data, err := os.ReadFile(s.path(runID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrCheckpointNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading checkpoint file: %w", err)
+	}
+	return decodeCheckpoint(data)
+}
+
+// Delete implements CheckpointStore.
+func (s FileCheckpointStore) Delete(ctx context.Context, runID string) error {
+	if err := os.Remove(s.path(runID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("deleting checkpoint file: %w", err)
+	}
+	return nil
+}
+
+// S3CheckpointStore keeps each run's checkpoint in an S3 object named
+// {Prefix}{run ID}.json. It uses the aws CLI, which must be installed and
+// have credentials for the bucket.
+type S3CheckpointStore struct {
+	// Bucket is the S3 bucket name.
+	Bucket string
+
+	// Prefix is prepended to object keys, e.g. "checkpoints/research/".
+	Prefix string
+}
+
+// key returns the object key of a run's checkpoint.
+func (s S3CheckpointStore) key(runID string) string {
+	return s.Prefix + checkpointKey(runID)
+}
+
+// Save implements CheckpointStore.
+func (s S3CheckpointStore) Save(ctx context.Context, cp *Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("encoding checkpoint: %w", err)
+	}
+	// put-object reads the body from a file.
+	tmp, err := os.CreateTemp("", "agentkit-checkpoint-")
+	if err != nil {
+		return fmt.Errorf("creating temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("writing temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing temp file: %w", err)
+	}
+	_, err = awscli.Run(ctx, "s3api", "put-object", "--bucket", s.Bucket, "--key", s.key(cp.RunID),
+		"--body", tmp.Name(), "--content-type", "application/json")
+	return err
+}
+
+// Load implements CheckpointStore.
+func (s S3CheckpointStore) Load(ctx context.Context, runID string) (*Checkpoint, error) {
+	data, _, err := awscli.RunOutfile(ctx, "s3api", "get-object", "--bucket", s.Bucket, "--key", s.key(runID))
+	if err != nil {
+		if strings.Contains(err.Error(), "NoSuchKey") {
+			return nil, ErrCheckpointNotFound
+		}
+		return nil, err
+	}
+	return decodeCheckpoint(data)
+}
+
+// Delete implements CheckpointStore.
+func (s S3CheckpointStore) Delete(ctx context.Context, runID string) error {
+	_, err := awscli.Run(ctx, "s3api", "delete-object", "--bucket", s.Bucket, "--key", s.key(runID))
+	return err
+}
+
+// DynamoDBCheckpointStore keeps checkpoints in a DynamoDB table whose
+// partition key is the string attribute "run_id". The checkpoint is stored
+// as JSON in the "checkpoint" attribute, so states are limited by the
+// 400 KB item size; items are handed to the CLI in a file, not as an
+// argument. It uses the aws CLI, which must be installed and have
+// credentials for the table.
+type DynamoDBCheckpointStore struct {
+	// Table is the DynamoDB table name.
+	Table string
+}
+
+// dynamoKey returns the DynamoDB key of a run.
+func dynamoKey(runID string) string {
+	key, _ := json.Marshal(map[string]map[string]string{"run_id": {"S": runID}})
+	return string(key)
+}
+
+// Save implements CheckpointStore.
+func (s DynamoDBCheckpointStore) Save(ctx context.Context, cp *Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("encoding checkpoint: %w", err)
+	}
+	_, err = awscli.RunInput(ctx, map[string]any{
+		"TableName": s.Table,
+		"Item": map[string]map[string]string{
+			"run_id":     {"S": cp.RunID},
+			"checkpoint": {"S": string(data)},
+		},
+	}, "dynamodb", "put-item")
+	return err
+}
+
+// Load implements CheckpointStore.
+func (s DynamoDBCheckpointStore) Load(ctx context.Context, runID string) (*Checkpoint, error) {
+	out, err := awscli.Run(ctx, "dynamodb", "get-item", "--table-name", s.Table,
+		"--key", dynamoKey(runID), "--consistent-read")
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Item map[string]struct {
+			S string
+		}
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return nil, fmt.Errorf("parsing get-item output: %w", err)
+	}
+	attr, ok := result.Item["checkpoint"]
+	if !ok {
+		return nil, ErrCheckpointNotFound
+	}
+	return decodeCheckpoint([]byte(attr.S))
+}
+
+// Delete implements CheckpointStore.
+func (s DynamoDBCheckpointStore) Delete(ctx context.Context, runID string) error {
+	_, err := awscli.Run(ctx, "dynamodb", "delete-item", "--table-name", s.Table, "--key", dynamoKey(runID))
+	return err
+}
+
+// checkpointKey returns the file or object name of a run's checkpoint.
+// Path separators in the run ID are replaced.
+func checkpointKey(runID string) string {
+	return strings.NewReplacer("/", "_", "\\", "_").Replace(runID) + ".json"
+}
+
+// decodeCheckpoint decodes a JSON checkpoint.
+func decodeCheckpoint(data []byte) (*Checkpoint, error) {
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("decoding checkpoint: %w", err)
+	}
+	return &cp, nil
+}
@@ -0,0 +1,84 @@
+package orchestration
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/cloudwego/eino/compose"
+	"github.com/plexusone/omniobserve/observops"
+)
+
+// CompiledExecutor runs a graph that is compiled once, when the executor
+// is created. Executor compiles the graph on every call; use
+// CompiledExecutor on hot paths such as HTTP handlers. Once configured, a
+// CompiledExecutor is safe for concurrent use.
+type CompiledExecutor[I, O any] struct {
+	runnable compose.Runnable[I, O]
+	name     string
+	opts     []compose.Option
+}
+
+// NewCompiledExecutor compiles graph and returns an executor for it. The
+// graph must not be changed afterwards.
+func NewCompiledExecutor[I, O any](ctx context.Context, graph *compose.Graph[I, O], name string, opts ...compose.GraphCompileOption) (*CompiledExecutor[I, O], error) {
+	runnable, err := graph.Compile(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile graph: %w", err)
+	}
+	return &CompiledExecutor[I, O]{runnable: runnable, name: name}, nil
+}
+
+// Compile compiles the executor's graph once and returns a
+// CompiledExecutor for it.
+func (e *Executor[I, O]) Compile(ctx context.Context, opts ...compose.GraphCompileOption) (*CompiledExecutor[I, O], error) {
+	compiled, err := NewCompiledExecutor(ctx, e.graph, e.name, opts...)
+	if err != nil {
+		return nil, err
+	}
+	compiled.opts = append(compiled.opts, e.opts...)
+	return compiled, nil
+}
+
+// SetObservability records a span and duration and error metrics for the
+// workflow and each of its nodes with provider; see
+// NewObservabilityHandler. It is part of setup: call it before the first
+// Execute or Stream, not concurrently with them.
+func (e *CompiledExecutor[I, O]) SetObservability(provider observops.Provider) *CompiledExecutor[I, O] {
+	e.opts = append(e.opts, compose.WithCallbacks(NewObservabilityHandler(e.name, provider)))
+	return e
+}
+
+// Execute runs the compiled graph; see Executor.Execute.
+func (e *CompiledExecutor[I, O]) Execute(ctx context.Context, input I) (O, error) {
+	log.Printf("[%s] Starting workflow execution", e.name)
+
+	ctx, saga := startSaga(ctx)
+	failure := &nodeFailure{}
+	result, err := e.runnable.Invoke(ctx, input, runOptions(e.opts, failure)...)
+	if err != nil {
+		var zero O
+		return zero, compensateRun(ctx, saga, failure.wrap(e.name, err))
+	}
+
+	log.Printf("[%s] Workflow completed successfully", e.name)
+	return result, nil
+}
+
+// Stream runs the compiled graph in streaming mode; see Executor.Stream.
+func (e *CompiledExecutor[I, O]) Stream(ctx context.Context, input I) (<-chan Chunk[O], error) {
+	log.Printf("[%s] Starting streaming workflow execution", e.name)
+	return streamRunnable(ctx, e.name, e.runnable, input, e.opts...)
+}
+
+// HTTPHandler returns an HTTP handler for the executor; see
+// NewHTTPHandler.
+func (e *CompiledExecutor[I, O]) HTTPHandler() *HTTPHandler[I, O] {
+	return &HTTPHandler[I, O]{execute: e.Execute}
+}
+
+// StreamHandler returns an SSE handler for the executor; see
+// NewStreamHandler.
+func (e *CompiledExecutor[I, O]) StreamHandler() *StreamHandler[I, O] {
+	return &StreamHandler[I, O]{stream: e.Stream}
+}
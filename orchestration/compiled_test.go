@@ -0,0 +1,79 @@
+package orchestration
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/cloudwego/eino/compose"
+)
+
+// benchmarkGraph returns a three-node pipeline that appends to its input.
+func benchmarkGraph(b *testing.B) *compose.Graph[string, string] {
+	b.Helper()
+	gb := NewGraphBuilder[string, string]("bench")
+	nodes := []string{"parse", "enrich", "format"}
+	for _, name := range nodes {
+		lambda := compose.InvokableLambda(func(_ context.Context, in string) (string, error) {
+			return in + "." + name, nil
+		})
+		if err := gb.AddLambdaNodeFunc(name, lambda); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if err := gb.AddStartEdge(nodes[0]); err != nil {
+		b.Fatal(err)
+	}
+	for i := 1; i < len(nodes); i++ {
+		if err := gb.AddEdge(nodes[i-1], nodes[i]); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if err := gb.AddEndEdge(nodes[len(nodes)-1]); err != nil {
+		b.Fatal(err)
+	}
+	return gb.Build()
+}
+
+// silenceLog discards the executors' per-run log lines for the benchmark.
+func silenceLog(b *testing.B) {
+	b.Helper()
+	out, flags := log.Writer(), log.Flags()
+	log.SetOutput(io.Discard)
+	b.Cleanup(func() {
+		log.SetOutput(out)
+		log.SetFlags(flags)
+	})
+}
+
+func BenchmarkExecutorExecute(b *testing.B) {
+	silenceLog(b)
+	executor := NewExecutor(benchmarkGraph(b), "bench")
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := executor.Execute(ctx, "in"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCompiledExecutorExecute(b *testing.B) {
+	silenceLog(b)
+	ctx := context.Background()
+	executor, err := NewCompiledExecutor(ctx, benchmarkGraph(b), "bench")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := executor.Execute(ctx, "in"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
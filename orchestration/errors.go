@@ -0,0 +1,115 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/compose"
+)
+
+// NodeError reports which step of a workflow failed. Executors return it,
+// possibly joined with compensation errors, when a run fails; use
+// errors.As to inspect it:
+//
+//	var nodeErr *orchestration.NodeError
+//	if errors.As(err, &nodeErr) {
+//	    log.Printf("step %s of %s failed: %v", nodeErr.Node, nodeErr.Workflow, nodeErr.Err)
+//	}
+type NodeError struct {
+	// Workflow is the name of the executor. It is empty for the errors
+	// WithRetry returns from within a node.
+	Workflow string
+
+	// Node is the name of the failing node, the innermost one for nodes
+	// that are graphs themselves. It is empty if the run failed outside
+	// any node, e.g. by exceeding its maximum steps.
+	Node string
+
+	// Attempt is the number of attempts the node made, more than one if
+	// it was retried with WithRetry. It is 0 if Node is empty.
+	Attempt int
+
+	// Err is the node's error, or the run's error if Node is empty.
+	Err error
+}
+
+// Error implements error.
+func (e *NodeError) Error() string {
+	var msg string
+	switch {
+	case e.Node == "":
+		msg = fmt.Sprintf("execution failed: %v", e.Err)
+	case e.Attempt > 1:
+		msg = fmt.Sprintf("node %s failed after %d attempts: %v", e.Node, e.Attempt, e.Err)
+	default:
+		msg = fmt.Sprintf("node %s failed: %v", e.Node, e.Err)
+	}
+	if e.Workflow == "" {
+		return msg
+	}
+	return fmt.Sprintf("workflow %s: %s", e.Workflow, msg)
+}
+
+// Unwrap returns the underlying error.
+func (e *NodeError) Unwrap() error {
+	return e.Err
+}
+
+// nodeFailure records the first node to fail in one run, from Eino's
+// error callbacks.
+type nodeFailure struct {
+	mu   sync.Mutex
+	node string
+	err  error
+}
+
+// runOptions returns opts plus a callback that records the failing node
+// in failure. It does not modify opts, which executors share between
+// concurrent runs.
+func runOptions(opts []compose.Option, failure *nodeFailure) []compose.Option {
+	handler := callbacks.NewHandlerBuilder().
+		OnErrorFn(func(ctx context.Context, info *callbacks.RunInfo, err error) context.Context {
+			failure.record(info, err)
+			return ctx
+		}).
+		Build()
+	return append(append(make([]compose.Option, 0, len(opts)+1), opts...), compose.WithCallbacks(handler))
+}
+
+// record records a failed run unless it is a graph, whose error comes
+// from one of its nodes, or a node already failed.
+func (f *nodeFailure) record(info *callbacks.RunInfo, err error) {
+	if info == nil || info.Name == "" {
+		return
+	}
+	switch info.Component {
+	case compose.ComponentOfGraph, compose.ComponentOfChain, compose.ComponentOfWorkflow:
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.node == "" {
+		f.node, f.err = info.Name, err
+	}
+}
+
+// wrap returns the NodeError of a run of workflow that failed with err.
+func (f *nodeFailure) wrap(workflow string, err error) error {
+	nodeErr := &NodeError{Workflow: workflow, Err: err}
+
+	f.mu.Lock()
+	if f.node != "" {
+		nodeErr.Node, nodeErr.Attempt, nodeErr.Err = f.node, 1, f.err
+	}
+	f.mu.Unlock()
+
+	// Adopt the attempts of a node that WithRetry retried.
+	var retried *NodeError
+	if errors.As(nodeErr.Err, &retried) && retried.Workflow == "" {
+		nodeErr.Node, nodeErr.Attempt, nodeErr.Err = retried.Node, retried.Attempt, retried.Err
+	}
+	return nodeErr
+}
@@ -0,0 +1,123 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/cloudwego/eino/compose"
+)
+
+// FanOutOptions configures a FanOut node.
+type FanOutOptions struct {
+	// Concurrency is the maximum number of items processed at once.
+	// Default: 4
+	Concurrency int
+
+	// ContinueOnError leaves failed items out of the results instead of
+	// failing the node. The node still fails if every item fails.
+	// Default: false
+	ContinueOnError bool
+}
+
+// FanOut returns a lambda node that splits its input into items, processes
+// the items in parallel with mapFn, and combines the results with reduce.
+// Results are passed to reduce in the order of the items. Unless
+// ContinueOnError is set, the first failure cancels the remaining items
+// and fails the node.
+//
+//	gb.AddLambdaNodeFunc("research_topics", orchestration.FanOut("research_topics",
+//	    func(s *State) []string { return s.Topics },
+//	    researchTopic,
+//	    func(ctx context.Context, s *State, findings []Finding) (*State, error) {
+//	        s.Findings = findings
+//	        return s, nil
+//	    },
+//	    orchestration.FanOutOptions{Concurrency: 3}))
+func FanOut[I, T, R, O any](
+	name string,
+	split func(input I) []T,
+	mapFn func(ctx context.Context, item T) (R, error),
+	reduce func(ctx context.Context, input I, results []R) (O, error),
+	opts FanOutOptions,
+) *compose.Lambda {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+	return compose.InvokableLambda(func(ctx context.Context, input I) (O, error) {
+		items := split(input)
+		results, err := runFanOut(ctx, name, items, mapFn, opts)
+		if err != nil {
+			var zero O
+			return zero, err
+		}
+		return reduce(ctx, input, results)
+	})
+}
+
+// runFanOut processes items with mapFn, at most opts.Concurrency at a
+// time, and returns the results in item order.
+func runFanOut[T, R any](ctx context.Context, name string, items []T, mapFn func(context.Context, T) (R, error), opts FanOutOptions) ([]R, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]R, len(items))
+	errs := make([]error, len(items))
+	sem := make(chan struct{}, opts.Concurrency)
+	var wg sync.WaitGroup
+
+	for i, item := range items {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i], errs[i] = mapFn(ctx, item)
+			if errs[i] != nil && !opts.ContinueOnError {
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if !opts.ContinueOnError {
+		// Report the failure that cancelled the others, not a cancellation.
+		for i, err := range errs {
+			if err != nil && !errors.Is(err, context.Canceled) {
+				return nil, fmt.Errorf("fan-out %s: item %d: %w", name, i, err)
+			}
+		}
+		for _, err := range errs {
+			if err != nil {
+				return nil, fmt.Errorf("fan-out %s: %w", name, err)
+			}
+		}
+		return results, nil
+	}
+
+	kept := make([]R, 0, len(items))
+	var failed []error
+	for i, err := range errs {
+		if err != nil {
+			log.Printf("[%s] Item %d failed: %v", name, i, err)
+			failed = append(failed, fmt.Errorf("item %d: %w", i, err))
+			continue
+		}
+		kept = append(kept, results[i])
+	}
+	if len(kept) == 0 {
+		return nil, fmt.Errorf("fan-out %s: all %d items failed: %w", name, len(items), errors.Join(failed...))
+	}
+	return kept, nil
+}
@@ -2,16 +2,17 @@
 package orchestration
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"time"
 
 	"github.com/cloudwego/eino/compose"
-
-	agenthttp "github.com/plexusone/agentkit/http"
+	"github.com/plexusone/omniobserve/observops"
 )
 
 // GraphBuilder helps construct Eino workflow graphs.
@@ -44,11 +45,12 @@ func (gb *GraphBuilder[I, O]) Graph() *compose.Graph[I, O] {
 	return gb.graph
 }
 
-// AddLambdaNodeFunc adds a lambda node using a function.
+// AddLambdaNodeFunc adds a lambda node using a function. The node is named
+// after its key in callbacks, so traces and metrics can tell nodes apart.
 // Note: Due to Go generics limitations, you may need to use Graph() directly
 // for complex type conversions.
 func (gb *GraphBuilder[I, O]) AddLambdaNodeFunc(name string, lambda *compose.Lambda) error {
-	if err := gb.graph.AddLambdaNode(name, lambda); err != nil {
+	if err := gb.graph.AddLambdaNode(name, lambda, compose.WithNodeName(name)); err != nil {
 		return fmt.Errorf("failed to add node %s: %w", name, err)
 	}
 	gb.nodes = append(gb.nodes, name)
@@ -81,6 +83,7 @@ type Executor[I, O any] struct {
 	graph  *compose.Graph[I, O]
 	name   string
 	client *http.Client
+	opts   []compose.Option
 }
 
 // NewExecutor creates a new graph executor.
@@ -98,7 +101,17 @@ func (e *Executor[I, O]) SetClient(client *http.Client) *Executor[I, O] {
 	return e
 }
 
-// Execute compiles and runs the graph.
+// SetObservability records a span and duration and error metrics for the
+// workflow and each of its nodes with provider; see
+// NewObservabilityHandler.
+func (e *Executor[I, O]) SetObservability(provider observops.Provider) *Executor[I, O] {
+	e.opts = append(e.opts, compose.WithCallbacks(NewObservabilityHandler(e.name, provider)))
+	return e
+}
+
+// Execute compiles and runs the graph. If it fails, it returns a
+// *NodeError naming the failing node, and the compensations of completed
+// Compensable steps run in reverse order.
 func (e *Executor[I, O]) Execute(ctx context.Context, input I) (O, error) {
 	log.Printf("[%s] Starting workflow execution", e.name)
 
@@ -108,80 +121,128 @@ func (e *Executor[I, O]) Execute(ctx context.Context, input I) (O, error) {
 		return zero, fmt.Errorf("failed to compile graph: %w", err)
 	}
 
-	result, err := compiled.Invoke(ctx, input)
+	ctx, saga := startSaga(ctx)
+	failure := &nodeFailure{}
+	result, err := compiled.Invoke(ctx, input, runOptions(e.opts, failure)...)
 	if err != nil {
 		var zero O
-		return zero, fmt.Errorf("workflow execution failed: %w", err)
+		return zero, compensateRun(ctx, saga, failure.wrap(e.name, err))
 	}
 
 	log.Printf("[%s] Workflow completed successfully", e.name)
 	return result, nil
 }
 
-// AgentCaller provides methods for calling other agents via HTTP.
-type AgentCaller struct {
-	client  *http.Client
-	baseURL string
-	name    string
-}
-
-// NewAgentCaller creates a new agent caller.
-func NewAgentCaller(baseURL, name string) *AgentCaller {
-	return &AgentCaller{
-		client:  &http.Client{Timeout: 60 * time.Second},
-		baseURL: baseURL,
-		name:    name,
-	}
-}
-
-// SetClient sets a custom HTTP client.
-func (ac *AgentCaller) SetClient(client *http.Client) *AgentCaller {
-	ac.client = client
-	return ac
-}
-
-// Call calls an agent endpoint with JSON request/response.
-func (ac *AgentCaller) Call(ctx context.Context, endpoint string, request, response interface{}) error {
-	url := fmt.Sprintf("%s%s", ac.baseURL, endpoint)
-	return agenthttp.PostJSON(ctx, ac.client, url, request, response)
-}
-
-// HealthCheck checks if the agent is healthy.
-func (ac *AgentCaller) HealthCheck(ctx context.Context) error {
-	return agenthttp.HealthCheck(ctx, ac.client, ac.baseURL)
-}
-
 // HTTPHandler wraps an executor as an HTTP handler.
 type HTTPHandler[I, O any] struct {
-	executor *Executor[I, O]
+	execute func(ctx context.Context, input I) (O, error)
+
+	inputSchema, outputSchema       json.RawMessage
+	inputValidator, outputValidator *jsonSchema
 }
 
 // NewHTTPHandler creates a new HTTP handler for a graph executor.
 func NewHTTPHandler[I, O any](executor *Executor[I, O]) *HTTPHandler[I, O] {
-	return &HTTPHandler[I, O]{executor: executor}
+	return &HTTPHandler[I, O]{execute: executor.Execute}
+}
+
+// SetSchemas attaches JSON Schemas to the handler. Requests that do not
+// match input are rejected with 422 and field-level errors before the
+// workflow runs, and responses that do not match output fail with 500
+// instead of being sent. A nil schema turns off validation on its side.
+// GET requests return the schemas. It returns an error if a schema is
+// invalid.
+//
+//	err := handler.SetSchemas(orchestration.SchemaOf[Input](), orchestration.SchemaOf[Output]())
+func (h *HTTPHandler[I, O]) SetSchemas(input, output json.RawMessage) error {
+	var inputValidator, outputValidator *jsonSchema
+	var err error
+	if input != nil {
+		if inputValidator, err = compileSchema(input); err != nil {
+			return fmt.Errorf("input schema: %w", err)
+		}
+	}
+	if output != nil {
+		if outputValidator, err = compileSchema(output); err != nil {
+			return fmt.Errorf("output schema: %w", err)
+		}
+	}
+	h.inputSchema, h.inputValidator = input, inputValidator
+	h.outputSchema, h.outputValidator = output, outputValidator
+	return nil
+}
+
+// schemaErrorResponse is the body of a response rejected by a schema.
+type schemaErrorResponse struct {
+	Error  string       `json:"error"`
+	Errors []FieldError `json:"errors"`
 }
 
-// ServeHTTP implements http.Handler.
+// ServeHTTP implements http.Handler. POST runs the workflow with the JSON
+// request body; GET returns the schemas set with SetSchemas as
+// {"input_schema": ..., "output_schema": ...}.
 func (h *HTTPHandler[I, O]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
+	switch r.Method {
+	case http.MethodPost:
+	case http.MethodGet:
+		writeJSON(w, struct {
+			InputSchema  json.RawMessage `json:"input_schema,omitempty"`
+			OutputSchema json.RawMessage `json:"output_schema,omitempty"`
+		}{h.inputSchema, h.outputSchema})
+		return
+	default:
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+	if h.inputValidator != nil {
+		violations, err := h.inputValidator.validate(body)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+		if len(violations) > 0 {
+			writeJSONStatus(w, http.StatusUnprocessableEntity, schemaErrorResponse{
+				Error:  "request does not match the input schema",
+				Errors: violations,
+			})
+			return
+		}
+	}
+
 	var req I
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := json.NewDecoder(bytes.NewReader(body)).Decode(&req); err != nil {
 		http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
 		return
 	}
 
-	resp, err := h.executor.Execute(r.Context(), req)
+	resp, err := h.execute(r.Context(), req)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Execution failed: %v", err), http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		log.Printf("Failed to encode response: %v", err)
+	if h.outputValidator != nil {
+		data, err := json.Marshal(resp)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to encode response: %v", err), http.StatusInternalServerError)
+			return
+		}
+		violations, err := h.outputValidator.validate(data)
+		if err == nil && len(violations) > 0 {
+			log.Printf("Response does not match the output schema: %v", violations)
+			writeJSONStatus(w, http.StatusInternalServerError, schemaErrorResponse{
+				Error:  "response does not match the output schema",
+				Errors: violations,
+			})
+			return
+		}
 	}
+
+	writeJSON(w, resp)
 }
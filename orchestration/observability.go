@@ -0,0 +1,149 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"time"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+	"github.com/plexusone/omniobserve/observops"
+)
+
+// Metrics recorded for workflows and their nodes. Both carry the
+// workflow.name and workflow.node attributes; the node is empty for the
+// workflow as a whole.
+const (
+	// MetricNodeDuration is a histogram of run durations in milliseconds.
+	MetricNodeDuration = "agentkit.workflow.node.duration"
+
+	// MetricNodeErrors counts failed runs.
+	MetricNodeErrors = "agentkit.workflow.node.errors"
+)
+
+// Span attributes set by the observability handler.
+const (
+	attrWorkflow  = "workflow.name"
+	attrNode      = "workflow.node"
+	attrComponent = "workflow.component"
+)
+
+// nodeObserver turns Eino callbacks into spans and metrics.
+type nodeObserver struct {
+	workflow string
+	tracer   observops.Tracer
+	duration observops.Histogram
+	errors   observops.Counter
+}
+
+// nodeRun is the span of one callback run, carried in the context between
+// its start and end callbacks.
+type nodeRun struct {
+	span  observops.Span
+	start time.Time
+	attrs []observops.KeyValue
+}
+
+type nodeRunKey struct{}
+
+// NewObservabilityHandler returns an Eino callback handler that records a
+// span and metrics for the workflow and each of its nodes with provider.
+// Executors install it with SetObservability; pass it with
+// compose.WithCallbacks when invoking a compiled graph directly.
+func NewObservabilityHandler(workflow string, provider observops.Provider) callbacks.Handler {
+	o := &nodeObserver{workflow: workflow, tracer: provider.Tracer()}
+
+	meter := provider.Meter()
+	duration, err := meter.Histogram(MetricNodeDuration,
+		observops.WithDescription("Workflow node run duration"),
+		observops.WithUnit("ms"))
+	if err != nil {
+		log.Printf("[%s] Failed to create %s histogram: %v", workflow, MetricNodeDuration, err)
+	}
+	o.duration = duration
+	errCounter, err := meter.Counter(MetricNodeErrors,
+		observops.WithDescription("Failed workflow node runs"))
+	if err != nil {
+		log.Printf("[%s] Failed to create %s counter: %v", workflow, MetricNodeErrors, err)
+	}
+	o.errors = errCounter
+
+	return callbacks.NewHandlerBuilder().
+		OnStartFn(func(ctx context.Context, info *callbacks.RunInfo, _ callbacks.CallbackInput) context.Context {
+			return o.start(ctx, info)
+		}).
+		OnStartWithStreamInputFn(func(ctx context.Context, info *callbacks.RunInfo, input *schema.StreamReader[callbacks.CallbackInput]) context.Context {
+			input.Close()
+			return o.start(ctx, info)
+		}).
+		OnEndFn(func(ctx context.Context, _ *callbacks.RunInfo, _ callbacks.CallbackOutput) context.Context {
+			o.end(ctx, nil)
+			return ctx
+		}).
+		OnEndWithStreamOutputFn(func(ctx context.Context, _ *callbacks.RunInfo, output *schema.StreamReader[callbacks.CallbackOutput]) context.Context {
+			// The run ends when its output stream is drained.
+			go func() {
+				defer output.Close()
+				for {
+					_, err := output.Recv()
+					if errors.Is(err, io.EOF) {
+						o.end(ctx, nil)
+						return
+					}
+					if err != nil {
+						o.end(ctx, err)
+						return
+					}
+				}
+			}()
+			return ctx
+		}).
+		OnErrorFn(func(ctx context.Context, _ *callbacks.RunInfo, err error) context.Context {
+			o.end(ctx, err)
+			return ctx
+		}).
+		Build()
+}
+
+// start opens the span of a run.
+func (o *nodeObserver) start(ctx context.Context, info *callbacks.RunInfo) context.Context {
+	node := info.Name
+	spanName := o.workflow + "." + node
+	if info.Component == compose.ComponentOfGraph && node == "" {
+		// The workflow itself, unless it is a named subgraph.
+		spanName = o.workflow
+	}
+	attrs := []observops.KeyValue{
+		observops.Attribute(attrWorkflow, o.workflow),
+		observops.Attribute(attrNode, node),
+	}
+
+	ctx, span := o.tracer.Start(ctx, spanName, observops.WithSpanAttributes(
+		append(attrs, observops.Attribute(attrComponent, string(info.Component)))...))
+	return context.WithValue(ctx, nodeRunKey{}, &nodeRun{span: span, start: time.Now(), attrs: attrs})
+}
+
+// end closes the span of a run and records its metrics.
+func (o *nodeObserver) end(ctx context.Context, err error) {
+	run, ok := ctx.Value(nodeRunKey{}).(*nodeRun)
+	if !ok {
+		return
+	}
+
+	if o.duration != nil {
+		o.duration.Record(ctx, float64(time.Since(run.start).Milliseconds()), observops.WithAttributes(run.attrs...))
+	}
+	if err != nil {
+		run.span.RecordError(err)
+		run.span.SetStatus(observops.StatusCodeError, err.Error())
+		if o.errors != nil {
+			o.errors.Add(ctx, 1, observops.WithAttributes(run.attrs...))
+		}
+	} else {
+		run.span.SetStatus(observops.StatusCodeOK, "")
+	}
+	run.span.End()
+}
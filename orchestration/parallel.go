@@ -0,0 +1,125 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cloudwego/eino/compose"
+)
+
+// ParallelAgent is one agent called by ParallelAgents or CallAgents.
+type ParallelAgent[Req, Resp any] struct {
+	// Name identifies the agent in results and errors.
+	Name string
+
+	// Call sends the request to the agent.
+	Call func(ctx context.Context, request Req) (Resp, error)
+}
+
+// CallerAgent returns a ParallelAgent that posts requests to endpoint with
+// caller, including its retries and circuit breaking.
+func CallerAgent[Req, Resp any](caller *AgentCaller, endpoint string) ParallelAgent[Req, Resp] {
+	return ParallelAgent[Req, Resp]{
+		Name: caller.name,
+		Call: func(ctx context.Context, request Req) (Resp, error) {
+			return Call[Req, Resp](ctx, caller, endpoint, request)
+		},
+	}
+}
+
+// AgentResult is the outcome of one agent in a parallel call.
+type AgentResult[Resp any] struct {
+	// Agent is the agent's name.
+	Agent string
+
+	// Response is the agent's response; it is the zero value if Err is set.
+	Response Resp
+
+	// Err is the agent's error. It is only set with ContinueOnError.
+	Err error
+}
+
+// CallAgents calls agents concurrently with the same request and returns
+// their results in the order of agents. Unless opts.ContinueOnError is
+// set, the first failure cancels the other calls and is returned. With
+// ContinueOnError, failures are reported in the results and CallAgents
+// only fails if every agent fails.
+func CallAgents[Req, Resp any](ctx context.Context, request Req, agents []ParallelAgent[Req, Resp], opts FanOutOptions) ([]AgentResult[Resp], error) {
+	return callAgents(ctx, "agents", request, agents, opts)
+}
+
+// callAgents implements CallAgents, naming the call name in logs and
+// errors.
+func callAgents[Req, Resp any](ctx context.Context, name string, request Req, agents []ParallelAgent[Req, Resp], opts FanOutOptions) ([]AgentResult[Resp], error) {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+
+	// Failures are kept in the results rather than dropped by runFanOut,
+	// so the combiner can tell which agents answered.
+	continueOnError := opts.ContinueOnError
+	opts.ContinueOnError = false
+	results, err := runFanOut(ctx, name, agents, func(ctx context.Context, agent ParallelAgent[Req, Resp]) (AgentResult[Resp], error) {
+		resp, err := agent.Call(ctx, request)
+		if err != nil {
+			if continueOnError {
+				return AgentResult[Resp]{Agent: agent.Name, Err: err}, nil
+			}
+			return AgentResult[Resp]{}, fmt.Errorf("agent %s: %w", agent.Name, err)
+		}
+		return AgentResult[Resp]{Agent: agent.Name, Response: resp}, nil
+	}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if !continueOnError || len(results) == 0 {
+		return results, nil
+	}
+	var failed []error
+	for _, r := range results {
+		if r.Err == nil {
+			return results, nil
+		}
+		failed = append(failed, fmt.Errorf("agent %s: %w", r.Agent, r.Err))
+	}
+	return nil, fmt.Errorf("fan-out %s: all %d agents failed: %w", name, len(results), errors.Join(failed...))
+}
+
+// ParallelAgents returns a lambda node that builds a request from its
+// input, sends it to every agent concurrently, and merges the results with
+// combine, the "ask several specialists, then synthesize" pattern:
+//
+//	gb.AddLambdaNodeFunc("specialists", orchestration.ParallelAgents("specialists",
+//	    []orchestration.ParallelAgent[ResearchRequest, ResearchResponse]{
+//	        orchestration.CallerAgent[ResearchRequest, ResearchResponse](legal, "/research"),
+//	        orchestration.CallerAgent[ResearchRequest, ResearchResponse](finance, "/research"),
+//	        orchestration.CallerAgent[ResearchRequest, ResearchResponse](market, "/research"),
+//	    },
+//	    func(s *State) ResearchRequest { return ResearchRequest{Topic: s.Topic} },
+//	    func(ctx context.Context, s *State, results []orchestration.AgentResult[ResearchResponse]) (*State, error) {
+//	        for _, r := range results {
+//	            s.Findings[r.Agent] = r.Response.Summary
+//	        }
+//	        return s, nil
+//	    },
+//	    orchestration.FanOutOptions{}))
+//
+// Errors are handled as in CallAgents.
+func ParallelAgents[I, Req, Resp, O any](
+	name string,
+	agents []ParallelAgent[Req, Resp],
+	request func(input I) Req,
+	combine func(ctx context.Context, input I, results []AgentResult[Resp]) (O, error),
+	opts FanOutOptions,
+) *compose.Lambda {
+	return compose.InvokableLambda(func(ctx context.Context, input I) (O, error) {
+		results, err := callAgents(ctx, name, request(input), agents, opts)
+		if err != nil {
+			var zero O
+			return zero, err
+		}
+		return combine(ctx, input, results)
+	})
+}
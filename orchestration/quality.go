@@ -0,0 +1,210 @@
+package orchestration
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/cloudwego/eino/compose"
+)
+
+// Node names inside a quality loop subgraph.
+const (
+	qualityGenerateNode = "generate"
+	qualityScoreNode    = "score"
+	qualityReviseNode   = "revise"
+)
+
+// QualityLoopConfig configures a generate → score → revise loop.
+type QualityLoopConfig[S any] struct {
+	// Generate produces the first draft.
+	Generate func(ctx context.Context, state S) (S, error)
+
+	// Score rates the current draft and explains the score. The feedback
+	// becomes the decision's Message and is passed to Revise.
+	// Range: 0-100
+	Score func(ctx context.Context, state S) (score int, feedback string, err error)
+
+	// Revise improves the draft after it missed the target.
+	Revise func(ctx context.Context, state S, decision *QualityDecision) (S, error)
+
+	// Target is the score a draft needs to pass.
+	// Range: 0-100
+	// Default: 80
+	Target int
+
+	// MaxAttempts is the maximum number of drafts scored, including the
+	// first. When it is reached the loop ends with the last draft even if
+	// it did not pass.
+	// Default: 3
+	MaxAttempts int
+}
+
+// withDefaults returns c with zero fields set to their defaults.
+func (c QualityLoopConfig[S]) withDefaults() QualityLoopConfig[S] {
+	if c.Target <= 0 {
+		c.Target = 80
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 3
+	}
+	return c
+}
+
+// validate checks that the loop's nodes are set.
+func (c QualityLoopConfig[S]) validate() error {
+	if c.Generate == nil || c.Score == nil || c.Revise == nil {
+		return fmt.Errorf("generate, score and revise are required")
+	}
+	if c.Target > 100 {
+		return fmt.Errorf("target %d out of range 0-100", c.Target)
+	}
+	return nil
+}
+
+// MaxRunSteps returns the number of graph steps the loop needs to reach
+// MaxAttempts. Pass it to compose.WithMaxRunSteps when compiling a loop
+// returned by NewQualityLoop; AddQualityLoop does this itself.
+func (c QualityLoopConfig[S]) MaxRunSteps() int {
+	c = c.withDefaults()
+	// generate, then score and revise per attempt, then END.
+	return 2*c.MaxAttempts + 2
+}
+
+// QualityDecisionKey returns the State.Metadata key under which a quality
+// loop records its last decision.
+func QualityDecisionKey(loop string) string {
+	return "quality." + loop + ".decision"
+}
+
+// QualityAttemptsKey returns the State.Metadata key under which a quality
+// loop records the number of drafts scored.
+func QualityAttemptsKey(loop string) string {
+	return "quality." + loop + ".attempts"
+}
+
+// qualityLoopState is the local state of one run of a quality loop.
+type qualityLoopState struct {
+	attempts int
+	decision *QualityDecision
+}
+
+// NewQualityLoop returns a subgraph that generates a draft, then scores and
+// revises it until it reaches the target score or MaxAttempts drafts have
+// been scored. If the state embeds State, the last decision and the number
+// of attempts are recorded in its metadata; see QualityDecisionKey and
+// QualityAttemptsKey.
+//
+// Use AddQualityLoop to add the loop to a GraphBuilder, or run it on its
+// own with NewCompiledExecutor and compose.WithMaxRunSteps(cfg.MaxRunSteps()).
+func NewQualityLoop[S any](name string, cfg QualityLoopConfig[S]) (*compose.Graph[S, S], error) {
+	cfg = cfg.withDefaults()
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("quality loop %s: %w", name, err)
+	}
+
+	graph := compose.NewGraph[S, S](compose.WithGenLocalState(func(ctx context.Context) *qualityLoopState {
+		return &qualityLoopState{}
+	}))
+
+	score := func(ctx context.Context, state S) (S, error) {
+		value, feedback, err := cfg.Score(ctx, state)
+		if err != nil {
+			return state, fmt.Errorf("quality loop %s: scoring: %w", name, err)
+		}
+		decision := NewQualityDecision(value, cfg.Target)
+		decision.Message = feedback
+
+		var attempts int
+		err = compose.ProcessState(ctx, func(ctx context.Context, ls *qualityLoopState) error {
+			ls.attempts++
+			ls.decision = decision
+			attempts = ls.attempts
+			return nil
+		})
+		if err != nil {
+			return state, fmt.Errorf("quality loop %s: %w", name, err)
+		}
+
+		log.Printf("[%s] Attempt %d/%d scored %d (target %d)", name, attempts, cfg.MaxAttempts, value, cfg.Target)
+		if m, ok := any(state).(metadataSetter); ok && !isNilPointer(m) {
+			m.SetMetadata(QualityDecisionKey(name), decision)
+			m.SetMetadata(QualityAttemptsKey(name), attempts)
+		}
+		return state, nil
+	}
+
+	revise := func(ctx context.Context, state S) (S, error) {
+		var decision *QualityDecision
+		err := compose.ProcessState(ctx, func(ctx context.Context, ls *qualityLoopState) error {
+			decision = ls.decision
+			return nil
+		})
+		if err != nil {
+			return state, fmt.Errorf("quality loop %s: %w", name, err)
+		}
+		return cfg.Revise(ctx, state, decision)
+	}
+
+	next := compose.NewGraphBranch(func(ctx context.Context, state S) (string, error) {
+		to := qualityReviseNode
+		err := compose.ProcessState(ctx, func(ctx context.Context, ls *qualityLoopState) error {
+			if ls.decision.Passed || ls.attempts >= cfg.MaxAttempts {
+				to = compose.END
+			}
+			return nil
+		})
+		return to, err
+	}, map[string]bool{qualityReviseNode: true, compose.END: true})
+
+	if err := graph.AddLambdaNode(qualityGenerateNode, compose.InvokableLambda(cfg.Generate), compose.WithNodeName(qualityGenerateNode)); err != nil {
+		return nil, fmt.Errorf("quality loop %s: %w", name, err)
+	}
+	if err := graph.AddLambdaNode(qualityScoreNode, compose.InvokableLambda(score), compose.WithNodeName(qualityScoreNode)); err != nil {
+		return nil, fmt.Errorf("quality loop %s: %w", name, err)
+	}
+	if err := graph.AddLambdaNode(qualityReviseNode, compose.InvokableLambda(revise), compose.WithNodeName(qualityReviseNode)); err != nil {
+		return nil, fmt.Errorf("quality loop %s: %w", name, err)
+	}
+	if err := graph.AddEdge(compose.START, qualityGenerateNode); err != nil {
+		return nil, fmt.Errorf("quality loop %s: %w", name, err)
+	}
+	if err := graph.AddEdge(qualityGenerateNode, qualityScoreNode); err != nil {
+		return nil, fmt.Errorf("quality loop %s: %w", name, err)
+	}
+	if err := graph.AddBranch(qualityScoreNode, next); err != nil {
+		return nil, fmt.Errorf("quality loop %s: %w", name, err)
+	}
+	if err := graph.AddEdge(qualityReviseNode, qualityScoreNode); err != nil {
+		return nil, fmt.Errorf("quality loop %s: %w", name, err)
+	}
+	return graph, nil
+}
+
+// AddQualityLoop adds a quality loop to gb as a subgraph node called name.
+// Like AddBranch, it is a function rather than a GraphBuilder method
+// because the loop's state type S need not be the graph's input or output:
+//
+//	err := orchestration.AddQualityLoop(gb, "draft", orchestration.QualityLoopConfig[*ReportState]{
+//	    Generate: writeDraft,
+//	    Score:    reviewDraft,
+//	    Revise:   reviseDraft,
+//	    Target:   85,
+//	})
+//	gb.AddStartEdge("draft")
+//	gb.AddEdge("draft", "publish")
+func AddQualityLoop[I, O, S any](gb *GraphBuilder[I, O], name string, cfg QualityLoopConfig[S]) error {
+	loop, err := NewQualityLoop(name, cfg)
+	if err != nil {
+		return err
+	}
+	err = gb.graph.AddGraphNode(name, loop, compose.WithNodeName(name), compose.WithGraphCompileOptions(
+		compose.WithGraphName(name),
+		compose.WithMaxRunSteps(cfg.MaxRunSteps()),
+	))
+	if err != nil {
+		return fmt.Errorf("failed to add node %s: %w", name, err)
+	}
+	gb.nodes = append(gb.nodes, name)
+	return nil
+}
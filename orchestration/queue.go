@@ -0,0 +1,202 @@
+package orchestration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Message is a message received from a queue.
+type Message struct {
+	// ID identifies the message in the queue.
+	ID string
+
+	// Body is the message payload, JSON for workflow inputs.
+	Body []byte
+
+	// Receives is how many times the message has been delivered,
+	// including this delivery.
+	Receives int
+
+	// handle is the source's receipt for acknowledging the message.
+	handle string
+}
+
+// MessageSource delivers queue messages to a QueueConsumer.
+type MessageSource interface {
+	// Receive waits for messages until at least one arrives or ctx is
+	// done.
+	Receive(ctx context.Context) ([]Message, error)
+
+	// Ack removes a processed message from the queue.
+	Ack(ctx context.Context, msg Message) error
+
+	// Nack returns a failed message to the queue for redelivery.
+	Nack(ctx context.Context, msg Message) error
+}
+
+// MessageSink sends messages to a queue, e.g. a dead-letter queue.
+type MessageSink interface {
+	Send(ctx context.Context, body []byte) error
+}
+
+// QueueOptions configures a QueueConsumer.
+type QueueOptions[O any] struct {
+	// Concurrency is the number of messages processed at once.
+	// Default: 1
+	Concurrency int
+
+	// MaxReceives is how many times a message is delivered before it is
+	// given up on. It is then sent to DeadLetter, if set, and removed.
+	// Default: 3
+	MaxReceives int
+
+	// DeadLetter receives messages that could not be processed: messages
+	// that are not valid input and messages that failed MaxReceives
+	// times. Without it, failed messages are left to the queue's own
+	// redrive policy and malformed messages are dropped.
+	// Default: nil
+	DeadLetter MessageSink
+
+	// OnResult is called after each message is processed, with the
+	// workflow's output or error, to report results.
+	// Default: nil
+	OnResult func(ctx context.Context, msg Message, output O, err error)
+}
+
+// QueueConsumer pulls messages from a queue, decodes each into a
+// workflow's input type and executes the workflow. Messages are
+// acknowledged when the workflow succeeds and returned to the queue when
+// it fails.
+type QueueConsumer[I, O any] struct {
+	name     string
+	source   MessageSource
+	executor WorkflowExecutor[I, O]
+	opts     QueueOptions[O]
+}
+
+// NewQueueConsumer creates a consumer that runs executor for the messages
+// of source.
+//
+//	consumer := orchestration.NewQueueConsumer("research", orchestration.NewSQSQueue(queueURL),
+//	    executor, orchestration.QueueOptions[*Output]{
+//	        Concurrency: 4,
+//	        DeadLetter:  orchestration.NewSQSQueue(dlqURL),
+//	    })
+//	err := consumer.Run(ctx)
+func NewQueueConsumer[I, O any](name string, source MessageSource, executor WorkflowExecutor[I, O], opts QueueOptions[O]) *QueueConsumer[I, O] {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	if opts.MaxReceives <= 0 {
+		opts.MaxReceives = 3
+	}
+	return &QueueConsumer[I, O]{name: name, source: source, executor: executor, opts: opts}
+}
+
+// Run consumes messages until ctx is done. Receive errors are logged and
+// retried with backoff. It returns nil when ctx is cancelled.
+func (c *QueueConsumer[I, O]) Run(ctx context.Context) error {
+	log.Printf("[%s] Consuming messages with %d workers", c.name, c.opts.Concurrency)
+
+	var wg sync.WaitGroup
+	for i := 0; i < c.opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.work(ctx)
+		}()
+	}
+	wg.Wait()
+
+	log.Printf("[%s] Stopped consuming messages", c.name)
+	return nil
+}
+
+// work receives and processes messages until ctx is done.
+func (c *QueueConsumer[I, O]) work(ctx context.Context) {
+	backoff := DefaultRetryPolicy()
+	failures := 0
+	for ctx.Err() == nil {
+		msgs, err := c.source.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			failures++
+			wait := backoff.Backoff(failures)
+			log.Printf("[%s] Failed to receive messages, retrying in %s: %v", c.name, wait, err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+			continue
+		}
+		failures = 0
+		for _, msg := range msgs {
+			c.process(ctx, msg)
+		}
+	}
+}
+
+// process executes the workflow for one message and settles it.
+func (c *QueueConsumer[I, O]) process(ctx context.Context, msg Message) {
+	var output O
+	var input I
+	err := json.Unmarshal(msg.Body, &input)
+	if err != nil {
+		err = fmt.Errorf("decoding message %s: %w", msg.ID, err)
+		log.Printf("[%s] %v", c.name, err)
+		c.report(ctx, msg, output, err)
+		c.deadLetter(ctx, msg)
+		return
+	}
+
+	output, err = c.executor.Execute(ctx, input)
+	c.report(ctx, msg, output, err)
+	if err == nil {
+		c.settle(ctx, msg, c.source.Ack)
+		return
+	}
+
+	log.Printf("[%s] Message %s failed (delivery %d/%d): %v", c.name, msg.ID, msg.Receives, c.opts.MaxReceives, err)
+	if msg.Receives >= c.opts.MaxReceives && c.opts.DeadLetter != nil {
+		c.deadLetter(ctx, msg)
+		return
+	}
+	c.settle(ctx, msg, c.source.Nack)
+}
+
+// deadLetter moves a message to the dead-letter queue, or drops it if
+// there is none.
+func (c *QueueConsumer[I, O]) deadLetter(ctx context.Context, msg Message) {
+	ctx = context.WithoutCancel(ctx)
+	if c.opts.DeadLetter != nil {
+		if err := c.opts.DeadLetter.Send(ctx, msg.Body); err != nil {
+			// Leave the message for redelivery rather than losing it.
+			log.Printf("[%s] Failed to dead-letter message %s: %v", c.name, msg.ID, err)
+			c.settle(ctx, msg, c.source.Nack)
+			return
+		}
+		log.Printf("[%s] Sent message %s to the dead-letter queue", c.name, msg.ID)
+	}
+	c.settle(ctx, msg, c.source.Ack)
+}
+
+// settle acknowledges or returns a message, even if ctx is cancelled.
+func (c *QueueConsumer[I, O]) settle(ctx context.Context, msg Message, fn func(context.Context, Message) error) {
+	if err := fn(context.WithoutCancel(ctx), msg); err != nil {
+		log.Printf("[%s] Failed to settle message %s: %v", c.name, msg.ID, err)
+	}
+}
+
+// report calls OnResult, if set.
+func (c *QueueConsumer[I, O]) report(ctx context.Context, msg Message, output O, err error) {
+	if c.opts.OnResult != nil {
+		c.opts.OnResult(ctx, msg, output, err)
+	}
+}
@@ -0,0 +1,200 @@
+package orchestration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/plexusone/agentkit/internal/awscli"
+)
+
+// ErrQueueClosed is returned by ChannelQueue after Close.
+var ErrQueueClosed = errors.New("queue closed")
+
+// ChannelQueue is an in-memory queue backed by a channel. It is meant for
+// tests and local development. It is both a MessageSource and a
+// MessageSink, so it can also serve as a dead-letter queue.
+type ChannelQueue struct {
+	messages chan Message
+	done     chan struct{}
+
+	mu     sync.Mutex
+	nextID int
+	closed bool
+}
+
+// NewChannelQueue creates a queue holding up to size pending messages.
+func NewChannelQueue(size int) *ChannelQueue {
+	return &ChannelQueue{
+		messages: make(chan Message, size),
+		done:     make(chan struct{}),
+	}
+}
+
+// Send implements MessageSink. It blocks while the queue is full.
+func (q *ChannelQueue) Send(ctx context.Context, body []byte) error {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return ErrQueueClosed
+	}
+	q.nextID++
+	msg := Message{ID: strconv.Itoa(q.nextID), Body: body}
+	q.mu.Unlock()
+	return q.put(ctx, msg)
+}
+
+// put enqueues msg unless the queue is closed.
+func (q *ChannelQueue) put(ctx context.Context, msg Message) error {
+	select {
+	case q.messages <- msg:
+		return nil
+	case <-q.done:
+		return ErrQueueClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Receive implements MessageSource. It returns one message at a time.
+func (q *ChannelQueue) Receive(ctx context.Context) ([]Message, error) {
+	select {
+	case msg := <-q.messages:
+		msg.Receives++
+		return []Message{msg}, nil
+	case <-q.done:
+		return nil, ErrQueueClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Ack implements MessageSource.
+func (q *ChannelQueue) Ack(ctx context.Context, msg Message) error {
+	return nil
+}
+
+// Nack implements MessageSource by putting the message back on the queue.
+func (q *ChannelQueue) Nack(ctx context.Context, msg Message) error {
+	// Requeue in the background so a full queue cannot block the consumer
+	// that is draining it.
+	go func() {
+		_ = q.put(context.Background(), msg)
+	}()
+	return nil
+}
+
+// Len returns the number of pending messages.
+func (q *ChannelQueue) Len() int {
+	return len(q.messages)
+}
+
+// Close stops the queue; pending and future receives fail with
+// ErrQueueClosed.
+func (q *ChannelQueue) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.closed {
+		q.closed = true
+		close(q.done)
+	}
+}
+
+// SQSQueue is an Amazon SQS queue. It uses the aws CLI, which must be
+// installed and have credentials for the queue. It is both a
+// MessageSource and a MessageSink.
+//
+// Configure the queue's visibility timeout to exceed the longest workflow
+// run, or messages are redelivered while still being processed.
+type SQSQueue struct {
+	// QueueURL is the URL of the queue.
+	QueueURL string
+
+	// BatchSize is the maximum number of messages per receive. Each
+	// consumer worker processes its batch one message at a time.
+	// Range: 1-10
+	// Default: 1
+	BatchSize int
+
+	// WaitSeconds is the long-polling wait of each receive.
+	// Range: 0-20
+	// Default: 20
+	WaitSeconds int
+}
+
+// NewSQSQueue creates an SQS queue with the default settings.
+func NewSQSQueue(queueURL string) *SQSQueue {
+	return &SQSQueue{QueueURL: queueURL, BatchSize: 1, WaitSeconds: 20}
+}
+
+// Receive implements MessageSource.
+func (q *SQSQueue) Receive(ctx context.Context) ([]Message, error) {
+	batch := q.BatchSize
+	if batch < 1 || batch > 10 {
+		batch = 1
+	}
+	wait := q.WaitSeconds
+	if wait < 0 || wait > 20 {
+		wait = 20
+	}
+
+	out, err := awscli.Run(ctx, "sqs", "receive-message", "--queue-url", q.QueueURL,
+		"--max-number-of-messages", strconv.Itoa(batch),
+		"--wait-time-seconds", strconv.Itoa(wait),
+		"--attribute-names", "ApproximateReceiveCount")
+	if err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+
+	var result struct {
+		Messages []struct {
+			MessageId     string
+			ReceiptHandle string
+			Body          string
+			Attributes    map[string]string
+		}
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return nil, fmt.Errorf("parsing receive-message output: %w", err)
+	}
+	msgs := make([]Message, 0, len(result.Messages))
+	for _, m := range result.Messages {
+		receives, _ := strconv.Atoi(m.Attributes["ApproximateReceiveCount"])
+		msgs = append(msgs, Message{
+			ID:       m.MessageId,
+			Body:     []byte(m.Body),
+			Receives: receives,
+			handle:   m.ReceiptHandle,
+		})
+	}
+	return msgs, nil
+}
+
+// Ack implements MessageSource by deleting the message.
+func (q *SQSQueue) Ack(ctx context.Context, msg Message) error {
+	_, err := awscli.Run(ctx, "sqs", "delete-message", "--queue-url", q.QueueURL, "--receipt-handle", msg.handle)
+	return err
+}
+
+// Nack implements MessageSource by making the message visible again.
+func (q *SQSQueue) Nack(ctx context.Context, msg Message) error {
+	_, err := awscli.Run(ctx, "sqs", "change-message-visibility", "--queue-url", q.QueueURL,
+		"--receipt-handle", msg.handle, "--visibility-timeout", "0")
+	return err
+}
+
+// Send implements MessageSink. The body is handed to the CLI in a file,
+// so messages may use the full 256 KB SQS limit.
+func (q *SQSQueue) Send(ctx context.Context, body []byte) error {
+	_, err := awscli.RunInput(ctx, map[string]string{
+		"QueueUrl":    q.QueueURL,
+		"MessageBody": string(body),
+	}, "sqs", "send-message")
+	return err
+}
@@ -0,0 +1,325 @@
+package orchestration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// WorkflowsPath is the path under which Registry serves its catalog.
+const WorkflowsPath = "/workflows"
+
+// WorkflowInfo describes a registered workflow in the catalog.
+type WorkflowInfo struct {
+	// Name identifies the workflow; it is run with POST /workflows/{name}.
+	Name string `json:"name"`
+
+	// Description explains what the workflow does.
+	Description string `json:"description,omitempty"`
+
+	// Version is the version of the workflow; see RegisterVersion.
+	Version string `json:"version,omitempty"`
+
+	// Versions lists the registered versions of the workflow, oldest
+	// first. It is only set for versioned workflows.
+	Versions []string `json:"versions,omitempty"`
+
+	// InputSchema is the JSON Schema of the request body.
+	InputSchema json.RawMessage `json:"input_schema,omitempty"`
+
+	// OutputSchema is the JSON Schema of the response body.
+	OutputSchema json.RawMessage `json:"output_schema,omitempty"`
+}
+
+// WorkflowExecutor runs a workflow. Executor and CompiledExecutor
+// implement it.
+type WorkflowExecutor[I, O any] interface {
+	Execute(ctx context.Context, input I) (O, error)
+}
+
+// registeredWorkflow is a workflow with its types erased.
+type registeredWorkflow struct {
+	info    WorkflowInfo
+	execute func(ctx context.Context, body io.Reader) (interface{}, error)
+}
+
+// Registry maps workflow names to executors so one server can host many
+// workflows. It serves a catalog and runs workflows over HTTP; see
+// ServeHTTP.
+type Registry struct {
+	mu        sync.RWMutex
+	workflows map[string]*registeredWorkflow   // latest version by name
+	versions  map[string][]*registeredWorkflow // all versions by name, oldest first
+	store     CheckpointStore
+}
+
+// NewRegistry creates an empty workflow registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		workflows: make(map[string]*registeredWorkflow),
+		versions:  make(map[string][]*registeredWorkflow),
+	}
+}
+
+// SetCheckpointStore sets the store of the checkpointed runs started over
+// HTTP with a run_id; see ServeHTTP.
+func (r *Registry) SetCheckpointStore(store CheckpointStore) *Registry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.store = store
+	return r
+}
+
+// Register adds a workflow to r under name. The input and output schemas
+// in the catalog are derived from I and O. It returns an error if a
+// workflow with the same name already exists.
+//
+// Register is a function rather than a Registry method because Go methods
+// cannot have type parameters:
+//
+//	registry := orchestration.NewRegistry()
+//	err := orchestration.Register(registry, "research", "Researches a topic", researchExecutor)
+func Register[I, O any](r *Registry, name, description string, executor WorkflowExecutor[I, O]) error {
+	return RegisterVersion(r, name, "", description, executor)
+}
+
+// RegisterVersion adds a version of a workflow to r. The version
+// registered last is the latest: new runs use it, while checkpointed runs
+// in flight continue on the version they started on (see ExecuteRun), so
+// workflow code can change without resuming old state with new code. Keep
+// older versions registered until their runs finish. Executions are run
+// under WithWorkflowVersion.
+//
+//	orchestration.RegisterVersion(registry, "research", "v1", "Researches a topic", researchV1)
+//	orchestration.RegisterVersion(registry, "research", "v2", "Researches a topic", researchV2)
+func RegisterVersion[I, O any](r *Registry, name, version, description string, executor WorkflowExecutor[I, O]) error {
+	if name == "" || strings.Contains(name, "/") {
+		return fmt.Errorf("invalid workflow name %q", name)
+	}
+
+	info := WorkflowInfo{
+		Name:         name,
+		Description:  description,
+		Version:      version,
+		InputSchema:  SchemaOf[I](),
+		OutputSchema: SchemaOf[O](),
+	}
+	wf := &registeredWorkflow{
+		info: info,
+		execute: func(ctx context.Context, body io.Reader) (interface{}, error) {
+			var input I
+			if err := json.NewDecoder(body).Decode(&input); err != nil {
+				return nil, &requestError{err: err}
+			}
+			if version != "" {
+				ctx = WithWorkflowVersion(ctx, version)
+			}
+			return executor.Execute(ctx, input)
+		},
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.versions[name] {
+		if existing.info.Version == version {
+			if version == "" {
+				return fmt.Errorf("workflow already registered: %s", name)
+			}
+			return fmt.Errorf("workflow already registered: %s version %s", name, version)
+		}
+	}
+	r.workflows[name] = wf
+	r.versions[name] = append(r.versions[name], wf)
+	return nil
+}
+
+// requestError marks an invalid request body.
+type requestError struct {
+	err error
+}
+
+func (e *requestError) Error() string { return e.err.Error() }
+func (e *requestError) Unwrap() error { return e.err }
+
+// List returns the registered workflows, sorted by name.
+func (r *Registry) List() []WorkflowInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	infos := make([]WorkflowInfo, 0, len(r.workflows))
+	for name := range r.workflows {
+		infos = append(infos, r.info(name))
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// Get returns the catalog entry of the latest version of a workflow.
+func (r *Registry) Get(name string) (WorkflowInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if _, ok := r.workflows[name]; !ok {
+		return WorkflowInfo{}, false
+	}
+	return r.info(name), true
+}
+
+// info returns the catalog entry of the latest version of a registered
+// workflow. r.mu must be held.
+func (r *Registry) info(name string) WorkflowInfo {
+	info := r.workflows[name].info
+	if info.Version != "" {
+		for _, wf := range r.versions[name] {
+			info.Versions = append(info.Versions, wf.info.Version)
+		}
+	}
+	return info
+}
+
+// lookup returns a version of a workflow, or its latest version if
+// version is "".
+func (r *Registry) lookup(name, version string) (*registeredWorkflow, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if version == "" {
+		if wf, ok := r.workflows[name]; ok {
+			return wf, nil
+		}
+		return nil, fmt.Errorf("workflow not found: %s", name)
+	}
+	for _, wf := range r.versions[name] {
+		if wf.info.Version == version {
+			return wf, nil
+		}
+	}
+	return nil, fmt.Errorf("workflow not found: %s version %s", name, version)
+}
+
+// RunVersion returns the version of workflow name that run runID must
+// execute on; see RouteVersion.
+func (r *Registry) RunVersion(ctx context.Context, name string, store CheckpointStore, runID string) (string, error) {
+	latest, err := r.lookup(name, "")
+	if err != nil {
+		return "", err
+	}
+	return RouteVersion(ctx, store, runID, latest.info.Version)
+}
+
+// ExecuteRun runs workflow name as checkpointed run runID with the JSON
+// input and returns the JSON output. A run with a checkpoint resumes on
+// the version that saved it; a new run uses the latest version. It
+// returns an error if the run's version is no longer registered.
+func (r *Registry) ExecuteRun(ctx context.Context, name string, store CheckpointStore, runID string, input json.RawMessage) (json.RawMessage, error) {
+	version, err := r.RunVersion(ctx, name, store, runID)
+	if err != nil {
+		return nil, err
+	}
+	wf, err := r.lookup(name, version)
+	if err != nil {
+		return nil, fmt.Errorf("run %s: %w", runID, err)
+	}
+
+	result, err := wf.execute(WithCheckpoints(ctx, store, runID), bytes.NewReader(input))
+	if err != nil {
+		return nil, err
+	}
+	output, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("encoding output: %w", err)
+	}
+	return output, nil
+}
+
+// ServeHTTP implements http.Handler. Mount the registry at "/workflows/":
+//
+//	GET  /workflows                     lists the workflows with their schemas
+//	GET  /workflows/{name}              describes one workflow
+//	POST /workflows/{name}              runs a workflow with the JSON request body
+//	POST /workflows/{name}?run_id={id}  runs or resumes a checkpointed run; see ExecuteRun
+//
+// Checkpointed runs require SetCheckpointStore.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	name := strings.Trim(strings.TrimPrefix(path.Clean(req.URL.Path), WorkflowsPath), "/")
+
+	if name == "" {
+		if req.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, r.List())
+		return
+	}
+
+	info, ok := r.Get(name)
+	if !ok {
+		http.Error(w, fmt.Sprintf("Workflow not found: %s", name), http.StatusNotFound)
+		return
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+		writeJSON(w, info)
+	case http.MethodPost:
+		resp, err := r.execute(req, name)
+		if err != nil {
+			var reqErr *requestError
+			if errors.As(err, &reqErr) {
+				http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+				return
+			}
+			http.Error(w, fmt.Sprintf("Execution failed: %v", err), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, resp)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// execute runs the workflow of a POST request, as a checkpointed run if
+// the request has a run_id.
+func (r *Registry) execute(req *http.Request, name string) (interface{}, error) {
+	runID := req.URL.Query().Get("run_id")
+	if runID == "" {
+		wf, err := r.lookup(name, "")
+		if err != nil {
+			return nil, err
+		}
+		return wf.execute(req.Context(), req.Body)
+	}
+
+	r.mu.RLock()
+	store := r.store
+	r.mu.RUnlock()
+	if store == nil {
+		return nil, &requestError{err: errors.New("run_id requires a checkpoint store")}
+	}
+	input, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, &requestError{err: err}
+	}
+	return r.ExecuteRun(req.Context(), name, store, runID, input)
+}
+
+// writeJSON writes v as a JSON response.
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	writeJSONStatus(w, http.StatusOK, v)
+}
+
+// writeJSONStatus writes v as a JSON response with status.
+func writeJSONStatus(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Failed to encode response: %v", err)
+	}
+}
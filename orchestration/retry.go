@@ -0,0 +1,127 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"time"
+
+	"github.com/cloudwego/eino/compose"
+
+	agenthttp "github.com/plexusone/agentkit/http"
+)
+
+// RetryPolicy configures how a node is retried. It is the shared
+// http.RetryPolicy, whose Retryable defaults to http.IsTransient, which
+// also treats errors marked with Transient as transient.
+type RetryPolicy = agenthttp.RetryPolicy
+
+// DefaultRetryPolicy returns a RetryPolicy with the default values.
+func DefaultRetryPolicy() RetryPolicy {
+	return agenthttp.DefaultRetryPolicy()
+}
+
+// transientError marks an error as transient.
+type transientError struct {
+	err error
+}
+
+func (e *transientError) Error() string   { return e.err.Error() }
+func (e *transientError) Unwrap() error   { return e.err }
+func (e *transientError) Transient() bool { return true }
+
+// Transient marks err as transient, so IsTransient reports true for it and
+// for errors wrapping it. It returns nil if err is nil.
+func Transient(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &transientError{err: err}
+}
+
+// IsTransient reports whether err is likely to succeed on retry: errors
+// marked with Transient or implementing Transient() bool, network
+// timeouts, refused or reset connections, and unexpected EOFs. Context
+// cancellation is never transient. It is http.IsTransient.
+func IsTransient(err error) bool {
+	return agenthttp.IsTransient(err)
+}
+
+// metadataSetter is implemented by *State and by types embedding State.
+type metadataSetter interface {
+	SetMetadata(key string, value interface{})
+}
+
+// RetryAttemptsKey returns the State.Metadata key under which WithRetry
+// records the number of attempts of node.
+func RetryAttemptsKey(node string) string {
+	return "retry." + node + ".attempts"
+}
+
+// WithRetry returns a lambda node that calls fn, retrying it with
+// exponential backoff while it fails with a retryable error. The number of
+// attempts is recorded in the metadata of the node's output, or of its
+// input if the output is nil, when either embeds State; see
+// RetryAttemptsKey. If the node still fails after retries, its error is a
+// *NodeError with the number of attempts.
+//
+//	gb.AddLambdaNodeFunc("research", orchestration.WithRetry("research",
+//	    researchStep, orchestration.DefaultRetryPolicy()))
+func WithRetry[I, O any](name string, fn func(ctx context.Context, input I) (O, error), policy RetryPolicy) *compose.Lambda {
+	policy = policy.WithDefaults()
+	return compose.InvokableLambda(func(ctx context.Context, input I) (O, error) {
+		var output O
+		attempts, err := runRetry(ctx, policy, name, func(ctx context.Context) error {
+			var err error
+			output, err = fn(ctx, input)
+			return err
+		})
+
+		recordAttempts(name, attempts, output, input)
+		if err != nil && attempts > 1 {
+			return output, &NodeError{Node: name, Attempt: attempts, Err: err}
+		}
+		return output, err
+	})
+}
+
+// runRetry calls fn under p with http.RetryPolicy.Do, logging each
+// retry. It returns the number of attempts and the last error, wrapped
+// with ctx.Err() if ctx ended the retries.
+func runRetry(ctx context.Context, p RetryPolicy, name string, fn func(ctx context.Context) error) (int, error) {
+	attempts := 0
+	onRetry := p.OnRetry
+	p.OnRetry = func(attempt int, wait time.Duration, err error) {
+		log.Printf("[%s] Attempt %d/%d failed, retrying in %s: %v", name, attempt, p.MaxAttempts, wait, err)
+		if onRetry != nil {
+			onRetry(attempt, wait, err)
+		}
+	}
+	err := p.Do(ctx, func(ctx context.Context) error {
+		attempts++
+		return fn(ctx)
+	})
+	if err != nil && ctx.Err() != nil && !errors.Is(err, ctx.Err()) {
+		return attempts, fmt.Errorf("%w (last error: %v)", ctx.Err(), err)
+	}
+	return attempts, err
+}
+
+// recordAttempts records the attempts of node in the first of states that
+// can hold metadata.
+func recordAttempts(node string, attempts int, states ...interface{}) {
+	for _, s := range states {
+		if m, ok := s.(metadataSetter); ok && !isNilPointer(m) {
+			m.SetMetadata(RetryAttemptsKey(node), attempts)
+			return
+		}
+	}
+}
+
+// isNilPointer reports whether v holds a nil pointer.
+func isNilPointer(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
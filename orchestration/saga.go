@@ -0,0 +1,123 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+)
+
+// compensation undoes one completed step.
+type compensation struct {
+	node string
+	undo func(ctx context.Context) error
+}
+
+// Saga records the compensations of completed workflow steps so they can
+// be undone in reverse order when a later step fails. Executors start a
+// saga for every run and compensate automatically; use NewSaga and
+// WithSaga to control compensation yourself, e.g. when invoking a
+// compiled graph directly or grouping several executions into one saga.
+type Saga struct {
+	mu    sync.Mutex
+	steps []compensation
+}
+
+// NewSaga creates an empty saga.
+func NewSaga() *Saga {
+	return &Saga{}
+}
+
+type sagaKey struct{}
+
+// WithSaga returns a context under which Compensable nodes register their
+// compensations with saga.
+func WithSaga(ctx context.Context, saga *Saga) context.Context {
+	return context.WithValue(ctx, sagaKey{}, saga)
+}
+
+// Len returns the number of compensations waiting to run.
+func (s *Saga) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.steps)
+}
+
+// Compensate runs the registered compensations in reverse order and
+// clears them. A failed compensation does not stop the others; all
+// failures are returned joined.
+func (s *Saga) Compensate(ctx context.Context) error {
+	s.mu.Lock()
+	steps := s.steps
+	s.steps = nil
+	s.mu.Unlock()
+
+	var errs []error
+	for i := len(steps) - 1; i >= 0; i-- {
+		step := steps[i]
+		log.Printf("[%s] Compensating", step.node)
+		if err := step.undo(ctx); err != nil {
+			log.Printf("[%s] Compensation failed: %v", step.node, err)
+			errs = append(errs, fmt.Errorf("compensating %s: %w", step.node, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// add registers a compensation.
+func (s *Saga) add(node string, undo func(ctx context.Context) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.steps = append(s.steps, compensation{node: node, undo: undo})
+}
+
+// Compensable wraps a node function with side effects so that, once it
+// succeeds, compensate is registered to undo it with the node's output.
+// If a later node fails, the executor runs the compensations of all
+// completed steps in reverse order:
+//
+//	gb.AddLambdaNodeFunc("create_ticket", compose.InvokableLambda(
+//	    orchestration.Compensable("create_ticket", createTicket,
+//	        func(ctx context.Context, s *State) error {
+//	            return tickets.Close(ctx, s.TicketID)
+//	        })))
+//
+// Steps skipped on resume by Checkpointed are not compensated.
+func Compensable[I, O any](node string, fn func(ctx context.Context, input I) (O, error), compensate func(ctx context.Context, output O) error) func(ctx context.Context, input I) (O, error) {
+	return func(ctx context.Context, input I) (O, error) {
+		output, err := fn(ctx, input)
+		if err != nil {
+			return output, err
+		}
+		if saga, ok := ctx.Value(sagaKey{}).(*Saga); ok {
+			saga.add(node, func(ctx context.Context) error {
+				return compensate(ctx, output)
+			})
+		}
+		return output, nil
+	}
+}
+
+// startSaga returns a context with a new saga for one run, or ctx and nil
+// if the caller already manages a saga.
+func startSaga(ctx context.Context) (context.Context, *Saga) {
+	if _, ok := ctx.Value(sagaKey{}).(*Saga); ok {
+		return ctx, nil
+	}
+	saga := NewSaga()
+	return WithSaga(ctx, saga), saga
+}
+
+// compensateRun runs the compensations of a failed run started with
+// startSaga and adds their failures to err. The compensations run even if
+// ctx was cancelled.
+func compensateRun(ctx context.Context, saga *Saga, err error) error {
+	if saga == nil || saga.Len() == 0 {
+		return err
+	}
+	if cerr := saga.Compensate(context.WithoutCancel(ctx)); cerr != nil {
+		return errors.Join(err, cerr)
+	}
+	return err
+}
@@ -0,0 +1,329 @@
+package orchestration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// OverlapPolicy decides what happens when a scheduled run is due while the
+// job's previous run is still going.
+type OverlapPolicy string
+
+const (
+	// OverlapSkip skips the due run and records it as skipped.
+	OverlapSkip OverlapPolicy = "skip"
+
+	// OverlapQueue runs the due run after the previous ones finish.
+	OverlapQueue OverlapPolicy = "queue"
+)
+
+// Statuses of a ScheduledRun.
+const (
+	RunSucceeded = "succeeded"
+	RunFailed    = "failed"
+	RunSkipped   = "skipped"
+)
+
+// ScheduledJob runs a registered workflow on a cron schedule.
+type ScheduledJob struct {
+	// Name identifies the job in logs and history.
+	Name string
+
+	// Cron is the schedule; see ParseCron.
+	Cron string
+
+	// Workflow is the name of the workflow in the scheduler's Registry.
+	Workflow string
+
+	// Input is a text/template that renders the workflow's JSON input.
+	// It is executed with a ScheduleData, e.g.
+	// {"date": "{{.Time.Format "2006-01-02"}}"}.
+	// Default: "{}"
+	Input string
+
+	// Overlap decides what happens when a run is due while the previous
+	// one is still going.
+	// Supported: OverlapSkip, OverlapQueue
+	// Default: OverlapSkip
+	Overlap OverlapPolicy
+}
+
+// ScheduleData is the data of a job's input template.
+type ScheduleData struct {
+	// Job is the job's name.
+	Job string
+
+	// Workflow is the name of the workflow being run.
+	Workflow string
+
+	// Time is when the run was scheduled.
+	Time time.Time
+}
+
+// ScheduledRun is one entry of a scheduler's run history.
+type ScheduledRun struct {
+	Job         string          `json:"job"`
+	Workflow    string          `json:"workflow"`
+	ScheduledAt time.Time       `json:"scheduled_at"`
+	StartedAt   time.Time       `json:"started_at,omitempty"`
+	FinishedAt  time.Time       `json:"finished_at,omitempty"`
+	Status      string          `json:"status"`
+	Output      json.RawMessage `json:"output,omitempty"`
+	Error       string          `json:"error,omitempty"`
+}
+
+// SchedulerOptions configures a Scheduler.
+type SchedulerOptions struct {
+	// Location is the time zone in which cron expressions are evaluated.
+	// Default: time.Local
+	Location *time.Location
+
+	// HistorySize is the number of runs kept in the history of each job.
+	// Default: 100
+	HistorySize int
+}
+
+// scheduledJob is a job with its parsed schedule and run state.
+type scheduledJob struct {
+	ScheduledJob
+	schedule *CronSchedule
+	input    *template.Template
+
+	mu      sync.Mutex
+	running bool
+	queued  []time.Time
+	history []ScheduledRun
+}
+
+// Scheduler runs workflows of a Registry on cron schedules, for periodic
+// agent jobs that run outside a managed scheduler such as EventBridge. It
+// keeps a bounded history of each job's runs.
+type Scheduler struct {
+	registry *Registry
+	opts     SchedulerOptions
+
+	mu   sync.Mutex
+	jobs map[string]*scheduledJob
+	runs sync.WaitGroup
+}
+
+// NewScheduler creates a scheduler for the workflows of registry.
+//
+//	scheduler := orchestration.NewScheduler(registry, orchestration.SchedulerOptions{})
+//	err := scheduler.Add(orchestration.ScheduledJob{
+//	    Name:     "daily-digest",
+//	    Cron:     "0 7 * * MON-FRI",
+//	    Workflow: "digest",
+//	    Input:    `{"date": "{{.Time.Format "2006-01-02"}}"}`,
+//	})
+//	err = scheduler.Run(ctx)
+func NewScheduler(registry *Registry, opts SchedulerOptions) *Scheduler {
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+	if opts.HistorySize <= 0 {
+		opts.HistorySize = 100
+	}
+	return &Scheduler{registry: registry, opts: opts, jobs: make(map[string]*scheduledJob)}
+}
+
+// Add adds a job. It returns an error if the schedule or input template is
+// invalid, the workflow is not registered, or a job with the same name
+// exists. Jobs must be added before Run.
+func (s *Scheduler) Add(job ScheduledJob) error {
+	if job.Name == "" {
+		return fmt.Errorf("scheduled job name is required")
+	}
+	schedule, err := ParseCron(job.Cron)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", job.Name, err)
+	}
+	if _, ok := s.registry.Get(job.Workflow); !ok {
+		return fmt.Errorf("job %s: workflow not found: %s", job.Name, job.Workflow)
+	}
+	if job.Input == "" {
+		job.Input = "{}"
+	}
+	input, err := template.New(job.Name).Option("missingkey=error").Parse(job.Input)
+	if err != nil {
+		return fmt.Errorf("job %s: parsing input template: %w", job.Name, err)
+	}
+	switch job.Overlap {
+	case "":
+		job.Overlap = OverlapSkip
+	case OverlapSkip, OverlapQueue:
+	default:
+		return fmt.Errorf("job %s: unsupported overlap policy %q", job.Name, job.Overlap)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.jobs[job.Name]; exists {
+		return fmt.Errorf("job already scheduled: %s", job.Name)
+	}
+	s.jobs[job.Name] = &scheduledJob{ScheduledJob: job, schedule: schedule, input: input}
+	return nil
+}
+
+// History returns the recorded runs of a job, oldest first, or of all
+// jobs ordered by scheduled time if job is empty.
+func (s *Scheduler) History(job string) []ScheduledRun {
+	s.mu.Lock()
+	jobs := make([]*scheduledJob, 0, len(s.jobs))
+	for name, j := range s.jobs {
+		if job == "" || name == job {
+			jobs = append(jobs, j)
+		}
+	}
+	s.mu.Unlock()
+
+	var runs []ScheduledRun
+	for _, j := range jobs {
+		j.mu.Lock()
+		runs = append(runs, j.history...)
+		j.mu.Unlock()
+	}
+	sort.SliceStable(runs, func(a, b int) bool { return runs[a].ScheduledAt.Before(runs[b].ScheduledAt) })
+	return runs
+}
+
+// Run triggers the jobs on their schedules until ctx is done, then waits
+// for runs in progress, whose context is cancelled, and returns nil.
+func (s *Scheduler) Run(ctx context.Context) error {
+	s.mu.Lock()
+	jobs := make([]*scheduledJob, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		jobs = append(jobs, j)
+	}
+	s.mu.Unlock()
+	log.Printf("Scheduler started with %d jobs", len(jobs))
+
+	var wg sync.WaitGroup
+	for _, j := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.tick(ctx, j)
+		}()
+	}
+	wg.Wait()
+	s.runs.Wait()
+
+	log.Printf("Scheduler stopped")
+	return nil
+}
+
+// tick triggers a job at each of its scheduled times until ctx is done.
+func (s *Scheduler) tick(ctx context.Context, j *scheduledJob) {
+	for {
+		next := j.schedule.Next(time.Now().In(s.opts.Location))
+		if next.IsZero() {
+			log.Printf("[%s] Schedule %q has no upcoming runs", j.Name, j.Cron)
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.trigger(ctx, j, next)
+	}
+}
+
+// trigger starts the run of a job scheduled at, applying the job's
+// overlap policy.
+func (s *Scheduler) trigger(ctx context.Context, j *scheduledJob, at time.Time) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.running {
+		if j.Overlap == OverlapQueue {
+			j.queued = append(j.queued, at)
+			return
+		}
+		log.Printf("[%s] Skipping run scheduled at %s: previous run still in progress", j.Name, at.Format(time.RFC3339))
+		j.record(ScheduledRun{Job: j.Name, Workflow: j.Workflow, ScheduledAt: at, Status: RunSkipped}, s.opts.HistorySize)
+		return
+	}
+
+	j.running = true
+	s.runs.Add(1)
+	go func() {
+		defer s.runs.Done()
+		for {
+			s.run(ctx, j, at)
+
+			j.mu.Lock()
+			if len(j.queued) == 0 || ctx.Err() != nil {
+				j.queued = nil
+				j.running = false
+				j.mu.Unlock()
+				return
+			}
+			at = j.queued[0]
+			j.queued = j.queued[1:]
+			j.mu.Unlock()
+		}
+	}()
+}
+
+// run executes one run of a job and records it.
+func (s *Scheduler) run(ctx context.Context, j *scheduledJob, at time.Time) {
+	run := ScheduledRun{Job: j.Name, Workflow: j.Workflow, ScheduledAt: at, StartedAt: time.Now()}
+	log.Printf("[%s] Running workflow %s", j.Name, j.Workflow)
+
+	output, err := s.execute(ctx, j, at)
+	run.FinishedAt = time.Now()
+	if err != nil {
+		log.Printf("[%s] Run failed: %v", j.Name, err)
+		run.Status = RunFailed
+		run.Error = err.Error()
+	} else {
+		run.Status = RunSucceeded
+		run.Output = output
+	}
+
+	j.mu.Lock()
+	j.record(run, s.opts.HistorySize)
+	j.mu.Unlock()
+}
+
+// execute renders a job's input and runs its workflow.
+func (s *Scheduler) execute(ctx context.Context, j *scheduledJob, at time.Time) (json.RawMessage, error) {
+	var input bytes.Buffer
+	if err := j.input.Execute(&input, ScheduleData{Job: j.Name, Workflow: j.Workflow, Time: at}); err != nil {
+		return nil, fmt.Errorf("rendering input: %w", err)
+	}
+
+	wf, err := s.registry.lookup(j.Workflow, "")
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := wf.execute(ctx, &input)
+	if err != nil {
+		return nil, err
+	}
+	output, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("encoding output: %w", err)
+	}
+	return output, nil
+}
+
+// record appends run to the job's history, dropping the oldest runs
+// beyond size. j.mu must be held.
+func (j *scheduledJob) record(run ScheduledRun, size int) {
+	j.history = append(j.history, run)
+	if over := len(j.history) - size; over > 0 {
+		j.history = append([]ScheduledRun(nil), j.history[over:]...)
+	}
+}
@@ -0,0 +1,519 @@
+package orchestration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cloudwego/eino/components/tool/utils"
+)
+
+// SchemaOf returns the JSON Schema of T, or nil if it cannot be derived.
+// jsonschema struct tags add descriptions and constraints.
+func SchemaOf[T any]() json.RawMessage {
+	params, err := utils.GoStruct2ParamsOneOf[T]()
+	if err != nil {
+		return nil
+	}
+	js, err := params.ToJSONSchema()
+	if err != nil {
+		return nil
+	}
+	data, err := json.Marshal(js)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// FieldError is a JSON Schema violation at one location of a document.
+type FieldError struct {
+	// Field is the JSON Pointer of the offending value, e.g.
+	// "/items/0/name"; it is empty for the document itself.
+	Field string `json:"field"`
+
+	// Message describes the violation.
+	Message string `json:"message"`
+}
+
+// Error implements error.
+func (e FieldError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+// jsonSchema validates JSON documents against a schema. It supports the
+// validation keywords of draft 2020-12 and draft-07 except format,
+// dependencies and conditionals, and local $refs.
+type jsonSchema struct {
+	root     interface{}
+	patterns map[string]*regexp.Regexp
+}
+
+// maxSchemaDepth bounds $ref resolution to stop recursive schemas from
+// looping.
+const maxSchemaDepth = 64
+
+// compileSchema parses a JSON Schema and compiles its patterns.
+func compileSchema(data json.RawMessage) (*jsonSchema, error) {
+	root, err := decodeJSONValue(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing schema: %w", err)
+	}
+	switch root.(type) {
+	case map[string]interface{}, bool:
+	default:
+		return nil, fmt.Errorf("parsing schema: must be an object or a boolean")
+	}
+	s := &jsonSchema{root: root, patterns: make(map[string]*regexp.Regexp)}
+	if err := s.compilePatterns(root); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// compilePatterns compiles the regular expressions of pattern and
+// patternProperties anywhere in schema.
+func (s *jsonSchema) compilePatterns(schema interface{}) error {
+	switch v := schema.(type) {
+	case map[string]interface{}:
+		if p, ok := v["pattern"].(string); ok {
+			if err := s.compilePattern(p); err != nil {
+				return err
+			}
+		}
+		if props, ok := v["patternProperties"].(map[string]interface{}); ok {
+			for p := range props {
+				if err := s.compilePattern(p); err != nil {
+					return err
+				}
+			}
+		}
+		for _, child := range v {
+			if err := s.compilePatterns(child); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			if err := s.compilePatterns(child); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// compilePattern compiles and caches one regular expression.
+func (s *jsonSchema) compilePattern(pattern string) error {
+	if _, ok := s.patterns[pattern]; ok {
+		return nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("parsing schema: pattern %q: %w", pattern, err)
+	}
+	s.patterns[pattern] = re
+	return nil
+}
+
+// validate validates a JSON document and returns its violations, sorted by
+// field.
+func (s *jsonSchema) validate(data []byte) ([]FieldError, error) {
+	value, err := decodeJSONValue(data)
+	if err != nil {
+		return nil, err
+	}
+	var errs []FieldError
+	s.check(s.root, value, "", 0, &errs)
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+	return errs, nil
+}
+
+// decodeJSONValue decodes JSON into generic values, keeping numbers exact.
+func decodeJSONValue(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// check validates value at path against schema, appending violations to
+// errs.
+func (s *jsonSchema) check(schema, value interface{}, path string, depth int, errs *[]FieldError) {
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, FieldError{Field: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	sch, ok := schema.(map[string]interface{})
+	if !ok {
+		if allowed, isBool := schema.(bool); isBool && !allowed {
+			fail("is not allowed")
+		}
+		return
+	}
+
+	if ref, ok := sch["$ref"].(string); ok {
+		target, err := s.resolve(ref)
+		switch {
+		case err != nil:
+			fail("%v", err)
+		case depth >= maxSchemaDepth:
+			fail("schema $ref nesting exceeds %d", maxSchemaDepth)
+		default:
+			s.check(target, value, path, depth+1, errs)
+		}
+	}
+
+	if types, ok := schemaTypes(sch["type"]); ok && !matchesAnyType(value, types) {
+		fail("must be %s", strings.Join(types, " or "))
+		return
+	}
+	if enum, ok := sch["enum"].([]interface{}); ok && !containsJSON(enum, value) {
+		fail("must be one of %s", formatJSON(enum))
+	}
+	if c, ok := sch["const"]; ok && !equalJSON(c, value) {
+		fail("must be %s", formatJSON(c))
+	}
+
+	switch v := value.(type) {
+	case json.Number:
+		s.checkNumber(sch, v, fail)
+	case string:
+		s.checkString(sch, v, fail)
+	case []interface{}:
+		s.checkArray(sch, v, path, depth, errs, fail)
+	case map[string]interface{}:
+		s.checkObject(sch, v, path, depth, errs, fail)
+	}
+
+	if all, ok := sch["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			s.check(sub, value, path, depth, errs)
+		}
+	}
+	if anyOf, ok := sch["anyOf"].([]interface{}); ok && s.countMatches(anyOf, value, path, depth) == 0 {
+		fail("must match at least one schema of anyOf")
+	}
+	if oneOf, ok := sch["oneOf"].([]interface{}); ok {
+		if n := s.countMatches(oneOf, value, path, depth); n != 1 {
+			fail("must match exactly one schema of oneOf, matched %d", n)
+		}
+	}
+	if not, ok := sch["not"]; ok && s.countMatches([]interface{}{not}, value, path, depth) == 1 {
+		fail("must not match the schema of not")
+	}
+}
+
+// countMatches returns how many of schemas value matches.
+func (s *jsonSchema) countMatches(schemas []interface{}, value interface{}, path string, depth int) int {
+	n := 0
+	for _, sub := range schemas {
+		var subErrs []FieldError
+		s.check(sub, value, path, depth, &subErrs)
+		if len(subErrs) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// checkNumber applies the numeric keywords.
+func (s *jsonSchema) checkNumber(sch map[string]interface{}, v json.Number, fail func(string, ...interface{})) {
+	n, ok := new(big.Float).SetString(v.String())
+	if !ok {
+		return
+	}
+	bound := func(key string) (*big.Float, bool) {
+		b, ok := sch[key].(json.Number)
+		if !ok {
+			return nil, false
+		}
+		f, ok := new(big.Float).SetString(b.String())
+		return f, ok
+	}
+	if min, ok := bound("minimum"); ok && n.Cmp(min) < 0 {
+		fail("must be >= %s", sch["minimum"])
+	}
+	if max, ok := bound("maximum"); ok && n.Cmp(max) > 0 {
+		fail("must be <= %s", sch["maximum"])
+	}
+	if min, ok := bound("exclusiveMinimum"); ok && n.Cmp(min) <= 0 {
+		fail("must be > %s", sch["exclusiveMinimum"])
+	}
+	if max, ok := bound("exclusiveMaximum"); ok && n.Cmp(max) >= 0 {
+		fail("must be < %s", sch["exclusiveMaximum"])
+	}
+	if m, ok := bound("multipleOf"); ok && m.Sign() != 0 {
+		if !new(big.Float).Quo(n, m).IsInt() {
+			fail("must be a multiple of %s", sch["multipleOf"])
+		}
+	}
+}
+
+// checkString applies the string keywords.
+func (s *jsonSchema) checkString(sch map[string]interface{}, v string, fail func(string, ...interface{})) {
+	length := utf8.RuneCountInString(v)
+	if min, ok := schemaInt(sch["minLength"]); ok && length < min {
+		fail("length must be >= %d", min)
+	}
+	if max, ok := schemaInt(sch["maxLength"]); ok && length > max {
+		fail("length must be <= %d", max)
+	}
+	if p, ok := sch["pattern"].(string); ok && !s.patterns[p].MatchString(v) {
+		fail("must match pattern %q", p)
+	}
+}
+
+// checkArray applies the array keywords.
+func (s *jsonSchema) checkArray(sch map[string]interface{}, v []interface{}, path string, depth int, errs *[]FieldError, fail func(string, ...interface{})) {
+	if min, ok := schemaInt(sch["minItems"]); ok && len(v) < min {
+		fail("must have at least %d items", min)
+	}
+	if max, ok := schemaInt(sch["maxItems"]); ok && len(v) > max {
+		fail("must have at most %d items", max)
+	}
+	if unique, _ := sch["uniqueItems"].(bool); unique {
+		for i := range v {
+			for j := i + 1; j < len(v); j++ {
+				if equalJSON(v[i], v[j]) {
+					fail("items %d and %d are equal", i, j)
+				}
+			}
+		}
+	}
+
+	// prefixItems (or items as an array, in draft-07) constrain leading
+	// items; items constrains the rest.
+	prefix, _ := sch["prefixItems"].([]interface{})
+	rest, hasRest := sch["items"]
+	if tuple, ok := rest.([]interface{}); ok {
+		prefix, rest = tuple, sch["additionalItems"]
+		_, hasRest = sch["additionalItems"]
+	}
+	for i, item := range v {
+		itemPath := path + "/" + strconv.Itoa(i)
+		switch {
+		case i < len(prefix):
+			s.check(prefix[i], item, itemPath, depth, errs)
+		case hasRest:
+			s.check(rest, item, itemPath, depth, errs)
+		}
+	}
+}
+
+// checkObject applies the object keywords.
+func (s *jsonSchema) checkObject(sch map[string]interface{}, v map[string]interface{}, path string, depth int, errs *[]FieldError, fail func(string, ...interface{})) {
+	if min, ok := schemaInt(sch["minProperties"]); ok && len(v) < min {
+		fail("must have at least %d properties", min)
+	}
+	if max, ok := schemaInt(sch["maxProperties"]); ok && len(v) > max {
+		fail("must have at most %d properties", max)
+	}
+	if required, ok := sch["required"].([]interface{}); ok {
+		for _, r := range required {
+			if name, ok := r.(string); ok {
+				if _, present := v[name]; !present {
+					*errs = append(*errs, FieldError{Field: path + "/" + escapePointer(name), Message: "is required"})
+				}
+			}
+		}
+	}
+
+	props, _ := sch["properties"].(map[string]interface{})
+	patternProps, _ := sch["patternProperties"].(map[string]interface{})
+	additional, hasAdditional := sch["additionalProperties"]
+	for name, value := range v {
+		propPath := path + "/" + escapePointer(name)
+		matched := false
+		if sub, ok := props[name]; ok {
+			s.check(sub, value, propPath, depth, errs)
+			matched = true
+		}
+		for p, sub := range patternProps {
+			if s.patterns[p].MatchString(name) {
+				s.check(sub, value, propPath, depth, errs)
+				matched = true
+			}
+		}
+		if !matched && hasAdditional {
+			if allowed, ok := additional.(bool); ok && !allowed {
+				*errs = append(*errs, FieldError{Field: propPath, Message: "is not allowed"})
+			} else {
+				s.check(additional, value, propPath, depth, errs)
+			}
+		}
+	}
+}
+
+// resolve returns the subschema a local $ref such as "#/$defs/Item"
+// points to.
+func (s *jsonSchema) resolve(ref string) (interface{}, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("unsupported schema $ref %q", ref)
+	}
+	node := s.root
+	for _, token := range strings.Split(strings.TrimPrefix(ref, "#"), "/")[1:] {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		switch n := node.(type) {
+		case map[string]interface{}:
+			node = n[token]
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(n) {
+				return nil, fmt.Errorf("unresolvable schema $ref %q", ref)
+			}
+			node = n[i]
+		default:
+			node = nil
+		}
+		if node == nil {
+			return nil, fmt.Errorf("unresolvable schema $ref %q", ref)
+		}
+	}
+	return node, nil
+}
+
+// escapePointer escapes a property name for use in a JSON Pointer.
+func escapePointer(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
+
+// schemaTypes returns the types allowed by a type keyword.
+func schemaTypes(t interface{}) ([]string, bool) {
+	switch v := t.(type) {
+	case string:
+		return []string{v}, true
+	case []interface{}:
+		types := make([]string, 0, len(v))
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types, len(types) > 0
+	}
+	return nil, false
+}
+
+// matchesAnyType reports whether value has one of the JSON types.
+func matchesAnyType(value interface{}, types []string) bool {
+	for _, t := range types {
+		switch v := value.(type) {
+		case nil:
+			if t == "null" {
+				return true
+			}
+		case bool:
+			if t == "boolean" {
+				return true
+			}
+		case string:
+			if t == "string" {
+				return true
+			}
+		case []interface{}:
+			if t == "array" {
+				return true
+			}
+		case map[string]interface{}:
+			if t == "object" {
+				return true
+			}
+		case json.Number:
+			if t == "number" {
+				return true
+			}
+			if t == "integer" {
+				if f, ok := new(big.Float).SetString(v.String()); ok && f.IsInt() {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// schemaInt returns a non-negative integer keyword value.
+func schemaInt(v interface{}) (int, bool) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	f, err := n.Float64()
+	if err != nil || f < 0 || f > math.MaxInt32 {
+		return 0, false
+	}
+	return int(f), true
+}
+
+// equalJSON reports whether two decoded JSON values are equal, comparing
+// numbers by value.
+func equalJSON(a, b interface{}) bool {
+	switch x := a.(type) {
+	case json.Number:
+		y, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		fx, okx := new(big.Float).SetString(x.String())
+		fy, oky := new(big.Float).SetString(y.String())
+		return okx && oky && fx.Cmp(fy) == 0
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equalJSON(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, xv := range x {
+			yv, ok := y[k]
+			if !ok || !equalJSON(xv, yv) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+// containsJSON reports whether values contains value.
+func containsJSON(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if equalJSON(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatJSON formats a decoded JSON value for messages.
+func formatJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,106 @@
+package orchestration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrStateNotFound is returned by a StateStore when a run has no saved
+// state.
+var ErrStateNotFound = errors.New("state not found")
+
+// StateStore persists the state of workflow runs, keyed by run ID, so the
+// nodes of one run can execute in different processes. States are stored
+// as JSON.
+type StateStore interface {
+	// Load returns the state of a run, or ErrStateNotFound.
+	Load(ctx context.Context, runID string) (json.RawMessage, error)
+
+	// Save stores the state of a run, replacing its previous state.
+	Save(ctx context.Context, runID string, state json.RawMessage) error
+
+	// Delete removes the state of a run. Deleting a missing state is not
+	// an error.
+	Delete(ctx context.Context, runID string) error
+}
+
+// LoadState loads the state of a run from store and decodes it into an S.
+// It is a function because Go methods cannot have type parameters.
+func LoadState[S any](ctx context.Context, store StateStore, runID string) (S, error) {
+	var state S
+	data, err := store.Load(ctx, runID)
+	if err != nil {
+		return state, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("decoding state of run %s: %w", runID, err)
+	}
+	return state, nil
+}
+
+// SaveState encodes state as JSON and saves it to store.
+func SaveState[S any](ctx context.Context, store StateStore, runID string, state S) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("encoding state of run %s: %w", runID, err)
+	}
+	if err := store.Save(ctx, runID, data); err != nil {
+		return fmt.Errorf("saving state of run %s: %w", runID, err)
+	}
+	return nil
+}
+
+// stateRun is the run whose state Persistent nodes load and save.
+type stateRun struct {
+	store StateStore
+	runID string
+}
+
+type stateRunKey struct{}
+
+// WithStateStore returns a context under which Persistent nodes load the
+// state of run runID from store before they execute and save it after.
+//
+//	ctx = orchestration.WithStateStore(ctx, store, runID)
+//	result, err := executor.Execute(ctx, input)
+func WithStateStore(ctx context.Context, store StateStore, runID string) context.Context {
+	return context.WithValue(ctx, stateRunKey{}, &stateRun{store: store, runID: runID})
+}
+
+// Persistent wraps a node function so that, under WithStateStore, it runs
+// on the run's stored state and saves the state it returns. If the run has
+// no stored state yet, the node runs on its input. This lets the steps of
+// one run execute in different processes that share a store:
+//
+//	gb.AddLambdaNodeFunc("research", compose.InvokableLambda(
+//	    orchestration.Persistent("research", research)))
+//
+// The state must round-trip through JSON.
+func Persistent[S any](node string, fn func(ctx context.Context, state S) (S, error)) func(ctx context.Context, state S) (S, error) {
+	return func(ctx context.Context, state S) (S, error) {
+		run, ok := ctx.Value(stateRunKey{}).(*stateRun)
+		if !ok {
+			return fn(ctx, state)
+		}
+
+		stored, err := LoadState[S](ctx, run.store, run.runID)
+		switch {
+		case errors.Is(err, ErrStateNotFound):
+		case err != nil:
+			return state, fmt.Errorf("node %s: loading state of run %s: %w", node, run.runID, err)
+		default:
+			state = stored
+		}
+
+		out, err := fn(ctx, state)
+		if err != nil {
+			return out, err
+		}
+		if err := SaveState(ctx, run.store, run.runID, out); err != nil {
+			return out, fmt.Errorf("node %s: %w", node, err)
+		}
+		return out, nil
+	}
+}
@@ -0,0 +1,187 @@
+package orchestration
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/plexusone/agentkit/internal/awscli"
+	"github.com/plexusone/agentkit/internal/redis"
+)
+
+// MemoryStateStore keeps states in memory. It is meant for tests and
+// single-process development; states do not survive a restart.
+type MemoryStateStore struct {
+	mu     sync.RWMutex
+	states map[string]json.RawMessage
+}
+
+// NewMemoryStateStore creates an empty in-memory store.
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{states: make(map[string]json.RawMessage)}
+}
+
+// Load implements StateStore.
+func (s *MemoryStateStore) Load(ctx context.Context, runID string) (json.RawMessage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	state, ok := s.states[runID]
+	if !ok {
+		return nil, ErrStateNotFound
+	}
+	return append(json.RawMessage(nil), state...), nil
+}
+
+// Save implements StateStore.
+func (s *MemoryStateStore) Save(ctx context.Context, runID string, state json.RawMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[runID] = append(json.RawMessage(nil), state...)
+	return nil
+}
+
+// Delete implements StateStore.
+func (s *MemoryStateStore) Delete(ctx context.Context, runID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.states, runID)
+	return nil
+}
+
+// RedisStateStore keeps each run's state in a Redis string key named
+// {Prefix}{run ID}. Connections are pooled and reused across operations.
+type RedisStateStore struct {
+	// Addr is the host:port of the Redis server.
+	Addr string
+
+	// Password authenticates with the server when set.
+	// Default: ""
+	Password string
+
+	// DB is the database number to select.
+	// Default: 0
+	DB int
+
+	// TLS enables TLS with the given configuration when set.
+	// Default: nil (plain TCP)
+	TLS *tls.Config
+
+	// Prefix is prepended to run IDs to form keys.
+	// Default: "agentkit:state:"
+	Prefix string
+
+	// TTL expires states that are not saved again within it; zero keeps
+	// them until deleted.
+	// Default: 0
+	TTL time.Duration
+
+	pool redis.Pool
+}
+
+// NewRedisStateStore creates a store for the Redis server at addr.
+func NewRedisStateStore(addr string) *RedisStateStore {
+	return &RedisStateStore{Addr: addr, Prefix: "agentkit:state:"}
+}
+
+// key returns the Redis key of a run's state.
+func (s *RedisStateStore) key(runID string) string {
+	return s.Prefix + runID
+}
+
+// Load implements StateStore.
+func (s *RedisStateStore) Load(ctx context.Context, runID string) (json.RawMessage, error) {
+	reply, err := s.do(ctx, "GET", s.key(runID))
+	if err != nil {
+		return nil, err
+	}
+	if reply == nil {
+		return nil, ErrStateNotFound
+	}
+	return reply, nil
+}
+
+// Save implements StateStore.
+func (s *RedisStateStore) Save(ctx context.Context, runID string, state json.RawMessage) error {
+	args := []string{"SET", s.key(runID), string(state)}
+	if s.TTL > 0 {
+		args = append(args, "PX", strconv.FormatInt(s.TTL.Milliseconds(), 10))
+	}
+	_, err := s.do(ctx, args...)
+	return err
+}
+
+// Delete implements StateStore.
+func (s *RedisStateStore) Delete(ctx context.Context, runID string) error {
+	_, err := s.do(ctx, "DEL", s.key(runID))
+	return err
+}
+
+// do runs one command on the store's server. It returns the reply of the
+// command, or nil for a null reply.
+func (s *RedisStateStore) do(ctx context.Context, args ...string) ([]byte, error) {
+	replies, err := s.pool.Do(ctx, redis.Options{Addr: s.Addr, Password: s.Password, DB: s.DB, TLS: s.TLS}, args)
+	if err != nil {
+		return nil, err
+	}
+	reply, _ := replies[0].([]byte)
+	return reply, nil
+}
+
+// Close closes the idle connections of the store.
+func (s *RedisStateStore) Close() error {
+	return s.pool.Close()
+}
+
+// DynamoDBStateStore keeps states in a DynamoDB table whose partition key
+// is the string attribute "run_id". The state is stored as JSON in the
+// "state" attribute, so states are limited by the 400 KB item size; items
+// are handed to the CLI in a file, not as an argument. It uses the aws CLI,
+// which must be installed and have credentials for the table.
+type DynamoDBStateStore struct {
+	// Table is the DynamoDB table name.
+	Table string
+}
+
+// Load implements StateStore.
+func (s DynamoDBStateStore) Load(ctx context.Context, runID string) (json.RawMessage, error) {
+	out, err := awscli.Run(ctx, "dynamodb", "get-item", "--table-name", s.Table,
+		"--key", dynamoKey(runID), "--consistent-read")
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Item map[string]struct {
+			S string
+		}
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return nil, fmt.Errorf("parsing get-item output: %w", err)
+	}
+	attr, ok := result.Item["state"]
+	if !ok {
+		return nil, ErrStateNotFound
+	}
+	return json.RawMessage(attr.S), nil
+}
+
+// Save implements StateStore.
+func (s DynamoDBStateStore) Save(ctx context.Context, runID string, state json.RawMessage) error {
+	_, err := awscli.RunInput(ctx, map[string]any{
+		"TableName": s.Table,
+		"Item": map[string]map[string]string{
+			"run_id": {"S": runID},
+			"state":  {"S": string(state)},
+		},
+	}, "dynamodb", "put-item")
+	return err
+}
+
+// Delete implements StateStore.
+func (s DynamoDBStateStore) Delete(ctx context.Context, runID string) error {
+	_, err := awscli.Run(ctx, "dynamodb", "delete-item", "--table-name", s.Table, "--key", dynamoKey(runID))
+	return err
+}
@@ -0,0 +1,148 @@
+package orchestration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/cloudwego/eino/compose"
+)
+
+// Chunk is one piece of a streamed workflow result. The last chunk of a
+// failed stream carries Err.
+type Chunk[O any] struct {
+	Value O
+	Err   error
+}
+
+// Stream compiles and runs the graph in streaming mode. Nodes that stream
+// their output, such as chat models, produce many chunks; other nodes
+// produce one. The channel is closed when the stream ends, after a chunk
+// with Err if it failed; Err and compensations are as in Execute. Cancel ctx to
+// stop reading early.
+func (e *Executor[I, O]) Stream(ctx context.Context, input I) (<-chan Chunk[O], error) {
+	log.Printf("[%s] Starting streaming workflow execution", e.name)
+
+	compiled, err := e.graph.Compile(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile graph: %w", err)
+	}
+	return streamRunnable(ctx, e.name, compiled, input, e.opts...)
+}
+
+// streamRunnable runs a compiled graph in streaming mode; see
+// Executor.Stream.
+func streamRunnable[I, O any](ctx context.Context, name string, compiled compose.Runnable[I, O], input I, opts ...compose.Option) (<-chan Chunk[O], error) {
+	ctx, saga := startSaga(ctx)
+	failure := &nodeFailure{}
+	reader, err := compiled.Stream(ctx, input, runOptions(opts, failure)...)
+	if err != nil {
+		return nil, compensateRun(ctx, saga, failure.wrap(name, err))
+	}
+
+	chunks := make(chan Chunk[O])
+	go func() {
+		defer close(chunks)
+		defer reader.Close()
+		for {
+			value, err := reader.Recv()
+			if errors.Is(err, io.EOF) {
+				log.Printf("[%s] Streaming workflow completed successfully", name)
+				return
+			}
+			chunk := Chunk[O]{Value: value}
+			if err != nil {
+				chunk.Err = compensateRun(ctx, saga, failure.wrap(name, err))
+			}
+			select {
+			case chunks <- chunk:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return chunks, nil
+}
+
+// StreamHandler serves an executor over Server-Sent Events. Each chunk is
+// sent as a message event whose data is the chunk as JSON. The stream ends
+// with a "done" event, or an "error" event with {"error": "..."} if the
+// workflow fails.
+type StreamHandler[I, O any] struct {
+	stream func(ctx context.Context, input I) (<-chan Chunk[O], error)
+}
+
+// NewStreamHandler creates an SSE handler for a graph executor.
+func NewStreamHandler[I, O any](executor *Executor[I, O]) *StreamHandler[I, O] {
+	return &StreamHandler[I, O]{stream: executor.Stream}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *StreamHandler[I, O]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	var req I
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	chunks, err := h.stream(r.Context(), req)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Execution failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	for chunk := range chunks {
+		if chunk.Err != nil {
+			writeEvent(w, "error", map[string]string{"error": chunk.Err.Error()})
+			flusher.Flush()
+			return
+		}
+		writeEvent(w, "", chunk.Value)
+		flusher.Flush()
+	}
+	if r.Context().Err() == nil {
+		writeEvent(w, "done", struct{}{})
+		flusher.Flush()
+	}
+}
+
+// writeEvent writes one Server-Sent Event with data encoded as JSON. An
+// empty event name sends a default "message" event.
+func writeEvent(w io.Writer, event string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		event = "error"
+		payload, _ = json.Marshal(map[string]string{"error": fmt.Sprintf("encoding chunk: %v", err)})
+	}
+	if event != "" {
+		if _, err := fmt.Fprintf(w, "event: %s\n", event); err != nil {
+			return
+		}
+	}
+	if _, err := fmt.Fprintf(w, "data: %s\n\n", payload); err != nil {
+		log.Printf("Failed to write event: %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/cloudwego/eino/compose"
+)
+
+// Kinds of ValidationIssue.
+const (
+	// IssueCompile is a graph that does not compile, e.g. because an edge
+	// connects nodes with incompatible types or START or END has no
+	// edge.
+	IssueCompile = "compile"
+
+	// IssueUnreachable is a node that no path from START reaches.
+	IssueUnreachable = "unreachable"
+
+	// IssueNoPathToEnd is a node from which no path leads to END.
+	IssueNoPathToEnd = "no_path_to_end"
+)
+
+// ValidationIssue is one problem found by Validate.
+type ValidationIssue struct {
+	// Kind classifies the issue; see IssueCompile, IssueUnreachable and
+	// IssueNoPathToEnd.
+	Kind string `json:"kind"`
+
+	// Node is the affected node, as a path such as "review/score" for
+	// nodes of nested graphs. It is empty for compile issues.
+	Node string `json:"node,omitempty"`
+
+	// Message describes the issue.
+	Message string `json:"message"`
+}
+
+// ValidationReport is the result of validating a workflow graph.
+type ValidationReport struct {
+	// Workflow is the name of the executor.
+	Workflow string `json:"workflow"`
+
+	// Nodes lists the nodes of the graph, sorted, including those of
+	// nested graphs. It is empty if the graph does not compile.
+	Nodes []string `json:"nodes,omitempty"`
+
+	// Issues lists the problems found; it is empty for a valid graph.
+	Issues []ValidationIssue `json:"issues,omitempty"`
+}
+
+// Valid reports whether no issues were found.
+func (r *ValidationReport) Valid() bool {
+	return len(r.Issues) == 0
+}
+
+// Err returns the issues as one error, or nil if the graph is valid.
+func (r *ValidationReport) Err() error {
+	if r.Valid() {
+		return nil
+	}
+	errs := make([]error, 0, len(r.Issues))
+	for _, issue := range r.Issues {
+		errs = append(errs, errors.New(issue.Message))
+	}
+	return fmt.Errorf("workflow %s is invalid: %w", r.Workflow, errors.Join(errs...))
+}
+
+// graphInfoCapture keeps the GraphInfo of a compiled graph.
+type graphInfoCapture struct {
+	info *compose.GraphInfo
+}
+
+// OnFinish implements compose.GraphCompileCallback.
+func (c *graphInfoCapture) OnFinish(ctx context.Context, info *compose.GraphInfo) {
+	c.info = info
+}
+
+// Validate compiles the graph without executing it and reports the
+// problems it finds: edges between incompatible types and a missing START
+// or END edge, which fail compilation, and nodes that cannot be reached
+// from START or cannot reach END. Use it in CI to check workflow
+// definitions:
+//
+//	if err := executor.Validate(ctx).Err(); err != nil {
+//	    t.Fatal(err)
+//	}
+func (e *Executor[I, O]) Validate(ctx context.Context, opts ...compose.GraphCompileOption) *ValidationReport {
+	report := &ValidationReport{Workflow: e.name}
+
+	capture := &graphInfoCapture{}
+	opts = append(opts[:len(opts):len(opts)], compose.WithGraphCompileCallbacks(capture))
+	if _, err := e.graph.Compile(ctx, opts...); err != nil {
+		report.Issues = append(report.Issues, ValidationIssue{
+			Kind:    IssueCompile,
+			Message: fmt.Sprintf("failed to compile graph: %v", err),
+		})
+		return report
+	}
+	if capture.info != nil {
+		validateGraph(report, "", capture.info)
+	}
+	sort.Strings(report.Nodes)
+	return report
+}
+
+// validateGraph checks the reachability of the nodes of info and of its
+// nested graphs, naming nodes with prefix.
+func validateGraph(report *ValidationReport, prefix string, info *compose.GraphInfo) {
+	forward := make(map[string][]string)
+	backward := make(map[string][]string)
+	link := func(from, to string) {
+		forward[from] = append(forward[from], to)
+		backward[to] = append(backward[to], from)
+	}
+	for _, edges := range []map[string][]string{info.Edges, info.DataEdges} {
+		for from, tos := range edges {
+			for _, to := range tos {
+				link(from, to)
+			}
+		}
+	}
+	for from, branches := range info.Branches {
+		for _, branch := range branches {
+			for to := range branch.GetEndNode() {
+				link(from, to)
+			}
+		}
+	}
+
+	fromStart := reachable(compose.START, forward)
+	toEnd := reachable(compose.END, backward)
+
+	keys := make([]string, 0, len(info.Nodes))
+	for key := range info.Nodes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		node := prefix + key
+		report.Nodes = append(report.Nodes, node)
+		switch {
+		case !fromStart[key]:
+			report.Issues = append(report.Issues, ValidationIssue{
+				Kind:    IssueUnreachable,
+				Node:    node,
+				Message: fmt.Sprintf("node %s is not reachable from START", node),
+			})
+		case !toEnd[key]:
+			report.Issues = append(report.Issues, ValidationIssue{
+				Kind:    IssueNoPathToEnd,
+				Node:    node,
+				Message: fmt.Sprintf("node %s has no path to END", node),
+			})
+		}
+		if nested := info.Nodes[key].GraphInfo; nested != nil {
+			validateGraph(report, node+"/", nested)
+		}
+	}
+}
+
+// reachable returns the nodes reachable from start in edges, including
+// start.
+func reachable(start string, edges map[string][]string) map[string]bool {
+	seen := map[string]bool{start: true}
+	queue := []string{start}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, next := range edges[node] {
+			if !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return seen
+}
@@ -0,0 +1,45 @@
+package orchestration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+type workflowVersionKey struct{}
+
+// WithWorkflowVersion returns a context that records the version of the
+// workflow code being run. Checkpoints saved under it carry the version,
+// and Checkpointed refuses to resume a run that another version started,
+// whose state the running code may not understand. Registry sets it for
+// versioned workflows.
+func WithWorkflowVersion(ctx context.Context, version string) context.Context {
+	return context.WithValue(ctx, workflowVersionKey{}, version)
+}
+
+// WorkflowVersion returns the version recorded by WithWorkflowVersion, or
+// "" if there is none.
+func WorkflowVersion(ctx context.Context) string {
+	version, _ := ctx.Value(workflowVersionKey{}).(string)
+	return version
+}
+
+// RouteVersion returns the version that run runID must execute on: the
+// version recorded in its checkpoint if the run is in flight, or latest
+// for a new run or one checkpointed without a version.
+//
+//	version, err := orchestration.RouteVersion(ctx, store, runID, "v2")
+//	executor := executors[version]
+func RouteVersion(ctx context.Context, store CheckpointStore, runID, latest string) (string, error) {
+	cp, err := store.Load(ctx, runID)
+	switch {
+	case errors.Is(err, ErrCheckpointNotFound):
+		return latest, nil
+	case err != nil:
+		return "", fmt.Errorf("loading checkpoint of run %s: %w", runID, err)
+	case cp.Version == "":
+		return latest, nil
+	default:
+		return cp.Version, nil
+	}
+}
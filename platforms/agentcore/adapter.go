@@ -226,3 +226,19 @@ func (r *MultiAgentRouter) Invoke(ctx context.Context, req Request) (Response, e
 func (r *MultiAgentRouter) RegisterAgent(ctx context.Context, agent Agent) error {
 	return r.registry.Register(ctx, agent)
 }
+
+// ParallelAgent adapts an Agent for orchestration.ParallelAgents and
+// orchestration.CallAgents, so AgentCore agents can be called alongside
+// HTTP agents. A response with Error set counts as a failure.
+func ParallelAgent(agent Agent) orchestration.ParallelAgent[Request, Response] {
+	return orchestration.ParallelAgent[Request, Response]{
+		Name: agent.Name(),
+		Call: func(ctx context.Context, req Request) (Response, error) {
+			resp, err := agent.Invoke(ctx, req)
+			if err == nil && resp.Error != "" {
+				err = fmt.Errorf("agent %s: %s", agent.Name(), resp.Error)
+			}
+			return resp, err
+		},
+	}
+}
@@ -3,6 +3,9 @@ package agentcore
 import (
 	"context"
 	"encoding/json"
+	"strconv"
+
+	"github.com/plexusone/agentkit/llm/stream"
 )
 
 // Request represents an AgentCore invocation request.
@@ -40,6 +43,44 @@ type Response struct {
 	Error string `json:"error,omitempty"`
 }
 
+// Metadata keys with a conventional meaning, for per-request and
+// per-tenant cost attribution.
+const (
+	// MetadataPromptTokens, MetadataCompletionTokens and MetadataCostUSD
+	// are the Response metadata keys of the token usage and cost in USD of
+	// an invocation, see UsageMetadata. The server records the cost in
+	// audit records and traces.
+	MetadataPromptTokens     = "prompt_tokens"
+	MetadataCompletionTokens = "completion_tokens"
+	MetadataCostUSD          = "cost_usd"
+
+	// MetadataTenant is the Request metadata key of the tenant an
+	// invocation is attributed to in audit records and traces.
+	MetadataTenant = "tenant"
+
+	// MetadataCorrelationID is the Request and Response metadata key of
+	// the correlation ID shared by the agents serving a request, see
+	// agenthttp.CorrelationID. The server sets it on both, and records it
+	// in audit records and traces.
+	MetadataCorrelationID = "correlation_id"
+)
+
+// UsageMetadata returns Response metadata with the token usage and cost
+// of usage, or nil if usage is nil.
+//
+//	result, _ := embedded.Invoke(ctx, req.Prompt)
+//	return agentcore.Response{Output: result.Output, Metadata: agentcore.UsageMetadata(result.Usage)}, nil
+func UsageMetadata(usage *stream.Usage) map[string]string {
+	if usage == nil {
+		return nil
+	}
+	return map[string]string{
+		MetadataPromptTokens:     strconv.Itoa(usage.PromptTokens),
+		MetadataCompletionTokens: strconv.Itoa(usage.CompletionTokens),
+		MetadataCostUSD:          strconv.FormatFloat(usage.CostUSD, 'f', -1, 64),
+	}
+}
+
 // Agent is the interface that AgentCore-compatible agents must implement.
 // This interface is designed to be simple and runtime-agnostic.
 type Agent interface {
@@ -52,6 +93,18 @@ type Agent interface {
 	Invoke(ctx context.Context, req Request) (Response, error)
 }
 
+// StreamingAgent is an Agent that can stream its output. The server
+// streams it to clients that accept text/event-stream, and
+// a2a.AgentCoreExecutor streams it to A2A clients; other callers get the
+// whole output from Invoke.
+type StreamingAgent interface {
+	Agent
+
+	// InvokeStream processes a request like Invoke, streaming the output
+	// as described by stream.Chunk.
+	InvokeStream(ctx context.Context, req Request) <-chan stream.Chunk
+}
+
 // AgentFunc is a function type that implements the Agent interface.
 // Useful for simple agents that don't need state.
 type AgentFunc struct {
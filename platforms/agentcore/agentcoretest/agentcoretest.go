@@ -0,0 +1,82 @@
+// Package agentcoretest provides utilities for unit-testing AgentCore agents
+// in-process, without starting an HTTP server.
+//
+// Agents are invoked the same way the agentcore.Server invokes them: the
+// request is decoded into an agentcore.Request and the agent receives a
+// context carrying the session and the original request.
+//
+//	func TestEchoAgent(t *testing.T) {
+//	    resp := agentcoretest.InvokeAgent(t, echoAgent, agentcore.Request{Prompt: "hi"})
+//	    agentcoretest.AssertOutput(t, resp, "hi")
+//	}
+package agentcoretest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/plexusone/agentkit/platforms/agentcore"
+)
+
+// DefaultSessionID is the session ID assigned to requests that don't set one.
+const DefaultSessionID = "agentcoretest-session"
+
+// NewSessionID returns a random session ID in the same format AgentCore uses
+// for runtime sessions. Useful when a test needs distinct sessions.
+func NewSessionID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return "agentcoretest-" + hex.EncodeToString(b)
+}
+
+// NewSessionContext returns a context carrying a fake AgentCore session,
+// as the server would create it for an invocation.
+// If sessionID is empty, DefaultSessionID is used.
+func NewSessionContext(ctx context.Context, sessionID string, metadata map[string]string) context.Context {
+	if sessionID == "" {
+		sessionID = DefaultSessionID
+	}
+	req := &agentcore.Request{
+		SessionID: sessionID,
+		Metadata:  metadata,
+	}
+	return agentcore.NewSessionContext(ctx, sessionID, req)
+}
+
+// Invoke calls the agent in-process with the given request.
+// A session ID is assigned if the request doesn't have one, and the agent
+// field defaults to the agent's name, as it would after server routing.
+func Invoke(ctx context.Context, agent agentcore.Agent, req agentcore.Request) (agentcore.Response, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if req.SessionID == "" {
+		req.SessionID = DefaultSessionID
+	}
+	if req.Agent == "" {
+		req.Agent = agent.Name()
+	}
+
+	ctx = agentcore.NewSessionContext(ctx, req.SessionID, &req)
+	return agent.Invoke(ctx, req)
+}
+
+// InvokeAgent calls the agent in-process and fails the test if the
+// invocation returns an error.
+func InvokeAgent(t testing.TB, agent agentcore.Agent, req agentcore.Request) agentcore.Response {
+	t.Helper()
+
+	resp, err := Invoke(context.Background(), agent, req)
+	if err != nil {
+		t.Fatalf("agent %s: invocation failed: %v", agent.Name(), err)
+	}
+	return resp
+}
+
+// InvokePrompt is a shorthand for InvokeAgent with only a prompt.
+func InvokePrompt(t testing.TB, agent agentcore.Agent, prompt string) agentcore.Response {
+	t.Helper()
+	return InvokeAgent(t, agent, agentcore.Request{Prompt: prompt})
+}
@@ -0,0 +1,88 @@
+package agentcoretest
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/plexusone/agentkit/platforms/agentcore"
+)
+
+// UpdateGoldenEnv is the environment variable that, when set to "1" or
+// "true", makes AssertGolden rewrite golden files instead of comparing.
+const UpdateGoldenEnv = "AGENTCORETEST_UPDATE_GOLDEN"
+
+// AssertOutput fails the test if the response output differs from want.
+func AssertOutput(t testing.TB, resp agentcore.Response, want string) {
+	t.Helper()
+	if resp.Output != want {
+		t.Errorf("output mismatch:\n got: %q\nwant: %q", resp.Output, want)
+	}
+}
+
+// AssertOutputContains fails the test if the response output doesn't contain substr.
+func AssertOutputContains(t testing.TB, resp agentcore.Response, substr string) {
+	t.Helper()
+	if !strings.Contains(resp.Output, substr) {
+		t.Errorf("output %q does not contain %q", resp.Output, substr)
+	}
+}
+
+// AssertNoError fails the test if the response carries an error message.
+func AssertNoError(t testing.TB, resp agentcore.Response) {
+	t.Helper()
+	if resp.Error != "" {
+		t.Errorf("unexpected response error: %s", resp.Error)
+	}
+}
+
+// AssertResponse fails the test if the response differs from want.
+// Responses are compared by their JSON encoding, as a client would see them.
+func AssertResponse(t testing.TB, got, want agentcore.Response) {
+	t.Helper()
+
+	gotJSON := mustMarshal(t, got)
+	wantJSON := mustMarshal(t, want)
+	if !bytes.Equal(gotJSON, wantJSON) {
+		t.Errorf("response mismatch:\n got: %s\nwant: %s", gotJSON, wantJSON)
+	}
+}
+
+// AssertGolden compares the response against the JSON golden file at path.
+// If UpdateGoldenEnv is set, the golden file is (re)written instead.
+func AssertGolden(t testing.TB, resp agentcore.Response, path string) {
+	t.Helper()
+
+	got := mustMarshal(t, resp)
+
+	if update := os.Getenv(UpdateGoldenEnv); update == "1" || update == "true" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create golden directory: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o600); err != nil {
+			t.Fatalf("failed to write golden file %s: %v", path, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file %s (set %s=1 to create it): %v", path, UpdateGoldenEnv, err)
+	}
+	if !bytes.Equal(bytes.TrimSpace(got), bytes.TrimSpace(want)) {
+		t.Errorf("response does not match golden file %s:\n got: %s\nwant: %s", path, got, want)
+	}
+}
+
+func mustMarshal(t testing.TB, resp agentcore.Response) []byte {
+	t.Helper()
+
+	data, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		t.Fatalf("failed to marshal response: %v", err)
+	}
+	return append(data, '\n')
+}
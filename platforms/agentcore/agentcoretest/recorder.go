@@ -0,0 +1,126 @@
+package agentcoretest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/plexusone/agentkit/platforms/agentcore"
+)
+
+// Call is a single invocation captured by a Recorder.
+type Call struct {
+	// Request is the request the agent received.
+	Request agentcore.Request
+
+	// Session is the session found in the invocation context, if any.
+	Session *agentcore.Session
+
+	// Response is the response returned to the caller.
+	Response agentcore.Response
+
+	// Err is the error returned to the caller.
+	Err error
+}
+
+// Recorder is an Agent that captures every Request it receives.
+// It either delegates to a wrapped agent or returns a canned response.
+type Recorder struct {
+	name     string
+	next     agentcore.Agent
+	response agentcore.Response
+	err      error
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+// NewRecorder creates a Recorder that returns the given response for every call.
+func NewRecorder(name string, resp agentcore.Response) *Recorder {
+	return &Recorder{name: name, response: resp}
+}
+
+// NewErrorRecorder creates a Recorder that fails every call with err.
+func NewErrorRecorder(name string, err error) *Recorder {
+	return &Recorder{name: name, err: err, response: agentcore.Response{Error: err.Error()}}
+}
+
+// Wrap creates a Recorder that delegates to agent and records its calls.
+// The recorder uses the wrapped agent's name.
+func Wrap(agent agentcore.Agent) *Recorder {
+	return &Recorder{name: agent.Name(), next: agent}
+}
+
+// Name returns the agent name.
+func (r *Recorder) Name() string {
+	return r.name
+}
+
+// Invoke records the request and returns the canned or delegated response.
+func (r *Recorder) Invoke(ctx context.Context, req agentcore.Request) (agentcore.Response, error) {
+	var (
+		resp agentcore.Response
+		err  error
+	)
+	if r.next != nil {
+		resp, err = r.next.Invoke(ctx, req)
+	} else {
+		resp, err = r.response, r.err
+	}
+
+	r.mu.Lock()
+	r.calls = append(r.calls, Call{
+		Request:  req,
+		Session:  agentcore.SessionFromContext(ctx),
+		Response: resp,
+		Err:      err,
+	})
+	r.mu.Unlock()
+
+	return resp, err
+}
+
+// Calls returns a copy of all recorded calls in invocation order.
+func (r *Recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	calls := make([]Call, len(r.calls))
+	copy(calls, r.calls)
+	return calls
+}
+
+// Requests returns the recorded requests in invocation order.
+func (r *Recorder) Requests() []agentcore.Request {
+	calls := r.Calls()
+	reqs := make([]agentcore.Request, len(calls))
+	for i, c := range calls {
+		reqs[i] = c.Request
+	}
+	return reqs
+}
+
+// LastRequest returns the most recent request.
+// Returns false if no calls were recorded.
+func (r *Recorder) LastRequest() (agentcore.Request, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.calls) == 0 {
+		return agentcore.Request{}, false
+	}
+	return r.calls[len(r.calls)-1].Request, true
+}
+
+// Count returns the number of recorded calls.
+func (r *Recorder) Count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.calls)
+}
+
+// Reset clears all recorded calls.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = nil
+}
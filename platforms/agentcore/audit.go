@@ -0,0 +1,261 @@
+package agentcore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// AuditPromptPolicy controls how prompts are recorded in audit records.
+type AuditPromptPolicy string
+
+const (
+	// AuditPromptHash records a SHA-256 hash of the prompt. This is the default.
+	AuditPromptHash AuditPromptPolicy = "hash"
+
+	// AuditPromptFull records the full prompt text.
+	AuditPromptFull AuditPromptPolicy = "full"
+
+	// AuditPromptNone omits the prompt entirely.
+	AuditPromptNone AuditPromptPolicy = "none"
+)
+
+// AuditOutcome is the result of an audited invocation.
+type AuditOutcome string
+
+const (
+	// AuditOutcomeSuccess indicates the agent returned a response without error.
+	AuditOutcomeSuccess AuditOutcome = "success"
+
+	// AuditOutcomeAgentError indicates the agent returned an error.
+	AuditOutcomeAgentError AuditOutcome = "agent_error"
+
+	// AuditOutcomeBadRequest indicates the request could not be parsed.
+	AuditOutcomeBadRequest AuditOutcome = "bad_request"
+)
+
+// AuditRecord describes a single invocation for compliance logging.
+type AuditRecord struct {
+	Timestamp   time.Time     `json:"timestamp"`
+	Agent       string        `json:"agent"`
+	SessionID   string        `json:"session_id,omitempty"`
+	PromptHash  string        `json:"prompt_hash,omitempty"`
+	Prompt      string        `json:"prompt,omitempty"`
+	PromptBytes int           `json:"prompt_bytes"`
+	OutputBytes int           `json:"output_bytes"`
+	Latency     time.Duration `json:"latency_ns"`
+	Outcome     AuditOutcome  `json:"outcome"`
+	Error       string        `json:"error,omitempty"`
+
+	// Tenant is the MetadataTenant of the request.
+	Tenant string `json:"tenant,omitempty"`
+
+	// CorrelationID is the MetadataCorrelationID of the request.
+	CorrelationID string `json:"correlation_id,omitempty"`
+
+	// CostUSD is the MetadataCostUSD of the response, if the agent
+	// reports it.
+	CostUSD float64 `json:"cost_usd,omitempty"`
+}
+
+// AuditSink receives an AuditRecord for every invocation handled by the server.
+// Implementations must be safe for concurrent use.
+type AuditSink interface {
+	// Record writes the audit record. Errors are logged by the server
+	// but never fail the invocation.
+	Record(ctx context.Context, rec AuditRecord) error
+}
+
+// newAuditRecord builds an AuditRecord, applying the prompt policy.
+func newAuditRecord(policy AuditPromptPolicy, req Request, resp Response, latency time.Duration, outcome AuditOutcome, err error) AuditRecord {
+	rec := AuditRecord{
+		Timestamp:   time.Now().UTC(),
+		Agent:       req.Agent,
+		SessionID:   req.SessionID,
+		PromptBytes: len(req.Prompt),
+		OutputBytes: len(resp.Output),
+		Latency:     latency,
+		Outcome:     outcome,
+	}
+
+	switch policy {
+	case AuditPromptFull:
+		rec.Prompt = req.Prompt
+	case AuditPromptNone:
+	default:
+		sum := sha256.Sum256([]byte(req.Prompt))
+		rec.PromptHash = hex.EncodeToString(sum[:])
+	}
+
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	rec.Tenant = req.Metadata[MetadataTenant]
+	rec.CorrelationID = req.Metadata[MetadataCorrelationID]
+	rec.CostUSD = responseCost(resp)
+	return rec
+}
+
+// responseCost returns the MetadataCostUSD of resp, or 0.
+func responseCost(resp Response) float64 {
+	cost, _ := strconv.ParseFloat(resp.Metadata[MetadataCostUSD], 64)
+	return cost
+}
+
+// NopAuditSink discards all audit records.
+type NopAuditSink struct{}
+
+// Record discards the record.
+func (NopAuditSink) Record(context.Context, AuditRecord) error {
+	return nil
+}
+
+// WriterAuditSink writes audit records as JSON lines to an io.Writer.
+type WriterAuditSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterAuditSink creates a sink that writes JSON lines to w.
+func NewWriterAuditSink(w io.Writer) *WriterAuditSink {
+	return &WriterAuditSink{w: w}
+}
+
+// Record writes the record as a single JSON line.
+func (s *WriterAuditSink) Record(_ context.Context, rec AuditRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(data, '\n'))
+	return err
+}
+
+// FileAuditSink appends audit records as JSON lines to a file.
+type FileAuditSink struct {
+	*WriterAuditSink
+	file *os.File
+}
+
+// NewFileAuditSink opens (or creates) path for appending audit records.
+func NewFileAuditSink(path string) (*FileAuditSink, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return &FileAuditSink{
+		WriterAuditSink: NewWriterAuditSink(f),
+		file:            f,
+	}, nil
+}
+
+// Close closes the underlying file.
+func (s *FileAuditSink) Close() error {
+	return s.file.Close()
+}
+
+// EMFAuditSink writes audit records in CloudWatch Embedded Metric Format.
+// AgentCore ships container stdout to CloudWatch Logs, where EMF records are
+// extracted into metrics (InvocationLatency, OutputBytes, Errors, and
+// CostUSD when the agent reports it) while the full record stays
+// searchable in Logs Insights. Records with a Tenant are also aggregated
+// per agent and tenant, and CorrelationId joins them with other logs of
+// the same request.
+type EMFAuditSink struct {
+	namespace string
+	mu        sync.Mutex
+	w         io.Writer
+}
+
+// NewEMFAuditSink creates an EMF sink writing to stdout under namespace.
+// If namespace is empty, "AgentKit/AgentCore" is used.
+func NewEMFAuditSink(namespace string) *EMFAuditSink {
+	return NewEMFAuditSinkWithWriter(namespace, os.Stdout)
+}
+
+// NewEMFAuditSinkWithWriter creates an EMF sink writing to w.
+func NewEMFAuditSinkWithWriter(namespace string, w io.Writer) *EMFAuditSink {
+	if namespace == "" {
+		namespace = "AgentKit/AgentCore"
+	}
+	return &EMFAuditSink{namespace: namespace, w: w}
+}
+
+// Record writes the record as an EMF log line.
+func (s *EMFAuditSink) Record(_ context.Context, rec AuditRecord) error {
+	errCount := 0
+	if rec.Outcome != AuditOutcomeSuccess {
+		errCount = 1
+	}
+
+	dimensions := [][]string{{"Agent"}, {"Agent", "Outcome"}}
+	if rec.Tenant != "" {
+		dimensions = append(dimensions, []string{"Agent", "Tenant"})
+	}
+	metrics := []map[string]string{
+		{"Name": "InvocationLatency", "Unit": "Milliseconds"},
+		{"Name": "OutputBytes", "Unit": "Bytes"},
+		{"Name": "Errors", "Unit": "Count"},
+	}
+	if rec.CostUSD > 0 {
+		metrics = append(metrics, map[string]string{"Name": "CostUSD", "Unit": "None"})
+	}
+
+	doc := map[string]any{
+		"_aws": map[string]any{
+			"Timestamp": rec.Timestamp.UnixMilli(),
+			"CloudWatchMetrics": []map[string]any{
+				{
+					"Namespace":  s.namespace,
+					"Dimensions": dimensions,
+					"Metrics":    metrics,
+				},
+			},
+		},
+		"Agent":             rec.Agent,
+		"Outcome":           string(rec.Outcome),
+		"InvocationLatency": float64(rec.Latency) / float64(time.Millisecond),
+		"OutputBytes":       rec.OutputBytes,
+		"Errors":            errCount,
+		"SessionId":         rec.SessionID,
+		"PromptBytes":       rec.PromptBytes,
+	}
+	if rec.PromptHash != "" {
+		doc["PromptHash"] = rec.PromptHash
+	}
+	if rec.Prompt != "" {
+		doc["Prompt"] = rec.Prompt
+	}
+	if rec.Error != "" {
+		doc["Error"] = rec.Error
+	}
+	if rec.Tenant != "" {
+		doc["Tenant"] = rec.Tenant
+	}
+	if rec.CorrelationID != "" {
+		doc["CorrelationId"] = rec.CorrelationID
+	}
+	if rec.CostUSD > 0 {
+		doc["CostUSD"] = rec.CostUSD
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal EMF record: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(data, '\n'))
+	return err
+}
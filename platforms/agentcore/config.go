@@ -17,6 +17,8 @@ import (
 	"os"
 	"strconv"
 	"time"
+
+	"github.com/plexusone/agentkit/observability"
 )
 
 // Config holds configuration for an AgentCore runtime server.
@@ -47,6 +49,20 @@ type Config struct {
 	// EnableSessionTracking enables session ID tracking in logs.
 	// Default is true.
 	EnableSessionTracking bool
+
+	// AuditSink receives an audit record for every invocation.
+	// If nil, no audit records are written.
+	AuditSink AuditSink
+
+	// AuditPromptPolicy controls how prompts appear in audit records.
+	// Default is AuditPromptHash.
+	AuditPromptPolicy AuditPromptPolicy
+
+	// Tracer records a trace per invocation, in the session of the
+	// request. Spans the agent starts in the invocation's context, such
+	// as those of its LLM and tool calls, become part of it.
+	// If nil, invocations are not traced.
+	Tracer observability.Tracer
 }
 
 // DefaultConfig returns a Config with sensible defaults for AgentCore.
@@ -68,6 +84,7 @@ func DefaultConfig() Config {
 //   - AGENTCORE_READ_TIMEOUT_SECS: Read timeout in seconds
 //   - AGENTCORE_WRITE_TIMEOUT_SECS: Write timeout in seconds
 //   - AGENTCORE_ENABLE_REQUEST_LOGGING: Enable request logging (true/false)
+//   - AGENTCORE_AUDIT_PROMPT_POLICY: Prompt audit policy (hash, full, none)
 func LoadConfigFromEnv() Config {
 	cfg := DefaultConfig()
 
@@ -101,5 +118,9 @@ func LoadConfigFromEnv() Config {
 		cfg.EnableSessionTracking = tracking == "true" || tracking == "1"
 	}
 
+	if policy := os.Getenv("AGENTCORE_AUDIT_PROMPT_POLICY"); policy != "" {
+		cfg.AuditPromptPolicy = AuditPromptPolicy(policy)
+	}
+
 	return cfg
 }
@@ -0,0 +1,400 @@
+// Package emulator provides a local emulator for the AWS Bedrock AgentCore
+// Runtime.
+//
+// The emulator sits in front of an agent container (or an in-process
+// agentcore.Registry) and reproduces what AgentCore does around it:
+//   - assigns runtime session IDs when the caller doesn't supply one
+//   - forwards the session via the X-Amzn-Bedrock-AgentCore-Runtime-Session-Id header
+//   - logs every invocation with latency and status
+//   - serves a small web UI for sending invocations by hand
+//
+// This lets developers exercise a container built for AgentCore before
+// pushing it to ECR:
+//
+//	docker run -p 8080:8080 my-agent
+//	agentkit emulate --target http://localhost:8080 --port 9090
+package emulator
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grokify/mogo/log/sanitize"
+
+	agenthttp "github.com/plexusone/agentkit/http"
+	"github.com/plexusone/agentkit/platforms/agentcore"
+)
+
+// SessionHeader is the header AgentCore uses to pass the runtime session ID
+// to the agent container.
+const SessionHeader = "X-Amzn-Bedrock-AgentCore-Runtime-Session-Id"
+
+// minSessionIDLength is the minimum session ID length accepted by AgentCore.
+const minSessionIDLength = 33
+
+// Config holds configuration for the emulator.
+type Config struct {
+	// Port is the port the emulator listens on. Default is 9090.
+	Port int
+
+	// Target is the base URL of the agent container, e.g. "http://localhost:8080".
+	// Either Target or Registry is required.
+	Target string
+
+	// Registry serves invocations in-process instead of proxying to Target.
+	Registry *agentcore.Registry
+
+	// InvocationTimeout bounds a single invocation. Default is 300 seconds,
+	// matching the AgentCore server's default write timeout.
+	InvocationTimeout time.Duration
+
+	// HistorySize is the number of invocations kept for the UI. Default is 100.
+	HistorySize int
+
+	// EnableRequestLogging logs each invocation to the standard logger.
+	EnableRequestLogging bool
+
+	// HTTPClient is used to reach Target. If nil, a client with
+	// InvocationTimeout is used.
+	HTTPClient *http.Client
+}
+
+// Invocation is a single logged invocation.
+type Invocation struct {
+	ID         int               `json:"id"`
+	Time       time.Time         `json:"time"`
+	SessionID  string            `json:"session_id"`
+	Agent      string            `json:"agent,omitempty"`
+	Prompt     string            `json:"prompt"`
+	StatusCode int               `json:"status_code"`
+	Duration   time.Duration     `json:"duration_ns"`
+	Response   json.RawMessage   `json:"response,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+}
+
+// Emulator emulates the AgentCore Runtime locally.
+type Emulator struct {
+	config     Config
+	client     *http.Client
+	httpServer *http.Server
+
+	mu      sync.Mutex
+	nextID  int
+	history []Invocation
+}
+
+// New creates a new emulator.
+func New(cfg Config) (*Emulator, error) {
+	if cfg.Target == "" && cfg.Registry == nil {
+		return nil, fmt.Errorf("target or registry is required")
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 9090
+	}
+	if cfg.InvocationTimeout == 0 {
+		cfg.InvocationTimeout = 300 * time.Second
+	}
+	if cfg.HistorySize == 0 {
+		cfg.HistorySize = 100
+	}
+	cfg.Target = strings.TrimRight(cfg.Target, "/")
+
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: cfg.InvocationTimeout}
+	}
+
+	e := &Emulator{
+		config: cfg,
+		client: client,
+	}
+	// The server is built here rather than in Start so that Stop, called
+	// from another goroutine, never races with Start setting it.
+	e.httpServer = &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.Port),
+		Handler:           e.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		WriteTimeout:      cfg.InvocationTimeout + 10*time.Second,
+	}
+	return e, nil
+}
+
+// NewSessionID returns a new simulated runtime session ID.
+// The format satisfies AgentCore's minimum length requirement.
+func NewSessionID() string {
+	b := make([]byte, 18)
+	_, _ = rand.Read(b)
+	return "emulator-" + hex.EncodeToString(b)
+}
+
+// Handler returns the emulator's HTTP handler.
+func (e *Emulator) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", e.handleUI)
+	mux.HandleFunc("/ping", e.handlePing)
+	mux.HandleFunc("/invocations", e.handleInvocations)
+	mux.HandleFunc("/emulator/sessions", e.handleNewSession)
+	mux.HandleFunc("/emulator/history", e.handleHistory)
+	return mux
+}
+
+// Invoke sends a request through the emulator, assigning a session ID if needed.
+// It returns the raw response body and HTTP status from the agent.
+func (e *Emulator) Invoke(ctx context.Context, req agentcore.Request) ([]byte, int, error) {
+	if req.SessionID == "" {
+		req.SessionID = NewSessionID()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.config.InvocationTimeout)
+	defer cancel()
+
+	start := time.Now()
+	body, status, err := e.dispatch(ctx, req)
+	e.record(req, body, status, time.Since(start), err)
+
+	return body, status, err
+}
+
+func (e *Emulator) dispatch(ctx context.Context, req agentcore.Request) ([]byte, int, error) {
+	if e.config.Registry != nil {
+		sessCtx := agentcore.NewSessionContext(ctx, req.SessionID, &req)
+		resp, err := e.config.Registry.Invoke(sessCtx, req)
+		if err != nil {
+			return []byte(fmt.Sprintf("invocation failed: %v", err)), http.StatusInternalServerError, err
+		}
+		data, err := json.Marshal(resp)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		return data, http.StatusOK, nil
+	}
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, e.config.Target+"/invocations", bytes.NewReader(payload))
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set(SessionHeader, req.SessionID)
+	if id := agenthttp.CorrelationID(ctx); id != "" {
+		httpReq.Header.Set(agenthttp.CorrelationIDHeader, id)
+	}
+
+	resp, err := e.client.Do(httpReq)
+	if err != nil {
+		return nil, http.StatusBadGateway, fmt.Errorf("target unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, http.StatusBadGateway, fmt.Errorf("failed to read target response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return data, resp.StatusCode, fmt.Errorf("target returned %d", resp.StatusCode)
+	}
+	return data, resp.StatusCode, nil
+}
+
+func (e *Emulator) record(req agentcore.Request, body []byte, status int, d time.Duration, err error) {
+	inv := Invocation{
+		Time:       time.Now(),
+		SessionID:  req.SessionID,
+		Agent:      req.Agent,
+		Prompt:     req.Prompt,
+		StatusCode: status,
+		Duration:   d,
+		Metadata:   req.Metadata,
+	}
+	if json.Valid(body) {
+		inv.Response = body
+	}
+	if err != nil {
+		inv.Error = err.Error()
+	}
+
+	e.mu.Lock()
+	e.nextID++
+	inv.ID = e.nextID
+	e.history = append(e.history, inv)
+	if len(e.history) > e.config.HistorySize {
+		e.history = e.history[len(e.history)-e.config.HistorySize:]
+	}
+	e.mu.Unlock()
+
+	if e.config.EnableRequestLogging {
+		//nolint:gosec // G706: sanitize.String removes control chars (CWE-117 mitigation)
+		log.Printf("[Emulator] Invocation #%d: agent=%s session=%s prompt_len=%d status=%d duration=%s",
+			inv.ID, sanitize.String(req.Agent), sanitize.String(req.SessionID), len(req.Prompt), status, d.Round(time.Millisecond))
+		if err != nil {
+			log.Printf("[Emulator] Invocation #%d failed: %v", inv.ID, err)
+		}
+	}
+}
+
+// History returns the logged invocations, oldest first.
+func (e *Emulator) History() []Invocation {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	history := make([]Invocation, len(e.history))
+	copy(history, e.history)
+	return history
+}
+
+func (e *Emulator) handlePing(w http.ResponseWriter, r *http.Request) {
+	if e.config.Registry != nil {
+		for name, err := range e.config.Registry.HealthCheck(r.Context()) {
+			if err != nil {
+				http.Error(w, fmt.Sprintf("agent unhealthy: %s: %v", name, err), http.StatusServiceUnavailable)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	httpReq, err := http.NewRequestWithContext(r.Context(), http.MethodGet, e.config.Target+"/ping", nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	resp, err := e.client.Do(httpReq)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("target unreachable: %v", err), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+	w.WriteHeader(resp.StatusCode)
+}
+
+func (e *Emulator) handleInvocations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req agentcore.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	// AgentCore takes the session from the header; the body field is a fallback.
+	if sid := r.Header.Get(SessionHeader); sid != "" {
+		req.SessionID = sid
+	}
+	if req.SessionID != "" && len(req.SessionID) < minSessionIDLength {
+		http.Error(w, fmt.Sprintf("session id must be at least %d characters", minSessionIDLength), http.StatusBadRequest)
+		return
+	}
+	if req.SessionID == "" {
+		req.SessionID = NewSessionID()
+	}
+
+	ctx := r.Context()
+	if id := r.Header.Get(agenthttp.CorrelationIDHeader); agenthttp.ValidCorrelationID(id) {
+		ctx = agenthttp.WithCorrelationID(ctx, id)
+	}
+	body, status, err := e.Invoke(ctx, req)
+	if err != nil && body == nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set(SessionHeader, req.SessionID)
+	if json.Valid(body) {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.WriteHeader(status)
+	if _, err := w.Write(body); err != nil {
+		log.Printf("[Emulator] Failed to write response: %v", err)
+	}
+}
+
+func (e *Emulator) handleNewSession(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]string{"session_id": NewSessionID()}); err != nil {
+		log.Printf("[Emulator] Failed to encode session: %v", err)
+	}
+}
+
+func (e *Emulator) handleHistory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(e.History()); err != nil {
+		log.Printf("[Emulator] Failed to encode history: %v", err)
+	}
+}
+
+func (e *Emulator) handleUI(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if _, err := io.WriteString(w, uiHTML); err != nil {
+		log.Printf("[Emulator] Failed to write UI: %v", err)
+	}
+}
+
+// Start starts the emulator. This method blocks until the emulator stops.
+// If Stop has already been called, Start returns http.ErrServerClosed
+// without listening.
+func (e *Emulator) Start() error {
+	log.Printf("[Emulator] AgentCore emulator listening on %s", e.httpServer.Addr)
+	if e.config.Target != "" {
+		log.Printf("[Emulator] Forwarding invocations to %s", e.config.Target)
+	} else {
+		log.Printf("[Emulator] Serving in-process agents: %v", e.config.Registry.List())
+	}
+	log.Printf("[Emulator] Web UI: http://localhost:%d/", e.config.Port)
+
+	return e.httpServer.ListenAndServe()
+}
+
+// Stop gracefully shuts down the emulator. It may be called before Start.
+func (e *Emulator) Stop(ctx context.Context) error {
+	return e.httpServer.Shutdown(ctx)
+}
+
+// Run creates an emulator and serves it until ctx is canceled.
+func Run(ctx context.Context, cfg Config) error {
+	emu, err := New(cfg)
+	if err != nil {
+		return err
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- emu.Start()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		stopErr := emu.Stop(shutdownCtx)
+		if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) && stopErr == nil {
+			return err
+		}
+		return stopErr
+	}
+}
@@ -0,0 +1,105 @@
+package emulator
+
+// uiHTML is the emulator's single-page web UI.
+const uiHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>AgentCore Emulator</title>
+<style>
+  body { font-family: system-ui, sans-serif; margin: 2rem; max-width: 960px; }
+  label { display: block; margin-top: 0.75rem; font-weight: 600; }
+  input, textarea { width: 100%; box-sizing: border-box; font-family: monospace; }
+  textarea { height: 8rem; }
+  pre { background: #f4f4f4; padding: 0.75rem; overflow-x: auto; white-space: pre-wrap; }
+  table { border-collapse: collapse; width: 100%; margin-top: 1rem; font-size: 0.9rem; }
+  td, th { border-bottom: 1px solid #ddd; padding: 0.3rem; text-align: left; vertical-align: top; }
+  .err { color: #b00020; }
+</style>
+</head>
+<body>
+<h1>AgentCore Emulator</h1>
+
+<label for="session">Session ID</label>
+<input id="session">
+<button onclick="newSession()">New session</button>
+
+<label for="agent">Agent (optional)</label>
+<input id="agent">
+
+<label for="prompt">Prompt</label>
+<textarea id="prompt"></textarea>
+
+<label for="metadata">Metadata (JSON object, optional)</label>
+<input id="metadata" placeholder='{"key": "value"}'>
+
+<p><button onclick="invoke()">Invoke</button> <span id="status"></span></p>
+<pre id="output"></pre>
+
+<h2>History</h2>
+<table>
+  <thead><tr><th>#</th><th>Session</th><th>Agent</th><th>Status</th><th>Duration</th><th>Prompt</th></tr></thead>
+  <tbody id="history"></tbody>
+</table>
+
+<script>
+async function newSession() {
+  const r = await fetch('/emulator/sessions', {method: 'POST'});
+  document.getElementById('session').value = (await r.json()).session_id;
+}
+
+async function invoke() {
+  const body = {
+    prompt: document.getElementById('prompt').value,
+    agent: document.getElementById('agent').value || undefined,
+  };
+  const md = document.getElementById('metadata').value.trim();
+  if (md) {
+    try { body.metadata = JSON.parse(md); }
+    catch (e) { setStatus('invalid metadata: ' + e, true); return; }
+  }
+  const headers = {'Content-Type': 'application/json'};
+  const session = document.getElementById('session').value.trim();
+  if (session) headers['X-Amzn-Bedrock-AgentCore-Runtime-Session-Id'] = session;
+
+  setStatus('invoking...', false);
+  const start = performance.now();
+  const r = await fetch('/invocations', {method: 'POST', headers, body: JSON.stringify(body)});
+  const text = await r.text();
+  document.getElementById('session').value = r.headers.get('X-Amzn-Bedrock-AgentCore-Runtime-Session-Id') || session;
+  setStatus(r.status + ' in ' + Math.round(performance.now() - start) + ' ms', !r.ok);
+  try { document.getElementById('output').textContent = JSON.stringify(JSON.parse(text), null, 2); }
+  catch (e) { document.getElementById('output').textContent = text; }
+  loadHistory();
+}
+
+function setStatus(msg, isErr) {
+  const el = document.getElementById('status');
+  el.textContent = msg;
+  el.className = isErr ? 'err' : '';
+}
+
+async function loadHistory() {
+  const r = await fetch('/emulator/history');
+  const items = (await r.json()) || [];
+  const tbody = document.getElementById('history');
+  tbody.replaceChildren();
+  for (const inv of items.reverse()) {
+    const tr = document.createElement('tr');
+    for (const v of [inv.id, inv.session_id, inv.agent || '', inv.status_code,
+                     Math.round(inv.duration_ns / 1e6) + ' ms', inv.prompt]) {
+      const td = document.createElement('td');
+      td.textContent = v;
+      tr.appendChild(td);
+    }
+    if (inv.error) tr.className = 'err';
+    tbody.appendChild(tr);
+  }
+}
+
+newSession();
+loadHistory();
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,52 @@
+package agentcore
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/plexusone/agentkit/platforms/agentcore/iac"
+)
+
+// EnvDecrypter decrypts encrypted environment value references.
+// Implementations typically wrap the AWS KMS and SSM clients.
+type EnvDecrypter interface {
+	// DecryptKMS decrypts a KMS ciphertext blob.
+	DecryptKMS(ctx context.Context, ciphertext []byte) (string, error)
+
+	// GetSecureParameter returns the decrypted value of an SSM SecureString
+	// parameter, given its name or ARN.
+	GetSecureParameter(ctx context.Context, name string) (string, error)
+}
+
+// DecryptEnvironment replaces every "kms:" and "ssm:" environment variable
+// of the current process with its decrypted value. Call it at startup,
+// before reading configuration from the environment.
+func DecryptEnvironment(ctx context.Context, d EnvDecrypter) error {
+	for _, kv := range os.Environ() {
+		key, value, _ := strings.Cut(kv, "=")
+		ref, ok, err := iac.ParseEnvReference(value)
+		if !ok {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("environment variable %s: %w", key, err)
+		}
+
+		var plaintext string
+		switch ref.Prefix {
+		case iac.EnvRefKMS:
+			plaintext, err = d.DecryptKMS(ctx, ref.Ciphertext)
+		case iac.EnvRefSSM:
+			plaintext, err = d.GetSecureParameter(ctx, ref.Parameter)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to decrypt environment variable %s: %w", key, err)
+		}
+		if err := os.Setenv(key, plaintext); err != nil {
+			return fmt.Errorf("failed to set environment variable %s: %w", key, err)
+		}
+	}
+	return nil
+}
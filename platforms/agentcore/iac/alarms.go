@@ -0,0 +1,387 @@
+package iac
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AgentCoreMetricsNamespace is the CloudWatch namespace of AgentCore
+// runtime metrics.
+const AgentCoreMetricsNamespace = "AWS/Bedrock-AgentCore"
+
+// AlarmsConfig defines CloudWatch alarms and the budget alert for the stack.
+//
+// Alarms are created by default: an error-rate and a latency alarm for each
+// agent, and a dead-letter alarm for each queue. Alarm and budget
+// notifications are sent to SNSTopicARN, or to a topic created by the stack.
+type AlarmsConfig struct {
+	// Enabled creates the alarms.
+	// Default: true
+	Enabled *bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+
+	// SNSTopicARN is an existing topic to notify. If empty, a topic is
+	// created. An existing topic must allow budgets.amazonaws.com to
+	// publish when Budget is set.
+	SNSTopicARN string `json:"snsTopicArn,omitempty" yaml:"snsTopicArn,omitempty"`
+
+	// NotificationEmails are subscribed to the created topic and receive
+	// budget alerts.
+	NotificationEmails []string `json:"notificationEmails,omitempty" yaml:"notificationEmails,omitempty"`
+
+	// ErrorRatePercent is the system error rate that triggers an agent's
+	// error-rate alarm.
+	// Range: 0-100 (exclusive of 0)
+	// Default: 5
+	ErrorRatePercent float64 `json:"errorRatePercent,omitempty" yaml:"errorRatePercent,omitempty"`
+
+	// LatencyThresholdMs is the p99 latency that triggers an agent's
+	// latency alarm.
+	// Default: 80% of the agent's TimeoutSeconds
+	LatencyThresholdMs int `json:"latencyThresholdMs,omitempty" yaml:"latencyThresholdMs,omitempty"`
+
+	// PeriodSeconds is the alarm evaluation period.
+	// Must be a multiple of 60.
+	// Default: 300
+	PeriodSeconds int `json:"periodSeconds,omitempty" yaml:"periodSeconds,omitempty"`
+
+	// EvaluationPeriods is the number of breaching periods that trigger an alarm.
+	// Range: 1-100
+	// Default: 3
+	EvaluationPeriods int `json:"evaluationPeriods,omitempty" yaml:"evaluationPeriods,omitempty"`
+
+	// MetricsNamespace is the CloudWatch namespace of agent metrics.
+	// Default: "AWS/Bedrock-AgentCore"
+	MetricsNamespace string `json:"metricsNamespace,omitempty" yaml:"metricsNamespace,omitempty"`
+
+	// Budget configures a monthly cost budget with alerts.
+	// Optional.
+	Budget *BudgetConfig `json:"budget,omitempty" yaml:"budget,omitempty"`
+}
+
+// BudgetConfig defines a monthly AWS Budgets cost budget.
+type BudgetConfig struct {
+	// MonthlyLimitUSD is the monthly budget in US dollars.
+	// Required.
+	MonthlyLimitUSD float64 `json:"monthlyLimitUSD" yaml:"monthlyLimitUSD"`
+
+	// AlertThresholds are percentages of the limit at which actual spend
+	// triggers an alert. An alert is also sent when forecast spend
+	// exceeds the limit.
+	// Default: [80, 100]
+	AlertThresholds []float64 `json:"alertThresholds,omitempty" yaml:"alertThresholds,omitempty"`
+
+	// CostAllocationTags restricts the budget to costs with these tags.
+	// The tags must be activated as cost allocation tags in Billing.
+	// Default: all costs in the account.
+	CostAllocationTags map[string]string `json:"costAllocationTags,omitempty" yaml:"costAllocationTags,omitempty"`
+}
+
+// DefaultAlarmsConfig returns an AlarmsConfig with sensible defaults.
+func DefaultAlarmsConfig() *AlarmsConfig {
+	return &AlarmsConfig{
+		ErrorRatePercent:  5,
+		PeriodSeconds:     300,
+		EvaluationPeriods: 3,
+		MetricsNamespace:  AgentCoreMetricsNamespace,
+	}
+}
+
+// IsEnabled reports whether alarms are created.
+func (a *AlarmsConfig) IsEnabled() bool {
+	return a != nil && (a.Enabled == nil || *a.Enabled)
+}
+
+// CreatesTopic reports whether the stack creates the notification topic.
+func (a *AlarmsConfig) CreatesTopic() bool {
+	return a != nil && a.SNSTopicARN == "" && (a.IsEnabled() || a.Budget != nil)
+}
+
+// LatencyThreshold returns the latency alarm threshold for agent in milliseconds.
+func (a *AlarmsConfig) LatencyThreshold(agent AgentConfig) int {
+	if a.LatencyThresholdMs > 0 {
+		return a.LatencyThresholdMs
+	}
+	return agent.TimeoutSeconds * 800
+}
+
+// AlarmDimensions returns the CloudWatch metric dimensions for an agent.
+func AlarmDimensions(agent AgentConfig) map[string]string {
+	return map[string]string{"Name": agent.Name}
+}
+
+// BudgetCostFilters returns the Budgets tag cost filter values, in the
+// "user:Key$Value" form, sorted.
+func (b *BudgetConfig) BudgetCostFilters() []string {
+	var filters []string
+	for _, k := range sortedTagKeys(b.CostAllocationTags) {
+		filters = append(filters, fmt.Sprintf("user:%s$%s", k, b.CostAllocationTags[k]))
+	}
+	return filters
+}
+
+// applyAlarmsDefaults fills in Alarms defaults. Alarms are enabled when not configured.
+func (c *StackConfig) applyAlarmsDefaults() {
+	if c.Alarms == nil {
+		c.Alarms = DefaultAlarmsConfig()
+		return
+	}
+	a := c.Alarms
+	defaults := DefaultAlarmsConfig()
+	if a.ErrorRatePercent == 0 {
+		a.ErrorRatePercent = defaults.ErrorRatePercent
+	}
+	if a.PeriodSeconds == 0 {
+		a.PeriodSeconds = defaults.PeriodSeconds
+	}
+	if a.EvaluationPeriods == 0 {
+		a.EvaluationPeriods = defaults.EvaluationPeriods
+	}
+	if a.MetricsNamespace == "" {
+		a.MetricsNamespace = defaults.MetricsNamespace
+	}
+	if a.Budget != nil && len(a.Budget.AlertThresholds) == 0 {
+		a.Budget.AlertThresholds = []float64{80, 100}
+	}
+}
+
+// validateAlarms adds Alarms and Budget errors to the report.
+func (c *StackConfig) validateAlarms(report *ValidationReport) {
+	a := c.Alarms
+	if a == nil {
+		return
+	}
+
+	if a.SNSTopicARN != "" && !strings.HasPrefix(a.SNSTopicARN, "arn:aws:sns:") {
+		report.addError("alarms.snsTopicArn", "alarms.snsTopicArn must be an SNS topic ARN")
+	}
+	for i, email := range a.NotificationEmails {
+		if at := strings.Index(email, "@"); at < 1 || at == len(email)-1 {
+			report.addError(fmt.Sprintf("alarms.notificationEmails[%d]", i), "alarms.notificationEmails[%d] is not a valid email address: %s", i, email)
+		}
+	}
+	if a.ErrorRatePercent < 0 || a.ErrorRatePercent > 100 {
+		report.addError("alarms.errorRatePercent", "alarms.errorRatePercent must be between 0 and 100")
+	}
+	if a.LatencyThresholdMs < 0 {
+		report.addError("alarms.latencyThresholdMs", "alarms.latencyThresholdMs must not be negative")
+	}
+	if a.PeriodSeconds != 0 && (a.PeriodSeconds < 60 || a.PeriodSeconds%60 != 0) {
+		report.addError("alarms.periodSeconds", "alarms.periodSeconds must be a multiple of 60")
+	}
+	if a.EvaluationPeriods != 0 && (a.EvaluationPeriods < 1 || a.EvaluationPeriods > 100) {
+		report.addError("alarms.evaluationPeriods", "alarms.evaluationPeriods must be between 1 and 100")
+	}
+
+	if b := a.Budget; b != nil {
+		if b.MonthlyLimitUSD <= 0 {
+			report.addError("alarms.budget.monthlyLimitUSD", "alarms.budget.monthlyLimitUSD must be greater than 0")
+		}
+		for i, t := range b.AlertThresholds {
+			if t <= 0 || t > 1000 {
+				report.addError(fmt.Sprintf("alarms.budget.alertThresholds[%d]", i), "alarms.budget.alertThresholds[%d] must be a percentage between 0 and 1000", i)
+			}
+		}
+		validateTags(report, "alarms.budget.costAllocationTags", b.CostAllocationTags)
+	}
+}
+
+// validateAlarmsWarnings adds risky Alarms settings to the report.
+func (c *StackConfig) validateAlarmsWarnings(report *ValidationReport) {
+	if c.Alarms != nil && c.Alarms.Budget != nil && len(c.Alarms.Budget.CostAllocationTags) == 0 {
+		report.addWarning("alarms.budget.costAllocationTags", "alarms.budget.costAllocationTags is empty; the budget tracks all costs in the account")
+	}
+}
+
+// cfAlarmTopic returns the CloudFormation value of the notification topic ARN.
+func cfAlarmTopic(a *AlarmsConfig) interface{} {
+	if a.SNSTopicARN != "" {
+		return a.SNSTopicARN
+	}
+	return map[string]string{"Ref": "AlarmTopic"}
+}
+
+// addAlarmResources adds the notification topic, CloudWatch alarms and budget.
+func addAlarmResources(template *CloudFormationTemplate, config *StackConfig) {
+	a := config.Alarms
+	if a == nil || (!a.IsEnabled() && a.Budget == nil) {
+		return
+	}
+	topic := cfAlarmTopic(a)
+
+	if a.CreatesTopic() {
+		var subscriptions []map[string]interface{}
+		for _, email := range a.NotificationEmails {
+			subscriptions = append(subscriptions, map[string]interface{}{"Protocol": "email", "Endpoint": email})
+		}
+		props := map[string]interface{}{
+			"TopicName": config.StackName + "-alarms",
+			"Tags":      cfTags(config.StackName+"-alarms", nil),
+		}
+		if len(subscriptions) > 0 {
+			props["Subscription"] = subscriptions
+		}
+		template.Resources["AlarmTopic"] = CFResource{Type: "AWS::SNS::Topic", Properties: props}
+
+		if a.Budget != nil {
+			template.Resources["AlarmTopicPolicy"] = CFResource{
+				Type: "AWS::SNS::TopicPolicy",
+				Properties: map[string]interface{}{
+					"Topics": []interface{}{topic},
+					"PolicyDocument": map[string]interface{}{
+						"Version": "2012-10-17",
+						"Statement": []map[string]interface{}{
+							{
+								"Effect":    "Allow",
+								"Principal": map[string]string{"Service": "budgets.amazonaws.com"},
+								"Action":    "SNS:Publish",
+								"Resource":  topic,
+							},
+						},
+					},
+				},
+			}
+		}
+
+		template.Outputs["AlarmTopicARN"] = CFOutput{
+			Description: "SNS topic for alarm and budget notifications",
+			Value:       topic,
+		}
+	}
+
+	if a.IsEnabled() {
+		for _, agent := range config.Agents {
+			addAgentAlarms(template, config, agent, topic)
+		}
+		for _, q := range config.Queues {
+			if !q.DeadLetter.IsEnabled() {
+				continue
+			}
+			template.Resources[fmt.Sprintf("%sDeadLetterAlarm", toPascalCase(q.Name))] = CFResource{
+				Type: "AWS::CloudWatch::Alarm",
+				Properties: map[string]interface{}{
+					"AlarmName":          fmt.Sprintf("%s-%s-dead-letters", config.StackName, q.Name),
+					"AlarmDescription":   fmt.Sprintf("Messages in the %s dead-letter queue", q.Name),
+					"Namespace":          "AWS/SQS",
+					"MetricName":         "ApproximateNumberOfMessagesVisible",
+					"Dimensions":         []map[string]interface{}{{"Name": "QueueName", "Value": q.DeadLetterQueueName(config.StackName)}},
+					"Statistic":          "Maximum",
+					"Period":             a.PeriodSeconds,
+					"EvaluationPeriods":  1,
+					"Threshold":          0,
+					"ComparisonOperator": "GreaterThanThreshold",
+					"TreatMissingData":   "notBreaching",
+					"AlarmActions":       []interface{}{topic},
+				},
+			}
+		}
+	}
+
+	if b := a.Budget; b != nil {
+		subscribers := []map[string]interface{}{{"SubscriptionType": "SNS", "Address": topic}}
+		for _, email := range a.NotificationEmails {
+			subscribers = append(subscribers, map[string]interface{}{"SubscriptionType": "EMAIL", "Address": email})
+		}
+		notification := func(notificationType string, threshold float64) map[string]interface{} {
+			return map[string]interface{}{
+				"Notification": map[string]interface{}{
+					"NotificationType":   notificationType,
+					"ComparisonOperator": "GREATER_THAN",
+					"Threshold":          threshold,
+					"ThresholdType":      "PERCENTAGE",
+				},
+				"Subscribers": subscribers,
+			}
+		}
+		var notifications []map[string]interface{}
+		for _, t := range b.AlertThresholds {
+			notifications = append(notifications, notification("ACTUAL", t))
+		}
+		notifications = append(notifications, notification("FORECASTED", 100))
+
+		budget := map[string]interface{}{
+			"BudgetName":  config.StackName + "-monthly",
+			"BudgetType":  "COST",
+			"TimeUnit":    "MONTHLY",
+			"BudgetLimit": map[string]interface{}{"Amount": b.MonthlyLimitUSD, "Unit": "USD"},
+		}
+		if filters := b.BudgetCostFilters(); len(filters) > 0 {
+			budget["CostFilters"] = map[string]interface{}{"TagKeyValue": filters}
+		}
+		template.Resources["Budget"] = CFResource{
+			Type: "AWS::Budgets::Budget",
+			Properties: map[string]interface{}{
+				"Budget":                       budget,
+				"NotificationsWithSubscribers": notifications,
+			},
+		}
+	}
+}
+
+// addAgentAlarms adds the error-rate and latency alarms for an agent.
+func addAgentAlarms(template *CloudFormationTemplate, config *StackConfig, agent AgentConfig, topic interface{}) {
+	a := config.Alarms
+	prefix := toPascalCase(agent.Name)
+
+	var dimensions []map[string]interface{}
+	for _, k := range sortedTagKeys(AlarmDimensions(agent)) {
+		dimensions = append(dimensions, map[string]interface{}{"Name": k, "Value": AlarmDimensions(agent)[k]})
+	}
+	metric := func(id, name string) map[string]interface{} {
+		return map[string]interface{}{
+			"Id": id,
+			"MetricStat": map[string]interface{}{
+				"Metric": map[string]interface{}{
+					"Namespace":  a.MetricsNamespace,
+					"MetricName": name,
+					"Dimensions": dimensions,
+				},
+				"Period": a.PeriodSeconds,
+				"Stat":   "Sum",
+			},
+			"ReturnData": false,
+		}
+	}
+
+	template.Resources[prefix+"ErrorRateAlarm"] = CFResource{
+		Type: "AWS::CloudWatch::Alarm",
+		Properties: map[string]interface{}{
+			"AlarmName":        fmt.Sprintf("%s-%s-error-rate", config.StackName, agent.Name),
+			"AlarmDescription": fmt.Sprintf("%s agent system error rate above %g%%", agent.Name, a.ErrorRatePercent),
+			"Metrics": []map[string]interface{}{
+				metric("errors", "SystemErrors"),
+				metric("invocations", "Invocations"),
+				{
+					"Id":         "rate",
+					"Expression": "IF(invocations > 0, 100 * errors / invocations, 0)",
+					"Label":      "Error rate (%)",
+					"ReturnData": true,
+				},
+			},
+			"EvaluationPeriods":  a.EvaluationPeriods,
+			"Threshold":          a.ErrorRatePercent,
+			"ComparisonOperator": "GreaterThanThreshold",
+			"TreatMissingData":   "notBreaching",
+			"AlarmActions":       []interface{}{topic},
+			"OKActions":          []interface{}{topic},
+		},
+	}
+
+	template.Resources[prefix+"LatencyAlarm"] = CFResource{
+		Type: "AWS::CloudWatch::Alarm",
+		Properties: map[string]interface{}{
+			"AlarmName":          fmt.Sprintf("%s-%s-latency", config.StackName, agent.Name),
+			"AlarmDescription":   fmt.Sprintf("%s agent p99 latency above %dms", agent.Name, a.LatencyThreshold(agent)),
+			"Namespace":          a.MetricsNamespace,
+			"MetricName":         "Latency",
+			"Dimensions":         dimensions,
+			"ExtendedStatistic":  "p99",
+			"Period":             a.PeriodSeconds,
+			"EvaluationPeriods":  a.EvaluationPeriods,
+			"Threshold":          a.LatencyThreshold(agent),
+			"ComparisonOperator": "GreaterThanThreshold",
+			"TreatMissingData":   "notBreaching",
+			"AlarmActions":       []interface{}{topic},
+			"OKActions":          []interface{}{topic},
+		},
+	}
+}
@@ -0,0 +1,258 @@
+package iac
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Environment variables injected into agents with artifact bucket access.
+const (
+	EnvArtifactsBucket = "AGENTCORE_ARTIFACTS_BUCKET"
+	EnvArtifactsPrefix = "AGENTCORE_ARTIFACTS_PREFIX"
+)
+
+// bucketNamePattern is the S3 bucket name constraint.
+var bucketNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+
+// ArtifactsConfig defines an S3 bucket for reports and files produced by
+// agents. Each agent reads and writes under its own "{agent-name}/" prefix.
+// The bucket name and prefix are injected into the agent environment as
+// AGENTCORE_ARTIFACTS_BUCKET and AGENTCORE_ARTIFACTS_PREFIX.
+type ArtifactsConfig struct {
+	// BucketName is the S3 bucket name. Bucket names are global, so use a
+	// placeholder such as ${aws:accountId} to keep it unique.
+	// Default: "{stack-name}-artifacts"
+	BucketName string `json:"bucketName,omitempty" yaml:"bucketName,omitempty"`
+
+	// Create creates the bucket if true. If false, the bucket must exist.
+	// Default: true
+	Create *bool `json:"create,omitempty" yaml:"create,omitempty"`
+
+	// KMSKeyARN encrypts objects with a customer managed key.
+	// If empty, S3 managed encryption (AES256) is used.
+	KMSKeyARN string `json:"kmsKeyArn,omitempty" yaml:"kmsKeyArn,omitempty"`
+
+	// Versioning keeps previous versions of overwritten objects.
+	// Default: false
+	Versioning bool `json:"versioning,omitempty" yaml:"versioning,omitempty"`
+
+	// InfrequentAccessDays moves objects to S3 Standard-IA after N days.
+	// 0 disables the rule.
+	// Range: 30 or more
+	InfrequentAccessDays int `json:"infrequentAccessDays,omitempty" yaml:"infrequentAccessDays,omitempty"`
+
+	// ExpireDays deletes objects after N days. 0 keeps objects forever.
+	ExpireDays int `json:"expireDays,omitempty" yaml:"expireDays,omitempty"`
+
+	// NoncurrentExpireDays deletes previous object versions after N days.
+	// Used with Versioning. 0 keeps previous versions forever.
+	NoncurrentExpireDays int `json:"noncurrentExpireDays,omitempty" yaml:"noncurrentExpireDays,omitempty"`
+
+	// Agents are the names of the agents with access to the bucket.
+	// Default: all agents
+	Agents []string `json:"agents,omitempty" yaml:"agents,omitempty"`
+}
+
+// ShouldCreate reports whether the bucket should be created by the stack.
+func (a *ArtifactsConfig) ShouldCreate() bool {
+	return a.Create == nil || *a.Create
+}
+
+// HasLifecycleRules reports whether any lifecycle rule is configured.
+func (a *ArtifactsConfig) HasLifecycleRules() bool {
+	return a.InfrequentAccessDays > 0 || a.ExpireDays > 0 || (a.Versioning && a.NoncurrentExpireDays > 0)
+}
+
+// AgentPrefix returns the key prefix of an agent's artifacts.
+func AgentPrefix(agentName string) string {
+	return agentName + "/"
+}
+
+// ArtifactAgents returns the agents with access to the artifact bucket.
+func (c *StackConfig) ArtifactAgents() []AgentConfig {
+	if c.Artifacts == nil {
+		return nil
+	}
+	if len(c.Artifacts.Agents) == 0 {
+		return c.Agents
+	}
+	return c.agentsNamed(c.Artifacts.Agents)
+}
+
+// applyArtifactsDefaults fills in Artifacts defaults and injects the bucket
+// into agent environments. Variables already set by the agent are kept.
+func (c *StackConfig) applyArtifactsDefaults() {
+	a := c.Artifacts
+	if a == nil {
+		return
+	}
+	if a.BucketName == "" {
+		a.BucketName = strings.ToLower(c.StackName) + "-artifacts"
+	}
+
+	access := make(map[string]bool)
+	for _, agent := range c.ArtifactAgents() {
+		access[agent.Name] = true
+	}
+	for i := range c.Agents {
+		agent := &c.Agents[i]
+		if !access[agent.Name] {
+			continue
+		}
+		if _, ok := agent.Environment[EnvArtifactsBucket]; !ok {
+			agent.Environment[EnvArtifactsBucket] = a.BucketName
+		}
+		if _, ok := agent.Environment[EnvArtifactsPrefix]; !ok {
+			agent.Environment[EnvArtifactsPrefix] = AgentPrefix(agent.Name)
+		}
+	}
+}
+
+// validateArtifacts adds Artifacts errors to the report.
+func (c *StackConfig) validateArtifacts(report *ValidationReport, agentNames map[string]bool) {
+	a := c.Artifacts
+	if a == nil {
+		return
+	}
+
+	if a.BucketName != "" && (!bucketNamePattern.MatchString(a.BucketName) || strings.Contains(a.BucketName, "..")) {
+		report.addError("artifacts.bucketName", "artifacts.bucketName must be 3-63 lowercase letters, digits, '.' and '-': %s", a.BucketName)
+	}
+	for _, name := range a.Agents {
+		if !agentNames[name] {
+			report.addError("artifacts.agents", "artifacts.agents: '%s' does not match any agent name", name)
+		}
+	}
+	if a.KMSKeyARN != "" && !strings.HasPrefix(a.KMSKeyARN, "arn:aws:kms:") {
+		report.addError("artifacts.kmsKeyArn", "artifacts.kmsKeyArn must be a KMS key ARN")
+	}
+	if a.InfrequentAccessDays < 0 || (a.InfrequentAccessDays > 0 && a.InfrequentAccessDays < 30) {
+		report.addError("artifacts.infrequentAccessDays", "artifacts.infrequentAccessDays must be at least 30")
+	}
+	if a.ExpireDays < 0 {
+		report.addError("artifacts.expireDays", "artifacts.expireDays must not be negative")
+	} else if a.ExpireDays > 0 && a.InfrequentAccessDays > 0 && a.ExpireDays <= a.InfrequentAccessDays {
+		report.addError("artifacts.expireDays", "artifacts.expireDays must be greater than infrequentAccessDays")
+	}
+	if a.NoncurrentExpireDays < 0 {
+		report.addError("artifacts.noncurrentExpireDays", "artifacts.noncurrentExpireDays must not be negative")
+	} else if a.NoncurrentExpireDays > 0 && !a.Versioning {
+		report.addError("artifacts.noncurrentExpireDays", "artifacts.noncurrentExpireDays requires versioning")
+	}
+}
+
+// artifactsIAMStatements returns the execution role statements granting each
+// of agents with artifact access its prefix, or nil if none has access.
+func artifactsIAMStatements(config *StackConfig, agents []AgentConfig) []map[string]interface{} {
+	agents = slices.DeleteFunc(slices.Clone(agents), func(a AgentConfig) bool {
+		return !slices.ContainsFunc(config.ArtifactAgents(), func(b AgentConfig) bool { return a.Name == b.Name })
+	})
+	if len(agents) == 0 {
+		return nil
+	}
+	bucket := config.Artifacts.BucketName
+
+	objects := make([]string, 0, len(agents))
+	prefixes := make([]string, 0, len(agents))
+	for _, agent := range agents {
+		objects = append(objects, fmt.Sprintf("arn:aws:s3:::%s/%s*", bucket, AgentPrefix(agent.Name)))
+		prefixes = append(prefixes, AgentPrefix(agent.Name)+"*")
+	}
+
+	statements := []map[string]interface{}{
+		{
+			"Effect": "Allow",
+			"Action": []string{
+				"s3:GetObject",
+				"s3:PutObject",
+				"s3:DeleteObject",
+			},
+			"Resource": objects,
+		},
+		{
+			"Effect":   "Allow",
+			"Action":   []string{"s3:ListBucket"},
+			"Resource": fmt.Sprintf("arn:aws:s3:::%s", bucket),
+			"Condition": map[string]interface{}{
+				"StringLike": map[string]interface{}{"s3:prefix": prefixes},
+			},
+		},
+	}
+	if config.Artifacts.KMSKeyARN != "" {
+		statements = append(statements, map[string]interface{}{
+			"Effect": "Allow",
+			"Action": []string{
+				"kms:Decrypt",
+				"kms:GenerateDataKey",
+			},
+			"Resource": config.Artifacts.KMSKeyARN,
+		})
+	}
+	return statements
+}
+
+// addArtifactsResources adds the artifact bucket.
+func addArtifactsResources(template *CloudFormationTemplate, config *StackConfig) {
+	a := config.Artifacts
+	if a == nil || !a.ShouldCreate() {
+		return
+	}
+
+	encryption := map[string]interface{}{"SSEAlgorithm": "AES256"}
+	if a.KMSKeyARN != "" {
+		encryption = map[string]interface{}{"SSEAlgorithm": "aws:kms", "KMSMasterKeyID": a.KMSKeyARN}
+	}
+
+	props := map[string]interface{}{
+		"BucketName": a.BucketName,
+		"BucketEncryption": map[string]interface{}{
+			"ServerSideEncryptionConfiguration": []map[string]interface{}{
+				{"ServerSideEncryptionByDefault": encryption, "BucketKeyEnabled": a.KMSKeyARN != ""},
+			},
+		},
+		"PublicAccessBlockConfiguration": map[string]interface{}{
+			"BlockPublicAcls":       true,
+			"BlockPublicPolicy":     true,
+			"IgnorePublicAcls":      true,
+			"RestrictPublicBuckets": true,
+		},
+		"OwnershipControls": map[string]interface{}{
+			"Rules": []map[string]string{{"ObjectOwnership": "BucketOwnerEnforced"}},
+		},
+		"Tags": cfTags(a.BucketName, nil),
+	}
+	if a.Versioning {
+		props["VersioningConfiguration"] = map[string]string{"Status": "Enabled"}
+	}
+	if a.HasLifecycleRules() {
+		rule := map[string]interface{}{"Id": "artifacts", "Status": "Enabled"}
+		if a.InfrequentAccessDays > 0 {
+			rule["Transitions"] = []map[string]interface{}{
+				{"StorageClass": "STANDARD_IA", "TransitionInDays": a.InfrequentAccessDays},
+			}
+		}
+		if a.ExpireDays > 0 {
+			rule["ExpirationInDays"] = a.ExpireDays
+		}
+		if a.Versioning && a.NoncurrentExpireDays > 0 {
+			rule["NoncurrentVersionExpiration"] = map[string]interface{}{"NoncurrentDays": a.NoncurrentExpireDays}
+		}
+		props["LifecycleConfiguration"] = map[string]interface{}{"Rules": []map[string]interface{}{rule}}
+	}
+
+	deletionPolicy := "Delete"
+	if config.RemovalPolicy == "retain" {
+		deletionPolicy = "Retain"
+	}
+	template.Resources["ArtifactsBucket"] = CFResource{
+		Type:           "AWS::S3::Bucket",
+		DeletionPolicy: deletionPolicy,
+		Properties:     props,
+	}
+	template.Outputs["ArtifactsBucketName"] = CFOutput{
+		Description: "S3 bucket for agent artifacts",
+		Value:       map[string]string{"Ref": "ArtifactsBucket"},
+	}
+}
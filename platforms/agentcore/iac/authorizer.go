@@ -0,0 +1,348 @@
+package iac
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// JWTAuthorizerConfig defines JWT bearer token validation for an agent.
+//
+// AgentCore validates tokens against an OIDC discovery document. The
+// discovery URL is taken from DiscoveryURL, derived from Issuer, or derived
+// from CognitoUserPoolID, in that order.
+type JWTAuthorizerConfig struct {
+	// DiscoveryURL is the OIDC discovery document URL.
+	// Example: "https://auth.example.com/.well-known/openid-configuration"
+	DiscoveryURL string `json:"discoveryUrl,omitempty" yaml:"discoveryUrl,omitempty"`
+
+	// Issuer is the token issuer. If DiscoveryURL is empty, the discovery URL
+	// is "{issuer}/.well-known/openid-configuration".
+	Issuer string `json:"issuer,omitempty" yaml:"issuer,omitempty"`
+
+	// JWKSURL is the JSON Web Key Set URL. Optional - normally read from
+	// the discovery document.
+	JWKSURL string `json:"jwksUrl,omitempty" yaml:"jwksUrl,omitempty"`
+
+	// AllowedAudiences are accepted "aud" claim values.
+	AllowedAudiences []string `json:"allowedAudiences,omitempty" yaml:"allowedAudiences,omitempty"`
+
+	// AllowedClients are accepted "client_id" claim values.
+	// Cognito access tokens carry client_id instead of aud.
+	AllowedClients []string `json:"allowedClients,omitempty" yaml:"allowedClients,omitempty"`
+
+	// CognitoUserPoolID is a Cognito user pool ID, e.g. "us-east-1_AbCdEf123".
+	// Shortcut for setting Issuer to the user pool's issuer URL.
+	CognitoUserPoolID string `json:"cognitoUserPoolId,omitempty" yaml:"cognitoUserPoolId,omitempty"`
+
+	// CognitoRegion is the user pool region.
+	// Default: the region prefix of CognitoUserPoolID
+	CognitoRegion string `json:"cognitoRegion,omitempty" yaml:"cognitoRegion,omitempty"`
+}
+
+// IssuerURL returns the token issuer, deriving it from the Cognito user
+// pool if Issuer is not set.
+func (j *JWTAuthorizerConfig) IssuerURL() string {
+	if j.Issuer != "" {
+		return strings.TrimSuffix(j.Issuer, "/")
+	}
+	if j.CognitoUserPoolID != "" {
+		return fmt.Sprintf("https://cognito-idp.%s.amazonaws.com/%s", j.cognitoRegion(), j.CognitoUserPoolID)
+	}
+	return ""
+}
+
+// ResolvedDiscoveryURL returns the OIDC discovery URL used by AgentCore.
+func (j *JWTAuthorizerConfig) ResolvedDiscoveryURL() string {
+	if j.DiscoveryURL != "" {
+		return j.DiscoveryURL
+	}
+	if issuer := j.IssuerURL(); issuer != "" {
+		return issuer + "/.well-known/openid-configuration"
+	}
+	return ""
+}
+
+// cognitoRegion returns CognitoRegion or the region prefix of the pool ID.
+func (j *JWTAuthorizerConfig) cognitoRegion() string {
+	if j.CognitoRegion != "" {
+		return j.CognitoRegion
+	}
+	region, _, _ := strings.Cut(j.CognitoUserPoolID, "_")
+	return region
+}
+
+// InboundConfiguration returns the AgentCore Runtime authorizer
+// configuration for the agent, or nil if AgentCore handles no token
+// validation (IAM, LAMBDA or NONE).
+func (a *AuthorizerConfig) InboundConfiguration() map[string]interface{} {
+	if a == nil || a.Type != "JWT" || a.JWT == nil {
+		return nil
+	}
+
+	jwt := map[string]interface{}{
+		"discoveryUrl": a.JWT.ResolvedDiscoveryURL(),
+	}
+	if len(a.JWT.AllowedAudiences) > 0 {
+		jwt["allowedAudience"] = a.JWT.AllowedAudiences
+	}
+	if len(a.JWT.AllowedClients) > 0 {
+		jwt["allowedClients"] = a.JWT.AllowedClients
+	}
+	return map[string]interface{}{"customJWTAuthorizer": jwt}
+}
+
+// InboundConfigurationJSON returns InboundConfiguration as compact JSON text,
+// or an empty string if there is none.
+func (a *AuthorizerConfig) InboundConfigurationJSON() string {
+	cfg := a.InboundConfiguration()
+	if cfg == nil {
+		return ""
+	}
+	// The configuration contains only strings and string slices.
+	data, _ := json.Marshal(cfg)
+	return string(data)
+}
+
+// validateJWTAuthorizer adds JWT authorizer errors for the agent at index i.
+func validateJWTAuthorizer(report *ValidationReport, i int, agent AgentConfig) {
+	a := agent.Authorizer
+	path := fmt.Sprintf("agents[%d].authorizer.jwt", i)
+
+	if a.Type != "JWT" {
+		if a.JWT != nil {
+			report.addError(path, "agents[%d] (%s): authorizer.jwt is only valid when type is JWT", i, agent.Name)
+		}
+		return
+	}
+	if a.JWT == nil {
+		report.addError(path, "agents[%d] (%s): authorizer.jwt is required when type is JWT", i, agent.Name)
+		return
+	}
+
+	j := a.JWT
+	if j.DiscoveryURL == "" && j.Issuer == "" && j.CognitoUserPoolID == "" {
+		report.addError(path, "agents[%d] (%s): authorizer.jwt requires discoveryUrl, issuer or cognitoUserPoolId", i, agent.Name)
+	}
+	if j.CognitoUserPoolID != "" && !strings.Contains(j.CognitoUserPoolID, "_") {
+		report.addError(path+".cognitoUserPoolId", "agents[%d] (%s): authorizer.jwt.cognitoUserPoolId must look like <region>_<id>", i, agent.Name)
+	}
+	for _, f := range [][2]string{{"discoveryUrl", j.DiscoveryURL}, {"issuer", j.Issuer}, {"jwksUrl", j.JWKSURL}} {
+		if f[1] != "" && !isHTTPSURL(f[1]) {
+			report.addError(path+"."+f[0], "agents[%d] (%s): authorizer.jwt.%s must be an https URL", i, agent.Name, f[0])
+		}
+	}
+	if len(j.AllowedAudiences) == 0 && len(j.AllowedClients) == 0 {
+		report.addError(path, "agents[%d] (%s): authorizer.jwt requires allowedAudiences or allowedClients", i, agent.Name)
+	}
+}
+
+// isHTTPSURL reports whether s is an absolute https URL.
+func isHTTPSURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme == "https" && u.Host != ""
+}
+
+// authorizerFunctionNamePattern restricts function names so that the
+// "{name}-role" execution role name fits IAM's 64 character limit.
+var authorizerFunctionNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,59}$`)
+
+// AuthorizerFunctionConfig defines a Lambda authorizer function deployed by
+// the stack, with an execution role that can write its logs. Exactly one of
+// CodePath and ImageURI is required.
+type AuthorizerFunctionConfig struct {
+	// Name is the function name.
+	// Default: "{stack-name}-{agent-name}-authorizer"
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// CodePath is the function code: a .zip archive or a directory.
+	// CloudFormation templates must be packaged with
+	// "aws cloudformation package" to upload it.
+	CodePath string `json:"codePath,omitempty" yaml:"codePath,omitempty"`
+
+	// ImageURI is a container image for the function.
+	ImageURI string `json:"imageUri,omitempty" yaml:"imageUri,omitempty"`
+
+	// Runtime is the Lambda runtime. Ignored for ImageURI.
+	// Default: "provided.al2023"
+	Runtime string `json:"runtime,omitempty" yaml:"runtime,omitempty"`
+
+	// Handler is the function entry point. Ignored for ImageURI.
+	// Default: "bootstrap"
+	Handler string `json:"handler,omitempty" yaml:"handler,omitempty"`
+
+	// Architecture is the instruction set architecture.
+	// Supported: "x86_64", "arm64"
+	// Default: "arm64"
+	Architecture string `json:"architecture,omitempty" yaml:"architecture,omitempty"`
+
+	// MemoryMB is the function memory.
+	// Range: 128-10240
+	// Default: 128
+	MemoryMB int `json:"memoryMB,omitempty" yaml:"memoryMB,omitempty"`
+
+	// TimeoutSeconds is the function timeout.
+	// Range: 1-900
+	// Default: 10
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty" yaml:"timeoutSeconds,omitempty"`
+
+	// Environment are environment variables for the function.
+	Environment map[string]string `json:"environment,omitempty" yaml:"environment,omitempty"`
+}
+
+// ValidLambdaArchitectures returns the list of valid Lambda architectures.
+func ValidLambdaArchitectures() []string {
+	return []string{"x86_64", "arm64"}
+}
+
+// RoleName returns the name of the function's execution role.
+func (f *AuthorizerFunctionConfig) RoleName() string {
+	return f.Name + "-role"
+}
+
+// IsZip reports whether CodePath is a .zip archive rather than a directory.
+func (f *AuthorizerFunctionConfig) IsZip() bool {
+	return strings.HasSuffix(strings.ToLower(f.CodePath), ".zip")
+}
+
+// PolicyStatements returns the execution role statements for the function,
+// which allow it to write to its own log group.
+func (f *AuthorizerFunctionConfig) PolicyStatements() []map[string]interface{} {
+	return []map[string]interface{}{
+		{
+			"Effect":   "Allow",
+			"Action":   []string{"logs:CreateLogGroup", "logs:CreateLogStream", "logs:PutLogEvents"},
+			"Resource": fmt.Sprintf("arn:aws:logs:*:*:log-group:/aws/lambda/%s:*", f.Name),
+		},
+	}
+}
+
+// AuthorizerFunctionAgents returns the agents whose Lambda authorizer is
+// deployed by the stack.
+func (c *StackConfig) AuthorizerFunctionAgents() []AgentConfig {
+	var agents []AgentConfig
+	for _, agent := range c.Agents {
+		if agent.Authorizer != nil && agent.Authorizer.Type == "LAMBDA" && agent.Authorizer.Function != nil {
+			agents = append(agents, agent)
+		}
+	}
+	return agents
+}
+
+// applyAuthorizerDefaults fills in authorizer Function defaults for an agent.
+func applyAuthorizerDefaults(stackName string, agent *AgentConfig) {
+	if agent.Authorizer == nil || agent.Authorizer.Function == nil {
+		return
+	}
+	f := agent.Authorizer.Function
+	if f.Name == "" {
+		f.Name = fmt.Sprintf("%s-%s-authorizer", stackName, agent.Name)
+	}
+	if f.ImageURI == "" {
+		if f.Runtime == "" {
+			f.Runtime = "provided.al2023"
+		}
+		if f.Handler == "" {
+			f.Handler = "bootstrap"
+		}
+	}
+	if f.Architecture == "" {
+		f.Architecture = "arm64"
+	}
+	if f.MemoryMB == 0 {
+		f.MemoryMB = 128
+	}
+	if f.TimeoutSeconds == 0 {
+		f.TimeoutSeconds = 10
+	}
+}
+
+// validateLambdaAuthorizer adds Lambda authorizer errors for the agent at index i.
+func validateLambdaAuthorizer(report *ValidationReport, i int, agent AgentConfig) {
+	a := agent.Authorizer
+	path := fmt.Sprintf("agents[%d].authorizer", i)
+
+	if a.Type != "LAMBDA" {
+		if a.Function != nil {
+			report.addError(path+".function", "agents[%d] (%s): authorizer.function is only valid when type is LAMBDA", i, agent.Name)
+		}
+		return
+	}
+	switch {
+	case a.LambdaARN == "" && a.Function == nil:
+		report.addError(path+".lambdaArn", "agents[%d] (%s): authorizer.lambdaArn or authorizer.function is required when type is LAMBDA", i, agent.Name)
+	case a.LambdaARN != "" && a.Function != nil:
+		report.addError(path+".function", "agents[%d] (%s): authorizer.lambdaArn and authorizer.function are mutually exclusive", i, agent.Name)
+	}
+
+	f := a.Function
+	if f == nil {
+		return
+	}
+	path += ".function"
+	if f.Name != "" && !authorizerFunctionNamePattern.MatchString(f.Name) {
+		report.addError(path+".name", "agents[%d] (%s): authorizer.function.name must be 1-59 letters, digits, '-' and '_': %s", i, agent.Name, f.Name)
+	}
+	if (f.CodePath == "") == (f.ImageURI == "") {
+		report.addError(path, "agents[%d] (%s): authorizer.function requires exactly one of codePath and imageUri", i, agent.Name)
+	}
+	if f.Architecture != "" && !slices.Contains(ValidLambdaArchitectures(), f.Architecture) {
+		report.addError(path+".architecture", "agents[%d] (%s): authorizer.function.architecture must be one of %v", i, agent.Name, ValidLambdaArchitectures())
+	}
+	if f.MemoryMB != 0 && (f.MemoryMB < 128 || f.MemoryMB > 10240) {
+		report.addError(path+".memoryMB", "agents[%d] (%s): authorizer.function.memoryMB must be between 128 and 10240", i, agent.Name)
+	}
+	if f.TimeoutSeconds != 0 && (f.TimeoutSeconds < 1 || f.TimeoutSeconds > 900) {
+		report.addError(path+".timeoutSeconds", "agents[%d] (%s): authorizer.function.timeoutSeconds must be between 1 and 900", i, agent.Name)
+	}
+	for key := range f.Environment {
+		if key == "" {
+			report.addError(path+".environment", "agents[%d] (%s): authorizer.function.environment keys must not be empty", i, agent.Name)
+		}
+	}
+}
+
+// addAuthorizerFunctionResources adds the Lambda authorizer functions
+// deployed by the stack and their execution roles.
+func addAuthorizerFunctionResources(template *CloudFormationTemplate, config *StackConfig) {
+	for _, agent := range config.AuthorizerFunctionAgents() {
+		f := agent.Authorizer.Function
+		logicalID := toPascalCase(agent.Name) + "Authorizer"
+
+		template.Resources[logicalID+"Role"] = cfServiceRole(f.RoleName(), "lambda.amazonaws.com", "Logs", f.PolicyStatements())
+
+		props := map[string]interface{}{
+			"FunctionName":  f.Name,
+			"Description":   fmt.Sprintf("Authorizer for %s agent", agent.Name),
+			"Role":          map[string]interface{}{"Fn::GetAtt": []string{logicalID + "Role", "Arn"}},
+			"Architectures": []string{f.Architecture},
+			"MemorySize":    f.MemoryMB,
+			"Timeout":       f.TimeoutSeconds,
+			"Tags":          cfTags(f.Name, agent.Tags),
+		}
+		if f.ImageURI != "" {
+			props["PackageType"] = "Image"
+			props["Code"] = map[string]interface{}{"ImageUri": f.ImageURI}
+		} else {
+			// A local path, replaced with its S3 location by
+			// "aws cloudformation package".
+			props["Code"] = f.CodePath
+			props["Runtime"] = f.Runtime
+			props["Handler"] = f.Handler
+		}
+		if len(f.Environment) > 0 {
+			props["Environment"] = map[string]interface{}{"Variables": f.Environment}
+		}
+		template.Resources[logicalID] = CFResource{
+			Type:       "AWS::Lambda::Function",
+			Properties: props,
+		}
+		template.Outputs[logicalID+"ARN"] = CFOutput{
+			Description: fmt.Sprintf("Lambda authorizer ARN for %s agent", agent.Name),
+			Value:       map[string]interface{}{"Fn::GetAtt": []string{logicalID, "Arn"}},
+		}
+	}
+}
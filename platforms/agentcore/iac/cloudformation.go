@@ -91,12 +91,46 @@ func GenerateCloudFormation(config *StackConfig) ([]byte, error) {
 	if config.Observability.EnableCloudWatchLogs {
 		addLogGroupResource(template, config)
 	}
+	addLoggingResources(template, config)
+
+	// Add Lambda authorizers deployed by the stack
+	addAuthorizerFunctionResources(template, config)
+
+	// Add ECR repositories for agents built by the stack
+	addECRResources(template, config)
+
+	// Add AgentCore Memory for agents with memory enabled
+	addMemoryResources(template, config)
+
+	// Add custom domains for agent and gateway endpoints
+	addDomainResources(template, config)
+
+	// Add scheduled and queued agent invocations
+	addRuntimeARNParameters(template, config)
+	if err := addScheduleResources(template, config); err != nil {
+		return nil, err
+	}
+	addQueueResources(template, config)
+
+	// Add the artifact bucket
+	addArtifactsResources(template, config)
+
+	// Add CloudWatch alarms and budget alerts
+	addAlarmResources(template, config)
+
+	// Add the CloudWatch dashboard
+	if err := addDashboardResources(template, config); err != nil {
+		return nil, err
+	}
 
 	// Add agent-related outputs and comments
 	addAgentOutputs(template, config)
 
 	// Add outputs
 	addOutputs(template, config)
+	if err := addDeclaredOutputs(template, config); err != nil {
+		return nil, err
+	}
 
 	// Marshal to YAML
 	data, err := yaml.Marshal(template)
@@ -134,6 +168,9 @@ func addParameters(template *CloudFormationTemplate, config *StackConfig) {
 
 	// Add parameters for each agent's container image
 	for _, agent := range config.Agents {
+		if agent.ContainerImage == "" {
+			continue // Image is built into a stack-managed ECR repository
+		}
 		paramName := fmt.Sprintf("%sContainerImage", toPascalCase(agent.Name))
 		template.Parameters[paramName] = CFParameter{
 			Type:        "String",
@@ -195,7 +232,7 @@ func addVPCResources(template *CloudFormationTemplate, config *StackConfig) {
 		Type: "AWS::EC2::Subnet",
 		Properties: map[string]interface{}{
 			"VpcId":               map[string]string{"Ref": "VPC"},
-			"CidrBlock":           "10.0.1.0/24",
+			"CidrBlock":           PublicSubnetCIDR,
 			"AvailabilityZone":    map[string]interface{}{"Fn::Select": []interface{}{0, map[string]string{"Fn::GetAZs": ""}}},
 			"MapPublicIpOnLaunch": true,
 			"Tags": []map[string]interface{}{
@@ -209,7 +246,7 @@ func addVPCResources(template *CloudFormationTemplate, config *StackConfig) {
 		Type: "AWS::EC2::Subnet",
 		Properties: map[string]interface{}{
 			"VpcId":            map[string]string{"Ref": "VPC"},
-			"CidrBlock":        "10.0.10.0/24",
+			"CidrBlock":        PrivateSubnetCIDR,
 			"AvailabilityZone": map[string]interface{}{"Fn::Select": []interface{}{0, map[string]string{"Fn::GetAZs": ""}}},
 			"Tags": []map[string]interface{}{
 				{"Key": "Name", "Value": fmt.Sprintf("%s-private-1", stackName)},
@@ -267,13 +304,9 @@ func addVPCResources(template *CloudFormationTemplate, config *StackConfig) {
 
 // addIAMResources adds IAM-related CloudFormation resources.
 func addIAMResources(template *CloudFormationTemplate, config *StackConfig) {
-	stackName := config.StackName
-
-	// Execution Role
-	template.Resources["ExecutionRole"] = CFResource{
-		Type: "AWS::IAM::Role",
-		Properties: map[string]interface{}{
-			"RoleName": fmt.Sprintf("%s-execution-role", stackName),
+	for _, role := range config.ExecutionRoles() {
+		props := map[string]interface{}{
+			"RoleName": role.RoleName,
 			"AssumeRolePolicyDocument": map[string]interface{}{
 				"Version": "2012-10-17",
 				"Statement": []map[string]interface{}{
@@ -294,84 +327,33 @@ func addIAMResources(template *CloudFormationTemplate, config *StackConfig) {
 					"PolicyName": "AgentCorePolicy",
 					"PolicyDocument": map[string]interface{}{
 						"Version":   "2012-10-17",
-						"Statement": buildIAMStatements(config),
+						"Statement": role.Statements,
 					},
 				},
 			},
 			"Tags": []map[string]interface{}{
-				{"Key": "Name", "Value": fmt.Sprintf("%s-execution-role", stackName)},
+				{"Key": "Name", "Value": role.RoleName},
 				{"Key": "ManagedBy", "Value": "agentkit"},
 			},
-		},
-	}
-}
-
-// buildIAMStatements builds IAM policy statements based on config.
-func buildIAMStatements(config *StackConfig) []map[string]interface{} {
-	statements := []map[string]interface{}{
-		// CloudWatch Logs
-		{
-			"Effect": "Allow",
-			"Action": []string{
-				"logs:CreateLogGroup",
-				"logs:CreateLogStream",
-				"logs:PutLogEvents",
-			},
-			"Resource": "arn:aws:logs:*:*:*",
-		},
-		// ECR
-		{
-			"Effect": "Allow",
-			"Action": []string{
-				"ecr:GetAuthorizationToken",
-				"ecr:BatchCheckLayerAvailability",
-				"ecr:GetDownloadUrlForLayer",
-				"ecr:BatchGetImage",
-			},
-			"Resource": "*",
-		},
-	}
-
-	// Bedrock access
-	if config.IAM.EnableBedrockAccess {
-		bedrockStatement := map[string]interface{}{
-			"Effect": "Allow",
-			"Action": []string{
-				"bedrock:InvokeModel",
-				"bedrock:InvokeModelWithResponseStream",
-			},
 		}
-		if len(config.IAM.BedrockModelIDs) > 0 {
-			resources := make([]string, len(config.IAM.BedrockModelIDs))
-			for i, modelID := range config.IAM.BedrockModelIDs {
-				resources[i] = fmt.Sprintf("arn:aws:bedrock:*:*:foundation-model/%s", modelID)
-			}
-			bedrockStatement["Resource"] = resources
-		} else {
-			bedrockStatement["Resource"] = "arn:aws:bedrock:*:*:foundation-model/*"
+		if len(config.IAM.AdditionalPolicies) > 0 {
+			props["ManagedPolicyArns"] = config.IAM.AdditionalPolicies
+		}
+		if config.IAM.PermissionsBoundaryARN != "" {
+			props["PermissionsBoundary"] = config.IAM.PermissionsBoundaryARN
 		}
-		statements = append(statements, bedrockStatement)
-	}
 
-	// Secrets Manager access
-	hasSecrets := false
-	for _, agent := range config.Agents {
-		if len(agent.SecretsARNs) > 0 {
-			hasSecrets = true
-			break
+		template.Resources[executionRoleLogicalID(role.Agent)] = CFResource{
+			Type:       "AWS::IAM::Role",
+			Properties: props,
 		}
 	}
-	if hasSecrets {
-		statements = append(statements, map[string]interface{}{
-			"Effect": "Allow",
-			"Action": []string{
-				"secretsmanager:GetSecretValue",
-			},
-			"Resource": "*", // Should be scoped to specific secrets in production
-		})
-	}
+}
 
-	return statements
+// executionRoleLogicalID returns the logical ID of an agent's execution
+// role, or of the shared role if agent is empty.
+func executionRoleLogicalID(agent string) string {
+	return toPascalCase(agent) + "ExecutionRole"
 }
 
 // addLogGroupResource adds CloudWatch Log Group resource.
@@ -404,12 +386,24 @@ func addAgentOutputs(template *CloudFormationTemplate, config *StackConfig) {
 		}
 		template.Outputs[fmt.Sprintf("Agent%dImage", i+1)] = CFOutput{
 			Description: fmt.Sprintf("Agent %d container image", i+1),
-			Value:       map[string]string{"Ref": fmt.Sprintf("%sContainerImage", toPascalCase(agent.Name))},
+			Value:       cfImageValue(agent),
 		}
 		template.Outputs[fmt.Sprintf("Agent%dMemory", i+1)] = CFOutput{
 			Description: fmt.Sprintf("Agent %d memory (MB)", i+1),
 			Value:       fmt.Sprintf("%d", agent.MemoryMB),
 		}
+		if auth := agent.Authorizer.InboundConfigurationJSON(); auth != "" {
+			template.Outputs[fmt.Sprintf("Agent%dAuthorizer", i+1)] = CFOutput{
+				Description: fmt.Sprintf("Agent %d inbound authorizer configuration (JSON)", i+1),
+				Value:       auth,
+			}
+		}
+		if agent.Scaling != nil {
+			template.Outputs[fmt.Sprintf("Agent%dLifecycle", i+1)] = CFOutput{
+				Description: fmt.Sprintf("Agent %d runtime lifecycle configuration (JSON)", i+1),
+				Value:       agent.Scaling.LifecycleConfigurationJSON(),
+			}
+		}
 	}
 }
 
@@ -438,12 +432,19 @@ func addOutputs(template *CloudFormationTemplate, config *StackConfig) {
 		}
 	}
 
-	template.Outputs["ExecutionRoleARN"] = CFOutput{
-		Description: "IAM Execution Role ARN",
-		Value:       map[string]interface{}{"Fn::GetAtt": []string{"ExecutionRole", "Arn"}},
-		Export: &CFExport{
-			Name: map[string]interface{}{"Fn::Sub": "${AWS::StackName}-ExecutionRoleARN"},
-		},
+	for _, role := range config.ExecutionRoles() {
+		id := executionRoleLogicalID(role.Agent)
+		description := "IAM Execution Role ARN"
+		if role.Agent != "" {
+			description = fmt.Sprintf("IAM Execution Role ARN for %s", role.Agent)
+		}
+		template.Outputs[id+"ARN"] = CFOutput{
+			Description: description,
+			Value:       map[string]interface{}{"Fn::GetAtt": []string{id, "Arn"}},
+			Export: &CFExport{
+				Name: map[string]interface{}{"Fn::Sub": "${AWS::StackName}-" + id + "ARN"},
+			},
+		}
 	}
 
 	if config.Observability.EnableCloudWatchLogs {
@@ -457,6 +458,13 @@ func addOutputs(template *CloudFormationTemplate, config *StackConfig) {
 		Description: "Number of agents configured",
 		Value:       fmt.Sprintf("%d", len(config.Agents)),
 	}
+
+	if config.Hooks != nil {
+		template.Outputs["Hooks"] = CFOutput{
+			Description: "Deployment lifecycle hooks (JSON)",
+			Value:       config.Hooks.JSON(),
+		}
+	}
 }
 
 // GenerateCloudFormationFile generates a CloudFormation template and writes it to a file.
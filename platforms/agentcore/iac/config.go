@@ -4,11 +4,12 @@
 // different IaC tools (CDK, Pulumi, Terraform, CloudFormation). The configuration
 // can be defined in Go code, JSON, or YAML files.
 //
-// Four deployment approaches are supported:
+// Five deployment approaches are supported:
 //  1. CDK Go constructs - via github.com/plexusone/agentkit-aws-cdk
 //  2. CDK + JSON/YAML config - configuration files with minimal CDK wrapper
 //  3. Pulumi - via github.com/plexusone/agentkit-aws-pulumi
 //  4. Pure CloudFormation - generate CF templates, deploy with AWS CLI
+//  5. Terraform - generate .tf.json modules via the iac/terraform subpackage
 //
 // Example usage:
 //
@@ -34,7 +35,13 @@ type AgentConfig struct {
 
 	// ContainerImage is the ECR image URI for the agent.
 	// Example: "123456789.dkr.ecr.us-east-1.amazonaws.com/my-agent:latest"
-	ContainerImage string `json:"containerImage" yaml:"containerImage"`
+	// Required unless ImageBuild is set.
+	ContainerImage string `json:"containerImage,omitempty" yaml:"containerImage,omitempty"`
+
+	// ImageBuild configures building the agent image and creating its ECR
+	// repository. When set, ContainerImage may be omitted and is derived
+	// from the repository and tag.
+	ImageBuild *ImageBuildConfig `json:"imageBuild,omitempty" yaml:"imageBuild,omitempty"`
 
 	// MemoryMB is the memory allocation in megabytes.
 	// Valid values: 512, 1024, 2048, 4096, 8192, 16384
@@ -47,13 +54,33 @@ type AgentConfig struct {
 	TimeoutSeconds int `json:"timeoutSeconds,omitempty" yaml:"timeoutSeconds,omitempty"`
 
 	// Environment contains environment variables for the agent.
-	// API keys should use SecretsARNs instead for security.
+	// Values may be encrypted references, decrypted by the agent at startup:
+	//   - "kms:<base64 ciphertext>" (requires EnvironmentKMSKeyARN)
+	//   - "ssm:<SecureString parameter name or ARN>"
+	// API keys should use an encrypted reference or SecretsARNs for security.
 	Environment map[string]string `json:"environment,omitempty" yaml:"environment,omitempty"`
 
+	// EnvironmentKMSKeyARN is the KMS key that encrypts "kms:" environment
+	// values and customer-managed SecureString parameters. The execution
+	// role is granted kms:Decrypt on it.
+	EnvironmentKMSKeyARN string `json:"environmentKmsKeyArn,omitempty" yaml:"environmentKmsKeyArn,omitempty"`
+
 	// SecretsARNs is a list of AWS Secrets Manager ARNs to inject.
 	// These are mounted as environment variables at runtime.
 	SecretsARNs []string `json:"secretsARNs,omitempty" yaml:"secretsARNs,omitempty"`
 
+	// IAM scopes the permissions this agent needs.
+	// Optional - defaults to the stack IAM settings.
+	IAM *AgentIAMConfig `json:"iam,omitempty" yaml:"iam,omitempty"`
+
+	// Guardrail applies a Bedrock guardrail to the agent's model calls.
+	// Optional - defaults to the stack guardrail.
+	Guardrail *GuardrailConfig `json:"guardrail,omitempty" yaml:"guardrail,omitempty"`
+
+	// Logging gives the agent its own log group.
+	// Optional - defaults to the stack log group.
+	Logging *AgentLoggingConfig `json:"logging,omitempty" yaml:"logging,omitempty"`
+
 	// IsDefault marks this as the default agent for the stack.
 	// Only one agent should have IsDefault=true.
 	IsDefault bool `json:"isDefault,omitempty" yaml:"isDefault,omitempty"`
@@ -68,20 +95,50 @@ type AgentConfig struct {
 	Authorizer *AuthorizerConfig `json:"authorizer,omitempty" yaml:"authorizer,omitempty"`
 
 	// EnableMemory enables persistent memory for the agent.
+	// Provisions an AgentCore Memory resource configured by Memory.
 	// Default: false
 	EnableMemory bool `json:"enableMemory,omitempty" yaml:"enableMemory,omitempty"`
+
+	// Memory configures the agent's AgentCore Memory resource.
+	// Optional - setting it implies EnableMemory.
+	Memory *MemoryConfig `json:"memory,omitempty" yaml:"memory,omitempty"`
+
+	// Scaling configures the runtime session lifecycle.
+	// Optional - if not set, AgentCore defaults apply.
+	Scaling *ScalingConfig `json:"scaling,omitempty" yaml:"scaling,omitempty"`
+
+	// Domain configures a custom domain for the agent endpoint.
+	// Optional.
+	Domain *DomainConfig `json:"domain,omitempty" yaml:"domain,omitempty"`
+
+	// RuntimeARN is the ARN of the deployed agent runtime. Used by stack
+	// resources that invoke the agent, such as schedules.
+	// Optional - can be supplied as a parameter at deploy time.
+	RuntimeARN string `json:"runtimeArn,omitempty" yaml:"runtimeArn,omitempty"`
+
+	// Tags are AWS resource tags for the agent's resources. They are merged
+	// with the stack tags, and take precedence on conflicting keys.
+	Tags map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
 }
 
 // AuthorizerConfig defines authorization configuration for an agent.
 type AuthorizerConfig struct {
 	// Type is the authorization type.
-	// Supported: "IAM", "LAMBDA", "NONE"
+	// Supported: "IAM", "LAMBDA", "JWT", "NONE"
 	// Default: "NONE"
 	Type string `json:"type" yaml:"type"`
 
-	// LambdaARN is the ARN of the Lambda authorizer function.
-	// Required when Type is "LAMBDA".
+	// LambdaARN is the ARN of an existing Lambda authorizer function.
+	// Type "LAMBDA" requires LambdaARN or Function.
 	LambdaARN string `json:"lambdaArn,omitempty" yaml:"lambdaArn,omitempty"`
+
+	// Function defines a Lambda authorizer function deployed by the stack.
+	// Type "LAMBDA" requires LambdaARN or Function.
+	Function *AuthorizerFunctionConfig `json:"function,omitempty" yaml:"function,omitempty"`
+
+	// JWT configures bearer token validation against an OIDC provider.
+	// Required when Type is "JWT".
+	JWT *JWTAuthorizerConfig `json:"jwt,omitempty" yaml:"jwt,omitempty"`
 }
 
 // VPCConfig defines networking configuration for AgentCore deployment.
@@ -99,11 +156,18 @@ type VPCConfig struct {
 	// Default: true
 	CreateVPC bool `json:"createVPC,omitempty" yaml:"createVPC,omitempty"`
 
-	// VPCCidr is the CIDR block for the new VPC.
+	// VPCCidr is the CIDR block for the new VPC. It must contain the
+	// subnets 10.0.1.0/24 and 10.0.10.0/24.
+	// Range: /16 to /28
 	// Default: "10.0.0.0/16"
 	VPCCidr string `json:"vpcCidr,omitempty" yaml:"vpcCidr,omitempty"`
 
+	// ReservedCIDRs are CIDR blocks already in use, such as peered VPCs or
+	// on-premises networks. The new VPC must not overlap them.
+	ReservedCIDRs []string `json:"reservedCidrs,omitempty" yaml:"reservedCidrs,omitempty"`
+
 	// MaxAZs is the maximum number of availability zones.
+	// Range: 1-6
 	// Default: 2
 	MaxAZs int `json:"maxAZs,omitempty" yaml:"maxAZs,omitempty"`
 
@@ -180,9 +244,18 @@ type IAMConfig struct {
 	// Default: true
 	EnableBedrockAccess bool `json:"enableBedrockAccess,omitempty" yaml:"enableBedrockAccess,omitempty"`
 
-	// BedrockModelIDs are specific model IDs to allow.
+	// BedrockModelIDs are specific model IDs to allow. Cross-region
+	// inference profile IDs such as "us.anthropic.claude-..." are supported.
 	// If empty, allows all models ("bedrock:*").
 	BedrockModelIDs []string `json:"bedrockModelIds,omitempty" yaml:"bedrockModelIds,omitempty"`
+
+	// PerAgentRoles creates a separate execution role for each agent, with
+	// only the permissions that agent needs.
+	// Default: false (one role shared by all agents)
+	PerAgentRoles bool `json:"perAgentRoles,omitempty" yaml:"perAgentRoles,omitempty"`
+
+	// AdditionalStatements are added to the policy of every execution role.
+	AdditionalStatements []IAMStatement `json:"additionalStatements,omitempty" yaml:"additionalStatements,omitempty"`
 }
 
 // GatewayConfig defines configuration for a multi-agent gateway.
@@ -201,6 +274,20 @@ type GatewayConfig struct {
 	// Targets is a list of agent names to route to.
 	// If empty, all agents in the stack are included.
 	Targets []string `json:"targets,omitempty" yaml:"targets,omitempty"`
+
+	// ExternalTargets are Lambda functions, OpenAPI and Smithy APIs, and MCP
+	// servers exposed through the gateway in addition to the stack agents.
+	ExternalTargets []GatewayTargetConfig `json:"externalTargets,omitempty" yaml:"externalTargets,omitempty"`
+
+	// Domain configures a custom domain for the gateway endpoint.
+	// Optional.
+	Domain *DomainConfig `json:"domain,omitempty" yaml:"domain,omitempty"`
+
+	// URL is the https URL of the deployed gateway, e.g.
+	// "https://{id}.gateway.bedrock-agentcore.{region}.amazonaws.com/mcp",
+	// which Domain routes to.
+	// Required when Domain is set.
+	URL string `json:"url,omitempty" yaml:"url,omitempty"`
 }
 
 // StackConfig defines the complete configuration for an AgentCore deployment stack.
@@ -236,9 +323,46 @@ type StackConfig struct {
 	// Optional - only needed for multi-agent communication.
 	Gateway *GatewayConfig `json:"gateway,omitempty" yaml:"gateway,omitempty"`
 
+	// Schedules are recurring agent invocations.
+	// Optional.
+	Schedules []ScheduleConfig `json:"schedules,omitempty" yaml:"schedules,omitempty"`
+
+	// Queues are SQS queues for asynchronous agent invocation.
+	// Optional.
+	Queues []QueueConfig `json:"queues,omitempty" yaml:"queues,omitempty"`
+
+	// Artifacts configures an S3 bucket for files produced by agents.
+	// Optional.
+	Artifacts *ArtifactsConfig `json:"artifacts,omitempty" yaml:"artifacts,omitempty"`
+
+	// Guardrail applies a Bedrock guardrail to all agents that do not set
+	// their own.
+	// Optional.
+	Guardrail *GuardrailConfig `json:"guardrail,omitempty" yaml:"guardrail,omitempty"`
+
+	// Hooks are commands and invocations run before and after deployment.
+	// Optional.
+	Hooks *HooksConfig `json:"hooks,omitempty" yaml:"hooks,omitempty"`
+
+	// Alarms configures CloudWatch alarms and the monthly budget alert.
+	// Optional - error-rate and latency alarms are created by default.
+	Alarms *AlarmsConfig `json:"alarms,omitempty" yaml:"alarms,omitempty"`
+
+	// Dashboard adds a CloudWatch dashboard for the agents.
+	// Optional.
+	Dashboard *DashboardConfig `json:"dashboard,omitempty" yaml:"dashboard,omitempty"`
+
+	// Outputs are additional stack outputs for downstream systems.
+	// Optional.
+	Outputs []OutputConfig `json:"outputs,omitempty" yaml:"outputs,omitempty"`
+
 	// Tags are AWS resource tags applied to all resources.
 	Tags map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
 
+	// TagPolicy enforces required tag keys and allowed tag values.
+	// Optional.
+	TagPolicy *TagPolicyConfig `json:"tagPolicy,omitempty" yaml:"tagPolicy,omitempty"`
+
 	// RemovalPolicy determines what happens to resources on stack deletion.
 	// "destroy" removes all resources, "retain" keeps them.
 	// Default: "destroy"
@@ -286,121 +410,6 @@ func DefaultIAMConfig() *IAMConfig {
 	}
 }
 
-// Validate validates the StackConfig and returns any errors.
-func (c *StackConfig) Validate() error {
-	if c.StackName == "" {
-		return fmt.Errorf("stackName is required")
-	}
-
-	if len(c.Agents) == 0 {
-		return fmt.Errorf("at least one agent is required")
-	}
-
-	defaultCount := 0
-	agentNames := make(map[string]bool)
-
-	for i, agent := range c.Agents {
-		if agent.Name == "" {
-			return fmt.Errorf("agents[%d]: name is required", i)
-		}
-		if agent.ContainerImage == "" {
-			return fmt.Errorf("agents[%d] (%s): containerImage is required", i, agent.Name)
-		}
-		if agentNames[agent.Name] {
-			return fmt.Errorf("duplicate agent name: %s", agent.Name)
-		}
-		agentNames[agent.Name] = true
-
-		if agent.IsDefault {
-			defaultCount++
-		}
-
-		if agent.MemoryMB != 0 {
-			validMemory := []int{512, 1024, 2048, 4096, 8192, 16384}
-			valid := false
-			for _, m := range validMemory {
-				if agent.MemoryMB == m {
-					valid = true
-					break
-				}
-			}
-			if !valid {
-				return fmt.Errorf("agents[%d] (%s): memoryMB must be one of %v", i, agent.Name, validMemory)
-			}
-		}
-
-		if agent.TimeoutSeconds != 0 && (agent.TimeoutSeconds < 1 || agent.TimeoutSeconds > 900) {
-			return fmt.Errorf("agents[%d] (%s): timeoutSeconds must be between 1 and 900", i, agent.Name)
-		}
-
-		// Validate protocol
-		if agent.Protocol != "" {
-			validProtocols := []string{"HTTP", "MCP", "A2A"}
-			valid := false
-			for _, p := range validProtocols {
-				if agent.Protocol == p {
-					valid = true
-					break
-				}
-			}
-			if !valid {
-				return fmt.Errorf("agents[%d] (%s): protocol must be one of %v", i, agent.Name, validProtocols)
-			}
-		}
-
-		// Validate authorizer
-		if agent.Authorizer != nil {
-			validAuthTypes := []string{"IAM", "LAMBDA", "NONE"}
-			valid := false
-			for _, t := range validAuthTypes {
-				if agent.Authorizer.Type == t {
-					valid = true
-					break
-				}
-			}
-			if !valid {
-				return fmt.Errorf("agents[%d] (%s): authorizer.type must be one of %v", i, agent.Name, validAuthTypes)
-			}
-			if agent.Authorizer.Type == "LAMBDA" && agent.Authorizer.LambdaARN == "" {
-				return fmt.Errorf("agents[%d] (%s): authorizer.lambdaArn is required when type is LAMBDA", i, agent.Name)
-			}
-		}
-	}
-
-	if defaultCount > 1 {
-		return fmt.Errorf("only one agent can be marked as default")
-	}
-
-	// Validate gateway targets reference existing agents
-	if c.Gateway != nil && c.Gateway.Enabled && len(c.Gateway.Targets) > 0 {
-		for _, target := range c.Gateway.Targets {
-			if !agentNames[target] {
-				return fmt.Errorf("gateway target '%s' does not match any agent name", target)
-			}
-		}
-	}
-
-	if c.VPC != nil && c.VPC.VPCID != "" && len(c.VPC.SubnetIDs) == 0 {
-		return fmt.Errorf("vpc.subnetIds are required when using an existing VPC")
-	}
-
-	if c.Observability != nil && c.Observability.Provider != "" {
-		validProviders := []string{"opik", "langfuse", "phoenix", "cloudwatch"}
-		valid := false
-		for _, p := range validProviders {
-			if c.Observability.Provider == p {
-				valid = true
-				break
-			}
-		}
-		if !valid {
-			return fmt.Errorf("invalid observability.provider: %s (valid: %v)", c.Observability.Provider, validProviders)
-		}
-	}
-
-	return nil
-}
-
 // ApplyDefaults applies default values to unset fields.
 func (c *StackConfig) ApplyDefaults() {
 	if c.Description == "" {
@@ -441,8 +450,12 @@ func (c *StackConfig) ApplyDefaults() {
 		if c.Gateway.Description == "" {
 			c.Gateway.Description = fmt.Sprintf("Gateway for %s", c.StackName)
 		}
+		applyDomainDefaults(c.Gateway.Domain)
 	}
 
+	c.applyHooksDefaults()
+	c.applyScheduleDefaults()
+
 	for i := range c.Agents {
 		if c.Agents[i].MemoryMB == 0 {
 			c.Agents[i].MemoryMB = 512
@@ -459,7 +472,20 @@ func (c *StackConfig) ApplyDefaults() {
 		if c.Agents[i].Protocol == "" {
 			c.Agents[i].Protocol = "HTTP"
 		}
+		applyImageBuildDefaults(c.StackName, &c.Agents[i])
+		applyScalingDefaults(&c.Agents[i])
+		applyDomainDefaults(c.Agents[i].Domain)
+		applyMemoryDefaults(c.StackName, &c.Agents[i])
+		applyAuthorizerDefaults(c.StackName, &c.Agents[i])
 	}
+
+	// Queue defaults depend on agent timeouts.
+	c.applyQueueDefaults()
+	c.applyArtifactsDefaults()
+	c.applyGuardrailDefaults()
+	c.applyLoggingDefaults()
+	c.applyAlarmsDefaults()
+	c.applyDashboardDefaults()
 }
 
 // ValidMemoryValues returns the list of valid memory values in MB.
@@ -479,5 +505,5 @@ func ValidProtocols() []string {
 
 // ValidAuthorizerTypes returns the list of valid authorizer types.
 func ValidAuthorizerTypes() []string {
-	return []string{"IAM", "LAMBDA", "NONE"}
+	return []string{"IAM", "LAMBDA", "JWT", "NONE"}
 }
@@ -0,0 +1,253 @@
+package iac
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// DashboardRegion is the region placeholder in dashboard bodies built for
+// stack resources. CloudFormation substitutes it with Fn::Sub.
+const DashboardRegion = "${AWS::Region}"
+
+// dashboardNamePattern is the CloudWatch dashboard name constraint.
+var dashboardNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,255}$`)
+
+// Dashboard grid dimensions. CloudWatch dashboards are 24 units wide.
+const (
+	dashboardWidth        = 24
+	dashboardMetricWidth  = 8
+	dashboardWidgetHeight = 6
+)
+
+// DashboardConfig defines a CloudWatch dashboard with a row of widgets per
+// agent: invocations, latency percentiles, errors and recent error logs.
+// Setting it adds the dashboard to the stack.
+type DashboardConfig struct {
+	// Name is the dashboard name.
+	// Default: "{stack-name}-agents"
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// PeriodSeconds is the metric aggregation period.
+	// Must be a multiple of 60.
+	// Default: 300
+	PeriodSeconds int `json:"periodSeconds,omitempty" yaml:"periodSeconds,omitempty"`
+
+	// LogGroups are the log groups searched by the Logs Insights widgets.
+	// Default: each agent's own log group, or the stack log group if
+	// CloudWatch Logs is enabled
+	LogGroups []string `json:"logGroups,omitempty" yaml:"logGroups,omitempty"`
+}
+
+// DefaultDashboardConfig returns a DashboardConfig with sensible defaults.
+func DefaultDashboardConfig(stackName string) *DashboardConfig {
+	return &DashboardConfig{
+		Name:          stackName + "-agents",
+		PeriodSeconds: 300,
+	}
+}
+
+// applyDashboardDefaults fills in Dashboard defaults.
+func (c *StackConfig) applyDashboardDefaults() {
+	d := c.Dashboard
+	if d == nil {
+		return
+	}
+	defaults := DefaultDashboardConfig(c.StackName)
+	if d.Name == "" {
+		d.Name = defaults.Name
+	}
+	if d.PeriodSeconds == 0 {
+		d.PeriodSeconds = defaults.PeriodSeconds
+	}
+}
+
+// validateDashboard adds Dashboard errors to the report.
+func (c *StackConfig) validateDashboard(report *ValidationReport) {
+	d := c.Dashboard
+	if d == nil {
+		return
+	}
+	if d.Name != "" && !dashboardNamePattern.MatchString(d.Name) {
+		report.addError("dashboard.name", "dashboard.name must be 1-255 letters, digits, '-' and '_': %s", d.Name)
+	}
+	if d.PeriodSeconds != 0 && (d.PeriodSeconds < 60 || d.PeriodSeconds%60 != 0) {
+		report.addError("dashboard.periodSeconds", "dashboard.periodSeconds must be a multiple of 60")
+	}
+	for i, group := range d.LogGroups {
+		if group == "" {
+			report.addError(fmt.Sprintf("dashboard.logGroups[%d]", i), "dashboard.logGroups[%d] must not be empty", i)
+		}
+	}
+}
+
+// dashboardLogGroups returns the log groups searched by the Logs Insights widgets.
+func (c *StackConfig) dashboardLogGroups(d *DashboardConfig) []string {
+	if len(d.LogGroups) > 0 {
+		return d.LogGroups
+	}
+	if c.Observability != nil && c.Observability.EnableCloudWatchLogs {
+		return []string{fmt.Sprintf("/aws/agentcore/%s", c.StackName)}
+	}
+	return nil
+}
+
+// DashboardBody returns the CloudWatch dashboard body JSON for the stack.
+// Widgets are placed in region; pass DashboardRegion for stack resources.
+// The config must have defaults applied.
+func DashboardBody(config *StackConfig, region string) (string, error) {
+	d := config.Dashboard
+	if d == nil {
+		d = DefaultDashboardConfig(config.StackName)
+	}
+	namespace := AgentCoreMetricsNamespace
+	if config.Alarms != nil && config.Alarms.MetricsNamespace != "" {
+		namespace = config.Alarms.MetricsNamespace
+	}
+	logGroups := config.dashboardLogGroups(d)
+
+	var widgets []map[string]interface{}
+	y := 0
+	for _, agent := range config.Agents {
+		var dimensions []interface{}
+		for _, k := range sortedTagKeys(AlarmDimensions(agent)) {
+			dimensions = append(dimensions, k, AlarmDimensions(agent)[k])
+		}
+		metric := func(name string, options map[string]interface{}) []interface{} {
+			m := append([]interface{}{namespace, name}, dimensions...)
+			if options != nil {
+				m = append(m, options)
+			}
+			return m
+		}
+		metricWidget := func(x int, title string, metrics [][]interface{}, stat string) map[string]interface{} {
+			return map[string]interface{}{
+				"type":   "metric",
+				"x":      x,
+				"y":      y,
+				"width":  dashboardMetricWidth,
+				"height": dashboardWidgetHeight,
+				"properties": map[string]interface{}{
+					"title":   title,
+					"region":  region,
+					"view":    "timeSeries",
+					"stacked": false,
+					"stat":    stat,
+					"period":  d.PeriodSeconds,
+					"metrics": metrics,
+				},
+			}
+		}
+
+		widgets = append(widgets, map[string]interface{}{
+			"type":       "text",
+			"x":          0,
+			"y":          y,
+			"width":      dashboardWidth,
+			"height":     1,
+			"properties": map[string]interface{}{"markdown": fmt.Sprintf("## %s", agent.Name)},
+		})
+		y++
+
+		widgets = append(widgets,
+			metricWidget(0, "Invocations", [][]interface{}{
+				metric("Invocations", nil),
+			}, "Sum"),
+			metricWidget(dashboardMetricWidth, "Latency (ms)", [][]interface{}{
+				metric("Latency", map[string]interface{}{"stat": "p50", "label": "p50"}),
+				metric("Latency", map[string]interface{}{"stat": "p90", "label": "p90"}),
+				metric("Latency", map[string]interface{}{"stat": "p99", "label": "p99"}),
+			}, "p99"),
+			metricWidget(2*dashboardMetricWidth, "Errors", [][]interface{}{
+				metric("SystemErrors", nil),
+				metric("UserErrors", nil),
+				metric("Throttles", nil),
+			}, "Sum"),
+		)
+		y += dashboardWidgetHeight
+
+		agentLogGroups := logGroups
+		if len(d.LogGroups) == 0 && agent.Logging != nil {
+			agentLogGroups = []string{agent.Logging.LogGroupName}
+		}
+		if len(agentLogGroups) == 0 {
+			continue
+		}
+		sources := make([]string, len(agentLogGroups))
+		for i, group := range agentLogGroups {
+			sources[i] = fmt.Sprintf("SOURCE '%s'", group)
+		}
+		widgets = append(widgets, map[string]interface{}{
+			"type":   "log",
+			"x":      0,
+			"y":      y,
+			"width":  dashboardWidth,
+			"height": dashboardWidgetHeight,
+			"properties": map[string]interface{}{
+				"title":  "Recent errors",
+				"region": region,
+				"view":   "table",
+				"query": strings.Join(sources, " | ") +
+					fmt.Sprintf(" | fields @timestamp, @logStream, @message | filter @logStream like %q and @message like /(?i)(error|exception)/ | sort @timestamp desc | limit 50", agent.Name),
+			},
+		})
+		y += dashboardWidgetHeight
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"widgets": widgets})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode dashboard: %w", err)
+	}
+	return string(body), nil
+}
+
+// GenerateDashboard generates the CloudWatch dashboard body JSON for the
+// stack, for use with "aws cloudwatch put-dashboard".
+func GenerateDashboard(config *StackConfig, region string) ([]byte, error) {
+	config.ApplyDefaults()
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if region == "" {
+		return nil, fmt.Errorf("region is required")
+	}
+	body, err := DashboardBody(config, region)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(body), nil
+}
+
+// GenerateDashboardFile generates a dashboard body and writes it to outputPath.
+func GenerateDashboardFile(config *StackConfig, region, outputPath string) error {
+	body, err := GenerateDashboard(config, region)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outputPath, body, 0600)
+}
+
+// addDashboardResources adds the CloudWatch dashboard.
+func addDashboardResources(template *CloudFormationTemplate, config *StackConfig) error {
+	if config.Dashboard == nil {
+		return nil
+	}
+	body, err := DashboardBody(config, DashboardRegion)
+	if err != nil {
+		return err
+	}
+	template.Resources["Dashboard"] = CFResource{
+		Type: "AWS::CloudWatch::Dashboard",
+		Properties: map[string]interface{}{
+			"DashboardName": config.Dashboard.Name,
+			"DashboardBody": map[string]interface{}{"Fn::Sub": body},
+		},
+	}
+	template.Outputs["DashboardName"] = CFOutput{
+		Description: "CloudWatch dashboard",
+		Value:       map[string]string{"Ref": "Dashboard"},
+	}
+	return nil
+}
@@ -0,0 +1,404 @@
+package iac
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ChangeKind is the kind of a config change.
+type ChangeKind string
+
+// Config change kinds.
+const (
+	ChangeAdded    ChangeKind = "added"
+	ChangeRemoved  ChangeKind = "removed"
+	ChangeModified ChangeKind = "modified"
+)
+
+// Change is a single field-level difference between two configs.
+type Change struct {
+	// Path is the config path, e.g. "agents[research].memoryMB".
+	// Items of named lists such as agents, schedules and queues are
+	// addressed by name.
+	Path string `json:"path"`
+
+	// Kind is the kind of change.
+	Kind ChangeKind `json:"kind"`
+
+	// Old is the previous value. Nil for added fields.
+	Old interface{} `json:"old,omitempty"`
+
+	// New is the new value. Nil for removed fields.
+	New interface{} `json:"new,omitempty"`
+}
+
+// String returns the change as a single line, e.g.
+// "~ agents[research].memoryMB: 512 -> 1024".
+func (c Change) String() string {
+	switch c.Kind {
+	case ChangeAdded:
+		return fmt.Sprintf("+ %s: %s", c.Path, formatDiffValue(c.New))
+	case ChangeRemoved:
+		return fmt.Sprintf("- %s: %s", c.Path, formatDiffValue(c.Old))
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", c.Path, formatDiffValue(c.Old), formatDiffValue(c.New))
+	}
+}
+
+// IAMPermission is a single action granted on a resource by the agent
+// execution role.
+type IAMPermission struct {
+	Action   string `json:"action"`
+	Resource string `json:"resource"`
+}
+
+// String returns the permission as "action on resource".
+func (p IAMPermission) String() string {
+	return p.Action + " on " + p.Resource
+}
+
+// ChangeSet is the effective infrastructure difference between two configs.
+type ChangeSet struct {
+	// AgentsAdded are the names of agents only in the new config.
+	AgentsAdded []string `json:"agentsAdded,omitempty"`
+
+	// AgentsRemoved are the names of agents only in the old config.
+	AgentsRemoved []string `json:"agentsRemoved,omitempty"`
+
+	// MemoryChanges are changes to memory settings of agents in both configs.
+	MemoryChanges []Change `json:"memoryChanges,omitempty"`
+
+	// IAMAdded are execution role permissions granted by the new config.
+	IAMAdded []IAMPermission `json:"iamAdded,omitempty"`
+
+	// IAMRemoved are execution role permissions no longer granted.
+	IAMRemoved []IAMPermission `json:"iamRemoved,omitempty"`
+
+	// Changes are all other field-level changes.
+	Changes []Change `json:"changes,omitempty"`
+}
+
+// IsEmpty returns true if the configs are equivalent.
+func (s *ChangeSet) IsEmpty() bool {
+	return len(s.AgentsAdded) == 0 && len(s.AgentsRemoved) == 0 && len(s.MemoryChanges) == 0 &&
+		len(s.IAMAdded) == 0 && len(s.IAMRemoved) == 0 && len(s.Changes) == 0
+}
+
+// ExpandsIAM returns true if the new config grants permissions the old one did not.
+func (s *ChangeSet) ExpandsIAM() bool {
+	return len(s.IAMAdded) > 0
+}
+
+// String returns a human-readable summary of the change set, suitable for
+// review comments.
+func (s *ChangeSet) String() string {
+	if s.IsEmpty() {
+		return "No changes.\n"
+	}
+
+	var b strings.Builder
+	if len(s.AgentsAdded) > 0 {
+		fmt.Fprintf(&b, "Agents added: %s\n", strings.Join(s.AgentsAdded, ", "))
+	}
+	if len(s.AgentsRemoved) > 0 {
+		fmt.Fprintf(&b, "Agents removed: %s\n", strings.Join(s.AgentsRemoved, ", "))
+	}
+	section := func(title string, lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "%s:\n", title)
+		for _, line := range lines {
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+	}
+	section("Memory", changeLines(s.MemoryChanges))
+	section("IAM permissions added", permissionLines("+ ", s.IAMAdded))
+	section("IAM permissions removed", permissionLines("- ", s.IAMRemoved))
+	section("Other changes", changeLines(s.Changes))
+	return b.String()
+}
+
+func changeLines(changes []Change) []string {
+	lines := make([]string, len(changes))
+	for i, c := range changes {
+		lines[i] = c.String()
+	}
+	return lines
+}
+
+func permissionLines(prefix string, perms []IAMPermission) []string {
+	lines := make([]string, len(perms))
+	for i, p := range perms {
+		lines[i] = prefix + p.String()
+	}
+	return lines
+}
+
+// Diff compares two configs after applying defaults and returns the
+// effective change set. Neither input is modified. A nil old config is
+// treated as an empty stack, so every agent is reported as added.
+func Diff(old, new *StackConfig) (*ChangeSet, error) {
+	oldConfig, err := copyWithDefaults(old)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read old config: %w", err)
+	}
+	newConfig, err := copyWithDefaults(new)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read new config: %w", err)
+	}
+
+	oldRaw, err := toRawConfig(oldConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode old config: %w", err)
+	}
+	newRaw, err := toRawConfig(newConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode new config: %w", err)
+	}
+
+	var changes []Change
+	diffValues("", oldRaw, newRaw, &changes)
+
+	set := &ChangeSet{}
+	for _, c := range changes {
+		switch {
+		case strings.HasPrefix(c.Path, "agents[") && strings.HasSuffix(c.Path, "]") && c.Kind == ChangeAdded:
+			set.AgentsAdded = append(set.AgentsAdded, listItemName(c.Path))
+		case strings.HasPrefix(c.Path, "agents[") && strings.HasSuffix(c.Path, "]") && c.Kind == ChangeRemoved:
+			set.AgentsRemoved = append(set.AgentsRemoved, listItemName(c.Path))
+		case strings.HasPrefix(c.Path, "agents[") && isMemoryPath(c.Path):
+			set.MemoryChanges = append(set.MemoryChanges, c)
+		default:
+			set.Changes = append(set.Changes, c)
+		}
+	}
+
+	if old != nil {
+		set.IAMAdded, set.IAMRemoved = diffPermissions(iamPermissions(oldConfig), iamPermissions(newConfig))
+	} else {
+		set.IAMAdded = iamPermissions(newConfig)
+	}
+
+	return set, nil
+}
+
+// copyWithDefaults returns a deep copy of config with defaults applied.
+func copyWithDefaults(config *StackConfig) (*StackConfig, error) {
+	out := &StackConfig{}
+	if config == nil {
+		return out, nil
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return nil, err
+	}
+	out.ApplyDefaults()
+	return out, nil
+}
+
+// toRawConfig converts config to a generic map for comparison.
+func toRawConfig(config *StackConfig) (map[string]interface{}, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// diffValues appends the differences between old and new at path to changes.
+// Objects are compared key by key and named lists item by item; other
+// values are compared as a whole.
+func diffValues(path string, old, new interface{}, changes *[]Change) {
+	if reflect.DeepEqual(old, new) {
+		return
+	}
+
+	oldMap, oldIsMap := old.(map[string]interface{})
+	newMap, newIsMap := new.(map[string]interface{})
+	if oldIsMap && newIsMap {
+		keys := make(map[string]bool, len(oldMap)+len(newMap))
+		for k := range oldMap {
+			keys[k] = true
+		}
+		for k := range newMap {
+			keys[k] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+		for _, k := range sorted {
+			diffValues(joinDiffPath(path, k), oldMap[k], newMap[k], changes)
+		}
+		return
+	}
+
+	if oldList, newList, ok := namedLists(old, new); ok {
+		diffNamedLists(path, oldList, newList, changes)
+		return
+	}
+
+	switch {
+	case old == nil:
+		*changes = append(*changes, Change{Path: path, Kind: ChangeAdded, New: new})
+	case new == nil:
+		*changes = append(*changes, Change{Path: path, Kind: ChangeRemoved, Old: old})
+	default:
+		*changes = append(*changes, Change{Path: path, Kind: ChangeModified, Old: old, New: new})
+	}
+}
+
+// namedLists returns old and new as lists if both are named lists, or
+// one is a named list and the other is absent or empty.
+func namedLists(old, new interface{}) ([]interface{}, []interface{}, bool) {
+	oldList, oldIsList := old.([]interface{})
+	newList, newIsList := new.([]interface{})
+	if (!oldIsList && old != nil) || (!newIsList && new != nil) {
+		return nil, nil, false
+	}
+	if !isNamedList(oldList) && !isNamedList(newList) {
+		return nil, nil, false
+	}
+	if (len(oldList) > 0 && !isNamedList(oldList)) || (len(newList) > 0 && !isNamedList(newList)) {
+		return nil, nil, false
+	}
+	return oldList, newList, true
+}
+
+// diffNamedLists compares list items with the same name, in old order
+// followed by new items in new order.
+func diffNamedLists(path string, old, new []interface{}, changes *[]Change) {
+	byName := func(list []interface{}) (map[string]interface{}, []string) {
+		items := make(map[string]interface{}, len(list))
+		var names []string
+		for _, item := range list {
+			name := item.(map[string]interface{})["name"].(string)
+			items[name] = item
+			names = append(names, name)
+		}
+		return items, names
+	}
+	oldItems, oldNames := byName(old)
+	newItems, newNames := byName(new)
+
+	for _, name := range oldNames {
+		diffValues(fmt.Sprintf("%s[%s]", path, name), oldItems[name], newItems[name], changes)
+	}
+	for _, name := range newNames {
+		if _, ok := oldItems[name]; !ok {
+			diffValues(fmt.Sprintf("%s[%s]", path, name), nil, newItems[name], changes)
+		}
+	}
+}
+
+func joinDiffPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// isMemoryPath reports whether an agent path refers to memory settings.
+func isMemoryPath(path string) bool {
+	return strings.HasSuffix(path, "].enableMemory") || strings.HasSuffix(path, "].memory") || strings.Contains(path, "].memory.")
+}
+
+// listItemName returns "name" from a path ending in "[name]".
+func listItemName(path string) string {
+	start := strings.Index(path, "[")
+	return path[start+1 : len(path)-1]
+}
+
+// formatDiffValue formats a config value for display.
+func formatDiffValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	data, err := marshalUnescaped(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return data
+}
+
+// iamPermissions returns the execution role permissions for config, sorted.
+func iamPermissions(config *StackConfig) []IAMPermission {
+	if len(config.Agents) == 0 {
+		return nil
+	}
+
+	seen := make(map[IAMPermission]bool)
+	var perms []IAMPermission
+	for _, statement := range buildIAMStatements(config) {
+		for _, action := range stringValues(statement["Action"]) {
+			for _, resource := range stringValues(statement["Resource"]) {
+				p := IAMPermission{Action: action, Resource: resource}
+				if !seen[p] {
+					seen[p] = true
+					perms = append(perms, p)
+				}
+			}
+		}
+	}
+	for _, arn := range config.IAM.AdditionalPolicies {
+		p := IAMPermission{Action: "managed-policy", Resource: arn}
+		if !seen[p] {
+			seen[p] = true
+			perms = append(perms, p)
+		}
+	}
+
+	sort.Slice(perms, func(i, j int) bool {
+		if perms[i].Action != perms[j].Action {
+			return perms[i].Action < perms[j].Action
+		}
+		return perms[i].Resource < perms[j].Resource
+	})
+	return perms
+}
+
+// diffPermissions returns the permissions only in new and only in old.
+func diffPermissions(old, new []IAMPermission) (added, removed []IAMPermission) {
+	oldSet := make(map[IAMPermission]bool, len(old))
+	for _, p := range old {
+		oldSet[p] = true
+	}
+	newSet := make(map[IAMPermission]bool, len(new))
+	for _, p := range new {
+		newSet[p] = true
+		if !oldSet[p] {
+			added = append(added, p)
+		}
+	}
+	for _, p := range old {
+		if !newSet[p] {
+			removed = append(removed, p)
+		}
+	}
+	return added, removed
+}
+
+// stringValues returns v as a list of strings, for IAM fields that may be
+// a string or a list.
+func stringValues(v interface{}) []string {
+	switch t := v.(type) {
+	case string:
+		return []string{t}
+	case []string:
+		return t
+	default:
+		return nil
+	}
+}
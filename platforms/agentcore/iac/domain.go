@@ -0,0 +1,287 @@
+package iac
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DomainConfig defines a custom domain for an agent or gateway endpoint.
+//
+// The stack creates a regional custom domain with a TLS certificate, an
+// HTTP API that proxies the domain to the agent's runtime invocation URL
+// (from its runtimeArn) or the gateway URL, mapped to the domain, and, if
+// HostedZoneID is set, a Route53 alias record pointing at it.
+type DomainConfig struct {
+	// DomainName is the fully qualified domain name.
+	// Example: "agent.example.com"
+	// Required.
+	DomainName string `json:"domainName" yaml:"domainName"`
+
+	// CertificateARN is an existing ACM certificate covering DomainName.
+	// If empty, a DNS-validated certificate is created in HostedZoneID.
+	CertificateARN string `json:"certificateArn,omitempty" yaml:"certificateArn,omitempty"`
+
+	// HostedZoneID is the Route53 hosted zone for DomainName.
+	// Required when CertificateARN is empty.
+	HostedZoneID string `json:"hostedZoneId,omitempty" yaml:"hostedZoneId,omitempty"`
+
+	// SecurityPolicy is the minimum TLS version.
+	// Supported: "TLS_1_2"
+	// Default: "TLS_1_2"
+	SecurityPolicy string `json:"securityPolicy,omitempty" yaml:"securityPolicy,omitempty"`
+}
+
+// DomainBinding associates a custom domain with the agent or gateway it serves.
+type DomainBinding struct {
+	// Owner is the agent name, or the gateway name for the gateway domain.
+	Owner string
+
+	// IsGateway is true for the gateway domain.
+	IsGateway bool
+
+	// Domain is the domain configuration.
+	Domain *DomainConfig
+
+	// TargetURL is the URL requests to the domain are proxied to: the
+	// agent's runtime invocation URL, without its query, or the gateway
+	// URL.
+	TargetURL string
+
+	// TargetQuery holds query parameters added to proxied requests, e.g.
+	// the runtime endpoint qualifier.
+	TargetQuery map[string]string
+}
+
+// ResourceName returns a name for the binding's resources: "gateway", or
+// "agent-" and the agent name, so that no agent collides with the gateway.
+func (b DomainBinding) ResourceName() string {
+	if b.IsGateway {
+		return "gateway"
+	}
+	return "agent-" + b.Owner
+}
+
+// DomainBindings returns all custom domains configured in the stack,
+// agents first and the gateway last.
+func (c *StackConfig) DomainBindings() []DomainBinding {
+	var bindings []DomainBinding
+	for _, agent := range c.Agents {
+		if agent.Domain != nil {
+			bindings = append(bindings, DomainBinding{
+				Owner:       agent.Name,
+				Domain:      agent.Domain,
+				TargetURL:   RuntimeInvocationURL(agent),
+				TargetQuery: map[string]string{"qualifier": "DEFAULT"},
+			})
+		}
+	}
+	if c.Gateway != nil && c.Gateway.Enabled && c.Gateway.Domain != nil {
+		bindings = append(bindings, DomainBinding{
+			Owner:     c.Gateway.Name,
+			IsGateway: true,
+			Domain:    c.Gateway.Domain,
+			TargetURL: c.Gateway.URL,
+		})
+	}
+	return bindings
+}
+
+// RequestParameters returns the API Gateway parameter mapping that adds
+// TargetQuery to proxied requests.
+func (b DomainBinding) RequestParameters() map[string]string {
+	params := make(map[string]string, len(b.TargetQuery))
+	for key, value := range b.TargetQuery {
+		params["overwrite:querystring."+key] = value
+	}
+	return params
+}
+
+// applyDomainDefaults fills in DomainConfig defaults.
+func applyDomainDefaults(d *DomainConfig) {
+	if d == nil {
+		return
+	}
+	d.DomainName = strings.TrimSuffix(strings.ToLower(d.DomainName), ".")
+	if d.SecurityPolicy == "" {
+		d.SecurityPolicy = "TLS_1_2"
+	}
+}
+
+// validateDomains adds custom domain errors to the report.
+func (c *StackConfig) validateDomains(report *ValidationReport) {
+	seen := make(map[string]bool)
+	check := func(path string, d *DomainConfig) {
+		if d == nil {
+			return
+		}
+		name := strings.TrimSuffix(strings.ToLower(d.DomainName), ".")
+		switch {
+		case name == "":
+			report.addError(path+".domainName", "%s.domainName is required", path)
+		case !isDomainName(name):
+			report.addError(path+".domainName", "%s.domainName is not a valid domain name: %s", path, d.DomainName)
+		case seen[name]:
+			report.addError(path+".domainName", "duplicate custom domain: %s", name)
+		}
+		seen[name] = true
+
+		if d.CertificateARN == "" && d.HostedZoneID == "" {
+			report.addError(path+".hostedZoneId", "%s.hostedZoneId is required when certificateArn is not set", path)
+		}
+		if d.CertificateARN != "" && !strings.HasPrefix(d.CertificateARN, "arn:aws:acm:") {
+			report.addError(path+".certificateArn", "%s.certificateArn must be an ACM certificate ARN", path)
+		}
+		if d.SecurityPolicy != "" && d.SecurityPolicy != "TLS_1_2" {
+			report.addError(path+".securityPolicy", "%s.securityPolicy must be TLS_1_2", path)
+		}
+	}
+
+	for i, agent := range c.Agents {
+		path := fmt.Sprintf("agents[%d].domain", i)
+		check(path, agent.Domain)
+		if agent.Domain != nil && RuntimeInvocationURL(agent) == "" {
+			report.addError(path, "%s requires agents[%d].runtimeArn to route the domain to the agent", path, i)
+		}
+	}
+	if c.Gateway != nil && c.Gateway.Enabled {
+		check("gateway.domain", c.Gateway.Domain)
+		if c.Gateway.Domain != nil && !strings.HasPrefix(c.Gateway.URL, "https://") {
+			report.addError("gateway.url", "gateway.domain requires gateway.url, the https URL of the gateway")
+		}
+	}
+}
+
+// isDomainName reports whether s is a plausible fully qualified domain name.
+func isDomainName(s string) bool {
+	labels := strings.Split(s, ".")
+	if len(labels) < 2 || len(s) > 253 {
+		return false
+	}
+	for _, label := range labels {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, r := range label {
+			if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// addDomainResources adds custom domains, certificates, the HTTP APIs that
+// route them to their agent or gateway, and DNS records.
+func addDomainResources(template *CloudFormationTemplate, config *StackConfig) {
+	for _, b := range config.DomainBindings() {
+		d := b.Domain
+		prefix := toPascalCase(b.ResourceName())
+
+		var certificate interface{} = d.CertificateARN
+		if d.CertificateARN == "" {
+			template.Resources[prefix+"Certificate"] = CFResource{
+				Type: "AWS::CertificateManager::Certificate",
+				Properties: map[string]interface{}{
+					"DomainName":       d.DomainName,
+					"ValidationMethod": "DNS",
+					"DomainValidationOptions": []map[string]interface{}{
+						{"DomainName": d.DomainName, "HostedZoneId": d.HostedZoneID},
+					},
+					"Tags": []map[string]interface{}{
+						{"Key": "Name", "Value": d.DomainName},
+						{"Key": "ManagedBy", "Value": "agentkit"},
+					},
+				},
+			}
+			certificate = map[string]string{"Ref": prefix + "Certificate"}
+		}
+
+		domainID := prefix + "DomainName"
+		template.Resources[domainID] = CFResource{
+			Type: "AWS::ApiGatewayV2::DomainName",
+			Properties: map[string]interface{}{
+				"DomainName": d.DomainName,
+				"DomainNameConfigurations": []map[string]interface{}{
+					{
+						"CertificateArn": certificate,
+						"EndpointType":   "REGIONAL",
+						"SecurityPolicy": d.SecurityPolicy,
+					},
+				},
+				"Tags": map[string]string{"Name": d.DomainName, "ManagedBy": "agentkit"},
+			},
+		}
+
+		apiID := prefix + "Api"
+		template.Resources[apiID] = CFResource{
+			Type: "AWS::ApiGatewayV2::Api",
+			Properties: map[string]interface{}{
+				"Name":         fmt.Sprintf("%s-%s", config.StackName, b.ResourceName()),
+				"ProtocolType": "HTTP",
+				"Tags":         map[string]string{"Name": d.DomainName, "ManagedBy": "agentkit"},
+			},
+		}
+		integration := map[string]interface{}{
+			"ApiId":                map[string]string{"Ref": apiID},
+			"IntegrationType":      "HTTP_PROXY",
+			"IntegrationMethod":    "ANY",
+			"IntegrationUri":       b.TargetURL,
+			"PayloadFormatVersion": "1.0",
+		}
+		if params := b.RequestParameters(); len(params) > 0 {
+			integration["RequestParameters"] = params
+		}
+		template.Resources[prefix+"Integration"] = CFResource{
+			Type:       "AWS::ApiGatewayV2::Integration",
+			Properties: integration,
+		}
+		template.Resources[prefix+"Route"] = CFResource{
+			Type: "AWS::ApiGatewayV2::Route",
+			Properties: map[string]interface{}{
+				"ApiId":    map[string]string{"Ref": apiID},
+				"RouteKey": "$default",
+				"Target":   map[string]string{"Fn::Sub": fmt.Sprintf("integrations/${%sIntegration}", prefix)},
+			},
+		}
+		template.Resources[prefix+"Stage"] = CFResource{
+			Type: "AWS::ApiGatewayV2::Stage",
+			Properties: map[string]interface{}{
+				"ApiId":      map[string]string{"Ref": apiID},
+				"StageName":  "$default",
+				"AutoDeploy": true,
+			},
+		}
+		template.Resources[prefix+"ApiMapping"] = CFResource{
+			Type: "AWS::ApiGatewayV2::ApiMapping",
+			Properties: map[string]interface{}{
+				"ApiId":      map[string]string{"Ref": apiID},
+				"DomainName": map[string]string{"Ref": domainID},
+				"Stage":      map[string]string{"Ref": prefix + "Stage"},
+			},
+		}
+
+		if d.HostedZoneID != "" {
+			template.Resources[prefix+"DomainRecord"] = CFResource{
+				Type: "AWS::Route53::RecordSet",
+				Properties: map[string]interface{}{
+					"HostedZoneId": d.HostedZoneID,
+					"Name":         d.DomainName,
+					"Type":         "A",
+					"AliasTarget": map[string]interface{}{
+						"DNSName":      map[string]interface{}{"Fn::GetAtt": []string{domainID, "RegionalDomainName"}},
+						"HostedZoneId": map[string]interface{}{"Fn::GetAtt": []string{domainID, "RegionalHostedZoneId"}},
+					},
+				},
+			}
+		}
+
+		template.Outputs[prefix+"URL"] = CFOutput{
+			Description: fmt.Sprintf("Custom domain URL for %s", b.Owner),
+			Value:       "https://" + d.DomainName,
+		}
+		template.Outputs[prefix+"DomainTarget"] = CFOutput{
+			Description: fmt.Sprintf("Regional domain name to alias %s to", d.DomainName),
+			Value:       map[string]interface{}{"Fn::GetAtt": []string{domainID, "RegionalDomainName"}},
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package iac
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Environment value reference prefixes. A value with one of these prefixes
+// is decrypted by the agent at startup instead of being used verbatim.
+const (
+	// EnvRefKMS marks a base64 KMS ciphertext: "kms:AQICAHh...".
+	EnvRefKMS = "kms:"
+
+	// EnvRefSSM marks an SSM SecureString parameter name or ARN:
+	// "ssm:/myapp/api-key" or "ssm:arn:aws:ssm:us-east-1:123456789012:parameter/myapp/api-key".
+	EnvRefSSM = "ssm:"
+)
+
+// EnvReference is a parsed encrypted environment value reference.
+type EnvReference struct {
+	// Prefix is EnvRefKMS or EnvRefSSM.
+	Prefix string
+
+	// Ciphertext is the decoded KMS ciphertext blob. Set for EnvRefKMS.
+	Ciphertext []byte
+
+	// Parameter is the SSM parameter name or ARN. Set for EnvRefSSM.
+	Parameter string
+}
+
+// plaintextCredentialPatterns match well-known credential formats that must
+// never appear as plaintext environment values.
+var plaintextCredentialPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`),                // AWS access key ID
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`),         // PEM private key
+	regexp.MustCompile(`\bsk-(ant-|proj-)?[A-Za-z0-9_-]{20,}`),       // OpenAI / Anthropic API key
+	regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}`),               // GitHub token
+	regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`),             // Slack token
+	regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`),                  // Google API key
+	regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]+`), // JWT
+}
+
+// IsEnvReference reports whether an environment value is an encrypted
+// value reference.
+func IsEnvReference(value string) bool {
+	return strings.HasPrefix(value, EnvRefKMS) || strings.HasPrefix(value, EnvRefSSM)
+}
+
+// ParseEnvReference parses an encrypted environment value reference.
+// It returns false if value is a plain value.
+func ParseEnvReference(value string) (EnvReference, bool, error) {
+	switch {
+	case strings.HasPrefix(value, EnvRefKMS):
+		encoded := strings.TrimPrefix(value, EnvRefKMS)
+		if encoded == "" {
+			return EnvReference{}, true, fmt.Errorf("kms reference has no ciphertext")
+		}
+		blob, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return EnvReference{}, true, fmt.Errorf("kms reference ciphertext is not valid base64: %w", err)
+		}
+		return EnvReference{Prefix: EnvRefKMS, Ciphertext: blob}, true, nil
+
+	case strings.HasPrefix(value, EnvRefSSM):
+		param := strings.TrimPrefix(value, EnvRefSSM)
+		if strings.HasPrefix(param, "arn:") {
+			if !strings.HasPrefix(param, "arn:aws:ssm:") || !strings.Contains(param, ":parameter/") {
+				return EnvReference{}, true, fmt.Errorf("ssm reference ARN must be an SSM parameter ARN: %s", param)
+			}
+		} else if param == "" || strings.ContainsAny(param, " \t\n") {
+			return EnvReference{}, true, fmt.Errorf("ssm reference must name a parameter")
+		}
+		return EnvReference{Prefix: EnvRefSSM, Parameter: param}, true, nil
+	}
+	return EnvReference{}, false, nil
+}
+
+// ParameterARN returns the IAM resource ARN of an SSM reference.
+// Parameter names are matched in any region and account.
+func (r EnvReference) ParameterARN() string {
+	if strings.HasPrefix(r.Parameter, "arn:") {
+		return r.Parameter
+	}
+	return "arn:aws:ssm:*:*:parameter/" + strings.TrimPrefix(r.Parameter, "/")
+}
+
+// EncryptedEnvironment returns the agent's environment variables that hold
+// encrypted value references, keyed by variable name. Malformed references
+// are skipped.
+func (a *AgentConfig) EncryptedEnvironment() map[string]EnvReference {
+	refs := make(map[string]EnvReference)
+	for key, value := range a.Environment {
+		if ref, ok, err := ParseEnvReference(value); ok && err == nil {
+			refs[key] = ref
+		}
+	}
+	return refs
+}
+
+// environmentIAMStatements returns the execution role statements needed to
+// decrypt encrypted environment values, or nil if no agent uses them.
+func environmentIAMStatements(agents []AgentConfig) []map[string]interface{} {
+	keys := make(map[string]bool)
+	params := make(map[string]bool)
+	for _, agent := range agents {
+		refs := agent.EncryptedEnvironment()
+		if len(refs) == 0 {
+			continue
+		}
+		// The key also covers SecureString parameters encrypted with it.
+		if agent.EnvironmentKMSKeyARN != "" {
+			keys[agent.EnvironmentKMSKeyARN] = true
+		}
+		for _, ref := range refs {
+			if ref.Prefix == EnvRefSSM {
+				params[ref.ParameterARN()] = true
+			}
+		}
+	}
+
+	var statements []map[string]interface{}
+	if len(keys) > 0 {
+		statements = append(statements, map[string]interface{}{
+			"Effect":   "Allow",
+			"Action":   []string{"kms:Decrypt"},
+			"Resource": sortedKeys(keys),
+		})
+	}
+	if len(params) > 0 {
+		statements = append(statements, map[string]interface{}{
+			"Effect": "Allow",
+			"Action": []string{
+				"ssm:GetParameter",
+				"ssm:GetParameters",
+			},
+			"Resource": sortedKeys(params),
+		})
+	}
+	return statements
+}
+
+// sortedKeys returns the keys of set in sorted order.
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// looksLikePlaintextCredential reports whether value matches a well-known
+// credential format.
+func looksLikePlaintextCredential(value string) bool {
+	for _, p := range plaintextCredentialPatterns {
+		if p.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateEnvironment adds encrypted reference and plaintext credential
+// errors for the agent at index i.
+func validateEnvironment(report *ValidationReport, i int, agent AgentConfig) {
+	if agent.EnvironmentKMSKeyARN != "" && !strings.HasPrefix(agent.EnvironmentKMSKeyARN, "arn:aws:kms:") {
+		report.addError(fmt.Sprintf("agents[%d].environmentKmsKeyArn", i), "agents[%d] (%s): environmentKmsKeyArn must be a KMS key ARN", i, agent.Name)
+	}
+
+	for _, key := range sortedTagKeys(agent.Environment) {
+		value := agent.Environment[key]
+		path := fmt.Sprintf("agents[%d].environment.%s", i, key)
+
+		ref, ok, err := ParseEnvReference(value)
+		switch {
+		case err != nil:
+			report.addError(path, "agents[%d] (%s): environment variable %s: %v", i, agent.Name, key, err)
+		case ok && ref.Prefix == EnvRefKMS && agent.EnvironmentKMSKeyARN == "":
+			report.addError(path, "agents[%d] (%s): environment variable %s uses a kms: reference; environmentKmsKeyArn is required", i, agent.Name, key)
+		case !ok && looksLikePlaintextCredential(value):
+			report.addError(path, "agents[%d] (%s): environment variable %s contains a plaintext credential; use a kms: or ssm: reference or secretsARNs instead", i, agent.Name, key)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package iac
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Gateway target types supported by AgentCore Gateway.
+const (
+	GatewayTargetLambda    = "LAMBDA"
+	GatewayTargetOpenAPI   = "OPENAPI"
+	GatewayTargetSmithy    = "SMITHY"
+	GatewayTargetMCPServer = "MCP_SERVER"
+)
+
+// Gateway target credential types.
+const (
+	GatewayCredentialsIAMRole = "GATEWAY_IAM_ROLE"
+	GatewayCredentialsAPIKey  = "API_KEY"
+	GatewayCredentialsOAuth   = "OAUTH"
+)
+
+// gatewayTargetNamePattern is the AgentCore Gateway target name constraint.
+var gatewayTargetNamePattern = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,98}[a-zA-Z0-9])?$`)
+
+// GatewayTargetConfig defines a gateway target that is not an agent in the stack.
+type GatewayTargetConfig struct {
+	// Name is the unique target name. Tools are exposed as "{name}___{tool}".
+	// Required.
+	Name string `json:"name" yaml:"name"`
+
+	// Type is the target type.
+	// Supported: "LAMBDA", "OPENAPI", "SMITHY", "MCP_SERVER"
+	// Required.
+	Type string `json:"type" yaml:"type"`
+
+	// Description is a description of the target.
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+
+	// LambdaARN is the Lambda function ARN. Required for "LAMBDA".
+	LambdaARN string `json:"lambdaArn,omitempty" yaml:"lambdaArn,omitempty"`
+
+	// SchemaS3URI is the S3 URI of the tool schema ("LAMBDA"), OpenAPI
+	// spec ("OPENAPI") or Smithy model ("SMITHY").
+	// One of SchemaS3URI or SchemaInline is required for these types.
+	SchemaS3URI string `json:"schemaS3Uri,omitempty" yaml:"schemaS3Uri,omitempty"`
+
+	// SchemaInline is the schema document inline, as JSON or YAML text.
+	SchemaInline string `json:"schemaInline,omitempty" yaml:"schemaInline,omitempty"`
+
+	// Endpoint is the https URL of the MCP server. Required for "MCP_SERVER".
+	Endpoint string `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+
+	// Credentials configures how the gateway authenticates to the target.
+	// Default: GATEWAY_IAM_ROLE for LAMBDA and SMITHY; none for MCP_SERVER.
+	// Required for OPENAPI.
+	Credentials *GatewayCredentialsConfig `json:"credentials,omitempty" yaml:"credentials,omitempty"`
+}
+
+// GatewayCredentialsConfig defines outbound credentials for a gateway target.
+type GatewayCredentialsConfig struct {
+	// Type is the credential type.
+	// Supported: "GATEWAY_IAM_ROLE", "API_KEY", "OAUTH"
+	Type string `json:"type" yaml:"type"`
+
+	// ProviderARN is the AgentCore Identity credential provider ARN.
+	// Required for "API_KEY" and "OAUTH".
+	ProviderARN string `json:"providerArn,omitempty" yaml:"providerArn,omitempty"`
+
+	// Scopes are the OAuth scopes to request. Used with "OAUTH".
+	Scopes []string `json:"scopes,omitempty" yaml:"scopes,omitempty"`
+
+	// APIKeyLocation is where the API key is sent, "HEADER" or "QUERY_PARAMETER".
+	// Used with "API_KEY".
+	// Default: "HEADER"
+	APIKeyLocation string `json:"apiKeyLocation,omitempty" yaml:"apiKeyLocation,omitempty"`
+
+	// APIKeyParameterName is the header or query parameter name.
+	// Used with "API_KEY".
+	// Default: "Authorization"
+	APIKeyParameterName string `json:"apiKeyParameterName,omitempty" yaml:"apiKeyParameterName,omitempty"`
+
+	// APIKeyPrefix is prepended to the key, e.g. "Bearer".
+	// Used with "API_KEY".
+	APIKeyPrefix string `json:"apiKeyPrefix,omitempty" yaml:"apiKeyPrefix,omitempty"`
+}
+
+// ValidGatewayTargetTypes returns the list of valid gateway target types.
+func ValidGatewayTargetTypes() []string {
+	return []string{GatewayTargetLambda, GatewayTargetOpenAPI, GatewayTargetSmithy, GatewayTargetMCPServer}
+}
+
+// validGatewayCredentials returns the credential types a target type accepts.
+func validGatewayCredentials(targetType string) []string {
+	switch targetType {
+	case GatewayTargetLambda, GatewayTargetSmithy:
+		return []string{GatewayCredentialsIAMRole}
+	case GatewayTargetOpenAPI:
+		return []string{GatewayCredentialsAPIKey, GatewayCredentialsOAuth}
+	case GatewayTargetMCPServer:
+		return []string{GatewayCredentialsOAuth}
+	default:
+		return nil
+	}
+}
+
+// TargetConfiguration returns the AgentCore Gateway target configuration
+// for the target.
+func (t *GatewayTargetConfig) TargetConfiguration() map[string]interface{} {
+	schema := func() map[string]interface{} {
+		if t.SchemaS3URI != "" {
+			return map[string]interface{}{"s3": map[string]string{"uri": t.SchemaS3URI}}
+		}
+		return map[string]interface{}{"inlinePayload": t.SchemaInline}
+	}
+
+	var mcp map[string]interface{}
+	switch t.Type {
+	case GatewayTargetLambda:
+		mcp = map[string]interface{}{"lambda": map[string]interface{}{"lambdaArn": t.LambdaARN, "toolSchema": schema()}}
+	case GatewayTargetOpenAPI:
+		mcp = map[string]interface{}{"openApiSchema": schema()}
+	case GatewayTargetSmithy:
+		mcp = map[string]interface{}{"smithyModel": schema()}
+	case GatewayTargetMCPServer:
+		mcp = map[string]interface{}{"mcpServer": map[string]string{"endpoint": t.Endpoint}}
+	}
+	return map[string]interface{}{"mcp": mcp}
+}
+
+// CredentialProviderConfigurations returns the AgentCore Gateway credential
+// provider configurations for the target, or nil if it uses none.
+func (t *GatewayTargetConfig) CredentialProviderConfigurations() []map[string]interface{} {
+	c := t.Credentials
+	if c == nil {
+		if t.Type == GatewayTargetLambda || t.Type == GatewayTargetSmithy {
+			c = &GatewayCredentialsConfig{Type: GatewayCredentialsIAMRole}
+		} else {
+			return nil
+		}
+	}
+
+	config := map[string]interface{}{"credentialProviderType": c.Type}
+	switch c.Type {
+	case GatewayCredentialsAPIKey:
+		location := c.APIKeyLocation
+		if location == "" {
+			location = "HEADER"
+		}
+		name := c.APIKeyParameterName
+		if name == "" {
+			name = "Authorization"
+		}
+		apiKey := map[string]interface{}{
+			"providerArn":             c.ProviderARN,
+			"credentialLocation":      location,
+			"credentialParameterName": name,
+		}
+		if c.APIKeyPrefix != "" {
+			apiKey["credentialPrefix"] = c.APIKeyPrefix
+		}
+		config["credentialProvider"] = map[string]interface{}{"apiKeyCredentialProvider": apiKey}
+	case GatewayCredentialsOAuth:
+		oauth := map[string]interface{}{"providerArn": c.ProviderARN, "scopes": c.Scopes}
+		if c.Scopes == nil {
+			oauth["scopes"] = []string{}
+		}
+		config["credentialProvider"] = map[string]interface{}{"oauthCredentialProvider": oauth}
+	}
+	return []map[string]interface{}{config}
+}
+
+// validateGatewayTargets adds external gateway target errors to the report.
+func (c *StackConfig) validateGatewayTargets(report *ValidationReport, agentNames map[string]bool) {
+	if c.Gateway == nil || !c.Gateway.Enabled {
+		return
+	}
+
+	names := make(map[string]bool)
+	for i, t := range c.Gateway.ExternalTargets {
+		path := fmt.Sprintf("gateway.externalTargets[%d]", i)
+
+		switch {
+		case !gatewayTargetNamePattern.MatchString(t.Name):
+			report.addError(path+".name", "%s: name is required and must contain only letters, digits and '-'", path)
+		case names[t.Name] || agentNames[t.Name]:
+			report.addError(path+".name", "duplicate gateway target name: %s", t.Name)
+		}
+		names[t.Name] = true
+
+		if !slices.Contains(ValidGatewayTargetTypes(), t.Type) {
+			report.addError(path+".type", "%s (%s): type must be one of %v", path, t.Name, ValidGatewayTargetTypes())
+			continue
+		}
+
+		switch t.Type {
+		case GatewayTargetLambda:
+			if !strings.HasPrefix(t.LambdaARN, "arn:aws:lambda:") {
+				report.addError(path+".lambdaArn", "%s (%s): lambdaArn is required for LAMBDA targets", path, t.Name)
+			}
+		case GatewayTargetMCPServer:
+			if !isHTTPSURL(t.Endpoint) {
+				report.addError(path+".endpoint", "%s (%s): endpoint must be an https URL for MCP_SERVER targets", path, t.Name)
+			}
+		}
+		if t.Type != GatewayTargetMCPServer {
+			if (t.SchemaS3URI == "") == (t.SchemaInline == "") {
+				report.addError(path+".schemaS3Uri", "%s (%s): exactly one of schemaS3Uri or schemaInline is required for %s targets", path, t.Name, t.Type)
+			} else if t.SchemaS3URI != "" && !strings.HasPrefix(t.SchemaS3URI, "s3://") {
+				report.addError(path+".schemaS3Uri", "%s (%s): schemaS3Uri must start with s3://", path, t.Name)
+			}
+		}
+
+		creds := t.Credentials
+		if creds == nil {
+			if t.Type == GatewayTargetOpenAPI {
+				report.addError(path+".credentials", "%s (%s): credentials are required for OPENAPI targets", path, t.Name)
+			}
+			continue
+		}
+		if valid := validGatewayCredentials(t.Type); !slices.Contains(valid, creds.Type) {
+			report.addError(path+".credentials.type", "%s (%s): credentials.type must be one of %v for %s targets", path, t.Name, valid, t.Type)
+			continue
+		}
+		if creds.Type != GatewayCredentialsIAMRole && !strings.HasPrefix(creds.ProviderARN, "arn:aws:bedrock-agentcore:") {
+			report.addError(path+".credentials.providerArn", "%s (%s): credentials.providerArn must be an AgentCore credential provider ARN", path, t.Name)
+		}
+		if creds.APIKeyLocation != "" && creds.APIKeyLocation != "HEADER" && creds.APIKeyLocation != "QUERY_PARAMETER" {
+			report.addError(path+".credentials.apiKeyLocation", "%s (%s): credentials.apiKeyLocation must be HEADER or QUERY_PARAMETER", path, t.Name)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package iac
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Environment variables injected into agents with a guardrail.
+const (
+	EnvGuardrailID      = "AGENTCORE_GUARDRAIL_ID"
+	EnvGuardrailVersion = "AGENTCORE_GUARDRAIL_VERSION"
+)
+
+// GuardrailVersionDraft is the working draft version of a guardrail.
+const GuardrailVersionDraft = "DRAFT"
+
+var (
+	guardrailIDPattern      = regexp.MustCompile(`^[a-z0-9]{1,64}$`)
+	guardrailARNPattern     = regexp.MustCompile(`^arn:aws[a-z-]*:bedrock:[a-z0-9-]+:\d{12}:guardrail/([a-z0-9]{1,64})$`)
+	guardrailVersionPattern = regexp.MustCompile(`^([1-9][0-9]{0,7}|DRAFT)$`)
+)
+
+// GuardrailConfig applies an Amazon Bedrock guardrail to an agent's model
+// calls. The guardrail ID and version are injected into the agent environment
+// as AGENTCORE_GUARDRAIL_ID and AGENTCORE_GUARDRAIL_VERSION, and the execution
+// role is granted bedrock:ApplyGuardrail on it.
+type GuardrailConfig struct {
+	// ID is the guardrail ID or ARN.
+	// Required.
+	ID string `json:"id" yaml:"id"`
+
+	// Version is the guardrail version number, or "DRAFT".
+	// Default: "DRAFT"
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+}
+
+// ARN returns the guardrail ARN. A bare guardrail ID matches the guardrail
+// in any region and account of the partition.
+func (g *GuardrailConfig) ARN() string {
+	if strings.HasPrefix(g.ID, "arn:") {
+		return g.ID
+	}
+	return fmt.Sprintf("arn:aws:bedrock:*:*:guardrail/%s", g.ID)
+}
+
+// applyGuardrailDefaults fills in Guardrail defaults. Agents without a
+// guardrail inherit the stack guardrail, and each guardrail is injected into
+// its agent's environment. Variables already set by the agent are kept.
+func (c *StackConfig) applyGuardrailDefaults() {
+	if c.Guardrail != nil && c.Guardrail.Version == "" {
+		c.Guardrail.Version = GuardrailVersionDraft
+	}
+	for i := range c.Agents {
+		agent := &c.Agents[i]
+		if agent.Guardrail == nil && c.Guardrail != nil {
+			g := *c.Guardrail
+			agent.Guardrail = &g
+		}
+		g := agent.Guardrail
+		if g == nil {
+			continue
+		}
+		if g.Version == "" {
+			g.Version = GuardrailVersionDraft
+		}
+		if _, ok := agent.Environment[EnvGuardrailID]; !ok {
+			agent.Environment[EnvGuardrailID] = g.ID
+		}
+		if _, ok := agent.Environment[EnvGuardrailVersion]; !ok {
+			agent.Environment[EnvGuardrailVersion] = g.Version
+		}
+	}
+}
+
+// validateGuardrail adds errors for a guardrail at path.
+func validateGuardrail(report *ValidationReport, path string, g *GuardrailConfig) {
+	if g == nil {
+		return
+	}
+	switch {
+	case g.ID == "":
+		report.addError(path+".id", "%s.id is required", path)
+	case strings.HasPrefix(g.ID, "arn:"):
+		if !guardrailARNPattern.MatchString(g.ID) {
+			report.addError(path+".id", "%s.id is not a valid guardrail ARN: %s", path, g.ID)
+		}
+	case !guardrailIDPattern.MatchString(g.ID):
+		report.addError(path+".id", "%s.id is not a valid guardrail ID: %s", path, g.ID)
+	}
+	if g.Version != "" && !guardrailVersionPattern.MatchString(g.Version) {
+		report.addError(path+".version", "%s.version must be a version number or %s: %s", path, GuardrailVersionDraft, g.Version)
+	}
+}
+
+// validateGuardrails adds stack and agent Guardrail errors to the report.
+// Agent guardrails inherited from the stack are reported once, for the stack.
+func (c *StackConfig) validateGuardrails(report *ValidationReport) {
+	validateGuardrail(report, "guardrail", c.Guardrail)
+	for i, agent := range c.Agents {
+		if agent.Guardrail == nil || (c.Guardrail != nil && *agent.Guardrail == *c.Guardrail) {
+			continue
+		}
+		validateGuardrail(report, fmt.Sprintf("agents[%d].guardrail", i), agent.Guardrail)
+	}
+}
+
+// validateGuardrailWarnings adds guardrails using the draft version to the report.
+func (c *StackConfig) validateGuardrailWarnings(report *ValidationReport) {
+	if c.isDevStack() {
+		return
+	}
+	for i, agent := range c.Agents {
+		if g := agent.Guardrail; g != nil && g.Version == GuardrailVersionDraft {
+			report.addWarning(fmt.Sprintf("agents[%d].guardrail.version", i),
+				"agents[%d] (%s): guardrail uses the DRAFT version, which changes whenever the guardrail is edited; pin a version number", i, agent.Name)
+		}
+	}
+}
+
+// guardrailIAMStatement returns the execution role statement allowing agents
+// to apply their guardrails, or nil if none has a guardrail.
+func guardrailIAMStatement(agents []AgentConfig) map[string]interface{} {
+	var resources []string
+	for _, agent := range agents {
+		if agent.Guardrail == nil {
+			continue
+		}
+		if arn := agent.Guardrail.ARN(); !slices.Contains(resources, arn) {
+			resources = append(resources, arn)
+		}
+	}
+	if len(resources) == 0 {
+		return nil
+	}
+	return map[string]interface{}{
+		"Effect":   "Allow",
+		"Action":   []string{"bedrock:ApplyGuardrail"},
+		"Resource": resources,
+	}
+}
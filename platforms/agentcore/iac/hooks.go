@@ -0,0 +1,332 @@
+package iac
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/plexusone/agentkit/internal/awscli"
+)
+
+// Hook phases.
+const (
+	HookPreDeploy  = "preDeploy"
+	HookPostDeploy = "postDeploy"
+)
+
+// Environment variables set for hook commands.
+const (
+	EnvHookStackName = "AGENTKIT_STACK_NAME"
+	EnvHookPhase     = "AGENTKIT_HOOK_PHASE"
+)
+
+// maxHookTimeoutSeconds bounds hook run time.
+const maxHookTimeoutSeconds = 3600
+
+// HooksConfig defines commands and invocations run around a deployment.
+//
+// Generators cannot run hooks; they emit them as the "Hooks" stack output
+// and deploy tooling runs them with RunHooks. Pre-deploy hooks run before
+// the stack is deployed, post-deploy hooks after it is deployed, in order.
+type HooksConfig struct {
+	// PreDeploy hooks run before the stack is deployed.
+	// A failing hook aborts the deployment.
+	PreDeploy []HookConfig `json:"preDeploy,omitempty" yaml:"preDeploy,omitempty"`
+
+	// PostDeploy hooks run after the stack is deployed, e.g. smoke tests.
+	PostDeploy []HookConfig `json:"postDeploy,omitempty" yaml:"postDeploy,omitempty"`
+}
+
+// HookConfig defines a single hook. Exactly one of Command, LambdaARN and
+// InvokeAgent must be set.
+type HookConfig struct {
+	// Name identifies the hook in output and errors.
+	// Default: "{phase}-{index}"
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// Command is a shell command run with "sh -c" in the deploy tool's
+	// working directory. AGENTKIT_STACK_NAME and AGENTKIT_HOOK_PHASE are set.
+	Command string `json:"command,omitempty" yaml:"command,omitempty"`
+
+	// LambdaARN is a Lambda function invoked synchronously with Payload.
+	LambdaARN string `json:"lambdaArn,omitempty" yaml:"lambdaArn,omitempty"`
+
+	// InvokeAgent is the name of an agent invoked with Payload, e.g. a
+	// smoke test of the default agent. The agent's RuntimeARN must be set.
+	InvokeAgent string `json:"invokeAgent,omitempty" yaml:"invokeAgent,omitempty"`
+
+	// Payload is the JSON payload sent to LambdaARN or InvokeAgent.
+	// Example: {"prompt": "ping"}
+	Payload map[string]interface{} `json:"payload,omitempty" yaml:"payload,omitempty"`
+
+	// TimeoutSeconds is the maximum run time of the hook.
+	// Range: 1-3600
+	// Default: 300
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty" yaml:"timeoutSeconds,omitempty"`
+
+	// ContinueOnError runs the remaining hooks if this hook fails.
+	// Default: false
+	ContinueOnError bool `json:"continueOnError,omitempty" yaml:"continueOnError,omitempty"`
+}
+
+// Kind returns "command", "lambda" or "agent", or an empty string if the
+// hook has no action.
+func (h *HookConfig) Kind() string {
+	switch {
+	case h.Command != "":
+		return "command"
+	case h.LambdaARN != "":
+		return "lambda"
+	case h.InvokeAgent != "":
+		return "agent"
+	default:
+		return ""
+	}
+}
+
+// PayloadJSON returns the hook payload as JSON text.
+func (h *HookConfig) PayloadJSON() ([]byte, error) {
+	payload := h.Payload
+	if payload == nil {
+		payload = map[string]interface{}{}
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("hook %s: failed to encode payload: %w", h.Name, err)
+	}
+	return data, nil
+}
+
+// Phase returns the hooks of a phase.
+func (h *HooksConfig) Phase(phase string) []HookConfig {
+	if h == nil {
+		return nil
+	}
+	switch phase {
+	case HookPreDeploy:
+		return h.PreDeploy
+	case HookPostDeploy:
+		return h.PostDeploy
+	default:
+		return nil
+	}
+}
+
+// Values returns the hooks keyed by phase. Used by the generators.
+func (h *HooksConfig) Values() map[string]interface{} {
+	values := make(map[string]interface{}, 2)
+	for _, phase := range []string{HookPreDeploy, HookPostDeploy} {
+		hooks := h.Phase(phase)
+		if hooks == nil {
+			hooks = []HookConfig{}
+		}
+		values[phase] = hooks
+	}
+	return values
+}
+
+// JSON returns the hooks as compact JSON text.
+func (h *HooksConfig) JSON() string {
+	// Payloads are validated to be encodable.
+	data, _ := json.Marshal(h.Values())
+	return string(data)
+}
+
+// applyHooksDefaults fills in Hooks defaults.
+func (c *StackConfig) applyHooksDefaults() {
+	if c.Hooks == nil {
+		return
+	}
+	for _, phase := range []string{HookPreDeploy, HookPostDeploy} {
+		hooks := c.Hooks.Phase(phase)
+		for i := range hooks {
+			if hooks[i].Name == "" {
+				hooks[i].Name = fmt.Sprintf("%s-%d", phase, i+1)
+			}
+			if hooks[i].TimeoutSeconds == 0 {
+				hooks[i].TimeoutSeconds = 300
+			}
+		}
+	}
+}
+
+// validateHooks adds Hooks errors to the report.
+func (c *StackConfig) validateHooks(report *ValidationReport, agentNames map[string]bool) {
+	if c.Hooks == nil {
+		return
+	}
+	for _, phase := range []string{HookPreDeploy, HookPostDeploy} {
+		names := make(map[string]bool)
+		for i, h := range c.Hooks.Phase(phase) {
+			path := fmt.Sprintf("hooks.%s[%d]", phase, i)
+
+			actions := 0
+			for _, set := range []bool{h.Command != "", h.LambdaARN != "", h.InvokeAgent != ""} {
+				if set {
+					actions++
+				}
+			}
+			if actions != 1 {
+				report.addError(path, "%s: exactly one of command, lambdaArn and invokeAgent is required", path)
+			}
+
+			if h.LambdaARN != "" && !strings.HasPrefix(h.LambdaARN, "arn:aws:lambda:") {
+				report.addError(path+".lambdaArn", "%s.lambdaArn must be a Lambda function ARN", path)
+			}
+			if h.InvokeAgent != "" && !agentNames[h.InvokeAgent] {
+				report.addError(path+".invokeAgent", "%s.invokeAgent: '%s' does not match any agent name", path, h.InvokeAgent)
+			}
+			if h.Payload != nil && h.Command != "" {
+				report.addError(path+".payload", "%s.payload is not used by command hooks", path)
+			}
+			if _, err := h.PayloadJSON(); err != nil {
+				report.addError(path+".payload", "%s.payload: %v", path, err)
+			}
+			if h.TimeoutSeconds < 0 || h.TimeoutSeconds > maxHookTimeoutSeconds {
+				report.addError(path+".timeoutSeconds", "%s.timeoutSeconds must be between 1 and %d", path, maxHookTimeoutSeconds)
+			}
+
+			if h.Name != "" {
+				if names[h.Name] {
+					report.addError(path+".name", "duplicate %s hook name: %s", phase, h.Name)
+				}
+				names[h.Name] = true
+			}
+		}
+	}
+}
+
+// HookInvoker invokes Lambda functions and agent runtimes for hooks.
+type HookInvoker interface {
+	// InvokeLambda invokes a function synchronously and returns its response.
+	InvokeLambda(ctx context.Context, functionARN string, payload []byte) ([]byte, error)
+
+	// InvokeAgent invokes an agent runtime and returns its response.
+	InvokeAgent(ctx context.Context, runtimeARN string, payload []byte) ([]byte, error)
+}
+
+// RunHooksOptions configures RunHooks.
+type RunHooksOptions struct {
+	// Invoker invokes Lambda and agent hooks.
+	// Default: AWSCLIHookInvoker
+	Invoker HookInvoker
+
+	// Dir is the working directory of command hooks.
+	// Default: the current directory
+	Dir string
+
+	// Output receives command output and hook progress.
+	// Default: os.Stdout
+	Output io.Writer
+}
+
+// RunHooks runs the hooks of a phase in order. A failing hook stops the
+// run and is returned, unless it has ContinueOnError set. The config must
+// have defaults applied.
+func RunHooks(ctx context.Context, config *StackConfig, phase string, opts RunHooksOptions) error {
+	if phase != HookPreDeploy && phase != HookPostDeploy {
+		return fmt.Errorf("unknown hook phase %q", phase)
+	}
+	if opts.Invoker == nil {
+		opts.Invoker = AWSCLIHookInvoker{}
+	}
+	if opts.Output == nil {
+		opts.Output = os.Stdout
+	}
+
+	for _, h := range config.Hooks.Phase(phase) {
+		fmt.Fprintf(opts.Output, "Running %s hook %s\n", phase, h.Name)
+		err := runHook(ctx, config, phase, h, opts)
+		if err == nil {
+			continue
+		}
+		if !h.ContinueOnError {
+			return fmt.Errorf("%s hook %s failed: %w", phase, h.Name, err)
+		}
+		fmt.Fprintf(opts.Output, "%s hook %s failed, continuing: %v\n", phase, h.Name, err)
+	}
+	return nil
+}
+
+// runHook runs a single hook.
+func runHook(ctx context.Context, config *StackConfig, phase string, h HookConfig, opts RunHooksOptions) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(h.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	switch h.Kind() {
+	case "command":
+		cmd := exec.CommandContext(ctx, "sh", "-c", h.Command)
+		cmd.Dir = opts.Dir
+		cmd.Env = append(os.Environ(),
+			EnvHookStackName+"="+config.StackName,
+			EnvHookPhase+"="+phase,
+		)
+		cmd.Stdout = opts.Output
+		cmd.Stderr = opts.Output
+		return cmd.Run()
+
+	case "lambda":
+		payload, err := h.PayloadJSON()
+		if err != nil {
+			return err
+		}
+		_, err = opts.Invoker.InvokeLambda(ctx, h.LambdaARN, payload)
+		return err
+
+	case "agent":
+		agents := config.agentsNamed([]string{h.InvokeAgent})
+		if len(agents) == 0 {
+			return fmt.Errorf("agent %s not found", h.InvokeAgent)
+		}
+		if agents[0].RuntimeARN == "" {
+			return fmt.Errorf("agent %s has no runtimeArn", h.InvokeAgent)
+		}
+		payload, err := h.PayloadJSON()
+		if err != nil {
+			return err
+		}
+		_, err = opts.Invoker.InvokeAgent(ctx, agents[0].RuntimeARN, payload)
+		return err
+
+	default:
+		return fmt.Errorf("hook has no command, lambdaArn or invokeAgent")
+	}
+}
+
+// AWSCLIHookInvoker invokes hooks with the aws CLI.
+type AWSCLIHookInvoker struct{}
+
+// InvokeLambda invokes a function with "aws lambda invoke". A function
+// error is returned as an error.
+func (AWSCLIHookInvoker) InvokeLambda(ctx context.Context, functionARN string, payload []byte) ([]byte, error) {
+	var result struct {
+		FunctionError string
+	}
+	response, meta, err := awscli.RunOutfile(ctx, "lambda", "invoke",
+		"--function-name", functionARN,
+		"--cli-binary-format", "raw-in-base64-out",
+		"--payload", string(payload))
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(meta, &result); err == nil && result.FunctionError != "" {
+		return response, fmt.Errorf("function error (%s): %s", result.FunctionError, bytes.TrimSpace(response))
+	}
+	return response, nil
+}
+
+// InvokeAgent invokes an agent runtime with "aws bedrock-agentcore
+// invoke-agent-runtime".
+func (AWSCLIHookInvoker) InvokeAgent(ctx context.Context, runtimeARN string, payload []byte) ([]byte, error) {
+	response, _, err := awscli.RunOutfile(ctx, "bedrock-agentcore", "invoke-agent-runtime",
+		"--agent-runtime-arn", runtimeARN,
+		"--cli-binary-format", "raw-in-base64-out",
+		"--payload", string(payload))
+	return response, err
+}
@@ -0,0 +1,403 @@
+package iac
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// maxRoleNameLength is the AWS limit on IAM role names.
+const maxRoleNameLength = 64
+
+// ecrImagePattern matches ECR image URIs and captures the account, region
+// and repository name.
+var ecrImagePattern = regexp.MustCompile(`^(\d+)\.dkr\.ecr\.([a-z0-9-]+)\.amazonaws\.com(?:\.cn)?/([^:@]+)`)
+
+// secretSuffixPattern matches the random suffix of a complete Secrets Manager ARN.
+var secretSuffixPattern = regexp.MustCompile(`-[A-Za-z0-9]{6}$`)
+
+// inferenceProfilePrefixes are the geographic prefixes of cross-region
+// inference profile IDs, e.g. "us.anthropic.claude-3-5-sonnet-20241022-v2:0".
+var inferenceProfilePrefixes = []string{"us.", "eu.", "apac.", "us-gov.", "global."}
+
+// IAMStatement is an IAM policy statement added to the execution role.
+type IAMStatement struct {
+	// Sid is an optional statement ID.
+	Sid string `json:"sid,omitempty" yaml:"sid,omitempty"`
+
+	// Effect is "Allow" or "Deny".
+	// Default: "Allow"
+	Effect string `json:"effect,omitempty" yaml:"effect,omitempty"`
+
+	// Actions are the IAM actions, e.g. "dynamodb:GetItem".
+	// Required.
+	Actions []string `json:"actions" yaml:"actions"`
+
+	// Resources are the resource ARNs.
+	// Required.
+	Resources []string `json:"resources" yaml:"resources"`
+
+	// Condition is an optional IAM condition block.
+	// Example: {"StringEquals": {"aws:RequestedRegion": "us-east-1"}}
+	Condition map[string]map[string]interface{} `json:"condition,omitempty" yaml:"condition,omitempty"`
+}
+
+// AgentIAMConfig scopes the execution role permissions of a single agent.
+type AgentIAMConfig struct {
+	// BedrockModelIDs are the model or inference profile IDs the agent invokes.
+	// Default: IAMConfig.BedrockModelIDs
+	BedrockModelIDs []string `json:"bedrockModelIds,omitempty" yaml:"bedrockModelIds,omitempty"`
+
+	// AdditionalStatements are added to the agent's permissions, for
+	// access the agent needs beyond what agentkit derives.
+	AdditionalStatements []IAMStatement `json:"additionalStatements,omitempty" yaml:"additionalStatements,omitempty"`
+}
+
+// ExecutionRole is an agent execution role and its inline policy.
+type ExecutionRole struct {
+	// Agent is the agent that assumes the role, or empty for the role
+	// shared by all agents.
+	Agent string
+
+	// RoleName is the IAM role name.
+	RoleName string
+
+	// Statements are the statements of the inline AgentCorePolicy.
+	Statements []map[string]interface{}
+}
+
+// ExecutionRoles returns the execution roles of the stack: one role per
+// agent when IAM.PerAgentRoles is set, otherwise one role shared by all agents.
+func (c *StackConfig) ExecutionRoles() []ExecutionRole {
+	if !c.IAM.PerAgentRoles {
+		return []ExecutionRole{{
+			RoleName:   c.StackName + "-execution-role",
+			Statements: IAMPolicyStatements(c),
+		}}
+	}
+	roles := make([]ExecutionRole, 0, len(c.Agents))
+	for _, agent := range c.Agents {
+		roles = append(roles, ExecutionRole{
+			Agent:      agent.Name,
+			RoleName:   agentRoleName(c.StackName, agent.Name),
+			Statements: AgentIAMPolicyStatements(c, agent),
+		})
+	}
+	return roles
+}
+
+// agentRoleName returns the execution role name of an agent.
+func agentRoleName(stackName, agentName string) string {
+	return fmt.Sprintf("%s-%s-execution-role", stackName, agentName)
+}
+
+// IAMPolicyStatements returns the IAM policy statements for the execution
+// role shared by all agents. Generators for other IaC tools use this to stay
+// in sync with the CloudFormation output.
+func IAMPolicyStatements(config *StackConfig) []map[string]interface{} {
+	return buildIAMStatements(config)
+}
+
+// AgentIAMPolicyStatements returns the IAM policy statements for the
+// execution role of a single agent.
+func AgentIAMPolicyStatements(config *StackConfig, agent AgentConfig) []map[string]interface{} {
+	return agentIAMStatements(config, []AgentConfig{agent})
+}
+
+// buildIAMStatements builds the IAM policy statements for all agents.
+func buildIAMStatements(config *StackConfig) []map[string]interface{} {
+	return agentIAMStatements(config, config.Agents)
+}
+
+// agentIAMStatements builds IAM policy statements granting only what the
+// given agents declare they need.
+func agentIAMStatements(config *StackConfig, agents []AgentConfig) []map[string]interface{} {
+	statements := []map[string]interface{}{
+		// CloudWatch Logs
+		{
+			"Effect": "Allow",
+			"Action": []string{
+				"logs:CreateLogGroup",
+				"logs:CreateLogStream",
+				"logs:PutLogEvents",
+			},
+			"Resource": logResources(config, agents),
+		},
+		// ECR authentication is not resource-scoped.
+		{
+			"Effect":   "Allow",
+			"Action":   []string{"ecr:GetAuthorizationToken"},
+			"Resource": "*",
+		},
+		// ECR image pulls
+		{
+			"Effect": "Allow",
+			"Action": []string{
+				"ecr:BatchCheckLayerAvailability",
+				"ecr:GetDownloadUrlForLayer",
+				"ecr:BatchGetImage",
+			},
+			"Resource": ecrResources(agents),
+		},
+	}
+
+	// Bedrock access
+	if config.IAM.EnableBedrockAccess {
+		statements = append(statements, map[string]interface{}{
+			"Effect": "Allow",
+			"Action": []string{
+				"bedrock:InvokeModel",
+				"bedrock:InvokeModelWithResponseStream",
+			},
+			"Resource": bedrockResources(config, agents),
+		})
+	}
+
+	// Bedrock guardrails
+	if guardrail := guardrailIAMStatement(agents); guardrail != nil {
+		statements = append(statements, guardrail)
+	}
+
+	// AgentCore Memory access
+	if memory := memoryIAMStatement(agents); memory != nil {
+		statements = append(statements, memory)
+	}
+
+	// AgentCore Gateway access
+	if g := config.Gateway; g != nil && g.Enabled {
+		// Gateway IDs start with the lowercased gateway name.
+		statements = append(statements, map[string]interface{}{
+			"Effect":   "Allow",
+			"Action":   []string{"bedrock-agentcore:InvokeGateway"},
+			"Resource": fmt.Sprintf("arn:aws:bedrock-agentcore:*:*:gateway/%s-*", strings.ToLower(g.Name)),
+		})
+	}
+
+	// Artifact bucket access
+	statements = append(statements, artifactsIAMStatements(config, agents)...)
+
+	// Encrypted environment values
+	statements = append(statements, environmentIAMStatements(agents)...)
+
+	// Secrets Manager access
+	if secrets := secretResources(agents); len(secrets) > 0 {
+		statements = append(statements, map[string]interface{}{
+			"Effect": "Allow",
+			"Action": []string{
+				"secretsmanager:GetSecretValue",
+			},
+			"Resource": secrets,
+		})
+	}
+
+	// Additional statements
+	for _, s := range config.IAM.AdditionalStatements {
+		statements = append(statements, s.policyStatement())
+	}
+	for _, agent := range agents {
+		if agent.IAM == nil {
+			continue
+		}
+		for _, s := range agent.IAM.AdditionalStatements {
+			statements = append(statements, s.policyStatement())
+		}
+	}
+
+	return statements
+}
+
+// policyStatement returns the statement in IAM policy document form.
+func (s IAMStatement) policyStatement() map[string]interface{} {
+	effect := s.Effect
+	if effect == "" {
+		effect = "Allow"
+	}
+	statement := map[string]interface{}{
+		"Effect":   effect,
+		"Action":   s.Actions,
+		"Resource": s.Resources,
+	}
+	if s.Sid != "" {
+		statement["Sid"] = s.Sid
+	}
+	if len(s.Condition) > 0 {
+		statement["Condition"] = s.Condition
+	}
+	return statement
+}
+
+// logResources returns the log groups agents write to: the AgentCore
+// runtime log groups, the stack log group and the agents' own log groups.
+func logResources(config *StackConfig, agents []AgentConfig) []string {
+	resources := []string{"arn:aws:logs:*:*:log-group:/aws/bedrock-agentcore/runtimes/*"}
+	if config.Observability.EnableCloudWatchLogs {
+		resources = append(resources, fmt.Sprintf("arn:aws:logs:*:*:log-group:/aws/agentcore/%s:*", config.StackName))
+	}
+	for _, agent := range agents {
+		if agent.Logging != nil {
+			resources = append(resources, fmt.Sprintf("arn:aws:logs:*:*:log-group:%s:*", agent.Logging.LogGroupName))
+		}
+	}
+	return resources
+}
+
+// ecrResources returns the ECR repositories the agents pull images from.
+// Agents whose image is not a recognizable ECR URI make the statement
+// unscoped.
+func ecrResources(agents []AgentConfig) interface{} {
+	var resources []string
+	for _, agent := range agents {
+		arn := ecrRepositoryARN(agent)
+		if arn == "" {
+			return "*"
+		}
+		if !slices.Contains(resources, arn) {
+			resources = append(resources, arn)
+		}
+	}
+	return resources
+}
+
+// ecrRepositoryARN returns the ARN of the agent's image repository, or an
+// empty string if it cannot be determined.
+func ecrRepositoryARN(agent AgentConfig) string {
+	if agent.ContainerImage == "" && agent.ImageBuild != nil && agent.ImageBuild.Repository != nil {
+		return fmt.Sprintf("arn:aws:ecr:*:*:repository/%s", agent.ImageBuild.Repository.Name)
+	}
+	m := ecrImagePattern.FindStringSubmatch(agent.ContainerImage)
+	if m == nil {
+		return ""
+	}
+	return fmt.Sprintf("arn:aws:ecr:%s:%s:repository/%s", m[2], m[1], m[3])
+}
+
+// bedrockResources returns the models the agents may invoke. An agent
+// without a model list may invoke any model.
+func bedrockResources(config *StackConfig, agents []AgentConfig) interface{} {
+	var resources []string
+	for _, agent := range agents {
+		models := config.IAM.BedrockModelIDs
+		if agent.IAM != nil && len(agent.IAM.BedrockModelIDs) > 0 {
+			models = agent.IAM.BedrockModelIDs
+		}
+		if len(models) == 0 {
+			return "arn:aws:bedrock:*:*:foundation-model/*"
+		}
+		for _, model := range models {
+			for _, arn := range bedrockModelARNs(model) {
+				if !slices.Contains(resources, arn) {
+					resources = append(resources, arn)
+				}
+			}
+		}
+	}
+	return resources
+}
+
+// bedrockModelARNs returns the resource ARNs needed to invoke a model ID,
+// inference profile ID or ARN. Inference profiles also need the models
+// they route to, in any region.
+func bedrockModelARNs(model string) []string {
+	if strings.HasPrefix(model, "arn:") {
+		return []string{model}
+	}
+	for _, prefix := range inferenceProfilePrefixes {
+		if strings.HasPrefix(model, prefix) {
+			return []string{
+				fmt.Sprintf("arn:aws:bedrock:*:*:inference-profile/%s", model),
+				fmt.Sprintf("arn:aws:bedrock:*:*:foundation-model/%s", strings.TrimPrefix(model, prefix)),
+			}
+		}
+	}
+	return []string{fmt.Sprintf("arn:aws:bedrock:*:*:foundation-model/%s", model)}
+}
+
+// secretResources returns the secrets the agents read. Partial ARNs, without
+// the random suffix Secrets Manager appends, match any suffix.
+func secretResources(agents []AgentConfig) []string {
+	var resources []string
+	for _, agent := range agents {
+		for _, arn := range agent.SecretsARNs {
+			if !secretSuffixPattern.MatchString(arn) {
+				arn += "-??????"
+			}
+			if !slices.Contains(resources, arn) {
+				resources = append(resources, arn)
+			}
+		}
+	}
+	return resources
+}
+
+// validateIAMStatements adds errors for malformed additional statements.
+func validateIAMStatements(report *ValidationReport, path string, statements []IAMStatement) {
+	for i, s := range statements {
+		p := fmt.Sprintf("%s[%d]", path, i)
+		if s.Effect != "" && s.Effect != "Allow" && s.Effect != "Deny" {
+			report.addError(p+".effect", "%s: effect must be Allow or Deny", p)
+		}
+		if len(s.Actions) == 0 {
+			report.addError(p+".actions", "%s: actions are required", p)
+		}
+		for _, action := range s.Actions {
+			if action != "*" && !strings.Contains(action, ":") {
+				report.addError(p+".actions", "%s: action %q must be in service:Action form", p, action)
+			}
+		}
+		if len(s.Resources) == 0 {
+			report.addError(p+".resources", "%s: resources are required", p)
+		}
+	}
+}
+
+// validateAgentIAM adds IAM errors for the agent at index i.
+func validateAgentIAM(report *ValidationReport, i int, agent AgentConfig) {
+	if agent.IAM == nil {
+		return
+	}
+	path := fmt.Sprintf("agents[%d].iam", i)
+	if slices.Contains(agent.IAM.BedrockModelIDs, "") {
+		report.addError(path+".bedrockModelIds", "agents[%d] (%s): iam.bedrockModelIds must not contain empty IDs", i, agent.Name)
+	}
+	validateIAMStatements(report, path+".additionalStatements", agent.IAM.AdditionalStatements)
+}
+
+// validateIAM adds stack IAM errors to the report.
+func (c *StackConfig) validateIAM(report *ValidationReport) {
+	if c.IAM == nil {
+		return
+	}
+	validateIAMStatements(report, "iam.additionalStatements", c.IAM.AdditionalStatements)
+
+	if c.IAM.PerAgentRoles {
+		for i, agent := range c.Agents {
+			if name := agentRoleName(c.StackName, agent.Name); len(name) > maxRoleNameLength {
+				report.addError(fmt.Sprintf("agents[%d].name", i), "agents[%d] (%s): execution role name %s exceeds %d characters", i, agent.Name, name, maxRoleNameLength)
+			}
+		}
+	}
+}
+
+// validateIAMWarnings adds broad IAM grants to the report.
+func (c *StackConfig) validateIAMWarnings(report *ValidationReport) {
+	check := func(path string, statements []IAMStatement) {
+		for i, s := range statements {
+			if s.Effect == "Deny" {
+				continue
+			}
+			if slices.Contains(s.Actions, "*") || slices.Contains(s.Resources, "*") {
+				p := fmt.Sprintf("%s[%d]", path, i)
+				report.addWarning(p, "%s grants access to all actions or resources", p)
+			}
+		}
+	}
+	if c.IAM != nil {
+		check("iam.additionalStatements", c.IAM.AdditionalStatements)
+	}
+	for i, agent := range c.Agents {
+		if agent.IAM != nil {
+			check(fmt.Sprintf("agents[%d].iam.additionalStatements", i), agent.IAM.AdditionalStatements)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package iac
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ImageBuildConfig defines how an agent's container image is built and where
+// it is pushed.
+type ImageBuildConfig struct {
+	// Dockerfile is the path to the Dockerfile, relative to Context.
+	// Default: "Dockerfile"
+	Dockerfile string `json:"dockerfile,omitempty" yaml:"dockerfile,omitempty"`
+
+	// Context is the docker build context directory.
+	// Default: "."
+	Context string `json:"context,omitempty" yaml:"context,omitempty"`
+
+	// BuildArgs are passed to docker build as --build-arg KEY=VALUE.
+	BuildArgs map[string]string `json:"buildArgs,omitempty" yaml:"buildArgs,omitempty"`
+
+	// Platform is the target platform. AgentCore Runtime runs on ARM64.
+	// Default: "linux/arm64"
+	Platform string `json:"platform,omitempty" yaml:"platform,omitempty"`
+
+	// Tag is the image tag to push.
+	// Default: "latest"
+	Tag string `json:"tag,omitempty" yaml:"tag,omitempty"`
+
+	// Repository configures the ECR repository.
+	// Default: a repository named "{stack-name}-{agent-name}"
+	Repository *ECRRepositoryConfig `json:"repository,omitempty" yaml:"repository,omitempty"`
+}
+
+// ECRRepositoryConfig defines an ECR repository for agent images.
+type ECRRepositoryConfig struct {
+	// Name is the repository name.
+	// Default: "{stack-name}-{agent-name}"
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// Create creates the repository if true. If false, the repository must exist.
+	// Default: true
+	Create *bool `json:"create,omitempty" yaml:"create,omitempty"`
+
+	// ImageTagMutability is "MUTABLE" or "IMMUTABLE".
+	// Default: "MUTABLE"
+	ImageTagMutability string `json:"imageTagMutability,omitempty" yaml:"imageTagMutability,omitempty"`
+
+	// ScanOnPush enables image vulnerability scanning on push.
+	// Default: false
+	ScanOnPush bool `json:"scanOnPush,omitempty" yaml:"scanOnPush,omitempty"`
+
+	// KMSKeyARN encrypts images with a customer managed key.
+	// If empty, AES256 encryption is used.
+	KMSKeyARN string `json:"kmsKeyARN,omitempty" yaml:"kmsKeyARN,omitempty"`
+
+	// Lifecycle configures image expiration.
+	// Optional.
+	Lifecycle *ECRLifecyclePolicy `json:"lifecycle,omitempty" yaml:"lifecycle,omitempty"`
+}
+
+// ECRLifecyclePolicy defines image expiration rules for an ECR repository.
+type ECRLifecyclePolicy struct {
+	// MaxImageCount keeps only the most recent N tagged images. 0 disables the rule.
+	MaxImageCount int `json:"maxImageCount,omitempty" yaml:"maxImageCount,omitempty"`
+
+	// UntaggedExpireDays expires untagged images after N days. 0 disables the rule.
+	UntaggedExpireDays int `json:"untaggedExpireDays,omitempty" yaml:"untaggedExpireDays,omitempty"`
+}
+
+// ShouldCreate reports whether the repository should be created by the stack.
+func (r *ECRRepositoryConfig) ShouldCreate() bool {
+	return r.Create == nil || *r.Create
+}
+
+// PolicyText returns the ECR lifecycle policy as JSON text.
+// Returns an empty string if no rules are enabled.
+func (p *ECRLifecyclePolicy) PolicyText() string {
+	if p == nil {
+		return ""
+	}
+
+	var rules []string
+	priority := 1
+	if p.UntaggedExpireDays > 0 {
+		rules = append(rules, fmt.Sprintf(`{"rulePriority":%d,"description":"Expire untagged images","selection":{"tagStatus":"untagged","countType":"sinceImagePushed","countUnit":"days","countNumber":%d},"action":{"type":"expire"}}`,
+			priority, p.UntaggedExpireDays))
+		priority++
+	}
+	if p.MaxImageCount > 0 {
+		rules = append(rules, fmt.Sprintf(`{"rulePriority":%d,"description":"Keep last %d images","selection":{"tagStatus":"any","countType":"imageCountMoreThan","countNumber":%d},"action":{"type":"expire"}}`,
+			priority, p.MaxImageCount, p.MaxImageCount))
+	}
+
+	if len(rules) == 0 {
+		return ""
+	}
+	return `{"rules":[` + strings.Join(rules, ",") + `]}`
+}
+
+// applyImageBuildDefaults fills in ImageBuild defaults for an agent.
+func applyImageBuildDefaults(stackName string, agent *AgentConfig) {
+	b := agent.ImageBuild
+	if b == nil {
+		return
+	}
+	if b.Dockerfile == "" {
+		b.Dockerfile = "Dockerfile"
+	}
+	if b.Context == "" {
+		b.Context = "."
+	}
+	if b.Platform == "" {
+		b.Platform = "linux/arm64"
+	}
+	if b.Tag == "" {
+		b.Tag = "latest"
+	}
+	if b.Repository == nil {
+		b.Repository = &ECRRepositoryConfig{}
+	}
+	if b.Repository.Name == "" {
+		b.Repository.Name = fmt.Sprintf("%s-%s", stackName, agent.Name)
+	}
+	if b.Repository.ImageTagMutability == "" {
+		b.Repository.ImageTagMutability = "MUTABLE"
+	}
+}
+
+// validateImageBuild adds ImageBuild errors for the agent at index i.
+func validateImageBuild(report *ValidationReport, i int, agent AgentConfig) {
+	b := agent.ImageBuild
+	if b == nil {
+		return
+	}
+	path := fmt.Sprintf("agents[%d].imageBuild", i)
+
+	if b.Platform != "" && b.Platform != "linux/arm64" {
+		report.addError(path+".platform", "agents[%d] (%s): imageBuild.platform must be linux/arm64 for AgentCore", i, agent.Name)
+	}
+	if r := b.Repository; r != nil {
+		if r.ImageTagMutability != "" && r.ImageTagMutability != "MUTABLE" && r.ImageTagMutability != "IMMUTABLE" {
+			report.addError(path+".repository.imageTagMutability", "agents[%d] (%s): imageBuild.repository.imageTagMutability must be MUTABLE or IMMUTABLE", i, agent.Name)
+		}
+		if r.Lifecycle != nil && (r.Lifecycle.MaxImageCount < 0 || r.Lifecycle.UntaggedExpireDays < 0) {
+			report.addError(path+".repository.lifecycle", "agents[%d] (%s): imageBuild.repository.lifecycle values must not be negative", i, agent.Name)
+		}
+	}
+}
+
+// ImageRepositoryName returns the ECR repository name for an agent built by
+// the stack, or an empty string if the agent uses a pre-pushed image.
+func (a *AgentConfig) ImageRepositoryName() string {
+	if a.ImageBuild == nil || a.ImageBuild.Repository == nil {
+		return ""
+	}
+	return a.ImageBuild.Repository.Name
+}
+
+// addECRResources adds ECR repositories for agents with ImageBuild.
+func addECRResources(template *CloudFormationTemplate, config *StackConfig) {
+	for _, agent := range config.Agents {
+		if agent.ImageBuild == nil || !agent.ImageBuild.Repository.ShouldCreate() {
+			continue
+		}
+		repo := agent.ImageBuild.Repository
+		logicalID := fmt.Sprintf("%sRepository", toPascalCase(agent.Name))
+
+		encryption := map[string]interface{}{"EncryptionType": "AES256"}
+		if repo.KMSKeyARN != "" {
+			encryption = map[string]interface{}{"EncryptionType": "KMS", "KmsKey": repo.KMSKeyARN}
+		}
+
+		props := map[string]interface{}{
+			"RepositoryName":     repo.Name,
+			"ImageTagMutability": repo.ImageTagMutability,
+			"ImageScanningConfiguration": map[string]interface{}{
+				"ScanOnPush": repo.ScanOnPush,
+			},
+			"EncryptionConfiguration": encryption,
+			"Tags":                    cfTags(repo.Name, agent.Tags),
+		}
+		if policy := repo.Lifecycle.PolicyText(); policy != "" {
+			props["LifecyclePolicy"] = map[string]interface{}{"LifecyclePolicyText": policy}
+		}
+
+		deletionPolicy := "Delete"
+		if config.RemovalPolicy == "retain" {
+			deletionPolicy = "Retain"
+		}
+		template.Resources[logicalID] = CFResource{
+			Type:           "AWS::ECR::Repository",
+			DeletionPolicy: deletionPolicy,
+			Properties:     props,
+		}
+		template.Outputs[fmt.Sprintf("%sRepositoryURI", toPascalCase(agent.Name))] = CFOutput{
+			Description: fmt.Sprintf("ECR repository URI for %s agent", agent.Name),
+			Value:       map[string]interface{}{"Fn::GetAtt": []string{logicalID, "RepositoryUri"}},
+		}
+	}
+}
+
+// cfImageValue returns the CloudFormation value for an agent's image.
+func cfImageValue(agent AgentConfig) interface{} {
+	if agent.ContainerImage == "" && agent.ImageBuild != nil {
+		return map[string]interface{}{
+			"Fn::Sub": fmt.Sprintf("${AWS::AccountId}.dkr.ecr.${AWS::Region}.amazonaws.com/%s:%s",
+				agent.ImageBuild.Repository.Name, agent.ImageBuild.Tag),
+		}
+	}
+	return map[string]string{"Ref": fmt.Sprintf("%sContainerImage", toPascalCase(agent.Name))}
+}
+
+// buildSpec is an AWS CodeBuild buildspec.
+type buildSpec struct {
+	Version string          `yaml:"version"`
+	Phases  buildSpecPhases `yaml:"phases"`
+}
+
+// buildSpecPhases holds the buildspec phases in execution order.
+type buildSpecPhases struct {
+	PreBuild  buildSpecPhase `yaml:"pre_build"`
+	Build     buildSpecPhase `yaml:"build"`
+	PostBuild buildSpecPhase `yaml:"post_build"`
+}
+
+// buildSpecPhase is a single buildspec phase.
+type buildSpecPhase struct {
+	Commands []string `yaml:"commands"`
+}
+
+// GenerateBuildSpec generates an AWS CodeBuild buildspec that builds and
+// pushes the images for all agents with ImageBuild configured.
+// Run it on an ARM64 CodeBuild environment (or with buildx and QEMU).
+func GenerateBuildSpec(config *StackConfig) ([]byte, error) {
+	config.ApplyDefaults()
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	registry := "${AWS_ACCOUNT_ID}.dkr.ecr.${AWS_DEFAULT_REGION}.amazonaws.com"
+	spec := buildSpec{
+		Version: "0.2",
+		Phases: buildSpecPhases{
+			PreBuild: buildSpecPhase{Commands: []string{
+				"AWS_ACCOUNT_ID=$(aws sts get-caller-identity --query Account --output text)",
+				fmt.Sprintf("aws ecr get-login-password --region ${AWS_DEFAULT_REGION} | docker login --username AWS --password-stdin %s", registry),
+			}},
+		},
+	}
+
+	built := 0
+	for _, agent := range config.Agents {
+		b := agent.ImageBuild
+		if b == nil {
+			continue
+		}
+		built++
+		image := fmt.Sprintf("%s/%s:%s", registry, b.Repository.Name, b.Tag)
+
+		args := []string{"docker", "buildx", "build", "--platform", b.Platform, "-f", b.Context + "/" + b.Dockerfile, "-t", image}
+		keys := make([]string, 0, len(b.BuildArgs))
+		for k := range b.BuildArgs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			args = append(args, "--build-arg", fmt.Sprintf("%s=%s", k, b.BuildArgs[k]))
+		}
+		args = append(args, "--load", b.Context)
+
+		spec.Phases.Build.Commands = append(spec.Phases.Build.Commands,
+			fmt.Sprintf("echo Building %s", agent.Name), strings.Join(args, " "))
+		spec.Phases.PostBuild.Commands = append(spec.Phases.PostBuild.Commands,
+			fmt.Sprintf("docker push %s", image))
+	}
+
+	if built == 0 {
+		return nil, fmt.Errorf("no agents have imageBuild configured")
+	}
+
+	data, err := yaml.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate buildspec: %w", err)
+	}
+	return append([]byte("# CodeBuild buildspec generated by agentkit\n"), data...), nil
+}
+
+// GenerateBuildSpecFile generates a buildspec and writes it to outputPath.
+func GenerateBuildSpecFile(config *StackConfig, outputPath string) error {
+	spec, err := GenerateBuildSpec(config)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outputPath, spec, 0600)
+}
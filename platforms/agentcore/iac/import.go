@@ -0,0 +1,417 @@
+package iac
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ImportResult is a StackConfig reconstructed from an existing deployment.
+type ImportResult struct {
+	// Config is the imported configuration, without defaults applied.
+	Config *StackConfig
+
+	// Warnings describe resources and properties that could not be mapped
+	// and must be reviewed by hand.
+	Warnings []string
+}
+
+// YAML returns the imported configuration as a YAML config file.
+func (r *ImportResult) YAML() ([]byte, error) {
+	data, err := yaml.Marshal(r.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	return data, nil
+}
+
+func (r *ImportResult) warnf(format string, args ...any) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// ImportCloudFormation reconstructs a StackConfig from a CloudFormation
+// template in JSON or YAML, such as the output of
+// "aws cloudformation get-template". AgentCore runtimes, memories, the
+// log group and a created VPC are imported; other resources are reported
+// as warnings. Parameter references resolve to their default values.
+func ImportCloudFormation(stackName string, data []byte) (*ImportResult, error) {
+	var template struct {
+		Description string                            `yaml:"Description"`
+		Parameters  map[string]map[string]interface{} `yaml:"Parameters"`
+		Resources   map[string]struct {
+			Type       string                 `yaml:"Type"`
+			Properties map[string]interface{} `yaml:"Properties"`
+		} `yaml:"Resources"`
+	}
+	// YAML is a superset of JSON, so one decoder handles both formats.
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse CloudFormation template: %w", err)
+	}
+	expandIntrinsicTags(&root)
+	if err := root.Decode(&template); err != nil {
+		return nil, fmt.Errorf("failed to parse CloudFormation template: %w", err)
+	}
+	if len(template.Resources) == 0 {
+		return nil, fmt.Errorf("CloudFormation template has no resources")
+	}
+
+	result := &ImportResult{Config: &StackConfig{StackName: stackName, Description: template.Description}}
+	config := result.Config
+
+	// resolve returns the literal string value of a property, following
+	// Ref to parameter defaults.
+	resolve := func(v interface{}) (string, bool) {
+		switch t := v.(type) {
+		case string:
+			return t, true
+		case map[string]interface{}:
+			if ref, ok := t["Ref"].(string); ok {
+				if def, ok := template.Parameters[ref]["Default"].(string); ok {
+					return def, true
+				}
+			}
+		}
+		return "", false
+	}
+
+	ids := make([]string, 0, len(template.Resources))
+	for id := range template.Resources {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	roles := make(map[string]map[string]interface{})
+	stackRoles := make(map[string]bool)
+	var memories []string
+	for _, id := range ids {
+		res := template.Resources[id]
+		switch res.Type {
+		case "AWS::BedrockAgentCore::Runtime":
+			agent := importRuntime(result, id, res.Properties, resolve)
+			if role, ok := res.Properties["RoleArn"].(map[string]interface{}); ok {
+				// Fn::GetAtt is [role, "Arn"] or "role.Arn".
+				if getAtt := toStrings(role["Fn::GetAtt"]); len(getAtt) > 0 {
+					stackRoles[strings.SplitN(getAtt[0], ".", 2)[0]] = true
+				}
+			}
+			config.Agents = append(config.Agents, agent)
+		case "AWS::BedrockAgentCore::Memory":
+			memories = append(memories, id)
+		case "AWS::IAM::Role":
+			roles[id] = res.Properties
+		case "AWS::Logs::LogGroup":
+			if config.Observability == nil {
+				config.Observability = &ObservabilityConfig{EnableCloudWatchLogs: true}
+			}
+			if days, ok := res.Properties["RetentionInDays"].(int); ok {
+				config.Observability.LogRetentionDays = days
+			}
+		case "AWS::EC2::VPC":
+			if config.VPC == nil {
+				config.VPC = &VPCConfig{}
+			}
+			config.VPC.CreateVPC = true
+			config.VPC.VPCCidr, _ = resolve(res.Properties["CidrBlock"])
+		case "AWS::EC2::Subnet", "AWS::EC2::InternetGateway", "AWS::EC2::VPCGatewayAttachment",
+			"AWS::EC2::EIP", "AWS::EC2::NatGateway", "AWS::EC2::SecurityGroup",
+			"AWS::EC2::RouteTable", "AWS::EC2::Route", "AWS::EC2::SubnetRouteTableAssociation":
+			// Covered by vpc.createVPC.
+		default:
+			result.warnf("resource %s (%s) is not imported", id, res.Type)
+		}
+	}
+	if len(config.Agents) == 0 {
+		return nil, fmt.Errorf("CloudFormation template has no AWS::BedrockAgentCore::Runtime resources")
+	}
+
+	for _, id := range memories {
+		importMemory(result, id, template.Resources[id].Properties, resolve)
+	}
+
+	// The execution role created by the stack becomes the generated role.
+	for _, id := range ids {
+		props, ok := roles[id]
+		if !ok || !stackRoles[id] {
+			continue
+		}
+		if config.IAM == nil {
+			config.IAM = &IAMConfig{}
+		}
+		for _, arn := range toStrings(props["ManagedPolicyArns"]) {
+			if !slices.Contains(config.IAM.AdditionalPolicies, arn) {
+				config.IAM.AdditionalPolicies = append(config.IAM.AdditionalPolicies, arn)
+			}
+		}
+		if boundary, ok := resolve(props["PermissionsBoundary"]); ok {
+			config.IAM.PermissionsBoundaryARN = boundary
+		}
+		if props["Policies"] != nil {
+			result.warnf("inline policies of role %s are not imported; the generated role grants the agentkit defaults", id)
+		}
+	}
+
+	result.addValidationWarnings()
+	return result, nil
+}
+
+// ImportAgentRuntimes reconstructs a StackConfig from AgentCore runtime
+// descriptions, as returned by "aws bedrock-agentcore-control get-agent-runtime".
+// Each element of runtimes is one JSON response.
+func ImportAgentRuntimes(stackName string, runtimes ...[]byte) (*ImportResult, error) {
+	if len(runtimes) == 0 {
+		return nil, fmt.Errorf("at least one agent runtime is required")
+	}
+
+	result := &ImportResult{Config: &StackConfig{StackName: stackName}}
+	resolve := func(v interface{}) (string, bool) {
+		s, ok := v.(string)
+		return s, ok
+	}
+	for i, data := range runtimes {
+		var props map[string]interface{}
+		if err := json.Unmarshal(data, &props); err != nil {
+			return nil, fmt.Errorf("failed to parse agent runtime %d: %w", i, err)
+		}
+		agent := importRuntime(result, fmt.Sprintf("runtime %d", i), props, resolve)
+		agent.RuntimeARN, _ = resolve(field(props, "agentRuntimeArn"))
+		result.Config.Agents = append(result.Config.Agents, agent)
+	}
+
+	result.addValidationWarnings()
+	return result, nil
+}
+
+// expandIntrinsicTags rewrites short-form intrinsic functions such as
+// "!Ref Param" to their long form, {"Ref": "Param"}.
+func expandIntrinsicTags(n *yaml.Node) {
+	for _, child := range n.Content {
+		expandIntrinsicTags(child)
+	}
+	if !strings.HasPrefix(n.Tag, "!") || strings.HasPrefix(n.Tag, "!!") {
+		return
+	}
+
+	key := "Fn::" + n.Tag[1:]
+	if n.Tag == "!Ref" {
+		key = "Ref"
+	}
+	value := *n
+	value.Tag = ""
+	*n = yaml.Node{
+		Kind:    yaml.MappingNode,
+		Tag:     "!!map",
+		Content: []*yaml.Node{{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, &value},
+	}
+}
+
+// importRuntime maps an AgentCore runtime to an AgentConfig. props may use
+// the CloudFormation (PascalCase) or API (camelCase) property names.
+func importRuntime(result *ImportResult, id string, props map[string]interface{}, resolve func(interface{}) (string, bool)) AgentConfig {
+	config := result.Config
+	str := func(key string) string {
+		s, _ := resolve(field(props, key))
+		return s
+	}
+
+	agent := AgentConfig{
+		Name:        str("AgentRuntimeName"),
+		Description: str("Description"),
+	}
+	if agent.Name == "" {
+		agent.Name = strings.ToLower(id)
+		result.warnf("%s: agent runtime name is not a literal; using %q", id, agent.Name)
+	}
+
+	artifact, _ := field(props, "AgentRuntimeArtifact").(map[string]interface{})
+	container, _ := field(artifact, "ContainerConfiguration").(map[string]interface{})
+	if uri, ok := resolve(field(container, "ContainerUri")); ok {
+		agent.ContainerImage = uri
+	} else {
+		result.warnf("%s (%s): container image is not a literal and must be set by hand", id, agent.Name)
+	}
+
+	switch p := field(props, "ProtocolConfiguration").(type) {
+	case string:
+		agent.Protocol = p
+	case map[string]interface{}:
+		agent.Protocol, _ = resolve(field(p, "ServerProtocol"))
+	}
+
+	if env, ok := field(props, "EnvironmentVariables").(map[string]interface{}); ok {
+		agent.Environment = make(map[string]string)
+		keys := make([]string, 0, len(env))
+		for k := range env {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if s, ok := resolve(env[k]); ok {
+				agent.Environment[k] = s
+			} else {
+				result.warnf("%s (%s): environment variable %s is not a literal and is skipped", id, agent.Name, k)
+			}
+		}
+	}
+
+	if auth, ok := field(props, "AuthorizerConfiguration").(map[string]interface{}); ok {
+		if jwt, ok := field(auth, "CustomJWTAuthorizer").(map[string]interface{}); ok {
+			discovery, _ := resolve(field(jwt, "DiscoveryUrl"))
+			agent.Authorizer = &AuthorizerConfig{
+				Type: "JWT",
+				JWT: &JWTAuthorizerConfig{
+					DiscoveryURL:     discovery,
+					AllowedAudiences: toStrings(field(jwt, "AllowedAudience")),
+					AllowedClients:   toStrings(field(jwt, "AllowedClients")),
+				},
+			}
+		}
+	}
+
+	if network, ok := field(props, "NetworkConfiguration").(map[string]interface{}); ok {
+		if mode, _ := resolve(field(network, "NetworkMode")); mode == "VPC" {
+			modeConfig, _ := field(network, "NetworkModeConfig").(map[string]interface{})
+			if config.VPC == nil {
+				config.VPC = &VPCConfig{}
+			}
+			if subnets := toStrings(field(modeConfig, "Subnets")); len(subnets) > 0 && !config.VPC.CreateVPC {
+				config.VPC.SubnetIDs = subnets
+				config.VPC.SecurityGroupIDs = toStrings(field(modeConfig, "SecurityGroups"))
+				result.warnf("%s (%s): runs in an existing VPC; set vpc.vpcId", id, agent.Name)
+			}
+		}
+	}
+
+	if role, ok := resolve(field(props, "RoleArn")); ok {
+		if config.IAM == nil {
+			config.IAM = &IAMConfig{}
+		}
+		if config.IAM.RoleARN != "" && config.IAM.RoleARN != role {
+			result.warnf("%s (%s): uses role %s, but agentkit uses one execution role per stack", id, agent.Name, role)
+		} else {
+			config.IAM.RoleARN = role
+		}
+	}
+
+	agent.Tags = importTags(field(props, "Tags"))
+	return agent
+}
+
+// importMemory attaches an AgentCore Memory to the agent it belongs to.
+// A memory belongs to the only agent, or to the agent whose name it contains.
+func importMemory(result *ImportResult, id string, props map[string]interface{}, resolve func(interface{}) (string, bool)) {
+	agents := result.Config.Agents
+	name, _ := resolve(field(props, "Name"))
+
+	var owner *AgentConfig
+	if len(agents) == 1 {
+		owner = &agents[0]
+	} else {
+		normalized := strings.ToLower(strings.ReplaceAll(name, "_", "-"))
+		for i := range agents {
+			if strings.Contains(normalized, strings.ToLower(agents[i].Name)) {
+				owner = &agents[i]
+				break
+			}
+		}
+	}
+	if owner == nil || owner.Memory != nil {
+		result.warnf("memory %s (%s) could not be matched to an agent", id, name)
+		return
+	}
+
+	memory := &MemoryConfig{Name: name}
+	memory.EncryptionKeyARN, _ = resolve(field(props, "EncryptionKeyArn"))
+	if days, ok := field(props, "EventExpiryDuration").(int); ok {
+		memory.EventExpiryDays = days
+	}
+	for _, s := range toSlice(field(props, "MemoryStrategies")) {
+		strategy, _ := s.(map[string]interface{})
+		for _, candidate := range ValidMemoryStrategies() {
+			body, ok := strategy[memoryStrategyKey(candidate)].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			memory.Strategies = append(memory.Strategies, candidate)
+			if len(memory.Namespaces) == 0 {
+				memory.Namespaces = toStrings(body["Namespaces"])
+			}
+		}
+	}
+	owner.EnableMemory = true
+	owner.Memory = memory
+}
+
+// importTags converts CloudFormation or API tags to a tag map, dropping the
+// tags agentkit adds itself.
+func importTags(v interface{}) map[string]string {
+	tags := make(map[string]string)
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, val := range t {
+			if s, ok := val.(string); ok {
+				tags[k] = s
+			}
+		}
+	case []interface{}:
+		for _, item := range t {
+			tag, _ := item.(map[string]interface{})
+			k, _ := tag["Key"].(string)
+			val, _ := tag["Value"].(string)
+			if k != "" {
+				tags[k] = val
+			}
+		}
+	}
+	delete(tags, "Name")
+	delete(tags, "ManagedBy")
+	if len(tags) == 0 {
+		return nil
+	}
+	return tags
+}
+
+// addValidationWarnings reports validation errors of the imported config.
+func (r *ImportResult) addValidationWarnings() {
+	config, err := copyWithDefaults(r.Config)
+	if err != nil {
+		return
+	}
+	for _, issue := range config.ValidateReport(ValidateOptions{}).Errors {
+		r.warnf("imported config is invalid: %s", issue.Error())
+	}
+}
+
+// field returns the value of key in m, matching the first letter case-insensitively
+// so CloudFormation and API property names both match.
+func field(m map[string]interface{}, key string) interface{} {
+	if v, ok := m[key]; ok {
+		return v
+	}
+	return m[strings.ToLower(key[:1])+key[1:]]
+}
+
+// toSlice returns v as a list, or nil.
+func toSlice(v interface{}) []interface{} {
+	s, _ := v.([]interface{})
+	return s
+}
+
+// toStrings returns the string elements of a list, or a single string as a
+// one-element list.
+func toStrings(v interface{}) []string {
+	if s, ok := v.(string); ok {
+		return []string{s}
+	}
+	var out []string
+	for _, item := range toSlice(v) {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,208 @@
+package iac
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config files may pull fragments from other files with an "include" key,
+// so agent definitions and shared settings live in their own files:
+//
+//	vpc:
+//	  include: ../shared/vpc.yaml     # object fragment, merged under vpc
+//	agents:
+//	  - include: agents/*.yaml        # each file is an agent (or a list of agents)
+//	  - name: local-agent
+//	    include: agents/base.yaml     # keys here override the fragment
+//
+// The include value is a path or list of paths, relative to the including
+// file, and may contain glob patterns. Matches are loaded in sorted order.
+// In an object, included objects are deep-merged in order and the object's
+// own keys are merged over them. A list item with only an include key is
+// replaced by the included fragments; list fragments are spliced in.
+// Fragments may include other files; cycles are an error.
+
+// IncludeKey is the config key that includes other files.
+const IncludeKey = "include"
+
+// includeResolver resolves includes, tracking the chain of files being
+// resolved to detect cycles.
+type includeResolver struct {
+	chain []string
+}
+
+// resolveIncludes resolves the includes in a config read from path.
+func resolveIncludes(raw map[string]interface{}, path string) (map[string]interface{}, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	r := &includeResolver{chain: []string{abs}}
+	return r.resolveMap(raw, filepath.Dir(abs))
+}
+
+// resolveValue resolves the includes in v, with relative paths taken from dir.
+func (r *includeResolver) resolveValue(v interface{}, dir string) (interface{}, error) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return r.resolveMap(v, dir)
+	case []interface{}:
+		return r.resolveList(v, dir)
+	default:
+		return v, nil
+	}
+}
+
+// resolveMap resolves the includes in an object.
+func (r *includeResolver) resolveMap(m map[string]interface{}, dir string) (map[string]interface{}, error) {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if k == IncludeKey {
+			continue
+		}
+		value, err := r.resolveValue(v, dir)
+		if err != nil {
+			return nil, err
+		}
+		out[k] = value
+	}
+
+	spec, ok := m[IncludeKey]
+	if !ok {
+		return out, nil
+	}
+	fragments, err := r.loadFragments(spec, dir)
+	if err != nil {
+		return nil, err
+	}
+	merged := make(map[string]interface{})
+	for _, f := range fragments {
+		fm, ok := f.value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: an object can only include objects", f.path)
+		}
+		merged = MergeConfigData(merged, fm)
+	}
+	return MergeConfigData(merged, out), nil
+}
+
+// resolveList resolves the includes in a list.
+func (r *includeResolver) resolveList(list []interface{}, dir string) ([]interface{}, error) {
+	out := make([]interface{}, 0, len(list))
+	for _, item := range list {
+		if m, ok := item.(map[string]interface{}); ok && len(m) == 1 && m[IncludeKey] != nil {
+			fragments, err := r.loadFragments(m[IncludeKey], dir)
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range fragments {
+				if items, ok := f.value.([]interface{}); ok {
+					out = append(out, items...)
+				} else {
+					out = append(out, f.value)
+				}
+			}
+			continue
+		}
+		value, err := r.resolveValue(item, dir)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, value)
+	}
+	return out, nil
+}
+
+// fragment is a resolved included file.
+type fragment struct {
+	path  string
+	value interface{}
+}
+
+// loadFragments loads and resolves the files named by an include value.
+func (r *includeResolver) loadFragments(spec interface{}, dir string) ([]fragment, error) {
+	var patterns []string
+	switch spec := spec.(type) {
+	case string:
+		patterns = []string{spec}
+	case []interface{}:
+		for _, p := range spec {
+			s, ok := p.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s must be a path or list of paths", IncludeKey)
+			}
+			patterns = append(patterns, s)
+		}
+	default:
+		return nil, fmt.Errorf("%s must be a path or list of paths", IncludeKey)
+	}
+
+	var fragments []fragment
+	for _, pattern := range patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(dir, pattern)
+		}
+		paths, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s pattern %s: %w", IncludeKey, pattern, err)
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("%s %s matches no files", IncludeKey, pattern)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			value, err := r.loadFragment(path)
+			if err != nil {
+				return nil, err
+			}
+			fragments = append(fragments, fragment{path: path, value: value})
+		}
+	}
+	return fragments, nil
+}
+
+// loadFragment reads an included file and resolves its own includes.
+func (r *includeResolver) loadFragment(path string) (interface{}, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	for i, p := range r.chain {
+		if p == abs {
+			cycle := append(append([]string{}, r.chain[i:]...), abs)
+			return nil, fmt.Errorf("%s cycle: %s", IncludeKey, strings.Join(cycle, " -> "))
+		}
+	}
+
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read included file: %w", err)
+	}
+	var value interface{}
+	switch ext := strings.ToLower(filepath.Ext(abs)); ext {
+	case ".json":
+		if err := json.Unmarshal(data, &value); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON fragment %s: %w", path, err)
+		}
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &value); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML fragment %s: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported fragment format: %s (use .json, .yaml, or .yml)", path)
+	}
+
+	r.chain = append(r.chain, abs)
+	defer func() { r.chain = r.chain[:len(r.chain)-1] }()
+	resolved, err := r.resolveValue(value, filepath.Dir(abs))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return resolved, nil
+}
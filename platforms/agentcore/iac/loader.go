@@ -12,22 +12,20 @@ import (
 )
 
 // LoadStackConfigFromFile loads a StackConfig from a JSON or YAML file.
-// The file format is auto-detected from the extension.
+// The file format is auto-detected from the extension. Included files are
+// merged in, and placeholders such as ${env:VAR} and ${aws:region} are
+// resolved before parsing.
 func LoadStackConfigFromFile(path string) (*StackConfig, error) {
-	data, err := os.ReadFile(path)
+	raw, err := readRawConfig(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		return nil, err
 	}
 
-	ext := strings.ToLower(filepath.Ext(path))
-	switch ext {
-	case ".json":
-		return LoadStackConfigFromJSON(data)
-	case ".yaml", ".yml":
-		return LoadStackConfigFromYAML(data)
-	default:
-		return nil, fmt.Errorf("unsupported file format: %s (use .json, .yaml, or .yml)", ext)
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
 	}
+	return LoadStackConfigFromJSON(data)
 }
 
 // LoadStackConfigFromJSON parses a StackConfig from JSON data.
@@ -0,0 +1,149 @@
+package iac
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// EnvLogGroup is the environment variable naming an agent's own log group.
+const EnvLogGroup = "AGENTCORE_LOG_GROUP"
+
+// logGroupNamePattern is the CloudWatch Logs log group name constraint.
+var logGroupNamePattern = regexp.MustCompile(`^[.\-_/#A-Za-z0-9]{1,512}$`)
+
+// AgentLoggingConfig gives an agent its own CloudWatch log group, for
+// agents whose logs need a different retention or encryption than the
+// stack log group (for example, agents handling PII). The log group name
+// is injected into the agent environment as AGENTCORE_LOG_GROUP.
+type AgentLoggingConfig struct {
+	// LogGroupName is the log group name.
+	// Default: "/aws/agentcore/{stack-name}/{agent-name}"
+	LogGroupName string `json:"logGroupName,omitempty" yaml:"logGroupName,omitempty"`
+
+	// RetentionDays is the log retention period.
+	// Supported: the CloudWatch Logs retention values (1, 3, 5, 7, 14, 30, ...)
+	// Default: observability.logRetentionDays
+	RetentionDays int `json:"retentionDays,omitempty" yaml:"retentionDays,omitempty"`
+
+	// KMSKeyARN encrypts the log group with a customer managed key. The key
+	// policy must allow the CloudWatch Logs service principal to use it.
+	// Optional.
+	KMSKeyARN string `json:"kmsKeyArn,omitempty" yaml:"kmsKeyArn,omitempty"`
+}
+
+// ValidLogRetentionDays returns the list of valid log retention periods in days.
+func ValidLogRetentionDays() []int {
+	return []int{1, 3, 5, 7, 14, 30, 60, 90, 120, 150, 180, 365, 400, 545, 731, 1096, 1827, 2192, 2557, 2922, 3288, 3653}
+}
+
+// LoggingAgents returns the agents that have their own log group.
+func (c *StackConfig) LoggingAgents() []AgentConfig {
+	var agents []AgentConfig
+	for _, agent := range c.Agents {
+		if agent.Logging != nil {
+			agents = append(agents, agent)
+		}
+	}
+	return agents
+}
+
+// applyLoggingDefaults fills in agent Logging defaults and injects each
+// agent's log group into its environment. Variables already set by the
+// agent are kept.
+func (c *StackConfig) applyLoggingDefaults() {
+	for i := range c.Agents {
+		agent := &c.Agents[i]
+		l := agent.Logging
+		if l == nil {
+			continue
+		}
+		if l.LogGroupName == "" {
+			l.LogGroupName = fmt.Sprintf("/aws/agentcore/%s/%s", c.StackName, agent.Name)
+		}
+		if l.RetentionDays == 0 {
+			l.RetentionDays = c.Observability.LogRetentionDays
+		}
+		if l.RetentionDays == 0 {
+			l.RetentionDays = DefaultObservabilityConfig().LogRetentionDays
+		}
+		if _, ok := agent.Environment[EnvLogGroup]; !ok {
+			agent.Environment[EnvLogGroup] = l.LogGroupName
+		}
+	}
+}
+
+// validateLogging adds agent Logging errors to the report.
+func validateLogging(report *ValidationReport, i int, agent AgentConfig) {
+	l := agent.Logging
+	if l == nil {
+		return
+	}
+	path := fmt.Sprintf("agents[%d].logging", i)
+	if l.LogGroupName != "" && !logGroupNamePattern.MatchString(l.LogGroupName) {
+		report.addError(path+".logGroupName", "agents[%d] (%s): logging.logGroupName must be 1-512 letters, digits and '._-/#': %s", i, agent.Name, l.LogGroupName)
+	}
+	if l.RetentionDays != 0 && !slices.Contains(ValidLogRetentionDays(), l.RetentionDays) {
+		report.addError(path+".retentionDays", "agents[%d] (%s): logging.retentionDays must be one of %v", i, agent.Name, ValidLogRetentionDays())
+	}
+	if l.KMSKeyARN != "" && !strings.HasPrefix(l.KMSKeyARN, "arn:aws:kms:") {
+		report.addError(path+".kmsKeyArn", "agents[%d] (%s): logging.kmsKeyArn must be a KMS key ARN", i, agent.Name)
+	}
+}
+
+// validateLogGroups adds errors for agent log groups that share a name with
+// each other or with the stack log group.
+func (c *StackConfig) validateLogGroups(report *ValidationReport) {
+	names := make(map[string]bool)
+	if c.Observability != nil && c.Observability.EnableCloudWatchLogs {
+		names[fmt.Sprintf("/aws/agentcore/%s", c.StackName)] = true
+	}
+	for i, agent := range c.Agents {
+		if agent.Logging == nil || agent.Logging.LogGroupName == "" {
+			continue
+		}
+		if names[agent.Logging.LogGroupName] {
+			report.addError(fmt.Sprintf("agents[%d].logging.logGroupName", i), "agents[%d] (%s): duplicate log group name: %s", i, agent.Name, agent.Logging.LogGroupName)
+		}
+		names[agent.Logging.LogGroupName] = true
+	}
+}
+
+// agentLogGroupLogicalID returns the CloudFormation logical ID of an
+// agent's log group.
+func agentLogGroupLogicalID(agent AgentConfig) string {
+	return toPascalCase(agent.Name) + "LogGroup"
+}
+
+// addLoggingResources adds a log group for each agent with its own logging.
+func addLoggingResources(template *CloudFormationTemplate, config *StackConfig) {
+	deletionPolicy := "Delete"
+	if config.RemovalPolicy == "retain" {
+		deletionPolicy = "Retain"
+	}
+	for _, agent := range config.LoggingAgents() {
+		l := agent.Logging
+		logicalID := agentLogGroupLogicalID(agent)
+		props := map[string]interface{}{
+			"LogGroupName":    l.LogGroupName,
+			"RetentionInDays": l.RetentionDays,
+			"Tags": []map[string]interface{}{
+				{"Key": "Name", "Value": fmt.Sprintf("%s-%s-logs", config.StackName, agent.Name)},
+				{"Key": "ManagedBy", "Value": "agentkit"},
+			},
+		}
+		if l.KMSKeyARN != "" {
+			props["KmsKeyId"] = l.KMSKeyARN
+		}
+		template.Resources[logicalID] = CFResource{
+			Type:           "AWS::Logs::LogGroup",
+			DeletionPolicy: deletionPolicy,
+			Properties:     props,
+		}
+		template.Outputs[logicalID+"Name"] = CFOutput{
+			Description: fmt.Sprintf("CloudWatch Log Group Name for %s agent", agent.Name),
+			Value:       map[string]string{"Ref": logicalID},
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package iac
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Memory strategy types supported by AgentCore Memory.
+const (
+	MemoryStrategySemantic       = "SEMANTIC"
+	MemoryStrategySummarization  = "SUMMARIZATION"
+	MemoryStrategyUserPreference = "USER_PREFERENCE"
+)
+
+// memoryNamePattern is the AgentCore Memory name constraint.
+var memoryNamePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]{0,47}$`)
+
+// MemoryConfig defines the AgentCore Memory resource for an agent.
+// Used when AgentConfig.EnableMemory is true.
+type MemoryConfig struct {
+	// Name is the memory resource name. Letters, digits and underscores only.
+	// Default: "{stack_name}_{agent_name}"
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// Strategies are the long-term memory extraction strategies.
+	// Supported: "SEMANTIC", "SUMMARIZATION", "USER_PREFERENCE"
+	// Default: ["SEMANTIC"]
+	Strategies []string `json:"strategies,omitempty" yaml:"strategies,omitempty"`
+
+	// EventExpiryDays is how long short-term memory events are kept.
+	// Range: 7-365
+	// Default: 90
+	EventExpiryDays int `json:"eventExpiryDays,omitempty" yaml:"eventExpiryDays,omitempty"`
+
+	// Namespaces are the namespaces extracted records are stored under.
+	// May reference {actorId}, {sessionId} and {strategyId}.
+	// Default: ["/strategies/{strategyId}/actors/{actorId}"]
+	Namespaces []string `json:"namespaces,omitempty" yaml:"namespaces,omitempty"`
+
+	// EncryptionKeyARN encrypts memory with a customer managed KMS key.
+	// Optional.
+	EncryptionKeyARN string `json:"encryptionKeyArn,omitempty" yaml:"encryptionKeyArn,omitempty"`
+}
+
+// ValidMemoryStrategies returns the list of valid memory strategy types.
+func ValidMemoryStrategies() []string {
+	return []string{MemoryStrategySemantic, MemoryStrategySummarization, MemoryStrategyUserPreference}
+}
+
+// MemoryAgents returns the agents that have memory enabled.
+func (c *StackConfig) MemoryAgents() []AgentConfig {
+	var agents []AgentConfig
+	for _, agent := range c.Agents {
+		if agent.EnableMemory {
+			agents = append(agents, agent)
+		}
+	}
+	return agents
+}
+
+// memoryStrategyKey maps a strategy type to its CloudFormation property name.
+func memoryStrategyKey(strategy string) string {
+	switch strategy {
+	case MemoryStrategySummarization:
+		return "SummaryMemoryStrategy"
+	case MemoryStrategyUserPreference:
+		return "UserPreferenceMemoryStrategy"
+	default:
+		return "SemanticMemoryStrategy"
+	}
+}
+
+// applyMemoryDefaults fills in Memory defaults for an agent.
+// Setting Memory implies EnableMemory.
+func applyMemoryDefaults(stackName string, agent *AgentConfig) {
+	if agent.Memory != nil {
+		agent.EnableMemory = true
+	}
+	if !agent.EnableMemory {
+		return
+	}
+	if agent.Memory == nil {
+		agent.Memory = &MemoryConfig{}
+	}
+	m := agent.Memory
+	if m.Name == "" {
+		m.Name = memoryName(stackName, agent.Name)
+	}
+	if len(m.Strategies) == 0 {
+		m.Strategies = []string{MemoryStrategySemantic}
+	}
+	if m.EventExpiryDays == 0 {
+		m.EventExpiryDays = 90
+	}
+	if len(m.Namespaces) == 0 {
+		m.Namespaces = []string{"/strategies/{strategyId}/actors/{actorId}"}
+	}
+}
+
+// memoryName derives a valid memory name from the stack and agent names.
+func memoryName(stackName, agentName string) string {
+	isLetter := func(r rune) bool { return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') }
+	name := strings.Map(func(r rune) rune {
+		if isLetter(r) || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, stackName+"_"+agentName)
+	if !isLetter(rune(name[0])) {
+		name = "m" + name
+	}
+	if len(name) > 48 {
+		name = name[:48]
+	}
+	return name
+}
+
+// validateMemory adds Memory errors for the agent at index i.
+func validateMemory(report *ValidationReport, i int, agent AgentConfig) {
+	m := agent.Memory
+	if m == nil {
+		return
+	}
+	path := fmt.Sprintf("agents[%d].memory", i)
+
+	if m.Name != "" && !memoryNamePattern.MatchString(m.Name) {
+		report.addError(path+".name", "agents[%d] (%s): memory.name must start with a letter and contain only letters, digits and underscores (max 48)", i, agent.Name)
+	}
+	for _, strategy := range m.Strategies {
+		if !slices.Contains(ValidMemoryStrategies(), strategy) {
+			report.addError(path+".strategies", "agents[%d] (%s): memory.strategies must be one of %v", i, agent.Name, ValidMemoryStrategies())
+			break
+		}
+	}
+	if m.EventExpiryDays != 0 && (m.EventExpiryDays < 7 || m.EventExpiryDays > 365) {
+		report.addError(path+".eventExpiryDays", "agents[%d] (%s): memory.eventExpiryDays must be between 7 and 365", i, agent.Name)
+	}
+}
+
+// memoryIAMStatement returns the execution role statement granting access to
+// the stack's memory resources, or nil if no agent uses memory.
+func memoryIAMStatement(agents []AgentConfig) map[string]interface{} {
+	var resources []string
+	for _, agent := range agents {
+		if !agent.EnableMemory || agent.Memory == nil {
+			continue
+		}
+		// Memory ARNs end in "{name}-{generated suffix}".
+		resources = append(resources, fmt.Sprintf("arn:aws:bedrock-agentcore:*:*:memory/%s-*", agent.Memory.Name))
+	}
+	if len(resources) == 0 {
+		return nil
+	}
+	return map[string]interface{}{
+		"Effect": "Allow",
+		"Action": []string{
+			"bedrock-agentcore:CreateEvent",
+			"bedrock-agentcore:GetEvent",
+			"bedrock-agentcore:ListEvents",
+			"bedrock-agentcore:DeleteEvent",
+			"bedrock-agentcore:ListSessions",
+			"bedrock-agentcore:ListActors",
+			"bedrock-agentcore:GetMemoryRecord",
+			"bedrock-agentcore:ListMemoryRecords",
+			"bedrock-agentcore:RetrieveMemoryRecords",
+		},
+		"Resource": resources,
+	}
+}
+
+// addMemoryResources adds AgentCore Memory resources for agents with memory enabled.
+func addMemoryResources(template *CloudFormationTemplate, config *StackConfig) {
+	for _, agent := range config.MemoryAgents() {
+		m := agent.Memory
+		logicalID := fmt.Sprintf("%sMemory", toPascalCase(agent.Name))
+
+		strategies := make([]map[string]interface{}, 0, len(m.Strategies))
+		for _, strategy := range m.Strategies {
+			strategies = append(strategies, map[string]interface{}{
+				memoryStrategyKey(strategy): map[string]interface{}{
+					"Name":       strings.ToLower(strategy),
+					"Namespaces": m.Namespaces,
+				},
+			})
+		}
+
+		props := map[string]interface{}{
+			"Name":                m.Name,
+			"Description":         fmt.Sprintf("Memory for %s agent", agent.Name),
+			"EventExpiryDuration": m.EventExpiryDays,
+			"MemoryStrategies":    strategies,
+			"Tags":                MergeTags(agent.Tags, map[string]string{"Name": m.Name, "ManagedBy": "agentkit"}),
+		}
+		if m.EncryptionKeyARN != "" {
+			props["EncryptionKeyArn"] = m.EncryptionKeyARN
+		}
+
+		deletionPolicy := "Delete"
+		if config.RemovalPolicy == "retain" {
+			deletionPolicy = "Retain"
+		}
+		template.Resources[logicalID] = CFResource{
+			Type:           "AWS::BedrockAgentCore::Memory",
+			DeletionPolicy: deletionPolicy,
+			Properties:     props,
+		}
+		template.Outputs[fmt.Sprintf("%sMemoryID", toPascalCase(agent.Name))] = CFOutput{
+			Description: fmt.Sprintf("AgentCore Memory ID for %s agent", agent.Name),
+			Value:       map[string]interface{}{"Fn::GetAtt": []string{logicalID, "MemoryId"}},
+		}
+	}
+}
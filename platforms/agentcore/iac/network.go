@@ -0,0 +1,158 @@
+package iac
+
+import (
+	"fmt"
+	"net/netip"
+	"regexp"
+)
+
+// Subnets created in a new VPC. They must lie within VPCConfig.VPCCidr.
+const (
+	PublicSubnetCIDR  = "10.0.1.0/24"
+	PrivateSubnetCIDR = "10.0.10.0/24"
+)
+
+// AWS VPC CIDR block size limits.
+const (
+	minVPCPrefixBits = 16
+	maxVPCPrefixBits = 28
+)
+
+// maxAZs is the largest number of availability zones in an AWS region.
+const maxAZs = 6
+
+// AWS resource ID formats. IDs have 8 (legacy) or 17 hex digits.
+var (
+	vpcIDPattern           = regexp.MustCompile(`^vpc-([0-9a-f]{8}|[0-9a-f]{17})$`)
+	subnetIDPattern        = regexp.MustCompile(`^subnet-([0-9a-f]{8}|[0-9a-f]{17})$`)
+	securityGroupIDPattern = regexp.MustCompile(`^sg-([0-9a-f]{8}|[0-9a-f]{17})$`)
+)
+
+// UsesExistingVPC reports whether agents run in an existing VPC.
+func (v *VPCConfig) UsesExistingVPC() bool {
+	return v.VPCID != ""
+}
+
+// parseCIDR parses an IPv4 CIDR block. The address must be the network
+// address of the block.
+func parseCIDR(cidr string) (netip.Prefix, error) {
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid CIDR block %q", cidr)
+	}
+	if !prefix.Addr().Is4() {
+		return netip.Prefix{}, fmt.Errorf("%s is not an IPv4 CIDR block", cidr)
+	}
+	if prefix.Masked() != prefix {
+		return netip.Prefix{}, fmt.Errorf("%s is not a network address; use %s", cidr, prefix.Masked())
+	}
+	return prefix, nil
+}
+
+// validateNetwork adds VPC errors to the report.
+func (c *StackConfig) validateNetwork(report *ValidationReport) {
+	v := c.VPC
+	if v == nil {
+		return
+	}
+
+	if v.MaxAZs < 0 || v.MaxAZs > maxAZs {
+		report.addError("vpc.maxAZs", "vpc.maxAZs must be between 1 and %d", maxAZs)
+	}
+
+	var reserved []netip.Prefix
+	for i, cidr := range v.ReservedCIDRs {
+		prefix, err := parseCIDR(cidr)
+		if err != nil {
+			report.addError(fmt.Sprintf("vpc.reservedCidrs[%d]", i), "vpc.reservedCidrs[%d]: %v", i, err)
+			continue
+		}
+		reserved = append(reserved, prefix)
+	}
+
+	if v.UsesExistingVPC() {
+		c.validateExistingVPC(report)
+		return
+	}
+
+	if len(v.SubnetIDs) > 0 {
+		report.addError("vpc.vpcId", "vpc.vpcId is required when vpc.subnetIds are set")
+	}
+	if len(v.SecurityGroupIDs) > 0 {
+		report.addError("vpc.vpcId", "vpc.vpcId is required when vpc.securityGroupIds are set")
+	}
+	if !v.CreateVPC || v.VPCCidr == "" {
+		return
+	}
+
+	vpc, err := parseCIDR(v.VPCCidr)
+	if err != nil {
+		report.addError("vpc.vpcCidr", "vpc.vpcCidr: %v", err)
+		return
+	}
+	if bits := vpc.Bits(); bits < minVPCPrefixBits || bits > maxVPCPrefixBits {
+		report.addError("vpc.vpcCidr", "vpc.vpcCidr must be between /%d and /%d, got /%d", minVPCPrefixBits, maxVPCPrefixBits, bits)
+	}
+	for _, subnet := range []string{PublicSubnetCIDR, PrivateSubnetCIDR} {
+		if s := netip.MustParsePrefix(subnet); !vpc.Contains(s.Addr()) || s.Bits() < vpc.Bits() {
+			report.addError("vpc.vpcCidr", "vpc.vpcCidr %s must contain the subnet %s", v.VPCCidr, subnet)
+		}
+	}
+	for i, r := range reserved {
+		if vpc.Overlaps(r) {
+			report.addError("vpc.vpcCidr", "vpc.vpcCidr %s overlaps reserved CIDR block %s (vpc.reservedCidrs[%d])", v.VPCCidr, r, i)
+		}
+	}
+}
+
+// validateExistingVPC adds errors for the IDs of an existing VPC.
+func (c *StackConfig) validateExistingVPC(report *ValidationReport) {
+	v := c.VPC
+
+	if !vpcIDPattern.MatchString(v.VPCID) {
+		report.addError("vpc.vpcId", "vpc.vpcId is not a valid VPC ID: %s", v.VPCID)
+	}
+	if len(v.SubnetIDs) == 0 {
+		report.addError("vpc.subnetIds", "vpc.subnetIds are required when using an existing VPC")
+	}
+
+	checkIDs := func(field string, ids []string, pattern *regexp.Regexp, kind string) {
+		seen := make(map[string]bool)
+		for i, id := range ids {
+			path := fmt.Sprintf("vpc.%s[%d]", field, i)
+			switch {
+			case !pattern.MatchString(id):
+				report.addError(path, "%s is not a valid %s ID: %s", path, kind, id)
+			case seen[id]:
+				report.addError(path, "duplicate %s ID: %s", kind, id)
+			}
+			seen[id] = true
+		}
+	}
+	checkIDs("subnetIds", v.SubnetIDs, subnetIDPattern, "subnet")
+	checkIDs("securityGroupIds", v.SecurityGroupIDs, securityGroupIDPattern, "security group")
+}
+
+// validateNetworkWarnings adds risky VPC settings to the report.
+func (c *StackConfig) validateNetworkWarnings(report *ValidationReport) {
+	v := c.VPC
+	if v == nil {
+		return
+	}
+
+	if v.UsesExistingVPC() {
+		if n := len(v.SubnetIDs); n == 1 {
+			report.addWarning("vpc.subnetIds", "vpc.subnetIds has a single subnet; agents are not spread across availability zones")
+		} else if n > 0 && v.MaxAZs > n {
+			report.addWarning("vpc.maxAZs", "vpc.maxAZs is %d but only %d subnets are provided; agents use at most %d availability zones", v.MaxAZs, n, n)
+		}
+		return
+	}
+
+	if !v.CreateVPC {
+		return
+	}
+	if vpc, err := parseCIDR(v.VPCCidr); err == nil && !vpc.Addr().IsPrivate() {
+		report.addWarning("vpc.vpcCidr", "vpc.vpcCidr %s is not a private (RFC 1918) range", v.VPCCidr)
+	}
+}
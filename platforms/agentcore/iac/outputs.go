@@ -0,0 +1,221 @@
+package iac
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Output value types.
+const (
+	OutputRuntimeARN  = "runtimeArn"
+	OutputEndpointURL = "endpointUrl"
+	OutputSecretARNs  = "secretArns"
+	OutputGatewayURL  = "gatewayUrl"
+	OutputValue       = "value"
+)
+
+// outputNamePattern restricts output names to valid CloudFormation logical IDs.
+var outputNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]{0,254}$`)
+
+// OutputConfig declares a stack output for downstream systems. Outputs are
+// added to the CloudFormation outputs, Terraform outputs (in snake_case)
+// and Pulumi exports.
+type OutputConfig struct {
+	// Name is the output name. Letters and digits only.
+	// Required.
+	Name string `json:"name" yaml:"name"`
+
+	// Description is a description of the output.
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+
+	// Type is the kind of value:
+	//   - "runtimeArn": the agent's runtime ARN (a stack parameter)
+	//   - "endpointUrl": the agent's custom domain URL, or its runtime
+	//     invocation URL (requires the agent's runtimeArn)
+	//   - "secretArns": the agent's secretsARNs, comma-separated
+	//   - "gatewayUrl": the gateway custom domain URL
+	//   - "value": Value, verbatim
+	// Supported: "runtimeArn", "endpointUrl", "secretArns", "gatewayUrl", "value"
+	// Required.
+	Type string `json:"type" yaml:"type"`
+
+	// Agent is the agent name. Required for "runtimeArn", "endpointUrl"
+	// and "secretArns".
+	Agent string `json:"agent,omitempty" yaml:"agent,omitempty"`
+
+	// Value is the output value. Required for "value".
+	Value string `json:"value,omitempty" yaml:"value,omitempty"`
+
+	// Export exports the output as "{stack-name}-{name}" for use by other
+	// CloudFormation stacks.
+	// Default: false
+	Export bool `json:"export,omitempty" yaml:"export,omitempty"`
+}
+
+// ValidOutputTypes returns the list of valid output types.
+func ValidOutputTypes() []string {
+	return []string{OutputRuntimeARN, OutputEndpointURL, OutputSecretARNs, OutputGatewayURL, OutputValue}
+}
+
+// RuntimeARNAgents returns the agents whose runtime ARN is a stack
+// parameter: agents invoked by stack resources and agents with a
+// runtimeArn output, in config order.
+func (c *StackConfig) RuntimeARNAgents() []AgentConfig {
+	var names []string
+	for _, agent := range c.InvokedAgents() {
+		names = append(names, agent.Name)
+	}
+	for _, o := range c.Outputs {
+		if o.Type == OutputRuntimeARN {
+			names = append(names, o.Agent)
+		}
+	}
+	return c.agentsNamed(names)
+}
+
+// AgentEndpointURL returns the URL agent is invoked at: its custom domain,
+// or its runtime invocation URL. Returns an empty string if neither the
+// domain nor the runtime ARN is configured.
+func AgentEndpointURL(agent AgentConfig) string {
+	if agent.Domain != nil {
+		return "https://" + agent.Domain.DomainName
+	}
+	if u := RuntimeInvocationURL(agent); u != "" {
+		return u + "?qualifier=DEFAULT"
+	}
+	return ""
+}
+
+// RuntimeInvocationURL returns the invocation URL of agent's runtime,
+// without the endpoint qualifier. Returns an empty string if the runtime
+// ARN is not configured.
+func RuntimeInvocationURL(agent AgentConfig) string {
+	parts := strings.Split(agent.RuntimeARN, ":")
+	if len(parts) < 6 || parts[3] == "" {
+		return ""
+	}
+	return fmt.Sprintf("https://bedrock-agentcore.%s.amazonaws.com/runtimes/%s/invocations",
+		parts[3], url.QueryEscape(agent.RuntimeARN))
+}
+
+// StaticValue returns the output value if it is known when the stack is
+// generated. It returns false for "runtimeArn" outputs, which each
+// generator references as a stack parameter.
+func (o *OutputConfig) StaticValue(config *StackConfig) (string, bool) {
+	var agent AgentConfig
+	if agents := config.agentsNamed([]string{o.Agent}); len(agents) > 0 {
+		agent = agents[0]
+	}
+	switch o.Type {
+	case OutputEndpointURL:
+		return AgentEndpointURL(agent), true
+	case OutputSecretARNs:
+		return strings.Join(agent.SecretsARNs, ","), true
+	case OutputGatewayURL:
+		if g := config.Gateway; g != nil && g.Domain != nil {
+			return "https://" + g.Domain.DomainName, true
+		}
+		return "", true
+	case OutputValue:
+		return o.Value, true
+	default:
+		return "", false
+	}
+}
+
+// OutputDescription returns the output description, or a generated one.
+func (o *OutputConfig) OutputDescription() string {
+	if o.Description != "" {
+		return o.Description
+	}
+	switch o.Type {
+	case OutputRuntimeARN:
+		return fmt.Sprintf("AgentCore runtime ARN for %s agent", o.Agent)
+	case OutputEndpointURL:
+		return fmt.Sprintf("Endpoint URL for %s agent", o.Agent)
+	case OutputSecretARNs:
+		return fmt.Sprintf("Secret ARNs for %s agent", o.Agent)
+	case OutputGatewayURL:
+		return "Gateway URL"
+	default:
+		return o.Name
+	}
+}
+
+// validateOutputs adds Outputs errors to the report.
+func (c *StackConfig) validateOutputs(report *ValidationReport) {
+	agents := make(map[string]AgentConfig, len(c.Agents))
+	for _, agent := range c.Agents {
+		agents[agent.Name] = agent
+	}
+
+	names := make(map[string]bool)
+	for i, o := range c.Outputs {
+		path := fmt.Sprintf("outputs[%d]", i)
+
+		if !outputNamePattern.MatchString(o.Name) {
+			report.addError(path+".name", "%s.name must start with a letter and contain only letters and digits: %s", path, o.Name)
+		} else if names[o.Name] {
+			report.addError(path+".name", "duplicate output name: %s", o.Name)
+		}
+		names[o.Name] = true
+
+		if !slices.Contains(ValidOutputTypes(), o.Type) {
+			report.addError(path+".type", "%s.type must be one of %v", path, ValidOutputTypes())
+			continue
+		}
+
+		switch o.Type {
+		case OutputRuntimeARN, OutputEndpointURL, OutputSecretARNs:
+			agent, ok := agents[o.Agent]
+			if !ok {
+				report.addError(path+".agent", "%s.agent: '%s' does not match any agent name", path, o.Agent)
+				continue
+			}
+			if o.Type == OutputEndpointURL && AgentEndpointURL(agent) == "" {
+				report.addError(path+".agent", "%s: endpointUrl requires agent %s to have a domain or runtimeArn", path, o.Agent)
+			}
+			if o.Type == OutputSecretARNs && len(agent.SecretsARNs) == 0 {
+				report.addError(path+".agent", "%s: agent %s has no secretsARNs", path, o.Agent)
+			}
+		case OutputGatewayURL:
+			if c.Gateway == nil || !c.Gateway.Enabled || c.Gateway.Domain == nil {
+				report.addError(path+".type", "%s: gatewayUrl requires an enabled gateway with a domain", path)
+			}
+		case OutputValue:
+			if o.Value == "" {
+				report.addError(path+".value", "%s.value is required", path)
+			}
+		}
+	}
+}
+
+// addDeclaredOutputs adds the outputs declared in config.Outputs. Names
+// must not collide with generated outputs.
+func addDeclaredOutputs(template *CloudFormationTemplate, config *StackConfig) error {
+	for _, o := range config.Outputs {
+		if _, exists := template.Outputs[o.Name]; exists {
+			return fmt.Errorf("output %s conflicts with a generated output", o.Name)
+		}
+		var value interface{}
+		if v, ok := o.StaticValue(config); ok {
+			value = v
+		} else {
+			value = cfRuntimeARN(AgentConfig{Name: o.Agent})
+		}
+		output := CFOutput{
+			Description: o.OutputDescription(),
+			Value:       value,
+		}
+		if o.Export {
+			output.Export = &CFExport{
+				Name: map[string]interface{}{"Fn::Sub": "${AWS::StackName}-" + o.Name},
+			}
+		}
+		template.Outputs[o.Name] = output
+	}
+	return nil
+}
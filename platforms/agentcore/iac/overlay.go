@@ -0,0 +1,183 @@
+package iac
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Environment overlays let teams keep one base config and small per-environment
+// patches instead of copying whole files:
+//
+//	config.yaml          # base
+//	config.dev.yaml      # dev overlay
+//	config.prod.yaml     # prod overlay
+//
+// Overlays are deep-merged onto the base:
+//   - objects are merged key by key
+//   - the agents list is merged by agent name; new agents are appended
+//   - other lists and scalars replace the base value
+//   - a null value removes the key from the base
+
+// OverlayPath returns the overlay file path for env next to the base config.
+// Example: OverlayPath("deploy/config.yaml", "prod") is "deploy/config.prod.yaml".
+func OverlayPath(path, env string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + env + ext
+}
+
+// LoadStackConfigForEnv loads the base config at path and applies the overlay
+// for env. If env is empty, only the base config is loaded. The overlay file
+// must exist when env is set. The "Environment" tag defaults to env.
+func LoadStackConfigForEnv(path, env string) (*StackConfig, error) {
+	if env == "" {
+		return LoadStackConfigFromFile(path)
+	}
+
+	base, err := readRawConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	overlayPath := OverlayPath(path, env)
+	overlay, err := readRawConfig(overlayPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s overlay: %w", env, err)
+	}
+
+	merged := MergeConfigData(base, overlay)
+
+	tags, _ := merged["tags"].(map[string]interface{})
+	if tags == nil {
+		tags = make(map[string]interface{})
+		merged["tags"] = tags
+	}
+	if _, ok := tags["Environment"]; !ok {
+		tags["Environment"] = env
+	}
+
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode merged config: %w", err)
+	}
+	return LoadStackConfigFromJSON(data)
+}
+
+// MergeConfigData deep-merges overlay onto base using overlay semantics and
+// returns the result. Neither input is modified.
+func MergeConfigData(base, overlay map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(base))
+	for k, v := range base {
+		out[k] = v
+	}
+
+	for k, ov := range overlay {
+		if ov == nil {
+			delete(out, k)
+			continue
+		}
+
+		bv, exists := out[k]
+		if !exists {
+			out[k] = ov
+			continue
+		}
+
+		switch ovTyped := ov.(type) {
+		case map[string]interface{}:
+			if bvMap, ok := bv.(map[string]interface{}); ok {
+				out[k] = MergeConfigData(bvMap, ovTyped)
+				continue
+			}
+		case []interface{}:
+			if bvList, ok := bv.([]interface{}); ok && isNamedList(bvList) && isNamedList(ovTyped) {
+				out[k] = mergeNamedLists(bvList, ovTyped)
+				continue
+			}
+		}
+		out[k] = ov
+	}
+
+	return out
+}
+
+// isNamedList reports whether every element is an object with a string "name".
+func isNamedList(list []interface{}) bool {
+	if len(list) == 0 {
+		return false
+	}
+	for _, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if _, ok := m["name"].(string); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeNamedLists merges overlay items into base items with the same name,
+// preserving base order and appending new items.
+func mergeNamedLists(base, overlay []interface{}) []interface{} {
+	out := make([]interface{}, len(base))
+	copy(out, base)
+
+	index := make(map[string]int, len(base))
+	for i, item := range base {
+		index[item.(map[string]interface{})["name"].(string)] = i
+	}
+
+	for _, item := range overlay {
+		m := item.(map[string]interface{})
+		name := m["name"].(string)
+		if i, ok := index[name]; ok {
+			out[i] = MergeConfigData(out[i].(map[string]interface{}), m)
+		} else {
+			index[name] = len(out)
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// readRawConfig reads a JSON or YAML config file into a generic map and
+// resolves its includes and placeholders.
+func readRawConfig(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var raw map[string]interface{}
+	ext := strings.ToLower(filepath.Ext(path))
+	switch ext {
+	case ".json":
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON config: %w", err)
+		}
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML config: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported file format: %s (use .json, .yaml, or .yml)", ext)
+	}
+
+	if raw == nil {
+		raw = make(map[string]interface{})
+	}
+	raw, err = resolveIncludes(raw, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve config includes: %w", err)
+	}
+	if err := SubstituteVariables(raw, NewVariableResolver()); err != nil {
+		return nil, fmt.Errorf("failed to resolve config variables: %w", err)
+	}
+	return raw, nil
+}
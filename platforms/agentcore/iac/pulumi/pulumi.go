@@ -0,0 +1,687 @@
+// Package pulumi exports an iac.StackConfig as a ready-to-run Pulumi Go program.
+//
+// The generated program creates the same foundational resources as
+// iac.GenerateCloudFormation (VPC, IAM execution role, CloudWatch Log Group)
+// using the pulumi-aws SDK. It is a starting point for teams using deployment
+// approach #3 without depending on github.com/plexusone/agentkit-aws-pulumi.
+//
+// Example:
+//
+//	config, _ := iac.LoadStackConfigFromFile("config.yaml")
+//	if err := pulumi.WriteProgram(config, "./infra", pulumi.Options{}); err != nil {
+//	    log.Fatal(err)
+//	}
+//	// Then: cd infra && go mod tidy && pulumi up
+package pulumi
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/plexusone/agentkit/platforms/agentcore/iac"
+)
+
+//go:embed template_*.tmpl
+var templates embed.FS
+
+// Default versions written to the generated go.mod.
+const (
+	DefaultPulumiSDKVersion = "v3.150.0"
+	DefaultAWSSDKVersion    = "v6.70.0"
+)
+
+// Options configures program generation.
+type Options struct {
+	// ProjectName is the Pulumi project name. Default: stack name.
+	ProjectName string
+
+	// ModulePath is the Go module path. Default: the project name.
+	ModulePath string
+
+	// PulumiSDKVersion is the github.com/pulumi/pulumi/sdk/v3 version.
+	PulumiSDKVersion string
+
+	// AWSSDKVersion is the github.com/pulumi/pulumi-aws/sdk/v6 version.
+	AWSSDKVersion string
+}
+
+// Program is a generated Pulumi program, keyed by relative file path.
+type Program struct {
+	Files map[string][]byte
+}
+
+// templateAgent holds per-agent data for template rendering.
+type templateAgent struct {
+	Name           string
+	ConfigKey      string
+	ContainerImage string
+	MemoryMB       int
+
+	// Agent tags, merged over the stack tags on agent resources.
+	Tags [][2]string
+
+	// Set when the image is built into a stack-managed ECR repository.
+	Repository      *iac.ECRRepositoryConfig
+	RepositoryVar   string
+	ImageTag        string
+	LifecyclePolicy string
+
+	// Runtime lifecycle configuration, if scaling is configured.
+	LifecycleKey string
+	Lifecycle    map[string]int
+
+	// Inbound authorizer configuration JSON, if AgentCore validates tokens.
+	AuthorizerKey  string
+	AuthorizerJSON string
+
+	// AgentCore Memory name, if memory is enabled.
+	MemoryKey  string
+	MemoryName string
+
+	// The agent's own log group, if configured.
+	Logging     *iac.AgentLoggingConfig
+	LogGroupVar string
+}
+
+// templateRole holds execution role data for template rendering.
+type templateRole struct {
+	ResourceName     string
+	PolicyName       string
+	AttachmentPrefix string
+	RoleName         string
+	Var              string
+	Export           string
+	PolicyJSON       string
+}
+
+// templateAuthorizer holds Lambda authorizer function data for template rendering.
+type templateAuthorizer struct {
+	Agent       string
+	Var         string
+	Function    *iac.AuthorizerFunctionConfig
+	PolicyJSON  string
+	Environment [][2]string
+	Tags        [][2]string
+}
+
+// templateDashboard holds CloudWatch dashboard data for template rendering.
+type templateDashboard struct {
+	Name   string
+	Body   string
+	Region string
+}
+
+// templateOutput holds a declared stack output for template rendering.
+// RuntimeVar is set for runtime ARN outputs, Value otherwise.
+type templateOutput struct {
+	Name       string
+	Value      string
+	RuntimeVar string
+}
+
+// templateDomain holds custom domain data for template rendering.
+type templateDomain struct {
+	Owner          string
+	ResourceName   string
+	Var            string
+	DomainName     string
+	CertificateARN string
+	HostedZoneID   string
+	SecurityPolicy string
+	APIName        string
+	TargetURL      string
+	RequestParams  map[string]string
+}
+
+// templateRuntime holds the runtime ARN config for an invoked agent.
+type templateRuntime struct {
+	Name      string
+	Var       string
+	ConfigKey string
+	Default   string
+	Scheduled bool
+	Queued    bool
+}
+
+// templateQueue holds queue data for template rendering.
+type templateQueue struct {
+	Name                string
+	Var                 string
+	QueueName           string
+	DLQName             string
+	ConsumerName        string
+	PipeName            string
+	RuntimeVar          string
+	VisibilityTimeout   int
+	RetentionSeconds    int
+	FIFO                bool
+	KMSKeyARN           string
+	DeadLetter          bool
+	MaxReceiveCount     int
+	DLQRetentionSeconds int
+	Definition          string
+	DeadLetterAlarmName string
+}
+
+// templateAlarms holds alarm and budget data for template rendering.
+type templateAlarms struct {
+	Enabled     bool
+	TopicARN    string
+	TopicName   string
+	Emails      []string
+	TopicPolicy string
+	Namespace   string
+	Period      int
+	Evaluations int
+	ErrorRate   float64
+	Agents      []templateAgentAlarm
+	Budget      *templateBudget
+}
+
+// templateAgentAlarm holds per-agent alarm data for template rendering.
+type templateAgentAlarm struct {
+	Name           string
+	ErrorRateAlarm string
+	LatencyAlarm   string
+	Dimensions     [][2]string
+	Metrics        [][2]string
+	LatencyMs      int
+}
+
+// templateBudget holds budget data for template rendering.
+type templateBudget struct {
+	Name          string
+	Limit         string
+	Filters       []string
+	Notifications []templateNotification
+}
+
+// templateNotification holds a budget notification for template rendering.
+type templateNotification struct {
+	Type      string
+	Threshold float64
+}
+
+// templateSchedule holds schedule data for template rendering.
+type templateSchedule struct {
+	Name        string
+	RuntimeVar  string
+	Expression  string
+	Timezone    string
+	State       string
+	Description string
+	Payload     string
+}
+
+// templateData holds all data for template rendering.
+type templateData struct {
+	ProjectName        string
+	ModulePath         string
+	PulumiSDKVersion   string
+	AWSSDKVersion      string
+	StackName          string
+	Description        string
+	CreateVPC          bool
+	VPCCidr            string
+	PublicSubnetCIDR   string
+	PrivateSubnetCIDR  string
+	EnableLogs         bool
+	AgentLogGroups     bool
+	LogRetentionDays   int
+	RetainLogs         bool
+	Roles              []templateRole
+	AssumeRoleJSON     string
+	PermissionsBound   string
+	AdditionalPolicies []string
+	Tags               [][2]string
+	Agents             []templateAgent
+	HasRepositories    bool
+	Hooks              string
+	Domains            []templateDomain
+	Authorizers        []templateAuthorizer
+	Runtimes           []templateRuntime
+	Schedules          []templateSchedule
+	Queues             []templateQueue
+	QueuePipePolicy    string
+	Artifacts          *iac.ArtifactsConfig
+	Alarms             *templateAlarms
+	Dashboard          *templateDashboard
+	Outputs            []templateOutput
+}
+
+// GenerateProgram converts the StackConfig into a Pulumi Go program.
+func GenerateProgram(config *iac.StackConfig, opts Options) (*Program, error) {
+	config.ApplyDefaults()
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	data, err := prepareTemplateData(config, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	program := &Program{Files: make(map[string][]byte)}
+	files := map[string]string{
+		"main.go":     "template_main.go.tmpl",
+		"Pulumi.yaml": "template_pulumi.yaml.tmpl",
+		"go.mod":      "template_go.mod.tmpl",
+	}
+	for name, tmpl := range files {
+		content, err := render(tmpl, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate %s: %w", name, err)
+		}
+		if strings.HasSuffix(name, ".go") {
+			formatted, err := format.Source(content)
+			if err != nil {
+				return nil, fmt.Errorf("failed to format %s: %w", name, err)
+			}
+			content = formatted
+		}
+		program.Files[name] = content
+	}
+
+	return program, nil
+}
+
+// WriteProgram generates the program and writes it to dir.
+// Existing files are overwritten.
+func WriteProgram(config *iac.StackConfig, dir string, opts Options) error {
+	program, err := GenerateProgram(config, opts)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	for name, content := range program.Files {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// WriteProgramFromFile loads a config file and writes the Pulumi program to dir.
+func WriteProgramFromFile(configPath, dir string, opts Options) error {
+	config, err := iac.LoadStackConfigFromFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	return WriteProgram(config, dir, opts)
+}
+
+func prepareTemplateData(config *iac.StackConfig, opts Options) (*templateData, error) {
+	if opts.ProjectName == "" {
+		opts.ProjectName = config.StackName
+	}
+	if opts.ModulePath == "" {
+		opts.ModulePath = opts.ProjectName
+	}
+	if opts.PulumiSDKVersion == "" {
+		opts.PulumiSDKVersion = DefaultPulumiSDKVersion
+	}
+	if opts.AWSSDKVersion == "" {
+		opts.AWSSDKVersion = DefaultAWSSDKVersion
+	}
+
+	var roles []templateRole
+	for _, role := range config.ExecutionRoles() {
+		policy, err := json.Marshal(map[string]interface{}{
+			"Version":   "2012-10-17",
+			"Statement": role.Statements,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode IAM policy: %w", err)
+		}
+		tr := templateRole{
+			ResourceName:     "execution-role",
+			PolicyName:       "agentcore-policy",
+			AttachmentPrefix: "additional-policy",
+			RoleName:         role.RoleName,
+			Var:              "role",
+			Export:           "executionRoleArn",
+			PolicyJSON:       string(policy),
+		}
+		if role.Agent != "" {
+			tr.ResourceName = role.Agent + "-execution-role"
+			tr.PolicyName = role.Agent + "-agentcore-policy"
+			tr.AttachmentPrefix = role.Agent + "-additional-policy"
+			tr.Var = toCamelCase(role.Agent) + "Role"
+			tr.Export = toCamelCase(role.Agent) + "ExecutionRoleArn"
+		}
+		roles = append(roles, tr)
+	}
+
+	assumeRole, err := json.Marshal(map[string]interface{}{
+		"Version": "2012-10-17",
+		"Statement": []map[string]interface{}{
+			{
+				"Effect": "Allow",
+				"Principal": map[string]interface{}{
+					"Service": []string{"bedrock.amazonaws.com", "lambda.amazonaws.com"},
+				},
+				"Action": "sts:AssumeRole",
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode assume role policy: %w", err)
+	}
+
+	data := &templateData{
+		ProjectName:        opts.ProjectName,
+		ModulePath:         opts.ModulePath,
+		PulumiSDKVersion:   opts.PulumiSDKVersion,
+		AWSSDKVersion:      opts.AWSSDKVersion,
+		StackName:          config.StackName,
+		Description:        config.Description,
+		CreateVPC:          config.VPC.CreateVPC,
+		VPCCidr:            config.VPC.VPCCidr,
+		PublicSubnetCIDR:   iac.PublicSubnetCIDR,
+		PrivateSubnetCIDR:  iac.PrivateSubnetCIDR,
+		EnableLogs:         config.Observability.EnableCloudWatchLogs,
+		LogRetentionDays:   config.Observability.LogRetentionDays,
+		RetainLogs:         config.RemovalPolicy == "retain",
+		Roles:              roles,
+		AssumeRoleJSON:     string(assumeRole),
+		PermissionsBound:   config.IAM.PermissionsBoundaryARN,
+		AdditionalPolicies: config.IAM.AdditionalPolicies,
+	}
+
+	if config.Hooks != nil {
+		data.Hooks = config.Hooks.JSON()
+	}
+	for _, o := range config.Outputs {
+		to := templateOutput{Name: o.Name}
+		if v, ok := o.StaticValue(config); ok {
+			to.Value = v
+		} else {
+			to.RuntimeVar = toCamelCase(o.Agent) + "RuntimeArn"
+		}
+		data.Outputs = append(data.Outputs, to)
+	}
+
+	data.Tags = tagPairs(config.Tags)
+
+	for _, agent := range config.Agents {
+		ta := templateAgent{
+			Name:           agent.Name,
+			ConfigKey:      toCamelCase(agent.Name) + "ContainerImage",
+			ContainerImage: agent.ContainerImage,
+			MemoryMB:       agent.MemoryMB,
+			Tags:           tagPairs(agent.Tags),
+		}
+		if auth := agent.Authorizer.InboundConfigurationJSON(); auth != "" {
+			ta.AuthorizerKey = toCamelCase(agent.Name) + "Authorizer"
+			ta.AuthorizerJSON = auth
+		}
+		if agent.EnableMemory {
+			ta.MemoryKey = toCamelCase(agent.Name) + "MemoryName"
+			ta.MemoryName = agent.Memory.Name
+		}
+		if agent.Logging != nil {
+			ta.Logging = agent.Logging
+			ta.LogGroupVar = toCamelCase(agent.Name) + "LogGroup"
+			data.AgentLogGroups = true
+		}
+		if agent.Scaling != nil {
+			ta.LifecycleKey = toCamelCase(agent.Name) + "Lifecycle"
+			ta.Lifecycle = agent.Scaling.LifecycleConfiguration()
+		}
+		if agent.ContainerImage == "" && agent.ImageBuild != nil && agent.ImageBuild.Repository.ShouldCreate() {
+			ta.Repository = agent.ImageBuild.Repository
+			ta.RepositoryVar = toCamelCase(agent.Name) + "Repository"
+			ta.ImageTag = agent.ImageBuild.Tag
+			ta.LifecyclePolicy = agent.ImageBuild.Repository.Lifecycle.PolicyText()
+			data.HasRepositories = true
+		} else if agent.ContainerImage == "" && agent.ImageBuild != nil {
+			ta.ContainerImage = agent.ImageBuild.Repository.Name + ":" + agent.ImageBuild.Tag
+		}
+		data.Agents = append(data.Agents, ta)
+	}
+
+	for _, agent := range config.AuthorizerFunctionAgents() {
+		f := agent.Authorizer.Function
+		policy, err := json.Marshal(map[string]interface{}{
+			"Version":   "2012-10-17",
+			"Statement": f.PolicyStatements(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode IAM policy: %w", err)
+		}
+		data.Authorizers = append(data.Authorizers, templateAuthorizer{
+			Agent:       agent.Name,
+			Var:         toCamelCase(agent.Name) + "Authorizer",
+			Function:    f,
+			PolicyJSON:  string(policy),
+			Environment: tagPairs(f.Environment),
+			Tags:        tagPairs(agent.Tags),
+		})
+	}
+
+	for _, agent := range config.RuntimeARNAgents() {
+		data.Runtimes = append(data.Runtimes, templateRuntime{
+			Name:      agent.Name,
+			Var:       toCamelCase(agent.Name) + "RuntimeArn",
+			ConfigKey: toCamelCase(agent.Name) + "RuntimeArn",
+			Default:   agent.RuntimeARN,
+			Scheduled: slices.ContainsFunc(config.Schedules, func(s iac.ScheduleConfig) bool { return s.Agent == agent.Name }),
+			Queued:    slices.ContainsFunc(config.Queues, func(q iac.QueueConfig) bool { return q.Agent == agent.Name }),
+		})
+	}
+	for _, sched := range config.Schedules {
+		payload, err := sched.Payload()
+		if err != nil {
+			return nil, err
+		}
+		data.Schedules = append(data.Schedules, templateSchedule{
+			Name:        config.StackName + "-" + sched.Name,
+			RuntimeVar:  toCamelCase(sched.Agent) + "RuntimeArn",
+			Expression:  sched.Expression,
+			Timezone:    sched.Timezone,
+			State:       sched.State(),
+			Description: sched.Description,
+			Payload:     payload,
+		})
+	}
+
+	if len(config.Queues) > 0 {
+		var queueARNs, stateMachineARNs []string
+		for _, q := range config.Queues {
+			tq := templateQueue{
+				Name:                q.Name,
+				DeadLetterAlarmName: fmt.Sprintf("%s-%s-dead-letters", config.StackName, q.Name),
+				Var:                 toCamelCase(strings.TrimSuffix(q.Name, ".fifo")),
+				QueueName:           q.QueueName(config.StackName),
+				DLQName:             q.DeadLetterQueueName(config.StackName),
+				ConsumerName:        fmt.Sprintf("%s-%s-consumer", config.StackName, strings.TrimSuffix(q.Name, ".fifo")),
+				PipeName:            fmt.Sprintf("%s-%s-pipe", config.StackName, strings.TrimSuffix(q.Name, ".fifo")),
+				RuntimeVar:          toCamelCase(q.Agent) + "RuntimeArn",
+				VisibilityTimeout:   q.VisibilityTimeoutSeconds,
+				RetentionSeconds:    q.MessageRetentionDays * 86400,
+				FIFO:                q.FIFO,
+				KMSKeyARN:           q.KMSKeyARN,
+				DeadLetter:          q.DeadLetter.IsEnabled(),
+				Definition:          q.ConsumerDefinition("%s"),
+			}
+			if tq.DeadLetter {
+				tq.MaxReceiveCount = q.DeadLetter.MaxReceiveCount
+				tq.DLQRetentionSeconds = q.DeadLetter.RetentionDays * 86400
+			}
+			data.Queues = append(data.Queues, tq)
+			queueARNs = append(queueARNs, "arn:aws:sqs:*:*:"+tq.QueueName)
+			stateMachineARNs = append(stateMachineARNs, "arn:aws:states:*:*:stateMachine:"+tq.ConsumerName)
+		}
+
+		pipePolicy, err := json.Marshal(map[string]interface{}{
+			"Version": "2012-10-17",
+			"Statement": []map[string]interface{}{
+				{"Effect": "Allow", "Action": []string{"sqs:ReceiveMessage", "sqs:DeleteMessage", "sqs:GetQueueAttributes"}, "Resource": queueARNs},
+				{"Effect": "Allow", "Action": "states:StartSyncExecution", "Resource": stateMachineARNs},
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode queue pipe policy: %w", err)
+		}
+		data.QueuePipePolicy = string(pipePolicy)
+	}
+
+	if config.Artifacts != nil && config.Artifacts.ShouldCreate() {
+		data.Artifacts = config.Artifacts
+	}
+
+	alarms, err := prepareAlarms(config)
+	if err != nil {
+		return nil, err
+	}
+	data.Alarms = alarms
+
+	if config.Dashboard != nil {
+		body, err := iac.DashboardBody(config, iac.DashboardRegion)
+		if err != nil {
+			return nil, err
+		}
+		data.Dashboard = &templateDashboard{
+			Name:   config.Dashboard.Name,
+			Body:   body,
+			Region: iac.DashboardRegion,
+		}
+	}
+
+	for _, b := range config.DomainBindings() {
+		data.Domains = append(data.Domains, templateDomain{
+			Owner:          b.Owner,
+			ResourceName:   b.ResourceName(),
+			Var:            toCamelCase(b.ResourceName()),
+			DomainName:     b.Domain.DomainName,
+			CertificateARN: b.Domain.CertificateARN,
+			HostedZoneID:   b.Domain.HostedZoneID,
+			SecurityPolicy: b.Domain.SecurityPolicy,
+			APIName:        fmt.Sprintf("%s-%s", config.StackName, b.ResourceName()),
+			TargetURL:      b.TargetURL,
+			RequestParams:  b.RequestParameters(),
+		})
+	}
+
+	return data, nil
+}
+
+// prepareAlarms returns the alarm template data, or nil if the stack has
+// no alarms or budget.
+func prepareAlarms(config *iac.StackConfig) (*templateAlarms, error) {
+	a := config.Alarms
+	if a == nil || (!a.IsEnabled() && a.Budget == nil) {
+		return nil, nil
+	}
+
+	ta := &templateAlarms{
+		Enabled:     a.IsEnabled(),
+		TopicARN:    a.SNSTopicARN,
+		Emails:      a.NotificationEmails,
+		Namespace:   a.MetricsNamespace,
+		Period:      a.PeriodSeconds,
+		Evaluations: a.EvaluationPeriods,
+		ErrorRate:   a.ErrorRatePercent,
+	}
+	if a.CreatesTopic() {
+		ta.TopicName = config.StackName + "-alarms"
+		if a.Budget != nil {
+			// The topic ARN is substituted with pulumi.Sprintf.
+			policy, err := json.Marshal(map[string]interface{}{
+				"Version": "2012-10-17",
+				"Statement": []map[string]interface{}{
+					{
+						"Effect":    "Allow",
+						"Principal": map[string]string{"Service": "budgets.amazonaws.com"},
+						"Action":    "SNS:Publish",
+						"Resource":  "%s",
+					},
+				},
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode alarm topic policy: %w", err)
+			}
+			ta.TopicPolicy = string(policy)
+		}
+	}
+	if ta.Enabled {
+		for _, agent := range config.Agents {
+			ta.Agents = append(ta.Agents, templateAgentAlarm{
+				Name:           agent.Name,
+				ErrorRateAlarm: fmt.Sprintf("%s-%s-error-rate", config.StackName, agent.Name),
+				LatencyAlarm:   fmt.Sprintf("%s-%s-latency", config.StackName, agent.Name),
+				Dimensions:     tagPairs(iac.AlarmDimensions(agent)),
+				Metrics:        [][2]string{{"errors", "SystemErrors"}, {"invocations", "Invocations"}},
+				LatencyMs:      a.LatencyThreshold(agent),
+			})
+		}
+	}
+	if b := a.Budget; b != nil {
+		ta.Budget = &templateBudget{
+			Name:    config.StackName + "-monthly",
+			Limit:   fmt.Sprintf("%g", b.MonthlyLimitUSD),
+			Filters: b.BudgetCostFilters(),
+		}
+		for _, t := range b.AlertThresholds {
+			ta.Budget.Notifications = append(ta.Budget.Notifications, templateNotification{Type: "ACTUAL", Threshold: t})
+		}
+		ta.Budget.Notifications = append(ta.Budget.Notifications, templateNotification{Type: "FORECASTED", Threshold: 100})
+	}
+	return ta, nil
+}
+
+func render(name string, data *templateData) ([]byte, error) {
+	tmpl, err := template.New(name).Funcs(template.FuncMap{
+		"quote": func(s string) string { return fmt.Sprintf("%q", s) },
+	}).ParseFS(templates, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// toCamelCase converts an agent name to a Pulumi config key.
+func toCamelCase(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return r == '-' || r == '_' || r == ' '
+	})
+	for i, word := range words {
+		word = strings.ToLower(word)
+		if i > 0 && len(word) > 0 {
+			word = strings.ToUpper(word[:1]) + word[1:]
+		}
+		words[i] = word
+	}
+	return strings.Join(words, "")
+}
+
+// tagPairs returns tags as key/value pairs sorted by key.
+func tagPairs(tags map[string]string) [][2]string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([][2]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, [2]string{k, tags[k]})
+	}
+	return pairs
+}
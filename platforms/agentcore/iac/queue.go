@@ -0,0 +1,307 @@
+package iac
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Express workflows, used as queue consumers, run for at most 5 minutes.
+const maxQueueConsumerSeconds = 300
+
+// queueNamePattern is the SQS queue name constraint, excluding the ".fifo" suffix.
+var queueNamePattern = regexp.MustCompile(`^[0-9a-zA-Z_-]{1,75}$`)
+
+// QueueConfig defines an SQS queue for asynchronous agent invocation.
+//
+// Each message body is sent to the agent as the invocation payload, with
+// the SQS message ID as the session ID. Messages are consumed by an
+// EventBridge Pipe that runs an Express Step Functions workflow calling
+// InvokeAgentRuntime. Failed invocations return the message to the queue
+// and move it to the dead-letter queue after MaxReceiveCount attempts.
+type QueueConfig struct {
+	// Name is the unique queue name.
+	// Required.
+	Name string `json:"name" yaml:"name"`
+
+	// Agent is the name of the agent that consumes the queue.
+	// Required.
+	Agent string `json:"agent" yaml:"agent"`
+
+	// VisibilityTimeoutSeconds is how long a message is hidden while it
+	// is processed. Should exceed the agent's TimeoutSeconds.
+	// Range: 0-43200
+	// Default: agent TimeoutSeconds + 60
+	VisibilityTimeoutSeconds int `json:"visibilityTimeoutSeconds,omitempty" yaml:"visibilityTimeoutSeconds,omitempty"`
+
+	// MessageRetentionDays is how long unconsumed messages are kept.
+	// Range: 1-14
+	// Default: 4
+	MessageRetentionDays int `json:"messageRetentionDays,omitempty" yaml:"messageRetentionDays,omitempty"`
+
+	// FIFO creates a FIFO queue. The ".fifo" suffix is added to the name.
+	// Default: false
+	FIFO bool `json:"fifo,omitempty" yaml:"fifo,omitempty"`
+
+	// KMSKeyARN encrypts messages with a customer managed key.
+	// If empty, SQS managed encryption is used.
+	KMSKeyARN string `json:"kmsKeyArn,omitempty" yaml:"kmsKeyArn,omitempty"`
+
+	// DeadLetter configures the dead-letter queue.
+	// Default: enabled with MaxReceiveCount 3
+	DeadLetter *DeadLetterConfig `json:"deadLetter,omitempty" yaml:"deadLetter,omitempty"`
+}
+
+// DeadLetterConfig defines the dead-letter queue for a QueueConfig.
+type DeadLetterConfig struct {
+	// Enabled creates a dead-letter queue.
+	// Default: true
+	Enabled *bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+
+	// MaxReceiveCount is the number of attempts before a message is moved
+	// to the dead-letter queue.
+	// Range: 1-1000
+	// Default: 3
+	MaxReceiveCount int `json:"maxReceiveCount,omitempty" yaml:"maxReceiveCount,omitempty"`
+
+	// RetentionDays is how long dead-lettered messages are kept.
+	// Range: 1-14
+	// Default: 14
+	RetentionDays int `json:"retentionDays,omitempty" yaml:"retentionDays,omitempty"`
+}
+
+// IsEnabled reports whether the dead-letter queue is created.
+func (d *DeadLetterConfig) IsEnabled() bool {
+	return d == nil || d.Enabled == nil || *d.Enabled
+}
+
+// QueueName returns the SQS queue name, including the stack name prefix
+// and the ".fifo" suffix for FIFO queues.
+func (q *QueueConfig) QueueName(stackName string) string {
+	return q.queueName(stackName, "")
+}
+
+// DeadLetterQueueName returns the SQS dead-letter queue name.
+func (q *QueueConfig) DeadLetterQueueName(stackName string) string {
+	return q.queueName(stackName, "-dlq")
+}
+
+func (q *QueueConfig) queueName(stackName, suffix string) string {
+	name := fmt.Sprintf("%s-%s%s", stackName, strings.TrimSuffix(q.Name, ".fifo"), suffix)
+	if q.FIFO {
+		name += ".fifo"
+	}
+	return name
+}
+
+// ConsumerDefinition returns the Amazon States Language definition of the
+// workflow that invokes the agent for a batch of one SQS message.
+func (q *QueueConfig) ConsumerDefinition(runtimeARN string) string {
+	// The runtime ARN is a plain ARN or an IaC reference, so it needs no
+	// escaping beyond JSON quoting.
+	return fmt.Sprintf(`{"Comment":"Invoke agent %s for queued messages","StartAt":"InvokeAgent","States":{"InvokeAgent":{"Type":"Task","Resource":"arn:aws:states:::aws-sdk:bedrockagentcore:invokeAgentRuntime","Parameters":{"AgentRuntimeArn":%q,"RuntimeSessionId.$":"$[0].messageId","Payload.$":"$[0].body"},"End":true}}}`,
+		q.Agent, runtimeARN)
+}
+
+// applyQueueDefaults fills in Queue defaults.
+func (c *StackConfig) applyQueueDefaults() {
+	timeouts := make(map[string]int, len(c.Agents))
+	for _, agent := range c.Agents {
+		timeouts[agent.Name] = agent.TimeoutSeconds
+	}
+
+	for i := range c.Queues {
+		q := &c.Queues[i]
+		if q.VisibilityTimeoutSeconds == 0 {
+			q.VisibilityTimeoutSeconds = min(timeouts[q.Agent]+60, 43200)
+		}
+		if q.MessageRetentionDays == 0 {
+			q.MessageRetentionDays = 4
+		}
+		if q.DeadLetter == nil {
+			q.DeadLetter = &DeadLetterConfig{}
+		}
+		if q.DeadLetter.MaxReceiveCount == 0 {
+			q.DeadLetter.MaxReceiveCount = 3
+		}
+		if q.DeadLetter.RetentionDays == 0 {
+			q.DeadLetter.RetentionDays = 14
+		}
+	}
+}
+
+// validateQueues adds Queue errors to the report.
+func (c *StackConfig) validateQueues(report *ValidationReport, agentNames map[string]bool) {
+	names := make(map[string]bool)
+	for i, q := range c.Queues {
+		path := fmt.Sprintf("queues[%d]", i)
+
+		name := strings.TrimSuffix(q.Name, ".fifo")
+		if !queueNamePattern.MatchString(name) {
+			report.addError(path+".name", "queues[%d]: name is required and must contain only letters, digits, '-' and '_'", i)
+		} else if names[name] {
+			report.addError(path+".name", "duplicate queue name: %s", name)
+		}
+		names[name] = true
+
+		if !agentNames[q.Agent] {
+			report.addError(path+".agent", "queues[%d] (%s): agent '%s' does not match any agent name", i, q.Name, q.Agent)
+		}
+		if q.VisibilityTimeoutSeconds < 0 || q.VisibilityTimeoutSeconds > 43200 {
+			report.addError(path+".visibilityTimeoutSeconds", "queues[%d] (%s): visibilityTimeoutSeconds must be between 0 and 43200", i, q.Name)
+		}
+		if q.MessageRetentionDays != 0 && (q.MessageRetentionDays < 1 || q.MessageRetentionDays > 14) {
+			report.addError(path+".messageRetentionDays", "queues[%d] (%s): messageRetentionDays must be between 1 and 14", i, q.Name)
+		}
+		if d := q.DeadLetter; d != nil {
+			if d.MaxReceiveCount != 0 && (d.MaxReceiveCount < 1 || d.MaxReceiveCount > 1000) {
+				report.addError(path+".deadLetter.maxReceiveCount", "queues[%d] (%s): deadLetter.maxReceiveCount must be between 1 and 1000", i, q.Name)
+			}
+			if d.RetentionDays != 0 && (d.RetentionDays < 1 || d.RetentionDays > 14) {
+				report.addError(path+".deadLetter.retentionDays", "queues[%d] (%s): deadLetter.retentionDays must be between 1 and 14", i, q.Name)
+			}
+		}
+	}
+}
+
+// validateQueueWarnings adds risky Queue settings to the report.
+func (c *StackConfig) validateQueueWarnings(report *ValidationReport) {
+	timeouts := make(map[string]int, len(c.Agents))
+	for _, agent := range c.Agents {
+		timeouts[agent.Name] = agent.TimeoutSeconds
+	}
+
+	for i, q := range c.Queues {
+		path := fmt.Sprintf("queues[%d]", i)
+		timeout := timeouts[q.Agent]
+		if timeout > maxQueueConsumerSeconds {
+			report.addWarning(path+".agent", "queues[%d] (%s): agent timeoutSeconds %d exceeds the %d second queue consumer limit; longer invocations are retried",
+				i, q.Name, timeout, maxQueueConsumerSeconds)
+		}
+		if q.VisibilityTimeoutSeconds != 0 && q.VisibilityTimeoutSeconds < timeout {
+			report.addWarning(path+".visibilityTimeoutSeconds", "queues[%d] (%s): visibilityTimeoutSeconds is shorter than the agent timeout; messages may be processed twice", i, q.Name)
+		}
+		if !q.DeadLetter.IsEnabled() {
+			report.addWarning(path+".deadLetter", "queues[%d] (%s): no dead-letter queue; failing messages are retried until they expire", i, q.Name)
+		}
+	}
+}
+
+// addQueueResources adds SQS queues, dead-letter queues and their consumers.
+func addQueueResources(template *CloudFormationTemplate, config *StackConfig) {
+	if len(config.Queues) == 0 {
+		return
+	}
+
+	deletionPolicy := "Delete"
+	if config.RemovalPolicy == "retain" {
+		deletionPolicy = "Retain"
+	}
+
+	var agentNames []string
+	var queueARNs, stateMachineARNs []interface{}
+
+	for _, q := range config.Queues {
+		prefix := toPascalCase(q.Name)
+		queueID := prefix + "Queue"
+
+		props := map[string]interface{}{
+			"QueueName":              q.QueueName(config.StackName),
+			"VisibilityTimeout":      q.VisibilityTimeoutSeconds,
+			"MessageRetentionPeriod": q.MessageRetentionDays * 86400,
+			"Tags": []map[string]interface{}{
+				{"Key": "Name", "Value": q.QueueName(config.StackName)},
+				{"Key": "ManagedBy", "Value": "agentkit"},
+			},
+		}
+		if q.FIFO {
+			props["FifoQueue"] = true
+		}
+		if q.KMSKeyARN != "" {
+			props["KmsMasterKeyId"] = q.KMSKeyARN
+		} else {
+			props["SqsManagedSseEnabled"] = true
+		}
+
+		if q.DeadLetter.IsEnabled() {
+			dlqProps := map[string]interface{}{
+				"QueueName":              q.DeadLetterQueueName(config.StackName),
+				"MessageRetentionPeriod": q.DeadLetter.RetentionDays * 86400,
+			}
+			for _, key := range []string{"FifoQueue", "KmsMasterKeyId", "SqsManagedSseEnabled"} {
+				if v, ok := props[key]; ok {
+					dlqProps[key] = v
+				}
+			}
+			template.Resources[prefix+"DeadLetterQueue"] = CFResource{
+				Type:           "AWS::SQS::Queue",
+				DeletionPolicy: deletionPolicy,
+				Properties:     dlqProps,
+			}
+			props["RedrivePolicy"] = map[string]interface{}{
+				"deadLetterTargetArn": map[string]interface{}{"Fn::GetAtt": []string{prefix + "DeadLetterQueue", "Arn"}},
+				"maxReceiveCount":     q.DeadLetter.MaxReceiveCount,
+			}
+			template.Outputs[prefix+"DeadLetterQueueURL"] = CFOutput{
+				Description: fmt.Sprintf("Dead-letter queue URL for %s", q.Name),
+				Value:       map[string]string{"Ref": prefix + "DeadLetterQueue"},
+			}
+		}
+
+		template.Resources[queueID] = CFResource{
+			Type:           "AWS::SQS::Queue",
+			DeletionPolicy: deletionPolicy,
+			Properties:     props,
+		}
+
+		runtimeRef := fmt.Sprintf("${%sRuntimeArn}", toPascalCase(q.Agent))
+		template.Resources[prefix+"Consumer"] = CFResource{
+			Type: "AWS::StepFunctions::StateMachine",
+			Properties: map[string]interface{}{
+				"StateMachineName": q.queueName(config.StackName, "-consumer"),
+				"StateMachineType": "EXPRESS",
+				"RoleArn":          map[string]interface{}{"Fn::GetAtt": []string{"QueueConsumerRole", "Arn"}},
+				"DefinitionString": map[string]interface{}{"Fn::Sub": q.ConsumerDefinition(runtimeRef)},
+			},
+		}
+
+		template.Resources[prefix+"Pipe"] = CFResource{
+			Type: "AWS::Pipes::Pipe",
+			Properties: map[string]interface{}{
+				"Name":    q.queueName(config.StackName, "-pipe"),
+				"RoleArn": map[string]interface{}{"Fn::GetAtt": []string{"QueuePipeRole", "Arn"}},
+				"Source":  map[string]interface{}{"Fn::GetAtt": []string{queueID, "Arn"}},
+				"SourceParameters": map[string]interface{}{
+					"SqsQueueParameters": map[string]interface{}{"BatchSize": 1},
+				},
+				"Target": map[string]string{"Ref": prefix + "Consumer"},
+				"TargetParameters": map[string]interface{}{
+					"StepFunctionStateMachineParameters": map[string]interface{}{"InvocationType": "REQUEST_RESPONSE"},
+				},
+			},
+		}
+
+		agentNames = append(agentNames, q.Agent)
+		queueARNs = append(queueARNs, map[string]interface{}{"Fn::GetAtt": []string{queueID, "Arn"}})
+		stateMachineARNs = append(stateMachineARNs, map[string]string{"Ref": prefix + "Consumer"})
+
+		template.Outputs[prefix+"QueueURL"] = CFOutput{
+			Description: fmt.Sprintf("Queue URL for asynchronous %s invocations", q.Agent),
+			Value:       map[string]string{"Ref": queueID},
+		}
+		template.Outputs[prefix+"QueueARN"] = CFOutput{
+			Description: fmt.Sprintf("Queue ARN for asynchronous %s invocations", q.Agent),
+			Value:       map[string]interface{}{"Fn::GetAtt": []string{queueID, "Arn"}},
+		}
+	}
+
+	template.Resources["QueueConsumerRole"] = cfServiceRole(config.StackName+"-queue-consumer-role", "states.amazonaws.com",
+		"InvokeAgentRuntime", []map[string]interface{}{
+			{"Effect": "Allow", "Action": "bedrock-agentcore:InvokeAgentRuntime", "Resource": cfInvokeResources(config.agentsNamed(agentNames))},
+		})
+	template.Resources["QueuePipeRole"] = cfServiceRole(config.StackName+"-queue-pipe-role", "pipes.amazonaws.com",
+		"ConsumeQueues", []map[string]interface{}{
+			{"Effect": "Allow", "Action": []string{"sqs:ReceiveMessage", "sqs:DeleteMessage", "sqs:GetQueueAttributes"}, "Resource": queueARNs},
+			{"Effect": "Allow", "Action": "states:StartSyncExecution", "Resource": stateMachineARNs},
+		})
+}
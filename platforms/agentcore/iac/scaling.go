@@ -0,0 +1,121 @@
+package iac
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Session lifecycle bounds enforced by AgentCore Runtime, in seconds.
+const (
+	MinSessionTimeoutSeconds = 60
+	MaxSessionTimeoutSeconds = 28800
+)
+
+// ScalingConfig defines scaling and session lifecycle settings for an agent.
+//
+// AgentCore Runtime scales sessions automatically and exposes only the
+// session lifecycle, so the concurrency, warm capacity and burst settings
+// are rejected by validation; they are kept so that configs setting them
+// fail loudly instead of being ignored.
+type ScalingConfig struct {
+	// MinConcurrentSessions is not supported by AgentCore Runtime and
+	// must be left unset.
+	MinConcurrentSessions int `json:"minConcurrentSessions,omitempty" yaml:"minConcurrentSessions,omitempty"`
+
+	// MaxConcurrentSessions is not supported by AgentCore Runtime and
+	// must be left unset. Concurrency is bounded by the account quota.
+	MaxConcurrentSessions int `json:"maxConcurrentSessions,omitempty" yaml:"maxConcurrentSessions,omitempty"`
+
+	// ProvisionedCapacity is not supported by AgentCore Runtime and
+	// must be left unset.
+	ProvisionedCapacity int `json:"provisionedCapacity,omitempty" yaml:"provisionedCapacity,omitempty"`
+
+	// BurstLimit is not supported by AgentCore Runtime and must be left
+	// unset.
+	BurstLimit int `json:"burstLimit,omitempty" yaml:"burstLimit,omitempty"`
+
+	// IdleSessionTimeoutSeconds terminates sessions idle for this long.
+	// Maps to the runtime's idleRuntimeSessionTimeout.
+	// Range: 60-28800
+	// Default: 900
+	IdleSessionTimeoutSeconds int `json:"idleSessionTimeoutSeconds,omitempty" yaml:"idleSessionTimeoutSeconds,omitempty"`
+
+	// MaxSessionLifetimeSeconds terminates sessions after this long.
+	// Maps to the runtime's maxLifetime.
+	// Range: 60-28800
+	// Default: 28800
+	MaxSessionLifetimeSeconds int `json:"maxSessionLifetimeSeconds,omitempty" yaml:"maxSessionLifetimeSeconds,omitempty"`
+}
+
+// LifecycleConfiguration returns the session lifecycle in the shape the
+// AgentCore Runtime API expects for lifecycleConfiguration.
+// Used by the generators to emit it as an output for the runtime.
+func (s *ScalingConfig) LifecycleConfiguration() map[string]int {
+	return map[string]int{
+		"idleRuntimeSessionTimeout": s.IdleSessionTimeoutSeconds,
+		"maxLifetime":               s.MaxSessionLifetimeSeconds,
+	}
+}
+
+// LifecycleConfigurationJSON returns LifecycleConfiguration as compact JSON text.
+func (s *ScalingConfig) LifecycleConfigurationJSON() string {
+	// json.Marshal sorts map keys and cannot fail for map[string]int.
+	data, _ := json.Marshal(s.LifecycleConfiguration())
+	return string(data)
+}
+
+// applyScalingDefaults fills in Scaling defaults for an agent.
+func applyScalingDefaults(agent *AgentConfig) {
+	s := agent.Scaling
+	if s == nil {
+		return
+	}
+	if s.IdleSessionTimeoutSeconds == 0 {
+		s.IdleSessionTimeoutSeconds = 900
+	}
+	if s.MaxSessionLifetimeSeconds == 0 {
+		s.MaxSessionLifetimeSeconds = MaxSessionTimeoutSeconds
+	}
+}
+
+// validateScaling adds Scaling errors for the agent at index i.
+func validateScaling(report *ValidationReport, i int, agent AgentConfig) {
+	s := agent.Scaling
+	if s == nil {
+		return
+	}
+	path := fmt.Sprintf("agents[%d].scaling", i)
+
+	unsupported := []struct {
+		name  string
+		value int
+	}{
+		{"minConcurrentSessions", s.MinConcurrentSessions},
+		{"maxConcurrentSessions", s.MaxConcurrentSessions},
+		{"provisionedCapacity", s.ProvisionedCapacity},
+		{"burstLimit", s.BurstLimit},
+	}
+	for _, f := range unsupported {
+		if f.value != 0 {
+			report.addError(path+"."+f.name, "agents[%d] (%s): scaling.%s is not supported by AgentCore Runtime, which scales sessions automatically",
+				i, agent.Name, f.name)
+		}
+	}
+	if !validSessionTimeout(s.IdleSessionTimeoutSeconds) {
+		report.addError(path+".idleSessionTimeoutSeconds", "agents[%d] (%s): scaling.idleSessionTimeoutSeconds must be between %d and %d",
+			i, agent.Name, MinSessionTimeoutSeconds, MaxSessionTimeoutSeconds)
+	}
+	if !validSessionTimeout(s.MaxSessionLifetimeSeconds) {
+		report.addError(path+".maxSessionLifetimeSeconds", "agents[%d] (%s): scaling.maxSessionLifetimeSeconds must be between %d and %d",
+			i, agent.Name, MinSessionTimeoutSeconds, MaxSessionTimeoutSeconds)
+	}
+	if s.IdleSessionTimeoutSeconds != 0 && s.MaxSessionLifetimeSeconds != 0 &&
+		s.IdleSessionTimeoutSeconds > s.MaxSessionLifetimeSeconds {
+		report.addError(path+".idleSessionTimeoutSeconds", "agents[%d] (%s): scaling.idleSessionTimeoutSeconds must not exceed maxSessionLifetimeSeconds", i, agent.Name)
+	}
+}
+
+// validSessionTimeout reports whether v is unset or within the AgentCore range.
+func validSessionTimeout(v int) bool {
+	return v == 0 || (v >= MinSessionTimeoutSeconds && v <= MaxSessionTimeoutSeconds)
+}
@@ -0,0 +1,312 @@
+package iac
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ScheduleTargetARN is the EventBridge Scheduler universal target that
+// invokes an AgentCore runtime.
+const ScheduleTargetARN = "arn:aws:scheduler:::aws-sdk:bedrockagentcore:invokeAgentRuntime"
+
+// scheduleNamePattern is the EventBridge Scheduler name constraint.
+var scheduleNamePattern = regexp.MustCompile(`^[0-9a-zA-Z_.-]{1,64}$`)
+
+// ScheduleConfig defines a recurring agent invocation.
+//
+// Schedules are generated as EventBridge Scheduler schedules that call
+// InvokeAgentRuntime. Each run uses the scheduler execution ID as the
+// session ID, so runs do not share session state.
+type ScheduleConfig struct {
+	// Name is the unique schedule name.
+	// Required.
+	Name string `json:"name" yaml:"name"`
+
+	// Agent is the name of the agent to invoke.
+	// Required.
+	Agent string `json:"agent" yaml:"agent"`
+
+	// Description is a description of the schedule.
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+
+	// Expression is a cron, rate or one-time expression.
+	// Examples: "cron(0 2 * * ? *)", "rate(1 hour)", "at(2025-01-01T00:00:00)"
+	// Required.
+	Expression string `json:"expression" yaml:"expression"`
+
+	// Timezone is the IANA time zone for cron and at expressions.
+	// Default: "UTC"
+	Timezone string `json:"timezone,omitempty" yaml:"timezone,omitempty"`
+
+	// Prompt is the prompt sent to the agent.
+	// May contain <aws.scheduler.scheduled-time> and
+	// <aws.scheduler.execution-id>, which are replaced at run time.
+	Prompt string `json:"prompt,omitempty" yaml:"prompt,omitempty"`
+
+	// Input is additional payload fields sent to the agent. Prompt, if
+	// set, is added as the "prompt" field.
+	Input map[string]interface{} `json:"input,omitempty" yaml:"input,omitempty"`
+
+	// Enabled controls whether the schedule runs.
+	// Default: true
+	Enabled *bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+}
+
+// IsEnabled reports whether the schedule runs.
+func (s *ScheduleConfig) IsEnabled() bool {
+	return s.Enabled == nil || *s.Enabled
+}
+
+// State returns the EventBridge Scheduler state, "ENABLED" or "DISABLED".
+func (s *ScheduleConfig) State() string {
+	if s.IsEnabled() {
+		return "ENABLED"
+	}
+	return "DISABLED"
+}
+
+// Payload returns the agent invocation payload as JSON text.
+func (s *ScheduleConfig) Payload() (string, error) {
+	payload := make(map[string]interface{}, len(s.Input)+1)
+	for k, v := range s.Input {
+		payload[k] = v
+	}
+	if s.Prompt != "" {
+		payload["prompt"] = s.Prompt
+	}
+	data, err := marshalUnescaped(payload)
+	if err != nil {
+		return "", fmt.Errorf("schedule %s: failed to encode input: %w", s.Name, err)
+	}
+	return data, nil
+}
+
+// TargetInput returns the InvokeAgentRuntime request for the schedule
+// target, using runtimeARN as the agent runtime ARN.
+func (s *ScheduleConfig) TargetInput(runtimeARN string) (string, error) {
+	payload, err := s.Payload()
+	if err != nil {
+		return "", err
+	}
+	data, err := marshalUnescaped(map[string]string{
+		"AgentRuntimeArn":  runtimeARN,
+		"RuntimeSessionId": "<aws.scheduler.execution-id>",
+		"Payload":          payload,
+	})
+	if err != nil {
+		return "", fmt.Errorf("schedule %s: failed to encode target input: %w", s.Name, err)
+	}
+	return data, nil
+}
+
+// marshalUnescaped encodes v as compact JSON without escaping '<', '>'
+// and '&', so scheduler placeholders such as <aws.scheduler.execution-id>
+// survive encoding.
+func marshalUnescaped(v interface{}) (string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// InvokedAgents returns the agents invoked by resources in the stack,
+// such as schedules and queues, in config order.
+func (c *StackConfig) InvokedAgents() []AgentConfig {
+	var names []string
+	for _, s := range c.Schedules {
+		names = append(names, s.Agent)
+	}
+	for _, q := range c.Queues {
+		names = append(names, q.Agent)
+	}
+	return c.agentsNamed(names)
+}
+
+// agentsNamed returns the agents whose names are in names, in config order.
+func (c *StackConfig) agentsNamed(names []string) []AgentConfig {
+	var agents []AgentConfig
+	for _, agent := range c.Agents {
+		if slices.Contains(names, agent.Name) {
+			agents = append(agents, agent)
+		}
+	}
+	return agents
+}
+
+// applyScheduleDefaults fills in Schedule defaults.
+func (c *StackConfig) applyScheduleDefaults() {
+	for i := range c.Schedules {
+		if c.Schedules[i].Timezone == "" {
+			c.Schedules[i].Timezone = "UTC"
+		}
+	}
+}
+
+// validateSchedules adds Schedule errors to the report.
+func (c *StackConfig) validateSchedules(report *ValidationReport, agentNames map[string]bool) {
+	names := make(map[string]bool)
+	for i, s := range c.Schedules {
+		path := fmt.Sprintf("schedules[%d]", i)
+
+		if !scheduleNamePattern.MatchString(s.Name) {
+			report.addError(path+".name", "schedules[%d]: name is required and must be 1-64 letters, digits, '-', '_' or '.'", i)
+		} else if names[s.Name] {
+			report.addError(path+".name", "duplicate schedule name: %s", s.Name)
+		}
+		names[s.Name] = true
+
+		if !agentNames[s.Agent] {
+			report.addError(path+".agent", "schedules[%d] (%s): agent '%s' does not match any agent name", i, s.Name, s.Agent)
+		}
+		if !isScheduleExpression(s.Expression) {
+			report.addError(path+".expression", "schedules[%d] (%s): expression must be cron(...), rate(...) or at(...)", i, s.Name)
+		}
+		if s.Prompt == "" && len(s.Input) == 0 {
+			report.addError(path+".prompt", "schedules[%d] (%s): prompt or input is required", i, s.Name)
+		}
+		if _, err := s.Payload(); err != nil {
+			report.addError(path+".input", "schedules[%d] (%s): input must be JSON-encodable: %v", i, s.Name, err)
+		}
+	}
+}
+
+// isScheduleExpression reports whether expr is a cron, rate or at expression.
+func isScheduleExpression(expr string) bool {
+	for _, prefix := range []string{"cron(", "rate(", "at("} {
+		if strings.HasPrefix(expr, prefix) && strings.HasSuffix(expr, ")") && len(expr) > len(prefix)+1 {
+			return true
+		}
+	}
+	return false
+}
+
+// cfRuntimeARN returns the CloudFormation reference to an agent's runtime
+// ARN parameter.
+func cfRuntimeARN(agent AgentConfig) map[string]string {
+	return map[string]string{"Ref": fmt.Sprintf("%sRuntimeArn", toPascalCase(agent.Name))}
+}
+
+// cfInvokeResources returns the IAM resources for invoking each agent's
+// runtime and runtime endpoints.
+func cfInvokeResources(agents []AgentConfig) []interface{} {
+	var resources []interface{}
+	for _, agent := range agents {
+		resources = append(resources, cfRuntimeARN(agent), map[string]interface{}{
+			"Fn::Sub": fmt.Sprintf("${%sRuntimeArn}/*", toPascalCase(agent.Name)),
+		})
+	}
+	return resources
+}
+
+// cfServiceRole returns an IAM role resource assumable by service with a
+// single inline policy.
+func cfServiceRole(roleName, service, policyName string, statements []map[string]interface{}) CFResource {
+	return CFResource{
+		Type: "AWS::IAM::Role",
+		Properties: map[string]interface{}{
+			"RoleName": roleName,
+			"AssumeRolePolicyDocument": map[string]interface{}{
+				"Version": "2012-10-17",
+				"Statement": []map[string]interface{}{
+					{
+						"Effect":    "Allow",
+						"Principal": map[string]interface{}{"Service": service},
+						"Action":    "sts:AssumeRole",
+					},
+				},
+			},
+			"Policies": []map[string]interface{}{
+				{
+					"PolicyName": policyName,
+					"PolicyDocument": map[string]interface{}{
+						"Version":   "2012-10-17",
+						"Statement": statements,
+					},
+				},
+			},
+		},
+	}
+}
+
+// addRuntimeARNParameters adds runtime ARN parameters for agents invoked by
+// stack resources or referenced by outputs.
+func addRuntimeARNParameters(template *CloudFormationTemplate, config *StackConfig) {
+	for _, agent := range config.RuntimeARNAgents() {
+		template.Parameters[fmt.Sprintf("%sRuntimeArn", toPascalCase(agent.Name))] = CFParameter{
+			Type:        "String",
+			Description: fmt.Sprintf("AgentCore runtime ARN for %s agent", agent.Name),
+			Default:     agent.RuntimeARN,
+		}
+	}
+}
+
+// addScheduleResources adds EventBridge Scheduler schedules and their role.
+func addScheduleResources(template *CloudFormationTemplate, config *StackConfig) error {
+	if len(config.Schedules) == 0 {
+		return nil
+	}
+
+	var names []string
+	for _, s := range config.Schedules {
+		names = append(names, s.Agent)
+	}
+	template.Resources["SchedulerRole"] = cfServiceRole(config.StackName+"-scheduler-role", "scheduler.amazonaws.com",
+		"InvokeAgentRuntime", []map[string]interface{}{
+			{"Effect": "Allow", "Action": "bedrock-agentcore:InvokeAgentRuntime", "Resource": cfInvokeResources(config.agentsNamed(names))},
+		})
+
+	agents := make(map[string]AgentConfig, len(config.Agents))
+	for _, agent := range config.Agents {
+		agents[agent.Name] = agent
+	}
+
+	for _, s := range config.Schedules {
+		agent := agents[s.Agent]
+		input, err := s.TargetInput(fmt.Sprintf("${%sRuntimeArn}", toPascalCase(agent.Name)))
+		if err != nil {
+			return err
+		}
+
+		props := map[string]interface{}{
+			"Name":                       fmt.Sprintf("%s-%s", config.StackName, s.Name),
+			"ScheduleExpression":         s.Expression,
+			"ScheduleExpressionTimezone": s.Timezone,
+			"State":                      s.State(),
+			"FlexibleTimeWindow":         map[string]string{"Mode": "OFF"},
+			"Target": map[string]interface{}{
+				"Arn":     ScheduleTargetARN,
+				"RoleArn": map[string]interface{}{"Fn::GetAtt": []string{"SchedulerRole", "Arn"}},
+				// Escape literal "${" so Fn::Sub only substitutes the runtime ARN.
+				"Input": map[string]interface{}{"Fn::Sub": escapeSubLiterals(input, agent)},
+			},
+		}
+		if s.Description != "" {
+			props["Description"] = s.Description
+		}
+
+		template.Resources[fmt.Sprintf("%sSchedule", toPascalCase(s.Name))] = CFResource{
+			Type:       "AWS::Scheduler::Schedule",
+			Properties: props,
+		}
+	}
+
+	return nil
+}
+
+// escapeSubLiterals escapes "${" in s for Fn::Sub, except the agent's
+// runtime ARN reference.
+func escapeSubLiterals(s string, agent AgentConfig) string {
+	ref := fmt.Sprintf("${%sRuntimeArn}", toPascalCase(agent.Name))
+	parts := strings.Split(s, ref)
+	for i, part := range parts {
+		parts[i] = strings.ReplaceAll(part, "${", "${!")
+	}
+	return strings.Join(parts, ref)
+}
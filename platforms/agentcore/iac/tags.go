@@ -0,0 +1,138 @@
+package iac
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// MaxTagsPerResource is the AWS limit on user tags per resource.
+const MaxTagsPerResource = 50
+
+// TagPolicyConfig enforces tagging rules, such as cost-allocation tags,
+// on the stack and every agent.
+type TagPolicyConfig struct {
+	// RequiredKeys are tag keys every agent must have, from the stack
+	// tags or its own tags.
+	// Example: ["CostCenter", "Owner"]
+	RequiredKeys []string `json:"requiredKeys,omitempty" yaml:"requiredKeys,omitempty"`
+
+	// AllowedValues restricts the values of the given tag keys.
+	// Example: {"Environment": ["dev", "staging", "prod"]}
+	AllowedValues map[string][]string `json:"allowedValues,omitempty" yaml:"allowedValues,omitempty"`
+}
+
+// MergeTags merges tag maps into a new map. Later maps take precedence.
+func MergeTags(tags ...map[string]string) map[string]string {
+	merged := make(map[string]string)
+	for _, t := range tags {
+		for k, v := range t {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+// AgentTags returns the effective tags for an agent: the stack tags
+// merged with the agent's own tags.
+func (c *StackConfig) AgentTags(agent AgentConfig) map[string]string {
+	return MergeTags(c.Tags, agent.Tags)
+}
+
+// sortedTagKeys returns the keys of tags in sorted order.
+func sortedTagKeys(tags map[string]string) []string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// cfTags returns a CloudFormation tag list with the Name and ManagedBy tags
+// followed by extra tags in key order.
+func cfTags(name string, extra map[string]string) []map[string]interface{} {
+	tags := []map[string]interface{}{
+		{"Key": "Name", "Value": name},
+		{"Key": "ManagedBy", "Value": "agentkit"},
+	}
+	for _, k := range sortedTagKeys(extra) {
+		if k == "Name" || k == "ManagedBy" {
+			continue
+		}
+		tags = append(tags, map[string]interface{}{"Key": k, "Value": extra[k]})
+	}
+	return tags
+}
+
+// validateTags adds errors for tags that violate AWS tag constraints.
+func validateTags(report *ValidationReport, path string, tags map[string]string) {
+	if len(tags) > MaxTagsPerResource {
+		report.addError(path, "%s: at most %d tags are allowed, got %d", path, MaxTagsPerResource, len(tags))
+	}
+	for _, k := range sortedTagKeys(tags) {
+		switch {
+		case k == "" || len(k) > 128:
+			report.addError(path, "%s: tag keys must be 1-128 characters: %q", path, k)
+		case strings.HasPrefix(strings.ToLower(k), "aws:"):
+			report.addError(path+"."+k, "%s: tag key %s uses the reserved aws: prefix", path, k)
+		case len(tags[k]) > 256:
+			report.addError(path+"."+k, "%s: tag %s value must be at most 256 characters", path, k)
+		}
+	}
+}
+
+// validateAgentTags adds tag errors for the agent at index i.
+func validateAgentTags(report *ValidationReport, i int, agent AgentConfig) {
+	validateTags(report, fmt.Sprintf("agents[%d].tags", i), agent.Tags)
+}
+
+// validateTagPolicy adds stack tag and tag policy errors to the report.
+func (c *StackConfig) validateTagPolicy(report *ValidationReport) {
+	validateTags(report, "tags", c.Tags)
+	for i, agent := range c.Agents {
+		if len(agent.Tags) > 0 {
+			if n := len(c.AgentTags(agent)); n > MaxTagsPerResource {
+				report.addError(fmt.Sprintf("agents[%d].tags", i), "agents[%d] (%s): at most %d tags are allowed including stack tags, got %d", i, agent.Name, MaxTagsPerResource, n)
+			}
+		}
+	}
+
+	p := c.TagPolicy
+	if p == nil {
+		return
+	}
+
+	for _, key := range p.RequiredKeys {
+		if _, ok := c.Tags[key]; ok {
+			continue
+		}
+		var missing []int
+		for i, agent := range c.Agents {
+			if _, ok := agent.Tags[key]; !ok {
+				missing = append(missing, i)
+			}
+		}
+		if len(missing) == len(c.Agents) {
+			report.addError("tags."+key, "tagPolicy: required tag %s is missing", key)
+			continue
+		}
+		for _, i := range missing {
+			report.addError(fmt.Sprintf("agents[%d].tags.%s", i, key), "agents[%d] (%s): tagPolicy: required tag %s is missing", i, c.Agents[i].Name, key)
+		}
+	}
+
+	checkAllowed := func(path, owner string, tags map[string]string) {
+		for _, k := range sortedTagKeys(tags) {
+			allowed, ok := p.AllowedValues[k]
+			if ok && !slices.Contains(allowed, tags[k]) {
+				report.addError(path+"."+k, "%stagPolicy: tag %s value %q must be one of %v", owner, k, tags[k], allowed)
+			}
+		}
+	}
+	checkAllowed("tags", "", c.Tags)
+	for i, agent := range c.Agents {
+		checkAllowed(fmt.Sprintf("agents[%d].tags", i), fmt.Sprintf("agents[%d] (%s): ", i, agent.Name), agent.Tags)
+	}
+}
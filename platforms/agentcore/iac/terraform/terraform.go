@@ -0,0 +1,1188 @@
+// Package terraform renders an iac.StackConfig as a Terraform module.
+//
+// The generated module uses Terraform's JSON syntax (.tf.json) and creates
+// the same foundational resources as iac.GenerateCloudFormation: VPC,
+// IAM execution role, and CloudWatch Log Group. This lets Terraform shops
+// consume the shared stack configuration without CDK or Pulumi.
+//
+// Example:
+//
+//	config, _ := iac.LoadStackConfigFromFile("config.yaml")
+//	module, _ := terraform.Generate(config)
+//	os.WriteFile("main.tf.json", module, 0644)
+//	// Then: terraform init && terraform apply
+package terraform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/plexusone/agentkit/platforms/agentcore/iac"
+)
+
+// DefaultAWSProviderVersion is the AWS provider version constraint
+// written to the required_providers block.
+const DefaultAWSProviderVersion = ">= 5.0"
+
+// MemoryAWSProviderVersion is the AWS provider constraint used when the
+// module contains AgentCore Memory resources.
+const MemoryAWSProviderVersion = ">= 6.18"
+
+// Module represents a Terraform module in JSON syntax.
+type Module struct {
+	Comment   string                            `json:"//,omitempty"`
+	Terraform map[string]interface{}            `json:"terraform"`
+	Provider  map[string]interface{}            `json:"provider"`
+	Variable  map[string]Variable               `json:"variable,omitempty"`
+	Data      map[string]map[string]interface{} `json:"data,omitempty"`
+	Resource  map[string]map[string]interface{} `json:"resource"`
+	Output    map[string]Output                 `json:"output,omitempty"`
+	Locals    map[string]interface{}            `json:"locals,omitempty"`
+}
+
+// Variable represents a Terraform input variable.
+type Variable struct {
+	Type        string      `json:"type,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Default     interface{} `json:"default,omitempty"`
+	Sensitive   bool        `json:"sensitive,omitempty"`
+}
+
+// Output represents a Terraform output value.
+// Value is usually an interpolation string, but may be any JSON value.
+type Output struct {
+	Description string      `json:"description,omitempty"`
+	Value       interface{} `json:"value"`
+}
+
+// Generate renders the StackConfig as a Terraform JSON module.
+func Generate(config *iac.StackConfig) ([]byte, error) {
+	module, err := Build(config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Disable HTML escaping so version constraints like ">= 5.0" stay readable.
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(module); err != nil {
+		return nil, fmt.Errorf("failed to generate JSON: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Build constructs the Terraform module for the StackConfig without
+// serializing it, so callers can add their own resources.
+func Build(config *iac.StackConfig) (*Module, error) {
+	config.ApplyDefaults()
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	providerVersion := DefaultAWSProviderVersion
+	if len(config.MemoryAgents()) > 0 {
+		providerVersion = MemoryAWSProviderVersion
+	}
+
+	module := &Module{
+		Comment: fmt.Sprintf("Terraform module generated by agentkit for stack %s. "+
+			"Creates foundational resources (VPC, IAM, Logs); AgentCore agent resources "+
+			"are configured separately.", config.StackName),
+		Terraform: map[string]interface{}{
+			"required_providers": map[string]interface{}{
+				"aws": map[string]string{
+					"source":  "hashicorp/aws",
+					"version": providerVersion,
+				},
+			},
+		},
+		Provider: map[string]interface{}{
+			"aws": map[string]interface{}{
+				"default_tags": map[string]interface{}{
+					"tags": config.Tags,
+				},
+			},
+		},
+		Variable: make(map[string]Variable),
+		Data:     make(map[string]map[string]interface{}),
+		Resource: make(map[string]map[string]interface{}),
+		Output:   make(map[string]Output),
+		Locals: map[string]interface{}{
+			"stack_name": config.StackName,
+		},
+	}
+
+	addVariables(module, config)
+
+	module.Data["aws_caller_identity"] = map[string]interface{}{"current": map[string]interface{}{}}
+	module.Data["aws_region"] = map[string]interface{}{"current": map[string]interface{}{}}
+
+	if config.VPC.CreateVPC {
+		addVPCResources(module, config)
+	}
+
+	addIAMResources(module, config)
+
+	if config.Observability.EnableCloudWatchLogs {
+		addLogGroupResource(module, config)
+	}
+	addAgentLogGroupResources(module, config)
+
+	addAuthorizerFunctionResources(module, config)
+
+	addECRResources(module, config)
+
+	addMemoryResources(module, config)
+
+	addDomainResources(module, config)
+
+	if err := addScheduleResources(module, config); err != nil {
+		return nil, err
+	}
+
+	addQueueResources(module, config)
+
+	addArtifactsResources(module, config)
+
+	addAlarmResources(module, config)
+
+	if err := addDashboardResources(module, config); err != nil {
+		return nil, err
+	}
+
+	addOutputs(module, config)
+
+	if err := addDeclaredOutputs(module, config); err != nil {
+		return nil, err
+	}
+
+	return module, nil
+}
+
+// GenerateFile renders the StackConfig and writes it to outputPath.
+func GenerateFile(config *iac.StackConfig, outputPath string) error {
+	module, err := Generate(config)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outputPath, module, 0600)
+}
+
+// GenerateFromFile loads a config file and writes the Terraform module.
+func GenerateFromFile(configPath, outputPath string) error {
+	config, err := iac.LoadStackConfigFromFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	return GenerateFile(config, outputPath)
+}
+
+// addResource adds a resource of the given type and name.
+func addResource(module *Module, resourceType, name string, body map[string]interface{}) {
+	if module.Resource[resourceType] == nil {
+		module.Resource[resourceType] = make(map[string]interface{})
+	}
+	module.Resource[resourceType][name] = body
+}
+
+// addVariables adds input variables mirroring the CloudFormation parameters.
+func addVariables(module *Module, config *iac.StackConfig) {
+	module.Variable["environment"] = Variable{
+		Type:        "string",
+		Description: "Deployment environment",
+		Default:     "production",
+	}
+
+	for _, agent := range config.Agents {
+		if agent.ContainerImage == "" {
+			continue // Image is built into a module-managed ECR repository
+		}
+		module.Variable[toSnakeCase(agent.Name)+"_container_image"] = Variable{
+			Type:        "string",
+			Description: fmt.Sprintf("Container image for %s agent", agent.Name),
+			Default:     agent.ContainerImage,
+		}
+	}
+
+	for _, agent := range config.RuntimeARNAgents() {
+		module.Variable[toSnakeCase(agent.Name)+"_runtime_arn"] = Variable{
+			Type:        "string",
+			Description: fmt.Sprintf("AgentCore runtime ARN for %s agent", agent.Name),
+			Default:     agent.RuntimeARN,
+		}
+	}
+
+	if config.Observability.Provider != "cloudwatch" && config.Observability.Provider != "" {
+		module.Variable["observability_api_key"] = Variable{
+			Type:        "string",
+			Description: fmt.Sprintf("API key for %s observability", config.Observability.Provider),
+			Default:     "",
+			Sensitive:   true,
+		}
+	}
+}
+
+// addVPCResources adds VPC-related resources.
+func addVPCResources(module *Module, config *iac.StackConfig) {
+	stackName := config.StackName
+
+	module.Data["aws_availability_zones"] = map[string]interface{}{
+		"available": map[string]interface{}{"state": "available"},
+	}
+
+	addResource(module, "aws_vpc", "main", map[string]interface{}{
+		"cidr_block":           config.VPC.VPCCidr,
+		"enable_dns_hostnames": true,
+		"enable_dns_support":   true,
+		"tags":                 map[string]string{"Name": stackName + "-vpc"},
+	})
+
+	addResource(module, "aws_internet_gateway", "main", map[string]interface{}{
+		"vpc_id": "${aws_vpc.main.id}",
+		"tags":   map[string]string{"Name": stackName + "-igw"},
+	})
+
+	addResource(module, "aws_subnet", "public_1", map[string]interface{}{
+		"vpc_id":                  "${aws_vpc.main.id}",
+		"cidr_block":              iac.PublicSubnetCIDR,
+		"availability_zone":       "${data.aws_availability_zones.available.names[0]}",
+		"map_public_ip_on_launch": true,
+		"tags":                    map[string]string{"Name": stackName + "-public-1"},
+	})
+
+	addResource(module, "aws_subnet", "private_1", map[string]interface{}{
+		"vpc_id":            "${aws_vpc.main.id}",
+		"cidr_block":        iac.PrivateSubnetCIDR,
+		"availability_zone": "${data.aws_availability_zones.available.names[0]}",
+		"tags":              map[string]string{"Name": stackName + "-private-1"},
+	})
+
+	addResource(module, "aws_eip", "nat", map[string]interface{}{
+		"domain":     "vpc",
+		"depends_on": []string{"aws_internet_gateway.main"},
+	})
+
+	addResource(module, "aws_nat_gateway", "main", map[string]interface{}{
+		"allocation_id": "${aws_eip.nat.id}",
+		"subnet_id":     "${aws_subnet.public_1.id}",
+		"tags":          map[string]string{"Name": stackName + "-nat"},
+	})
+
+	addResource(module, "aws_security_group", "agents", map[string]interface{}{
+		"name":        stackName + "-sg",
+		"description": fmt.Sprintf("Security group for %s AgentCore agents", stackName),
+		"vpc_id":      "${aws_vpc.main.id}",
+		"tags":        map[string]string{"Name": stackName + "-sg"},
+	})
+
+	addResource(module, "aws_vpc_security_group_ingress_rule", "agents_internal", map[string]interface{}{
+		"security_group_id":            "${aws_security_group.agents.id}",
+		"referenced_security_group_id": "${aws_security_group.agents.id}",
+		"ip_protocol":                  "-1",
+		"description":                  "Allow communication between agents",
+	})
+
+	addResource(module, "aws_vpc_security_group_egress_rule", "all_outbound", map[string]interface{}{
+		"security_group_id": "${aws_security_group.agents.id}",
+		"cidr_ipv4":         "0.0.0.0/0",
+		"ip_protocol":       "-1",
+		"description":       "Allow all outbound traffic",
+	})
+}
+
+// addIAMResources adds the execution roles and their inline policies.
+func addIAMResources(module *Module, config *iac.StackConfig) {
+	assumeRole := map[string]interface{}{
+		"Version": "2012-10-17",
+		"Statement": []map[string]interface{}{
+			{
+				"Effect": "Allow",
+				"Principal": map[string]interface{}{
+					"Service": []string{
+						"bedrock.amazonaws.com",
+						"lambda.amazonaws.com",
+					},
+				},
+				"Action": "sts:AssumeRole",
+			},
+		},
+	}
+
+	for _, r := range config.ExecutionRoles() {
+		name := executionRoleName(r.Agent)
+
+		role := map[string]interface{}{
+			"name":               r.RoleName,
+			"assume_role_policy": mustJSON(assumeRole),
+			"tags":               map[string]string{"Name": r.RoleName},
+		}
+		if config.IAM.PermissionsBoundaryARN != "" {
+			role["permissions_boundary"] = config.IAM.PermissionsBoundaryARN
+		}
+		addResource(module, "aws_iam_role", name, role)
+
+		addResource(module, "aws_iam_role_policy", policyName(r.Agent), map[string]interface{}{
+			"name": "AgentCorePolicy",
+			"role": fmt.Sprintf("${aws_iam_role.%s.id}", name),
+			"policy": mustJSON(map[string]interface{}{
+				"Version":   "2012-10-17",
+				"Statement": r.Statements,
+			}),
+		})
+
+		for i, policyARN := range config.IAM.AdditionalPolicies {
+			addResource(module, "aws_iam_role_policy_attachment", fmt.Sprintf("%s_%d", additionalPrefix(r.Agent), i+1), map[string]interface{}{
+				"role":       fmt.Sprintf("${aws_iam_role.%s.name}", name),
+				"policy_arn": policyARN,
+			})
+		}
+	}
+}
+
+// executionRoleName returns the resource name of an agent's execution
+// role, or of the shared role if agent is empty.
+func executionRoleName(agent string) string {
+	if agent == "" {
+		return "execution"
+	}
+	return "execution_" + toSnakeCase(agent)
+}
+
+// policyName returns the resource name of an execution role's inline policy.
+func policyName(agent string) string {
+	if agent == "" {
+		return "agentcore"
+	}
+	return "agentcore_" + toSnakeCase(agent)
+}
+
+// additionalPrefix returns the resource name prefix of an execution role's
+// additional policy attachments.
+func additionalPrefix(agent string) string {
+	if agent == "" {
+		return "additional"
+	}
+	return "additional_" + toSnakeCase(agent)
+}
+
+// addLogGroupResource adds the CloudWatch Log Group.
+func addLogGroupResource(module *Module, config *iac.StackConfig) {
+	logGroup := map[string]interface{}{
+		"name":              fmt.Sprintf("/aws/agentcore/%s", config.StackName),
+		"retention_in_days": config.Observability.LogRetentionDays,
+		"tags":              map[string]string{"Name": config.StackName + "-logs"},
+	}
+	if config.RemovalPolicy == "retain" {
+		logGroup["skip_destroy"] = true
+	}
+	addResource(module, "aws_cloudwatch_log_group", "agents", logGroup)
+}
+
+// addAgentLogGroupResources adds a log group for each agent with its own logging.
+func addAgentLogGroupResources(module *Module, config *iac.StackConfig) {
+	for _, agent := range config.LoggingAgents() {
+		l := agent.Logging
+		name := toSnakeCase(agent.Name) + "_logs"
+		logGroup := map[string]interface{}{
+			"name":              l.LogGroupName,
+			"retention_in_days": l.RetentionDays,
+			"tags":              map[string]string{"Name": fmt.Sprintf("%s-%s-logs", config.StackName, agent.Name)},
+		}
+		if l.KMSKeyARN != "" {
+			logGroup["kms_key_id"] = l.KMSKeyARN
+		}
+		if config.RemovalPolicy == "retain" {
+			logGroup["skip_destroy"] = true
+		}
+		addResource(module, "aws_cloudwatch_log_group", name, logGroup)
+		module.Output[toSnakeCase(agent.Name)+"_log_group_name"] = Output{
+			Description: fmt.Sprintf("CloudWatch Log Group Name for %s agent", agent.Name),
+			Value:       fmt.Sprintf("${aws_cloudwatch_log_group.%s.name}", name),
+		}
+	}
+}
+
+// addAuthorizerFunctionResources adds the Lambda authorizer functions
+// deployed by the stack and their execution roles. Code directories are
+// zipped with the archive provider.
+func addAuthorizerFunctionResources(module *Module, config *iac.StackConfig) {
+	for _, agent := range config.AuthorizerFunctionAgents() {
+		f := agent.Authorizer.Function
+		name := toSnakeCase(agent.Name) + "_authorizer"
+
+		addServiceRole(module, name, f.RoleName(), "lambda.amazonaws.com", "Logs", f.PolicyStatements())
+
+		function := map[string]interface{}{
+			"function_name": f.Name,
+			"description":   fmt.Sprintf("Authorizer for %s agent", agent.Name),
+			"role":          fmt.Sprintf("${aws_iam_role.%s.arn}", name),
+			"architectures": []string{f.Architecture},
+			"memory_size":   f.MemoryMB,
+			"timeout":       f.TimeoutSeconds,
+			"tags":          iac.MergeTags(agent.Tags, map[string]string{"Name": f.Name}),
+		}
+		switch {
+		case f.ImageURI != "":
+			function["package_type"] = "Image"
+			function["image_uri"] = f.ImageURI
+		case f.IsZip():
+			function["filename"] = f.CodePath
+			function["source_code_hash"] = fmt.Sprintf("${filebase64sha256(%q)}", f.CodePath)
+		default:
+			if module.Data["archive_file"] == nil {
+				module.Data["archive_file"] = make(map[string]interface{})
+				module.Terraform["required_providers"].(map[string]interface{})["archive"] = map[string]string{
+					"source": "hashicorp/archive",
+				}
+			}
+			module.Data["archive_file"][name] = map[string]interface{}{
+				"type":        "zip",
+				"source_dir":  f.CodePath,
+				"output_path": fmt.Sprintf("${path.module}/.build/%s.zip", f.Name),
+			}
+			function["filename"] = fmt.Sprintf("${data.archive_file.%s.output_path}", name)
+			function["source_code_hash"] = fmt.Sprintf("${data.archive_file.%s.output_base64sha256}", name)
+		}
+		if f.ImageURI == "" {
+			function["runtime"] = f.Runtime
+			function["handler"] = f.Handler
+		}
+		if len(f.Environment) > 0 {
+			function["environment"] = map[string]interface{}{"variables": f.Environment}
+		}
+		addResource(module, "aws_lambda_function", name, function)
+
+		module.Output[name+"_arn"] = Output{
+			Description: fmt.Sprintf("Lambda authorizer ARN for %s agent", agent.Name),
+			Value:       fmt.Sprintf("${aws_lambda_function.%s.arn}", name),
+		}
+	}
+}
+
+// addOutputs adds output values mirroring the CloudFormation outputs.
+func addOutputs(module *Module, config *iac.StackConfig) {
+	if config.VPC.CreateVPC {
+		module.Output["vpc_id"] = Output{Description: "VPC ID", Value: "${aws_vpc.main.id}"}
+		module.Output["security_group_id"] = Output{Description: "Security Group ID", Value: "${aws_security_group.agents.id}"}
+		module.Output["private_subnet_id"] = Output{Description: "Private Subnet ID", Value: "${aws_subnet.private_1.id}"}
+	}
+
+	for _, r := range config.ExecutionRoles() {
+		name := executionRoleName(r.Agent)
+		description := "IAM Execution Role ARN"
+		if r.Agent != "" {
+			description = fmt.Sprintf("IAM Execution Role ARN for %s", r.Agent)
+		}
+		module.Output[name+"_role_arn"] = Output{
+			Description: description,
+			Value:       fmt.Sprintf("${aws_iam_role.%s.arn}", name),
+		}
+	}
+
+	if config.Observability.EnableCloudWatchLogs {
+		module.Output["log_group_name"] = Output{
+			Description: "CloudWatch Log Group Name",
+			Value:       "${aws_cloudwatch_log_group.agents.name}",
+		}
+	}
+
+	if config.Hooks != nil {
+		module.Output["hooks"] = Output{
+			Description: "Deployment lifecycle hooks",
+			Value:       config.Hooks.Values(),
+		}
+	}
+
+	for _, agent := range config.Agents {
+		name := toSnakeCase(agent.Name)
+		module.Output[name+"_container_image"] = Output{
+			Description: fmt.Sprintf("Container image for %s agent", agent.Name),
+			Value:       imageValue(agent),
+		}
+		if auth := agent.Authorizer.InboundConfiguration(); auth != nil {
+			module.Output[name+"_authorizer_configuration"] = Output{
+				Description: fmt.Sprintf("Inbound authorizer configuration for %s agent", agent.Name),
+				Value:       auth,
+			}
+		}
+		if agent.Scaling != nil {
+			module.Output[name+"_lifecycle_configuration"] = Output{
+				Description: fmt.Sprintf("Runtime lifecycle configuration for %s agent", agent.Name),
+				Value:       agent.Scaling.LifecycleConfiguration(),
+			}
+		}
+	}
+}
+
+// addECRResources adds ECR repositories for agents with ImageBuild.
+func addECRResources(module *Module, config *iac.StackConfig) {
+	for _, agent := range config.Agents {
+		if agent.ImageBuild == nil || !agent.ImageBuild.Repository.ShouldCreate() {
+			continue
+		}
+		repo := agent.ImageBuild.Repository
+		name := toSnakeCase(agent.Name)
+
+		encryption := map[string]interface{}{"encryption_type": "AES256"}
+		if repo.KMSKeyARN != "" {
+			encryption = map[string]interface{}{"encryption_type": "KMS", "kms_key": repo.KMSKeyARN}
+		}
+
+		addResource(module, "aws_ecr_repository", name, map[string]interface{}{
+			"name":                 repo.Name,
+			"image_tag_mutability": repo.ImageTagMutability,
+			"force_delete":         config.RemovalPolicy != "retain",
+			"image_scanning_configuration": map[string]interface{}{
+				"scan_on_push": repo.ScanOnPush,
+			},
+			"encryption_configuration": encryption,
+			"tags":                     iac.MergeTags(agent.Tags, map[string]string{"Name": repo.Name}),
+		})
+
+		if policy := repo.Lifecycle.PolicyText(); policy != "" {
+			addResource(module, "aws_ecr_lifecycle_policy", name, map[string]interface{}{
+				"repository": fmt.Sprintf("${aws_ecr_repository.%s.name}", name),
+				"policy":     policy,
+			})
+		}
+
+		module.Output[name+"_repository_url"] = Output{
+			Description: fmt.Sprintf("ECR repository URL for %s agent", agent.Name),
+			Value:       fmt.Sprintf("${aws_ecr_repository.%s.repository_url}", name),
+		}
+	}
+}
+
+// addMemoryResources adds AgentCore Memory resources for agents with memory enabled.
+func addMemoryResources(module *Module, config *iac.StackConfig) {
+	agents := config.MemoryAgents()
+	if len(agents) == 0 {
+		return
+	}
+	for _, agent := range agents {
+		m := agent.Memory
+		name := toSnakeCase(agent.Name)
+
+		memory := map[string]interface{}{
+			"name":                  m.Name,
+			"description":           fmt.Sprintf("Memory for %s agent", agent.Name),
+			"event_expiry_duration": m.EventExpiryDays,
+			"tags":                  iac.MergeTags(agent.Tags, map[string]string{"Name": m.Name}),
+		}
+		if m.EncryptionKeyARN != "" {
+			memory["encryption_key_arn"] = m.EncryptionKeyARN
+		}
+		addResource(module, "aws_bedrockagentcore_memory", name, memory)
+
+		for _, strategy := range m.Strategies {
+			strategyName := strings.ToLower(strategy)
+			addResource(module, "aws_bedrockagentcore_memory_strategy", name+"_"+strategyName, map[string]interface{}{
+				"name":       strategyName,
+				"memory_id":  fmt.Sprintf("${aws_bedrockagentcore_memory.%s.id}", name),
+				"type":       strategy,
+				"namespaces": m.Namespaces,
+			})
+		}
+
+		module.Output[name+"_memory_id"] = Output{
+			Description: fmt.Sprintf("AgentCore Memory ID for %s agent", agent.Name),
+			Value:       fmt.Sprintf("${aws_bedrockagentcore_memory.%s.id}", name),
+		}
+	}
+}
+
+// addDomainResources adds custom domains, certificates, the HTTP APIs that
+// route them to their agent or gateway, and DNS records.
+func addDomainResources(module *Module, config *iac.StackConfig) {
+	for _, b := range config.DomainBindings() {
+		d := b.Domain
+		name := toSnakeCase(b.ResourceName())
+
+		certificate := d.CertificateARN
+		if certificate == "" {
+			addResource(module, "aws_acm_certificate", name, map[string]interface{}{
+				"domain_name":       d.DomainName,
+				"validation_method": "DNS",
+				"tags":              map[string]string{"Name": d.DomainName},
+				"lifecycle":         map[string]interface{}{"create_before_destroy": true},
+			})
+			addResource(module, "aws_route53_record", name+"_validation", map[string]interface{}{
+				"for_each": fmt.Sprintf("${{for o in aws_acm_certificate.%s.domain_validation_options : o.domain_name => o}}", name),
+				"zone_id":  d.HostedZoneID,
+				"name":     "${each.value.resource_record_name}",
+				"type":     "${each.value.resource_record_type}",
+				"records":  []string{"${each.value.resource_record_value}"},
+				"ttl":      60,
+			})
+			addResource(module, "aws_acm_certificate_validation", name, map[string]interface{}{
+				"certificate_arn":         fmt.Sprintf("${aws_acm_certificate.%s.arn}", name),
+				"validation_record_fqdns": fmt.Sprintf("${[for r in aws_route53_record.%s_validation : r.fqdn]}", name),
+			})
+			certificate = fmt.Sprintf("${aws_acm_certificate_validation.%s.certificate_arn}", name)
+		}
+
+		addResource(module, "aws_apigatewayv2_domain_name", name, map[string]interface{}{
+			"domain_name": d.DomainName,
+			"domain_name_configuration": map[string]interface{}{
+				"certificate_arn": certificate,
+				"endpoint_type":   "REGIONAL",
+				"security_policy": d.SecurityPolicy,
+			},
+			"tags": map[string]string{"Name": d.DomainName},
+		})
+
+		addResource(module, "aws_apigatewayv2_api", name, map[string]interface{}{
+			"name":          fmt.Sprintf("%s-%s", config.StackName, b.ResourceName()),
+			"protocol_type": "HTTP",
+			"tags":          map[string]string{"Name": d.DomainName},
+		})
+		integration := map[string]interface{}{
+			"api_id":                 fmt.Sprintf("${aws_apigatewayv2_api.%s.id}", name),
+			"integration_type":       "HTTP_PROXY",
+			"integration_method":     "ANY",
+			"integration_uri":        b.TargetURL,
+			"payload_format_version": "1.0",
+		}
+		if params := b.RequestParameters(); len(params) > 0 {
+			integration["request_parameters"] = params
+		}
+		addResource(module, "aws_apigatewayv2_integration", name, integration)
+		addResource(module, "aws_apigatewayv2_route", name, map[string]interface{}{
+			"api_id":    fmt.Sprintf("${aws_apigatewayv2_api.%s.id}", name),
+			"route_key": "$default",
+			"target":    fmt.Sprintf("integrations/${aws_apigatewayv2_integration.%s.id}", name),
+		})
+		addResource(module, "aws_apigatewayv2_stage", name, map[string]interface{}{
+			"api_id":      fmt.Sprintf("${aws_apigatewayv2_api.%s.id}", name),
+			"name":        "$default",
+			"auto_deploy": true,
+		})
+		addResource(module, "aws_apigatewayv2_api_mapping", name, map[string]interface{}{
+			"api_id":      fmt.Sprintf("${aws_apigatewayv2_api.%s.id}", name),
+			"domain_name": fmt.Sprintf("${aws_apigatewayv2_domain_name.%s.id}", name),
+			"stage":       fmt.Sprintf("${aws_apigatewayv2_stage.%s.id}", name),
+		})
+
+		if d.HostedZoneID != "" {
+			addResource(module, "aws_route53_record", name+"_domain", map[string]interface{}{
+				"zone_id": d.HostedZoneID,
+				"name":    d.DomainName,
+				"type":    "A",
+				"alias": map[string]interface{}{
+					"name":                   fmt.Sprintf("${aws_apigatewayv2_domain_name.%s.domain_name_configuration[0].target_domain_name}", name),
+					"zone_id":                fmt.Sprintf("${aws_apigatewayv2_domain_name.%s.domain_name_configuration[0].hosted_zone_id}", name),
+					"evaluate_target_health": false,
+				},
+			})
+		}
+
+		module.Output[name+"_url"] = Output{
+			Description: fmt.Sprintf("Custom domain URL for %s", b.Owner),
+			Value:       "https://" + d.DomainName,
+		}
+		module.Output[name+"_domain_target"] = Output{
+			Description: fmt.Sprintf("Regional domain name to alias %s to", d.DomainName),
+			Value:       fmt.Sprintf("${aws_apigatewayv2_domain_name.%s.domain_name_configuration[0].target_domain_name}", name),
+		}
+	}
+}
+
+// addScheduleResources adds EventBridge Scheduler schedules and their role.
+func addScheduleResources(module *Module, config *iac.StackConfig) error {
+	if len(config.Schedules) == 0 {
+		return nil
+	}
+
+	var resources []string
+	for _, agent := range config.InvokedAgents() {
+		if !slices.ContainsFunc(config.Schedules, func(s iac.ScheduleConfig) bool { return s.Agent == agent.Name }) {
+			continue
+		}
+		arn := fmt.Sprintf("${var.%s_runtime_arn}", toSnakeCase(agent.Name))
+		resources = append(resources, arn, arn+"/*")
+	}
+
+	addServiceRole(module, "scheduler", config.StackName+"-scheduler-role", "scheduler.amazonaws.com",
+		"InvokeAgentRuntime", []map[string]interface{}{
+			{"Effect": "Allow", "Action": "bedrock-agentcore:InvokeAgentRuntime", "Resource": resources},
+		})
+
+	for _, s := range config.Schedules {
+		ref := fmt.Sprintf("${var.%s_runtime_arn}", toSnakeCase(s.Agent))
+		input, err := s.TargetInput(ref)
+		if err != nil {
+			return err
+		}
+
+		schedule := map[string]interface{}{
+			"name":                         fmt.Sprintf("%s-%s", config.StackName, s.Name),
+			"schedule_expression":          s.Expression,
+			"schedule_expression_timezone": s.Timezone,
+			"state":                        s.State(),
+			"flexible_time_window":         map[string]string{"mode": "OFF"},
+			"target": map[string]interface{}{
+				"arn":      iac.ScheduleTargetARN,
+				"role_arn": "${aws_iam_role.scheduler.arn}",
+				"input":    escapeTemplate(input, ref),
+			},
+		}
+		if s.Description != "" {
+			schedule["description"] = s.Description
+		}
+		addResource(module, "aws_scheduler_schedule", toSnakeCase(s.Name), schedule)
+	}
+
+	return nil
+}
+
+// addQueueResources adds SQS queues, dead-letter queues and their consumers.
+func addQueueResources(module *Module, config *iac.StackConfig) {
+	if len(config.Queues) == 0 {
+		return
+	}
+
+	var runtimeResources, queueARNs, stateMachineARNs []string
+	seen := make(map[string]bool)
+
+	for _, q := range config.Queues {
+		name := toSnakeCase(strings.TrimSuffix(q.Name, ".fifo"))
+		runtimeRef := fmt.Sprintf("${var.%s_runtime_arn}", toSnakeCase(q.Agent))
+		if !seen[q.Agent] {
+			seen[q.Agent] = true
+			runtimeResources = append(runtimeResources, runtimeRef, runtimeRef+"/*")
+		}
+
+		queue := map[string]interface{}{
+			"name":                       q.QueueName(config.StackName),
+			"visibility_timeout_seconds": q.VisibilityTimeoutSeconds,
+			"message_retention_seconds":  q.MessageRetentionDays * 86400,
+			"tags":                       map[string]string{"Name": q.QueueName(config.StackName)},
+		}
+		if q.FIFO {
+			queue["fifo_queue"] = true
+		}
+		if q.KMSKeyARN != "" {
+			queue["kms_master_key_id"] = q.KMSKeyARN
+		} else {
+			queue["sqs_managed_sse_enabled"] = true
+		}
+
+		if q.DeadLetter.IsEnabled() {
+			dlq := map[string]interface{}{
+				"name":                      q.DeadLetterQueueName(config.StackName),
+				"message_retention_seconds": q.DeadLetter.RetentionDays * 86400,
+			}
+			for _, key := range []string{"fifo_queue", "kms_master_key_id", "sqs_managed_sse_enabled"} {
+				if v, ok := queue[key]; ok {
+					dlq[key] = v
+				}
+			}
+			addResource(module, "aws_sqs_queue", name+"_dlq", dlq)
+			queue["redrive_policy"] = fmt.Sprintf(`${jsonencode({deadLetterTargetArn = aws_sqs_queue.%s_dlq.arn, maxReceiveCount = %d})}`,
+				name, q.DeadLetter.MaxReceiveCount)
+			module.Output[name+"_dead_letter_queue_url"] = Output{
+				Description: fmt.Sprintf("Dead-letter queue URL for %s", q.Name),
+				Value:       fmt.Sprintf("${aws_sqs_queue.%s_dlq.url}", name),
+			}
+		}
+		addResource(module, "aws_sqs_queue", name, queue)
+
+		addResource(module, "aws_sfn_state_machine", name+"_consumer", map[string]interface{}{
+			"name":       fmt.Sprintf("%s-%s-consumer", config.StackName, strings.TrimSuffix(q.Name, ".fifo")),
+			"type":       "EXPRESS",
+			"role_arn":   "${aws_iam_role.queue_consumer.arn}",
+			"definition": q.ConsumerDefinition(runtimeRef),
+		})
+
+		addResource(module, "aws_pipes_pipe", name, map[string]interface{}{
+			"name":     fmt.Sprintf("%s-%s-pipe", config.StackName, strings.TrimSuffix(q.Name, ".fifo")),
+			"role_arn": "${aws_iam_role.queue_pipe.arn}",
+			"source":   fmt.Sprintf("${aws_sqs_queue.%s.arn}", name),
+			"target":   fmt.Sprintf("${aws_sfn_state_machine.%s_consumer.arn}", name),
+			"source_parameters": map[string]interface{}{
+				"sqs_queue_parameters": map[string]interface{}{"batch_size": 1},
+			},
+			"target_parameters": map[string]interface{}{
+				"step_function_state_machine_parameters": map[string]interface{}{"invocation_type": "REQUEST_RESPONSE"},
+			},
+		})
+
+		queueARNs = append(queueARNs, fmt.Sprintf("${aws_sqs_queue.%s.arn}", name))
+		stateMachineARNs = append(stateMachineARNs, fmt.Sprintf("${aws_sfn_state_machine.%s_consumer.arn}", name))
+
+		module.Output[name+"_queue_url"] = Output{
+			Description: fmt.Sprintf("Queue URL for asynchronous %s invocations", q.Agent),
+			Value:       fmt.Sprintf("${aws_sqs_queue.%s.url}", name),
+		}
+		module.Output[name+"_queue_arn"] = Output{
+			Description: fmt.Sprintf("Queue ARN for asynchronous %s invocations", q.Agent),
+			Value:       fmt.Sprintf("${aws_sqs_queue.%s.arn}", name),
+		}
+	}
+
+	addServiceRole(module, "queue_consumer", config.StackName+"-queue-consumer-role", "states.amazonaws.com",
+		"InvokeAgentRuntime", []map[string]interface{}{
+			{"Effect": "Allow", "Action": "bedrock-agentcore:InvokeAgentRuntime", "Resource": runtimeResources},
+		})
+	addServiceRole(module, "queue_pipe", config.StackName+"-queue-pipe-role", "pipes.amazonaws.com",
+		"ConsumeQueues", []map[string]interface{}{
+			{"Effect": "Allow", "Action": []string{"sqs:ReceiveMessage", "sqs:DeleteMessage", "sqs:GetQueueAttributes"}, "Resource": queueARNs},
+			{"Effect": "Allow", "Action": "states:StartSyncExecution", "Resource": stateMachineARNs},
+		})
+}
+
+// addArtifactsResources adds the artifact bucket.
+func addArtifactsResources(module *Module, config *iac.StackConfig) {
+	a := config.Artifacts
+	if a == nil || !a.ShouldCreate() {
+		return
+	}
+	bucket := "${aws_s3_bucket.artifacts.id}"
+
+	addResource(module, "aws_s3_bucket", "artifacts", map[string]interface{}{
+		"bucket":        a.BucketName,
+		"force_destroy": config.RemovalPolicy != "retain",
+		"tags":          map[string]string{"Name": a.BucketName},
+	})
+
+	encryption := map[string]interface{}{"sse_algorithm": "AES256"}
+	if a.KMSKeyARN != "" {
+		encryption = map[string]interface{}{"sse_algorithm": "aws:kms", "kms_master_key_id": a.KMSKeyARN}
+	}
+	addResource(module, "aws_s3_bucket_server_side_encryption_configuration", "artifacts", map[string]interface{}{
+		"bucket": bucket,
+		"rule": map[string]interface{}{
+			"apply_server_side_encryption_by_default": encryption,
+			"bucket_key_enabled":                      a.KMSKeyARN != "",
+		},
+	})
+	addResource(module, "aws_s3_bucket_public_access_block", "artifacts", map[string]interface{}{
+		"bucket":                  bucket,
+		"block_public_acls":       true,
+		"block_public_policy":     true,
+		"ignore_public_acls":      true,
+		"restrict_public_buckets": true,
+	})
+	addResource(module, "aws_s3_bucket_ownership_controls", "artifacts", map[string]interface{}{
+		"bucket": bucket,
+		"rule":   map[string]string{"object_ownership": "BucketOwnerEnforced"},
+	})
+
+	if a.Versioning {
+		addResource(module, "aws_s3_bucket_versioning", "artifacts", map[string]interface{}{
+			"bucket":                   bucket,
+			"versioning_configuration": map[string]string{"status": "Enabled"},
+		})
+	}
+
+	if a.HasLifecycleRules() {
+		rule := map[string]interface{}{
+			"id":     "artifacts",
+			"status": "Enabled",
+			"filter": map[string]interface{}{},
+		}
+		if a.InfrequentAccessDays > 0 {
+			rule["transition"] = map[string]interface{}{"days": a.InfrequentAccessDays, "storage_class": "STANDARD_IA"}
+		}
+		if a.ExpireDays > 0 {
+			rule["expiration"] = map[string]interface{}{"days": a.ExpireDays}
+		}
+		if a.Versioning && a.NoncurrentExpireDays > 0 {
+			rule["noncurrent_version_expiration"] = map[string]interface{}{"noncurrent_days": a.NoncurrentExpireDays}
+		}
+		addResource(module, "aws_s3_bucket_lifecycle_configuration", "artifacts", map[string]interface{}{
+			"bucket": bucket,
+			"rule":   rule,
+		})
+	}
+
+	module.Output["artifacts_bucket_name"] = Output{
+		Description: "S3 bucket for agent artifacts",
+		Value:       bucket,
+	}
+}
+
+// addAlarmResources adds the notification topic, CloudWatch alarms and budget.
+func addAlarmResources(module *Module, config *iac.StackConfig) {
+	a := config.Alarms
+	if a == nil || (!a.IsEnabled() && a.Budget == nil) {
+		return
+	}
+
+	topic := a.SNSTopicARN
+	if a.CreatesTopic() {
+		topic = "${aws_sns_topic.alarms.arn}"
+		addResource(module, "aws_sns_topic", "alarms", map[string]interface{}{
+			"name": config.StackName + "-alarms",
+			"tags": map[string]string{"Name": config.StackName + "-alarms"},
+		})
+		for i, email := range a.NotificationEmails {
+			addResource(module, "aws_sns_topic_subscription", fmt.Sprintf("alarms_email_%d", i), map[string]interface{}{
+				"topic_arn": topic,
+				"protocol":  "email",
+				"endpoint":  email,
+			})
+		}
+		if a.Budget != nil {
+			addResource(module, "aws_sns_topic_policy", "alarms", map[string]interface{}{
+				"arn": topic,
+				"policy": mustJSON(map[string]interface{}{
+					"Version": "2012-10-17",
+					"Statement": []map[string]interface{}{
+						{
+							"Effect":    "Allow",
+							"Principal": map[string]string{"Service": "budgets.amazonaws.com"},
+							"Action":    "SNS:Publish",
+							"Resource":  topic,
+						},
+					},
+				}),
+			})
+		}
+		module.Output["alarm_topic_arn"] = Output{
+			Description: "SNS topic for alarm and budget notifications",
+			Value:       topic,
+		}
+	}
+
+	if a.IsEnabled() {
+		for _, agent := range config.Agents {
+			name := toSnakeCase(agent.Name)
+			dimensions := iac.AlarmDimensions(agent)
+			metric := func(id, metricName string) map[string]interface{} {
+				return map[string]interface{}{
+					"id": id,
+					"metric": map[string]interface{}{
+						"namespace":   a.MetricsNamespace,
+						"metric_name": metricName,
+						"dimensions":  dimensions,
+						"period":      a.PeriodSeconds,
+						"stat":        "Sum",
+					},
+				}
+			}
+
+			addResource(module, "aws_cloudwatch_metric_alarm", name+"_error_rate", map[string]interface{}{
+				"alarm_name":        fmt.Sprintf("%s-%s-error-rate", config.StackName, agent.Name),
+				"alarm_description": fmt.Sprintf("%s agent system error rate above %g%%", agent.Name, a.ErrorRatePercent),
+				"metric_query": []map[string]interface{}{
+					metric("errors", "SystemErrors"),
+					metric("invocations", "Invocations"),
+					{
+						"id":          "rate",
+						"expression":  "IF(invocations > 0, 100 * errors / invocations, 0)",
+						"label":       "Error rate (%)",
+						"return_data": true,
+					},
+				},
+				"evaluation_periods":  a.EvaluationPeriods,
+				"threshold":           a.ErrorRatePercent,
+				"comparison_operator": "GreaterThanThreshold",
+				"treat_missing_data":  "notBreaching",
+				"alarm_actions":       []string{topic},
+				"ok_actions":          []string{topic},
+			})
+
+			addResource(module, "aws_cloudwatch_metric_alarm", name+"_latency", map[string]interface{}{
+				"alarm_name":          fmt.Sprintf("%s-%s-latency", config.StackName, agent.Name),
+				"alarm_description":   fmt.Sprintf("%s agent p99 latency above %dms", agent.Name, a.LatencyThreshold(agent)),
+				"namespace":           a.MetricsNamespace,
+				"metric_name":         "Latency",
+				"dimensions":          dimensions,
+				"extended_statistic":  "p99",
+				"period":              a.PeriodSeconds,
+				"evaluation_periods":  a.EvaluationPeriods,
+				"threshold":           a.LatencyThreshold(agent),
+				"comparison_operator": "GreaterThanThreshold",
+				"treat_missing_data":  "notBreaching",
+				"alarm_actions":       []string{topic},
+				"ok_actions":          []string{topic},
+			})
+		}
+
+		for _, q := range config.Queues {
+			if !q.DeadLetter.IsEnabled() {
+				continue
+			}
+			name := toSnakeCase(strings.TrimSuffix(q.Name, ".fifo"))
+			addResource(module, "aws_cloudwatch_metric_alarm", name+"_dead_letters", map[string]interface{}{
+				"alarm_name":          fmt.Sprintf("%s-%s-dead-letters", config.StackName, q.Name),
+				"alarm_description":   fmt.Sprintf("Messages in the %s dead-letter queue", q.Name),
+				"namespace":           "AWS/SQS",
+				"metric_name":         "ApproximateNumberOfMessagesVisible",
+				"dimensions":          map[string]string{"QueueName": fmt.Sprintf("${aws_sqs_queue.%s_dlq.name}", name)},
+				"statistic":           "Maximum",
+				"period":              a.PeriodSeconds,
+				"evaluation_periods":  1,
+				"threshold":           0,
+				"comparison_operator": "GreaterThanThreshold",
+				"treat_missing_data":  "notBreaching",
+				"alarm_actions":       []string{topic},
+			})
+		}
+	}
+
+	if b := a.Budget; b != nil {
+		notification := func(notificationType string, threshold float64) map[string]interface{} {
+			n := map[string]interface{}{
+				"comparison_operator":       "GREATER_THAN",
+				"threshold":                 threshold,
+				"threshold_type":            "PERCENTAGE",
+				"notification_type":         notificationType,
+				"subscriber_sns_topic_arns": []string{topic},
+			}
+			if len(a.NotificationEmails) > 0 {
+				n["subscriber_email_addresses"] = a.NotificationEmails
+			}
+			return n
+		}
+		var notifications []map[string]interface{}
+		for _, t := range b.AlertThresholds {
+			notifications = append(notifications, notification("ACTUAL", t))
+		}
+		notifications = append(notifications, notification("FORECASTED", 100))
+
+		budget := map[string]interface{}{
+			"name":         config.StackName + "-monthly",
+			"budget_type":  "COST",
+			"time_unit":    "MONTHLY",
+			"limit_amount": fmt.Sprintf("%g", b.MonthlyLimitUSD),
+			"limit_unit":   "USD",
+			"notification": notifications,
+		}
+		if filters := b.BudgetCostFilters(); len(filters) > 0 {
+			budget["cost_filter"] = []map[string]interface{}{{"name": "TagKeyValue", "values": filters}}
+		}
+		addResource(module, "aws_budgets_budget", "monthly", budget)
+	}
+}
+
+// addDashboardResources adds the CloudWatch dashboard.
+func addDashboardResources(module *Module, config *iac.StackConfig) error {
+	if config.Dashboard == nil {
+		return nil
+	}
+	body, err := iac.DashboardBody(config, "${data.aws_region.current.name}")
+	if err != nil {
+		return err
+	}
+	addResource(module, "aws_cloudwatch_dashboard", "agents", map[string]interface{}{
+		"dashboard_name": config.Dashboard.Name,
+		"dashboard_body": body,
+	})
+	module.Output["dashboard_name"] = Output{
+		Description: "CloudWatch dashboard",
+		Value:       "${aws_cloudwatch_dashboard.agents.dashboard_name}",
+	}
+	return nil
+}
+
+// addServiceRole adds an IAM role assumable by service with a single inline policy.
+func addServiceRole(module *Module, name, roleName, service, policyName string, statements []map[string]interface{}) {
+	addResource(module, "aws_iam_role", name, map[string]interface{}{
+		"name": roleName,
+		"assume_role_policy": mustJSON(map[string]interface{}{
+			"Version": "2012-10-17",
+			"Statement": []map[string]interface{}{
+				{
+					"Effect":    "Allow",
+					"Principal": map[string]interface{}{"Service": service},
+					"Action":    "sts:AssumeRole",
+				},
+			},
+		}),
+		"tags": map[string]string{"Name": roleName},
+	})
+	addResource(module, "aws_iam_role_policy", name, map[string]interface{}{
+		"name": policyName,
+		"role": fmt.Sprintf("${aws_iam_role.%s.id}", name),
+		"policy": mustJSON(map[string]interface{}{
+			"Version":   "2012-10-17",
+			"Statement": statements,
+		}),
+	})
+}
+
+// escapeTemplate escapes Terraform template sequences in s, except the
+// interpolation ref.
+func escapeTemplate(s, ref string) string {
+	parts := strings.Split(s, ref)
+	for i, part := range parts {
+		part = strings.ReplaceAll(part, "${", "$${")
+		parts[i] = strings.ReplaceAll(part, "%{", "%%{")
+	}
+	return strings.Join(parts, ref)
+}
+
+// imageValue returns the Terraform expression for an agent's image.
+func imageValue(agent iac.AgentConfig) string {
+	name := toSnakeCase(agent.Name)
+	if agent.ContainerImage == "" && agent.ImageBuild != nil {
+		if agent.ImageBuild.Repository.ShouldCreate() {
+			return fmt.Sprintf("${aws_ecr_repository.%s.repository_url}:%s", name, agent.ImageBuild.Tag)
+		}
+		return fmt.Sprintf("${data.aws_caller_identity.current.account_id}.dkr.ecr.${data.aws_region.current.name}.amazonaws.com/%s:%s",
+			agent.ImageBuild.Repository.Name, agent.ImageBuild.Tag)
+	}
+	return fmt.Sprintf("${var.%s_container_image}", name)
+}
+
+// mustJSON encodes a policy document. Policy documents are built from
+// plain maps and slices, so encoding cannot fail.
+func mustJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("terraform: failed to encode policy: %v", err))
+	}
+	return string(data)
+}
+
+// toSnakeCase converts an agent name to a Terraform identifier.
+func toSnakeCase(s string) string {
+	s = strings.ToLower(s)
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// addDeclaredOutputs adds the outputs declared in config.Outputs. Names
+// must not collide with generated outputs.
+func addDeclaredOutputs(module *Module, config *iac.StackConfig) error {
+	for _, o := range config.Outputs {
+		name := camelToSnakeCase(o.Name)
+		if _, exists := module.Output[name]; exists {
+			return fmt.Errorf("output %s conflicts with a generated output", name)
+		}
+		value, ok := o.StaticValue(config)
+		if !ok {
+			value = fmt.Sprintf("${var.%s_runtime_arn}", toSnakeCase(o.Agent))
+		}
+		module.Output[name] = Output{Description: o.OutputDescription(), Value: value}
+	}
+	return nil
+}
+
+// camelToSnakeCase converts a camelCase or PascalCase name to snake_case.
+func camelToSnakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,254 @@
+package iac
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ValidationIssue is a single problem found while validating a StackConfig.
+type ValidationIssue struct {
+	// Path is the config path the issue refers to, e.g. "agents[0].memoryMB".
+	// Empty for stack-level issues.
+	Path string `json:"path,omitempty"`
+
+	// Message describes the issue.
+	Message string `json:"message"`
+}
+
+// Error returns the issue formatted as an error message.
+func (i ValidationIssue) Error() string {
+	return i.Message
+}
+
+// ValidationReport collects the errors and warnings for a StackConfig.
+type ValidationReport struct {
+	// Errors are problems that make the configuration undeployable.
+	Errors []ValidationIssue `json:"errors,omitempty"`
+
+	// Warnings are deployable but risky settings.
+	// In strict mode, warnings are promoted to errors.
+	Warnings []ValidationIssue `json:"warnings,omitempty"`
+}
+
+// ValidateOptions configures ValidateReport.
+type ValidateOptions struct {
+	// Strict promotes all warnings to errors.
+	Strict bool
+}
+
+// OK returns true if the report has no errors.
+func (r *ValidationReport) OK() bool {
+	return len(r.Errors) == 0
+}
+
+// Err returns the first error, or nil if the report has no errors.
+func (r *ValidationReport) Err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	return r.Errors[0]
+}
+
+func (r *ValidationReport) addError(path, format string, args ...any) {
+	r.Errors = append(r.Errors, ValidationIssue{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (r *ValidationReport) addWarning(path, format string, args ...any) {
+	r.Warnings = append(r.Warnings, ValidationIssue{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// Validate validates the StackConfig and returns the first error found.
+// Use ValidateReport to get all errors and warnings.
+func (c *StackConfig) Validate() error {
+	report := &ValidationReport{}
+	c.validateErrors(report)
+	return report.Err()
+}
+
+// ValidateReport validates the StackConfig and returns all errors and warnings.
+// With opts.Strict, warnings are reported as errors.
+func (c *StackConfig) ValidateReport(opts ValidateOptions) *ValidationReport {
+	report := &ValidationReport{}
+	c.validateErrors(report)
+	c.validateWarnings(report)
+
+	if opts.Strict && len(report.Warnings) > 0 {
+		for _, w := range report.Warnings {
+			w.Message = "strict: " + w.Message
+			report.Errors = append(report.Errors, w)
+		}
+		report.Warnings = nil
+	}
+
+	return report
+}
+
+// validateErrors adds all hard validation errors to the report.
+func (c *StackConfig) validateErrors(report *ValidationReport) {
+	if c.StackName == "" {
+		report.addError("stackName", "stackName is required")
+	}
+
+	if len(c.Agents) == 0 {
+		report.addError("agents", "at least one agent is required")
+	}
+
+	defaultCount := 0
+	agentNames := make(map[string]bool)
+
+	for i, agent := range c.Agents {
+		path := fmt.Sprintf("agents[%d]", i)
+
+		if agent.Name == "" {
+			report.addError(path+".name", "agents[%d]: name is required", i)
+			continue
+		}
+		if agent.ContainerImage == "" && agent.ImageBuild == nil {
+			report.addError(path+".containerImage", "agents[%d] (%s): containerImage is required", i, agent.Name)
+		}
+		validateImageBuild(report, i, agent)
+		validateScaling(report, i, agent)
+		validateMemory(report, i, agent)
+		validateAgentTags(report, i, agent)
+		validateEnvironment(report, i, agent)
+		validateAgentIAM(report, i, agent)
+		validateLogging(report, i, agent)
+		if agentNames[agent.Name] {
+			report.addError(path+".name", "duplicate agent name: %s", agent.Name)
+		}
+		agentNames[agent.Name] = true
+
+		if agent.IsDefault {
+			defaultCount++
+		}
+
+		if agent.MemoryMB != 0 && !slices.Contains(ValidMemoryValues(), agent.MemoryMB) {
+			report.addError(path+".memoryMB", "agents[%d] (%s): memoryMB must be one of %v", i, agent.Name, ValidMemoryValues())
+		}
+
+		if agent.TimeoutSeconds != 0 && (agent.TimeoutSeconds < 1 || agent.TimeoutSeconds > 900) {
+			report.addError(path+".timeoutSeconds", "agents[%d] (%s): timeoutSeconds must be between 1 and 900", i, agent.Name)
+		}
+
+		if agent.Protocol != "" && !slices.Contains(ValidProtocols(), agent.Protocol) {
+			report.addError(path+".protocol", "agents[%d] (%s): protocol must be one of %v", i, agent.Name, ValidProtocols())
+		}
+
+		if agent.Authorizer != nil {
+			if !slices.Contains(ValidAuthorizerTypes(), agent.Authorizer.Type) {
+				report.addError(path+".authorizer.type", "agents[%d] (%s): authorizer.type must be one of %v", i, agent.Name, ValidAuthorizerTypes())
+			}
+			validateLambdaAuthorizer(report, i, agent)
+			validateJWTAuthorizer(report, i, agent)
+		}
+	}
+
+	if defaultCount > 1 {
+		report.addError("agents", "only one agent can be marked as default")
+	}
+
+	// Validate gateway targets reference existing agents
+	if c.Gateway != nil && c.Gateway.Enabled && len(c.Gateway.Targets) > 0 {
+		for _, target := range c.Gateway.Targets {
+			if !agentNames[target] {
+				report.addError("gateway.targets", "gateway target '%s' does not match any agent name", target)
+			}
+		}
+	}
+
+	c.validateGatewayTargets(report, agentNames)
+	c.validateSchedules(report, agentNames)
+	c.validateQueues(report, agentNames)
+	c.validateArtifacts(report, agentNames)
+	c.validateGuardrails(report)
+	c.validateLogGroups(report)
+	c.validateHooks(report, agentNames)
+	c.validateDomains(report)
+	c.validateTagPolicy(report)
+	c.validateAlarms(report)
+	c.validateDashboard(report)
+	c.validateOutputs(report)
+
+	c.validateNetwork(report)
+	c.validateIAM(report)
+
+	if c.Observability != nil && c.Observability.Provider != "" &&
+		!slices.Contains(ValidObservabilityProviders(), c.Observability.Provider) {
+		report.addError("observability.provider", "invalid observability.provider: %s (valid: %v)", c.Observability.Provider, ValidObservabilityProviders())
+	}
+}
+
+// secretEnvMarkers are substrings of environment variable names that
+// usually hold credentials.
+var secretEnvMarkers = []string{"API_KEY", "APIKEY", "SECRET", "TOKEN", "PASSWORD", "PASSWD", "CREDENTIAL", "PRIVATE_KEY"}
+
+// devEnvironments are tag values and stack name markers for non-production stacks.
+var devEnvironments = []string{"dev", "development", "test", "sandbox"}
+
+// validateWarnings adds risky-but-valid settings to the report.
+func (c *StackConfig) validateWarnings(report *ValidationReport) {
+	for i, agent := range c.Agents {
+		for key, value := range agent.Environment {
+			if IsEnvReference(value) {
+				continue
+			}
+			upper := strings.ToUpper(key)
+			for _, marker := range secretEnvMarkers {
+				if strings.Contains(upper, marker) {
+					report.addWarning(fmt.Sprintf("agents[%d].environment.%s", i, key),
+						"agents[%d] (%s): environment variable %s looks like a secret; use a kms: or ssm: reference or secretsARNs instead", i, agent.Name, key)
+					break
+				}
+			}
+		}
+	}
+
+	if c.IAM != nil && c.IAM.EnableBedrockAccess && len(c.IAM.BedrockModelIDs) == 0 &&
+		slices.ContainsFunc(c.Agents, func(a AgentConfig) bool { return a.IAM == nil || len(a.IAM.BedrockModelIDs) == 0 }) {
+		report.addWarning("iam.bedrockModelIds", "iam.bedrockModelIds is empty; agents can invoke all Bedrock models")
+	}
+
+	if c.Secrets != nil && len(c.Secrets.SecretValues) > 0 {
+		report.addWarning("secrets.secretValues", "secrets.secretValues stores plaintext secrets in the config file")
+	}
+
+	if c.RemovalPolicy == "retain" && c.isDevStack() {
+		report.addWarning("removalPolicy", "removalPolicy is retain on a development stack; resources will be orphaned on deletion")
+	}
+
+	c.validateNetworkWarnings(report)
+	c.validateIAMWarnings(report)
+	c.validateGuardrailWarnings(report)
+	c.validateQueueWarnings(report)
+	c.validateAlarmsWarnings(report)
+
+	if len(c.Agents) > 1 {
+		hasDefault := false
+		for _, agent := range c.Agents {
+			if agent.IsDefault {
+				hasDefault = true
+				break
+			}
+		}
+		if !hasDefault {
+			report.addWarning("agents", "multiple agents but none is marked isDefault; requests must name an agent")
+		}
+	}
+}
+
+// isDevStack reports whether the stack looks like a non-production stack,
+// based on its Environment tag or stack name.
+func (c *StackConfig) isDevStack() bool {
+	if env, ok := c.Tags["Environment"]; ok {
+		return slices.Contains(devEnvironments, strings.ToLower(env))
+	}
+	for _, part := range strings.FieldsFunc(strings.ToLower(c.StackName), func(r rune) bool {
+		return r == '-' || r == '_'
+	}) {
+		if slices.Contains(devEnvironments, part) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package iac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/plexusone/agentkit/internal/awscli"
+)
+
+// Config files may contain placeholders that are resolved at load time, so
+// one file works across accounts and regions:
+//
+//	${env:VAR}        environment variable VAR (must be set)
+//	${aws:accountId}  account of the current AWS credentials
+//	${aws:region}     current AWS region
+//
+// Placeholders are substituted in string values only. Placeholders in
+// other namespaces, such as CloudFormation's ${AWS::Region}, are left as
+// they are. Write $${...} for a literal "${...}".
+
+// variablePattern matches ${namespace:name} placeholders and $${ escapes.
+var variablePattern = regexp.MustCompile(`\$?\$\{([a-zA-Z]+):([^}]*)\}`)
+
+// awsCLITimeout bounds the aws CLI calls used to resolve aws: variables.
+const awsCLITimeout = 10 * time.Second
+
+// ErrUnknownNamespace is returned by a VariableResolver for a namespace it
+// does not handle; the placeholder is then left untouched.
+var ErrUnknownNamespace = errors.New("unknown variable namespace")
+
+// VariableResolver resolves the placeholder ${namespace:name}.
+type VariableResolver func(namespace, name string) (string, error)
+
+// NewVariableResolver returns the default resolver for env: and aws:
+// variables. AWS values are looked up once and cached:
+//   - aws:region comes from AWS_REGION, AWS_DEFAULT_REGION or "aws configure get region"
+//   - aws:accountId comes from AWS_ACCOUNT_ID or "aws sts get-caller-identity"
+func NewVariableResolver() VariableResolver {
+	cache := make(map[string]string)
+	return func(namespace, name string) (string, error) {
+		switch namespace {
+		case "env":
+			value, ok := os.LookupEnv(name)
+			if !ok {
+				return "", fmt.Errorf("environment variable %s is not set", name)
+			}
+			return value, nil
+
+		case "aws":
+			if value, ok := cache[name]; ok {
+				return value, nil
+			}
+			var value string
+			var err error
+			switch name {
+			case "region":
+				value, err = lookupAWS([]string{"AWS_REGION", "AWS_DEFAULT_REGION"}, "configure", "get", "region")
+			case "accountId":
+				value, err = lookupAWS([]string{"AWS_ACCOUNT_ID"}, "sts", "get-caller-identity", "--query", "Account")
+			default:
+				return "", fmt.Errorf("unknown aws variable %q (use accountId or region)", name)
+			}
+			if err != nil {
+				return "", fmt.Errorf("failed to resolve aws:%s: %w", name, err)
+			}
+			cache[name] = value
+			return value, nil
+
+		default:
+			return "", ErrUnknownNamespace
+		}
+	}
+}
+
+// lookupAWS returns the first set environment variable in envVars, or the
+// text output of the aws CLI called with args.
+func lookupAWS(envVars []string, args ...string) (string, error) {
+	for _, name := range envVars {
+		if value := os.Getenv(name); value != "" {
+			return value, nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), awsCLITimeout)
+	defer cancel()
+
+	out, err := awscli.RunText(ctx, args...)
+	if err != nil {
+		return "", err
+	}
+	value := strings.TrimSpace(string(out))
+	if value == "" || value == "None" {
+		return "", fmt.Errorf("aws %s returned no value", strings.Join(args, " "))
+	}
+	return value, nil
+}
+
+// SubstituteVariables replaces placeholders in every string value of a
+// decoded config, in place.
+func SubstituteVariables(raw map[string]interface{}, resolve VariableResolver) error {
+	for k, v := range raw {
+		value, err := substituteValue(v, k, resolve)
+		if err != nil {
+			return err
+		}
+		raw[k] = value
+	}
+	return nil
+}
+
+// substituteValue returns v with placeholders replaced. path is used in errors.
+func substituteValue(v interface{}, path string, resolve VariableResolver) (interface{}, error) {
+	switch v := v.(type) {
+	case string:
+		return substituteString(v, path, resolve)
+	case map[string]interface{}:
+		for k, item := range v {
+			value, err := substituteValue(item, path+"."+k, resolve)
+			if err != nil {
+				return nil, err
+			}
+			v[k] = value
+		}
+	case []interface{}:
+		for i, item := range v {
+			value, err := substituteValue(item, fmt.Sprintf("%s[%d]", path, i), resolve)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = value
+		}
+	}
+	return v, nil
+}
+
+// substituteString replaces the placeholders in s.
+func substituteString(s, path string, resolve VariableResolver) (string, error) {
+	var resolveErr error
+	result := variablePattern.ReplaceAllStringFunc(s, func(match string) string {
+		if resolveErr != nil {
+			return match
+		}
+		if strings.HasPrefix(match, "$$") {
+			return match[1:]
+		}
+		parts := variablePattern.FindStringSubmatch(match)
+		value, err := resolve(parts[1], parts[2])
+		if errors.Is(err, ErrUnknownNamespace) {
+			return match
+		}
+		if err != nil {
+			resolveErr = fmt.Errorf("%s: %s: %w", path, match, err)
+			return match
+		}
+		return value
+	})
+	return result, resolveErr
+}
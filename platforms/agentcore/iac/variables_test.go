@@ -0,0 +1,46 @@
+package iac
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSubstituteString(t *testing.T) {
+	t.Setenv("AGENTKIT_TEST_STAGE", "prod")
+	t.Setenv("AWS_REGION", "eu-west-1")
+	t.Setenv("AWS_ACCOUNT_ID", "123456789012")
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{"env", "agent-${env:AGENTKIT_TEST_STAGE}", "agent-prod", ""},
+		{"aws region", "${aws:region}", "eu-west-1", ""},
+		{"aws account", "arn:aws:iam::${aws:accountId}:role/agent", "arn:aws:iam::123456789012:role/agent", ""},
+		{"escape", "$${env:AGENTKIT_TEST_STAGE}", "${env:AGENTKIT_TEST_STAGE}", ""},
+		{"cloudformation account", "arn:aws:iam::${AWS::AccountId}:role/agent", "arn:aws:iam::${AWS::AccountId}:role/agent", ""},
+		{"cloudformation region", "${AWS::Region}-${aws:region}", "${AWS::Region}-eu-west-1", ""},
+		{"unknown namespace", "${ssm:/agent/stage}", "${ssm:/agent/stage}", ""},
+		{"unset env", "${env:AGENTKIT_TEST_UNSET}", "", "AGENTKIT_TEST_UNSET is not set"},
+		{"unknown aws variable", "${aws:partition}", "", `unknown aws variable "partition"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := substituteString(tt.input, "agents[0].name", NewVariableResolver())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package agentcore
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Supported response content types for /invocations.
+const (
+	ContentTypeJSON        = "application/json"
+	ContentTypeText        = "text/plain"
+	ContentTypeEventStream = "text/event-stream"
+)
+
+// negotiateContentType picks the response content type from an Accept header.
+// The highest-weighted supported type wins; ties keep header order.
+// Returns ContentTypeJSON when the header is empty or nothing matches.
+func negotiateContentType(accept string) string {
+	if accept == "" {
+		return ContentTypeJSON
+	}
+
+	best := ""
+	bestQ := 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q <= bestQ {
+			continue
+		}
+
+		var candidate string
+		switch mediaType {
+		case ContentTypeJSON, "*/*", "application/*":
+			candidate = ContentTypeJSON
+		case ContentTypeText, "text/*":
+			candidate = ContentTypeText
+		case ContentTypeEventStream:
+			candidate = ContentTypeEventStream
+		default:
+			continue
+		}
+		best, bestQ = candidate, q
+	}
+
+	if best == "" {
+		return ContentTypeJSON
+	}
+	return best
+}
+
+// writeResponse encodes resp in the given content type.
+func writeResponse(w http.ResponseWriter, contentType string, resp Response) error {
+	switch contentType {
+	case ContentTypeText:
+		w.Header().Set("Content-Type", ContentTypeText+"; charset=utf-8")
+		if resp.Error != "" {
+			w.Header().Set("X-Agent-Error", resp.Error)
+		}
+		_, err := io.WriteString(w, resp.Output)
+		return err
+
+	case ContentTypeEventStream:
+		w.Header().Set("Content-Type", ContentTypeEventStream)
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		if err := writeEvent(w, "output", resp.Output); err != nil {
+			return err
+		}
+		if resp.Error != "" {
+			if err := writeEvent(w, "error", resp.Error); err != nil {
+				return err
+			}
+		}
+		if len(resp.Metadata) > 0 {
+			data, err := json.Marshal(resp.Metadata)
+			if err != nil {
+				return err
+			}
+			if err := writeEvent(w, "metadata", string(data)); err != nil {
+				return err
+			}
+		}
+		if err := writeEvent(w, "done", ""); err != nil {
+			return err
+		}
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+		return nil
+
+	default:
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		return json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// writeEvent writes a single server-sent event. Multi-line data is split
+// into multiple data fields as required by the SSE format.
+func writeEvent(w io.Writer, event, data string) error {
+	if _, err := fmt.Fprintf(w, "event: %s\n", event); err != nil {
+		return err
+	}
+	for _, line := range strings.Split(data, "\n") {
+		if _, err := fmt.Fprintf(w, "data: %s\n", line); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -3,11 +3,18 @@ package agentcore
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/grokify/mogo/log/sanitize"
+
+	agenthttp "github.com/plexusone/agentkit/http"
+	"github.com/plexusone/agentkit/llm/stream"
+	"github.com/plexusone/agentkit/observability"
 )
 
 // Server implements the AWS AgentCore HTTP contract.
@@ -87,18 +94,25 @@ func (s *Server) handlePing(w http.ResponseWriter, r *http.Request) {
 
 // handleInvocations implements the /invocations endpoint required by AgentCore.
 // Routes requests to the appropriate agent and returns the response.
+// The response format follows the Accept header: application/json (default)
+// returns the Response envelope, text/plain returns the raw output, and
+// text/event-stream returns the output as server-sent events, streamed as
+// it is produced by a StreamingAgent.
 func (s *Server) handleInvocations(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
+	start := time.Now()
+
 	// Parse request
 	var req Request
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		if s.config.EnableRequestLogging {
 			log.Printf("[AgentCore] Invalid request: %v", err)
 		}
+		s.audit(r.Context(), req, Response{}, time.Since(start), AuditOutcomeBadRequest, err)
 		http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
 		return
 	}
@@ -108,27 +122,72 @@ func (s *Server) handleInvocations(w http.ResponseWriter, r *http.Request) {
 		req.Agent = s.config.DefaultAgent
 	}
 
+	ctx, correlationID := correlate(r, &req)
+	w.Header().Set(agenthttp.CorrelationIDHeader, correlationID)
+
 	if s.config.EnableRequestLogging {
-		log.Printf("[AgentCore] Invocation: agent=%s session=%s prompt_len=%d",
-			sanitize.String(req.Agent), sanitize.String(req.SessionID), len(req.Prompt))
+		log.Printf("[AgentCore] Invocation: agent=%s session=%s correlation_id=%s prompt_len=%d",
+			sanitize.String(req.Agent), sanitize.String(req.SessionID), correlationID, len(req.Prompt))
 	}
 
 	// Create session context
-	ctx := NewSessionContext(r.Context(), req.SessionID, &req)
+	ctx = NewSessionContext(ctx, req.SessionID, &req)
+
+	// Trace the invocation
+	traceName := req.Agent
+	if traceName == "" {
+		traceName = "invocation"
+	}
+	ctx = observability.WithSessionID(ctx, req.SessionID)
+	ctx, span := observability.OrNoop(s.config.Tracer).Start(ctx, observability.SpanAgent, traceName, req.Prompt)
+	if tenant := req.Metadata[MetadataTenant]; tenant != "" {
+		span.SetMetadata(MetadataTenant, tenant)
+	}
+	span.SetMetadata(MetadataCorrelationID, correlationID)
+
+	// Stream the output of streaming agents to clients that accept it
+	contentType := negotiateContentType(r.Header.Get("Accept"))
+	if contentType == ContentTypeEventStream {
+		if agent, err := s.registry.Get(req.Agent); err == nil {
+			if sa, ok := agent.(StreamingAgent); ok {
+				s.streamInvocation(ctx, w, sa, req, span, start)
+				return
+			}
+		}
+	}
 
 	// Invoke agent
 	resp, err := s.registry.Invoke(ctx, req)
+	resp.Metadata = withMetadata(resp.Metadata, MetadataCorrelationID, correlationID)
+	if cost := responseCost(resp); cost > 0 {
+		span.SetCost(cost)
+	}
+	switch {
+	case err != nil:
+		span.End(nil, err)
+	case resp.Error != "":
+		span.End(resp.Output, fmt.Errorf("%s", resp.Error))
+	default:
+		span.End(resp.Output, nil)
+	}
 	if err != nil {
 		if s.config.EnableRequestLogging {
-			log.Printf("[AgentCore] Invocation failed: %v", err)
+			log.Printf("[AgentCore] Invocation failed: %v (correlation_id=%s)", err, correlationID)
 		}
+		s.audit(r.Context(), req, resp, time.Since(start), AuditOutcomeAgentError, err)
 		http.Error(w, fmt.Sprintf("invocation failed: %v", err), http.StatusInternalServerError)
 		return
 	}
 
-	// Send response
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(resp); err != nil {
+	if resp.Error != "" {
+		s.audit(r.Context(), req, resp, time.Since(start), AuditOutcomeAgentError, errors.New(resp.Error))
+	} else {
+		s.audit(r.Context(), req, resp, time.Since(start), AuditOutcomeSuccess, nil)
+	}
+
+	// Send response in the negotiated content type
+	w.Header().Add("Vary", "Accept")
+	if err := writeResponse(w, contentType, resp); err != nil {
 		log.Printf("[AgentCore] Failed to encode response: %v", err)
 	}
 
@@ -139,16 +198,123 @@ func (s *Server) handleInvocations(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// Start starts the AgentCore server. This method blocks until the server stops.
-func (s *Server) Start() error {
+// streamInvocation invokes a streaming agent and sends each chunk of its
+// output as an "output" event, followed by a "usage" event with the token
+// usage if it is known, or an "error" event, and a "done" event. span is
+// the span of the invocation, which it ends.
+func (s *Server) streamInvocation(ctx context.Context, w http.ResponseWriter, agent StreamingAgent, req Request, span observability.Span, start time.Time) {
+	w.Header().Add("Vary", "Accept")
+	w.Header().Set("Content-Type", ContentTypeEventStream)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flush := func() {
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+	}
+	flush()
+
+	var output strings.Builder
+	var usage *stream.Usage
+	var streamErr error
+	for chunk := range agent.InvokeStream(ctx, req) {
+		var err error
+		switch {
+		case chunk.Err != nil:
+			streamErr = chunk.Err
+			err = writeEvent(w, "error", chunk.Err.Error())
+		case chunk.Text != "":
+			output.WriteString(chunk.Text)
+			err = writeEvent(w, "output", chunk.Text)
+		case chunk.Usage != nil:
+			usage = chunk.Usage
+			span.SetUsage(usage.PromptTokens, usage.CompletionTokens)
+			span.SetCost(usage.CostUSD)
+			data, _ := json.Marshal(chunk.Usage)
+			err = writeEvent(w, "usage", string(data))
+		}
+		if err != nil {
+			// The client is gone; let the agent see the canceled context.
+			log.Printf("[AgentCore] Failed to write stream: %v", err)
+			break
+		}
+		flush()
+	}
+	if err := writeEvent(w, "done", ""); err == nil {
+		flush()
+	}
+
+	resp := Response{Output: output.String(), Metadata: UsageMetadata(usage)}
+	resp.Metadata = withMetadata(resp.Metadata, MetadataCorrelationID, req.Metadata[MetadataCorrelationID])
+	span.End(resp.Output, streamErr)
+	outcome := AuditOutcomeSuccess
+	if streamErr != nil {
+		resp.Error = streamErr.Error()
+		outcome = AuditOutcomeAgentError
+		if s.config.EnableRequestLogging {
+			log.Printf("[AgentCore] Invocation failed: %v (correlation_id=%s)", streamErr, req.Metadata[MetadataCorrelationID])
+		}
+	}
+	s.audit(ctx, req, resp, time.Since(start), outcome, streamErr)
+}
+
+// correlate returns the context of the invocation of req, with its
+// correlation ID, which is the first of: the correlation ID of the
+// request context, e.g. set by httpserver.RequestID, the X-Correlation-ID
+// header, the MetadataCorrelationID of req, and a new ID. It sets the
+// MetadataCorrelationID of req, so that agents see it.
+func correlate(r *http.Request, req *Request) (context.Context, string) {
+	ctx := r.Context()
+	id := agenthttp.CorrelationID(ctx)
+	for _, candidate := range []string{r.Header.Get(agenthttp.CorrelationIDHeader), req.Metadata[MetadataCorrelationID]} {
+		if id == "" && agenthttp.ValidCorrelationID(candidate) {
+			id = candidate
+		}
+	}
+	if id == "" {
+		id = agenthttp.NewCorrelationID()
+	}
+	req.Metadata = withMetadata(req.Metadata, MetadataCorrelationID, id)
+	return agenthttp.WithCorrelationID(ctx, id), id
+}
+
+// withMetadata returns metadata with key set to value, allocating it if
+// it is nil.
+func withMetadata(metadata map[string]string, key, value string) map[string]string {
+	if metadata == nil {
+		metadata = make(map[string]string, 1)
+	}
+	metadata[key] = value
+	return metadata
+}
+
+// audit records an invocation with the configured AuditSink, if any.
+func (s *Server) audit(ctx context.Context, req Request, resp Response, latency time.Duration, outcome AuditOutcome, err error) {
+	if s.config.AuditSink == nil {
+		return
+	}
+	rec := newAuditRecord(s.config.AuditPromptPolicy, req, resp, latency, outcome, err)
+	if err := s.config.AuditSink.Record(ctx, rec); err != nil {
+		log.Printf("[AgentCore] Failed to write audit record: %v", err)
+	}
+}
+
+// Handler returns the handler of the AgentCore endpoints, /ping and
+// /invocations, to mount them on another server.
+func (s *Server) Handler() http.Handler {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/ping", s.handlePing)
 	mux.HandleFunc("/invocations", s.handleInvocations)
+	return mux
+}
 
+// Start starts the AgentCore server. This method blocks until the server stops.
+func (s *Server) Start() error {
 	addr := fmt.Sprintf(":%d", s.config.Port)
 	s.httpServer = &http.Server{
 		Addr:         addr,
-		Handler:      mux,
+		Handler:      s.Handler(),
 		ReadTimeout:  s.config.ReadTimeout,
 		WriteTimeout: s.config.WriteTimeout,
 		IdleTimeout:  s.config.IdleTimeout,
@@ -187,6 +353,13 @@ func (s *Server) Stop(ctx context.Context) error {
 		errs = append(errs, err)
 	}
 
+	// Close the audit sink if it holds resources
+	if closer, ok := s.config.AuditSink.(Closer); ok {
+		if err := closer.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("audit sink close: %w", err))
+		}
+	}
+
 	if len(errs) > 0 {
 		return fmt.Errorf("shutdown errors: %v", errs)
 	}
@@ -243,6 +416,19 @@ func (b *Builder) WithDefaultAgent(name string) *Builder {
 	return b
 }
 
+// WithAuditSink sets the sink that receives invocation audit records.
+func (b *Builder) WithAuditSink(sink AuditSink, policy AuditPromptPolicy) *Builder {
+	b.config.AuditSink = sink
+	b.config.AuditPromptPolicy = policy
+	return b
+}
+
+// WithTracer sets the tracer that records a trace per invocation.
+func (b *Builder) WithTracer(tracer observability.Tracer) *Builder {
+	b.config.Tracer = tracer
+	return b
+}
+
 // WithRegistry uses an existing registry instead of creating a new one.
 func (b *Builder) WithRegistry(registry *Registry) *Builder {
 	b.registry = registry
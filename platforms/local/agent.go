@@ -7,6 +7,13 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/plexusone/omnillm"
+
+	"github.com/plexusone/agentkit/config"
+	agenthttp "github.com/plexusone/agentkit/http"
+	"github.com/plexusone/agentkit/llm/stream"
+	"github.com/plexusone/agentkit/observability"
 )
 
 // EmbeddedAgent is a lightweight agent that runs in-process.
@@ -17,6 +24,7 @@ type EmbeddedAgent struct {
 	tools        []Tool
 	llm          LLMClient
 	maxTokens    int
+	tracer       observability.Tracer
 }
 
 // LLMClient defines the interface for language model interactions.
@@ -25,6 +33,16 @@ type LLMClient interface {
 	Complete(ctx context.Context, messages []Message, tools []ToolDefinition) (*CompletionResponse, error)
 }
 
+// StreamingLLMClient is an LLMClient that can stream its completions.
+// EmbeddedAgent.InvokeStream uses it when the client implements it.
+type StreamingLLMClient interface {
+	LLMClient
+
+	// CompleteStream generates a completion like Complete, passing its
+	// text to onText as it is produced.
+	CompleteStream(ctx context.Context, messages []Message, tools []ToolDefinition, onText func(text string)) (*CompletionResponse, error)
+}
+
 // Message represents a chat message.
 type Message struct {
 	Role    string `json:"role"` // "system", "user", "assistant", "tool"
@@ -52,6 +70,10 @@ type CompletionResponse struct {
 	Content   string     `json:"content"`
 	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
 	Done      bool       `json:"done"`
+
+	// Usage is the token usage of the completion, if the provider
+	// reports it.
+	Usage *stream.Usage `json:"usage,omitempty"`
 }
 
 // NewEmbeddedAgent creates a new embedded agent.
@@ -88,9 +110,17 @@ func NewEmbeddedAgent(cfg AgentConfig, toolSet *ToolSet, llm LLMClient) (*Embedd
 		tools:        tools,
 		llm:          llm,
 		maxTokens:    maxTokens,
+		tracer:       observability.Noop(),
 	}, nil
 }
 
+// SetTracer sets the tracer that records the agent's invocations, as
+// agent spans with a span per LLM and tool call. Call it before invoking
+// the agent. Default: no tracing.
+func (a *EmbeddedAgent) SetTracer(tracer observability.Tracer) {
+	a.tracer = observability.OrNoop(tracer)
+}
+
 // Name returns the agent's name.
 func (a *EmbeddedAgent) Name() string {
 	return a.name
@@ -103,6 +133,116 @@ func (a *EmbeddedAgent) Description() string {
 
 // Invoke runs the agent with the given input and returns the result.
 func (a *EmbeddedAgent) Invoke(ctx context.Context, input string) (*AgentResult, error) {
+	result, _, err := a.run(ctx, input, nil)
+	return result, err
+}
+
+// InvokeStream runs the agent like Invoke and streams the text of the
+// LLM's responses, including those of turns that call tools, as described
+// by stream.Chunk. The last chunk carries the total usage of the LLM
+// calls. The text is streamed as it is produced if the LLM client is a
+// StreamingLLMClient, and a response at a time otherwise.
+func (a *EmbeddedAgent) InvokeStream(ctx context.Context, input string) <-chan stream.Chunk {
+	ch := make(chan stream.Chunk)
+	go func() {
+		defer close(ch)
+		send := func(c stream.Chunk) {
+			select {
+			case ch <- c:
+			case <-ctx.Done():
+			}
+		}
+		result, usage, err := a.run(ctx, input, func(text string) { send(stream.Chunk{Text: text}) })
+		if err == nil && !result.Success {
+			err = fmt.Errorf("%s", result.Error)
+		}
+		if err != nil {
+			send(stream.Chunk{Err: err})
+			return
+		}
+		send(stream.Chunk{Done: true, Usage: usage})
+	}()
+	return ch
+}
+
+// complete gets a completion from the LLM, streaming its text to onText if
+// it is set, and records it as an LLM span. Its usage is priced with
+// config.Cost unless the LLM client set a cost.
+func (a *EmbeddedAgent) complete(ctx context.Context, messages []Message, toolDefs []ToolDefinition, onText func(string)) (*CompletionResponse, error) {
+	model, name := "", "completion"
+	if m, ok := a.llm.(interface{ Model() string }); ok {
+		model, name = m.Model(), m.Model()
+	}
+	var providerName string
+	if p, ok := a.llm.(interface{ Provider() omnillm.ProviderName }); ok {
+		providerName = string(p.Provider())
+	}
+	ctx, span := a.tracer.Start(ctx, observability.SpanLLM, name, messages)
+	if providerName != "" {
+		span.SetModel(providerName, model)
+	}
+	resp, err := a.completeLLM(ctx, messages, toolDefs, onText)
+	if err != nil {
+		span.End(nil, err)
+		return nil, err
+	}
+	if resp.Usage != nil {
+		if resp.Usage.CostUSD == 0 && model != "" {
+			resp.Usage.CostUSD, _ = config.Cost(providerName, model, resp.Usage.PromptTokens, resp.Usage.CompletionTokens)
+		}
+		span.SetUsage(resp.Usage.PromptTokens, resp.Usage.CompletionTokens)
+		span.SetCost(resp.Usage.CostUSD)
+	}
+	span.End(resp, nil)
+	return resp, nil
+}
+
+// completeLLM gets a completion from the LLM, streaming its text to onText
+// if it is set.
+func (a *EmbeddedAgent) completeLLM(ctx context.Context, messages []Message, toolDefs []ToolDefinition, onText func(string)) (*CompletionResponse, error) {
+	if onText == nil {
+		return a.llm.Complete(ctx, messages, toolDefs)
+	}
+	if s, ok := a.llm.(StreamingLLMClient); ok {
+		return s.CompleteStream(ctx, messages, toolDefs, onText)
+	}
+	resp, err := a.llm.Complete(ctx, messages, toolDefs)
+	if err == nil && resp.Content != "" {
+		onText(resp.Content)
+	}
+	return resp, err
+}
+
+// run runs the agent loop like loop, recording it as an agent span. It
+// gives ctx a correlation ID if it has none.
+func (a *EmbeddedAgent) run(ctx context.Context, input string, onText func(string)) (*AgentResult, *stream.Usage, error) {
+	ctx, correlationID := agenthttp.EnsureCorrelationID(ctx)
+	ctx, span := a.tracer.Start(ctx, observability.SpanAgent, a.name, input)
+	span.SetMetadata("correlation_id", correlationID)
+	result, usage, err := a.loop(ctx, input, onText)
+	if usage != nil {
+		span.SetUsage(usage.PromptTokens, usage.CompletionTokens)
+		span.SetCost(usage.CostUSD)
+	}
+	if result != nil {
+		result.Usage = usage
+		result.CorrelationID = correlationID
+	}
+	switch {
+	case err != nil:
+		span.End(nil, err)
+	case !result.Success:
+		span.End(result.Output, fmt.Errorf("%s", result.Error))
+	default:
+		span.End(result.Output, nil)
+	}
+	return result, usage, err
+}
+
+// loop runs the agent loop, passing the text of the LLM's responses to
+// onText if it is set, and returns the result and the total usage of the
+// LLM calls.
+func (a *EmbeddedAgent) loop(ctx context.Context, input string, onText func(string)) (*AgentResult, *stream.Usage, error) {
 	// Build initial messages
 	messages := []Message{
 		{Role: "system", Content: a.instructions},
@@ -113,12 +253,21 @@ func (a *EmbeddedAgent) Invoke(ctx context.Context, input string) (*AgentResult,
 	toolDefs := a.buildToolDefinitions()
 
 	// Agent loop - handle tool calls until done
+	var usage *stream.Usage
 	maxIterations := 10
 	for i := 0; i < maxIterations; i++ {
 		// Get completion from LLM
-		resp, err := a.llm.Complete(ctx, messages, toolDefs)
+		resp, err := a.complete(ctx, messages, toolDefs, onText)
 		if err != nil {
-			return nil, fmt.Errorf("LLM completion failed: %w", err)
+			return nil, usage, fmt.Errorf("LLM completion failed: %w", err)
+		}
+		if resp.Usage != nil {
+			if usage == nil {
+				usage = &stream.Usage{}
+			}
+			usage.PromptTokens += resp.Usage.PromptTokens
+			usage.CompletionTokens += resp.Usage.CompletionTokens
+			usage.CostUSD += resp.Usage.CostUSD
 		}
 
 		// If no tool calls, we're done
@@ -128,7 +277,7 @@ func (a *EmbeddedAgent) Invoke(ctx context.Context, input string) (*AgentResult,
 				Input:   input,
 				Output:  resp.Content,
 				Success: true,
-			}, nil
+			}, usage, nil
 		}
 
 		// Add assistant message with tool calls
@@ -165,7 +314,7 @@ func (a *EmbeddedAgent) Invoke(ctx context.Context, input string) (*AgentResult,
 		Output:  "Max iterations reached",
 		Success: false,
 		Error:   "agent loop exceeded maximum iterations",
-	}, nil
+	}, usage, nil
 }
 
 // buildToolDefinitions creates tool definitions for the LLM.
@@ -248,19 +397,36 @@ func (a *EmbeddedAgent) getToolParameters(name string) map[string]interface{} {
 			},
 			"required": []string{"command"},
 		}
+	case "fetch":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"url": map[string]interface{}{
+					"type":        "string",
+					"description": "http or https URL of the page to fetch",
+				},
+			},
+			"required": []string{"url"},
+		}
 	default:
 		return map[string]interface{}{"type": "object"}
 	}
 }
 
-// executeTool executes a tool call and returns the result.
+// executeTool executes a tool call and returns the result, recording it as
+// a tool span.
 func (a *EmbeddedAgent) executeTool(ctx context.Context, tc ToolCall) (any, error) {
+	ctx, span := a.tracer.Start(ctx, observability.SpanTool, tc.Name, tc.Arguments)
 	for _, tool := range a.tools {
 		if tool.Name() == tc.Name {
-			return tool.Execute(ctx, tc.Arguments)
+			result, err := tool.Execute(ctx, tc.Arguments)
+			span.End(result, err)
+			return result, err
 		}
 	}
-	return nil, fmt.Errorf("unknown tool: %s", tc.Name)
+	err := fmt.Errorf("unknown tool: %s", tc.Name)
+	span.End(nil, err)
+	return nil, err
 }
 
 // AgentResult holds the result of an agent invocation.
@@ -270,4 +436,12 @@ type AgentResult struct {
 	Output  string `json:"output"`
 	Success bool   `json:"success"`
 	Error   string `json:"error,omitempty"`
+
+	// Usage is the total token usage and cost of the LLM calls, if the
+	// LLM client reports usage.
+	Usage *stream.Usage `json:"usage,omitempty"`
+
+	// CorrelationID identifies the request the agent ran for, across the
+	// agents serving it; see agenthttp.CorrelationID.
+	CorrelationID string `json:"correlation_id,omitempty"`
 }
@@ -12,6 +12,8 @@ import (
 	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/plexusone/agentkit/config"
 )
 
 // Config holds configuration for local embedded mode.
@@ -197,6 +199,8 @@ func DefaultConfig() Config {
 
 // LoadConfig loads configuration from a JSON or YAML file.
 // The format is detected by file extension (.json, .yaml, .yml).
+// String values may reference environment variables as ${VAR} or
+// ${VAR:-default}.
 func LoadConfig(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -219,6 +223,8 @@ func LoadConfig(path string) (*Config, error) {
 		return nil, fmt.Errorf("unsupported config format %q (use .json, .yaml, or .yml)", ext)
 	}
 
+	config.ExpandEnvFields(&cfg)
+
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid config: %w", err)
 	}
@@ -253,6 +259,8 @@ func LoadConfigFromBytes(data []byte, format ConfigFormat) (*Config, error) {
 		return nil, fmt.Errorf("unsupported config format: %s", format)
 	}
 
+	config.ExpandEnvFields(&cfg)
+
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid config: %w", err)
 	}
@@ -308,6 +316,7 @@ func (c *Config) Validate() error {
 			"glob":  true,
 			"grep":  true,
 			"shell": true,
+			"fetch": true,
 		}
 		for _, tool := range agent.Tools {
 			if !validTools[tool] {
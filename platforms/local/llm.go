@@ -4,10 +4,15 @@ package local
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
 
 	"github.com/plexusone/omnillm"
 	"github.com/plexusone/omnillm/provider"
+
+	"github.com/plexusone/agentkit/llm/stream"
 )
 
 // OmniLLMClient implements LLMClient using omnillm.ChatClient.
@@ -58,6 +63,81 @@ func NewOmniLLMClient(cfg OmniLLMConfig) (*OmniLLMClient, error) {
 
 // Complete generates a completion for the given messages.
 func (c *OmniLLMClient) Complete(ctx context.Context, messages []Message, tools []ToolDefinition) (*CompletionResponse, error) {
+	resp, err := c.client.CreateChatCompletion(ctx, c.request(messages, tools))
+	if err != nil {
+		return nil, fmt.Errorf("completion failed: %w", err)
+	}
+
+	return convertFromOmniResponse(resp), nil
+}
+
+// CompleteStream implements StreamingLLMClient. Tool calls are assembled
+// from the streamed deltas.
+func (c *OmniLLMClient) CompleteStream(ctx context.Context, messages []Message, tools []ToolDefinition, onText func(text string)) (*CompletionResponse, error) {
+	s, err := c.client.CreateChatCompletionStream(ctx, c.request(messages, tools))
+	if err != nil {
+		return nil, fmt.Errorf("completion failed: %w", err)
+	}
+	defer func() { _ = s.Close() }()
+
+	var content strings.Builder
+	var toolCalls []provider.ToolCall
+	var finish *string
+	var usage *provider.Usage
+	for {
+		chunk, err := s.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("completion stream failed: %w", err)
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		choice := chunk.Choices[0]
+		if choice.FinishReason != nil {
+			finish = choice.FinishReason
+		}
+		delta := &choice.Message
+		if choice.Delta != nil {
+			delta = choice.Delta
+		}
+		if delta.Content != "" {
+			content.WriteString(delta.Content)
+			onText(delta.Content)
+		}
+		for _, tc := range delta.ToolCalls {
+			// A call starts with its ID; later deltas add to its arguments.
+			if tc.ID != "" || len(toolCalls) == 0 {
+				toolCalls = append(toolCalls, tc)
+				continue
+			}
+			last := &toolCalls[len(toolCalls)-1]
+			if last.Function.Name == "" {
+				last.Function.Name = tc.Function.Name
+			}
+			last.Function.Arguments += tc.Function.Arguments
+		}
+	}
+
+	resp := &provider.ChatCompletionResponse{
+		Choices: []provider.ChatCompletionChoice{{
+			Message:      provider.Message{Role: provider.RoleAssistant, Content: content.String(), ToolCalls: toolCalls},
+			FinishReason: finish,
+		}},
+	}
+	if usage != nil {
+		resp.Usage = *usage
+	}
+	return convertFromOmniResponse(resp), nil
+}
+
+// request builds the omnillm request for messages and tools.
+func (c *OmniLLMClient) request(messages []Message, tools []ToolDefinition) *provider.ChatCompletionRequest {
 	// Convert local messages to omnillm messages
 	omniMessages := make([]provider.Message, len(messages))
 	for i, msg := range messages {
@@ -70,18 +150,11 @@ func (c *OmniLLMClient) Complete(ctx context.Context, messages []Message, tools
 		omniTools[i] = convertToOmniTool(tool)
 	}
 
-	req := &provider.ChatCompletionRequest{
+	return &provider.ChatCompletionRequest{
 		Model:    c.model,
 		Messages: omniMessages,
 		Tools:    omniTools,
 	}
-
-	resp, err := c.client.CreateChatCompletion(ctx, req)
-	if err != nil {
-		return nil, fmt.Errorf("completion failed: %w", err)
-	}
-
-	return convertFromOmniResponse(resp), nil
 }
 
 // Close closes the underlying client.
@@ -154,6 +227,12 @@ func convertFromOmniResponse(resp *provider.ChatCompletionResponse) *CompletionR
 
 	choice := resp.Choices[0]
 	result.Content = choice.Message.Content
+	if resp.Usage.PromptTokens > 0 || resp.Usage.CompletionTokens > 0 {
+		result.Usage = &stream.Usage{
+			PromptTokens:     resp.Usage.PromptTokens,
+			CompletionTokens: resp.Usage.CompletionTokens,
+		}
+	}
 
 	// Check finish reason to determine if we're done
 	if choice.FinishReason != nil {
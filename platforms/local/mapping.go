@@ -13,7 +13,7 @@ var CanonicalToolMap = map[string]string{
 	"Grep":      "grep",
 	"Bash":      "shell",
 	"WebSearch": "shell", // Implemented via curl/external commands
-	"WebFetch":  "shell", // Implemented via curl/external commands
+	"WebFetch":  "fetch", // Main page content as Markdown (see extract)
 	"Task":      "",      // Task spawning handled by orchestration layer
 }
 
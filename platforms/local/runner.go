@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"log"
 	"sync"
+
+	agenthttp "github.com/plexusone/agentkit/http"
+	"github.com/plexusone/agentkit/observability"
 )
 
 // Runner orchestrates multiple embedded agents.
@@ -44,7 +47,17 @@ func NewRunner(cfg *Config, llm LLMClient) (*Runner, error) {
 	return runner, nil
 }
 
-// Invoke runs a single agent synchronously.
+// SetTracer sets the tracer of all agents, see EmbeddedAgent.SetTracer.
+func (r *Runner) SetTracer(tracer observability.Tracer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, agent := range r.agents {
+		agent.SetTracer(tracer)
+	}
+}
+
+// Invoke runs a single agent synchronously. The agent runs with the
+// correlation ID of ctx, or a new one if ctx has none.
 func (r *Runner) Invoke(ctx context.Context, agentName, input string) (*AgentResult, error) {
 	r.mu.RLock()
 	agent, ok := r.agents[agentName]
@@ -54,13 +67,14 @@ func (r *Runner) Invoke(ctx context.Context, agentName, input string) (*AgentRes
 		return nil, fmt.Errorf("agent not found: %s", agentName)
 	}
 
-	log.Printf("[Runner] Invoking agent: %s", agentName)
+	ctx, correlationID := agenthttp.EnsureCorrelationID(ctx)
+	log.Printf("[Runner] Invoking agent: %s (correlation_id=%s)", agentName, correlationID)
 	result, err := agent.Invoke(ctx, input)
 	if err != nil {
-		return nil, fmt.Errorf("agent invocation failed: %w", err)
+		return nil, fmt.Errorf("agent invocation failed (correlation_id=%s): %w", correlationID, err)
 	}
 
-	log.Printf("[Runner] Agent %s completed: success=%v", agentName, result.Success)
+	log.Printf("[Runner] Agent %s completed: success=%v (correlation_id=%s)", agentName, result.Success, correlationID)
 	return result, nil
 }
 
@@ -70,11 +84,13 @@ type AgentTask struct {
 	Input string `json:"input"`
 }
 
-// InvokeParallel runs multiple agents concurrently.
+// InvokeParallel runs multiple agents concurrently, with one correlation
+// ID as Invoke.
 func (r *Runner) InvokeParallel(ctx context.Context, tasks []AgentTask) ([]*AgentResult, error) {
 	if len(tasks) == 0 {
 		return nil, nil
 	}
+	ctx, correlationID := agenthttp.EnsureCorrelationID(ctx)
 
 	log.Printf("[Runner] Starting parallel execution of %d agents", len(tasks))
 
@@ -91,10 +107,11 @@ func (r *Runner) InvokeParallel(ctx context.Context, tasks []AgentTask) ([]*Agen
 			if err != nil {
 				errors[idx] = err
 				results[idx] = &AgentResult{
-					Agent:   t.Agent,
-					Input:   t.Input,
-					Success: false,
-					Error:   err.Error(),
+					Agent:         t.Agent,
+					Input:         t.Input,
+					Success:       false,
+					Error:         err.Error(),
+					CorrelationID: correlationID,
 				}
 			} else {
 				results[idx] = result
@@ -117,11 +134,13 @@ func (r *Runner) InvokeParallel(ctx context.Context, tasks []AgentTask) ([]*Agen
 	return results, nil
 }
 
-// InvokeSequential runs multiple agents in sequence, passing context between them.
+// InvokeSequential runs multiple agents in sequence, passing context between
+// them, with one correlation ID as Invoke.
 func (r *Runner) InvokeSequential(ctx context.Context, tasks []AgentTask) ([]*AgentResult, error) {
 	if len(tasks) == 0 {
 		return nil, nil
 	}
+	ctx, correlationID := agenthttp.EnsureCorrelationID(ctx)
 
 	log.Printf("[Runner] Starting sequential execution of %d agents", len(tasks))
 
@@ -138,10 +157,11 @@ func (r *Runner) InvokeSequential(ctx context.Context, tasks []AgentTask) ([]*Ag
 		result, err := r.Invoke(ctx, task.Agent, input)
 		if err != nil {
 			result = &AgentResult{
-				Agent:   task.Agent,
-				Input:   task.Input,
-				Success: false,
-				Error:   err.Error(),
+				Agent:         task.Agent,
+				Input:         task.Input,
+				Success:       false,
+				Error:         err.Error(),
+				CorrelationID: correlationID,
 			}
 		}
 
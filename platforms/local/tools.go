@@ -4,12 +4,18 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"io/fs"
+	"mime"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
+
+	"github.com/plexusone/agentkit/extract"
 )
 
 // Tool represents a capability available to agents.
@@ -19,25 +25,36 @@ type Tool interface {
 	Execute(ctx context.Context, args map[string]any) (any, error)
 }
 
-// ToolSet provides filesystem and shell tools scoped to a workspace.
+// ToolSet provides filesystem, shell and web fetch tools scoped to a
+// workspace.
 type ToolSet struct {
-	workspace   string
-	maxFileSize int64
+	workspace      string
+	maxFileSize    int64
+	maxFetchTokens int
+	httpClient     *http.Client
 }
 
 // NewToolSet creates a new tool set for the given workspace.
 func NewToolSet(workspace string) *ToolSet {
 	return &ToolSet{
-		workspace:   workspace,
-		maxFileSize: 10 * 1024 * 1024, // 10MB default
+		workspace:      workspace,
+		maxFileSize:    10 * 1024 * 1024, // 10MB default
+		maxFetchTokens: 8000,
+		httpClient:     &http.Client{Timeout: 30 * time.Second},
 	}
 }
 
-// SetMaxFileSize sets the maximum file size for read operations.
+// SetMaxFileSize sets the maximum file size for read operations and
+// fetched pages.
 func (ts *ToolSet) SetMaxFileSize(size int64) {
 	ts.maxFileSize = size
 }
 
+// SetMaxFetchTokens sets the token budget of fetched page content.
+func (ts *ToolSet) SetMaxFetchTokens(tokens int) {
+	ts.maxFetchTokens = tokens
+}
+
 // validatePath ensures a path is within the workspace.
 func (ts *ToolSet) validatePath(path string) (string, error) {
 	// Handle relative paths
@@ -242,6 +259,51 @@ func (ts *ToolSet) RunShell(ctx context.Context, shellCmd string) (*CommandResul
 	return ts.RunCommand(ctx, "sh", []string{"-c", shellCmd})
 }
 
+// FetchURL fetches a web page and returns its main content as Markdown,
+// without navigation, scripts and other boilerplate (see
+// extract.FromHTML). Other text content is returned as is. Both are
+// truncated to the fetch token budget.
+func (ts *ToolSet) FetchURL(ctx context.Context, rawURL string) (string, error) {
+	if !strings.HasPrefix(rawURL, "http://") && !strings.HasPrefix(rawURL, "https://") {
+		return "", fmt.Errorf("only http and https URLs can be fetched: %s", rawURL)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "AgentKit")
+
+	resp, err := ts.httpClient.Do(req) //nolint:gosec // G704: URL provided by the agent
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch URL: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, ts.maxFileSize))
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		content, _ := extract.Truncate(string(body), ts.maxFetchTokens, extract.EstimateTokens)
+		return content, nil
+	}
+	doc, err := extract.FromHTML(string(body), extract.Options{
+		BaseURL:   resp.Request.URL.String(),
+		MaxTokens: ts.maxFetchTokens,
+	})
+	if err != nil {
+		return "", err
+	}
+	if doc.Title != "" {
+		return "# " + doc.Title + "\n\n" + doc.Content, nil
+	}
+	return doc.Content, nil
+}
+
 // CommandResult holds the result of a command execution.
 type CommandResult struct {
 	Command  string   `json:"command"`
@@ -372,6 +434,23 @@ func (t *ShellTool) Execute(ctx context.Context, args map[string]any) (any, erro
 	return t.ts.RunShell(ctx, command)
 }
 
+// FetchTool wraps FetchURL as a Tool interface.
+type FetchTool struct {
+	ts *ToolSet
+}
+
+func (t *FetchTool) Name() string { return "fetch" }
+func (t *FetchTool) Description() string {
+	return "Fetch a web page and return its main content as Markdown"
+}
+func (t *FetchTool) Execute(ctx context.Context, args map[string]any) (any, error) {
+	url, ok := args["url"].(string)
+	if !ok {
+		return nil, fmt.Errorf("url argument required")
+	}
+	return t.ts.FetchURL(ctx, url)
+}
+
 // CreateTools creates Tool instances for the specified tool names.
 func (ts *ToolSet) CreateTools(names []string) ([]Tool, error) {
 	var tools []Tool
@@ -387,6 +466,8 @@ func (ts *ToolSet) CreateTools(names []string) ([]Tool, error) {
 			tools = append(tools, &GrepTool{ts: ts})
 		case "shell":
 			tools = append(tools, &ShellTool{ts: ts})
+		case "fetch":
+			tools = append(tools, &FetchTool{ts: ts})
 		default:
 			return nil, fmt.Errorf("unknown tool: %s", name)
 		}
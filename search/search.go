@@ -0,0 +1,200 @@
+// Package search provides web, news and image search through the Serper
+// and SerpAPI Google search APIs, behind one Provider interface.
+//
+// A Provider is created from the search settings of the config with New,
+// or directly with NewSerper or NewSerpAPI. Both return results in the
+// same normalized form, so agents do not depend on the provider:
+//
+//	provider, err := search.New(cfg)
+//	resp, err := provider.Search(ctx, search.Request{Query: "golang generics"})
+//	for _, r := range resp.Results {
+//	    fmt.Println(r.Title, r.URL)
+//	}
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/plexusone/agentkit/config"
+)
+
+// defaultTimeout bounds each request to a search API.
+const defaultTimeout = 30 * time.Second
+
+// ErrMissingAPIKey is returned by New when the API key of the configured
+// provider is not set.
+var ErrMissingAPIKey = errors.New("search API key is not set")
+
+// Type is the kind of results searched for.
+type Type string
+
+const (
+	// TypeWeb searches web pages.
+	TypeWeb Type = "web"
+
+	// TypeNews searches news articles.
+	TypeNews Type = "news"
+
+	// TypeImages searches images.
+	TypeImages Type = "images"
+)
+
+// Request is a search query.
+type Request struct {
+	// Query is the search query.
+	Query string `json:"query"`
+
+	// Type is the kind of results searched for.
+	// Default: TypeWeb
+	Type Type `json:"type,omitempty"`
+
+	// Num is the number of results per page.
+	// Default: 10
+	Num int `json:"num,omitempty"`
+
+	// Page is the page of results, starting at 1.
+	// Default: 1
+	Page int `json:"page,omitempty"`
+
+	// Country is the two-letter country code results are localized to,
+	// e.g. "us".
+	Country string `json:"country,omitempty"`
+
+	// Language is the two-letter language code of the results, e.g. "en".
+	Language string `json:"language,omitempty"`
+}
+
+// withDefaults returns r with zero fields set to their defaults.
+func (r Request) withDefaults() Request {
+	if r.Type == "" {
+		r.Type = TypeWeb
+	}
+	if r.Num <= 0 {
+		r.Num = 10
+	}
+	if r.Page <= 0 {
+		r.Page = 1
+	}
+	return r
+}
+
+// validate checks that r can be sent to a provider.
+func (r Request) validate() error {
+	if strings.TrimSpace(r.Query) == "" {
+		return errors.New("search query is empty")
+	}
+	switch r.Type {
+	case TypeWeb, TypeNews, TypeImages:
+		return nil
+	default:
+		return fmt.Errorf("unsupported search type %q", r.Type)
+	}
+}
+
+// Result is one search result, in the same form for every provider.
+// Fields a provider does not return for a type of search are empty.
+type Result struct {
+	// Position is the rank of the result in the response, starting at 1.
+	Position int `json:"position"`
+
+	// Title is the title of the page, article or image.
+	Title string `json:"title"`
+
+	// URL is the page the result links to.
+	URL string `json:"url"`
+
+	// Snippet is an excerpt of the page or article.
+	Snippet string `json:"snippet,omitempty"`
+
+	// Source is the publisher or site name.
+	Source string `json:"source,omitempty"`
+
+	// Date is the publication date as reported by the provider, e.g.
+	// "2 hours ago" or "Jan 5, 2025".
+	Date string `json:"date,omitempty"`
+
+	// ImageURL is the full-size image of an image result, or the image of
+	// a news article.
+	ImageURL string `json:"image_url,omitempty"`
+
+	// ThumbnailURL is a thumbnail of the image.
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+}
+
+// Response is the result of a search.
+type Response struct {
+	// Provider is the name of the provider that answered.
+	Provider string `json:"provider"`
+
+	// Query is the query searched for.
+	Query string `json:"query"`
+
+	// Type is the kind of results searched for.
+	Type Type `json:"type"`
+
+	// Results are the results, best first.
+	Results []Result `json:"results"`
+}
+
+// Provider is a search API. Implementations must be safe for concurrent
+// use.
+type Provider interface {
+	// Name returns the name of the provider, e.g. "serper".
+	Name() string
+
+	// Search runs req and returns its normalized results.
+	Search(ctx context.Context, req Request) (*Response, error)
+}
+
+// New creates the Provider configured by the search settings of cfg:
+// SearchProvider (serper or serpapi) with its API key, SerperAPIKey or
+// SerpAPIKey.
+func New(cfg *config.Config) (Provider, error) {
+	switch cfg.SearchProvider {
+	case "serper", "":
+		if cfg.SerperAPIKey == "" {
+			return nil, fmt.Errorf("serper: %w (SERPER_API_KEY)", ErrMissingAPIKey)
+		}
+		return NewSerper(cfg.SerperAPIKey), nil
+	case "serpapi":
+		if cfg.SerpAPIKey == "" {
+			return nil, fmt.Errorf("serpapi: %w (SERPAPI_API_KEY)", ErrMissingAPIKey)
+		}
+		return NewSerpAPI(cfg.SerpAPIKey), nil
+	default:
+		return nil, fmt.Errorf("unsupported search provider: %s", cfg.SearchProvider)
+	}
+}
+
+// normalize cleans up the results of a provider: it trims whitespace,
+// drops results without a valid URL and repeated URLs, keeps at most num
+// results and numbers them from 1.
+func normalize(results []Result, num int) []Result {
+	seen := make(map[string]bool, len(results))
+	out := make([]Result, 0, min(len(results), num))
+	for _, r := range results {
+		r.Title = strings.TrimSpace(r.Title)
+		r.URL = strings.TrimSpace(r.URL)
+		r.Snippet = strings.Join(strings.Fields(r.Snippet), " ")
+		r.Source = strings.TrimSpace(r.Source)
+		r.Date = strings.TrimSpace(r.Date)
+		if u, err := url.Parse(r.URL); err != nil || u.Host == "" || seen[r.URL] {
+			continue
+		}
+		seen[r.URL] = true
+		if r.Title == "" {
+			r.Title = r.URL
+		}
+		r.Position = len(out) + 1
+		out = append(out, r)
+		if len(out) == num {
+			break
+		}
+	}
+	return out
+}
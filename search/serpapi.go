@@ -0,0 +1,146 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	agenthttp "github.com/plexusone/agentkit/http"
+)
+
+// serpAPIBaseURL is the endpoint of SerpAPI.
+const serpAPIBaseURL = "https://serpapi.com"
+
+// SerpAPI searches Google with SerpAPI (serpapi.com).
+type SerpAPI struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+// NewSerpAPI creates a SerpAPI provider with apiKey.
+func NewSerpAPI(apiKey string) *SerpAPI {
+	return &SerpAPI{
+		apiKey:  apiKey,
+		baseURL: serpAPIBaseURL,
+		client:  agenthttp.NewClient(agenthttp.ClientConfig{Timeout: defaultTimeout}),
+	}
+}
+
+// SetClient sets a custom HTTP client.
+func (s *SerpAPI) SetClient(client *http.Client) *SerpAPI {
+	s.client = client
+	return s
+}
+
+// SetBaseURL sets the endpoint of the API, e.g. for a proxy.
+func (s *SerpAPI) SetBaseURL(baseURL string) *SerpAPI {
+	s.baseURL = strings.TrimSuffix(baseURL, "/")
+	return s
+}
+
+// Name implements Provider.
+func (s *SerpAPI) Name() string { return "serpapi" }
+
+// serpAPIResult is a result of any type of SerpAPI search.
+type serpAPIResult struct {
+	Title     string `json:"title"`
+	Link      string `json:"link"`
+	Snippet   string `json:"snippet"`
+	Date      string `json:"date"`
+	Source    string `json:"source"`
+	Original  string `json:"original"`
+	Thumbnail string `json:"thumbnail"`
+}
+
+// serpAPIResponse holds the results of each type of SerpAPI search.
+type serpAPIResponse struct {
+	Error          string          `json:"error"`
+	OrganicResults []serpAPIResult `json:"organic_results"`
+	NewsResults    []serpAPIResult `json:"news_results"`
+	ImagesResults  []serpAPIResult `json:"images_results"`
+}
+
+// Search implements Provider.
+func (s *SerpAPI) Search(ctx context.Context, req Request) (*Response, error) {
+	req = req.withDefaults()
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+
+	params := url.Values{
+		"engine":  {"google"},
+		"q":       {req.Query},
+		"api_key": {s.apiKey},
+		"num":     {strconv.Itoa(req.Num)},
+	}
+	if req.Page > 1 {
+		params.Set("start", strconv.Itoa((req.Page-1)*req.Num))
+	}
+	if req.Country != "" {
+		params.Set("gl", req.Country)
+	}
+	if req.Language != "" {
+		params.Set("hl", req.Language)
+	}
+	switch req.Type {
+	case TypeNews:
+		params.Set("tbm", "nws")
+	case TypeImages:
+		params.Set("tbm", "isch")
+	}
+
+	var resp serpAPIResponse
+	err := agenthttp.GetJSON(ctx, s.client, s.baseURL+"/search.json?"+params.Encode(), &resp,
+		agenthttp.WithRetry(agenthttp.DefaultRetryPolicy()))
+	if err != nil {
+		return nil, fmt.Errorf("serpapi search failed: %w", redact(err, s.apiKey))
+	}
+	if resp.Error != "" {
+		// A search without results is reported as an error.
+		if !strings.Contains(resp.Error, "hasn't returned any results") {
+			return nil, fmt.Errorf("serpapi search failed: %s", resp.Error)
+		}
+	}
+
+	raw := map[Type][]serpAPIResult{TypeWeb: resp.OrganicResults, TypeNews: resp.NewsResults, TypeImages: resp.ImagesResults}[req.Type]
+	results := make([]Result, 0, len(raw))
+	for _, r := range raw {
+		result := Result{
+			Title:        r.Title,
+			URL:          r.Link,
+			Snippet:      r.Snippet,
+			Source:       r.Source,
+			Date:         r.Date,
+			ThumbnailURL: r.Thumbnail,
+		}
+		if req.Type == TypeImages {
+			result.ImageURL = r.Original
+		}
+		results = append(results, result)
+	}
+	return &Response{Provider: s.Name(), Query: req.Query, Type: req.Type, Results: normalize(results, req.Num)}, nil
+}
+
+// redactedError hides the API key in the message of an error, whose
+// request URL carries the key as a query parameter.
+type redactedError struct {
+	err error
+	msg string
+}
+
+// redact returns err with key replaced in its message.
+func redact(err error, key string) error {
+	msg := err.Error()
+	if key != "" {
+		msg = strings.ReplaceAll(msg, url.QueryEscape(key), "REDACTED")
+		msg = strings.ReplaceAll(msg, key, "REDACTED")
+	}
+	return &redactedError{err: err, msg: msg}
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
@@ -0,0 +1,112 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	agenthttp "github.com/plexusone/agentkit/http"
+)
+
+// serperBaseURL is the endpoint of the Serper API.
+const serperBaseURL = "https://google.serper.dev"
+
+// Serper searches with the Serper API (serper.dev).
+type Serper struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+// NewSerper creates a Serper provider with apiKey.
+func NewSerper(apiKey string) *Serper {
+	return &Serper{
+		apiKey:  apiKey,
+		baseURL: serperBaseURL,
+		client:  agenthttp.NewClient(agenthttp.ClientConfig{Timeout: defaultTimeout}),
+	}
+}
+
+// SetClient sets a custom HTTP client.
+func (s *Serper) SetClient(client *http.Client) *Serper {
+	s.client = client
+	return s
+}
+
+// SetBaseURL sets the endpoint of the API, e.g. for a proxy.
+func (s *Serper) SetBaseURL(baseURL string) *Serper {
+	s.baseURL = strings.TrimSuffix(baseURL, "/")
+	return s
+}
+
+// Name implements Provider.
+func (s *Serper) Name() string { return "serper" }
+
+// serperRequest is the body of a Serper search.
+type serperRequest struct {
+	Q    string `json:"q"`
+	Num  int    `json:"num,omitempty"`
+	Page int    `json:"page,omitempty"`
+	GL   string `json:"gl,omitempty"`
+	HL   string `json:"hl,omitempty"`
+}
+
+// serperResult is a result of any type of Serper search.
+type serperResult struct {
+	Title        string `json:"title"`
+	Link         string `json:"link"`
+	Snippet      string `json:"snippet"`
+	Date         string `json:"date"`
+	Source       string `json:"source"`
+	Domain       string `json:"domain"`
+	ImageURL     string `json:"imageUrl"`
+	ThumbnailURL string `json:"thumbnailUrl"`
+}
+
+// serperResponse holds the results of each type of Serper search.
+type serperResponse struct {
+	Organic []serperResult `json:"organic"`
+	News    []serperResult `json:"news"`
+	Images  []serperResult `json:"images"`
+}
+
+// Search implements Provider.
+func (s *Serper) Search(ctx context.Context, req Request) (*Response, error) {
+	req = req.withDefaults()
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+
+	endpoint := map[Type]string{TypeWeb: "/search", TypeNews: "/news", TypeImages: "/images"}[req.Type]
+	var resp serperResponse
+	err := agenthttp.PostJSON(ctx, s.client, s.baseURL+endpoint, serperRequest{
+		Q:    req.Query,
+		Num:  req.Num,
+		Page: req.Page,
+		GL:   req.Country,
+		HL:   req.Language,
+	}, &resp, agenthttp.WithRetry(agenthttp.DefaultRetryPolicy()), agenthttp.WithHeader("X-API-KEY", s.apiKey))
+	if err != nil {
+		return nil, fmt.Errorf("serper search failed: %w", err)
+	}
+
+	raw := map[Type][]serperResult{TypeWeb: resp.Organic, TypeNews: resp.News, TypeImages: resp.Images}[req.Type]
+	results := make([]Result, 0, len(raw))
+	for _, r := range raw {
+		result := Result{
+			Title:        r.Title,
+			URL:          r.Link,
+			Snippet:      r.Snippet,
+			Source:       r.Source,
+			Date:         r.Date,
+			ImageURL:     r.ImageURL,
+			ThumbnailURL: r.ThumbnailURL,
+		}
+		if result.Source == "" {
+			result.Source = r.Domain
+		}
+		results = append(results, result)
+	}
+	return &Response{Provider: s.Name(), Query: req.Query, Type: req.Type, Results: normalize(results, req.Num)}, nil
+}